@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/style"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// outputActiveSecrets lists the findings whose secrets validation confirmed
+// live, one line each with where the first such match is and what the
+// validator said, ahead of the rest of a human report. It prints nothing if
+// there are none. Findings must already be sorted, active ones first.
+func outputActiveSecrets(out io.Writer, s store.Store, st *style.Styles, findings []*types.Finding, matchesByFinding map[string][]*types.Match, ruleMap map[string]*types.Rule) {
+	var active []*types.Finding
+	for _, f := range findings {
+		if activeFinding(f, matchesByFinding) {
+			active = append(active, f)
+		}
+	}
+	if len(active) == 0 {
+		return
+	}
+
+	fmt.Fprintf(out, "%s %s\n", st.Active.Sprint("Active Secrets"),
+		st.Metadata.Sprintf("(%d confirmed live by validation)", len(active)))
+	for _, f := range active {
+		ruleName := f.RuleID
+		if r, ok := ruleMap[f.RuleID]; ok {
+			ruleName = r.Name
+		}
+		m := firstActiveMatch(f, matchesByFinding)
+		place := m.BlobID.Hex()
+		if prov, err := s.GetProvenance(m.BlobID); err == nil && prov != nil && prov.Path() != "" {
+			place = prov.Path()
+		}
+		if m.Location.Source.Start.Line > 0 {
+			place = fmt.Sprintf("%s:%d", place, m.Location.Source.Start.Line)
+		}
+		fmt.Fprintf(out, "  %s %s %s %s\n",
+			st.RuleName.Sprint(ruleName),
+			st.Metadata.Sprint(place),
+			st.Heading.Sprint("id"),
+			st.ID.Sprint(f.ID))
+		if msg := m.ValidationResult.Message; msg != "" {
+			fmt.Fprintf(out, "      %s\n", st.Metadata.Sprint(msg))
+		}
+	}
+	fmt.Fprintf(out, "\n\n")
+}
+
+// formatValidation describes a validation result for human output, e.g.
+// "valid (authenticated as octocat)".
+func formatValidation(r *types.ValidationResult) string {
+	if r.Message == "" {
+		return string(r.Status)
+	}
+	return fmt.Sprintf("%s (%s)", r.Status, r.Message)
+}
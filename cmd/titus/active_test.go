@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/style"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputActiveSecrets(t *testing.T) {
+	s := store.NewMemory()
+	blob := types.ComputeBlobID([]byte("creds"))
+	require.NoError(t, s.AddProvenance(blob, types.FileProvenance{FilePath: "deploy/.env"}))
+
+	live := &types.Match{BlobID: blob, RuleID: "np.github.1", ValidationResult: types.NewValidationResult(types.StatusValid, 1, "authenticated as octocat")}
+	live.Location.Source.Start.Line = 7
+	dead := &types.Match{BlobID: blob, RuleID: "np.aws.1", ValidationResult: types.NewValidationResult(types.StatusInvalid, 1, "rejected")}
+	findings := []*types.Finding{{ID: "f1", RuleID: "np.github.1"}, {ID: "f2", RuleID: "np.aws.1"}}
+	byFinding := map[string][]*types.Match{"f1": {live}, "f2": {dead}}
+	ruleMap := map[string]*types.Rule{"np.github.1": {ID: "np.github.1", Name: "GitHub Personal Access Token"}}
+
+	var out bytes.Buffer
+	outputActiveSecrets(&out, s, style.New(false), findings, byFinding, ruleMap)
+	assert.Contains(t, out.String(), "Active Secrets (1 confirmed live by validation)\n")
+	assert.Contains(t, out.String(), "  GitHub Personal Access Token deploy/.env:7 id f1\n")
+	assert.Contains(t, out.String(), "authenticated as octocat")
+	assert.NotContains(t, out.String(), "f2")
+
+	out.Reset()
+	outputActiveSecrets(&out, s, style.New(false), findings[1:], byFinding, ruleMap)
+	assert.Empty(t, out.String(), "nothing is printed without live secrets")
+}
+
+func TestOutputReportHuman_ActiveSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "datastore.db")
+	s, err := store.New(store.Config{Path: path})
+	require.NoError(t, err)
+	defer s.Close()
+
+	blob := types.ComputeBlobID([]byte("creds"))
+	require.NoError(t, s.AddBlob(blob, 5))
+	require.NoError(t, s.AddProvenance(blob, types.FileProvenance{FilePath: "a/creds"}))
+	rule := &types.Rule{ID: "test.1", Name: "Test", StructuralID: "test"}
+	match := &types.Match{BlobID: blob, RuleID: rule.ID, StructuralID: "m1", Groups: [][]byte{[]byte("secret")},
+		ValidationResult: types.NewValidationResult(types.StatusValid, 1, "key is live")}
+	finding := &types.Finding{ID: types.ComputeFindingID(rule.StructuralID, match.Groups), RuleID: rule.ID, Groups: match.Groups}
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	require.NoError(t, outputReportHuman(cmd, []*types.Finding{finding}, []*types.Match{match}, path,
+		map[string]*types.Rule{rule.ID: rule}))
+
+	assert.True(t, strings.HasPrefix(out.String(), "Active Secrets (1 confirmed live by validation)\n"), out.String())
+	assert.Contains(t, out.String(), "Validation: valid (key is live)")
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// setIntroduced sets the Introduced date of each finding: the earliest
+// commit date of the git locations of its matches.
+func setIntroduced(s store.Store, findings []*types.Finding, matchesByFinding map[string][]*types.Match) {
+	earliest := make(map[types.BlobID]time.Time)
+	for _, f := range findings {
+		f.Introduced = time.Time{}
+		for _, m := range matchesByFinding[f.ID] {
+			t, ok := earliest[m.BlobID]
+			if !ok {
+				if provs, err := s.GetAllProvenance(m.BlobID); err == nil {
+					t = types.EarliestCommit(provs)
+				}
+				earliest[m.BlobID] = t
+			}
+			if !t.IsZero() && (f.Introduced.IsZero() || t.Before(f.Introduced)) {
+				f.Introduced = t
+			}
+		}
+	}
+}
+
+// sortFindingsByAge orders findings newest first by Introduced, keeping
+// the order of those introduced at the same time; findings of unknown age
+// come last. Active findings stay ahead of the rest.
+func sortFindingsByAge(findings []*types.Finding, matchesByFinding map[string][]*types.Match) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if aa, ba := activeFinding(findings[i], matchesByFinding), activeFinding(findings[j], matchesByFinding); aa != ba {
+			return aa
+		}
+		a, b := findings[i].Introduced, findings[j].Introduced
+		if a.IsZero() || b.IsZero() {
+			return !a.IsZero() && b.IsZero()
+		}
+		return a.After(b)
+	})
+}
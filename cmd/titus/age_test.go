@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetIntroduced(t *testing.T) {
+	s := store.NewMemory()
+	older := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	blob := func(content string, provs ...types.Provenance) types.BlobID {
+		id := types.ComputeBlobID([]byte(content))
+		require.NoError(t, s.AddBlob(id, int64(len(content))))
+		for _, p := range provs {
+			require.NoError(t, s.AddProvenance(id, p))
+		}
+		return id
+	}
+	a := blob("a", types.GitProvenance{BlobPath: "a", Commit: &types.CommitMetadata{AuthorTimestamp: newer}})
+	b := blob("b", types.GitProvenance{BlobPath: "b", Commit: &types.CommitMetadata{AuthorTimestamp: older}}, types.FileProvenance{FilePath: "b"})
+	c := blob("c", types.FileProvenance{FilePath: "c"})
+
+	findings := []*types.Finding{{ID: "ab"}, {ID: "a"}, {ID: "c"}}
+	setIntroduced(s, findings, map[string][]*types.Match{
+		"ab": {{BlobID: a}, {BlobID: b}},
+		"a":  {{BlobID: a}},
+		"c":  {{BlobID: c}},
+	})
+	assert.Equal(t, older, findings[0].Introduced)
+	assert.Equal(t, newer, findings[1].Introduced)
+	assert.True(t, findings[2].Introduced.IsZero())
+
+	sortFindingsByAge(findings, nil)
+	assert.Equal(t, "a", findings[0].ID, "the most recently introduced comes first")
+	assert.Equal(t, "ab", findings[1].ID)
+	assert.Equal(t, "c", findings[2].ID, "findings of unknown age come last")
+}
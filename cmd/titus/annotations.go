@@ -0,0 +1,317 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// annotationsFileVersion is the version of the annotations export format.
+const annotationsFileVersion = 1
+
+var (
+	annotationsDatastore string
+	annotationsOutput    string
+)
+
+var annotationsCmd = &cobra.Command{
+	Use:   "annotations",
+	Short: "Export and import triage decisions",
+	Long: `Export the annotations of a datastore (accepted and rejected findings and
+matches, with comments) to JSON, and import them into another datastore.
+
+Analysts triaging copies of the same datastore can merge their decisions:
+each exports their annotations and imports the others'. When both sides
+annotated the same finding or match, the most recent annotation wins.`,
+}
+
+var annotationsExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write the annotations of a datastore as JSON",
+	Long: `Write every annotation of a datastore as JSON, with its finding ID (and
+match ID for match annotations), status, comment, author and timestamp.
+
+Examples:
+  titus annotations export --datastore titus.ds > alice.json
+  titus annotations export --datastore titus.ds -o alice.json`,
+	Args: cobra.NoArgs,
+	RunE: runAnnotationsExport,
+}
+
+var annotationsImportCmd = &cobra.Command{
+	Use:   "import <file>...",
+	Short: "Merge exported annotations into a datastore",
+	Long: `Merge annotations written by 'titus annotations export' into a datastore.
+
+An imported annotation replaces the datastore's annotation of the same
+finding or match only if it is more recent. Annotations of findings and
+matches the datastore doesn't have are skipped. Accepting or rejecting a
+finding moves it to the triaged state, as in 'titus explore'.
+
+Examples:
+  titus annotations import --datastore titus.ds alice.json bob.json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runAnnotationsImport,
+}
+
+func init() {
+	annotationsCmd.PersistentFlags().StringVar(&annotationsDatastore, "datastore", "titus.ds", "Path to datastore directory or file")
+	annotationsExportCmd.Flags().StringVarP(&annotationsOutput, "output", "o", "", "Write to this file instead of stdout")
+	annotationsCmd.AddCommand(annotationsExportCmd)
+	annotationsCmd.AddCommand(annotationsImportCmd)
+	rootCmd.AddCommand(annotationsCmd)
+}
+
+// annotationsFile is the JSON document written by 'annotations export'.
+type annotationsFile struct {
+	Version     int                  `json:"version"`
+	ExportedAt  time.Time            `json:"exported_at"`
+	Annotations []exportedAnnotation `json:"annotations"`
+}
+
+// exportedAnnotation is one annotation in an annotationsFile.
+type exportedAnnotation struct {
+	Type      string    `json:"type"` // "finding" or "match"
+	FindingID string    `json:"finding_id"`
+	MatchID   string    `json:"match_id,omitempty"` // structural ID, for match annotations
+	Status    string    `json:"status,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	Author    string    `json:"author,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// annotationAuthor returns the author recorded for annotations made
+// interactively: flag if set, else the current user's login name.
+func annotationAuthor(flag string) string {
+	if flag != "" {
+		return flag
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+func runAnnotationsExport(cmd *cobra.Command, args []string) error {
+	s, err := openDatastore(annotationsDatastore)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	doc, err := exportAnnotations(s)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if annotationsOutput != "" {
+		f, err := os.Create(annotationsOutput)
+		if err != nil {
+			return fmt.Errorf("creating output file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("writing annotations: %w", err)
+	}
+	if annotationsOutput != "" {
+		statusf(cmd.ErrOrStderr(), "Exported %d annotations to %s\n", len(doc.Annotations), annotationsOutput)
+	}
+	return nil
+}
+
+// exportAnnotations reads the annotations of s. A match annotation is
+// listed under the finding its match belongs to.
+func exportAnnotations(s store.Store) (*annotationsFile, error) {
+	annotations, err := s.GetAnnotations()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving annotations: %w", err)
+	}
+	findings, err := s.GetFindings()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving findings: %w", err)
+	}
+	matches, err := s.GetAllMatches()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving matches: %w", err)
+	}
+	findingOfMatch := make(map[string]string, len(matches))
+	for findingID, fMatches := range buildFindingMatchMap(findings, matches) {
+		for _, m := range fMatches {
+			findingOfMatch[m.StructuralID] = findingID
+		}
+	}
+
+	doc := &annotationsFile{
+		Version:     annotationsFileVersion,
+		ExportedAt:  time.Now().UTC(),
+		Annotations: []exportedAnnotation{},
+	}
+	for _, a := range annotations {
+		e := exportedAnnotation{
+			Type:      a.TargetType,
+			FindingID: a.TargetID,
+			Status:    a.Status,
+			Comment:   a.Comment,
+			Author:    a.Author,
+			Timestamp: a.UpdatedAt.UTC(),
+		}
+		if a.TargetType == "match" {
+			e.FindingID, e.MatchID = findingOfMatch[a.TargetID], a.TargetID
+		}
+		doc.Annotations = append(doc.Annotations, e)
+	}
+	return doc, nil
+}
+
+func runAnnotationsImport(cmd *cobra.Command, args []string) error {
+	s, err := openDatastore(annotationsDatastore)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	var imported []exportedAnnotation
+	for _, path := range args {
+		doc, err := readAnnotationsFile(path)
+		if err != nil {
+			return err
+		}
+		imported = append(imported, doc.Annotations...)
+	}
+
+	var result annotationImport
+	err = s.ExecBatch(func(tx store.Store) error {
+		var err error
+		result, err = importAnnotations(tx, imported)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Imported %d of %d annotations", result.imported, len(imported))
+	if result.older > 0 {
+		fmt.Fprintf(out, ", kept %d more recent local ones", result.older)
+	}
+	if result.unknown > 0 {
+		fmt.Fprintf(out, ", skipped %d of findings or matches not in the datastore", result.unknown)
+	}
+	fmt.Fprintln(out)
+	return nil
+}
+
+// readAnnotationsFile reads a file written by 'annotations export'.
+func readAnnotationsFile(path string) (*annotationsFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening annotations file: %w", err)
+	}
+	defer f.Close()
+	var doc annotationsFile
+	if err := json.NewDecoder(f).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if doc.Version != annotationsFileVersion {
+		return nil, fmt.Errorf("%s: unsupported annotations file version %d", path, doc.Version)
+	}
+	return &doc, nil
+}
+
+// annotationImport counts what importAnnotations did.
+type annotationImport struct {
+	imported int // annotations written to the datastore
+	older    int // annotations not more recent than the datastore's
+	unknown  int // annotations of findings or matches the datastore lacks
+}
+
+// importAnnotations merges annotations into s. Each imported annotation
+// replaces the annotation of its target in s if it is more recent; of
+// several imported annotations of one target, the most recent wins.
+func importAnnotations(s store.Store, imported []exportedAnnotation) (annotationImport, error) {
+	var result annotationImport
+
+	findings, err := s.GetFindings()
+	if err != nil {
+		return result, fmt.Errorf("retrieving findings: %w", err)
+	}
+	findingsByID := make(map[string]*types.Finding, len(findings))
+	for _, f := range findings {
+		findingsByID[f.ID] = f
+	}
+	matches, err := s.GetAllMatches()
+	if err != nil {
+		return result, fmt.Errorf("retrieving matches: %w", err)
+	}
+	matchIDs := make(map[string]bool, len(matches))
+	for _, m := range matches {
+		matchIDs[m.StructuralID] = true
+	}
+
+	local, err := s.GetAnnotations()
+	if err != nil {
+		return result, fmt.Errorf("retrieving annotations: %w", err)
+	}
+	latest := make(map[[2]string]time.Time, len(local))
+	for _, a := range local {
+		latest[[2]string{a.TargetType, a.TargetID}] = a.UpdatedAt
+	}
+
+	for _, e := range imported {
+		a := &types.Annotation{
+			TargetType: e.Type,
+			TargetID:   e.FindingID,
+			Status:     e.Status,
+			Comment:    e.Comment,
+			Author:     e.Author,
+			UpdatedAt:  e.Timestamp,
+		}
+		switch e.Type {
+		case "finding":
+			if findingsByID[a.TargetID] == nil {
+				result.unknown++
+				continue
+			}
+		case "match":
+			a.TargetID = e.MatchID
+			if !matchIDs[a.TargetID] {
+				result.unknown++
+				continue
+			}
+		default:
+			return result, fmt.Errorf("unknown annotation type %q", e.Type)
+		}
+
+		key := [2]string{a.TargetType, a.TargetID}
+		if at, ok := latest[key]; ok && !a.UpdatedAt.After(at) {
+			result.older++
+			continue
+		}
+		if err := s.PutAnnotation(a); err != nil {
+			return result, fmt.Errorf("saving annotation: %w", err)
+		}
+		latest[key] = a.UpdatedAt
+		result.imported++
+
+		if f := findingsByID[a.TargetID]; a.TargetType == "finding" && a.Status != "" {
+			if state := f.State.Triaged(); state != f.State {
+				if err := s.SetFindingState(f.ID, state); err != nil {
+					return result, fmt.Errorf("updating finding state: %w", err)
+				}
+				f.State = state
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/praetorian-inc/titus/pkg/enum"
+	"github.com/praetorian-inc/titus/pkg/filetype"
+	"github.com/praetorian-inc/titus/pkg/matcher"
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// scanEnumerator scans everything an enumerator of a hosted service yields,
+// stores the results at outputPath and prints them in format (json or human).
+// source names the service in the completion message.
+func scanEnumerator(cmd *cobra.Command, e enum.Enumerator, source, outputPath, format string) error {
+	rules, err := loadRules("", "", "", scanRuleset)
+	if err != nil {
+		return fmt.Errorf("loading rules: %w", err)
+	}
+	ruleMap := make(map[string]*types.Rule)
+	for _, r := range rules {
+		ruleMap[r.ID] = r
+	}
+
+	m, err := matcher.New(matcher.Config{
+		Rules:        rules,
+		ContextLines: 3,
+	})
+	if err != nil {
+		return fmt.Errorf("creating matcher: %w", err)
+	}
+	defer m.Close()
+
+	s, err := store.New(store.Config{Path: outputPath})
+	if err != nil {
+		return fmt.Errorf("creating store: %w", err)
+	}
+	defer s.Close()
+	for _, r := range rules {
+		if err := s.AddRule(r); err != nil {
+			return fmt.Errorf("storing rule: %w", err)
+		}
+	}
+	run, err := beginScan(s, strings.ToLower(source), rules)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	matchCount := 0
+	findingCount := 0
+	progress := newScanProgress(cmd.ErrOrStderr())
+	// Findings are marked seen, but the service's documents aren't paths a
+	// later scan can be checked against, so none are marked remediated
+	lifecycle := newScanLifecycle("", false)
+	lifecycle.partial = true
+	err = e.Enumerate(ctx, func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		lifecycle.see(blobID)
+		matches, err := m.MatchWithBlobID(content, blobID)
+		if err != nil {
+			return fmt.Errorf("matching content: %w", err)
+		}
+		setLineColumns(content, matches)
+		progress.blob(blobID, prov, len(content), matches)
+		return s.ExecBatch(func(tx store.Store) error {
+			created, err := recordBlob(tx, ruleMap, blobID, filetype.Tag(prov, content), int64(len(content)), matches)
+			matchCount += len(matches)
+			findingCount += len(created)
+			return err
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("scanning %s: %w", source, err)
+	}
+
+	if _, _, err := lifecycle.update(s, ruleMap); err != nil {
+		return fmt.Errorf("updating finding states: %w", err)
+	}
+	if err := finishScan(s, run); err != nil {
+		return err
+	}
+	progress.printRuleStats(ruleMap)
+	statusf(cmd.OutOrStdout(), "%s scan complete: %d matches, %d findings\n", source, matchCount, findingCount)
+	statusf(cmd.OutOrStdout(), "Results stored in: %s\n", outputPath)
+
+	if format == "json" {
+		matches, err := s.GetAllMatches()
+		if err != nil {
+			return fmt.Errorf("retrieving matches: %w", err)
+		}
+		newResultOrder(s).sortMatches(matches)
+		return outputMatches(cmd, matches)
+	}
+
+	findings, err := s.GetFindings()
+	if err != nil {
+		return fmt.Errorf("retrieving findings: %w", err)
+	}
+	newResultOrder(s).sortFindings(findings, nil)
+	return outputFindings(cmd, findings)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/praetorian-inc/titus/pkg/enum"
+	"github.com/spf13/cobra"
+)
+
+var (
+	artifactoryURL          string
+	artifactoryUser         string
+	artifactoryToken        string
+	artifactoryMaxFileSize  int64
+	artifactoryExtract      string
+	artifactoryOutputPath   string
+	artifactoryOutputFormat string
+)
+
+var artifactoryCmd = &cobra.Command{
+	Use:   "artifactory [repo-key...]",
+	Short: "Scan JFrog Artifactory repositories for secrets",
+	Long: `Scan the artifacts in JFrog Artifactory repositories via the REST API.
+Without repository keys, every local repository the token can read is scanned;
+name remote or virtual repositories to scan them too.
+
+Build artifacts often embed credentials injected at CI time. Jars, wars, npm
+tarballs and other archives are extracted and their members scanned.
+
+Authentication:
+  An access token in --token (or ARTIFACTORY_TOKEN), sent as a bearer token.
+  Or --user (or ARTIFACTORY_USER) with an API key or password in --token.
+
+Findings name the artifact's URL, plus the member for archives.
+
+Examples:
+  titus artifactory --url https://acme.jfrog.io/artifactory --token $TOKEN
+  titus artifactory --url https://artifacts.corp.com/artifactory --user ci --token $API_KEY libs-release-local`,
+	RunE: runArtifactoryScan,
+}
+
+func init() {
+	artifactoryCmd.Flags().StringVar(&artifactoryURL, "url", "", "Artifactory URL (or ARTIFACTORY_URL env; e.g., https://acme.jfrog.io/artifactory)")
+	artifactoryCmd.Flags().StringVar(&artifactoryUser, "user", "", "User for an API key or password (or ARTIFACTORY_USER env)")
+	artifactoryCmd.Flags().StringVar(&artifactoryToken, "token", "", "Access token, API key or password (or ARTIFACTORY_TOKEN env)")
+	artifactoryCmd.Flags().Int64Var(&artifactoryMaxFileSize, "max-file-size", 100*1024*1024, "Maximum artifact size to download (bytes)")
+	artifactoryCmd.Flags().StringVar(&artifactoryExtract, "extract", "all", "Extract text from binary artifacts (extensions: jar,war,tgz,zip or 'all'; empty to disable)")
+	artifactoryCmd.Flags().StringVar(&artifactoryOutputPath, "output", "titus.db", "Output database path (:memory: for in-memory)")
+	artifactoryCmd.Flags().StringVar(&artifactoryOutputFormat, "format", "human", "Output format: json, human")
+
+	rootCmd.AddCommand(artifactoryCmd)
+}
+
+func runArtifactoryScan(cmd *cobra.Command, args []string) error {
+	baseURL := artifactoryURL
+	if baseURL == "" {
+		baseURL = os.Getenv("ARTIFACTORY_URL")
+	}
+	user := artifactoryUser
+	if user == "" {
+		user = os.Getenv("ARTIFACTORY_USER")
+	}
+	token := artifactoryToken
+	if token == "" {
+		token = os.Getenv("ARTIFACTORY_TOKEN")
+	}
+
+	if baseURL == "" {
+		return fmt.Errorf("must specify the Artifactory URL: use --url or ARTIFACTORY_URL")
+	}
+	insecure, err := enum.ValidateBaseURL(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid --url: %w", err)
+	}
+	if insecure && token != "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "WARNING: Using HTTP with an API token. Your token will be sent in plaintext.\n")
+	}
+	if token == "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Note: No Artifactory token provided. Only anonymously readable repositories will be scanned.\n\n")
+	}
+
+	e, err := enum.NewArtifactoryEnumerator(enum.ArtifactoryConfig{
+		BaseURL: baseURL,
+		User:    user,
+		Token:   token,
+		Repos:   args,
+		Config: enum.Config{
+			MaxFileSize:     artifactoryMaxFileSize,
+			ExtractArchives: artifactoryExtract,
+			ExtractLimits:   enum.DefaultExtractionLimits(),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("creating Artifactory client: %w", err)
+	}
+	return scanEnumerator(cmd, e, "Artifactory", artifactoryOutputPath, artifactoryOutputFormat)
+}
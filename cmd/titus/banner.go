@@ -3,8 +3,6 @@ package main
 import (
 	"fmt"
 	"os"
-
-	"golang.org/x/term"
 )
 
 // ANSI color codes
@@ -27,9 +25,7 @@ const banner = `
 const tagline = " There's always something."
 const credit = " Praetorian Security, Inc."
 
-func printBanner() {
-	useColor := term.IsTerminal(int(os.Stderr.Fd()))
-
+func printBanner(useColor bool) {
 	if useColor {
 		fmt.Fprintf(os.Stderr, "%s%s%s%s", colorBold, colorRed, banner, colorReset)
 		fmt.Fprintf(os.Stderr, "%s%s%s%s\n", colorBold, colorRed, tagline, colorReset)
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/matcher"
+	matcherbench "github.com/praetorian-inc/titus/pkg/matcher/bench"
+	"github.com/spf13/cobra"
+)
+
+var (
+	perfBackends          []string
+	perfCorpus            string
+	perfCorpusSize        int
+	perfMaxFileSize       int64
+	perfIterations        int
+	perfPerRule           bool
+	perfTopRules          int
+	perfRulesPath         string
+	perfRulesInclude      string
+	perfRulesExclude      string
+	perfCategories        string
+	perfExcludeCategories string
+	perfRuleset           string
+	perfRulePacks         string
+	perfRulePackDirs      []string
+	perfFormat            string
+	perfMinThroughput     float64
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Measure matcher throughput on a corpus",
+	Long: `Scan a corpus with each matcher backend and report throughput (MB/s),
+allocations per pass and, with --per-rule, the time each rule takes on its own.
+
+The bundled corpus is generated from a fixed seed, so results are comparable
+between runs and releases. It mixes source files, a minified bundle, files
+dense with credentials, non-ASCII text and logs. Use --corpus to scan a
+directory instead.
+
+Backends available depend on the build: portable and regexp always, and
+vectorscan when built with -tags vectorscan.
+
+Use --min-throughput to fail with a non-zero exit code, e.g. as a performance
+regression gate in CI before release.
+
+Examples:
+  titus bench
+  titus bench --backend portable --per-rule --top 20
+  titus bench --corpus ./testdata --ruleset all --format json
+  titus bench --min-throughput 0.5`,
+	Args: cobra.NoArgs,
+	RunE: runBench,
+}
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+	benchCmd.Flags().StringSliceVar(&perfBackends, "backend", nil, "Matcher backends to measure (comma-separated; default: all in this build)")
+	benchCmd.Flags().StringVar(&perfCorpus, "corpus", "", "Directory to scan instead of the bundled corpus")
+	benchCmd.Flags().IntVar(&perfCorpusSize, "corpus-size", 1, "Size of the bundled corpus in MB")
+	benchCmd.Flags().Int64Var(&perfMaxFileSize, "max-file-size", 10*1024*1024, "Skip --corpus files larger than this (bytes)")
+	benchCmd.Flags().IntVar(&perfIterations, "iterations", 1, "Passes over the corpus per backend")
+	benchCmd.Flags().BoolVar(&perfPerRule, "per-rule", false, "Also time each rule on its own")
+	benchCmd.Flags().IntVar(&perfTopRules, "top", 10, "Slowest rules to show with --per-rule (0 = all)")
+	benchCmd.Flags().StringVar(&perfRulesPath, "rules", "", "Path to custom rules file, or https:// URL of a rule pack .tar.gz")
+	benchCmd.Flags().StringVar(&perfRulesInclude, "rules-include", "", "Include rules matching regex pattern (comma-separated)")
+	benchCmd.Flags().StringVar(&perfRulesExclude, "rules-exclude", "", "Exclude rules matching regex pattern (comma-separated)")
+	benchCmd.Flags().StringVar(&perfCategories, "categories", "", "Only use rules with any of these categories or tags (comma-separated)")
+	benchCmd.Flags().StringVar(&perfExcludeCategories, "exclude-categories", "", "Skip rules with any of these categories or tags (comma-separated)")
+	benchCmd.Flags().StringVar(&perfRuleset, "ruleset", "default", "Ruleset to use: default, np.assets, np.hashes, all (all = no filtering)")
+	benchCmd.Flags().StringVar(&perfRulePacks, "rule-packs", "", "Rule packs to use instead of --ruleset (comma-separated name or name@version)")
+	benchCmd.Flags().StringSliceVar(&perfRulePackDirs, "rule-pack-dir", nil, "Directory containing an external rule pack (repeatable)")
+	benchCmd.Flags().StringVar(&perfFormat, "format", "table", "Output format: table, json")
+	benchCmd.Flags().Float64Var(&perfMinThroughput, "min-throughput", 0, "Fail if any backend scans slower than this many MB/s")
+}
+
+func runBench(cmd *cobra.Command, args []string) error {
+	if perfFormat != "table" && perfFormat != "json" {
+		return fmt.Errorf("unknown output format: %s", perfFormat)
+	}
+
+	backends := matcher.Backends()
+	if len(perfBackends) > 0 {
+		backends = perfBackends
+	}
+
+	var corpus []matcherbench.Blob
+	if perfCorpus != "" {
+		var err error
+		if corpus, err = matcherbench.LoadCorpus(perfCorpus, perfMaxFileSize); err != nil {
+			return err
+		}
+	} else {
+		if perfCorpusSize <= 0 {
+			return fmt.Errorf("--corpus-size must be positive")
+		}
+		corpus = matcherbench.BundledCorpus(perfCorpusSize << 20)
+	}
+
+	rules, err := loadRuleSelection(perfRulesPath, ruleFilter(perfRulesInclude, perfRulesExclude, perfCategories, perfExcludeCategories), perfRuleset, perfRulePacks, perfRulePackDirs)
+	if err != nil {
+		return fmt.Errorf("loading rules: %w", err)
+	}
+
+	var results []*matcherbench.Result
+	for _, backend := range backends {
+		result, err := matcherbench.Run(backend, rules, corpus, matcherbench.Options{
+			Iterations: perfIterations,
+			PerRule:    perfPerRule,
+		})
+		if err != nil {
+			return fmt.Errorf("benchmarking %s: %w", backend, err)
+		}
+		results = append(results, result)
+	}
+
+	if perfFormat == "json" {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		printThroughputReport(cmd, results, len(rules))
+	}
+
+	for _, r := range results {
+		if r.MBPerSec() < perfMinThroughput {
+			return fmt.Errorf("%s throughput %.2f MB/s is below --min-throughput %.2f", r.Backend, r.MBPerSec(), perfMinThroughput)
+		}
+	}
+	return nil
+}
+
+// printThroughputReport writes one row per backend, then the slowest rules
+// of each backend timed with --per-rule.
+func printThroughputReport(cmd *cobra.Command, results []*matcherbench.Result, ruleCount int) {
+	out := cmd.OutOrStdout()
+	if len(results) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "Rules: %d  Corpus: %d blobs, %.1f MB  Iterations: %d\n\n",
+		ruleCount, results[0].Blobs, float64(results[0].Bytes)/(1<<20), results[0].Iterations)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Backend\tMB/s\tTime/pass\tSetup\tMatches\tAllocs/pass\tAlloc MB/pass\n")
+	fmt.Fprintf(w, "-------\t----\t---------\t-----\t-------\t-----------\t-------------\n")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%.2f\t%s\t%s\t%d\t%d\t%.1f\n", r.Backend, r.MBPerSec(),
+			r.Duration.Round(time.Millisecond), r.Setup.Round(time.Millisecond), r.Matches, r.Allocs, float64(r.AllocBytes)/(1<<20))
+	}
+	w.Flush()
+
+	for _, r := range results {
+		if len(r.Rules) == 0 {
+			continue
+		}
+		rules := r.Rules
+		if perfTopRules > 0 && len(rules) > perfTopRules {
+			rules = rules[:perfTopRules]
+		}
+		fmt.Fprintf(out, "\nSlowest rules (%s):\n", r.Backend)
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "ID\tName\tTime\tMatches\n")
+		fmt.Fprintf(w, "--\t----\t----\t-------\n")
+		for _, t := range rules {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", t.RuleID, t.RuleName, t.Duration.Round(time.Microsecond), t.Matches)
+		}
+		w.Flush()
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	matcherbench "github.com/praetorian-inc/titus/pkg/matcher/bench"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBench(t *testing.T) {
+	perfBackends = []string{"portable"}
+	perfRulesInclude = `^np\.github\.`
+	perfCorpusSize = 1
+	perfPerRule = true
+	defer func() {
+		perfBackends, perfRulesInclude, perfPerRule, perfFormat, perfMinThroughput = nil, "", false, "table", 0
+	}()
+
+	var buf bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&buf)
+	require.NoError(t, runBench(cmd, nil))
+	assert.Contains(t, buf.String(), "Corpus: 5 blobs, 1.0 MB")
+	assert.Contains(t, buf.String(), "Slowest rules (portable):")
+
+	perfFormat = "json"
+	buf.Reset()
+	require.NoError(t, runBench(cmd, nil))
+	var results []matcherbench.Result
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &results))
+	require.Len(t, results, 1)
+	assert.Equal(t, "portable", results[0].Backend)
+	assert.NotEmpty(t, results[0].Rules)
+
+	// An unreachable throughput floor trips the regression gate
+	perfMinThroughput = 1e9
+	assert.ErrorContains(t, runBench(cmd, nil), "--min-throughput")
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/bitbucket"
+	"github.com/praetorian-inc/titus/pkg/rule"
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bitbucketDatastore string
+	bitbucketWorkspace string
+	bitbucketRepo      string
+	bitbucketCommit    string
+	bitbucketToken     string
+	bitbucketBaseURL   string
+	bitbucketReportID  string
+)
+
+// bitbucketPipelinesProxy is the proxy Bitbucket Pipelines runs alongside
+// each step, which authenticates Code Insights requests for the step's
+// repository. It only accepts plain HTTP.
+const (
+	bitbucketPipelinesProxy   = "http://localhost:29418"
+	bitbucketPipelinesBaseURL = "http://api.bitbucket.org/2.0"
+)
+
+var bitbucketInsightsCmd = &cobra.Command{
+	Use:   "bitbucket-insights",
+	Short: "Publish findings as a Bitbucket Code Insights report",
+	Long: `Publish the findings in a datastore as a Code Insights report on a Bitbucket
+Cloud commit, with an annotation at each secret's file and line. Bitbucket shows
+the report on the commit and the annotations inline in its pull requests.
+
+The report is replaced on each run, so rerunning a pipeline doesn't duplicate
+annotations. It fails if any secret was found that isn't revoked or likely test
+data; active (validated live) secrets are critical, unverified ones high, and
+revoked secrets and likely test data low and ignored. Secret values are never
+sent.
+
+In Bitbucket Pipelines, --workspace, --repo and --commit default to the
+pipeline's repository and commit, and no token is needed: requests go through
+the Pipelines authentication proxy. Elsewhere use --token or BITBUCKET_TOKEN
+with a repository access token that has the pullrequest scope.
+
+Examples:
+  titus scan . && titus bitbucket-insights
+  titus bitbucket-insights --workspace acme --repo api --commit 1a2b3c4`,
+	RunE: runBitbucketInsights,
+}
+
+func init() {
+	bitbucketInsightsCmd.Flags().StringVar(&bitbucketDatastore, "datastore", "titus.ds", "Path to datastore directory or file")
+	bitbucketInsightsCmd.Flags().StringVar(&bitbucketWorkspace, "workspace", "", "Bitbucket workspace (default: BITBUCKET_WORKSPACE)")
+	bitbucketInsightsCmd.Flags().StringVar(&bitbucketRepo, "repo", "", "Repository slug (default: BITBUCKET_REPO_SLUG)")
+	bitbucketInsightsCmd.Flags().StringVar(&bitbucketCommit, "commit", "", "Commit hash to report on (default: BITBUCKET_COMMIT)")
+	bitbucketInsightsCmd.Flags().StringVar(&bitbucketToken, "token", "", "Bitbucket access token (or BITBUCKET_TOKEN env; optional in Pipelines)")
+	bitbucketInsightsCmd.Flags().StringVar(&bitbucketBaseURL, "url", "", "Bitbucket API base URL (default: "+bitbucket.DefaultBaseURL+")")
+	bitbucketInsightsCmd.Flags().StringVar(&bitbucketReportID, "report-id", "titus", "ID of the report, unique per commit")
+	rootCmd.AddCommand(bitbucketInsightsCmd)
+}
+
+func runBitbucketInsights(cmd *cobra.Command, args []string) error {
+	workspace := flagOrEnv(bitbucketWorkspace, "BITBUCKET_WORKSPACE")
+	repo := flagOrEnv(bitbucketRepo, "BITBUCKET_REPO_SLUG")
+	commit := flagOrEnv(bitbucketCommit, "BITBUCKET_COMMIT")
+	if workspace == "" || repo == "" || commit == "" {
+		return fmt.Errorf("must specify --workspace, --repo and --commit outside Bitbucket Pipelines")
+	}
+
+	client, err := bitbucketClient(flagOrEnv(bitbucketToken, "BITBUCKET_TOKEN"), bitbucketBaseURL)
+	if err != nil {
+		return err
+	}
+
+	s, err := openDatastore(bitbucketDatastore)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	report, annotations, err := buildBitbucketReport(s)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	published, err := client.Publish(ctx, workspace, repo, commit, bitbucketReportID, report, annotations)
+	if err != nil {
+		return fmt.Errorf("publishing Code Insights report: %w", err)
+	}
+	statusf(cmd.ErrOrStderr(), "Published Code Insights report %q on %s/%s@%s with %d annotations\n",
+		bitbucketReportID, workspace, repo, shortID(commit), published)
+	if published < len(annotations) {
+		statusf(cmd.ErrOrStderr(), "%d annotations not published (Bitbucket keeps at most %d per report)\n",
+			len(annotations)-published, bitbucket.MaxAnnotations)
+	}
+	return nil
+}
+
+// flagOrEnv returns a flag's value, or the environment variable's if the
+// flag wasn't set.
+func flagOrEnv(flagValue, envVar string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	return os.Getenv(envVar)
+}
+
+// bitbucketClient creates a client authenticating with token, or through
+// the Pipelines proxy when there's no token and Titus runs in Pipelines.
+func bitbucketClient(token, baseURL string) (*bitbucket.Client, error) {
+	if token != "" {
+		return bitbucket.NewClient(baseURL, token, nil), nil
+	}
+	if os.Getenv("BITBUCKET_BUILD_NUMBER") == "" {
+		return nil, fmt.Errorf("a Bitbucket token is required outside Pipelines: use --token or BITBUCKET_TOKEN")
+	}
+
+	proxy, _ := url.Parse(bitbucketPipelinesProxy)
+	httpClient := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxy)}}
+	if baseURL == "" {
+		baseURL = bitbucketPipelinesBaseURL
+	}
+	return bitbucket.NewClient(baseURL, "", httpClient), nil
+}
+
+// buildBitbucketReport builds the report of a datastore's findings and an
+// annotation for each place a match was found, most severe first.
+func buildBitbucketReport(s store.Store) (bitbucket.Report, []bitbucket.Annotation, error) {
+	findings, err := s.GetFindings()
+	if err != nil {
+		return bitbucket.Report{}, nil, fmt.Errorf("retrieving findings: %w", err)
+	}
+	matches, err := s.GetAllMatches()
+	if err != nil {
+		return bitbucket.Report{}, nil, fmt.Errorf("retrieving matches: %w", err)
+	}
+	rules, err := rule.NewLoader().LoadBuiltinRules()
+	if err != nil {
+		return bitbucket.Report{}, nil, fmt.Errorf("loading rules: %w", err)
+	}
+	ruleMap := make(map[string]*types.Rule)
+	for _, r := range rules {
+		ruleMap[r.ID] = r
+	}
+
+	matches = reportableMatches(matches, ruleMap)
+	newResultOrder(s).sortMatches(matches)
+	flagLikelyFalsePositives(s, matches)
+	newBlobLocations(s, 0).attach(matches)
+	matchesByFinding := buildFindingMatchMap(findings, matches)
+
+	var annotations []bitbucket.Annotation
+	for _, m := range matches {
+		locations := m.Locations
+		if len(locations) == 0 {
+			locations = []types.BlobLocation{{}}
+		}
+		seen := make(map[string]bool)
+		for _, loc := range locations {
+			path := bitbucketPath(loc.Path)
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			annotations = append(annotations, bitbucketAnnotation(m, ruleMap, path))
+		}
+	}
+
+	active, actionable := 0, 0
+	for _, f := range findings {
+		if activeFinding(f, matchesByFinding) {
+			active++
+		}
+		if actionableFinding(f, matchesByFinding) {
+			actionable++
+		}
+	}
+	report := bitbucket.Report{
+		Title:      "Titus secrets scan",
+		ReportType: "SECURITY",
+		Reporter:   "Titus",
+		Link:       "https://github.com/praetorian-inc/titus",
+		Result:     bitbucket.ResultPassed,
+		Details:    "No secrets found.",
+		Data: []bitbucket.DataField{
+			{Title: "Findings", Type: "NUMBER", Value: len(findings)},
+			{Title: "Active secrets", Type: "NUMBER", Value: active},
+		},
+	}
+	switch {
+	case actionable > 0:
+		report.Result = bitbucket.ResultFailed
+		report.Details = fmt.Sprintf("Titus found %d secrets, %d confirmed live by validation. Revoke and rotate them, then remove them from the code.", len(findings), active)
+	case len(findings) > 0:
+		report.Details = fmt.Sprintf("Titus found %d secrets, all revoked or likely test data.", len(findings))
+	}
+	return report, annotations, nil
+}
+
+// actionableFinding reports whether any of f's matches, in matchesByFinding
+// or f.Matches, still needs fixing.
+func actionableFinding(f *types.Finding, matchesByFinding map[string][]*types.Match) bool {
+	for _, m := range matchesByFinding[f.ID] {
+		if actionableMatch(m) {
+			return true
+		}
+	}
+	for _, m := range f.Matches {
+		if actionableMatch(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// actionableMatch reports whether m's secret still needs fixing: unless
+// validation confirmed it live, a revoked secret or likely test data doesn't.
+func actionableMatch(m *types.Match) bool {
+	if m.Active() {
+		return true
+	}
+	revoked := m.ValidationResult != nil && m.ValidationResult.Status == types.StatusInvalid
+	return !revoked && !m.LikelyFP
+}
+
+// bitbucketAnnotation describes a match found at path, without its secret.
+func bitbucketAnnotation(m *types.Match, ruleMap map[string]*types.Rule, path string) bitbucket.Annotation {
+	name := summaryRuleName(m.RuleID, ruleMap)
+	a := bitbucket.Annotation{
+		ExternalID:     bitbucketExternalID(m, path),
+		AnnotationType: "VULNERABILITY",
+		Summary:        name + " detected",
+		Path:           path,
+		Line:           m.Location.Source.Start.Line,
+		Severity:       bitbucket.SeverityHigh,
+		Result:         bitbucket.ResultFailed,
+	}
+
+	var details []string
+	if r, ok := ruleMap[m.RuleID]; ok && r.Description != "" {
+		details = append(details, r.Description)
+	}
+	switch {
+	case m.Active():
+		a.Severity = bitbucket.SeverityCritical
+		a.Summary = "Active " + name + " detected"
+	case m.ValidationResult != nil && m.ValidationResult.Status == types.StatusInvalid:
+		a.Severity = bitbucket.SeverityLow
+		a.Result = bitbucket.ResultIgnored
+	case m.LikelyFP:
+		a.Severity = bitbucket.SeverityLow
+		a.Result = bitbucket.ResultIgnored
+		details = append(details, "Likely test data: "+strings.Join(m.FPReasons, ", "))
+	}
+	if m.ValidationResult != nil {
+		details = append(details, "Validation: "+formatValidation(m.ValidationResult))
+	}
+	details = append(details, "Rule "+m.RuleID+", finding "+shortID(m.FindingID))
+	a.Details = strings.Join(details, "\n")
+	return a
+}
+
+// bitbucketExternalID identifies a match at a path within a report.
+func bitbucketExternalID(m *types.Match, path string) string {
+	sum := sha256.Sum256([]byte(m.StructuralID + "\x00" + path))
+	return "titus-" + hex.EncodeToString(sum[:12])
+}
+
+// bitbucketPath returns a path relative to the repository root, as
+// Bitbucket locates annotations: slash-separated, without a leading "./",
+// and relative to the working directory if absolute. It returns "" for
+// paths outside the working directory, whose annotations then apply to the
+// commit as a whole.
+func bitbucketPath(path string) string {
+	if filepath.IsAbs(path) {
+		wd, err := os.Getwd()
+		if err != nil {
+			return ""
+		}
+		rel, err := filepath.Rel(wd, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return ""
+		}
+		path = rel
+	}
+	path = filepath.ToSlash(path)
+	for strings.HasPrefix(path, "./") {
+		path = path[2:]
+	}
+	return path
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/praetorian-inc/titus/pkg/enum"
+)
+
+// printCloneFailures lists the repositories a scan had to skip, and why, so
+// a partial scan of an organization isn't mistaken for a complete one.
+func printCloneFailures(w io.Writer, failures []enum.RepoFailure) {
+	if len(failures) == 0 {
+		return
+	}
+	noun := "repositories"
+	if len(failures) == 1 {
+		noun = "repository"
+	}
+	fmt.Fprintf(w, "\n%d %s could not be scanned:\n", len(failures), noun)
+	for _, f := range failures {
+		fmt.Fprintf(w, "  %s: %v\n", f.Repo.Name, f.Err)
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/enum"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintCloneFailures(t *testing.T) {
+	var out bytes.Buffer
+	printCloneFailures(&out, nil)
+	assert.Empty(t, out.String())
+
+	printCloneFailures(&out, []enum.RepoFailure{
+		{Repo: enum.RepoInfo{Name: "acme/api"}, Err: errors.New("cloning acme/api (3 attempts): exit status 128: Could not resolve host: github.com")},
+		{Repo: enum.RepoInfo{Name: "acme/old"}, Err: errors.New("cloning acme/old: exit status 128: repository not found")},
+	})
+	assert.Contains(t, out.String(), "2 repositories could not be scanned:")
+	assert.Contains(t, out.String(), "  acme/api: cloning acme/api (3 attempts)")
+	assert.Contains(t, out.String(), "  acme/old: cloning acme/old: exit status 128: repository not found")
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/praetorian-inc/titus/pkg/rule"
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/style"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	compareFormat string
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <old-datastore> <new-datastore>",
+	Short: "Compare findings between two datastores",
+	Long: `Compare two datastores, such as last month's scan and today's scan of the same
+estate, and report which findings are new, which were resolved and which
+persist, with per-rule deltas.
+
+Findings are matched by ID, which is derived from the rule and the secret
+itself, so a secret that moved between files still counts as persisting.
+Findings a later scan marked remediated count as absent; new findings that
+were remediated before are flagged as regressions.
+
+Examples:
+  titus compare scans/2024-05.ds scans/2024-06.ds
+  titus compare old.ds titus.ds --format json`,
+	Args: cobra.ExactArgs(2),
+	RunE: runCompare,
+}
+
+func init() {
+	compareCmd.Flags().StringVar(&compareFormat, "format", "human", "Output format: human, json")
+	rootCmd.AddCommand(compareCmd)
+}
+
+// compareResult is the difference between two datastores' findings.
+type compareResult struct {
+	Old        string            `json:"old"`
+	New        string            `json:"new"`
+	Added      []comparedFinding `json:"new_findings"`
+	Resolved   []comparedFinding `json:"resolved_findings"`
+	Persisting []comparedFinding `json:"persisting_findings"`
+	Rules      []ruleDelta       `json:"rules"`
+}
+
+// comparedFinding identifies a finding in a comparison.
+type comparedFinding struct {
+	ID       string             `json:"id"`
+	RuleID   string             `json:"rule_id"`
+	RuleName string             `json:"rule_name"`
+	Path     string             `json:"path,omitempty"` // location of the finding's first match
+	State    types.FindingState `json:"state,omitempty"`
+}
+
+// ruleDelta holds per-rule finding counts in both datastores.
+type ruleDelta struct {
+	RuleID     string `json:"rule_id"`
+	RuleName   string `json:"rule_name"`
+	Before     int    `json:"before"`
+	After      int    `json:"after"`
+	Delta      int    `json:"delta"`
+	New        int    `json:"new"`
+	Resolved   int    `json:"resolved"`
+	Persisting int    `json:"persisting"`
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	loader := rule.NewLoader()
+	rules, err := loader.LoadBuiltinRules()
+	if err != nil {
+		return fmt.Errorf("loading rules: %w", err)
+	}
+	ruleMap := make(map[string]*types.Rule)
+	for _, r := range rules {
+		ruleMap[r.ID] = r
+	}
+
+	oldStore, err := openDatastore(args[0])
+	if err != nil {
+		return err
+	}
+	defer oldStore.Close()
+	newStore, err := openDatastore(args[1])
+	if err != nil {
+		return err
+	}
+	defer newStore.Close()
+	oldFP, err := datastoreFingerprint(oldStore)
+	if err != nil {
+		return err
+	}
+	newFP, err := datastoreFingerprint(newStore)
+	if err != nil {
+		return err
+	}
+	if oldFP != newFP {
+		return fmt.Errorf("%s uses fingerprint %s and %s uses %s; migrate one with 'titus fingerprints migrate --to'", args[0], oldFP, args[1], newFP)
+	}
+
+	oldFindings, err := comparedFindings(oldStore, ruleMap)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", args[0], err)
+	}
+	newFindings, err := comparedFindings(newStore, ruleMap)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", args[1], err)
+	}
+
+	result := compareFindings(oldFindings, newFindings)
+	result.Old, result.New = args[0], args[1]
+
+	switch compareFormat {
+	case "json":
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	case "human":
+		return outputCompareHuman(cmd.OutOrStdout(), result, colorEnabled)
+	default:
+		return fmt.Errorf("unknown output format: %s", compareFormat)
+	}
+}
+
+// openDatastore opens a datastore directory or database file for reading.
+func openDatastore(path string) (store.Store, error) {
+	if path == ":memory:" {
+		return nil, fmt.Errorf("cannot read from in-memory store")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("datastore not found: %s", path)
+	}
+	if info.IsDir() {
+		path = filepath.Join(path, "datastore.db")
+	}
+	s, err := store.New(store.Config{Path: path})
+	if err != nil {
+		return nil, fmt.Errorf("opening datastore: %w", err)
+	}
+	return s, nil
+}
+
+// comparedFindings reads a datastore's findings with the path of their first
+// match, leaving out remediated findings.
+func comparedFindings(s store.Store, ruleMap map[string]*types.Rule) ([]comparedFinding, error) {
+	findings, err := s.GetFindings()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving findings: %w", err)
+	}
+	matches, err := s.GetAllMatches()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving matches: %w", err)
+	}
+	matchesByFinding := buildFindingMatchMap(findings, matches)
+
+	out := make([]comparedFinding, 0, len(findings))
+	for _, f := range findings {
+		if f.State == types.FindingStateRemediated {
+			continue
+		}
+		cf := comparedFinding{ID: f.ID, RuleID: f.RuleID, RuleName: f.RuleID, State: f.State}
+		if r, ok := ruleMap[f.RuleID]; ok {
+			cf.RuleName = r.Name
+		}
+		if fm := matchesByFinding[f.ID]; len(fm) > 0 {
+			if prov, err := s.GetProvenance(fm[0].BlobID); err == nil && prov != nil {
+				cf.Path = prov.Path()
+			}
+		}
+		out = append(out, cf)
+	}
+	return out, nil
+}
+
+// compareFindings classifies findings as new, resolved or persisting by ID and
+// tallies them per rule. Rules are ordered by largest increase first.
+func compareFindings(oldFindings, newFindings []comparedFinding) compareResult {
+	oldByID := make(map[string]comparedFinding, len(oldFindings))
+	for _, f := range oldFindings {
+		oldByID[f.ID] = f
+	}
+	newByID := make(map[string]comparedFinding, len(newFindings))
+	for _, f := range newFindings {
+		newByID[f.ID] = f
+	}
+
+	result := compareResult{
+		Added:      []comparedFinding{},
+		Resolved:   []comparedFinding{},
+		Persisting: []comparedFinding{},
+		Rules:      []ruleDelta{},
+	}
+	deltas := make(map[string]*ruleDelta)
+	delta := func(f comparedFinding) *ruleDelta {
+		d, ok := deltas[f.RuleID]
+		if !ok {
+			d = &ruleDelta{RuleID: f.RuleID, RuleName: f.RuleName}
+			deltas[f.RuleID] = d
+		}
+		return d
+	}
+
+	for _, f := range newByID {
+		d := delta(f)
+		d.After++
+		if _, ok := oldByID[f.ID]; ok {
+			d.Persisting++
+			result.Persisting = append(result.Persisting, f)
+		} else {
+			d.New++
+			result.Added = append(result.Added, f)
+		}
+	}
+	for _, f := range oldByID {
+		d := delta(f)
+		d.Before++
+		if _, ok := newByID[f.ID]; !ok {
+			d.Resolved++
+			result.Resolved = append(result.Resolved, f)
+		}
+	}
+
+	for _, d := range deltas {
+		d.Delta = d.After - d.Before
+		result.Rules = append(result.Rules, *d)
+	}
+	sort.Slice(result.Rules, func(i, j int) bool {
+		if result.Rules[i].Delta != result.Rules[j].Delta {
+			return result.Rules[i].Delta > result.Rules[j].Delta
+		}
+		return result.Rules[i].RuleID < result.Rules[j].RuleID
+	})
+	for _, list := range [][]comparedFinding{result.Added, result.Resolved, result.Persisting} {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].RuleName != list[j].RuleName {
+				return list[i].RuleName < list[j].RuleName
+			}
+			return list[i].ID < list[j].ID
+		})
+	}
+	return result
+}
+
+func outputCompareHuman(out io.Writer, result compareResult, colorEnabled bool) error {
+	s := style.New(colorEnabled)
+	added := color.New(color.FgRed)
+	resolved := color.New(color.FgGreen)
+	if !colorEnabled {
+		added.DisableColor()
+		resolved.DisableColor()
+	}
+
+	fmt.Fprintf(out, "%s %s -> %s\n", s.Heading.Sprint("Comparing:"), result.Old, result.New)
+	fmt.Fprintf(out, "%s %s   %s %s   %s %d\n\n",
+		s.Heading.Sprint("New:"), added.Sprint(len(result.Added)),
+		s.Heading.Sprint("Resolved:"), resolved.Sprint(len(result.Resolved)),
+		s.Heading.Sprint("Persisting:"), len(result.Persisting))
+
+	if len(result.Rules) == 0 {
+		fmt.Fprintf(out, "No findings in either datastore.\n")
+		return nil
+	}
+
+	maxNameLen := len("Rule")
+	for _, r := range result.Rules {
+		if len(r.RuleName) > maxNameLen {
+			maxNameLen = len(r.RuleName)
+		}
+	}
+	fmt.Fprintf(out, " %s   %s   %s   %s   %s   %s \n",
+		s.Heading.Sprintf("%-*s", maxNameLen, "Rule"),
+		s.Heading.Sprint("Before"),
+		s.Heading.Sprint("After"),
+		s.Heading.Sprint("Delta"),
+		s.Heading.Sprint("New"),
+		s.Heading.Sprint("Resolved"))
+	fmt.Fprintf(out, "%s\n", strings.Repeat("─", maxNameLen+44))
+	for _, r := range result.Rules {
+		fmt.Fprintf(out, " %s   %6d   %5d   %5s   %3d   %8d \n",
+			s.RuleName.Sprintf("%-*s", maxNameLen, r.RuleName),
+			r.Before, r.After, fmt.Sprintf("%+d", r.Delta), r.New, r.Resolved)
+	}
+
+	printList := func(title string, list []comparedFinding) {
+		if len(list) == 0 {
+			return
+		}
+		fmt.Fprintf(out, "\n%s\n", title)
+		for _, f := range list {
+			fmt.Fprintf(out, "  %s  %s", s.ID.Sprint(f.ID), s.RuleName.Sprint(f.RuleName))
+			if f.Path != "" {
+				fmt.Fprintf(out, "  %s", s.Metadata.Sprint(f.Path))
+			}
+			if f.State == types.FindingStateRegressed {
+				fmt.Fprintf(out, "  %s", added.Sprint("(regressed)"))
+			}
+			fmt.Fprintln(out)
+		}
+	}
+	printList(added.Sprint("New findings:"), result.Added)
+	printList(resolved.Sprint("Resolved findings:"), result.Resolved)
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/praetorian-inc/titus/pkg/enum"
+	"github.com/spf13/cobra"
+)
+
+var (
+	confluenceURL           string
+	confluenceUser          string
+	confluenceToken         string
+	confluenceNoAttachments bool
+	confluenceMaxFileSize   int64
+	confluenceExtract       string
+	confluenceOutputPath    string
+	confluenceOutputFormat  string
+)
+
+var confluenceCmd = &cobra.Command{
+	Use:   "confluence [space-key...]",
+	Short: "Scan Confluence pages and attachments for secrets",
+	Long: `Scan the pages, blog posts and attachments of Confluence spaces via the REST API.
+Without space keys, every space the token can read is scanned.
+
+Authentication:
+  Confluence Cloud: --user (or CONFLUENCE_USER) with an API token in --token (or CONFLUENCE_TOKEN).
+  Confluence Data Center: a personal access token in --token, without --user.
+
+Findings name the page URL, which includes the space key and page title.
+Attachments are named by their page URL followed by the file name.
+
+Examples:
+  titus confluence --url https://acme.atlassian.net --user me@acme.com --token $TOKEN
+  titus confluence --url https://wiki.corp.com --token $PAT ENG OPS`,
+	RunE: runConfluenceScan,
+}
+
+func init() {
+	confluenceCmd.Flags().StringVar(&confluenceURL, "url", "", "Confluence site URL (or CONFLUENCE_URL env; e.g., https://acme.atlassian.net)")
+	confluenceCmd.Flags().StringVar(&confluenceUser, "user", "", "Account email for a Confluence Cloud API token (or CONFLUENCE_USER env)")
+	confluenceCmd.Flags().StringVar(&confluenceToken, "token", "", "API token or personal access token (or CONFLUENCE_TOKEN env)")
+	confluenceCmd.Flags().BoolVar(&confluenceNoAttachments, "no-attachments", false, "Scan page content only, not attachments")
+	confluenceCmd.Flags().Int64Var(&confluenceMaxFileSize, "max-file-size", 10*1024*1024, "Maximum attachment size to scan (bytes)")
+	confluenceCmd.Flags().StringVar(&confluenceExtract, "extract", "all", "Extract text from binary attachments (extensions: xlsx,docx,pdf,zip or 'all'; empty to disable)")
+	confluenceCmd.Flags().StringVar(&confluenceOutputPath, "output", "titus.db", "Output database path (:memory: for in-memory)")
+	confluenceCmd.Flags().StringVar(&confluenceOutputFormat, "format", "human", "Output format: json, human")
+
+	rootCmd.AddCommand(confluenceCmd)
+}
+
+func runConfluenceScan(cmd *cobra.Command, args []string) error {
+	baseURL := confluenceURL
+	if baseURL == "" {
+		baseURL = os.Getenv("CONFLUENCE_URL")
+	}
+	user := confluenceUser
+	if user == "" {
+		user = os.Getenv("CONFLUENCE_USER")
+	}
+	token := confluenceToken
+	if token == "" {
+		token = os.Getenv("CONFLUENCE_TOKEN")
+	}
+
+	if baseURL == "" {
+		return fmt.Errorf("must specify the Confluence site: use --url or CONFLUENCE_URL")
+	}
+	insecure, err := enum.ValidateBaseURL(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid --url: %w", err)
+	}
+	if insecure && token != "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "WARNING: Using HTTP with an API token. Your token will be sent in plaintext.\n")
+	}
+	if token == "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Note: No Confluence token provided. Only anonymously readable spaces will be scanned.\n\n")
+	}
+
+	e, err := enum.NewConfluenceEnumerator(enum.ConfluenceConfig{
+		BaseURL:     baseURL,
+		User:        user,
+		Token:       token,
+		Spaces:      args,
+		Attachments: !confluenceNoAttachments,
+		Config: enum.Config{
+			MaxFileSize:     confluenceMaxFileSize,
+			ExtractArchives: confluenceExtract,
+			ExtractLimits:   enum.DefaultExtractionLimits(),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("creating Confluence client: %w", err)
+	}
+	return scanEnumerator(cmd, e, "Confluence", confluenceOutputPath, confluenceOutputFormat)
+}
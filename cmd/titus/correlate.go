@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/praetorian-inc/titus/pkg/matcher"
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/praetorian-inc/titus/pkg/validator"
+	"github.com/spf13/cobra"
+)
+
+// crossFileCorrelationEnabled reports whether the scan should pair credential
+// components across files. Cross-file pairs are guesses, so they are only
+// assembled when they can be validated.
+func crossFileCorrelationEnabled() bool {
+	return (scanValidate || scanValidateAggressive) && scanCorrelateFiles
+}
+
+// reportableMatches returns matches without those of CorrelationOnly rules,
+// which are stored only so they can be paired across files.
+func reportableMatches(matches []*types.Match, ruleMap map[string]*types.Rule) []*types.Match {
+	out := make([]*types.Match, 0, len(matches))
+	for _, m := range matches {
+		if r, ok := ruleMap[m.RuleID]; ok && r.CorrelationOnly {
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// correlateAcrossBlobs runs after the scan. It assembles credential sets from
+// unpaired components stored in different files of the same directory or
+// repository, such as an AWS key ID in .env and its secret in config.yml. It
+// validates each candidate and stores the first valid combination for each
+// anchor as a match and finding of the correlated rule. Combinations that
+// don't validate are discarded.
+func correlateAcrossBlobs(ctx context.Context, cmd *cobra.Command, s store.Store, rules []*types.Rule, ruleMap map[string]*types.Rule, engine *validator.Engine) error {
+	if engine == nil {
+		return nil
+	}
+
+	matches, err := s.GetAllMatches()
+	if err != nil {
+		return fmt.Errorf("retrieving matches: %w", err)
+	}
+
+	scopes := make(map[types.BlobID][]string)
+	for _, m := range matches {
+		if _, ok := scopes[m.BlobID]; ok {
+			continue
+		}
+		provs, err := s.GetAllProvenance(m.BlobID)
+		if err != nil {
+			return fmt.Errorf("retrieving provenance: %w", err)
+		}
+		scopes[m.BlobID] = matcher.BlobScopes(provs)
+	}
+
+	candidates := matcher.NewCorrelator(rules).CorrelateBlobs(matches, scopes)
+
+	paired := make(map[string]bool)
+	var valid []*types.Match
+	for _, c := range candidates {
+		if paired[c.StructuralID] || !engine.CanValidate(c.RuleID) {
+			continue
+		}
+		result, err := engine.ValidateMatch(ctx, c)
+		if err != nil || result.Status != types.StatusValid {
+			continue
+		}
+		c.ValidationResult = result
+		paired[c.StructuralID] = true
+		valid = append(valid, c)
+	}
+	if len(valid) == 0 {
+		return nil
+	}
+
+	fp, err := datastoreFingerprint(s)
+	if err != nil {
+		return err
+	}
+	err = s.ExecBatch(func(tx store.Store) error {
+		for _, m := range valid {
+			if r, ok := ruleMap[m.RuleID]; ok {
+				m.FindingID = fp.FindingID(r, m.Groups)
+			}
+			if err := tx.AddMatch(m); err != nil {
+				return fmt.Errorf("storing match: %w", err)
+			}
+			exists, err := tx.FindingExists(m.FindingID)
+			if err != nil {
+				return fmt.Errorf("checking finding: %w", err)
+			}
+			if exists {
+				continue
+			}
+			if err := tx.AddFinding(&types.Finding{
+				ID:      m.FindingID,
+				RuleID:  m.RuleID,
+				Groups:  m.Groups,
+				Matches: []*types.Match{m},
+			}); err != nil {
+				return fmt.Errorf("storing finding: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Correlated %d credential set(s) across files\n", len(valid))
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/praetorian-inc/titus/pkg/explore"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/praetorian-inc/titus/pkg/validator"
+	"golang.org/x/term"
+)
+
+const (
+	// dashboardTopRules is how many of the rules with the most matches the
+	// dashboard lists.
+	dashboardTopRules = 8
+
+	// plainStatusInterval is how often a --dashboard scan prints a status
+	// line when stderr isn't a terminal.
+	plainStatusInterval = 10 * time.Second
+)
+
+// progressDashboard shows a running scan's progress for --dashboard: a live view
+// redrawn in place when out is a terminal, otherwise a plain status line
+// every plainStatusInterval, so logs of CI runs stay readable.
+type progressDashboard struct {
+	progress *scanProgress
+	ruleMap  map[string]*types.Rule
+	engine   *validator.Engine
+	workers  int
+	start    time.Time
+
+	program *tea.Program
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// startProgressDashboard starts showing progress on out. interrupt is called if
+// the user stops the scan from the live view.
+func startProgressDashboard(out io.Writer, progress *scanProgress, ruleMap map[string]*types.Rule, engine *validator.Engine, workers int, interrupt func()) *progressDashboard {
+	d := &progressDashboard{
+		progress: progress,
+		ruleMap:  ruleMap,
+		engine:   engine,
+		workers:  workers,
+		start:    time.Now(),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	if f, ok := out.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		d.program = tea.NewProgram(explore.NewDashboard(d.stats, interrupt), tea.WithOutput(out))
+		go func() {
+			defer close(d.done)
+			d.program.Run()
+		}()
+		return d
+	}
+
+	go func() {
+		defer close(d.done)
+		ticker := time.NewTicker(plainStatusInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				statusf(out, "[progress] %s\n", d.stats())
+			case <-d.stop:
+				return
+			}
+		}
+	}()
+	return d
+}
+
+// Stop draws the final stats and gives the terminal back.
+func (d *progressDashboard) Stop() {
+	if d == nil {
+		return
+	}
+	if d.program != nil {
+		d.program.Send(explore.DashboardDoneMsg{})
+	} else {
+		close(d.stop)
+	}
+	<-d.done
+}
+
+// stats takes a snapshot of the scan's progress.
+func (d *progressDashboard) stats() explore.ScanStats {
+	p := d.progress
+	s := explore.ScanStats{
+		Elapsed:     time.Since(d.start),
+		Blobs:       p.blobs.Load(),
+		Bytes:       p.bytes.Load(),
+		BusyWorkers: int(p.busy.Load()),
+		Workers:     d.workers,
+	}
+	if d.engine != nil {
+		s.Validating = true
+		s.ValidationQueue = d.engine.Pending()
+	}
+
+	p.mu.Lock()
+	s.Findings = p.findings
+	for id, n := range p.counts {
+		s.Matches += int64(n)
+		s.TopRules = append(s.TopRules, explore.RuleCount{RuleID: id, RuleName: d.ruleName(id), Matches: n})
+	}
+	for i := len(p.recent) - 1; i >= 0; i-- {
+		s.Recent = append(s.Recent, explore.RecentFinding{RuleName: d.ruleName(p.recent[i].ruleID), Path: p.recent[i].path})
+	}
+	p.mu.Unlock()
+
+	sort.Slice(s.TopRules, func(i, j int) bool {
+		if s.TopRules[i].Matches != s.TopRules[j].Matches {
+			return s.TopRules[i].Matches > s.TopRules[j].Matches
+		}
+		return s.TopRules[i].RuleID < s.TopRules[j].RuleID
+	})
+	if len(s.TopRules) > dashboardTopRules {
+		s.TopRules = s.TopRules[:dashboardTopRules]
+	}
+	return s
+}
+
+func (d *progressDashboard) ruleName(id string) string {
+	if r, ok := d.ruleMap[id]; ok {
+		return r.Name
+	}
+	return id
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// Blob deduplication scopes for --dedupe.
+const (
+	// dedupeGlobal records a blob found by more than one part of a scan,
+	// such as git history and the working tree or nested repositories, only
+	// where it was first found. With --incremental, blobs stored by earlier
+	// scans are skipped.
+	dedupeGlobal = "global"
+
+	// dedupePerTarget records a blob in every part of a scan that finds it,
+	// but matches it once: later locations, and with --incremental the
+	// locations of blobs stored by earlier scans, are recorded without
+	// matching the blob again.
+	dedupePerTarget = "per-target"
+
+	// dedupeNone matches a blob at every location it is found, so that
+	// progress output and SIEM events cover each of them.
+	dedupeNone = "none"
+)
+
+// checkDedupeScope validates --dedupe.
+func checkDedupeScope(scope string, incremental bool) error {
+	switch scope {
+	case dedupeGlobal, dedupePerTarget:
+		return nil
+	case dedupeNone:
+		if incremental {
+			return fmt.Errorf("--incremental cannot be used with --dedupe none")
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid --dedupe %q (use global, per-target or none)", scope)
+}
+
+// keepDuplicates reports whether enumerators should yield a blob at every
+// location they find it, for scope to record or match it there.
+func keepDuplicates(scope string) bool {
+	return scope == dedupePerTarget || scope == dedupeNone
+}
+
+// blobDedupe decides what a scan does with each blob it enumerates.
+type blobDedupe struct {
+	scope       string
+	store       store.Store
+	incremental bool
+
+	mu      sync.Mutex
+	matched map[types.BlobID]bool
+}
+
+func newBlobDedupe(scope string, s store.Store, incremental bool) *blobDedupe {
+	return &blobDedupe{
+		scope:       scope,
+		store:       s,
+		incremental: incremental,
+		matched:     make(map[types.BlobID]bool),
+	}
+}
+
+// check reports whether a blob is to be scanned and, if so, whether it is
+// already matched and only its location is to be recorded.
+func (d *blobDedupe) check(id types.BlobID) (scan, recordOnly bool, err error) {
+	if d.scope == dedupePerTarget {
+		d.mu.Lock()
+		recordOnly = d.matched[id]
+		d.matched[id] = true
+		d.mu.Unlock()
+		if recordOnly {
+			return true, true, nil
+		}
+	}
+	if d.incremental {
+		exists, err := d.store.BlobExists(id)
+		if err != nil {
+			return false, false, fmt.Errorf("checking blob: %w", err)
+		}
+		if exists {
+			// Skipped under global scope; none rules out --incremental
+			record := d.scope == dedupePerTarget
+			return record, record, nil
+		}
+	}
+	return true, false, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDedupeScope(t *testing.T) {
+	assert.NoError(t, checkDedupeScope(dedupeGlobal, true))
+	assert.NoError(t, checkDedupeScope(dedupePerTarget, true))
+	assert.NoError(t, checkDedupeScope(dedupeNone, false))
+	assert.Error(t, checkDedupeScope(dedupeNone, true))
+	assert.Error(t, checkDedupeScope("repo", false))
+}
+
+func TestBlobDedupe(t *testing.T) {
+	stored := types.ComputeBlobID([]byte("stored"))
+	fresh := types.ComputeBlobID([]byte("fresh"))
+	s := store.NewMemory()
+	require.NoError(t, s.AddBlob(stored, 6))
+
+	type result struct{ scan, recordOnly bool }
+	check := func(d *blobDedupe, id types.BlobID) result {
+		scan, recordOnly, err := d.check(id)
+		require.NoError(t, err)
+		return result{scan, recordOnly}
+	}
+
+	tests := []struct {
+		scope       string
+		incremental bool
+		stored      result
+		fresh       result
+		freshAgain  result
+	}{
+		{dedupeGlobal, false, result{true, false}, result{true, false}, result{true, false}},
+		{dedupeGlobal, true, result{false, false}, result{true, false}, result{true, false}},
+		{dedupePerTarget, false, result{true, false}, result{true, false}, result{true, true}},
+		{dedupePerTarget, true, result{true, true}, result{true, false}, result{true, true}},
+		{dedupeNone, false, result{true, false}, result{true, false}, result{true, false}},
+	}
+	for _, tt := range tests {
+		d := newBlobDedupe(tt.scope, s, tt.incremental)
+		assert.Equal(t, tt.stored, check(d, stored), "%s, incremental=%v: stored blob", tt.scope, tt.incremental)
+		assert.Equal(t, tt.fresh, check(d, fresh), "%s, incremental=%v: new blob", tt.scope, tt.incremental)
+		assert.Equal(t, tt.freshAgain, check(d, fresh), "%s, incremental=%v: new blob again", tt.scope, tt.incremental)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/praetorian-inc/titus/pkg/enum"
+)
+
+// remoteTarget reports whether target is read over the network or by a
+// plugin rather than from the filesystem: an SMB share, a mailbox or a
+// custom scheme:// URL.
+func remoteTarget(target string) bool {
+	return enum.IsSMBURL(target) || enum.IsIMAPURL(target) || enum.IsCustomTarget(target)
+}
+
+// loadEnumeratorPlugins registers the enumerator plugins in dir (or the
+// default plugin directory) if target is a custom scheme:// URL that no
+// enumerator is registered for yet. A scheme stays with whatever registered
+// it first: an enumerator built into this binary, then plugins in name
+// order.
+func loadEnumeratorPlugins(target, dir string) error {
+	if !enum.IsCustomTarget(target) {
+		return nil
+	}
+	if _, ok := enum.Lookup(target); ok {
+		return nil
+	}
+
+	if dir == "" {
+		dir = enum.DefaultPluginDir()
+	}
+	if dir != "" {
+		plugins, err := enum.LoadPlugins(context.Background(), dir)
+		if err != nil {
+			return fmt.Errorf("loading enumerator plugins: %w", err)
+		}
+		for _, p := range plugins {
+			for _, scheme := range p.Schemes() {
+				if _, ok := enum.Lookup(scheme + "://"); ok {
+					continue
+				}
+				if err := enum.Register(scheme, p.Enumerator); err != nil {
+					return fmt.Errorf("registering plugin %s: %w", p.Name(), err)
+				}
+			}
+		}
+	}
+
+	if _, ok := enum.Lookup(target); !ok {
+		return fmt.Errorf("no enumerator for %s:// targets: add a plugin for it to %s or use --enumerator-plugins", enum.TargetScheme(target), dir)
+	}
+	return nil
+}
@@ -2,14 +2,23 @@ package main
 
 import (
 	"fmt"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/praetorian-inc/titus/pkg/explore"
+	"github.com/praetorian-inc/titus/pkg/validator"
 	"github.com/spf13/cobra"
 )
 
 var (
-	exploreDatastore string
+	exploreDatastore        string
+	exploreAuthor           string
+	exploreFollow           bool
+	exploreFollowInterval   time.Duration
+	exploreValidatorPlugins string
+	exploreValidateAudit    string
+	exploreValidateTimeouts []string
+	exploreValidateBreaker  int
 )
 
 var exploreCmd = &cobra.Command{
@@ -19,16 +28,36 @@ var exploreCmd = &cobra.Command{
 
 Features:
   - Three-pane layout: filters, findings table, match details
-  - Faceted search by rule name, category, and validation status
-  - Accept/reject annotations with comments
+  - Faceted search by rule name, category, validation status, and a
+    directory tree of the paths findings were found under
+  - Accept/reject annotations with comments, recorded with --author for
+    merging with 'titus annotations'
+  - On-demand validation of the selected finding or match (v), saved to
+    the datastore
   - Vi-style navigation (hjkl, Ctrl-f/b, g/G)
   - Source viewer for matched content
-  - Sortable findings table`,
+  - Sortable findings table
+
+With --follow, the datastore is reloaded while a scan is still writing to
+it and new findings are added as they are recorded, so triage of a long
+scan can start before it finishes.
+
+Examples:
+  titus explore --datastore titus.ds
+  titus scan /srv/monorepo --output titus.ds &
+  titus explore --datastore titus.ds --follow`,
 	RunE: runExplore,
 }
 
 func init() {
 	exploreCmd.Flags().StringVar(&exploreDatastore, "datastore", "titus.ds", "Path to datastore directory or file")
+	exploreCmd.Flags().BoolVar(&exploreFollow, "follow", false, "Keep adding findings that a running scan writes to the datastore")
+	exploreCmd.Flags().DurationVar(&exploreFollowInterval, "follow-interval", 2*time.Second, "How often --follow reloads the datastore")
+	exploreCmd.Flags().StringVar(&exploreAuthor, "author", "", "Name recorded as the author of annotations (default: the current user)")
+	exploreCmd.Flags().StringVar(&exploreValidateAudit, "validate-audit", "", "Append a JSON line per outbound validation request (time, rule, validator, host, result; no secrets) to this file")
+	exploreCmd.Flags().StringSliceVar(&exploreValidateTimeouts, "validate-timeout", nil, "Time limit for each validation, and per validator as name=duration (e.g. 10s,aws=30s; default 30s, or the validator's own)")
+	exploreCmd.Flags().IntVar(&exploreValidateBreaker, "validate-breaker", validator.DefaultBreakerFailures, "Skip a validator's endpoint, marking its matches undetermined, after this many consecutive network failures or timeouts against it (0 = never)")
+	exploreCmd.Flags().StringVar(&exploreValidatorPlugins, "validator-plugins", "", "directory of external validator executables (default: <user config dir>/titus/validators)")
 }
 
 func runExplore(cmd *cobra.Command, args []string) error {
@@ -37,6 +66,31 @@ func runExplore(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("loading datastore: %w", err)
 	}
 	defer model.Close()
+	model.SetAuthor(annotationAuthor(exploreAuthor))
+	if exploreFollow {
+		if exploreFollowInterval <= 0 {
+			return fmt.Errorf("--follow-interval must be positive")
+		}
+		model.Follow(exploreFollowInterval)
+	}
+
+	engine := validator.NewDefaultEngine(4)
+	dir := exploreValidatorPlugins
+	if dir == "" {
+		dir = validator.DefaultPluginDir()
+	}
+	model.SetValidatorPlugins(dir)
+	if err := setValidationLimits(engine, exploreValidateTimeouts, exploreValidateBreaker); err != nil {
+		return err
+	}
+	audit, err := openValidationAudit(exploreValidateAudit, engine)
+	if err != nil {
+		return err
+	}
+	if audit != nil {
+		defer closeValidationAudit(audit)
+	}
+	model.SetValidator(engine)
 
 	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
 	if _, err := p.Run(); err != nil {
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportDatastore string
+	exportOutput    string
+	exportRedacted  bool
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Copy a datastore, optionally with secrets redacted",
+	Long: `Copy a datastore to a new datastore directory.
+
+With --redacted, the copy can be handed to remediation owners without
+disclosing the credentials again: each secret capture group is replaced with
+its HMAC-SHA256, keyed with the source datastore's secret key, and secrets in
+match snippets, validation messages and annotation comments are masked. The
+key isn't exported, so secrets can't be guessed from the copy. Rules,
+locations, provenance, validation results, annotations and scan records are
+kept, so the copy works with report, explore and compare. Stored blobs and
+clones are not copied.
+
+Examples:
+  titus export --redacted --output redacted.ds
+  titus export --redacted --datastore scans/2024-06.ds --output handoff.ds`,
+	RunE: runExport,
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportDatastore, "datastore", "titus.ds", "Path to datastore directory or file")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Datastore directory to create (required)")
+	exportCmd.Flags().BoolVar(&exportRedacted, "redacted", false, "Hash secret groups and mask snippets in the copy")
+	exportCmd.MarkFlagRequired("output")
+	rootCmd.AddCommand(exportCmd)
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	src := datastoreFile(exportDatastore)
+	if src == "" {
+		return fmt.Errorf("cannot export an in-memory store")
+	}
+	if _, err := os.Stat(src); err != nil {
+		return fmt.Errorf("datastore not found: %s", exportDatastore)
+	}
+	if _, err := os.Stat(exportOutput); err == nil {
+		return fmt.Errorf("output %s already exists", exportOutput)
+	}
+
+	if err := os.MkdirAll(exportOutput, 0755); err != nil {
+		return fmt.Errorf("creating output datastore: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(exportOutput, ".gitignore"), []byte("*\n"), 0644); err != nil {
+		return fmt.Errorf("writing .gitignore: %w", err)
+	}
+	dst := filepath.Join(exportOutput, "datastore.db")
+	if err := store.Export(src, dst, store.ExportOptions{Redacted: exportRedacted}); err != nil {
+		os.RemoveAll(exportOutput)
+		return fmt.Errorf("exporting datastore: %w", err)
+	}
+
+	what := "Datastore"
+	if exportRedacted {
+		what = "Redacted datastore"
+	}
+	statusf(cmd.ErrOrStderr(), "%s written to %s\n", what, exportOutput)
+	return nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/praetorian-inc/titus/pkg/matcher"
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// pathMatches collects the matches of filename rules against the paths the
+// enumerator finds, to be recorded once enumeration is done.
+type pathMatches struct {
+	matcher  *matcher.FilenameMatcher
+	policies *scanPolicies
+
+	mu      sync.Mutex
+	matches map[string][]*types.Match // path -> matches
+}
+
+func newPathMatches(m *matcher.FilenameMatcher, policies *scanPolicies) *pathMatches {
+	return &pathMatches{
+		matcher:  m,
+		policies: policies,
+		matches:  make(map[string][]*types.Match),
+	}
+}
+
+// hooks returns the enumerator hooks that feed paths to the matcher, or none
+// if there are no filename rules.
+func (p *pathMatches) hooks() enumHooks {
+	if p.matcher.Empty() {
+		return enumHooks{}
+	}
+	return enumHooks{onFile: p.match}
+}
+
+// match matches path against the filename rules the policies for it report.
+func (p *pathMatches) match(path string) {
+	prov := types.FileProvenance{FilePath: path}
+	ms := p.policies.filter(prov, p.matcher.Match(path))
+	if len(ms) == 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.matches[path] = append(p.matches[path], ms...)
+}
+
+// pathMatchResult reports what record stored.
+type pathMatchResult struct {
+	blobIDs    []types.BlobID
+	matches    int64
+	newMatches int64 // matches the store didn't hold yet
+	findings   int64
+}
+
+// record stores the collected matches, each path as a blob of its own, and
+// calls emit with each path's matches. Paths are recorded in sorted order.
+func (p *pathMatches) record(s store.Store, ruleMap map[string]*types.Rule, emit func([]*types.Match, types.Provenance)) (pathMatchResult, error) {
+	var result pathMatchResult
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.matches) == 0 {
+		return result, nil
+	}
+
+	paths := make([]string, 0, len(p.matches))
+	for path := range p.matches {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	err := s.ExecBatch(func(tx store.Store) error {
+		for _, path := range paths {
+			ms := p.matches[path]
+			blobID := matcher.PathBlobID(path)
+			prov := types.FileProvenance{FilePath: path}
+			fresh, err := countNewMatches(tx, ruleMap, blobID, ms)
+			if err != nil {
+				return err
+			}
+			created, err := recordBlob(tx, ruleMap, blobID, prov, int64(len(path)), ms)
+			if err != nil {
+				return err
+			}
+			result.blobIDs = append(result.blobIDs, blobID)
+			result.matches += int64(len(ms))
+			result.newMatches += fresh
+			result.findings += int64(len(created))
+		}
+		return nil
+	})
+	if err != nil {
+		return pathMatchResult{}, err
+	}
+	if emit != nil {
+		for _, path := range paths {
+			emit(p.matches[path], types.FileProvenance{FilePath: path})
+		}
+	}
+	return result, nil
+}
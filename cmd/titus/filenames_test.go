@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/matcher"
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathMatches(t *testing.T) {
+	rules := []*types.Rule{
+		{ID: "np.filename.1", Name: "SSH Private Key File", Kind: types.RuleKindFilename, Pattern: `(?:^|/)id_rsa$`},
+		{ID: "np.test.1", Name: "Content", Pattern: `secret`},
+	}
+	ruleMap := map[string]*types.Rule{}
+	s := store.NewMemory()
+	for _, r := range rules {
+		ruleMap[r.ID] = r
+		require.NoError(t, s.AddRule(r))
+	}
+	m, err := matcher.NewFilenameMatcher(rules)
+	require.NoError(t, err)
+
+	paths := newPathMatches(m, nil)
+	require.NotNil(t, paths.hooks().onFile)
+	paths.match("home/.ssh/id_rsa")
+	paths.match("home/.ssh/id_rsa.pub")
+	paths.match("src/secret.go")
+
+	var emitted []string
+	result, err := paths.record(s, ruleMap, func(ms []*types.Match, prov types.Provenance) {
+		emitted = append(emitted, prov.Path())
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"home/.ssh/id_rsa"}, emitted)
+	assert.Equal(t, int64(1), result.matches)
+	assert.Equal(t, int64(1), result.newMatches)
+	assert.Equal(t, int64(1), result.findings)
+	assert.Equal(t, []types.BlobID{matcher.PathBlobID("home/.ssh/id_rsa")}, result.blobIDs)
+
+	findings, err := s.GetFindings()
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "np.filename.1", findings[0].RuleID)
+	matches, err := s.GetAllMatches()
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, types.RuleKindFilename, matches[0].Kind)
+}
+
+func TestPathMatches_NoRules(t *testing.T) {
+	m, err := matcher.NewFilenameMatcher([]*types.Rule{{ID: "np.test.1", Pattern: `secret`}})
+	require.NoError(t, err)
+	paths := newPathMatches(m, nil)
+	assert.Nil(t, paths.hooks().onFile)
+}
+
+func TestRunRepoScan_PathRules(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+	defer func(out, format string, git bool) {
+		scanOutputPath, scanOutputFormat, scanGit = out, format, git
+	}(scanOutputPath, scanOutputFormat, scanGit)
+	scanOutputPath = filepath.Join(t.TempDir(), "titus.ds")
+	scanOutputFormat, scanGit = "json", false
+
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init", "-q", "-b", "main")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "Test User")
+	require.NoError(t, os.Mkdir(filepath.Join(repo, "deploy"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "deploy", "id_rsa"), []byte("placeholder\n"), 0o644))
+	git("add", ".")
+	git("commit", "-q", "-m", "add key")
+
+	rt := repoTarget{Platform: "ssh", Host: "git.example.com", FullPath: "acme/app", CloneURL: repo}
+	cmd := &cobra.Command{}
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	require.NoError(t, runRepoScan(cmd, rt, nil))
+
+	s, err := openDatastore(scanOutputPath)
+	require.NoError(t, err)
+	defer s.Close()
+	matches, err := s.GetAllMatches()
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "np.filename.1", matches[0].RuleID)
+	prov, err := s.GetProvenance(matches[0].BlobID)
+	require.NoError(t, err)
+	assert.Equal(t, rt.name()+"/deploy/id_rsa", prov.Path(), "paths are reported under the repository, not the temp clone")
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/praetorian-inc/titus/pkg/filetype"
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// matchFileTypes returns the file types of every location a blob was found
+// at, keyed by blob and cached across calls.
+type matchFileTypes struct {
+	s     store.Store
+	cache map[types.BlobID][]string
+}
+
+func newMatchFileTypes(s store.Store) *matchFileTypes {
+	return &matchFileTypes{s: s, cache: make(map[types.BlobID][]string)}
+}
+
+func (t *matchFileTypes) of(blobID types.BlobID) []string {
+	if fts, ok := t.cache[blobID]; ok {
+		return fts
+	}
+	var fts []string
+	if provs, err := t.s.GetAllProvenance(blobID); err == nil {
+		for _, p := range provs {
+			if ft := filetype.Of(p); ft != "" {
+				fts = append(fts, ft)
+			}
+		}
+	}
+	t.cache[blobID] = fts
+	return fts
+}
+
+// filterByFileType keeps matches found in a file of an included type (any
+// type if include is empty) and of no excluded type, and the findings that
+// still have matches. Types compare case-insensitively.
+func filterByFileType(s store.Store, findings []*types.Finding, matches []*types.Match, include, exclude []string) ([]*types.Finding, []*types.Match) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return findings, matches
+	}
+	fileTypes := newMatchFileTypes(s)
+	keep := func(m *types.Match) bool {
+		fts := fileTypes.of(m.BlobID)
+		for _, ft := range fts {
+			if containsFold(exclude, ft) {
+				return false
+			}
+		}
+		if len(include) == 0 {
+			return true
+		}
+		for _, ft := range fts {
+			if containsFold(include, ft) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var keptMatches []*types.Match
+	for _, m := range matches {
+		if keep(m) {
+			keptMatches = append(keptMatches, m)
+		}
+	}
+	matchesByFinding := buildFindingMatchMap(findings, keptMatches)
+	var keptFindings []*types.Finding
+	for _, f := range findings {
+		if len(matchesByFinding[f.ID]) > 0 {
+			keptFindings = append(keptFindings, f)
+		}
+	}
+	return keptFindings, keptMatches
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(strings.TrimSpace(v), s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	scanFingerprintVersion string
+
+	fingerprintsDatastore string
+	fingerprintsTo        string
+	fingerprintsRulesPath string
+	fingerprintsDryRun    bool
+)
+
+var fingerprintsCmd = &cobra.Command{
+	Use:   "fingerprints",
+	Short: "Inspect and migrate the finding IDs of a datastore",
+	Long: `Finding IDs (fingerprints) identify a finding across scans; annotations,
+lifecycle states and reports of new findings rely on them.
+
+A datastore records the fingerprint version its IDs are computed with:
+
+  v1  SHA-1(rule structural ID, NUL, JSON of the capture groups). The
+      default, and the IDs Nosey Parker computes. The structural ID is the
+      SHA-1 of the rule's pattern, so editing a pattern changes the IDs of
+      its findings.
+  v2  SHA-1("titus-fp-v2", NUL, rule ID, NUL, JSON of the capture groups).
+      Survives pattern edits; changes only if a rule is renamed or its
+      capture groups change.
+
+A version's algorithm never changes once released.`,
+}
+
+var fingerprintsShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the fingerprint version of a datastore",
+	Args:  cobra.NoArgs,
+	RunE:  runFingerprintsShow,
+}
+
+var fingerprintsMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Recompute finding IDs with the current rules, carrying annotations along",
+	Long: `Recompute the ID of every finding in a datastore and move its annotation
+and lifecycle state to the new ID.
+
+Run it after upgrading rules whose patterns changed, so v1 findings keep
+their triage, or with --to to switch the datastore to another fingerprint
+version. Findings that end up with the same ID are merged.
+
+Examples:
+  titus fingerprints migrate --datastore titus.ds
+  titus fingerprints migrate --datastore titus.ds --to v2
+  titus fingerprints migrate --rules custom.yml --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runFingerprintsMigrate,
+}
+
+func init() {
+	scanCmd.Flags().StringVar(&scanFingerprintVersion, "fingerprint-version", "", "Finding ID algorithm for a new datastore: v1 (Nosey Parker compatible) or v2 (stable across rule edits); default: the datastore's, else v1")
+
+	fingerprintsCmd.PersistentFlags().StringVar(&fingerprintsDatastore, "datastore", "titus.ds", "Path to datastore directory or file")
+	fingerprintsMigrateCmd.Flags().StringVar(&fingerprintsTo, "to", "", "Fingerprint version to migrate to (default: the datastore's current version)")
+	fingerprintsMigrateCmd.Flags().StringVar(&fingerprintsRulesPath, "rules", "", "Custom rules file to resolve the rules of findings from, with the built-in rules")
+	fingerprintsMigrateCmd.Flags().BoolVar(&fingerprintsDryRun, "dry-run", false, "Report what would change without changing the datastore")
+	fingerprintsCmd.AddCommand(fingerprintsShowCmd)
+	fingerprintsCmd.AddCommand(fingerprintsMigrateCmd)
+	rootCmd.AddCommand(fingerprintsCmd)
+}
+
+// datastoreFingerprint returns the fingerprint version of the finding IDs
+// in s: the recorded one, or the default for datastores without one.
+func datastoreFingerprint(s store.Store) (types.FingerprintVersion, error) {
+	v, err := s.GetFingerprintVersion()
+	if err != nil {
+		return 0, fmt.Errorf("reading fingerprint version: %w", err)
+	}
+	if v == 0 {
+		v = types.DefaultFingerprintVersion
+	}
+	return v, nil
+}
+
+// resolveFingerprint settles the fingerprint version a scan into s uses and
+// records it. flag, the --fingerprint-version value, may be empty to keep
+// the datastore's. Asking for a different version than the one existing
+// findings use is an error: mixing them would duplicate every finding.
+func resolveFingerprint(s store.Store, flag string) (types.FingerprintVersion, error) {
+	current, err := datastoreFingerprint(s)
+	if err != nil {
+		return 0, err
+	}
+	if flag == "" {
+		return current, s.SetFingerprintVersion(current)
+	}
+	v, err := types.ParseFingerprintVersion(flag)
+	if err != nil {
+		return 0, err
+	}
+	if v != current {
+		findings, err := s.GetFindings()
+		if err != nil {
+			return 0, fmt.Errorf("retrieving findings: %w", err)
+		}
+		if len(findings) > 0 {
+			return 0, fmt.Errorf("datastore findings use fingerprint %s; run 'titus fingerprints migrate --to %s' first", current, v)
+		}
+	}
+	if err := s.SetFingerprintVersion(v); err != nil {
+		return 0, fmt.Errorf("recording fingerprint version: %w", err)
+	}
+	return v, nil
+}
+
+func openFingerprintsStore() (store.Store, error) {
+	path := datastoreFile(fingerprintsDatastore)
+	if path == "" {
+		return nil, fmt.Errorf("an in-memory store has no fingerprints to migrate")
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("datastore not found: %s", fingerprintsDatastore)
+	}
+	s, err := store.New(store.Config{Path: path})
+	if err != nil {
+		return nil, fmt.Errorf("opening datastore: %w", err)
+	}
+	return s, nil
+}
+
+func runFingerprintsShow(cmd *cobra.Command, args []string) error {
+	s, err := openFingerprintsStore()
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	v, err := datastoreFingerprint(s)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), v)
+	return nil
+}
+
+func runFingerprintsMigrate(cmd *cobra.Command, args []string) error {
+	s, err := openFingerprintsStore()
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	from, err := datastoreFingerprint(s)
+	if err != nil {
+		return err
+	}
+	to := from
+	if fingerprintsTo != "" {
+		if to, err = types.ParseFingerprintVersion(fingerprintsTo); err != nil {
+			return err
+		}
+	}
+
+	rules, err := loadRules(fingerprintsRulesPath, "", "", "all")
+	if err != nil {
+		return fmt.Errorf("loading rules: %w", err)
+	}
+	ruleMap := make(map[string]*types.Rule, len(rules))
+	for _, r := range rules {
+		ruleMap[r.ID] = r
+	}
+
+	findings, err := s.GetFindings()
+	if err != nil {
+		return fmt.Errorf("retrieving findings: %w", err)
+	}
+	plan := planFingerprintMigration(findings, ruleMap, to)
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Fingerprints %s -> %s: %d of %d findings change ID", from, to, len(plan.ids), len(findings))
+	if plan.merged > 0 {
+		fmt.Fprintf(out, ", %d merged", plan.merged)
+	}
+	fmt.Fprintln(out)
+	if len(plan.unknownRules) > 0 {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Kept the IDs of %d findings of rules not found (pass their rules with --rules): %v\n", plan.unknown, plan.unknownRules)
+	}
+	if fingerprintsDryRun {
+		return nil
+	}
+
+	return s.ExecBatch(func(tx store.Store) error {
+		if err := tx.RemapFindings(plan.ids); err != nil {
+			return fmt.Errorf("remapping findings: %w", err)
+		}
+		if err := tx.SetFingerprintVersion(to); err != nil {
+			return fmt.Errorf("recording fingerprint version: %w", err)
+		}
+		return nil
+	})
+}
+
+// fingerprintMigration is the set of finding ID changes a migration makes.
+type fingerprintMigration struct {
+	ids          map[string]string // old ID -> new ID, for IDs that change
+	merged       int               // findings whose new ID another finding has too
+	unknown      int               // findings whose rule wasn't found
+	unknownRules []string
+}
+
+// planFingerprintMigration computes the new ID of each finding under
+// version to. v2 IDs need only the rule ID, so findings of rules that are
+// no longer loaded can move to v2; v1 IDs need the rule's pattern.
+func planFingerprintMigration(findings []*types.Finding, ruleMap map[string]*types.Rule, to types.FingerprintVersion) fingerprintMigration {
+	plan := fingerprintMigration{ids: make(map[string]string)}
+	seen := make(map[string]bool, len(findings))
+	unknownRules := make(map[string]bool)
+	for _, f := range findings {
+		r, ok := ruleMap[f.RuleID]
+		if !ok && to == types.FingerprintV2 {
+			r, ok = &types.Rule{ID: f.RuleID}, true
+		}
+		if !ok {
+			plan.unknown++
+			if !unknownRules[f.RuleID] {
+				unknownRules[f.RuleID] = true
+				plan.unknownRules = append(plan.unknownRules, f.RuleID)
+			}
+			seen[f.ID] = true
+			continue
+		}
+		id := to.FindingID(r, f.Groups)
+		if seen[id] {
+			plan.merged++
+		}
+		seen[id] = true
+		if id != f.ID {
+			plan.ids[f.ID] = id
+		}
+	}
+	return plan
+}
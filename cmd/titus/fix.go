@@ -0,0 +1,276 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/praetorian-inc/titus/pkg/fix"
+	"github.com/praetorian-inc/titus/pkg/rule"
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fixDatastore   string
+	fixFindings    []string
+	fixRules       []string
+	fixStyle       string
+	fixPlaceholder string
+	fixWrite       bool
+	fixOutput      string
+)
+
+var fixCmd = &cobra.Command{
+	Use:   "fix",
+	Short: "Replace detected secrets in the working tree",
+	Long: `Rewrite files from a scan to remove the secrets found in them, producing a
+patch for review.
+
+With --style env (the default) each secret is replaced with an environment
+variable reference in the idiom of the file: process.env.NAME in JavaScript and
+TypeScript, os.Getenv("NAME") in Go and ${NAME} in YAML. The variable is named
+after the key the secret was assigned to. Secrets in .env files, in other file
+types, in Go constants, or inside a longer string literal are replaced with the
+placeholder.
+
+Only findings in filesystem files are fixed; secrets found in git history need
+their credentials rotated, not a patch. The patch is printed unless --write is
+given, in which case files are changed in place.
+
+Examples:
+  titus fix > remove-secrets.patch && git apply remove-secrets.patch
+  titus fix --finding 3f2a9c --write
+  titus fix --rule np.github.1 --style placeholder --placeholder CHANGEME`,
+	RunE: runFix,
+}
+
+func init() {
+	fixCmd.Flags().StringVar(&fixDatastore, "datastore", "titus.ds", "Path to datastore directory or file")
+	fixCmd.Flags().StringSliceVar(&fixFindings, "finding", nil, "Finding IDs (or ID prefixes) to fix (default: all)")
+	fixCmd.Flags().StringSliceVar(&fixRules, "rule", nil, "Only fix findings of these rule IDs")
+	fixCmd.Flags().StringVar(&fixStyle, "style", string(fix.StyleEnv), "Replacement style: env, placeholder")
+	fixCmd.Flags().StringVar(&fixPlaceholder, "placeholder", fix.DefaultPlaceholder, "Text to replace secrets with when no variable reference applies")
+	fixCmd.Flags().BoolVar(&fixWrite, "write", false, "Rewrite files in place instead of printing a patch")
+	fixCmd.Flags().StringVar(&fixOutput, "output", "", "Write the patch to a file instead of stdout")
+	rootCmd.AddCommand(fixCmd)
+}
+
+func runFix(cmd *cobra.Command, args []string) error {
+	switch fix.Style(fixStyle) {
+	case fix.StyleEnv, fix.StylePlaceholder:
+	default:
+		return fmt.Errorf("unknown style: %s", fixStyle)
+	}
+
+	s, err := openDatastore(fixDatastore)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	loader := rule.NewLoader()
+	rules, err := loader.LoadBuiltinRules()
+	if err != nil {
+		return fmt.Errorf("loading rules: %w", err)
+	}
+	ruleMap := make(map[string]*types.Rule)
+	for _, r := range rules {
+		ruleMap[r.ID] = r
+	}
+
+	out := cmd.OutOrStdout()
+	if fixOutput != "" && !fixWrite {
+		f, err := os.Create(fixOutput)
+		if err != nil {
+			return fmt.Errorf("creating patch file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	return fixSecrets(s, ruleMap, out, cmd.ErrOrStderr())
+}
+
+// fixSecrets rewrites the files holding the selected findings' secrets,
+// printing a patch to out (or writing the files with --write) and a summary
+// of the replacements to errOut.
+func fixSecrets(s store.Store, ruleMap map[string]*types.Rule, out, errOut io.Writer) error {
+	secrets, err := collectFixSecrets(s, ruleMap)
+	if err != nil {
+		return err
+	}
+	if len(secrets) == 0 {
+		fmt.Fprintln(errOut, "No findings in filesystem files to fix.")
+		return nil
+	}
+
+	paths := make([]string, 0, len(secrets))
+	for path := range secrets {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	fixer := fix.New(fix.Options{Style: fix.Style(fixStyle), Placeholder: fixPlaceholder})
+	var replaced, files int
+	for _, path := range paths {
+		before, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(errOut, "skipping %s: %v\n", path, err)
+			continue
+		}
+		after, repls, err := fixer.Fix(path, before, secrets[path])
+		if err != nil {
+			return err
+		}
+		if len(repls) == 0 {
+			fmt.Fprintf(errOut, "skipping %s: secrets no longer present\n", path)
+			continue
+		}
+
+		display := displayPath(path)
+		if fixWrite {
+			info, err := os.Stat(path)
+			if err != nil {
+				return fmt.Errorf("stat %s: %w", path, err)
+			}
+			if err := os.WriteFile(path, after, info.Mode().Perm()); err != nil {
+				return fmt.Errorf("writing %s: %w", path, err)
+			}
+		} else {
+			diff, err := fix.UnifiedDiff(display, before, after)
+			if err != nil {
+				return fmt.Errorf("diffing %s: %w", path, err)
+			}
+			fmt.Fprint(out, diff)
+		}
+
+		for _, r := range repls {
+			if r.EnvVar != "" {
+				fmt.Fprintf(errOut, "%s:%d: replaced with %s (set %s in the environment)\n", display, r.Line, r.New, r.EnvVar)
+			} else {
+				fmt.Fprintf(errOut, "%s:%d: replaced with %s\n", display, r.Line, r.New)
+			}
+		}
+		replaced += len(repls)
+		files++
+	}
+
+	fmt.Fprintf(errOut, "Replaced %d secret(s) in %d file(s)\n", replaced, files)
+	return nil
+}
+
+// collectFixSecrets returns the secrets of the selected findings, keyed by
+// the path of each filesystem file they were found in.
+func collectFixSecrets(s store.Store, ruleMap map[string]*types.Rule) (map[string][]fix.Secret, error) {
+	findings, err := s.GetFindings()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving findings: %w", err)
+	}
+	matches, err := s.GetAllMatches()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving matches: %w", err)
+	}
+	matchesByFinding := buildFindingMatchMap(findings, matches)
+
+	secrets := make(map[string][]fix.Secret)
+	for _, f := range findings {
+		if !fixSelected(f) {
+			continue
+		}
+		for _, m := range matchesByFinding[f.ID] {
+			if !capturesSecret(ruleMap, m.RuleID) {
+				continue
+			}
+			provs, err := s.GetAllProvenance(m.BlobID)
+			if err != nil {
+				return nil, fmt.Errorf("retrieving provenance: %w", err)
+			}
+			secret := fix.Secret{
+				Value:    secretValue(m),
+				Start:    m.Location.Offset.Start,
+				End:      m.Location.Offset.End,
+				RuleName: m.RuleName,
+			}
+			if r, ok := ruleMap[m.RuleID]; ok && secret.RuleName == "" {
+				secret.RuleName = r.Name
+			}
+			for _, p := range provs {
+				if fp, ok := p.(types.FileProvenance); ok {
+					secrets[fp.FilePath] = append(secrets[fp.FilePath], secret)
+				}
+			}
+		}
+	}
+	return secrets, nil
+}
+
+// fixSelected reports whether a finding matches the --finding and --rule filters.
+func fixSelected(f *types.Finding) bool {
+	if len(fixRules) > 0 {
+		found := false
+		for _, id := range fixRules {
+			if f.RuleID == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(fixFindings) == 0 {
+		return true
+	}
+	for _, id := range fixFindings {
+		if id != "" && strings.HasPrefix(f.ID, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// capturesSecret reports whether the matches of a rule capture the secret
+// itself. Rules in the "reference" category capture where a secret is kept
+// instead, such as the variable np.ci.2 finds a CI step printing; replacing
+// that would break the code and leave the secret in place. Rules not in
+// ruleMap are assumed to capture the secret.
+func capturesSecret(ruleMap map[string]*types.Rule, ruleID string) bool {
+	if r, ok := ruleMap[ruleID]; ok {
+		return !slices.Contains(r.Categories, "reference")
+	}
+	return true
+}
+
+// secretValue returns the part of a match to replace: the secret named
+// group if the rule has one, otherwise the last capture group (the secret
+// follows the identifier in multi-group rules), otherwise the whole match.
+func secretValue(m *types.Match) []byte {
+	for _, name := range []string{"secret", "token", "key", "password", "secret_key", "api_key"} {
+		if v := m.NamedGroups[name]; len(v) > 0 {
+			return v
+		}
+	}
+	if n := len(m.Groups); n > 0 && len(m.Groups[n-1]) > 0 {
+		return m.Groups[n-1]
+	}
+	return m.Snippet.Matching
+}
+
+// displayPath returns path relative to the working directory when it is
+// inside it, with forward slashes, for patch headers and messages.
+func displayPath(path string) string {
+	if filepath.IsAbs(path) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
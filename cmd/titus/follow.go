@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/praetorian-inc/titus/pkg/enum"
+	"github.com/praetorian-inc/titus/pkg/matcher"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// followRecord is one line of `scan --follow` output: a match as printed by
+// `--format json`, plus the log file it was found in.
+type followRecord struct {
+	Path string
+	*types.Match
+}
+
+// runFollow tails the log files matching patterns until interrupted, scanning
+// appended lines and printing each match as a JSON line on stdout. Matches are
+// also recorded in the output datastore.
+func runFollow(cmd *cobra.Command, patterns []string) error {
+	if scanGit || scanBrowser || scanProfile != "" || scanPreset != "" || scanEnv {
+		return fmt.Errorf("--follow cannot be used with --git, --browser, --profile, --preset or --env")
+	}
+	follower, err := enum.NewFollower(patterns)
+	if err != nil {
+		return err
+	}
+
+	rules, err := loadRuleSelection(scanRulesPath, ruleFilter(scanRulesInclude, scanRulesExclude, scanCategories, scanExcludeCategories), scanRuleset, scanRulePacks, scanRulePackDirs)
+	if err != nil {
+		return fmt.Errorf("loading rules: %w", err)
+	}
+	ruleMap := make(map[string]*types.Rule)
+	for _, r := range rules {
+		ruleMap[r.ID] = r
+	}
+
+	m, err := matcher.New(matcher.Config{
+		Rules:        rules,
+		ContextLines: scanContextLines,
+		ContextBytes: scanContextBytes,
+		WarnFunc: func(format string, args ...any) {
+			fmt.Fprintf(os.Stderr, format, args...)
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("creating matcher: %w", err)
+	}
+	defer m.Close()
+
+	s, ds, err := openScanStore(scanOutputPath, scanStoreBlobs)
+	if err != nil {
+		return err
+	}
+	if ds != nil {
+		defer ds.Close()
+	} else {
+		defer s.Close()
+	}
+	for _, r := range rules {
+		if err := s.AddRule(r); err != nil {
+			return fmt.Errorf("storing rule: %w", err)
+		}
+	}
+	if _, err := resolveFingerprint(s, scanFingerprintVersion); err != nil {
+		return err
+	}
+
+	engine, err := initValidationEngine()
+	if err != nil {
+		return err
+	}
+	audit, err := openValidationAudit(scanValidateAudit, engine)
+	if err != nil {
+		return err
+	}
+	if audit != nil {
+		defer closeValidationAudit(audit)
+	}
+	if engine != nil {
+		matcher.SetCanValidate(m, engine.CanValidate)
+	}
+
+	sink, err := openSIEMSink(scanSIEMTarget, scanSIEMFormat)
+	if err != nil {
+		return err
+	}
+	if sink != nil {
+		defer sink.Close()
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	f := &logFollower{
+		pipeline: &streamPipeline{
+			matcher:   m,
+			store:     s,
+			ruleMap:   ruleMap,
+			validator: engine,
+			siem:      sink,
+			out:       cmd.ErrOrStderr(),
+		},
+		encoder: json.NewEncoder(cmd.OutOrStdout()),
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Following %d pattern(s) (%d rules). Press Ctrl+C to stop.\n", len(patterns), len(rules))
+	return follower.Follow(ctx, func(lines enum.LogLines) error {
+		return f.process(ctx, lines)
+	})
+}
+
+// logFollower scans batches of log lines and streams their matches as JSONL.
+type logFollower struct {
+	pipeline *streamPipeline
+	encoder  *json.Encoder
+}
+
+// process scans one batch of lines. Line numbers are made relative to the log
+// file rather than the batch. Match errors are logged and skipped so one bad
+// batch never stops following.
+func (f *logFollower) process(ctx context.Context, lines enum.LogLines) error {
+	prov := types.FileProvenance{FilePath: lines.Path}
+	reported, _, err := f.pipeline.scan(ctx, lines.Content, types.ComputeBlobID(lines.Content), prov, lines.FirstLine)
+	if err != nil {
+		return err
+	}
+	flagLikelyFalsePositives(f.pipeline.store, reported)
+
+	for _, match := range reported {
+		if err := f.encoder.Encode(followRecord{Path: lines.Path, Match: match}); err != nil {
+			return fmt.Errorf("writing match: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// githubSummaryFormat is the --format of scan and report that writes a
+// GitHub Actions job summary.
+const githubSummaryFormat = "github-summary"
+
+const (
+	// githubSummaryMaxRows caps the findings listed in a job summary, which
+	// GitHub limits to 1 MiB per step.
+	githubSummaryMaxRows = 200
+
+	// githubSummarySnippetLen caps each snippet shown in a job summary.
+	githubSummarySnippetLen = 300
+)
+
+// summarySeverity is how a finding is ranked in a job summary.
+type summarySeverity struct {
+	emoji string
+	label string
+}
+
+var (
+	severityActive     = summarySeverity{"🔴", "active"}
+	severityUnverified = summarySeverity{"🟠", "unverified"}
+	severityLikelyFP   = summarySeverity{"🟡", "likely test data"}
+	severityRevoked    = summarySeverity{"⚪", "revoked"}
+)
+
+// findingSeverity ranks a finding by its matches: active if any secret was
+// confirmed live, revoked if validation rejected it, likely test data if
+// every match looks like it, and otherwise unverified.
+func findingSeverity(matches []*types.Match) summarySeverity {
+	invalid, likelyFP := len(matches) > 0, len(matches) > 0
+	for _, m := range matches {
+		if m.Active() {
+			return severityActive
+		}
+		if m.ValidationResult == nil || m.ValidationResult.Status != types.StatusInvalid {
+			invalid = false
+		}
+		if !m.LikelyFP {
+			likelyFP = false
+		}
+	}
+	switch {
+	case invalid:
+		return severityRevoked
+	case likelyFP:
+		return severityLikelyFP
+	}
+	return severityUnverified
+}
+
+// writeGitHubSummary writes findings as a Markdown job summary: appended
+// to the file named by GITHUB_STEP_SUMMARY when running in GitHub Actions,
+// otherwise written to out. Findings must already be sorted.
+func writeGitHubSummary(out io.Writer, s store.Store, findings []*types.Finding, matchesByFinding map[string][]*types.Match, ruleMap map[string]*types.Rule) error {
+	summary := githubSummary(s, findings, matchesByFinding, ruleMap)
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		_, err := io.WriteString(out, summary)
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening job summary: %w", err)
+	}
+	if _, err := io.WriteString(f, summary); err != nil {
+		f.Close()
+		return fmt.Errorf("writing job summary: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing job summary: %w", err)
+	}
+	statusf(os.Stderr, "Job summary written to %s\n", path)
+	return nil
+}
+
+// githubSummary renders the job summary: counts by severity, a table of
+// findings, and each finding's snippet in a collapsed section. Secrets are
+// masked in snippets, since anyone who can read the repository's workflow
+// runs can read its job summaries.
+func githubSummary(s store.Store, findings []*types.Finding, matchesByFinding map[string][]*types.Match, ruleMap map[string]*types.Rule) string {
+	var b strings.Builder
+	b.WriteString("## Titus secrets scan\n\n")
+	if len(findings) == 0 {
+		b.WriteString("No secrets found.\n")
+		return b.String()
+	}
+
+	severities := make([]summarySeverity, len(findings))
+	counts := make(map[summarySeverity]int)
+	for i, f := range findings {
+		severities[i] = findingSeverity(matchesByFinding[f.ID])
+		counts[severities[i]]++
+	}
+	noun := "findings"
+	if len(findings) == 1 {
+		noun = "finding"
+	}
+	fmt.Fprintf(&b, "**%d %s**:", len(findings), noun)
+	for _, sev := range []summarySeverity{severityActive, severityUnverified, severityLikelyFP, severityRevoked} {
+		if counts[sev] > 0 {
+			fmt.Fprintf(&b, " %s %d %s", sev.emoji, counts[sev], sev.label)
+		}
+	}
+	b.WriteString("\n\n")
+
+	// Most severe first, keeping the given order otherwise
+	order := make([]int, len(findings))
+	for i := range order {
+		order[i] = i
+	}
+	rank := map[summarySeverity]int{severityActive: 0, severityUnverified: 1, severityLikelyFP: 2, severityRevoked: 3}
+	sort.SliceStable(order, func(i, j int) bool { return rank[severities[order[i]]] < rank[severities[order[j]]] })
+	shown := order
+	if len(shown) > githubSummaryMaxRows {
+		shown = shown[:githubSummaryMaxRows]
+	}
+
+	b.WriteString("| | Rule | Location | Finding | Validation |\n")
+	b.WriteString("|---|---|---|---|---|\n")
+	for _, i := range shown {
+		f := findings[i]
+		var first *types.Match
+		if ms := matchesByFinding[f.ID]; len(ms) > 0 {
+			first = ms[0]
+		}
+		validation := "not validated"
+		if first != nil && first.ValidationResult != nil {
+			validation = formatValidation(first.ValidationResult)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n",
+			severities[i].emoji,
+			markdownCell(summaryRuleName(f.RuleID, ruleMap)),
+			markdownCode(summaryLocation(s, first)),
+			markdownCode(shortID(f.ID)),
+			markdownCell(validation))
+	}
+	if len(order) > len(shown) {
+		fmt.Fprintf(&b, "\n%d more findings not shown; run `titus report` for all of them.\n", len(order)-len(shown))
+	}
+
+	b.WriteString("\n")
+	for _, i := range shown {
+		f := findings[i]
+		ms := matchesByFinding[f.ID]
+		if len(ms) == 0 {
+			continue
+		}
+		m := ms[0]
+		snippet := formatSnippet(maskSecrets(m.Snippet.Before, m), maskSecrets(m.Snippet.Matching, m), maskSecrets(m.Snippet.After, m), githubSummarySnippetLen)
+		fmt.Fprintf(&b, "<details><summary>%s %s in %s</summary>\n\n",
+			severities[i].emoji, htmlText(summaryRuleName(f.RuleID, ruleMap)), htmlText(summaryLocation(s, m)))
+		fence := "```"
+		for strings.Contains(snippet, fence) {
+			fence += "`"
+		}
+		fmt.Fprintf(&b, "%s\n%s\n%s\n\n</details>\n\n", fence, strings.TrimRight(snippet, "\n"), fence)
+	}
+	return b.String()
+}
+
+// summaryRuleName returns the name of a rule, or its ID if it's unknown.
+func summaryRuleName(ruleID string, ruleMap map[string]*types.Rule) string {
+	if r, ok := ruleMap[ruleID]; ok {
+		return r.Name
+	}
+	return ruleID
+}
+
+// summaryLocation returns path:line for a match, or its blob ID if it has
+// no path.
+func summaryLocation(s store.Store, m *types.Match) string {
+	if m == nil {
+		return "-"
+	}
+	place := m.BlobID.Hex()
+	if prov, err := s.GetProvenance(m.BlobID); err == nil && prov != nil && prov.Path() != "" {
+		place = prov.Path()
+	}
+	if m.Location.Source.Start.Line > 0 {
+		place = fmt.Sprintf("%s:%d", place, m.Location.Source.Start.Line)
+	}
+	return place
+}
+
+// maskSecrets replaces a match's capture groups in b with [REDACTED],
+// longest first so a group containing another is masked whole.
+func maskSecrets(b []byte, m *types.Match) []byte {
+	groups := append([][]byte(nil), m.Groups...)
+	sort.Slice(groups, func(i, j int) bool { return len(groups[i]) > len(groups[j]) })
+	for _, g := range groups {
+		if len(g) > 0 {
+			b = bytes.ReplaceAll(b, g, []byte("[REDACTED]"))
+		}
+	}
+	return b
+}
+
+// shortID abbreviates a finding ID for display.
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// markdownCell escapes text for a Markdown table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return htmlText(s)
+}
+
+// markdownCode formats text as inline code in a Markdown table cell.
+func markdownCode(s string) string {
+	s = strings.ReplaceAll(s, "`", "'")
+	s = strings.ReplaceAll(s, "\n", " ")
+	return "`" + strings.ReplaceAll(s, "|", `\|`) + "`"
+}
+
+// htmlText escapes the characters GitHub would read as HTML.
+func htmlText(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubSummary(t *testing.T) {
+	s := store.NewMemory()
+	blob := types.ComputeBlobID([]byte("creds"))
+	require.NoError(t, s.AddProvenance(blob, types.FileProvenance{FilePath: "deploy/prod|env"}))
+
+	live := &types.Match{
+		BlobID:           blob,
+		RuleID:           "np.github.1",
+		Groups:           [][]byte{[]byte("ghp_livetoken")},
+		Snippet:          types.Snippet{Before: []byte("TOKEN="), Matching: []byte("ghp_livetoken"), After: []byte("\n```")},
+		ValidationResult: types.NewValidationResult(types.StatusValid, 1, "authenticated as octocat"),
+	}
+	live.Location.Source.Start.Line = 3
+	unverified := &types.Match{BlobID: blob, RuleID: "np.aws.1", Groups: [][]byte{[]byte("AKIAEXAMPLE")}, Snippet: types.Snippet{Matching: []byte("AKIAEXAMPLE")}}
+	revoked := &types.Match{BlobID: blob, RuleID: "np.aws.1", Groups: [][]byte{[]byte("AKIAOLD")},
+		ValidationResult: types.NewValidationResult(types.StatusInvalid, 1, "credentials rejected")}
+
+	findings := []*types.Finding{
+		{ID: "revokedfinding0000", RuleID: "np.aws.1"},
+		{ID: "livefinding0000000", RuleID: "np.github.1"},
+		{ID: "unverifiedfinding0", RuleID: "np.aws.1"},
+	}
+	byFinding := map[string][]*types.Match{
+		"revokedfinding0000": {revoked},
+		"livefinding0000000": {live},
+		"unverifiedfinding0": {unverified},
+	}
+	ruleMap := map[string]*types.Rule{"np.github.1": {ID: "np.github.1", Name: "GitHub <PAT>"}}
+
+	summary := githubSummary(s, findings, byFinding, ruleMap)
+	assert.True(t, strings.HasPrefix(summary, "## Titus secrets scan\n\n**3 findings**: 🔴 1 active 🟠 1 unverified ⚪ 1 revoked\n"), summary)
+	assert.Contains(t, summary, "| 🔴 | GitHub &lt;PAT&gt; | `deploy/prod\\|env:3` | `livefinding0` | valid (authenticated as octocat) |\n")
+	assert.Contains(t, summary, "| ⚪ | np.aws.1 | `deploy/prod\\|env` | `revokedfindi` | invalid (credentials rejected) |\n")
+
+	// Rows go from most to least severe
+	assert.Less(t, strings.Index(summary, "| 🔴"), strings.Index(summary, "| 🟠"))
+	assert.Less(t, strings.Index(summary, "| 🟠"), strings.Index(summary, "| ⚪"))
+
+	// Snippets are collapsed, fenced past any backticks in them, and masked
+	assert.Contains(t, summary, "<details><summary>🔴 GitHub &lt;PAT&gt; in deploy/prod|env:3</summary>\n\n````\nTOKEN=[REDACTED]\n```\n````\n\n</details>")
+	assert.NotContains(t, summary, "ghp_livetoken")
+	assert.NotContains(t, summary, "AKIAEXAMPLE")
+}
+
+func TestGitHubSummary_NoFindings(t *testing.T) {
+	assert.Equal(t, "## Titus secrets scan\n\nNo secrets found.\n", githubSummary(store.NewMemory(), nil, nil, nil))
+}
+
+func TestFindingSeverity(t *testing.T) {
+	fp := &types.Match{LikelyFP: true}
+	assert.Equal(t, severityLikelyFP, findingSeverity([]*types.Match{fp}))
+	assert.Equal(t, severityUnverified, findingSeverity([]*types.Match{fp, {}}))
+	assert.Equal(t, severityUnverified, findingSeverity(nil))
+	assert.Equal(t, severityActive, findingSeverity([]*types.Match{fp, {ValidationResult: types.NewValidationResult(types.StatusValid, 1, "")}}))
+}
+
+func TestWriteGitHubSummary_StepSummaryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.md")
+	require.NoError(t, os.WriteFile(path, []byte("# Earlier step\n"), 0o644))
+	t.Setenv("GITHUB_STEP_SUMMARY", path)
+
+	var out strings.Builder
+	require.NoError(t, writeGitHubSummary(&out, store.NewMemory(), nil, nil, nil))
+	assert.Empty(t, out.String())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "# Earlier step\n## Titus secrets scan\n\nNo secrets found.\n", string(data))
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// gitHistoryFilter narrows git history to the commits of interest, for
+// --since, --author and --ref. It applies only to blobs and matches with
+// git provenance; files scanned from disk are kept.
+type gitHistoryFilter struct {
+	since  time.Time      // zero = any time
+	author *regexp.Regexp // nil = any author
+	refs   []string       // empty = every ref
+
+	// reachable caches, per repository, the commits reachable from refs
+	reachable map[string]map[string]bool
+}
+
+// newGitHistoryFilter parses the --since, --author and --ref values. It
+// returns nil if they are all empty.
+func newGitHistoryFilter(since, author string, refs []string) (*gitHistoryFilter, error) {
+	if since == "" && author == "" && len(refs) == 0 {
+		return nil, nil
+	}
+	f := &gitHistoryFilter{refs: refs, reachable: make(map[string]map[string]bool)}
+	if since != "" {
+		t, err := parseSince(since)
+		if err != nil {
+			return nil, err
+		}
+		f.since = t
+	}
+	if author != "" {
+		re, err := regexp.Compile("(?i)" + author)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --author pattern: %w", err)
+		}
+		f.author = re
+	}
+	for _, ref := range refs {
+		if ref == "" || strings.HasPrefix(ref, "-") {
+			return nil, fmt.Errorf("invalid --ref %q", ref)
+		}
+	}
+	return f, nil
+}
+
+// parseSince parses a --since value: a date (2023-01-01) or an RFC 3339
+// timestamp.
+func parseSince(s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: want a date like 2023-01-01 or an RFC 3339 timestamp", s)
+}
+
+// matchesCommit reports whether a commit was authored since f.since by an
+// author matching f.author. A blob whose commit isn't known can't be shown
+// to match, so it doesn't.
+func (f *gitHistoryFilter) matchesCommit(c *types.CommitMetadata) bool {
+	if f.since.IsZero() && f.author == nil {
+		return true
+	}
+	if c == nil {
+		return false
+	}
+	if !f.since.IsZero() && c.Time().Before(f.since) {
+		return false
+	}
+	if f.author != nil && !f.author.MatchString(c.AuthorName+" <"+c.AuthorEmail+">") {
+		return false
+	}
+	return true
+}
+
+// keepBlob reports whether a blob the scan enumerated should be scanned.
+// Refs are applied by the git enumerator, so only --since and --author are
+// checked here.
+func (f *gitHistoryFilter) keepBlob(prov types.Provenance) bool {
+	gp, ok := prov.(types.GitProvenance)
+	return !ok || f.matchesCommit(gp.Commit)
+}
+
+// onRefs reports whether a commit of the repository at repoPath is
+// reachable from f.refs, listing the history of the refs with git the
+// first time a repository is asked about.
+func (f *gitHistoryFilter) onRefs(repoPath, commit string) (bool, error) {
+	if len(f.refs) == 0 {
+		return true, nil
+	}
+	commits, ok := f.reachable[repoPath]
+	if !ok {
+		cmd := exec.CommandContext(context.Background(), "git", append([]string{"rev-list"}, f.refs...)...)
+		cmd.Dir = repoPath
+		out, err := cmd.Output()
+		if err != nil {
+			return false, fmt.Errorf("listing the history of --ref %s in %s (rescan with --ref if the repository is gone): %w", strings.Join(f.refs, ","), repoPath, err)
+		}
+		commits = make(map[string]bool)
+		for _, id := range strings.Fields(string(out)) {
+			commits[id] = true
+		}
+		f.reachable[repoPath] = commits
+	}
+	return commits[commit], nil
+}
+
+// keepGitProvenance reports whether a location of a previous scan's match
+// passes the filter.
+func (f *gitHistoryFilter) keepGitProvenance(gp types.GitProvenance) (bool, error) {
+	if !f.matchesCommit(gp.Commit) {
+		return false, nil
+	}
+	if len(f.refs) == 0 {
+		return true, nil
+	}
+	if gp.Commit == nil {
+		return false, nil
+	}
+	return f.onRefs(gp.RepoPath, gp.Commit.CommitID)
+}
+
+// filterByGitHistory keeps the matches found in git history that f keeps
+// at least one git location of, the matches not found in git history, and
+// the findings that still have matches.
+func filterByGitHistory(s store.Store, findings []*types.Finding, matches []*types.Match, f *gitHistoryFilter) ([]*types.Finding, []*types.Match, error) {
+	if f == nil {
+		return findings, matches, nil
+	}
+	keepBlob := make(map[types.BlobID]bool)
+	var keptMatches []*types.Match
+	for _, m := range matches {
+		keep, ok := keepBlob[m.BlobID]
+		if !ok {
+			provs, err := s.GetAllProvenance(m.BlobID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("retrieving provenance: %w", err)
+			}
+			keep = true
+			for _, prov := range provs {
+				gp, isGit := prov.(types.GitProvenance)
+				if !isGit {
+					continue
+				}
+				if keep, err = f.keepGitProvenance(gp); err != nil {
+					return nil, nil, err
+				}
+				if keep {
+					break
+				}
+			}
+			keepBlob[m.BlobID] = keep
+		}
+		if keep {
+			keptMatches = append(keptMatches, m)
+		}
+	}
+
+	matchesByFinding := buildFindingMatchMap(findings, keptMatches)
+	var keptFindings []*types.Finding
+	for _, f := range findings {
+		if len(matchesByFinding[f.ID]) > 0 {
+			keptFindings = append(keptFindings, f)
+		}
+	}
+	return keptFindings, keptMatches, nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGitHistoryFilter(t *testing.T) {
+	f, err := newGitHistoryFilter("", "", nil)
+	require.NoError(t, err)
+	assert.Nil(t, f)
+
+	f, err = newGitHistoryFilter("2023-01-01", "alice", nil)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), f.since)
+
+	_, err = newGitHistoryFilter("last week", "", nil)
+	assert.ErrorContains(t, err, "invalid --since")
+	_, err = newGitHistoryFilter("", "(", nil)
+	assert.ErrorContains(t, err, "invalid --author")
+	_, err = newGitHistoryFilter("", "", []string{"--all"})
+	assert.ErrorContains(t, err, "invalid --ref")
+}
+
+func TestGitHistoryFilter_MatchesCommit(t *testing.T) {
+	f, err := newGitHistoryFilter("2023-01-01", "alice", nil)
+	require.NoError(t, err)
+
+	recent := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	old := time.Date(2015, 5, 1, 0, 0, 0, 0, time.UTC)
+	assert.True(t, f.matchesCommit(&types.CommitMetadata{AuthorName: "Alice", AuthorEmail: "a@example.com", AuthorTimestamp: recent}))
+	assert.True(t, f.matchesCommit(&types.CommitMetadata{AuthorName: "A", AuthorEmail: "alice@example.com", AuthorTimestamp: recent}))
+	assert.False(t, f.matchesCommit(&types.CommitMetadata{AuthorName: "Alice", AuthorTimestamp: old}))
+	assert.False(t, f.matchesCommit(&types.CommitMetadata{AuthorName: "Bob", AuthorTimestamp: recent}))
+	assert.False(t, f.matchesCommit(nil), "unknown commits can't be shown to match")
+
+	assert.True(t, f.keepBlob(types.FileProvenance{FilePath: "config.yml"}), "files on disk are kept")
+	assert.False(t, f.keepBlob(types.GitProvenance{BlobPath: "config.yml", Commit: &types.CommitMetadata{AuthorName: "Bob", AuthorTimestamp: recent}}))
+}
+
+func TestFilterByGitHistory(t *testing.T) {
+	s := store.NewMemory()
+	add := func(findingID string, content string, prov types.Provenance) {
+		blobID := types.ComputeBlobID([]byte(content))
+		groups := [][]byte{[]byte(content)}
+		require.NoError(t, s.AddBlob(blobID, int64(len(content))))
+		require.NoError(t, s.AddProvenance(blobID, prov))
+		require.NoError(t, s.AddMatch(&types.Match{BlobID: blobID, StructuralID: findingID + "-m", RuleID: "np.test.1", Groups: groups}))
+		require.NoError(t, s.AddFinding(&types.Finding{ID: findingID, RuleID: "np.test.1", Groups: groups}))
+	}
+	add("recent", "secret-1", types.GitProvenance{BlobPath: "a.env", Commit: &types.CommitMetadata{AuthorName: "Alice", AuthorTimestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}})
+	add("old", "secret-2", types.GitProvenance{BlobPath: "b.env", Commit: &types.CommitMetadata{AuthorName: "Alice", AuthorTimestamp: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)}})
+	add("disk", "secret-3", types.FileProvenance{FilePath: "c.env"})
+
+	findings, err := s.GetFindings()
+	require.NoError(t, err)
+	matches, err := s.GetAllMatches()
+	require.NoError(t, err)
+
+	f, err := newGitHistoryFilter("2023-01-01", "", nil)
+	require.NoError(t, err)
+	kept, keptMatches, err := filterByGitHistory(s, findings, matches, f)
+	require.NoError(t, err)
+	var ids []string
+	for _, finding := range kept {
+		ids = append(ids, finding.ID)
+	}
+	assert.ElementsMatch(t, []string{"recent", "disk"}, ids)
+	assert.Len(t, keptMatches, 2)
+
+	kept, _, err = filterByGitHistory(s, findings, matches, nil)
+	require.NoError(t, err)
+	assert.Len(t, kept, 3)
+}
+
+func TestGitHistoryFilter_Refs(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q", "-b", "main")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0o644))
+	git("add", ".")
+	git("commit", "-q", "-m", "main")
+	onMain := git("rev-parse", "HEAD")
+	git("checkout", "-q", "-b", "feature")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.txt"), []byte("b"), 0o644))
+	git("add", ".")
+	git("commit", "-q", "-m", "feature")
+	onFeature := git("rev-parse", "HEAD")
+
+	f, err := newGitHistoryFilter("", "", []string{"main"})
+	require.NoError(t, err)
+	keep, err := f.keepGitProvenance(types.GitProvenance{RepoPath: dir, Commit: &types.CommitMetadata{CommitID: onMain}})
+	require.NoError(t, err)
+	assert.True(t, keep)
+	keep, err = f.keepGitProvenance(types.GitProvenance{RepoPath: dir, Commit: &types.CommitMetadata{CommitID: onFeature}})
+	require.NoError(t, err)
+	assert.False(t, keep)
+
+	_, err = f.keepGitProvenance(types.GitProvenance{RepoPath: t.TempDir(), Commit: &types.CommitMetadata{CommitID: onMain}})
+	assert.Error(t, err)
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/praetorian-inc/titus/pkg/enum"
+	"github.com/praetorian-inc/titus/pkg/filetype"
 	"github.com/praetorian-inc/titus/pkg/matcher"
 	"github.com/praetorian-inc/titus/pkg/store"
 	"github.com/praetorian-inc/titus/pkg/types"
@@ -24,6 +27,13 @@ var (
 	githubGit          bool
 	githubSkipForks    bool
 	githubRateLimit    float64
+	githubWorkDir      string
+	githubKeepClone    bool
+	githubSubmodules   bool
+	githubCloneRetries int
+	githubDedupe       string
+	githubNoDiscovery  bool
+	githubFingerprint  string
 )
 
 var githubCmd = &cobra.Command{
@@ -35,6 +45,8 @@ Supports github.com and GitHub Enterprise Server instances.
 Authentication:
   No token needed for public repositories (60 requests/hour).
   Use --token or GITHUB_TOKEN env var for private repos and higher rate limits (5000/hour).
+  Without either, a token is taken from 'gh auth token' or git's credential
+  helpers if you are logged in; --no-token-discovery turns this off.
 
 GitHub Enterprise:
   Use --url or GITHUB_BASE_URL env var to point at a GHE Server instance.
@@ -66,6 +78,7 @@ Use --no-clone to fetch files via API instead of cloning (requires token).`,
 
 func init() {
 	githubScanCmd.Flags().StringVar(&githubToken, "token", "", "GitHub API token (or GITHUB_TOKEN env; optional for public repos)")
+	githubScanCmd.Flags().BoolVar(&githubNoDiscovery, "no-token-discovery", false, "Don't look for a token from the gh CLI or git credential helpers")
 	githubScanCmd.Flags().StringVar(&githubBaseURL, "url", "", "GitHub Enterprise base URL (or GITHUB_BASE_URL env; e.g., https://github.example.com)")
 	githubScanCmd.Flags().StringVar(&githubOrg, "org", "", "Scan all repositories in organization")
 	githubScanCmd.Flags().StringVar(&githubUser, "user", "", "Scan all repositories for user")
@@ -75,8 +88,15 @@ func init() {
 	githubScanCmd.Flags().BoolVar(&githubGit, "git", false, "Scan full git history (slower; default scans only current files)")
 	githubScanCmd.Flags().BoolVar(&githubSkipForks, "skip-forks", false, "Skip forked repositories when scanning orgs or users")
 	githubScanCmd.Flags().Float64Var(&githubRateLimit, "rate-limit", 0, "Delay in seconds between repository clones (e.g., 2 or 0.5; 0 = no delay)")
+	githubScanCmd.Flags().StringVar(&githubWorkDir, "work-dir", "", "Directory for temporary clones (default: system temp dir)")
+	githubScanCmd.Flags().BoolVar(&githubKeepClone, "keep-clone", false, "Keep temporary clones after scanning (for debugging)")
+	githubScanCmd.Flags().BoolVar(&githubSubmodules, "submodules", false, "Clone and scan repositories' submodules too")
+	githubScanCmd.Flags().IntVar(&githubCloneRetries, "clone-retries", enum.DefaultCloneRetries, "Times to retry a failed clone, with backoff")
+	githubScanCmd.Flags().StringVar(&githubDedupe, "dedupe", dedupeGlobal, "Where a blob found in several places is recorded: global (first place only), per-target (every place, matched once) or none (every place, matched each time)")
+	githubScanCmd.Flags().StringVar(&githubFingerprint, "fingerprint-version", "", "Finding ID algorithm for a new datastore: v1 (Nosey Parker compatible) or v2 (stable across rule edits); default: the datastore's, else v1")
 
 	githubCmd.Flags().StringVar(&githubToken, "token", "", "GitHub API token (or GITHUB_TOKEN env; optional for public repos)")
+	githubCmd.Flags().BoolVar(&githubNoDiscovery, "no-token-discovery", false, "Don't look for a token from the gh CLI or git credential helpers")
 	githubCmd.Flags().StringVar(&githubBaseURL, "url", "", "GitHub Enterprise base URL (or GITHUB_BASE_URL env; e.g., https://github.example.com)")
 	githubCmd.Flags().StringVar(&githubOrg, "org", "", "Scan all repositories in organization")
 	githubCmd.Flags().StringVar(&githubUser, "user", "", "Scan all repositories for user")
@@ -86,20 +106,27 @@ func init() {
 	githubCmd.Flags().BoolVar(&githubGit, "git", false, "Scan full git history (slower; default scans only current files)")
 	githubCmd.Flags().BoolVar(&githubSkipForks, "skip-forks", false, "Skip forked repositories when scanning orgs or users")
 	githubCmd.Flags().Float64Var(&githubRateLimit, "rate-limit", 0, "Delay in seconds between repository clones (e.g., 2 or 0.5; 0 = no delay)")
+	githubCmd.Flags().StringVar(&githubWorkDir, "work-dir", "", "Directory for temporary clones (default: system temp dir)")
+	githubCmd.Flags().BoolVar(&githubKeepClone, "keep-clone", false, "Keep temporary clones after scanning (for debugging)")
+	githubCmd.Flags().BoolVar(&githubSubmodules, "submodules", false, "Clone and scan repositories' submodules too")
+	githubCmd.Flags().IntVar(&githubCloneRetries, "clone-retries", enum.DefaultCloneRetries, "Times to retry a failed clone, with backoff")
+	githubCmd.Flags().StringVar(&githubDedupe, "dedupe", dedupeGlobal, "Where a blob found in several places is recorded: global (first place only), per-target (every place, matched once) or none (every place, matched each time)")
+	githubCmd.Flags().StringVar(&githubFingerprint, "fingerprint-version", "", "Finding ID algorithm for a new datastore: v1 (Nosey Parker compatible) or v2 (stable across rule edits); default: the datastore's, else v1")
 
 	githubCmd.AddCommand(githubScanCmd)
 }
 
 func runGitHubScan(cmd *cobra.Command, args []string) error {
-	token := githubToken
-	if token == "" {
-		token = os.Getenv("GITHUB_TOKEN")
+	if err := checkDedupeScope(githubDedupe, false); err != nil {
+		return err
 	}
 
 	baseURL := githubBaseURL
 	if baseURL == "" {
 		baseURL = os.Getenv("GITHUB_BASE_URL")
 	}
+	token := resolveToken(cmd.ErrOrStderr(), githubToken, "GITHUB_TOKEN", "github",
+		tokenHost(baseURL, "github.com"), !githubNoDiscovery)
 
 	if githubNoClone && token == "" {
 		return fmt.Errorf("--no-clone requires a GitHub API token: use --token or GITHUB_TOKEN")
@@ -187,8 +214,24 @@ func runGitHubScan(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	ctx := context.Background()
+	target := "github:" + githubOrg + githubUser
+	if repo != "" {
+		target = "github:" + owner + "/" + repo
+	}
+	fp, err := resolveFingerprint(s, githubFingerprint)
+	if err != nil {
+		return err
+	}
+	run, err := beginScan(s, target, rules)
+	if err != nil {
+		return err
+	}
+
+	// Cancel on Ctrl+C so in-flight clones are killed and their temp dirs removed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 	var enumerator enum.Enumerator
+	var cloneEnum *enum.CloneEnumerator
 
 	if githubNoClone {
 		enumerator = ghEnum
@@ -201,11 +244,16 @@ func runGitHubScan(cmd *cobra.Command, args []string) error {
 
 		fmt.Fprintf(cmd.ErrOrStderr(), "Found %d repositories to scan\n\n", len(repos))
 
-		cloneEnum := enum.NewCloneEnumerator(repos, enum.Config{
+		cloneEnum = enum.NewCloneEnumerator(repos, enum.Config{
 			MaxFileSize: 10 * 1024 * 1024,
 		})
 		cloneEnum.Git = githubGit
 		cloneEnum.Token = token
+		cloneEnum.WorkDir = githubWorkDir
+		cloneEnum.KeepClone = githubKeepClone
+		cloneEnum.Submodules = githubSubmodules
+		cloneEnum.KeepDuplicates = keepDuplicates(githubDedupe)
+		cloneEnum.Retries = githubCloneRetries
 		if githubRateLimit > 0 {
 			cloneEnum.Delay = time.Duration(githubRateLimit * float64(time.Second))
 		}
@@ -215,28 +263,38 @@ func runGitHubScan(cmd *cobra.Command, args []string) error {
 	matchCount := 0
 	findingCount := 0
 
+	progress := newScanProgress(cmd.ErrOrStderr())
+	dedupe := newBlobDedupe(githubDedupe, s, false)
+	// Repositories are named rather than found under a path, so findings are
+	// marked seen but never remediated
+	lifecycle := newScanLifecycle(target, true)
+	lifecycle.partial = true
 	err = enumerator.Enumerate(ctx, func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		lifecycle.see(blobID)
+
+		// Skip or only record blobs already scanned, per --dedupe
+		scan, recordOnly, err := dedupe.check(blobID)
+		if err != nil || !scan {
+			return err
+		}
 		if err := s.AddBlob(blobID, int64(len(content))); err != nil {
 			return fmt.Errorf("storing blob: %w", err)
 		}
 
-		if err := s.AddProvenance(blobID, prov); err != nil {
+		if err := s.AddProvenance(blobID, filetype.Tag(prov, content)); err != nil {
 			return fmt.Errorf("storing provenance: %w", err)
 		}
+		if recordOnly {
+			return nil
+		}
 
 		matches, err := m.MatchWithBlobID(content, blobID)
 		if err != nil {
 			return fmt.Errorf("matching content: %w", err)
 		}
 
-		for _, match := range matches {
-			startLine, startCol := types.ComputeLineColumn(content, int(match.Location.Offset.Start))
-			endLine, endCol := types.ComputeLineColumn(content, int(match.Location.Offset.End))
-			match.Location.Source.Start.Line = startLine
-			match.Location.Source.Start.Column = startCol
-			match.Location.Source.End.Line = endLine
-			match.Location.Source.End.Column = endCol
-		}
+		setLineColumns(content, matches)
+		progress.blob(blobID, prov, len(content), matches)
 
 		for _, match := range matches {
 			matchCount++
@@ -249,7 +307,7 @@ func runGitHubScan(cmd *cobra.Command, args []string) error {
 			if !ok {
 				return fmt.Errorf("rule not found: %s", match.RuleID)
 			}
-			findingID := types.ComputeFindingID(rule.StructuralID, match.Groups)
+			findingID := fp.FindingID(rule, match.Groups)
 			exists, err := s.FindingExists(findingID)
 			if err != nil {
 				return fmt.Errorf("checking finding: %w", err)
@@ -275,14 +333,25 @@ func runGitHubScan(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("scanning GitHub: %w", err)
 	}
 
-	fmt.Fprintf(cmd.OutOrStdout(), "GitHub scan complete: %d matches, %d findings\n", matchCount, findingCount)
-	fmt.Fprintf(cmd.OutOrStdout(), "Results stored in: %s\n", githubOutputPath)
+	if _, _, err := lifecycle.update(s, ruleMap); err != nil {
+		return fmt.Errorf("updating finding states: %w", err)
+	}
+	if err := finishScan(s, run); err != nil {
+		return err
+	}
+	progress.printRuleStats(ruleMap)
+	if cloneEnum != nil {
+		printCloneFailures(cmd.ErrOrStderr(), cloneEnum.Failures())
+	}
+	statusf(cmd.OutOrStdout(), "GitHub scan complete: %d matches, %d findings\n", matchCount, findingCount)
+	statusf(cmd.OutOrStdout(), "Results stored in: %s\n", githubOutputPath)
 
 	if githubOutputFormat == "json" {
 		matches, err := s.GetAllMatches()
 		if err != nil {
 			return fmt.Errorf("retrieving matches: %w", err)
 		}
+		newResultOrder(s).sortMatches(matches)
 		return outputMatches(cmd, matches)
 	}
 
@@ -290,6 +359,7 @@ func runGitHubScan(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("retrieving findings: %w", err)
 	}
+	newResultOrder(s).sortFindings(findings, nil)
 	return outputFindings(cmd, findings)
 }
 
@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/praetorian-inc/titus/pkg/enum"
+	"github.com/praetorian-inc/titus/pkg/filetype"
 	"github.com/praetorian-inc/titus/pkg/matcher"
 	"github.com/praetorian-inc/titus/pkg/store"
 	"github.com/praetorian-inc/titus/pkg/types"
@@ -23,6 +26,13 @@ var (
 	gitlabNoClone      bool
 	gitlabGit          bool
 	gitlabRateLimit    float64
+	gitlabWorkDir      string
+	gitlabKeepClone    bool
+	gitlabSubmodules   bool
+	gitlabCloneRetries int
+	gitlabDedupe       string
+	gitlabNoDiscovery  bool
+	gitlabFingerprint  string
 )
 
 var gitlabCmd = &cobra.Command{
@@ -31,6 +41,8 @@ var gitlabCmd = &cobra.Command{
 	Long: `Scan GitLab projects by cloning and scanning locally.
 No API token needed for public projects.
 Use --token or GITLAB_TOKEN for private projects and higher rate limits.
+Without either, a token is taken from glab's config or git's credential
+helpers if you are logged in; --no-token-discovery turns this off.
 Use --git to scan full git history (slower but finds deleted secrets).`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runGitLabScan,
@@ -49,6 +61,7 @@ Use --git to also scan full git history.`,
 
 func init() {
 	gitlabScanCmd.Flags().StringVar(&gitlabToken, "token", "", "GitLab token (or GITLAB_TOKEN env; optional for public projects)")
+	gitlabScanCmd.Flags().BoolVar(&gitlabNoDiscovery, "no-token-discovery", false, "Don't look for a token from the glab CLI or git credential helpers")
 	gitlabScanCmd.Flags().StringVar(&gitlabGroup, "group", "", "Scan all projects in group")
 	gitlabScanCmd.Flags().StringVar(&gitlabUser, "user", "", "Scan all projects for user")
 	gitlabScanCmd.Flags().StringVar(&gitlabBaseURL, "url", "", "GitLab base URL (default: gitlab.com)")
@@ -57,8 +70,15 @@ func init() {
 	gitlabScanCmd.Flags().BoolVar(&gitlabNoClone, "no-clone", false, "Fetch files via API instead of cloning (requires token, no git history)")
 	gitlabScanCmd.Flags().BoolVar(&gitlabGit, "git", false, "Scan full git history (slower; default scans only current files)")
 	gitlabScanCmd.Flags().Float64Var(&gitlabRateLimit, "rate-limit", 0, "Delay in seconds between project clones (e.g., 2 or 0.5; 0 = no delay)")
+	gitlabScanCmd.Flags().StringVar(&gitlabWorkDir, "work-dir", "", "Directory for temporary clones (default: system temp dir)")
+	gitlabScanCmd.Flags().BoolVar(&gitlabKeepClone, "keep-clone", false, "Keep temporary clones after scanning (for debugging)")
+	gitlabScanCmd.Flags().BoolVar(&gitlabSubmodules, "submodules", false, "Clone and scan projects' submodules too")
+	gitlabScanCmd.Flags().IntVar(&gitlabCloneRetries, "clone-retries", enum.DefaultCloneRetries, "Times to retry a failed clone, with backoff")
+	gitlabScanCmd.Flags().StringVar(&gitlabDedupe, "dedupe", dedupeGlobal, "Where a blob found in several places is recorded: global (first place only), per-target (every place, matched once) or none (every place, matched each time)")
+	gitlabScanCmd.Flags().StringVar(&gitlabFingerprint, "fingerprint-version", "", "Finding ID algorithm for a new datastore: v1 (Nosey Parker compatible) or v2 (stable across rule edits); default: the datastore's, else v1")
 
 	gitlabCmd.Flags().StringVar(&gitlabToken, "token", "", "GitLab token (or GITLAB_TOKEN env; optional for public projects)")
+	gitlabCmd.Flags().BoolVar(&gitlabNoDiscovery, "no-token-discovery", false, "Don't look for a token from the glab CLI or git credential helpers")
 	gitlabCmd.Flags().StringVar(&gitlabGroup, "group", "", "Scan all projects in group")
 	gitlabCmd.Flags().StringVar(&gitlabUser, "user", "", "Scan all projects for user")
 	gitlabCmd.Flags().StringVar(&gitlabBaseURL, "url", "", "GitLab base URL (default: gitlab.com)")
@@ -67,16 +87,24 @@ func init() {
 	gitlabCmd.Flags().BoolVar(&gitlabNoClone, "no-clone", false, "Fetch files via API instead of cloning (requires token, no git history)")
 	gitlabCmd.Flags().BoolVar(&gitlabGit, "git", false, "Scan full git history (slower; default scans only current files)")
 	gitlabCmd.Flags().Float64Var(&gitlabRateLimit, "rate-limit", 0, "Delay in seconds between project clones (e.g., 2 or 0.5; 0 = no delay)")
+	gitlabCmd.Flags().StringVar(&gitlabWorkDir, "work-dir", "", "Directory for temporary clones (default: system temp dir)")
+	gitlabCmd.Flags().BoolVar(&gitlabKeepClone, "keep-clone", false, "Keep temporary clones after scanning (for debugging)")
+	gitlabCmd.Flags().BoolVar(&gitlabSubmodules, "submodules", false, "Clone and scan projects' submodules too")
+	gitlabCmd.Flags().IntVar(&gitlabCloneRetries, "clone-retries", enum.DefaultCloneRetries, "Times to retry a failed clone, with backoff")
+	gitlabCmd.Flags().StringVar(&gitlabDedupe, "dedupe", dedupeGlobal, "Where a blob found in several places is recorded: global (first place only), per-target (every place, matched once) or none (every place, matched each time)")
+	gitlabCmd.Flags().StringVar(&gitlabFingerprint, "fingerprint-version", "", "Finding ID algorithm for a new datastore: v1 (Nosey Parker compatible) or v2 (stable across rule edits); default: the datastore's, else v1")
 
 	gitlabCmd.AddCommand(gitlabScanCmd)
 }
 
 func runGitLabScan(cmd *cobra.Command, args []string) error {
-	token := gitlabToken
-	if token == "" {
-		token = os.Getenv("GITLAB_TOKEN")
+	if err := checkDedupeScope(gitlabDedupe, false); err != nil {
+		return err
 	}
 
+	token := resolveToken(cmd.ErrOrStderr(), gitlabToken, "GITLAB_TOKEN", "gitlab",
+		tokenHost(gitlabBaseURL, "gitlab.com"), !gitlabNoDiscovery)
+
 	if gitlabNoClone && token == "" {
 		return fmt.Errorf("--no-clone requires a GitLab token: use --token or GITLAB_TOKEN")
 	}
@@ -155,8 +183,24 @@ func runGitLabScan(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	ctx := context.Background()
+	target := "gitlab:" + gitlabGroup + gitlabUser
+	if project != "" {
+		target = "gitlab:" + project
+	}
+	fp, err := resolveFingerprint(s, gitlabFingerprint)
+	if err != nil {
+		return err
+	}
+	run, err := beginScan(s, target, rules)
+	if err != nil {
+		return err
+	}
+
+	// Cancel on Ctrl+C so in-flight clones are killed and their temp dirs removed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 	var enumerator enum.Enumerator
+	var cloneEnum *enum.CloneEnumerator
 
 	if gitlabNoClone {
 		enumerator = glEnum
@@ -169,11 +213,16 @@ func runGitLabScan(cmd *cobra.Command, args []string) error {
 
 		fmt.Fprintf(cmd.ErrOrStderr(), "Found %d projects to scan\n\n", len(projects))
 
-		cloneEnum := enum.NewCloneEnumerator(projects, enum.Config{
+		cloneEnum = enum.NewCloneEnumerator(projects, enum.Config{
 			MaxFileSize: 10 * 1024 * 1024,
 		})
 		cloneEnum.Git = gitlabGit
 		cloneEnum.Token = token
+		cloneEnum.WorkDir = gitlabWorkDir
+		cloneEnum.KeepClone = gitlabKeepClone
+		cloneEnum.Submodules = gitlabSubmodules
+		cloneEnum.KeepDuplicates = keepDuplicates(gitlabDedupe)
+		cloneEnum.Retries = gitlabCloneRetries
 		if gitlabRateLimit > 0 {
 			cloneEnum.Delay = time.Duration(gitlabRateLimit * float64(time.Second))
 		}
@@ -183,28 +232,38 @@ func runGitLabScan(cmd *cobra.Command, args []string) error {
 	matchCount := 0
 	findingCount := 0
 
+	progress := newScanProgress(cmd.ErrOrStderr())
+	dedupe := newBlobDedupe(gitlabDedupe, s, false)
+	// Repositories are named rather than found under a path, so findings are
+	// marked seen but never remediated
+	lifecycle := newScanLifecycle(target, true)
+	lifecycle.partial = true
 	err = enumerator.Enumerate(ctx, func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		lifecycle.see(blobID)
+
+		// Skip or only record blobs already scanned, per --dedupe
+		scan, recordOnly, err := dedupe.check(blobID)
+		if err != nil || !scan {
+			return err
+		}
 		if err := s.AddBlob(blobID, int64(len(content))); err != nil {
 			return fmt.Errorf("storing blob: %w", err)
 		}
 
-		if err := s.AddProvenance(blobID, prov); err != nil {
+		if err := s.AddProvenance(blobID, filetype.Tag(prov, content)); err != nil {
 			return fmt.Errorf("storing provenance: %w", err)
 		}
+		if recordOnly {
+			return nil
+		}
 
 		matches, err := m.MatchWithBlobID(content, blobID)
 		if err != nil {
 			return fmt.Errorf("matching content: %w", err)
 		}
 
-		for _, match := range matches {
-			startLine, startCol := types.ComputeLineColumn(content, int(match.Location.Offset.Start))
-			endLine, endCol := types.ComputeLineColumn(content, int(match.Location.Offset.End))
-			match.Location.Source.Start.Line = startLine
-			match.Location.Source.Start.Column = startCol
-			match.Location.Source.End.Line = endLine
-			match.Location.Source.End.Column = endCol
-		}
+		setLineColumns(content, matches)
+		progress.blob(blobID, prov, len(content), matches)
 
 		for _, match := range matches {
 			matchCount++
@@ -217,7 +276,7 @@ func runGitLabScan(cmd *cobra.Command, args []string) error {
 			if !ok {
 				return fmt.Errorf("rule not found: %s", match.RuleID)
 			}
-			findingID := types.ComputeFindingID(rule.StructuralID, match.Groups)
+			findingID := fp.FindingID(rule, match.Groups)
 			exists, err := s.FindingExists(findingID)
 			if err != nil {
 				return fmt.Errorf("checking finding: %w", err)
@@ -243,14 +302,25 @@ func runGitLabScan(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("scanning: %w", err)
 	}
 
-	fmt.Fprintf(cmd.OutOrStdout(), "GitLab scan complete: %d matches, %d findings\n", matchCount, findingCount)
-	fmt.Fprintf(cmd.OutOrStdout(), "Results stored in: %s\n", gitlabOutputPath)
+	if _, _, err := lifecycle.update(s, ruleMap); err != nil {
+		return fmt.Errorf("updating finding states: %w", err)
+	}
+	if err := finishScan(s, run); err != nil {
+		return err
+	}
+	progress.printRuleStats(ruleMap)
+	if cloneEnum != nil {
+		printCloneFailures(cmd.ErrOrStderr(), cloneEnum.Failures())
+	}
+	statusf(cmd.OutOrStdout(), "GitLab scan complete: %d matches, %d findings\n", matchCount, findingCount)
+	statusf(cmd.OutOrStdout(), "Results stored in: %s\n", gitlabOutputPath)
 
 	if gitlabOutputFormat == "json" {
 		matches, err := s.GetAllMatches()
 		if err != nil {
 			return fmt.Errorf("retrieving matches: %w", err)
 		}
+		newResultOrder(s).sortMatches(matches)
 		return outputMatches(cmd, matches)
 	}
 
@@ -258,5 +328,6 @@ func runGitLabScan(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("retrieving findings: %w", err)
 	}
+	newResultOrder(s).sortFindings(findings, nil)
 	return outputFindings(cmd, findings)
 }
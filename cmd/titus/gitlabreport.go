@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/gitlabreport"
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// outputGitLabReport outputs matches as a GitLab secret detection report,
+// with one vulnerability per place each match's blob was found, up to
+// maxLocations of them (0 = all).
+func outputGitLabReport(cmd *cobra.Command, s store.Store, matches []*types.Match, ruleMap map[string]*types.Rule, maxLocations int) error {
+	start, end := lastScanTimes(s)
+	report := gitlabreport.NewReport(version, start, end)
+
+	newBlobLocations(s, maxLocations).attach(matches)
+	for _, match := range matches {
+		locations := match.Locations
+		if len(locations) == 0 {
+			locations = []types.BlobLocation{{Kind: "blob", Path: match.BlobID.Hex()}}
+		}
+		for _, loc := range locations {
+			report.AddVulnerability(match, ruleMap[match.RuleID], loc)
+		}
+	}
+
+	jsonBytes, err := report.ToJSON()
+	if err != nil {
+		return fmt.Errorf("serializing GitLab report: %w", err)
+	}
+	if _, err := cmd.OutOrStdout().Write(jsonBytes); err != nil {
+		return fmt.Errorf("writing GitLab report: %w", err)
+	}
+	return nil
+}
+
+// lastScanTimes returns when the datastore's latest scan started and
+// finished, or the current time for what isn't recorded.
+func lastScanTimes(s store.Store) (start, end time.Time) {
+	start, end = time.Now(), time.Now()
+	scans, err := s.GetScans()
+	if err != nil || len(scans) == 0 {
+		return start, end
+	}
+	last := scans[len(scans)-1]
+	start = last.StartedAt
+	if !last.FinishedAt.IsZero() {
+		end = last.FinishedAt
+	}
+	return start, end
+}
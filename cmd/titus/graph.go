@@ -0,0 +1,219 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/praetorian-inc/titus/pkg/graph"
+	"github.com/praetorian-inc/titus/pkg/rule"
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	graphDatastore string
+	graphFormat    string
+	graphOutput    string
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Export how secrets spread across files, repositories and authors",
+	Long: `Build a secret sprawl graph from a datastore: a node for each secret, each
+file it was found in, each repository holding those files and each author
+who committed them, with edges secret -> file, file -> repository and
+author -> file. A credential copied across a codebase shows up as one
+secret node fanning out to every place it landed.
+
+Findings of different rules that captured the same secret share a node.
+Secrets are identified by the start of their SHA-256, never by value, so
+the graph can be shared. Remediated findings are left out.
+
+Formats:
+  json     adjacency list: each node with the nodes its edges lead to
+  graphml  for Gephi, yEd and Cytoscape
+  dot      for Graphviz
+
+Examples:
+  titus graph --format dot | dot -Tsvg > sprawl.svg
+  titus graph --format graphml -o sprawl.graphml`,
+	Args: cobra.NoArgs,
+	RunE: runGraph,
+}
+
+func init() {
+	graphCmd.Flags().StringVar(&graphDatastore, "datastore", "titus.ds", "Path to datastore directory or file")
+	graphCmd.Flags().StringVar(&graphFormat, "format", "json", "Output format: json, graphml, dot")
+	graphCmd.Flags().StringVarP(&graphOutput, "output", "o", "", "Write to this file instead of stdout")
+	rootCmd.AddCommand(graphCmd)
+}
+
+func runGraph(cmd *cobra.Command, args []string) error {
+	var write func(*graph.Graph, io.Writer) error
+	switch graphFormat {
+	case "json":
+		write = (*graph.Graph).WriteJSON
+	case "graphml":
+		write = (*graph.Graph).WriteGraphML
+	case "dot":
+		write = (*graph.Graph).WriteDOT
+	default:
+		return fmt.Errorf("unknown format %q (want json, graphml or dot)", graphFormat)
+	}
+
+	s, err := openDatastore(graphDatastore)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	rules, err := rule.NewLoader().LoadBuiltinRules()
+	if err != nil {
+		return fmt.Errorf("loading rules: %w", err)
+	}
+	ruleMap := make(map[string]*types.Rule)
+	for _, r := range rules {
+		ruleMap[r.ID] = r
+	}
+
+	g, err := buildSprawlGraph(s, ruleMap)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if graphOutput != "" {
+		f, err := os.Create(graphOutput)
+		if err != nil {
+			return fmt.Errorf("creating output file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := write(g, out); err != nil {
+		return fmt.Errorf("writing graph: %w", err)
+	}
+	if graphOutput != "" {
+		statusf(cmd.ErrOrStderr(), "Wrote %d nodes and %d edges to %s\n", len(g.Nodes()), len(g.Edges()), graphOutput)
+	}
+	return nil
+}
+
+// buildSprawlGraph relates the secret of each unremediated finding to every
+// place its matches were found.
+func buildSprawlGraph(s store.Store, ruleMap map[string]*types.Rule) (*graph.Graph, error) {
+	findings, err := s.GetFindings()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving findings: %w", err)
+	}
+	matches, err := s.GetAllMatches()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving matches: %w", err)
+	}
+	matchesByFinding := buildFindingMatchMap(findings, matches)
+	// Stores don't order findings; sort so the output is stable
+	sort.Slice(findings, func(i, j int) bool { return findings[i].ID < findings[j].ID })
+
+	key, err := s.SecretKey()
+	if err != nil {
+		return nil, fmt.Errorf("reading datastore key: %w", err)
+	}
+
+	g := graph.New()
+	provenance := make(map[types.BlobID][]types.Provenance)
+	for _, f := range findings {
+		if f.State == types.FindingStateRemediated {
+			continue
+		}
+		secret := sprawlSecretNode(g, f, ruleMap, key)
+		if activeFinding(f, matchesByFinding) {
+			secret.Attributes["active"] = "true"
+		}
+
+		for _, m := range matchesByFinding[f.ID] {
+			provs, ok := provenance[m.BlobID]
+			if !ok {
+				if provs, err = s.GetAllProvenance(m.BlobID); err != nil {
+					return nil, fmt.Errorf("retrieving provenance: %w", err)
+				}
+				provenance[m.BlobID] = provs
+			}
+			for _, p := range provs {
+				addSprawlLocation(g, secret, p)
+			}
+		}
+	}
+	return g, nil
+}
+
+// sprawlSecretNode returns the node of f's secret, recording f on it. The
+// node is named by an HMAC of the secret under the datastore's key, so that
+// a shared graph can't be used to confirm a guessed secret. Findings
+// without capture groups each get their own node.
+func sprawlSecretNode(g *graph.Graph, f *types.Finding, ruleMap map[string]*types.Rule, key []byte) *graph.Node {
+	ruleName := f.RuleID
+	if r, ok := ruleMap[f.RuleID]; ok {
+		ruleName = r.Name
+	}
+
+	var n *graph.Node
+	if secret := types.SecretValue(f.Groups); len(secret) > 0 {
+		mac := hmac.New(sha256.New, key)
+		mac.Write(secret)
+		hash := hex.EncodeToString(mac.Sum(nil))[:12]
+		n = g.AddNode(graph.KindSecret, hash, fmt.Sprintf("%s (hmac:%s)", ruleName, hash))
+	} else {
+		n = g.AddNode(graph.KindSecret, "finding-"+shortID(f.ID), ruleName)
+	}
+
+	if !containsString(strings.Split(n.Attributes["rule_ids"], ","), f.RuleID) {
+		n.Attributes["rule_ids"] = strings.TrimPrefix(n.Attributes["rule_ids"]+","+f.RuleID, ",")
+	}
+	n.Attributes["finding_ids"] = strings.TrimPrefix(n.Attributes["finding_ids"]+","+f.ID, ",")
+	count, _ := strconv.Atoi(n.Attributes["findings"])
+	n.Attributes["findings"] = strconv.Itoa(count + 1)
+	return n
+}
+
+// addSprawlLocation links secret to the file of provenance p and, for git
+// history, to its repository and the commit's author.
+func addSprawlLocation(g *graph.Graph, secret *graph.Node, p types.Provenance) {
+	path := p.Path()
+	if path == "" {
+		return
+	}
+	gp, ok := p.(types.GitProvenance)
+	if !ok || gp.RepoPath == "" {
+		file := g.AddNode(graph.KindFile, path, path)
+		g.AddEdge(secret, file, graph.EdgeFoundIn)
+		return
+	}
+
+	file := g.AddNode(graph.KindFile, gp.RepoPath+":"+path, path)
+	file.Attributes["repository"] = gp.RepoPath
+	g.AddEdge(secret, file, graph.EdgeFoundIn)
+	repo := g.AddNode(graph.KindRepo, gp.RepoPath, gp.RepoPath)
+	g.AddEdge(file, repo, graph.EdgePartOf)
+
+	if c := gp.Commit; c != nil && (c.AuthorEmail != "" || c.AuthorName != "") {
+		key, label := strings.ToLower(c.AuthorEmail), c.AuthorName
+		switch {
+		case key == "":
+			key = c.AuthorName
+		case label == "":
+			label = c.AuthorEmail
+		default:
+			label = fmt.Sprintf("%s <%s>", c.AuthorName, c.AuthorEmail)
+		}
+		author := g.AddNode(graph.KindAuthor, key, label)
+		g.AddEdge(author, file, graph.EdgeCommitted)
+	}
+}
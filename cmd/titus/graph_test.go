@@ -0,0 +1,86 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/graph"
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSprawlGraph(t *testing.T) {
+	s := store.NewMemory()
+	add := func(findingID, ruleID, secret, content string, provs ...types.Provenance) {
+		blobID := types.ComputeBlobID([]byte(content))
+		groups := [][]byte{[]byte(secret)}
+		require.NoError(t, s.AddBlob(blobID, int64(len(content))))
+		for _, prov := range provs {
+			require.NoError(t, s.AddProvenance(blobID, prov))
+		}
+		require.NoError(t, s.AddMatch(&types.Match{BlobID: blobID, StructuralID: findingID + "-m", RuleID: ruleID, Groups: groups}))
+		require.NoError(t, s.AddFinding(&types.Finding{ID: findingID, RuleID: ruleID, Groups: groups}))
+	}
+	alice := &types.CommitMetadata{CommitID: "c1", AuthorName: "Alice", AuthorEmail: "Alice@example.com"}
+	add("f1", "np.github.1", "ghp_shared", "token=ghp_shared",
+		types.GitProvenance{RepoPath: "/src/api", BlobPath: "config.env", Commit: alice},
+		types.GitProvenance{RepoPath: "/src/api", BlobPath: "config.env", Commit: &types.CommitMetadata{CommitID: "c2", AuthorName: "Alice", AuthorEmail: "alice@example.com"}},
+		types.FileProvenance{FilePath: "/tmp/backup.env"})
+	add("f2", "np.generic.1", "ghp_shared", "GITHUB=ghp_shared",
+		types.GitProvenance{RepoPath: "/src/web", BlobPath: "ci.yml", Commit: &types.CommitMetadata{CommitID: "c3", AuthorName: "Bob"}})
+	add("f3", "np.github.1", "ghp_fixed", "old=ghp_fixed", types.FileProvenance{FilePath: "old.env"})
+	require.NoError(t, s.SetFindingState("f3", types.FindingStateRemediated))
+
+	g, err := buildSprawlGraph(s, map[string]*types.Rule{"np.github.1": {ID: "np.github.1", Name: "GitHub Personal Access Token"}})
+	require.NoError(t, err)
+
+	nodes := make(map[string]*graph.Node)
+	for _, n := range g.Nodes() {
+		nodes[n.ID] = n
+	}
+	var secrets []*graph.Node
+	for _, n := range g.Nodes() {
+		if n.Kind == graph.KindSecret {
+			secrets = append(secrets, n)
+		}
+	}
+	// Both rules captured the same value; the remediated finding is left out
+	require.Len(t, secrets, 1)
+	secret := secrets[0]
+	assert.Contains(t, secret.Label, "GitHub Personal Access Token (hmac:")
+	assert.NotContains(t, secret.Label, "ghp_shared")
+	unsalted := sha256.Sum256([]byte("ghp_shared"))
+	assert.NotContains(t, secret.ID, hex.EncodeToString(unsalted[:])[:12], "the secret's plain hash can't be checked against the graph")
+
+	// The datastore keeps its key, so the graph is stable across runs
+	again, err := buildSprawlGraph(s, nil)
+	require.NoError(t, err)
+	var ids []string
+	for _, n := range again.Nodes() {
+		ids = append(ids, n.ID)
+	}
+	assert.Contains(t, ids, secret.ID)
+	assert.Equal(t, "np.github.1,np.generic.1", secret.Attributes["rule_ids"])
+	assert.Equal(t, "f1,f2", secret.Attributes["finding_ids"])
+	assert.Equal(t, "2", secret.Attributes["findings"])
+
+	assert.Contains(t, nodes, "file:/src/api:config.env")
+	assert.Contains(t, nodes, "file:/tmp/backup.env")
+	assert.Contains(t, nodes, "repository:/src/web")
+	assert.Equal(t, "Alice <Alice@example.com>", nodes["author:alice@example.com"].Label)
+	assert.Equal(t, "Bob", nodes["author:Bob"].Label)
+	assert.NotContains(t, nodes, "file:old.env")
+
+	assert.ElementsMatch(t, []graph.Edge{
+		{Source: secret.ID, Target: "file:/src/api:config.env", Kind: graph.EdgeFoundIn},
+		{Source: "file:/src/api:config.env", Target: "repository:/src/api", Kind: graph.EdgePartOf},
+		{Source: "author:alice@example.com", Target: "file:/src/api:config.env", Kind: graph.EdgeCommitted},
+		{Source: secret.ID, Target: "file:/tmp/backup.env", Kind: graph.EdgeFoundIn},
+		{Source: secret.ID, Target: "file:/src/web:ci.yml", Kind: graph.EdgeFoundIn},
+		{Source: "file:/src/web:ci.yml", Target: "repository:/src/web", Kind: graph.EdgePartOf},
+		{Source: "author:Bob", Target: "file:/src/web:ci.yml", Kind: graph.EdgeCommitted},
+	}, g.Edges())
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/praetorian-inc/titus/pkg/enum"
+	"github.com/praetorian-inc/titus/pkg/store"
+)
+
+// gitHighWaterMarks lets scan --git --incremental walk only the commits added
+// since the last scan. The datastore records the commit each branch and tag
+// of a repository was at when its history was last scanned; commits
+// reachable from those are left out of the next walk.
+type gitHighWaterMarks struct {
+	store store.Store
+
+	// readOnly leaves the marks where they were: set for scans narrowed by
+	// --since, --author or --ref, which don't cover the history up to the
+	// refs they walk
+	readOnly bool
+
+	mu      sync.Mutex
+	refs    map[string]map[string]string // repository -> refs when this scan began
+	resumed bool                         // some repository had marks
+}
+
+func newGitHighWaterMarks(s store.Store) *gitHighWaterMarks {
+	return &gitHighWaterMarks{store: s, refs: make(map[string]map[string]string)}
+}
+
+// scanned returns the commits of repoPath whose history an earlier scan
+// covered, and notes where its refs are now so commit can record them.
+func (h *gitHighWaterMarks) scanned(repoPath string) ([]string, error) {
+	repo, err := filepath.Abs(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := enum.ListRefs(context.Background(), repoPath)
+	if err != nil {
+		return nil, err
+	}
+	marks, err := h.store.GetRepoRefs(repo)
+	if err != nil {
+		return nil, fmt.Errorf("reading scan progress: %w", err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.refs[repo] = refs
+	var commits []string
+	for _, commit := range marks {
+		commits = append(commits, commit)
+	}
+	if len(commits) > 0 {
+		h.resumed = true
+	}
+	return commits, nil
+}
+
+// partial reports whether the scan left out history covered by an earlier
+// one, in which case findings not seen aren't necessarily gone.
+func (h *gitHighWaterMarks) partial() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.resumed
+}
+
+// commit records the refs noted by scanned as the new marks, unless the
+// marks are read-only. It is called only once the scan succeeds, so an
+// interrupted scan is retried in full.
+func (h *gitHighWaterMarks) commit() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.readOnly {
+		return nil
+	}
+	for repo, refs := range h.refs {
+		for ref, commit := range refs {
+			if err := h.store.SetRepoRef(repo, ref, commit); err != nil {
+				return fmt.Errorf("recording scan progress: %w", err)
+			}
+		}
+	}
+	return nil
+}
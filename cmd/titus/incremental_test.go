@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHighWaterMarks_OnlyWalksNewCommits(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	commitFile := func(name, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(repo, name), []byte(content), 0644))
+		git("add", name)
+		git("commit", "-m", "add "+name)
+	}
+	git("init")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "Test User")
+	commitFile("first.env", "token = one\n")
+
+	s := store.NewMemory()
+	historyPaths := func(marks *gitHighWaterMarks) []string {
+		t.Helper()
+		e, err := createEnumerator(repo, true, nil, enumHooks{scannedCommits: marks.scanned})
+		require.NoError(t, err)
+		var paths []string
+		err = e.Enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+			if gp, ok := prov.(types.GitProvenance); ok {
+				paths = append(paths, gp.BlobPath)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		return paths
+	}
+
+	marks := newGitHighWaterMarks(s)
+	assert.Equal(t, []string{"first.env"}, historyPaths(marks))
+	assert.False(t, marks.partial(), "first scan walks all history")
+	require.NoError(t, marks.commit())
+
+	abs, err := filepath.Abs(repo)
+	require.NoError(t, err)
+	refs, err := s.GetRepoRefs(abs)
+	require.NoError(t, err)
+	assert.Len(t, refs, 1)
+
+	commitFile("second.env", "token = two\n")
+	marks = newGitHighWaterMarks(s)
+	assert.Equal(t, []string{"second.env"}, historyPaths(marks))
+	assert.True(t, marks.partial())
+
+	// Marks only advance on commit, so an interrupted scan starts over
+	marks = newGitHighWaterMarks(s)
+	assert.Equal(t, []string{"second.env"}, historyPaths(marks))
+
+	// A filtered scan doesn't move them either
+	marks = newGitHighWaterMarks(s)
+	marks.readOnly = true
+	assert.Equal(t, []string{"second.env"}, historyPaths(marks))
+	require.NoError(t, marks.commit())
+	marks = newGitHighWaterMarks(s)
+	assert.Equal(t, []string{"second.env"}, historyPaths(marks))
+}
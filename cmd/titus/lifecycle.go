@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// scanLifecycle records the blobs a scan enumerated so that, once it
+// finishes, findings can be marked as seen again or remediated.
+type scanLifecycle struct {
+	target  string
+	git     bool // scanning git history rather than the filesystem
+	partial bool // the scan read only part of the target (--sample)
+	started time.Time
+
+	mu    sync.Mutex
+	blobs map[types.BlobID]struct{}
+	// files, when non-nil, scopes the scan to the files it read rather than
+	// the tree under target, as when watch rescans the files that changed
+	files map[string]struct{}
+}
+
+func newScanLifecycle(target string, git bool) *scanLifecycle {
+	return &scanLifecycle{
+		target:  target,
+		git:     git,
+		started: time.Now().UTC().Truncate(time.Second),
+		blobs:   make(map[types.BlobID]struct{}),
+	}
+}
+
+// newFileLifecycle returns a lifecycle scoped to the files it sees.
+func newFileLifecycle() *scanLifecycle {
+	l := newScanLifecycle("", false)
+	l.files = make(map[string]struct{})
+	return l
+}
+
+// see records a blob enumerated by the scan, including blobs skipped by
+// --incremental since their stored matches are still current.
+func (l *scanLifecycle) see(id types.BlobID) {
+	l.mu.Lock()
+	l.blobs[id] = struct{}{}
+	l.mu.Unlock()
+}
+
+// seeFile records a blob read from prov. A lifecycle scoped to files also
+// records its path.
+func (l *scanLifecycle) seeFile(id types.BlobID, prov types.Provenance) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.blobs[id] = struct{}{}
+	if l.files == nil {
+		return
+	}
+	if abs, err := filepath.Abs(provenancePath(prov)); err == nil {
+		l.files[abs] = struct{}{}
+	}
+}
+
+// reset forgets what was seen so far, for a long-running command to start
+// its next cycle.
+func (l *scanLifecycle) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.started = time.Now().UTC().Truncate(time.Second)
+	clear(l.blobs)
+	if l.files != nil {
+		clear(l.files)
+	}
+}
+
+// update marks findings with a match in a seen blob as seen, and findings
+// whose every location is under the scan target but weren't seen as
+// remediated. Findings of rules not loaded for this scan are left alone, as
+// are findings also found outside the target. A partial scan never marks
+// findings remediated.
+func (l *scanLifecycle) update(s store.Store, ruleMap map[string]*types.Rule) (seen, remediated int, err error) {
+	findings, err := s.GetFindings()
+	if err != nil {
+		return 0, 0, fmt.Errorf("retrieving findings: %w", err)
+	}
+	matches, err := s.GetAllMatches()
+	if err != nil {
+		return 0, 0, fmt.Errorf("retrieving matches: %w", err)
+	}
+	matchesByFinding := buildFindingMatchMap(findings, matches)
+
+	var seenIDs, remediatedIDs []string
+	for _, f := range findings {
+		if _, ok := ruleMap[f.RuleID]; !ok {
+			continue
+		}
+		fm := matchesByFinding[f.ID]
+		if l.anySeen(fm) {
+			seenIDs = append(seenIDs, f.ID)
+			continue
+		}
+		if l.partial || f.State == types.FindingStateRemediated || len(fm) == 0 {
+			continue
+		}
+		inScope, err := l.allInScope(s, fm)
+		if err != nil {
+			return 0, 0, err
+		}
+		if inScope {
+			remediatedIDs = append(remediatedIDs, f.ID)
+		}
+	}
+
+	err = s.ExecBatch(func(tx store.Store) error {
+		if err := tx.MarkFindingsSeen(seenIDs, l.started); err != nil {
+			return fmt.Errorf("marking findings seen: %w", err)
+		}
+		for _, id := range remediatedIDs {
+			if err := tx.SetFindingState(id, types.FindingStateRemediated); err != nil {
+				return fmt.Errorf("marking finding remediated: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return len(seenIDs), len(remediatedIDs), nil
+}
+
+func (l *scanLifecycle) anySeen(matches []*types.Match) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, m := range matches {
+		if _, ok := l.blobs[m.BlobID]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// allInScope reports whether every provenance of the matches is of the kind
+// the scan enumerates (files, or git blobs with --git) and under the target,
+// or one of the files read when the lifecycle is scoped to files.
+func (l *scanLifecycle) allInScope(s store.Store, matches []*types.Match) (bool, error) {
+	root, err := filepath.Abs(l.target)
+	if err != nil {
+		return false, nil
+	}
+	for _, m := range matches {
+		provs, err := s.GetAllProvenance(m.BlobID)
+		if err != nil {
+			return false, fmt.Errorf("retrieving provenance: %w", err)
+		}
+		if len(provs) == 0 {
+			return false, nil
+		}
+		for _, p := range provs {
+			path := provenancePath(p)
+			if path == "" || isGit(p) != l.git {
+				return false, nil
+			}
+			abs, err := filepath.Abs(path)
+			if err != nil || !l.inScope(abs, root) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+func (l *scanLifecycle) inScope(path, root string) bool {
+	if l.files == nil {
+		return underPath(path, root)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.files[path]
+	return ok
+}
+
+// provenancePath returns the file, repository or archive a provenance
+// locates a blob in, or "" for other kinds.
+func provenancePath(p types.Provenance) string {
+	switch p := p.(type) {
+	case types.FileProvenance:
+		return p.FilePath
+	case types.GitProvenance:
+		return p.RepoPath
+	case types.ArchiveProvenance:
+		return p.ArchivePath
+	}
+	return ""
+}
+
+func isGit(p types.Provenance) bool {
+	_, ok := p.(types.GitProvenance)
+	return ok
+}
+
+// underPath reports whether path is root or inside it.
+func underPath(path, root string) bool {
+	if path == root {
+		return true
+	}
+	return strings.HasPrefix(path, strings.TrimSuffix(root, string(filepath.Separator))+string(filepath.Separator))
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// runListOnly enumerates target as a scan would, without matching. It lists
+// each blob that would be scanned with its size, and each file or archive
+// member that would be skipped with the reason, so that include and exclude
+// settings can be tuned before a long scan.
+func runListOnly(cmd *cobra.Command, target string) error {
+	out := cmd.OutOrStdout()
+	var mu sync.Mutex
+	var blobs, skipped, totalBytes int64
+
+	var policies *scanPolicies
+	if !remoteTarget(target) {
+		var err error
+		if policies, _, err = loadScanPolicies(target, nil); err != nil {
+			return err
+		}
+	}
+
+	enumerator, err := createEnumerator(target, scanGit, policies, enumHooks{onSkip: func(path, reason string) {
+		mu.Lock()
+		defer mu.Unlock()
+		skipped++
+		fmt.Fprintf(out, "skip %s: %s\n", path, reason)
+	}})
+	if err != nil {
+		return fmt.Errorf("creating enumerator: %w", err)
+	}
+
+	err = enumerator.Enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		mu.Lock()
+		defer mu.Unlock()
+		blobs++
+		totalBytes += int64(len(content))
+		path := blobID.Hex()
+		if prov != nil && prov.Path() != "" {
+			path = prov.Path()
+		}
+		fmt.Fprintf(out, "scan %s: %d B\n", path, len(content))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("enumerating: %w", err)
+	}
+
+	statusf(cmd.ErrOrStderr(), "Would scan %d B in %d blobs; %d skipped\n", totalBytes, blobs, skipped)
+	return nil
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunListOnly(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.env"), []byte("TOKEN=abc"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "image.raw"), []byte{0x00, 0x01}, 0644))
+
+	var out, errOut bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	require.NoError(t, runListOnly(cmd, dir))
+
+	assert.Contains(t, out.String(), "scan "+filepath.Join(dir, "app.env")+": 9 B\n")
+	assert.Contains(t, out.String(), "skip "+filepath.Join(dir, "image.raw")+": binary\n")
+	assert.Equal(t, "Would scan 9 B in 1 blobs; 1 skipped\n", errOut.String())
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// defaultMaxLocations is how many of the places a match's blob was found
+// reports list before giving a count of the rest.
+const defaultMaxLocations = 10
+
+// blobLocations looks up where blobs were found, caching by blob.
+type blobLocations struct {
+	s     store.Store
+	max   int // 0 = no limit
+	cache map[types.BlobID]blobLocationSet
+}
+
+type blobLocationSet struct {
+	locations []types.BlobLocation
+	total     int
+}
+
+func newBlobLocations(s store.Store, max int) *blobLocations {
+	return &blobLocations{s: s, max: max, cache: make(map[types.BlobID]blobLocationSet)}
+}
+
+// get returns up to max of the places a blob was found, in the order they
+// were recorded, and how many there are in all.
+func (l *blobLocations) get(id types.BlobID) ([]types.BlobLocation, int) {
+	if set, ok := l.cache[id]; ok {
+		return set.locations, set.total
+	}
+	var set blobLocationSet
+	if provs, err := l.s.GetAllProvenance(id); err == nil {
+		set.total = len(provs)
+		if l.max > 0 && len(provs) > l.max {
+			provs = provs[:l.max]
+		}
+		for _, p := range provs {
+			set.locations = append(set.locations, types.NewBlobLocation(p))
+		}
+	}
+	l.cache[id] = set
+	return set.locations, set.total
+}
+
+// attach sets Locations and LocationCount on each match.
+func (l *blobLocations) attach(matches []*types.Match) {
+	for _, m := range matches {
+		m.Locations, m.LocationCount = l.get(m.BlobID)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobLocations(t *testing.T) {
+	s := store.NewMemory()
+	blob := types.ComputeBlobID([]byte("creds"))
+	require.NoError(t, s.AddBlob(blob, 5))
+	require.NoError(t, s.AddProvenance(blob, types.FileProvenance{FilePath: "a/creds"}))
+	require.NoError(t, s.AddProvenance(blob, types.GitProvenance{
+		RepoPath: "org/repo",
+		BlobPath: "creds",
+		Commit:   &types.CommitMetadata{CommitID: "0123456789abcdef0123456789abcdef01234567"},
+	}))
+	require.NoError(t, s.AddProvenance(blob, types.FileProvenance{FilePath: "b/creds"}))
+
+	matches := []*types.Match{{BlobID: blob}, {BlobID: blob}}
+	newBlobLocations(s, 2).attach(matches)
+	for _, m := range matches {
+		assert.Equal(t, 3, m.LocationCount)
+		assert.Equal(t, []types.BlobLocation{
+			{Kind: "file", Path: "a/creds"},
+			{Kind: "git", Path: "creds", Repository: "org/repo", Commit: "0123456789abcdef0123456789abcdef01234567"},
+		}, m.Locations)
+	}
+	assert.Equal(t, "creds in org/repo @ 0123456789ab", matches[0].Locations[1].String())
+
+	locations, total := newBlobLocations(s, 0).get(blob)
+	assert.Len(t, locations, 3)
+	assert.Equal(t, 3, total)
+}
+
+func TestOutputReportHuman_Locations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "datastore.db")
+	s, err := store.New(store.Config{Path: path})
+	require.NoError(t, err)
+	defer s.Close()
+
+	blob := types.ComputeBlobID([]byte("creds"))
+	require.NoError(t, s.AddBlob(blob, 5))
+	for _, p := range []string{"a/creds", "b/creds", "c/creds"} {
+		require.NoError(t, s.AddProvenance(blob, types.FileProvenance{FilePath: p}))
+	}
+	rule := &types.Rule{ID: "test.1", Name: "Test", StructuralID: "test"}
+	match := &types.Match{BlobID: blob, RuleID: rule.ID, StructuralID: "m1", Groups: [][]byte{[]byte("secret")}}
+	finding := &types.Finding{ID: types.ComputeFindingID(rule.StructuralID, match.Groups), RuleID: rule.ID, Groups: match.Groups}
+	newBlobLocations(s, 2).attach([]*types.Match{match})
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	require.NoError(t, outputReportHuman(cmd, []*types.Finding{finding}, []*types.Match{match}, path,
+		map[string]*types.Rule{rule.ID: rule}))
+
+	assert.Contains(t, out.String(), "File: a/creds")
+	assert.Contains(t, out.String(), "Also: b/creds")
+	assert.NotContains(t, out.String(), "c/creds")
+	assert.Contains(t, out.String(), "Locations: 3 (1 more not shown)")
+}
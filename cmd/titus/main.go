@@ -3,6 +3,12 @@ package main
 import "os"
 
 func main() {
+	if isService, err := runAsService(); isService {
+		if err != nil {
+			os.Exit(1)
+		}
+		return
+	}
 	if err := Execute(); err != nil {
 		os.Exit(1)
 	}
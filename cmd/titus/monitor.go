@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/datastore"
+	"github.com/praetorian-inc/titus/pkg/enum"
+	"github.com/praetorian-inc/titus/pkg/matcher"
+	"github.com/praetorian-inc/titus/pkg/monitor"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/praetorian-inc/titus/pkg/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	monitorConfigPath       string
+	monitorOutputPath       string
+	monitorInterval         string
+	monitorOnce             bool
+	monitorRuleset          string
+	monitorValidate         bool
+	monitorValidateAudit    string
+	monitorValidateTimeouts []string
+	monitorValidateBreaker  int
+	monitorMaxFileSize      int64
+	monitorSIEMTarget       string
+	monitorSIEMFormat       string
+	monitorMetricsAddr      string
+)
+
+var monitorCmd = &cobra.Command{
+	Use:   "monitor",
+	Short: "Periodically fetch and scan new commits in a set of repositories",
+	Long: `Run as a daemon that re-scans a list of repositories on a schedule. Each cycle
+fetches every repository into the datastore's clone cache and scans only the
+commits added since the previous cycle. The last scanned commit of every ref is
+kept in the datastore, so monitoring resumes where it left off after a restart.
+
+New findings are printed as they are discovered.
+
+The config file is YAML:
+
+  interval: "@hourly"          # @hourly, @daily, @weekly, @every 15m, or 30m
+  repos:
+    - https://github.com/org/repo
+  github_orgs:                  # uses GITHUB_TOKEN when set
+    - my-org
+  gitlab_groups:                # uses GITLAB_TOKEN when set
+    - my-group
+  skip_forks: true
+
+Examples:
+  titus monitor --config monitor.yaml
+  titus monitor --config monitor.yaml --once --validate
+  titus monitor --config monitor.yaml --siem tcp://siem.local:514 --metrics-addr :9090`,
+	Args: cobra.NoArgs,
+	RunE: runMonitor,
+}
+
+func init() {
+	monitorCmd.Flags().StringVar(&monitorConfigPath, "config", "", "Path to monitor config file (required)")
+	monitorCmd.Flags().StringVar(&monitorOutputPath, "output", "titus.ds", "Output datastore path")
+	monitorCmd.Flags().StringVar(&monitorInterval, "interval", "", "Override the config schedule (e.g. @daily, 30m)")
+	monitorCmd.Flags().BoolVar(&monitorOnce, "once", false, "Run a single cycle and exit (for use with an external scheduler)")
+	monitorCmd.Flags().StringVar(&monitorRuleset, "ruleset", "default", "Ruleset to use: default, np.assets, np.hashes, all (all = no filtering)")
+	monitorCmd.Flags().BoolVar(&monitorValidate, "validate", false, "validate detected secrets against their source APIs")
+	monitorCmd.Flags().StringVar(&monitorValidateAudit, "validate-audit", "", "Append a JSON line per outbound validation request (time, rule, validator, host, result; no secrets) to this file")
+	monitorCmd.Flags().StringSliceVar(&monitorValidateTimeouts, "validate-timeout", nil, "Time limit for each validation, and per validator as name=duration (e.g. 10s,aws=30s; default 30s, or the validator's own)")
+	monitorCmd.Flags().IntVar(&monitorValidateBreaker, "validate-breaker", validator.DefaultBreakerFailures, "Skip a validator's endpoint, marking its matches undetermined, after this many consecutive network failures or timeouts against it (0 = never)")
+	monitorCmd.Flags().Int64Var(&monitorMaxFileSize, "max-file-size", 10*1024*1024, "Maximum file size to scan (bytes)")
+	monitorCmd.Flags().StringVar(&monitorSIEMTarget, "siem", "", "Stream match events to a SIEM: udp://host:port, tcp://host:port (syslog) or a file path")
+	monitorCmd.Flags().StringVar(&monitorSIEMFormat, "siem-format", "cef", "SIEM event format: cef, leef, ocsf, ecs")
+	monitorCmd.Flags().StringVar(&monitorMetricsAddr, "metrics-addr", "", "Expose Prometheus metrics at http://<addr>/metrics (empty = disabled)")
+	monitorCmd.MarkFlagRequired("config")
+
+	rootCmd.AddCommand(monitorCmd)
+}
+
+func runMonitor(cmd *cobra.Command, args []string) error {
+	cfg, err := monitor.LoadConfig(monitorConfigPath)
+	if err != nil {
+		return err
+	}
+	interval, err := cfg.Schedule()
+	if err != nil {
+		return err
+	}
+	if monitorInterval != "" {
+		if interval, err = monitor.ParseSchedule(monitorInterval); err != nil {
+			return err
+		}
+	}
+
+	if monitorOutputPath == ":memory:" {
+		return fmt.Errorf("monitor requires an on-disk datastore to keep clones and scan progress")
+	}
+
+	rules, err := loadRules("", "", "", monitorRuleset)
+	if err != nil {
+		return fmt.Errorf("loading rules: %w", err)
+	}
+	ruleMap := make(map[string]*types.Rule)
+	for _, r := range rules {
+		ruleMap[r.ID] = r
+	}
+
+	m, err := matcher.New(matcher.Config{
+		Rules:        rules,
+		ContextLines: 3,
+		WarnFunc: func(format string, args ...any) {
+			fmt.Fprintf(os.Stderr, format, args...)
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("creating matcher: %w", err)
+	}
+	defer m.Close()
+
+	_, ds, err := openScanStore(monitorOutputPath, false)
+	if err != nil {
+		return err
+	}
+	defer ds.Close()
+	for _, r := range rules {
+		if err := ds.Store.AddRule(r); err != nil {
+			return fmt.Errorf("storing rule: %w", err)
+		}
+	}
+
+	var engine *validator.Engine
+	if monitorValidate {
+		engine = validator.NewDefaultEngine(4)
+		matcher.SetCanValidate(m, engine.CanValidate)
+		if err := setValidationLimits(engine, monitorValidateTimeouts, monitorValidateBreaker); err != nil {
+			return err
+		}
+	}
+	audit, err := openValidationAudit(monitorValidateAudit, engine)
+	if err != nil {
+		return err
+	}
+	if audit != nil {
+		defer closeValidationAudit(audit)
+	}
+
+	sink, err := openSIEMSink(monitorSIEMTarget, monitorSIEMFormat)
+	if err != nil {
+		return err
+	}
+	if sink != nil {
+		defer sink.Close()
+	}
+
+	ctx, cancel := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	p := &streamPipeline{
+		matcher:   m,
+		store:     ds.Store,
+		ruleMap:   ruleMap,
+		validator: engine,
+		siem:      sink,
+		metrics:   startMetricsServer(ctx, monitorMetricsAddr),
+		out:       cmd.OutOrStdout(),
+		lifecycle: newScanLifecycle("", true),
+	}
+	// Only new commits are scanned, so findings are marked seen but never
+	// remediated
+	p.lifecycle.partial = true
+
+	for {
+		runMonitorCycle(ctx, cmd.ErrOrStderr(), cfg, ds, p)
+		if monitorOnce {
+			return nil
+		}
+
+		fmt.Fprintf(cmd.ErrOrStderr(), "Next cycle at %s\n", time.Now().Add(interval).Format("2006-01-02 15:04:05"))
+		select {
+		case <-ctx.Done():
+			fmt.Fprintf(cmd.ErrOrStderr(), "Stopped monitoring\n")
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// runMonitorCycle fetches and scans every configured repository once.
+// Per-repository failures are reported and do not stop the cycle.
+func runMonitorCycle(ctx context.Context, log io.Writer, cfg *monitor.Config, ds *datastore.Datastore, p *streamPipeline) {
+	start := time.Now()
+	repos, err := resolveMonitorRepos(ctx, cfg)
+	if err != nil {
+		fmt.Fprintf(log, "[warn] %v\n", err)
+	}
+
+	updated := 0
+	for _, repoURL := range repos {
+		if ctx.Err() != nil {
+			return
+		}
+		changed, err := scanMonitoredRepo(ctx, ds, p, repoURL)
+		if err != nil {
+			fmt.Fprintf(log, "[warn] %s: %v\n", repoURL, err)
+			continue
+		}
+		if changed {
+			updated++
+		}
+	}
+	p.updateLifecycle(log)
+	fmt.Fprintf(log, "Cycle complete: %d repositories checked, %d with new commits (%s)\n",
+		len(repos), updated, time.Since(start).Round(time.Second))
+}
+
+// resolveMonitorRepos expands the configured orgs and groups into clone URLs.
+// Listing errors are returned alongside the repositories that did resolve.
+func resolveMonitorRepos(ctx context.Context, cfg *monitor.Config) ([]string, error) {
+	repos := append([]string(nil), cfg.Repos...)
+	var errs []error
+
+	for _, org := range cfg.GitHubOrgs {
+		ghEnum, err := enum.NewGitHubEnumerator(enum.GitHubConfig{
+			Token:     os.Getenv("GITHUB_TOKEN"),
+			Org:       org,
+			SkipForks: cfg.SkipForks,
+		})
+		if err == nil {
+			var infos []enum.RepoInfo
+			if infos, err = ghEnum.ListRepoURLs(ctx); err == nil {
+				for _, info := range infos {
+					repos = append(repos, info.CloneURL)
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("listing GitHub org %s: %w", org, err))
+		}
+	}
+
+	for _, group := range cfg.GitLabGroups {
+		glEnum, err := enum.NewGitLabEnumerator(enum.GitLabConfig{
+			Token: os.Getenv("GITLAB_TOKEN"),
+			Group: group,
+		})
+		if err == nil {
+			var infos []enum.RepoInfo
+			if infos, err = glEnum.ListProjectURLs(ctx); err == nil {
+				for _, info := range infos {
+					repos = append(repos, info.CloneURL)
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("listing GitLab group %s: %w", group, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return repos, fmt.Errorf("resolving repositories: %v", errs)
+	}
+	return repos, nil
+}
+
+// scanMonitoredRepo fetches repoURL and scans the commits added since the last
+// recorded high-water marks. The marks are only advanced once the scan
+// succeeds, so an interrupted cycle is retried in full next time. Reports
+// whether any ref had moved.
+func scanMonitoredRepo(ctx context.Context, ds *datastore.Datastore, p *streamPipeline, repoURL string) (bool, error) {
+	repoPath, err := ds.CloneCache.GetOrClone(repoURL)
+	if err != nil {
+		return false, err
+	}
+	if err := ds.CloneCache.Update(repoURL); err != nil {
+		return false, err
+	}
+
+	refs, err := enum.ListRefs(ctx, repoPath)
+	if err != nil {
+		return false, err
+	}
+	marks, err := ds.Store.GetRepoRefs(repoURL)
+	if err != nil {
+		return false, fmt.Errorf("reading scan progress: %w", err)
+	}
+	if maps.Equal(refs, marks) {
+		return false, nil
+	}
+
+	gitEnum := enum.NewGitEnumerator(enum.Config{
+		Root:        repoPath,
+		MaxFileSize: monitorMaxFileSize,
+	})
+	gitEnum.WalkAll = true
+	for _, commit := range marks {
+		gitEnum.Exclude = append(gitEnum.Exclude, commit)
+	}
+
+	start := time.Now()
+	err = gitEnum.Enumerate(ctx, func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		if gp, ok := prov.(types.GitProvenance); ok {
+			gp.RepoPath = repoURL
+			prov = gp
+		}
+		return p.process(ctx, content, blobID, prov)
+	})
+	p.metrics.ObserveScan(time.Since(start), err != nil)
+	if err != nil {
+		return false, fmt.Errorf("scanning: %w", err)
+	}
+
+	for ref, commit := range refs {
+		if marks[ref] == commit {
+			continue
+		}
+		if err := ds.Store.SetRepoRef(repoURL, ref, commit); err != nil {
+			return false, fmt.Errorf("recording scan progress: %w", err)
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/praetorian-inc/titus/pkg/enum"
+	"github.com/spf13/cobra"
+)
+
+var (
+	nexusURL          string
+	nexusUser         string
+	nexusPassword     string
+	nexusMaxFileSize  int64
+	nexusExtract      string
+	nexusOutputPath   string
+	nexusOutputFormat string
+)
+
+var nexusCmd = &cobra.Command{
+	Use:   "nexus [repository...]",
+	Short: "Scan Sonatype Nexus repositories for secrets",
+	Long: `Scan the assets in Sonatype Nexus Repository 3 repositories via the REST API.
+Without repository names, every hosted repository the user can read is scanned;
+name proxy or group repositories to scan them too.
+
+Build artifacts often embed credentials injected at CI time. Jars, wars, npm
+tarballs and other archives are extracted and their members scanned.
+
+Authentication: --user and --password (or NEXUS_USER and NEXUS_PASSWORD), or
+the name and pass codes of a user token.
+
+Findings name the asset's download URL, plus the member for archives.
+
+Examples:
+  titus nexus --url https://nexus.corp.com --user ci --password $NEXUS_PASSWORD
+  titus nexus --url https://nexus.corp.com --user ci --password $NEXUS_PASSWORD maven-releases npm-internal`,
+	RunE: runNexusScan,
+}
+
+func init() {
+	nexusCmd.Flags().StringVar(&nexusURL, "url", "", "Nexus URL (or NEXUS_URL env; e.g., https://nexus.corp.com)")
+	nexusCmd.Flags().StringVar(&nexusUser, "user", "", "User name or user token name code (or NEXUS_USER env)")
+	nexusCmd.Flags().StringVar(&nexusPassword, "password", "", "Password or user token pass code (or NEXUS_PASSWORD env)")
+	nexusCmd.Flags().Int64Var(&nexusMaxFileSize, "max-file-size", 100*1024*1024, "Maximum asset size to download (bytes)")
+	nexusCmd.Flags().StringVar(&nexusExtract, "extract", "all", "Extract text from binary assets (extensions: jar,war,tgz,zip or 'all'; empty to disable)")
+	nexusCmd.Flags().StringVar(&nexusOutputPath, "output", "titus.db", "Output database path (:memory: for in-memory)")
+	nexusCmd.Flags().StringVar(&nexusOutputFormat, "format", "human", "Output format: json, human")
+
+	rootCmd.AddCommand(nexusCmd)
+}
+
+func runNexusScan(cmd *cobra.Command, args []string) error {
+	baseURL := nexusURL
+	if baseURL == "" {
+		baseURL = os.Getenv("NEXUS_URL")
+	}
+	user := nexusUser
+	if user == "" {
+		user = os.Getenv("NEXUS_USER")
+	}
+	password := nexusPassword
+	if password == "" {
+		password = os.Getenv("NEXUS_PASSWORD")
+	}
+
+	if baseURL == "" {
+		return fmt.Errorf("must specify the Nexus URL: use --url or NEXUS_URL")
+	}
+	insecure, err := enum.ValidateBaseURL(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid --url: %w", err)
+	}
+	if insecure && password != "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "WARNING: Using HTTP with a password. Your password will be sent in plaintext.\n")
+	}
+	if user == "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Note: No Nexus user provided. Only anonymously readable repositories will be scanned.\n\n")
+	}
+
+	e, err := enum.NewNexusEnumerator(enum.NexusConfig{
+		BaseURL:  baseURL,
+		User:     user,
+		Password: password,
+		Repos:    args,
+		Config: enum.Config{
+			MaxFileSize:     nexusMaxFileSize,
+			ExtractArchives: nexusExtract,
+			ExtractLimits:   enum.DefaultExtractionLimits(),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("creating Nexus client: %w", err)
+	}
+	return scanEnumerator(cmd, e, "Nexus", nexusOutputPath, nexusOutputFormat)
+}
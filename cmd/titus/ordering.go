@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// resultOrder sorts matches and findings into the order every output format
+// uses, so that rerunning a scan or report over the same content produces
+// the same output: secrets validation confirmed live first, then by rule
+// ID, path and offset.
+type resultOrder struct {
+	s     store.Store
+	paths map[types.BlobID]string
+}
+
+func newResultOrder(s store.Store) *resultOrder {
+	return &resultOrder{s: s, paths: make(map[types.BlobID]string)}
+}
+
+// path returns the path of a blob's provenance, or its ID if it has none.
+func (o *resultOrder) path(id types.BlobID) string {
+	if p, ok := o.paths[id]; ok {
+		return p
+	}
+	p := id.Hex()
+	if prov, err := o.s.GetProvenance(id); err == nil && prov != nil {
+		p = prov.Path()
+	}
+	o.paths[id] = p
+	return p
+}
+
+// less orders active matches first, then matches by rule ID, path and
+// offset, breaking ties between copies of a blob at the same path by blob ID.
+func (o *resultOrder) less(a, b *types.Match) bool {
+	if a.Active() != b.Active() {
+		return a.Active()
+	}
+	if a.RuleID != b.RuleID {
+		return a.RuleID < b.RuleID
+	}
+	if pa, pb := o.path(a.BlobID), o.path(b.BlobID); pa != pb {
+		return pa < pb
+	}
+	if a.Location.Offset.Start != b.Location.Offset.Start {
+		return a.Location.Offset.Start < b.Location.Offset.Start
+	}
+	if a.Location.Offset.End != b.Location.Offset.End {
+		return a.Location.Offset.End < b.Location.Offset.End
+	}
+	if a.BlobID != b.BlobID {
+		return bytes.Compare(a.BlobID[:], b.BlobID[:]) < 0
+	}
+	return a.StructuralID < b.StructuralID
+}
+
+func (o *resultOrder) sortMatches(matches []*types.Match) {
+	sort.SliceStable(matches, func(i, j int) bool { return o.less(matches[i], matches[j]) })
+}
+
+// sortFindings orders active findings first, then findings by rule ID,
+// then by their first match in matchesByFinding, whose lists must already
+// be sorted, then by ID. Findings without matches come after those with.
+func (o *resultOrder) sortFindings(findings []*types.Finding, matchesByFinding map[string][]*types.Match) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if aa, ba := activeFinding(a, matchesByFinding), activeFinding(b, matchesByFinding); aa != ba {
+			return aa
+		}
+		if a.RuleID != b.RuleID {
+			return a.RuleID < b.RuleID
+		}
+		ma, mb := matchesByFinding[a.ID], matchesByFinding[b.ID]
+		if (len(ma) > 0) != (len(mb) > 0) {
+			return len(ma) > 0
+		}
+		if len(ma) > 0 && ma[0] != mb[0] {
+			if o.less(ma[0], mb[0]) {
+				return true
+			}
+			if o.less(mb[0], ma[0]) {
+				return false
+			}
+		}
+		return a.ID < b.ID
+	})
+}
+
+// activeFinding reports whether validation confirmed the secret of any of
+// f's matches is live.
+func activeFinding(f *types.Finding, matchesByFinding map[string][]*types.Match) bool {
+	return firstActiveMatch(f, matchesByFinding) != nil
+}
+
+// firstActiveMatch returns the first of f's matches, in matchesByFinding or
+// f.Matches, whose secret was confirmed live, or nil if there is none.
+func firstActiveMatch(f *types.Finding, matchesByFinding map[string][]*types.Match) *types.Match {
+	for _, m := range matchesByFinding[f.ID] {
+		if m.Active() {
+			return m
+		}
+	}
+	for _, m := range f.Matches {
+		if m.Active() {
+			return m
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultOrder(t *testing.T) {
+	s, err := store.New(store.Config{Path: ":memory:"})
+	require.NoError(t, err)
+	defer s.Close()
+
+	blobA := types.ComputeBlobID([]byte("a"))
+	blobB := types.ComputeBlobID([]byte("b"))
+	blobC := types.ComputeBlobID([]byte("c"))
+	require.NoError(t, s.AddProvenance(blobA, types.FileProvenance{FilePath: "src/a.go"}))
+	require.NoError(t, s.AddProvenance(blobB, types.FileProvenance{FilePath: "src/b.go"}))
+
+	match := func(id, ruleID string, blob types.BlobID, start int64) *types.Match {
+		m := &types.Match{StructuralID: id, RuleID: ruleID, BlobID: blob}
+		m.Location.Offset = types.OffsetSpan{Start: start, End: start + 10}
+		return m
+	}
+	matches := []*types.Match{
+		match("m1", "np.slack.2", blobA, 5),
+		match("m2", "np.aws.1", blobB, 5),
+		match("m3", "np.aws.1", blobA, 40),
+		match("m4", "np.aws.1", blobC, 0),
+		match("m5", "np.aws.1", blobA, 3),
+	}
+
+	order := newResultOrder(s)
+	order.sortMatches(matches)
+	var ids []string
+	for _, m := range matches {
+		ids = append(ids, m.StructuralID)
+	}
+	// blobC has no provenance, so it sorts by its hex ID in place of a path
+	assert.Equal(t, []string{"m4", "m5", "m3", "m2", "m1"}, ids)
+
+	findings := []*types.Finding{
+		{ID: "f-slack", RuleID: "np.slack.2"},
+		{ID: "f-none", RuleID: "np.aws.1"},
+		{ID: "f-b", RuleID: "np.aws.1"},
+		{ID: "f-a", RuleID: "np.aws.1"},
+	}
+	order.sortFindings(findings, map[string][]*types.Match{
+		"f-slack": {matches[4]},
+		"f-b":     {matches[3]},
+		"f-a":     {matches[1], matches[2]},
+	})
+	ids = nil
+	for _, f := range findings {
+		ids = append(ids, f.ID)
+	}
+	assert.Equal(t, []string{"f-a", "f-b", "f-none", "f-slack"}, ids)
+}
+
+func TestResultOrder_ActiveFirst(t *testing.T) {
+	s := store.NewMemory()
+	blob := types.ComputeBlobID([]byte("a"))
+	require.NoError(t, s.AddProvenance(blob, types.FileProvenance{FilePath: "src/a.go"}))
+
+	valid := types.NewValidationResult(types.StatusValid, 1, "")
+	invalid := types.NewValidationResult(types.StatusInvalid, 1, "")
+	matches := []*types.Match{
+		{StructuralID: "m1", RuleID: "np.aws.1", BlobID: blob},
+		{StructuralID: "m2", RuleID: "np.aws.1", BlobID: blob, ValidationResult: invalid},
+		{StructuralID: "m3", RuleID: "np.slack.2", BlobID: blob, ValidationResult: valid},
+	}
+	newResultOrder(s).sortMatches(matches)
+	assert.Equal(t, "m3", matches[0].StructuralID, "live secrets come first")
+
+	findings := []*types.Finding{
+		{ID: "f-aws", RuleID: "np.aws.1"},
+		{ID: "f-slack", RuleID: "np.slack.2"},
+	}
+	byFinding := map[string][]*types.Match{"f-aws": matches[1:], "f-slack": matches[:1]}
+	newResultOrder(s).sortFindings(findings, byFinding)
+	assert.Equal(t, "f-slack", findings[0].ID)
+
+	findings[1].Introduced = time.Now()
+	sortFindingsByAge(findings, byFinding)
+	assert.Equal(t, "f-slack", findings[0].ID, "live secrets stay first sorted by age")
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/praetorian-inc/titus/pkg/owner"
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// attributeOwners sets Owner on matches found in local git repositories:
+// git history blobs, and files scanned from a checkout. Matches in remote
+// repositories that were never cloned locally are left without an owner.
+func attributeOwners(ctx context.Context, s store.Store, matches []*types.Match) {
+	a := owner.NewAttributor()
+	for _, m := range matches {
+		provs, err := s.GetAllProvenance(m.BlobID)
+		if err != nil {
+			continue
+		}
+		line := m.Location.Source.Start.Line
+		for _, p := range provs {
+			if o := attributeProvenance(ctx, a, p, line); o != nil {
+				m.Owner = o
+				break
+			}
+		}
+	}
+}
+
+func attributeProvenance(ctx context.Context, a *owner.Attributor, prov types.Provenance, line int) *types.Owner {
+	switch p := prov.(type) {
+	case types.GitProvenance:
+		if info, err := os.Stat(p.RepoPath); err != nil || !info.IsDir() || p.BlobPath == "" {
+			return nil
+		}
+		rev := "HEAD"
+		if p.Commit != nil && p.Commit.CommitID != "" {
+			rev = p.Commit.CommitID
+		}
+		return a.Attribute(ctx, p.RepoPath, rev, p.BlobPath, line)
+	case types.FileProvenance:
+		if root, rel, ok := gitWorkTree(p.FilePath); ok {
+			return a.Attribute(ctx, root, "", rel, line)
+		}
+	}
+	return nil
+}
+
+// formatOwner renders an owner as "Name <email> (@team, @user)".
+func formatOwner(o *types.Owner) string {
+	var parts []string
+	switch {
+	case o.AuthorName != "" && o.AuthorEmail != "":
+		parts = append(parts, fmt.Sprintf("%s <%s>", o.AuthorName, o.AuthorEmail))
+	case o.AuthorName != "":
+		parts = append(parts, o.AuthorName)
+	case o.AuthorEmail != "":
+		parts = append(parts, "<"+o.AuthorEmail+">")
+	}
+	if len(o.Codeowners) > 0 {
+		parts = append(parts, "("+strings.Join(o.Codeowners, ", ")+")")
+	}
+	return strings.Join(parts, " ")
+}
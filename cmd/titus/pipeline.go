@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/filetype"
+	"github.com/praetorian-inc/titus/pkg/fpfilter"
+	"github.com/praetorian-inc/titus/pkg/matcher"
+	"github.com/praetorian-inc/titus/pkg/metrics"
+	"github.com/praetorian-inc/titus/pkg/siem"
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/praetorian-inc/titus/pkg/validator"
+)
+
+// streamPipeline scans and persists blobs one at a time as they arrive, for the
+// long-running watch and monitor commands and scan --follow.
+type streamPipeline struct {
+	matcher   matcher.Matcher
+	store     store.Store
+	ruleMap   map[string]*types.Rule
+	validator *validator.Engine
+	siem      *siem.Sink
+	metrics   *metrics.Registry
+	out       io.Writer
+
+	// lifecycle, if set, collects the blobs processed since the last
+	// updateLifecycle
+	lifecycle *scanLifecycle
+
+	// pending is a reloaded rule set waiting to be swapped in
+	pending atomic.Pointer[ruleSet]
+}
+
+// offerRules queues a reloaded rule set to be swapped in before the next
+// blob, replacing any set still waiting. It is safe to call while blobs are
+// being processed.
+func (p *streamPipeline) offerRules(rs *ruleSet) {
+	if stale := p.pending.Swap(rs); stale != nil {
+		stale.matcher.Close()
+	}
+}
+
+// useRules makes rs the pipeline's rules and closes the old matcher.
+func (p *streamPipeline) useRules(rs *ruleSet) error {
+	ruleMap := make(map[string]*types.Rule, len(rs.rules))
+	for _, r := range rs.rules {
+		ruleMap[r.ID] = r
+		if err := p.store.AddRule(r); err != nil {
+			rs.matcher.Close()
+			return fmt.Errorf("storing rule: %w", err)
+		}
+	}
+	old := p.matcher
+	p.matcher, p.ruleMap = rs.matcher, ruleMap
+	old.Close()
+	return nil
+}
+
+// closeMatchers closes the current matcher and any rule set still waiting.
+func (p *streamPipeline) closeMatchers() {
+	if rs := p.pending.Swap(nil); rs != nil {
+		rs.matcher.Close()
+	}
+	p.matcher.Close()
+}
+
+// process scans a single blob, persists the results, and notifies about
+// findings that were not already in the datastore. Match errors are logged and
+// skipped so one bad file never stops the daemon.
+func (p *streamPipeline) process(ctx context.Context, content []byte, blobID types.BlobID, prov types.Provenance) error {
+	_, created, err := p.scan(ctx, content, blobID, prov, 1)
+	if err != nil {
+		return err
+	}
+	for _, f := range created {
+		p.notify(f, prov)
+	}
+	return nil
+}
+
+// scan matches, validates and persists a single blob whose first line is line
+// firstLine of its source, returning the matches to report and the findings
+// that were not already in the datastore. Match errors are logged and yield
+// no matches.
+func (p *streamPipeline) scan(ctx context.Context, content []byte, blobID types.BlobID, prov types.Provenance, firstLine int) ([]*types.Match, []*types.Finding, error) {
+	if rs := p.pending.Swap(nil); rs != nil {
+		if err := p.useRules(rs); err != nil {
+			fmt.Fprintf(os.Stderr, "[rules] reload failed, keeping the current rules: %v\n", err)
+		}
+	}
+	prov = filetype.Tag(prov, content)
+	matches, err := p.matcher.MatchWithBlobID(content, blobID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[warn] match error (skipping %s): %v\n", prov.Path(), err)
+		return nil, nil, nil
+	}
+	setLineColumns(content, matches)
+	for _, match := range matches {
+		match.Location.Source.Start.Line += firstLine - 1
+		match.Location.Source.End.Line += firstLine - 1
+	}
+	reported := reportableMatches(matches, p.ruleMap)
+	validateMatches(ctx, p.validator, reported, verboseAt(verboseTrace))
+	emitSIEMEvents(p.siem, reported, prov)
+	if p.lifecycle != nil {
+		p.lifecycle.seeFile(blobID, prov)
+	}
+	p.metrics.ObserveBlob(int64(len(content)))
+	p.metrics.ObserveMatches(reported)
+
+	var created []*types.Finding
+	err = p.store.ExecBatch(func(tx store.Store) error {
+		var err error
+		created, err = recordBlob(tx, p.ruleMap, blobID, prov, int64(len(content)), matches)
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("storing results: %w", err)
+	}
+	return reported, created, nil
+}
+
+// updateLifecycle updates finding states from the blobs processed since the
+// last update, then starts collecting afresh. Errors are logged so the daemon
+// keeps running.
+func (p *streamPipeline) updateLifecycle(log io.Writer) {
+	if p.lifecycle == nil {
+		return
+	}
+	_, remediated, err := p.lifecycle.update(p.store, p.ruleMap)
+	p.lifecycle.reset()
+	if err != nil {
+		fmt.Fprintf(log, "[warn] updating finding states: %v\n", err)
+		return
+	}
+	if remediated > 0 {
+		fmt.Fprintf(p.out, "%s %d finding(s) no longer present, marked remediated\n",
+			time.Now().Format("2006-01-02 15:04:05"), remediated)
+	}
+}
+
+// notify prints a one-line notice for a newly discovered finding.
+func (p *streamPipeline) notify(f *types.Finding, prov types.Provenance) {
+	name := f.RuleID
+	if r, ok := p.ruleMap[f.RuleID]; ok {
+		name = r.Name
+	}
+	location := prov.Path()
+	status := ""
+	if len(f.Matches) > 0 {
+		m := f.Matches[0]
+		if m.Location.Source.Start.Line > 0 {
+			location = fmt.Sprintf("%s:%d", location, m.Location.Source.Start.Line)
+		}
+		if m.ValidationResult != nil {
+			status = fmt.Sprintf(" [%s]", m.ValidationResult.Status)
+		}
+		if fpfilter.New().Flag(m, []string{prov.Path()}) {
+			status += " [likely false positive]"
+		}
+	}
+	if gp, ok := prov.(types.GitProvenance); ok && gp.RepoPath != "" {
+		location = fmt.Sprintf("%s (%s)", location, gp.RepoPath)
+	}
+	fmt.Fprintf(p.out, "%s new finding: %s in %s (id %s)%s\n",
+		time.Now().Format("2006-01-02 15:04:05"), name, location, f.ID, status)
+}
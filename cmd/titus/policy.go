@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/praetorian-inc/titus/pkg/enum"
+	"github.com/praetorian-inc/titus/pkg/policy"
+	"github.com/praetorian-inc/titus/pkg/rule"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// scanPolicies applies the per-path policies of a scan config to a scan of
+// root: size and extraction settings to the enumerator, and rule packs and
+// exclusions to the matches of each blob.
+type scanPolicies struct {
+	cfg       *policy.Config
+	root      string
+	scanRules map[string]bool            // IDs of the rules selected for the whole scan
+	packRules map[string]map[string]bool // pack name -> IDs of its rules
+}
+
+// loadScanPolicies loads the scan config given by --config, or else the
+// titus.yaml in the root of target if there is one. It returns nil if there
+// is no config, and otherwise the policies along with rules extended by the
+// rule packs they name.
+func loadScanPolicies(target string, rules []*types.Rule) (*scanPolicies, []*types.Rule, error) {
+	root := target
+	if info, err := os.Stat(target); err == nil && !info.IsDir() {
+		root = filepath.Dir(target)
+	}
+
+	path := scanConfigPath
+	if path == "" {
+		candidate := filepath.Join(root, policy.FileName)
+		if _, err := os.Stat(candidate); err != nil {
+			return nil, rules, nil
+		}
+		path = candidate
+	}
+	cfg, err := policy.LoadConfig(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, p := range cfg.Policies {
+		if p.MaxFileSize != "" {
+			if _, err := parseSize(p.MaxFileSize); err != nil {
+				return nil, nil, fmt.Errorf("%s: policy %d: max_file_size: %w", path, i+1, err)
+			}
+		}
+	}
+
+	p := &scanPolicies{
+		cfg:       cfg,
+		root:      root,
+		scanRules: make(map[string]bool, len(rules)),
+		packRules: make(map[string]map[string]bool),
+	}
+	for _, r := range rules {
+		p.scanRules[r.ID] = true
+	}
+
+	packs := cfg.RulePacks()
+	if len(packs) == 0 {
+		return p, rules, nil
+	}
+	loader := rule.NewLoader()
+	available, err := loader.LoadBuiltinPacks()
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading rule packs: %w", err)
+	}
+	for _, dir := range scanRulePackDirs {
+		pack, err := loader.LoadPackDir(dir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("loading rule pack %s: %w", dir, err)
+		}
+		available = append(available, pack)
+	}
+	builtin, err := loader.LoadBuiltinRules()
+	if err != nil {
+		return nil, nil, err
+	}
+	added := make(map[string]bool)
+	for _, name := range packs {
+		packRules, err := rule.SelectPacks(available, builtin, []string{name})
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+		p.packRules[name] = make(map[string]bool, len(packRules))
+		for _, r := range packRules {
+			p.packRules[name][r.ID] = true
+			if !p.scanRules[r.ID] && !added[r.ID] {
+				added[r.ID] = true
+				rules = append(rules, r)
+			}
+		}
+	}
+	return p, rules, nil
+}
+
+// pathConfig returns the enum.Config.PathConfig applying the policies to
+// base.
+func (p *scanPolicies) pathConfig(base enum.Config) func(relPath string) enum.Config {
+	return func(relPath string) enum.Config {
+		config := base
+		s := p.cfg.For(relPath)
+		if s.MaxFileSize != "" {
+			config.MaxFileSize, _ = parseSize(s.MaxFileSize) // checked when loaded
+		}
+		if s.Extract != nil {
+			config.ExtractArchives = *s.Extract
+		}
+		return config
+	}
+}
+
+// repoPathConfig is pathConfig for the history of the repository at repo,
+// whose blob paths are relative to it.
+func (p *scanPolicies) repoPathConfig(base enum.Config, repo string) func(relPath string) enum.Config {
+	pathConfig := p.pathConfig(base)
+	prefix := p.repoPrefix(repo)
+	return func(relPath string) enum.Config {
+		return pathConfig(prefix + relPath)
+	}
+}
+
+// filter drops the matches the policies for the blob's path don't report:
+// those of excluded rules, and those of rule packs named only by policies
+// for other paths.
+func (p *scanPolicies) filter(prov types.Provenance, matches []*types.Match) []*types.Match {
+	if p == nil || len(matches) == 0 {
+		return matches
+	}
+	s := p.cfg.For(p.relPath(prov))
+	kept := matches[:0]
+	for _, m := range matches {
+		if s.Excludes(m.RuleID) || !p.reports(m.RuleID, s.RulePacks) {
+			continue
+		}
+		kept = append(kept, m)
+	}
+	return kept
+}
+
+// reports reports whether ruleID is selected for the scan or belongs to one
+// of packs.
+func (p *scanPolicies) reports(ruleID string, packs []string) bool {
+	if p.scanRules[ruleID] {
+		return true
+	}
+	for _, name := range packs {
+		if p.packRules[name][ruleID] {
+			return true
+		}
+	}
+	return false
+}
+
+// relPath returns the slash-separated path of a blob relative to the scan
+// root. Paths of git blobs are relative to their repository, which is
+// prefixed when it is nested below the root.
+func (p *scanPolicies) relPath(prov types.Provenance) string {
+	var path string
+	switch pr := prov.(type) {
+	case types.GitProvenance:
+		return p.repoPrefix(pr.RepoPath) + pr.BlobPath
+	case types.ArchiveProvenance:
+		path = pr.ArchivePath
+	case nil:
+		return ""
+	default:
+		path = pr.Path()
+	}
+	if rel, err := filepath.Rel(p.root, path); err == nil {
+		path = rel
+	}
+	return filepath.ToSlash(path)
+}
+
+// repoPrefix returns the slash-separated path of a repository nested below
+// the scan root, with a trailing slash, or "" for the root's own.
+func (p *scanPolicies) repoPrefix(repo string) string {
+	rel, err := filepath.Rel(p.root, repo)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	return filepath.ToSlash(rel) + "/"
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/enum"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadScanPolicies(t *testing.T) {
+	dir := t.TempDir()
+	rules := []*types.Rule{{ID: "custom.1"}}
+
+	policies, got, err := loadScanPolicies(dir, rules)
+	require.NoError(t, err)
+	assert.Nil(t, policies, "no titus.yaml")
+	assert.Equal(t, rules, got)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "titus.yaml"), []byte(`
+policies:
+  - paths: ["src/"]
+    rule_packs: [core]
+  - paths: ["tests/"]
+    exclude_rules: ["^custom\\."]
+  - paths: ["backups/"]
+    extract: none
+    max_file_size: 1KB
+`), 0644))
+	policies, got, err = loadScanPolicies(dir, rules)
+	require.NoError(t, err)
+	require.NotNil(t, policies)
+	var generic string
+	for _, r := range got {
+		if strings.HasPrefix(r.ID, "np.generic.") {
+			generic = r.ID
+			break
+		}
+	}
+	require.NotEmpty(t, generic, "rules of the core pack are added")
+
+	matches := func(path string) []string {
+		prov := types.FileProvenance{FilePath: filepath.Join(dir, path)}
+		var ids []string
+		for _, m := range policies.filter(prov, []*types.Match{{RuleID: "custom.1"}, {RuleID: generic}}) {
+			ids = append(ids, m.RuleID)
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"custom.1", generic}, matches("src/main.go"))
+	assert.Equal(t, []string{"custom.1"}, matches("docs/readme.md"))
+	assert.Empty(t, matches("tests/fixture.env"))
+
+	base := enum.Config{MaxFileSize: 10 * 1024 * 1024, ExtractArchives: "all"}
+	assert.Equal(t, base, policies.pathConfig(base)("src/main.go"))
+	config := policies.pathConfig(base)("backups/db.zip")
+	assert.Equal(t, int64(1024), config.MaxFileSize)
+	assert.Empty(t, config.ExtractArchives)
+
+	// Blob paths in a nested repository's history are relative to it
+	config = policies.repoPathConfig(base, filepath.Join(dir, "backups"))("db.zip")
+	assert.Equal(t, int64(1024), config.MaxFileSize)
+	assert.Equal(t, base, policies.repoPathConfig(base, dir)("db.zip"))
+}
+
+func TestScanPolicies_RelPathNestedRepository(t *testing.T) {
+	policies := &scanPolicies{root: "/work"}
+	assert.Equal(t, "src/main.go", policies.relPath(types.GitProvenance{RepoPath: "/work", BlobPath: "src/main.go"}))
+	assert.Equal(t, "vendor/lib/src/main.go", policies.relPath(types.GitProvenance{RepoPath: "/work/vendor/lib", BlobPath: "src/main.go"}))
+	assert.Equal(t, "main.go", policies.relPath(types.GitProvenance{RepoPath: "/elsewhere", BlobPath: "main.go"}))
+}
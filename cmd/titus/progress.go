@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// recentFindings is how many of the latest findings scanProgress keeps for
+// the dashboard.
+const recentFindings = 8
+
+// scanProgress counts scanned blobs and matches per rule, and reports each
+// scanned blob and the counts for scans run with -v. It also tracks what the
+// --dashboard view shows: busy workers and the latest findings. It is safe
+// for concurrent use by scan workers.
+type scanProgress struct {
+	out     io.Writer
+	enabled bool
+
+	blobs atomic.Int64
+	bytes atomic.Int64
+	busy  atomic.Int64
+
+	mu       sync.Mutex
+	counts   map[string]int
+	findings int64
+	recent   []recentFinding // oldest first
+}
+
+// recentFinding is a finding just recorded, and where it was found.
+type recentFinding struct {
+	ruleID string
+	path   string
+}
+
+func newScanProgress(out io.Writer) *scanProgress {
+	return &scanProgress{out: out, enabled: verboseAt(verboseProgress), counts: make(map[string]int)}
+}
+
+// blob counts the matches reported in a scanned blob, and reports it.
+func (p *scanProgress) blob(blobID types.BlobID, prov types.Provenance, size int, matches []*types.Match) {
+	p.blobs.Add(1)
+	p.bytes.Add(int64(size))
+	if !p.enabled && len(matches) == 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, m := range matches {
+		p.counts[m.RuleID]++
+	}
+	if !p.enabled {
+		return
+	}
+	path := blobID.Hex()
+	if prov != nil && prov.Path() != "" {
+		path = prov.Path()
+	}
+	fmt.Fprintf(p.out, "[scan] %s: %d B, %d matches\n", path, size, len(matches))
+}
+
+// printRuleStats prints how many matches each rule reported, most first.
+func (p *scanProgress) printRuleStats(ruleMap map[string]*types.Rule) {
+	if !p.enabled {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ruleIDs := make([]string, 0, len(p.counts))
+	for id := range p.counts {
+		ruleIDs = append(ruleIDs, id)
+	}
+	sort.Slice(ruleIDs, func(i, j int) bool {
+		if p.counts[ruleIDs[i]] != p.counts[ruleIDs[j]] {
+			return p.counts[ruleIDs[i]] > p.counts[ruleIDs[j]]
+		}
+		return ruleIDs[i] < ruleIDs[j]
+	})
+	for _, id := range ruleIDs {
+		name := id
+		if r, ok := ruleMap[id]; ok {
+			name = r.Name
+		}
+		fmt.Fprintf(p.out, "[rules] %s (%s): %d matches\n", id, name, p.counts[id])
+	}
+}
+
+// ruleCounts returns how many matches each rule reported.
+func (p *scanProgress) ruleCounts() map[string]int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	counts := make(map[string]int, len(p.counts))
+	for id, n := range p.counts {
+		counts[id] = n
+	}
+	return counts
+}
+
+// working records that a worker started (delta 1) or finished (delta -1)
+// work on a blob.
+func (p *scanProgress) working(delta int64) {
+	p.busy.Add(delta)
+}
+
+// found records findings newly created from a blob found at prov.
+func (p *scanProgress) found(prov types.Provenance, created []*types.Finding) {
+	if len(created) == 0 {
+		return
+	}
+	path := ""
+	if prov != nil {
+		path = prov.Path()
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.findings += int64(len(created))
+	for _, f := range created {
+		p.recent = append(p.recent, recentFinding{ruleID: f.RuleID, path: path})
+	}
+	if len(p.recent) > recentFindings {
+		p.recent = append(p.recent[:0], p.recent[len(p.recent)-recentFindings:]...)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerboseAt(t *testing.T) {
+	defer func(v int, q bool) { verbosity, quiet = v, q }(verbosity, quiet)
+
+	verbosity, quiet = 1, false
+	assert.True(t, verboseAt(verboseProgress))
+	assert.False(t, verboseAt(verboseTrace))
+
+	verbosity = 2
+	assert.True(t, verboseAt(verboseTrace))
+
+	quiet = true
+	assert.False(t, verboseAt(verboseProgress), "--quiet overrides -v")
+	var out bytes.Buffer
+	statusf(&out, "Scanned %d B\n", 10)
+	assert.Empty(t, out.String())
+}
+
+func TestScanProgress(t *testing.T) {
+	defer func(v int, q bool) { verbosity, quiet = v, q }(verbosity, quiet)
+	ruleMap := map[string]*types.Rule{"np.aws.1": {ID: "np.aws.1", Name: "AWS API Key"}}
+	matches := []*types.Match{{RuleID: "np.slack.2"}, {RuleID: "np.aws.1"}, {RuleID: "np.aws.1"}}
+
+	var out bytes.Buffer
+	verbosity, quiet = 0, false
+	p := newScanProgress(&out)
+	p.blob(types.ComputeBlobID([]byte("a")), types.FileProvenance{FilePath: "a.env"}, 120, matches)
+	p.printRuleStats(ruleMap)
+	assert.Empty(t, out.String())
+
+	verbosity = 1
+	p = newScanProgress(&out)
+	p.blob(types.ComputeBlobID([]byte("a")), types.FileProvenance{FilePath: "a.env"}, 120, matches)
+	p.printRuleStats(ruleMap)
+	assert.Equal(t, "[scan] a.env: 120 B, 3 matches\n"+
+		"[rules] np.aws.1 (AWS API Key): 2 matches\n"+
+		"[rules] np.slack.2 (np.slack.2): 1 matches\n", out.String())
+}
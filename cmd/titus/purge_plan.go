@@ -0,0 +1,296 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/praetorian-inc/titus/pkg/purge"
+	"github.com/praetorian-inc/titus/pkg/rule"
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	purgeDatastore       string
+	purgeTool            string
+	purgeOutputDir       string
+	purgeReplacement     string
+	purgeValidOnly       bool
+	purgeIncludeRejected bool
+)
+
+// Names of the files and directories purge-plan writes or points to in
+// --output-dir. Each repository gets its own paths file and mirror clone,
+// named after it.
+const (
+	purgeReplaceTextFile = "titus-replace-text.txt"
+	purgePathsFile       = "titus-paths-%s.txt"
+	purgeMirrorDir       = "titus-mirrors"
+)
+
+var purgePlanCmd = &cobra.Command{
+	Use:   "purge-plan",
+	Short: "Generate a plan to scrub findings from git history",
+	Long: `Generate the files and commands needed to remove every finding in a datastore
+from git history with git-filter-repo or BFG Repo-Cleaner.
+
+A replace-text file listing each secret is written to --output-dir, along with a
+paths file per repository of credential-only files (private keys, .env files,
+.netrc, ...) to delete outright. The commands to run are printed to stdout:
+they mirror-clone each repository's origin into --output-dir and rewrite the
+mirror.
+
+Findings rejected in 'titus explore' are left out; --valid-only further limits
+the plan to secrets that validated as live.
+
+Rewriting history does not revoke anything: rotate the credentials first, run
+the commands, then force-push every ref from the mirrors.
+
+Examples:
+  titus purge-plan
+  titus purge-plan --tool bfg --output-dir /tmp/purge
+  titus purge-plan --valid-only > purge.sh`,
+	RunE: runPurgePlan,
+}
+
+func init() {
+	purgePlanCmd.Flags().StringVar(&purgeDatastore, "datastore", "titus.ds", "Path to datastore directory or file")
+	purgePlanCmd.Flags().StringVar(&purgeTool, "tool", string(purge.ToolFilterRepo), "History rewriting tool: filter-repo, bfg")
+	purgePlanCmd.Flags().StringVar(&purgeOutputDir, "output-dir", ".", "Directory to write the replace-text and paths files to")
+	purgePlanCmd.Flags().StringVar(&purgeReplacement, "replacement", purge.DefaultReplacement, "Text to replace secrets with")
+	purgePlanCmd.Flags().BoolVar(&purgeValidOnly, "valid-only", false, "Only include findings with a match validated as live")
+	purgePlanCmd.Flags().BoolVar(&purgeIncludeRejected, "include-rejected", false, "Include findings rejected in titus explore")
+	rootCmd.AddCommand(purgePlanCmd)
+}
+
+func runPurgePlan(cmd *cobra.Command, args []string) error {
+	switch purge.Tool(purgeTool) {
+	case purge.ToolFilterRepo, purge.ToolBFG:
+	default:
+		return fmt.Errorf("unknown tool: %s", purgeTool)
+	}
+
+	storePath := purgeDatastore
+	if storePath == ":memory:" {
+		return fmt.Errorf("cannot plan from in-memory store")
+	}
+	info, err := os.Stat(storePath)
+	if err != nil {
+		return fmt.Errorf("datastore not found: %s", storePath)
+	}
+	if info.IsDir() {
+		storePath = filepath.Join(storePath, "datastore.db")
+	}
+
+	s, err := store.New(store.Config{
+		Path: storePath,
+	})
+	if err != nil {
+		return fmt.Errorf("opening datastore: %w", err)
+	}
+	defer s.Close()
+
+	rules, err := rule.NewLoader().LoadBuiltinRules()
+	if err != nil {
+		return fmt.Errorf("loading rules: %w", err)
+	}
+	ruleMap := make(map[string]*types.Rule)
+	for _, r := range rules {
+		ruleMap[r.ID] = r
+	}
+
+	plan, err := buildPurgePlan(s, ruleMap)
+	if err != nil {
+		return err
+	}
+	return writePurgePlan(plan, cmd.OutOrStdout(), cmd.ErrOrStderr())
+}
+
+// buildPurgePlan collects the secrets, credential files and repositories of
+// the selected findings. Matches of rules that don't capture the secret
+// itself are left out.
+func buildPurgePlan(s store.Store, ruleMap map[string]*types.Rule) (*purge.Plan, error) {
+	findings, err := s.GetFindings()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving findings: %w", err)
+	}
+	matches, err := s.GetAllMatches()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving matches: %w", err)
+	}
+	matchesByFinding := buildFindingMatchMap(findings, matches)
+
+	plan := purge.NewPlan()
+	for _, f := range findings {
+		if !purgeIncludeRejected {
+			status, _, err := s.GetAnnotation("finding", f.ID)
+			if err != nil {
+				return nil, fmt.Errorf("retrieving annotation: %w", err)
+			}
+			if status == "reject" {
+				continue
+			}
+		}
+
+		fm := matchesByFinding[f.ID]
+		if purgeValidOnly && !anyValid(fm) {
+			continue
+		}
+
+		for _, m := range fm {
+			if !capturesSecret(ruleMap, m.RuleID) {
+				continue
+			}
+			plan.AddSecret(secretValue(m))
+
+			provs, err := s.GetAllProvenance(m.BlobID)
+			if err != nil {
+				return nil, fmt.Errorf("retrieving provenance: %w", err)
+			}
+			for _, p := range provs {
+				switch p := p.(type) {
+				case types.GitProvenance:
+					plan.AddRepo(p.RepoPath)
+					plan.AddPath(p.RepoPath, p.BlobPath)
+				case types.FileProvenance:
+					// Files scanned from a checkout: purge the repository they're in
+					if root, rel, ok := gitWorkTree(p.FilePath); ok {
+						plan.AddRepo(root)
+						plan.AddPath(root, rel)
+					}
+				}
+			}
+		}
+	}
+	return plan, nil
+}
+
+// anyValid reports whether any match validated as live.
+func anyValid(matches []*types.Match) bool {
+	for _, m := range matches {
+		if m.ValidationResult != nil && m.ValidationResult.Status == types.StatusValid {
+			return true
+		}
+	}
+	return false
+}
+
+// gitWorkTree finds the git work tree containing path, returning its root
+// and path relative to it with forward slashes.
+func gitWorkTree(path string) (root, rel string, ok bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", "", false
+	}
+	for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			rel, err := filepath.Rel(dir, abs)
+			if err != nil {
+				return "", "", false
+			}
+			return dir, filepath.ToSlash(rel), true
+		}
+		if filepath.Dir(dir) == dir {
+			return "", "", false
+		}
+	}
+}
+
+// writePurgePlan writes the plan's files to --output-dir and prints the
+// commands that apply it.
+func writePurgePlan(plan *purge.Plan, out, errOut io.Writer) error {
+	secrets := plan.Secrets()
+	if len(secrets) == 0 {
+		fmt.Fprintln(errOut, "No findings to purge.")
+		return nil
+	}
+
+	if err := os.MkdirAll(purgeOutputDir, 0o755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+	dir, err := filepath.Abs(purgeOutputDir)
+	if err != nil {
+		return fmt.Errorf("resolving output directory: %w", err)
+	}
+
+	// The replace-text file lists live secrets, so keep it private
+	replaceText := filepath.Join(dir, purgeReplaceTextFile)
+	if err := writePlanFile(replaceText, func(w io.Writer) error {
+		return plan.WriteReplaceText(w, purgeReplacement)
+	}); err != nil {
+		return err
+	}
+	files := purgeRepoFiles(dir, plan.Repos())
+	var pathsFiles []string
+	for repo, f := range files {
+		if len(plan.Paths(repo)) == 0 {
+			continue
+		}
+		if err := writePlanFile(f.PathsFile, func(w io.Writer) error {
+			return plan.WritePaths(w, repo)
+		}); err != nil {
+			return err
+		}
+		pathsFiles = append(pathsFiles, f.PathsFile)
+	}
+	sort.Strings(pathsFiles)
+
+	fmt.Fprintf(out, "# Generated by titus purge-plan: %d secret(s), %d file(s) to delete\n", len(secrets), plan.PathCount())
+	fmt.Fprintln(out, "# Rotate every listed credential first: rewriting history does not revoke it.")
+	fmt.Fprintln(out, "# Each repository is rewritten in a mirror clone of its origin; force-push all refs from it afterwards.")
+	for _, c := range plan.Commands(purge.Tool(purgeTool), replaceText, func(repo string) purge.RepoFiles { return files[repo] }) {
+		fmt.Fprintln(out, c)
+	}
+
+	fmt.Fprintf(errOut, "Wrote %s\n", strings.Join(append([]string{replaceText}, pathsFiles...), ", "))
+	if repos := plan.Repos(); len(repos) == 0 {
+		fmt.Fprintln(errOut, "No git repository found for the findings; run the commands from the repository to purge.")
+	} else {
+		fmt.Fprintf(errOut, "Repositories: %s\n", strings.Join(repos, ", "))
+	}
+	return nil
+}
+
+// purgeRepoFiles names each repository's mirror clone and paths file in dir
+// after its base name, numbering repositories that share one. With no
+// repositories, the current directory's is keyed by "".
+func purgeRepoFiles(dir string, repos []string) map[string]purge.RepoFiles {
+	if len(repos) == 0 {
+		repos = []string{""}
+	}
+	files := make(map[string]purge.RepoFiles, len(repos))
+	used := make(map[string]int)
+	for _, repo := range repos {
+		name := strings.TrimSuffix(filepath.Base(repo), ".git")
+		if name == "" || name == "." || name == string(filepath.Separator) {
+			name = "repo"
+		}
+		used[name]++
+		if n := used[name]; n > 1 {
+			name = fmt.Sprintf("%s-%d", name, n)
+		}
+		files[repo] = purge.RepoFiles{
+			Mirror:    filepath.Join(dir, purgeMirrorDir, name+".git"),
+			PathsFile: filepath.Join(dir, fmt.Sprintf(purgePathsFile, name)),
+		}
+	}
+	return files
+}
+
+// writePlanFile creates path readable only by the owner and fills it with write.
+func writePlanFile(path string, write func(io.Writer) error) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Base(path), err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("writing %s: %w", filepath.Base(path), err)
+	}
+	return f.Close()
+}
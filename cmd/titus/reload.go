@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/matcher"
+	"github.com/praetorian-inc/titus/pkg/rule"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// ruleSet is a set of rules and the matcher compiled from them.
+type ruleSet struct {
+	rules   []*types.Rule
+	matcher matcher.Matcher
+}
+
+// ruleReloader polls rule files and directories for changes, and loads and
+// compiles the new rules in the background when they change, so long-running
+// commands can update their rules without a restart. A rule set that fails
+// to load or compile is reported, and the current rules stay in use.
+type ruleReloader struct {
+	paths    []string
+	interval time.Duration
+	load     func() ([]*types.Rule, error)
+	compile  func(rules []*types.Rule) (matcher.Matcher, error)
+	// publish receives each new rule set, to swap in between blobs. It
+	// owns the set's matcher.
+	publish func(*ruleSet)
+	log     io.Writer
+
+	fingerprint string
+}
+
+// reloadPaths returns the local rule files and directories to watch for
+// --rules-reload, or an error if there are none.
+func reloadPaths(rulesPath string, packDirs []string) ([]string, error) {
+	var paths []string
+	if rulesPath != "" && !rule.IsRemote(rulesPath) {
+		paths = append(paths, rulesPath)
+	}
+	paths = append(paths, packDirs...)
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("--rules-reload needs a local --rules file or a --rule-pack-dir")
+	}
+	return paths, nil
+}
+
+// run polls until ctx is done. The first poll records the current state of
+// the rule files, which the caller has already loaded.
+func (r *ruleReloader) run(ctx context.Context) {
+	r.fingerprint = rulesFingerprint(r.paths)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.poll()
+		}
+	}
+}
+
+// poll reloads the rules if their files changed since the last poll.
+func (r *ruleReloader) poll() {
+	fingerprint := rulesFingerprint(r.paths)
+	if fingerprint == r.fingerprint {
+		return
+	}
+	r.fingerprint = fingerprint
+
+	start := time.Now()
+	rules, err := r.load()
+	if err != nil {
+		fmt.Fprintf(r.log, "[rules] reload failed, keeping the current rules: %v\n", err)
+		return
+	}
+	m, err := r.compile(rules)
+	if err != nil {
+		fmt.Fprintf(r.log, "[rules] reload failed, keeping the current rules: %v\n", err)
+		return
+	}
+	fmt.Fprintf(r.log, "[rules] reloaded %d rules in %s\n", len(rules), time.Since(start).Round(time.Millisecond))
+	r.publish(&ruleSet{rules: rules, matcher: m})
+}
+
+// rulesFingerprint summarizes the names, sizes and modification times of
+// the files under paths. Paths that can't be read count as empty, so a
+// rule file being rewritten is picked up once it is back.
+func rulesFingerprint(paths []string) string {
+	h := sha256.New()
+	for _, root := range paths {
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			fmt.Fprintf(h, "%s\x00%d\x00%d\n", path, info.Size(), info.ModTime().UnixNano())
+			return nil
+		})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
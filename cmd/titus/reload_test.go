@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/matcher"
+	"github.com/praetorian-inc/titus/pkg/rule"
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleReloader_Poll(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pack.yml"), []byte("name: acme\nversion: 1.0.0\n"), 0644))
+	ruleFile := filepath.Join(dir, "acme.yml")
+	writeRule := func(yaml string, mtime time.Time) {
+		require.NoError(t, os.WriteFile(ruleFile, []byte(yaml), 0644))
+		require.NoError(t, os.Chtimes(ruleFile, mtime, mtime))
+	}
+	now := time.Now()
+	writeRule("rules:\n  - name: Acme Token\n    id: acme.1\n    pattern: 'acme_[a-z0-9]{32}'\n", now)
+
+	var published []*ruleSet
+	log := &bytes.Buffer{}
+	r := &ruleReloader{
+		paths: []string{dir},
+		load: func() ([]*types.Rule, error) {
+			return loadRuleSelection("", rule.FilterConfig{}, "default", "acme", []string{dir})
+		},
+		compile: func(rules []*types.Rule) (matcher.Matcher, error) {
+			return matcher.New(matcher.Config{Rules: rules})
+		},
+		publish: func(rs *ruleSet) { published = append(published, rs) },
+		log:     log,
+	}
+	r.fingerprint = rulesFingerprint(r.paths)
+
+	// Unchanged files are not reloaded
+	r.poll()
+	assert.Empty(t, published)
+
+	writeRule("rules:\n  - name: Acme Token\n    id: acme.2\n    pattern: 'acme2_[a-z0-9]{32}'\n", now.Add(time.Second))
+	r.poll()
+	require.Len(t, published, 1)
+	require.Len(t, published[0].rules, 1)
+	assert.Equal(t, "acme.2", published[0].rules[0].ID)
+	assert.Contains(t, log.String(), "[rules] reloaded 1 rules")
+	published[0].matcher.Close()
+
+	// A rule set that doesn't compile is reported and not published
+	writeRule("rules:\n  - name: Acme Token\n    id: acme.3\n    pattern: 'acme_[a-z'\n", now.Add(2*time.Second))
+	r.poll()
+	assert.Len(t, published, 1)
+	assert.Contains(t, log.String(), "reload failed, keeping the current rules")
+}
+
+func TestReloadPaths(t *testing.T) {
+	paths, err := reloadPaths("rules.yml", []string{"packs/acme"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rules.yml", "packs/acme"}, paths)
+
+	_, err = reloadPaths("https://example.com/rules.tar.gz", nil)
+	assert.ErrorContains(t, err, "--rules-reload needs")
+}
+
+func TestStreamPipeline_SwapsInOfferedRules(t *testing.T) {
+	s, err := store.New(store.Config{Path: ":memory:"})
+	require.NoError(t, err)
+	defer s.Close()
+
+	// The pipeline owns its matchers, closing each one it replaces
+	initial, err := matcher.New(matcher.Config{Rules: []*types.Rule{{ID: "other.1", Name: "Other", Pattern: `(other_[a-z0-9]{32})`}}})
+	require.NoError(t, err)
+	out := &bytes.Buffer{}
+	p := &streamPipeline{matcher: initial, store: s, ruleMap: map[string]*types.Rule{}, out: out}
+	defer p.closeMatchers()
+	content := []byte("token = acme_0123456789abcdef0123456789abcdef\n")
+	require.NoError(t, p.process(context.Background(), content, types.ComputeBlobID(content), types.FileProvenance{FilePath: "a.txt"}))
+	assert.Empty(t, out.String())
+
+	rules := []*types.Rule{{ID: "acme.1", Name: "Acme Token", Pattern: `(acme_[a-z0-9]{32})`}}
+	for _, r := range rules {
+		r.StructuralID = r.ComputeStructuralID()
+	}
+	m, err := matcher.New(matcher.Config{Rules: rules})
+	require.NoError(t, err)
+	p.offerRules(&ruleSet{rules: rules, matcher: m})
+
+	require.NoError(t, p.process(context.Background(), content, types.ComputeBlobID(content), types.FileProvenance{FilePath: "b.txt"}))
+	assert.Contains(t, out.String(), "new finding: Acme Token in b.txt:1")
+	assert.Same(t, m, p.matcher)
+}
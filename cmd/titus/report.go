@@ -1,64 +1,43 @@
 package main
 
 import (
+	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
-	"github.com/fatih/color"
+	"github.com/praetorian-inc/titus/pkg/filetype"
 	"github.com/praetorian-inc/titus/pkg/rule"
+	"github.com/praetorian-inc/titus/pkg/siem"
 	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/style"
 	"github.com/praetorian-inc/titus/pkg/types"
 	"github.com/spf13/cobra"
-	"golang.org/x/term"
 )
 
 var (
-	reportDatastore string
-	reportFormat    string
-	reportColor     string
-	summaryFormat   string
+	reportDatastore    string
+	reportFormat       string
+	reportOwners       bool
+	reportFileTypes    []string
+	reportExcludeTypes []string
+	reportMaxLocations int
+	reportGroupBy      string
+	reportSort         string
+	reportSince        string
+	reportAuthor       string
+	reportRefs         []string
+	reportSigning      signingFlags
+	summaryFormat      string
 )
 
-// styles holds color formatters matching NoseyParker color scheme
-type styles struct {
-	findingHeading *color.Color
-	id             *color.Color
-	ruleName       *color.Color
-	heading        *color.Color
-	match          *color.Color
-	metadata       *color.Color
-}
-
-// newStyles creates color formatters for report output
-// enabled=false respects --no-color flag and NO_COLOR env var
-func newStyles(enabled bool) *styles {
-	s := &styles{
-		findingHeading: color.New(color.Bold, color.FgHiWhite),
-		id:             color.New(color.FgHiGreen),
-		ruleName:       color.New(color.Bold, color.FgHiBlue),
-		heading:        color.New(color.Bold),
-		match:          color.New(color.FgYellow),
-		metadata:       color.New(color.FgHiBlue),
-	}
-
-	if !enabled {
-		// Disable colors on all formatters
-		s.findingHeading.DisableColor()
-		s.id.DisableColor()
-		s.ruleName.DisableColor()
-		s.heading.DisableColor()
-		s.match.DisableColor()
-		s.metadata.DisableColor()
-	}
-
-	return s
-}
-
 // snippetParts holds separated snippet components for colored output
 type snippetParts struct {
 	prefix   string // "..." if truncated at start
@@ -141,42 +120,32 @@ func aggregateSummary(findings []*types.Finding, matchesByFinding map[string][]*
 	return result
 }
 
-func outputSummaryHuman(out io.Writer, summary summaryResult, colorEnabled bool) error {
+// outputSummaryHuman writes the per-rule table, truncating rule names so
+// lines fit width columns when width is positive.
+func outputSummaryHuman(out io.Writer, summary summaryResult, colorEnabled bool, width int) error {
 	if summary.TotalFindings == 0 {
 		fmt.Fprintf(out, "No findings.\n")
 		return nil
 	}
 
-	s := newStyles(colorEnabled)
+	s := style.New(colorEnabled)
 
 	fmt.Fprintf(out, "%s %d findings, %d matches\n\n",
-		s.heading.Sprint("Total:"), summary.TotalFindings, summary.TotalMatches)
+		s.Heading.Sprint("Total:"), summary.TotalFindings, summary.TotalMatches)
 
-	// Find longest rule name for column width
-	maxNameLen := len("Rule")
-	for _, r := range summary.Rules {
-		if len(r.RuleName) > maxNameLen {
-			maxNameLen = len(r.RuleName)
-		}
+	table := style.Table{
+		Columns: []style.Column{
+			{Header: s.Heading.Sprint("Rule"), Shrink: true},
+			{Header: s.Heading.Sprint("Findings"), Right: true},
+			{Header: s.Heading.Sprint("Matches"), Right: true},
+		},
+		Separator: "─",
+		Width:     width,
 	}
-
-	// Print header
-	fmt.Fprintf(out, " %s   %s   %s \n",
-		s.heading.Sprintf("%-*s", maxNameLen, "Rule"),
-		s.heading.Sprint("Findings"),
-		s.heading.Sprint("Matches"))
-
-	// Print separator line
-	separatorLen := maxNameLen + 3 + 10 + 3 + 8
-	fmt.Fprintf(out, "%s\n", strings.Repeat("─", separatorLen))
-
-	// Print data rows
 	for _, r := range summary.Rules {
-		fmt.Fprintf(out, " %s   %8d   %7d \n",
-			s.ruleName.Sprintf("%-*s", maxNameLen, r.RuleName), r.Findings, r.Matches)
+		table.Rows = append(table.Rows, []string{s.RuleName.Sprint(r.RuleName), strconv.Itoa(r.Findings), strconv.Itoa(r.Matches)})
 	}
-
-	return nil
+	return table.Render(out)
 }
 
 func outputSummaryJSON(out io.Writer, summary summaryResult) error {
@@ -187,9 +156,18 @@ func outputSummaryJSON(out io.Writer, summary summaryResult) error {
 
 func init() {
 	reportCmd.PersistentFlags().StringVar(&reportDatastore, "datastore", "titus.ds", "Path to datastore directory or file")
-	reportCmd.Flags().StringVar(&reportFormat, "format", "human", "Output format: human, json, sarif")
-	reportCmd.PersistentFlags().StringVar(&reportColor, "color", "auto", "Color output: auto, always, never")
-	reportCmd.PersistentFlags().Lookup("color").NoOptDefVal = "always"
+	reportCmd.Flags().StringVar(&reportFormat, "format", "human", "Output format: human, json, csv, sarif, gitlab, cyclonedx, spdx, ocsf, ecs, github-summary")
+	reportCmd.Flags().BoolVar(&reportOwners, "owners", false, "Attribute matches to owners with git blame and CODEOWNERS")
+	reportCmd.Flags().StringSliceVar(&reportFileTypes, "file-type", nil, "Only report matches in these file types (e.g. Terraform,YAML)")
+	reportCmd.Flags().StringSliceVar(&reportExcludeTypes, "exclude-file-type", nil, "Leave out matches in these file types (e.g. Markdown)")
+	reportCmd.Flags().StringVar(&reportSort, "sort", "rule", "Finding order: rule, or age for the most recently introduced secrets first (by git commit date)")
+	reportCmd.Flags().StringVar(&reportSince, "since", "", "Only report git history matches of commits authored on or after this date (2023-01-01 or RFC 3339)")
+	reportCmd.Flags().StringVar(&reportAuthor, "author", "", "Only report git history matches of commits whose author name or email matches this regular expression")
+	reportCmd.Flags().StringSliceVar(&reportRefs, "ref", nil, "Only report git history matches of commits on these branches, tags or commits (reads the scanned repository)")
+	reportCmd.Flags().IntVar(&reportMaxLocations, "max-locations", defaultMaxLocations, "Places to list per match when its content was found in several (0 = all)")
+	reportCmd.Flags().StringVar(&reportGroupBy, "group-by", "finding", "Report entries: finding, or secret to merge the findings of every rule that captured the same secret (human and json formats)")
+	reportSigning.addFlags(reportCmd)
+	reportCmd.RunE = reportSigning.wrap(runReport, &reportFormat, &reportDatastore)
 
 	reportCmd.AddCommand(summaryCmd)
 	summaryCmd.Flags().StringVar(&summaryFormat, "format", "human", "Output format: human, json")
@@ -246,14 +224,68 @@ func runReport(cmd *cobra.Command, args []string) error {
 		ruleMap[r.ID] = r
 	}
 
+	findings, matches = filterByFileType(s, findings, matches, reportFileTypes, reportExcludeTypes)
+	gitFilter, err := newGitHistoryFilter(reportSince, reportAuthor, reportRefs)
+	if err != nil {
+		return err
+	}
+	if findings, matches, err = filterByGitHistory(s, findings, matches, gitFilter); err != nil {
+		return err
+	}
+	order := newResultOrder(s)
+	order.sortMatches(matches)
+	matchesByFinding := buildFindingMatchMap(findings, matches)
+	order.sortFindings(findings, matchesByFinding)
+	setIntroduced(s, findings, matchesByFinding)
+	switch reportSort {
+	case "rule":
+	case "age":
+		sortFindingsByAge(findings, matchesByFinding)
+	default:
+		return fmt.Errorf("unknown --sort %q (want rule or age)", reportSort)
+	}
+	flagLikelyFalsePositives(s, matches)
+	if reportOwners {
+		attributeOwners(context.Background(), s, matches)
+	}
+	newBlobLocations(s, reportMaxLocations).attach(matches)
+
+	switch reportGroupBy {
+	case "finding":
+	case "secret":
+		groups := groupFindingsBySecret(findings, ruleMap)
+		switch reportFormat {
+		case "json":
+			return outputSecretGroupsJSON(cmd.OutOrStdout(), groups, matchesByFinding)
+		case "human":
+			return outputSecretGroupsHuman(cmd.OutOrStdout(), s, groups, matchesByFinding, ruleMap)
+		default:
+			return fmt.Errorf("--group-by secret supports the human and json formats, not %s", reportFormat)
+		}
+	default:
+		return fmt.Errorf("unknown --group-by %q (want finding or secret)", reportGroupBy)
+	}
+
 	// Output based on format
 	switch reportFormat {
 	case "json":
 		return outputReportJSON(cmd, findings, matches, ruleMap)
+	case "csv":
+		return outputReportCSV(cmd.OutOrStdout(), s, findings, matches, ruleMap)
 	case "human":
 		return outputReportHuman(cmd, findings, matches, storePath, ruleMap)
 	case "sarif":
-		return fmt.Errorf("SARIF output not yet implemented")
+		return outputSARIF(cmd, s, rules, matches, reportMaxLocations)
+	case "gitlab":
+		return outputGitLabReport(cmd, s, matches, ruleMap, reportMaxLocations)
+	case "cyclonedx", "spdx":
+		return outputSBOM(cmd, reportFormat, findings, matches, ruleMap)
+	case "ocsf":
+		return outputReportEvents(cmd.OutOrStdout(), s, siem.FormatOCSF, findings, matches, ruleMap)
+	case "ecs":
+		return outputReportEvents(cmd.OutOrStdout(), s, siem.FormatECS, findings, matches, ruleMap)
+	case githubSummaryFormat:
+		return writeGitHubSummary(cmd.OutOrStdout(), s, findings, matchesByFinding, ruleMap)
 	default:
 		return fmt.Errorf("unknown output format: %s", reportFormat)
 	}
@@ -303,28 +335,14 @@ func runSummary(cmd *cobra.Command, args []string) error {
 		ruleMap[r.ID] = r
 	}
 
-	matchesByFinding := buildFindingMatchMap(findings, matches, ruleMap)
+	matchesByFinding := buildFindingMatchMap(findings, matches)
 	summary := aggregateSummary(findings, matchesByFinding, ruleMap)
 
-	// Determine color setting (inherited from parent)
-	switch reportColor {
-	case "always":
-		color.NoColor = false
-	case "never":
-		color.NoColor = true
-	default:
-		if !term.IsTerminal(int(os.Stdout.Fd())) || os.Getenv("NO_COLOR") != "" {
-			color.NoColor = true
-		} else {
-			color.NoColor = false
-		}
-	}
-
 	switch summaryFormat {
 	case "json":
 		return outputSummaryJSON(cmd.OutOrStdout(), summary)
 	case "human":
-		return outputSummaryHuman(cmd.OutOrStdout(), summary, !color.NoColor)
+		return outputSummaryHuman(cmd.OutOrStdout(), summary, colorEnabled, style.TerminalWidth(cmd.OutOrStdout()))
 	default:
 		return fmt.Errorf("unknown output format: %s", summaryFormat)
 	}
@@ -334,38 +352,24 @@ func runSummary(cmd *cobra.Command, args []string) error {
 // HELPERS
 // =============================================================================
 
-// buildFindingMatchMap groups matches by finding ID using content-based computation.
-// It uses structural ID matching with a fallback to RuleID + Groups matching.
-func buildFindingMatchMap(findings []*types.Finding, matches []*types.Match, ruleMap map[string]*types.Rule) map[string][]*types.Match {
-	matchesByFinding := make(map[string][]*types.Match)
-	for _, m := range matches {
-		r, ok := ruleMap[m.RuleID]
-		if ok {
-			findingID := types.ComputeFindingID(r.StructuralID, m.Groups)
-			matchesByFinding[findingID] = append(matchesByFinding[findingID], m)
-		}
+// buildFindingMatchMap groups matches by the ID of the finding they belong
+// to. A finding is its rule and capture groups, whichever fingerprint
+// version computed its ID, so matches are joined to findings on those
+// rather than by recomputing IDs, which would miss the findings of rules
+// edited since the scan.
+func buildFindingMatchMap(findings []*types.Finding, matches []*types.Match) map[string][]*types.Match {
+	idsByContent := make(map[string][]string, len(findings))
+	for _, f := range findings {
+		key := types.FindingContentKey(f.RuleID, f.Groups)
+		idsByContent[key] = append(idsByContent[key], f.ID)
 	}
 
-	// Fallback for rules not in builtin rules
-	for _, f := range findings {
-		if _, exists := matchesByFinding[f.ID]; !exists {
-			for _, m := range matches {
-				if m.RuleID == f.RuleID && len(m.Groups) == len(f.Groups) {
-					groupsMatch := true
-					for i := range m.Groups {
-						if string(m.Groups[i]) != string(f.Groups[i]) {
-							groupsMatch = false
-							break
-						}
-					}
-					if groupsMatch {
-						matchesByFinding[f.ID] = append(matchesByFinding[f.ID], m)
-					}
-				}
-			}
+	matchesByFinding := make(map[string][]*types.Match)
+	for _, m := range matches {
+		for _, id := range idsByContent[types.FindingContentKey(m.RuleID, m.Groups)] {
+			matchesByFinding[id] = append(matchesByFinding[id], m)
 		}
 	}
-
 	return matchesByFinding
 }
 
@@ -505,7 +509,7 @@ func formatSnippetWithParts(before, matching, after []byte, maxLen int) snippetP
 
 func outputReportJSON(cmd *cobra.Command, findings []*types.Finding, matches []*types.Match, ruleMap map[string]*types.Rule) error {
 	// Group matches by finding ID using content-based computation
-	matchesByFinding := buildFindingMatchMap(findings, matches, ruleMap)
+	matchesByFinding := buildFindingMatchMap(findings, matches)
 
 	// Attach matches to their findings
 	for _, f := range findings {
@@ -517,24 +521,86 @@ func outputReportJSON(cmd *cobra.Command, findings []*types.Finding, matches []*
 	return encoder.Encode(findings)
 }
 
-func outputReportHuman(cmd *cobra.Command, findings []*types.Finding, matches []*types.Match, datastorePath string, ruleMap map[string]*types.Rule) error {
-	out := cmd.OutOrStdout()
+// outputReportCSV writes one row per match, for spreadsheets and ticketing
+// imports.
+func outputReportCSV(out io.Writer, s store.Store, findings []*types.Finding, matches []*types.Match, ruleMap map[string]*types.Rule) error {
+	matchesByFinding := buildFindingMatchMap(findings, matches)
+
+	w := csv.NewWriter(out)
+	w.Write([]string{"finding_id", "rule_id", "rule_name", "state", "path", "file_type", "line", "repository", "commit",
+		"validation_status", "author_name", "author_email", "codeowners", "introduced"})
+	for _, f := range findings {
+		ruleName := f.RuleID
+		if r, ok := ruleMap[f.RuleID]; ok {
+			ruleName = r.Name
+		}
+		var introduced string
+		if !f.Introduced.IsZero() {
+			introduced = f.Introduced.UTC().Format(time.RFC3339)
+		}
+		for _, m := range matchesByFinding[f.ID] {
+			var path, fileType, repo, commit string
+			if prov, err := s.GetProvenance(m.BlobID); err == nil && prov != nil {
+				path, fileType = prov.Path(), filetype.Of(prov)
+				if gp, ok := prov.(types.GitProvenance); ok {
+					repo = gp.RepoPath
+					if gp.Commit != nil {
+						commit = gp.Commit.CommitID
+					}
+				}
+			}
+			var line, status string
+			if m.Location.Source.Start.Line > 0 {
+				line = fmt.Sprint(m.Location.Source.Start.Line)
+			}
+			if m.ValidationResult != nil {
+				status = string(m.ValidationResult.Status)
+			}
+			var author, email, owners string
+			if m.Owner != nil {
+				author, email = m.Owner.AuthorName, m.Owner.AuthorEmail
+				owners = strings.Join(m.Owner.Codeowners, " ")
+			}
+			w.Write([]string{f.ID, f.RuleID, ruleName, string(f.State), path, fileType, line, repo, commit,
+				status, author, email, owners, introduced})
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
 
-	// Determine if colors should be enabled based on --color flag
-	switch reportColor {
-	case "always":
-		color.NoColor = false
-	case "never":
-		color.NoColor = true
-	default: // "auto"
-		// Check if stdout is a TTY and NO_COLOR is not set
-		if !term.IsTerminal(int(os.Stdout.Fd())) || os.Getenv("NO_COLOR") != "" {
-			color.NoColor = true
-		} else {
-			color.NoColor = false
+// outputReportEvents writes one OCSF or ECS JSON document per match, in the
+// same shape --siem sends them, for loading into a security data lake.
+func outputReportEvents(out io.Writer, s store.Store, format siem.Format, findings []*types.Finding, matches []*types.Match, ruleMap map[string]*types.Rule) error {
+	matchesByFinding := buildFindingMatchMap(findings, matches)
+	for _, f := range findings {
+		for _, m := range matchesByFinding[f.ID] {
+			prov, err := s.GetProvenance(m.BlobID)
+			if err != nil {
+				prov = nil
+			}
+			ev := siem.NewEvent(m, prov)
+			ev.FindingID = f.ID
+			if ev.RuleName == "" {
+				if r, ok := ruleMap[f.RuleID]; ok {
+					ev.RuleName = r.Name
+				}
+			}
+			if !f.FirstSeen.IsZero() {
+				ev.Time = f.FirstSeen
+			}
+			if _, err := io.WriteString(out, siem.Encode(format, ev)+"\n"); err != nil {
+				return fmt.Errorf("writing %s output: %w", format, err)
+			}
 		}
 	}
-	s := newStyles(!color.NoColor)
+	return nil
+}
+
+func outputReportHuman(cmd *cobra.Command, findings []*types.Finding, matches []*types.Match, datastorePath string, ruleMap map[string]*types.Rule) error {
+	out := cmd.OutOrStdout()
+
+	s := style.New(colorEnabled)
 
 	// Resolve datastore path (same logic as runReport)
 	storePath := datastorePath
@@ -550,30 +616,47 @@ func outputReportHuman(cmd *cobra.Command, findings []*types.Finding, matches []
 	defer store.Close()
 
 	// Build content-based finding-to-match map
-	matchesByFinding := buildFindingMatchMap(findings, matches, ruleMap)
+	matchesByFinding := buildFindingMatchMap(findings, matches)
+
+	outputActiveSecrets(out, store, s, findings, matchesByFinding, ruleMap)
 
 	totalFindings := len(findings)
+	now := time.Now()
 
 	// Output each finding in noseyparker format with colors
 	for i, f := range findings {
 		// Finding header - "Finding N/M" in findingHeading style, "(id xyz)" with ID in id style
 		fmt.Fprintf(out, "%s (%s %s)\n",
-			s.findingHeading.Sprintf("Finding %d/%d", i+1, totalFindings),
-			s.heading.Sprint("id"),
-			s.id.Sprint(f.ID))
+			s.FindingHeading.Sprintf("Finding %d/%d", i+1, totalFindings),
+			s.Heading.Sprint("id"),
+			s.ID.Sprint(f.ID))
 
 		// Rule name - "Rule:" in heading style, rule name in ruleName style
 		ruleName := f.RuleID
 		if r, ok := ruleMap[f.RuleID]; ok {
 			ruleName = r.Name
 		}
-		fmt.Fprintf(out, "%s %s\n", s.heading.Sprint("Rule:"), s.ruleName.Sprint(ruleName))
+		fmt.Fprintf(out, "%s %s\n", s.Heading.Sprint("Rule:"), s.RuleName.Sprint(ruleName))
+
+		if f.State != "" {
+			fmt.Fprintf(out, "%s %s", s.Heading.Sprint("State:"), s.Metadata.Sprint(f.State))
+			if !f.FirstSeen.IsZero() {
+				fmt.Fprintf(out, " %s", s.Metadata.Sprintf("(first seen %s, last seen %s)",
+					f.FirstSeen.Format("2006-01-02"), f.LastSeen.Format("2006-01-02")))
+			}
+			fmt.Fprintln(out)
+		}
+
+		if !f.Introduced.IsZero() {
+			fmt.Fprintf(out, "%s %s %s\n", s.Heading.Sprint("Age:"), s.Metadata.Sprint(style.Age(f.Introduced, now, false)),
+				s.Metadata.Sprintf("(introduced %s)", f.Introduced.Format("2006-01-02")))
+		}
 
 		// Capture groups - "Group N:" in heading style, value in match style
 		for j, group := range f.Groups {
 			fmt.Fprintf(out, "%s %s\n",
-				s.heading.Sprintf("Group %d:", j+1),
-				s.match.Sprint(string(group)))
+				s.Heading.Sprintf("Group %d:", j+1),
+				s.Match.Sprint(string(group)))
 		}
 
 		// Matches for this finding
@@ -586,32 +669,81 @@ func outputReportHuman(cmd *cobra.Command, findings []*types.Finding, matches []
 		for k, match := range findingMatches {
 			// Match header - "Match N/M" in heading style, "(id xyz)" with ID in id style
 			fmt.Fprintf(out, "\n    %s (%s %s)\n",
-				s.heading.Sprintf("Match %d/%d", k+1, len(matchesByFinding[f.ID])),
-				s.heading.Sprint("id"),
-				s.id.Sprint(match.StructuralID))
+				s.Heading.Sprintf("Match %d/%d", k+1, len(matchesByFinding[f.ID])),
+				s.Heading.Sprint("id"),
+				s.ID.Sprint(match.StructuralID))
 
 			// File path from provenance - "File:" in heading style, path in metadata style
 			prov, err := store.GetProvenance(match.BlobID)
 			if err == nil && prov != nil {
 				fmt.Fprintf(out, "    %s %s\n",
-					s.heading.Sprint("File:"),
-					s.metadata.Sprint(prov.Path()))
+					s.Heading.Sprint("File:"),
+					s.Metadata.Sprint(prov.Path()))
+				if ft := filetype.Of(prov); ft != "" && ft != filetype.Unknown {
+					fmt.Fprintf(out, "    %s %s\n",
+						s.Heading.Sprint("Type:"),
+						s.Metadata.Sprint(ft))
+				}
 				if gp, ok := prov.(types.GitProvenance); ok && gp.Commit != nil && !gp.Commit.CommitterTimestamp.IsZero() {
 					fmt.Fprintf(out, "    %s %s\n",
-						s.heading.Sprint("Date:"),
-						s.metadata.Sprint(gp.Commit.CommitterTimestamp.Format("2006-01-02 15:04:05")))
+						s.Heading.Sprint("Date:"),
+						s.Metadata.Sprint(gp.Commit.CommitterTimestamp.Format("2006-01-02 15:04:05")))
+				}
+			}
+
+			// The same content found elsewhere
+			if len(match.Locations) > 1 {
+				for _, loc := range match.Locations[1:] {
+					fmt.Fprintf(out, "    %s %s\n",
+						s.Heading.Sprint("Also:"),
+						s.Metadata.Sprint(loc))
+				}
+			}
+			if shown := len(match.Locations); match.LocationCount > shown {
+				fmt.Fprintf(out, "    %s %s\n",
+					s.Heading.Sprint("Locations:"),
+					s.Metadata.Sprintf("%d (%d more not shown)", match.LocationCount, match.LocationCount-shown))
+			}
+
+			if match.Owner != nil {
+				fmt.Fprintf(out, "    %s %s\n",
+					s.Heading.Sprint("Owner:"),
+					s.Metadata.Sprint(formatOwner(match.Owner)))
+			}
+
+			if vr := match.ValidationResult; vr != nil {
+				valueStyle := s.Metadata
+				if match.Active() {
+					valueStyle = s.Active
 				}
+				fmt.Fprintf(out, "    %s %s\n",
+					s.Heading.Sprint("Validation:"),
+					valueStyle.Sprint(formatValidation(vr)))
+			}
+
+			if match.LikelyFP {
+				fmt.Fprintf(out, "    %s %s\n",
+					s.Heading.Sprint("Likely FP:"),
+					s.Metadata.Sprint(strings.Join(match.FPReasons, ", ")))
 			}
 
 			// Blob info - "Blob:" in heading style, ID in metadata style
 			fmt.Fprintf(out, "    %s %s\n",
-				s.heading.Sprint("Blob:"),
-				s.metadata.Sprint(match.BlobID.Hex()))
+				s.Heading.Sprint("Blob:"),
+				s.Metadata.Sprint(match.BlobID.Hex()))
+
+			// Filename matches have no lines; the snippet is the path.
+			// PII matches are personal data rather than secrets.
+			if match.Kind != "" {
+				fmt.Fprintf(out, "    %s %s\n",
+					s.Heading.Sprint("Kind:"),
+					s.Metadata.Sprint(match.Kind))
+			}
 
 			// Line info - "Lines:" in heading style
 			if match.Location.Source.Start.Line > 0 {
 				fmt.Fprintf(out, "    %s %d:%d-%d:%d\n",
-					s.heading.Sprint("Lines:"),
+					s.Heading.Sprint("Lines:"),
 					match.Location.Source.Start.Line, match.Location.Source.Start.Column,
 					match.Location.Source.End.Line, match.Location.Source.End.Column)
 			}
@@ -622,7 +754,7 @@ func outputReportHuman(cmd *cobra.Command, findings []*types.Finding, matches []
 				fmt.Fprintf(out, "\n        %s%s%s%s%s\n",
 					parts.prefix,
 					parts.before,
-					s.match.Sprint(parts.matching),
+					s.Match.Sprint(parts.matching),
 					parts.after,
 					parts.suffix)
 			}
@@ -224,56 +224,6 @@ func TestFormatSnippet_MinifiedJS_100KB_Line(t *testing.T) {
 	}
 }
 
-// =============================================================================
-// Tests for color styles
-// =============================================================================
-
-func TestNewStyles_Enabled(t *testing.T) {
-	// Test: Color styles should be created when enabled
-	styles := newStyles(true)
-
-	if styles == nil {
-		t.Fatal("Expected styles to be non-nil")
-	}
-
-	if styles.findingHeading == nil {
-		t.Error("Expected findingHeading style to be initialized")
-	}
-	if styles.id == nil {
-		t.Error("Expected id style to be initialized")
-	}
-	if styles.ruleName == nil {
-		t.Error("Expected ruleName style to be initialized")
-	}
-	if styles.heading == nil {
-		t.Error("Expected heading style to be initialized")
-	}
-	if styles.match == nil {
-		t.Error("Expected match style to be initialized")
-	}
-	if styles.metadata == nil {
-		t.Error("Expected metadata style to be initialized")
-	}
-}
-
-func TestNewStyles_Disabled(t *testing.T) {
-	// Test: When disabled, styles should still format but without colors
-	styles := newStyles(false)
-
-	if styles == nil {
-		t.Fatal("Expected styles to be non-nil")
-	}
-
-	// Test that formatting still works without colors
-	testText := "test"
-	result := styles.findingHeading.Sprint(testText)
-
-	// When disabled, should return the same text without ANSI codes
-	if result != testText {
-		t.Errorf("Expected plain text %q, got %q", testText, result)
-	}
-}
-
 func TestSnippetParts_Structure(t *testing.T) {
 	// Test: snippetParts should separate before/matching/after
 	before := []byte("context before ")
@@ -422,7 +372,7 @@ func TestOutputSummaryHuman(t *testing.T) {
 	}
 
 	var buf bytes.Buffer
-	err := outputSummaryHuman(&buf, summary, false)
+	err := outputSummaryHuman(&buf, summary, false, 0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -456,7 +406,7 @@ func TestOutputSummaryHuman_Empty(t *testing.T) {
 	summary := summaryResult{}
 
 	var buf bytes.Buffer
-	err := outputSummaryHuman(&buf, summary, false)
+	err := outputSummaryHuman(&buf, summary, false, 0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -0,0 +1,407 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/rule"
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/style"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rollupDatastore string
+	rollupFormat    string
+	rollupDepth     int
+	rollupTop       int
+)
+
+var rollupCmd = &cobra.Command{
+	Use:   "summary",
+	Short: "Print aggregate tables of findings for status updates",
+	Long: `Print only aggregate counts of the open findings in a datastore, with no
+secrets, snippets or per-finding detail, so the output can be pasted into a
+status update or ticket:
+
+  - by severity: active, unverified, likely test data, revoked
+  - by validation status
+  - by rule
+  - by path prefix, the first --depth directories below the directory
+    common to every location
+  - the trend against the previous scan, when the datastore recorded two
+    or more: findings first found by the latest scan, and findings the
+    latest scan marked remediated
+
+Remediated findings are counted but left out of the tables. Use
+'titus report summary' for per-rule finding and match counts alone.
+
+Examples:
+  titus summary
+  titus summary --format markdown --depth 1`,
+	Args: cobra.NoArgs,
+	RunE: runRollup,
+}
+
+func init() {
+	rollupCmd.Flags().StringVar(&rollupDatastore, "datastore", "titus.ds", "Path to datastore directory or file")
+	rollupCmd.Flags().StringVar(&rollupFormat, "format", "text", "Output format: text, markdown, json")
+	rollupCmd.Flags().IntVar(&rollupDepth, "depth", 2, "Directories in each path prefix")
+	rollupCmd.Flags().IntVar(&rollupTop, "top", 10, "Rows per rule and path table in text and markdown (0 = all)")
+	rootCmd.AddCommand(rollupCmd)
+}
+
+func runRollup(cmd *cobra.Command, args []string) error {
+	switch rollupFormat {
+	case "text", "markdown", "json":
+	default:
+		return fmt.Errorf("unknown format %q (want text, markdown or json)", rollupFormat)
+	}
+	if rollupDepth < 1 {
+		return fmt.Errorf("--depth must be at least 1")
+	}
+
+	s, err := openDatastore(rollupDatastore)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	rules, err := rule.NewLoader().LoadBuiltinRules()
+	if err != nil {
+		return fmt.Errorf("loading rules: %w", err)
+	}
+	ruleMap := make(map[string]*types.Rule)
+	for _, r := range rules {
+		ruleMap[r.ID] = r
+	}
+
+	r, err := buildRollup(s, ruleMap, rollupDepth)
+	if err != nil {
+		return err
+	}
+	switch rollupFormat {
+	case "json":
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	case "markdown":
+		return r.writeMarkdown(cmd.OutOrStdout(), rollupTop)
+	}
+	return r.writeText(cmd.OutOrStdout(), rollupTop, style.TerminalWidth(cmd.OutOrStdout()))
+}
+
+// rollupCount is a row of a summary table: the findings counted under a name.
+type rollupCount struct {
+	Name     string `json:"name"`
+	Findings int    `json:"findings"`
+}
+
+// rollupTrend compares the latest recorded scan with the one before it.
+type rollupTrend struct {
+	PreviousScan int64     `json:"previous_scan"`
+	PreviousAt   time.Time `json:"previous_at"`
+	LatestScan   int64     `json:"latest_scan"`
+	LatestAt     time.Time `json:"latest_at"`
+	New          int       `json:"new"`        // findings whose matches the latest scan stored first
+	Remediated   int       `json:"remediated"` // findings seen by the previous scan, now remediated
+}
+
+// rollup is the aggregate view of a datastore printed by the summary command.
+type rollup struct {
+	Findings     int           `json:"findings"` // open findings
+	Matches      int           `json:"matches"`
+	Active       int           `json:"active"`
+	Remediated   int           `json:"remediated"`
+	BySeverity   []rollupCount `json:"by_severity"`
+	ByValidation []rollupCount `json:"by_validation"`
+	ByRule       []rollupCount `json:"by_rule"`
+	PathRoot     string        `json:"path_root,omitempty"` // directory common to every location
+	ByPath       []rollupCount `json:"by_path"`
+	Trend        *rollupTrend  `json:"trend,omitempty"`
+}
+
+// buildRollup counts the open findings in s by severity, validation status,
+// rule and path prefix of depth directories, and compares the latest two
+// recorded scans.
+func buildRollup(s store.Store, ruleMap map[string]*types.Rule, depth int) (*rollup, error) {
+	findings, err := s.GetFindings()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving findings: %w", err)
+	}
+	matches, err := s.GetAllMatches()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving matches: %w", err)
+	}
+	scans, err := s.GetScans()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving scans: %w", err)
+	}
+	flagLikelyFalsePositives(s, matches)
+	matchesByFinding := buildFindingMatchMap(findings, matches)
+
+	r := &rollup{}
+	if len(scans) >= 2 {
+		previous, latest := scans[len(scans)-2], scans[len(scans)-1]
+		r.Trend = &rollupTrend{PreviousScan: previous.ID, PreviousAt: previous.StartedAt, LatestScan: latest.ID, LatestAt: latest.StartedAt}
+	}
+
+	severities := make(map[string]int)
+	validations := make(map[string]int)
+	rules := make(map[string]int)
+	paths := make(map[*types.Finding][]string)
+	provenance := make(map[types.BlobID][]types.Provenance)
+	for _, f := range findings {
+		fm := matchesByFinding[f.ID]
+		if f.State == types.FindingStateRemediated {
+			r.Remediated++
+			if r.Trend != nil && !f.LastSeen.Before(r.Trend.PreviousAt.Truncate(time.Second)) {
+				r.Trend.Remediated++
+			}
+			continue
+		}
+		r.Findings++
+		r.Matches += len(fm)
+		if activeFinding(f, matchesByFinding) {
+			r.Active++
+		}
+		severities[findingSeverity(fm).label]++
+		validations[findingValidation(fm)]++
+		rules[summaryRuleName(f.RuleID, ruleMap)]++
+		if r.Trend != nil && firstStoredBy(fm, r.Trend.LatestScan) {
+			r.Trend.New++
+		}
+
+		for _, m := range fm {
+			provs, ok := provenance[m.BlobID]
+			if !ok {
+				if provs, err = s.GetAllProvenance(m.BlobID); err != nil {
+					return nil, fmt.Errorf("retrieving provenance: %w", err)
+				}
+				provenance[m.BlobID] = provs
+			}
+			for _, p := range provs {
+				if loc := rollupLocation(p); loc != "" {
+					paths[f] = append(paths[f], loc)
+				}
+			}
+		}
+	}
+
+	for _, sev := range []summarySeverity{severityActive, severityUnverified, severityLikelyFP, severityRevoked} {
+		if n := severities[sev.label]; n > 0 {
+			r.BySeverity = append(r.BySeverity, rollupCount{Name: sev.label, Findings: n})
+		}
+	}
+	for _, status := range []string{string(types.StatusValid), string(types.StatusInvalid), string(types.StatusUndetermined), "not validated"} {
+		if n := validations[status]; n > 0 {
+			r.ByValidation = append(r.ByValidation, rollupCount{Name: status, Findings: n})
+		}
+	}
+	r.ByRule = sortedRollupCounts(rules)
+	r.PathRoot, r.ByPath = rollupPaths(paths, depth)
+	return r, nil
+}
+
+// findingValidation returns the most telling validation status of a
+// finding's matches: valid, then invalid, then undetermined.
+func findingValidation(matches []*types.Match) string {
+	rank := map[types.ValidationStatus]int{types.StatusValid: 3, types.StatusInvalid: 2, types.StatusUndetermined: 1}
+	status := "not validated"
+	best := 0
+	for _, m := range matches {
+		if m.ValidationResult == nil {
+			continue
+		}
+		if r := rank[m.ValidationResult.Status]; r > best {
+			best, status = r, string(m.ValidationResult.Status)
+		}
+	}
+	return status
+}
+
+// firstStoredBy reports whether every one of matches was stored by the scan
+// with ID scanID, so their finding is new in that scan.
+func firstStoredBy(matches []*types.Match, scanID int64) bool {
+	for _, m := range matches {
+		if m.ScanID != scanID {
+			return false
+		}
+	}
+	return len(matches) > 0
+}
+
+// rollupLocation returns the slash-separated path of a location, with
+// git history paths under their repository, or "" if it has none.
+func rollupLocation(p types.Provenance) string {
+	switch p := p.(type) {
+	case types.GitProvenance:
+		if p.BlobPath == "" {
+			return ""
+		}
+		return path.Join(filepath.ToSlash(p.RepoPath), p.BlobPath)
+	case types.ArchiveProvenance:
+		return filepath.ToSlash(p.ArchivePath)
+	}
+	return filepath.ToSlash(p.Path())
+}
+
+// rollupPaths counts findings by the first depth directories of their
+// locations below the directory common to all of them, which it returns.
+// A finding is counted once under each prefix it was found in.
+func rollupPaths(paths map[*types.Finding][]string, depth int) (string, []rollupCount) {
+	var root []string
+	first := true
+	for _, locs := range paths {
+		for _, loc := range locs {
+			dirs := strings.Split(path.Dir(loc), "/")
+			if first {
+				root, first = dirs, false
+				continue
+			}
+			n := 0
+			for n < len(root) && n < len(dirs) && root[n] == dirs[n] {
+				n++
+			}
+			root = root[:n]
+		}
+	}
+	if len(root) == 1 && root[0] == "." {
+		root = nil
+	}
+
+	counts := make(map[string]int)
+	for _, locs := range paths {
+		seen := make(map[string]bool)
+		for _, loc := range locs {
+			dirs := strings.Split(path.Dir(loc), "/")[len(root):]
+			if len(dirs) > depth {
+				dirs = dirs[:depth]
+			}
+			prefix := "./"
+			if len(dirs) > 0 && dirs[0] != "." {
+				prefix = strings.Join(dirs, "/") + "/"
+			}
+			if !seen[prefix] {
+				seen[prefix] = true
+				counts[prefix]++
+			}
+		}
+	}
+
+	rootPath := ""
+	if len(root) > 0 {
+		rootPath = strings.Join(root, "/") + "/"
+	}
+	return rootPath, sortedRollupCounts(counts)
+}
+
+// sortedRollupCounts returns counts as rows, most findings first.
+func sortedRollupCounts(counts map[string]int) []rollupCount {
+	rows := make([]rollupCount, 0, len(counts))
+	for name, n := range counts {
+		rows = append(rows, rollupCount{Name: name, Findings: n})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Findings != rows[j].Findings {
+			return rows[i].Findings > rows[j].Findings
+		}
+		return rows[i].Name < rows[j].Name
+	})
+	return rows
+}
+
+// rollupTable is a summary table as printed.
+type rollupTable struct {
+	heading string
+	rows    []rollupCount
+	more    int // rows left out by --top
+}
+
+// tables returns the summary's tables, the rule and path tables cut to top
+// rows unless top is 0.
+func (r *rollup) tables(top int) []rollupTable {
+	limit := func(heading string, rows []rollupCount) rollupTable {
+		t := rollupTable{heading: heading, rows: rows}
+		if top > 0 && len(rows) > top {
+			t.rows, t.more = rows[:top], len(rows)-top
+		}
+		return t
+	}
+	pathHeading := "Path"
+	if r.PathRoot != "" {
+		pathHeading = "Path (under " + r.PathRoot + ")"
+	}
+	tables := []rollupTable{
+		{heading: "Severity", rows: r.BySeverity},
+		{heading: "Validation", rows: r.ByValidation},
+		limit("Rule", r.ByRule),
+		limit(pathHeading, r.ByPath),
+	}
+	if t := r.Trend; t != nil {
+		tables = append(tables, rollupTable{
+			heading: fmt.Sprintf("Since scan %d (%s)", t.PreviousScan, t.PreviousAt.UTC().Format("2006-01-02 15:04 MST")),
+			rows:    []rollupCount{{Name: "new", Findings: t.New}, {Name: "remediated", Findings: t.Remediated}},
+		})
+	}
+	return tables
+}
+
+// headline is the one-line total that opens the summary.
+func (r *rollup) headline() string {
+	return fmt.Sprintf("%d open findings (%d active), %d matches; %d remediated", r.Findings, r.Active, r.Matches, r.Remediated)
+}
+
+// writeText writes the summary as plain aligned tables, truncating names
+// so lines fit width columns when width is positive.
+func (r *rollup) writeText(out io.Writer, top, width int) error {
+	fmt.Fprintf(out, "%s\n", r.headline())
+	for _, t := range r.tables(top) {
+		if len(t.rows) == 0 {
+			continue
+		}
+		table := style.Table{
+			Columns: []style.Column{{Header: t.heading, Shrink: true}, {Header: "Findings", Right: true}},
+			Width:   width,
+		}
+		for _, row := range t.rows {
+			table.Rows = append(table.Rows, []string{row.Name, strconv.Itoa(row.Findings)})
+		}
+		if t.more > 0 {
+			table.Rows = append(table.Rows, []string{fmt.Sprintf("(%d more)", t.more)})
+		}
+		fmt.Fprintln(out)
+		if err := table.Render(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMarkdown writes the summary as Markdown tables.
+func (r *rollup) writeMarkdown(out io.Writer, top int) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s**\n", r.headline())
+	for _, t := range r.tables(top) {
+		if len(t.rows) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n| %s | Findings |\n|---|---:|\n", markdownCell(t.heading))
+		for _, row := range t.rows {
+			fmt.Fprintf(&b, "| %s | %d |\n", markdownCell(row.Name), row.Findings)
+		}
+		if t.more > 0 {
+			fmt.Fprintf(&b, "| _%d more_ | |\n", t.more)
+		}
+	}
+	_, err := io.WriteString(out, b.String())
+	return err
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildRollup(t *testing.T) {
+	s := store.NewMemory()
+	add := func(findingID, ruleID, secret string, validation *types.ValidationResult, provs ...types.Provenance) {
+		content := "key=" + secret
+		blobID := types.ComputeBlobID([]byte(content))
+		groups := [][]byte{[]byte(secret)}
+		require.NoError(t, s.AddBlob(blobID, int64(len(content))))
+		for _, prov := range provs {
+			require.NoError(t, s.AddProvenance(blobID, prov))
+		}
+		require.NoError(t, s.AddMatch(&types.Match{BlobID: blobID, StructuralID: findingID + "-m", RuleID: ruleID, Groups: groups, ValidationResult: validation}))
+		require.NoError(t, s.AddFinding(&types.Finding{ID: findingID, RuleID: ruleID, Groups: groups}))
+	}
+
+	previous := &types.Scan{StartedAt: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)}
+	require.NoError(t, s.AddScan(previous))
+	add("old", "np.aws.1", "AKIAOLD", types.NewValidationResult(types.StatusValid, 1, ""),
+		types.FileProvenance{FilePath: "/src/app/api/config.env"})
+	add("fixed", "np.aws.1", "AKIAFIXED", nil, types.FileProvenance{FilePath: "/src/app/web/.env"})
+	require.NoError(t, s.MarkFindingsSeen([]string{"old", "fixed"}, previous.StartedAt))
+
+	latest := &types.Scan{StartedAt: time.Date(2026, 10, 8, 9, 0, 0, 0, time.UTC)}
+	require.NoError(t, s.AddScan(latest))
+	add("new", "np.github.1", "ghp_new", types.NewValidationResult(types.StatusInvalid, 1, ""),
+		types.GitProvenance{RepoPath: "/src/app", BlobPath: "api/deploy/ci.yml"},
+		types.FileProvenance{FilePath: "/src/app/README"})
+	require.NoError(t, s.SetFindingState("fixed", types.FindingStateRemediated))
+
+	ruleMap := map[string]*types.Rule{"np.aws.1": {ID: "np.aws.1", Name: "AWS API Key"}}
+	r, err := buildRollup(s, ruleMap, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, r.Findings)
+	assert.Equal(t, 1, r.Active)
+	assert.Equal(t, 1, r.Remediated)
+	assert.Equal(t, []rollupCount{{"active", 1}, {"revoked", 1}}, r.BySeverity)
+	assert.Equal(t, []rollupCount{{"valid", 1}, {"invalid", 1}}, r.ByValidation)
+	assert.Equal(t, []rollupCount{{"AWS API Key", 1}, {"np.github.1", 1}}, r.ByRule)
+	assert.Equal(t, "/src/app/", r.PathRoot)
+	assert.Equal(t, []rollupCount{{"api/", 2}, {"./", 1}}, r.ByPath)
+	require.NotNil(t, r.Trend)
+	assert.Equal(t, 1, r.Trend.New)
+	assert.Equal(t, 1, r.Trend.Remediated)
+
+	var out bytes.Buffer
+	require.NoError(t, r.writeText(&out, 1, 0))
+	text := out.String()
+	assert.Contains(t, text, "2 open findings (1 active), 2 matches; 1 remediated\n")
+	assert.Contains(t, text, " Path (under /src/app/)   Findings \n api/                            2 \n")
+	assert.Contains(t, text, " (1 more)  ")
+	assert.Contains(t, text, "Since scan 1 (2026-10-01 09:00 UTC)")
+	assert.NotContains(t, text, "AKIA")
+
+	out.Reset()
+	require.NoError(t, r.writeMarkdown(&out, 0))
+	assert.Contains(t, out.String(), "| Rule | Findings |\n|---|---:|\n| AWS API Key | 1 |\n| np.github.1 | 1 |\n")
+}
+
+func TestRollupPaths(t *testing.T) {
+	a, b := &types.Finding{ID: "a"}, &types.Finding{ID: "b"}
+	root, rows := rollupPaths(map[*types.Finding][]string{
+		a: {"services/api/main.go", "services/api/handlers/auth.go"},
+		b: {"config.yml", "services/web/app.js"},
+	}, 2)
+	assert.Equal(t, "", root)
+	assert.Equal(t, []rollupCount{{"./", 1}, {"services/api/", 1}, {"services/web/", 1}}, rows)
+}
@@ -1,12 +1,23 @@
 package main
 
 import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/praetorian-inc/titus/pkg/style"
 	"github.com/spf13/cobra"
 )
 
 var (
-	verbose bool
-	quiet   bool
+	verbosity int
+	quiet     bool
+	colorArg  string
+	noColor   bool
+
+	// colorEnabled is whether human output on stdout is colored, resolved
+	// from --color, --no-color and NO_COLOR before each command runs.
+	colorEnabled bool
 )
 
 var rootCmd = &cobra.Command{
@@ -14,16 +25,26 @@ var rootCmd = &cobra.Command{
 	Short: "Titus - Go port of NoseyParker secrets scanner",
 	Long: `Titus is a fast secrets scanner that finds credentials in code, files, and git history.
 It uses regex-based detection rules to identify sensitive data like API keys, passwords, and tokens.`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		mode, err := colorMode()
+		if err != nil {
+			return err
+		}
+		colorEnabled = style.Enabled(mode, os.Stdout)
+		style.Apply(colorEnabled)
 		if !quiet {
-			printBanner()
+			printBanner(style.Enabled(mode, os.Stderr))
 		}
+		return nil
 	},
 }
 
 func init() {
-	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
-	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Quiet mode (errors only)")
+	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "Verbose output: -v for per-file progress and per-rule stats, -vv to also trace validation")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Quiet mode: print only findings and errors")
+	rootCmd.PersistentFlags().StringVar(&colorArg, "color", "auto", "Color output: auto, always, never")
+	rootCmd.PersistentFlags().Lookup("color").NoOptDefVal = "always"
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable color output (same as --color=never)")
 
 	// Add subcommands
 	rootCmd.AddCommand(scanCmd)
@@ -35,6 +56,34 @@ func init() {
 	rootCmd.AddCommand(exploreCmd)
 }
 
+// Verbosity levels, each adding to the output of the one before.
+const (
+	verboseProgress = 1 // -v: per-file progress and per-rule stats
+	verboseTrace    = 2 // -vv: validation traces
+)
+
+// verboseAt reports whether output at a verbosity level is enabled. --quiet
+// overrides -v.
+func verboseAt(level int) bool {
+	return !quiet && verbosity >= level
+}
+
+// statusf prints a status line, such as scan statistics, unless --quiet is
+// set.
+func statusf(w io.Writer, format string, args ...any) {
+	if !quiet {
+		fmt.Fprintf(w, format, args...)
+	}
+}
+
+// colorMode returns the color mode set by --color and --no-color.
+func colorMode() (style.Mode, error) {
+	if noColor {
+		return style.Never, nil
+	}
+	return style.ParseMode(colorArg)
+}
+
 // Execute runs the root command.
 func Execute() error {
 	return rootCmd.Execute()
@@ -11,10 +11,13 @@ import (
 )
 
 var (
-	rulesPath    string
-	rulesInclude string
-	rulesExclude string
-	outputFormat string
+	rulesPath              string
+	rulesInclude           string
+	rulesExclude           string
+	rulesCategories        string
+	rulesExcludeCategories string
+	outputFormat           string
+	rulesPackDirs          []string
 )
 
 var rulesCmd = &cobra.Command{
@@ -23,6 +26,13 @@ var rulesCmd = &cobra.Command{
 	Long:  "Commands for listing and inspecting detection rules",
 }
 
+var rulesPacksCmd = &cobra.Command{
+	Use:   "packs",
+	Short: "List available rule packs",
+	Long:  "Display the built-in rule packs, and any external packs given with --rule-pack-dir, with their versions and rule counts",
+	RunE:  runRulesPacks,
+}
+
 var rulesListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List available rules",
@@ -32,9 +42,15 @@ var rulesListCmd = &cobra.Command{
 
 func init() {
 	rulesCmd.AddCommand(rulesListCmd)
-	rulesListCmd.Flags().StringVar(&rulesPath, "rules", "", "Path to custom rules file or directory")
+	rulesCmd.AddCommand(rulesPacksCmd)
+	rulesPacksCmd.Flags().StringSliceVar(&rulesPackDirs, "rule-pack-dir", nil, "Directory containing an external rule pack (repeatable)")
+	rulesListCmd.Flags().StringVar(&rulesPath, "rules", "", "Path to custom rules file, or https:// URL of a rule pack .tar.gz")
+	rulesListCmd.Flags().StringVar(&remoteRulesSHA256, "rules-sha256", "", "Expected SHA-256 of a remote --rules pack")
+	rulesListCmd.Flags().StringVar(&remoteRulesPubKey, "rules-pubkey", "", "Ed25519 public key file used to verify a remote --rules pack's <url>.sig signature")
 	rulesListCmd.Flags().StringVar(&rulesInclude, "include", "", "Include rules matching regex pattern (comma-separated)")
 	rulesListCmd.Flags().StringVar(&rulesExclude, "exclude", "", "Exclude rules matching regex pattern (comma-separated)")
+	rulesListCmd.Flags().StringVar(&rulesCategories, "categories", "", "Only list rules with any of these categories or tags (comma-separated)")
+	rulesListCmd.Flags().StringVar(&rulesExcludeCategories, "exclude-categories", "", "Skip rules with any of these categories or tags (comma-separated)")
 	rulesListCmd.Flags().StringVar(&outputFormat, "format", "table", "Output format: table, json")
 }
 
@@ -46,12 +62,11 @@ func runRulesList(cmd *cobra.Command, args []string) error {
 
 	// Load rules (builtin or custom)
 	if rulesPath != "" {
-		// Custom rules from file
-		r, loadErr := loader.LoadRuleFile(rulesPath)
-		if loadErr != nil {
-			return fmt.Errorf("loading rules from %s: %w", rulesPath, loadErr)
+		// Custom rules from file or remote pack
+		rules, err = loadCustomRules(loader, rulesPath)
+		if err != nil {
+			return fmt.Errorf("loading rules from %s: %w", rulesPath, err)
 		}
-		rules = []*types.Rule{r}
 	} else {
 		// Builtin rules
 		rules, err = loader.LoadBuiltinRules()
@@ -60,16 +75,10 @@ func runRulesList(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Apply filtering if patterns specified
-	if rulesInclude != "" || rulesExclude != "" {
-		config := rule.FilterConfig{
-			Include: rule.ParsePatterns(rulesInclude),
-			Exclude: rule.ParsePatterns(rulesExclude),
-		}
-		rules, err = rule.Filter(rules, config)
-		if err != nil {
-			return fmt.Errorf("filtering rules: %w", err)
-		}
+	// Apply filtering
+	rules, err = rule.Filter(rules, ruleFilter(rulesInclude, rulesExclude, rulesCategories, rulesExcludeCategories))
+	if err != nil {
+		return fmt.Errorf("filtering rules: %w", err)
 	}
 
 	// Output based on format
@@ -83,6 +92,39 @@ func runRulesList(cmd *cobra.Command, args []string) error {
 	}
 }
 
+func runRulesPacks(cmd *cobra.Command, args []string) error {
+	loader := rule.NewLoader()
+	builtin, err := loader.LoadBuiltinRules()
+	if err != nil {
+		return fmt.Errorf("loading builtin rules: %w", err)
+	}
+	packs, err := loader.LoadBuiltinPacks()
+	if err != nil {
+		return fmt.Errorf("loading rule packs: %w", err)
+	}
+	for _, dir := range rulesPackDirs {
+		p, err := loader.LoadPackDir(dir)
+		if err != nil {
+			return fmt.Errorf("loading rule pack %s: %w", dir, err)
+		}
+		packs = append(packs, p)
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintf(w, "Name\tVersion\tRules\tSource\tDescription\n")
+	fmt.Fprintf(w, "----\t-------\t-----\t------\t-----------\n")
+	for _, p := range packs {
+		source := "builtin"
+		if p.Dir != "" {
+			source = p.Dir
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", p.Name, p.Version, len(p.Select(builtin)), source, p.Description)
+	}
+	return nil
+}
+
 // =============================================================================
 // HELPERS
 // =============================================================================
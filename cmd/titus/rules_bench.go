@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/praetorian-inc/titus/pkg/matcher"
+	"github.com/praetorian-inc/titus/pkg/rule/bench"
+	"github.com/spf13/cobra"
+)
+
+var (
+	benchCorpus            string
+	benchRulesPath         string
+	benchRulesInclude      string
+	benchRulesExclude      string
+	benchCategories        string
+	benchExcludeCategories string
+	benchRuleset           string
+	benchRulePacks         string
+	benchRulePackDirs      []string
+	benchFormat            string
+	benchMinPrecision      float64
+	benchMinRecall         float64
+	benchMaxFP             int
+)
+
+var rulesBenchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Measure rule precision and recall against a labeled corpus",
+	Long: `Run the active rules over a labeled corpus and report precision, recall and
+per-rule false positive counts.
+
+The corpus directory contains positive/ (files that each hold a real secret)
+and negative/ (known-clean files). An optional labels.yml maps positive files
+to the rule IDs expected to fire on them:
+
+  positive/aws.env: [np.aws.2]
+
+Use --min-precision, --min-recall or --max-false-positives to fail with a
+non-zero exit code, e.g. as a regression gate in CI before rolling out rules.
+
+Examples:
+  titus rules bench --corpus ./corpus
+  titus rules bench --corpus ./corpus --rule-packs cloud --max-false-positives 0
+  titus rules bench --corpus ./corpus --rules new-rule.yml --format json`,
+	Args: cobra.NoArgs,
+	RunE: runRulesBench,
+}
+
+func init() {
+	rulesCmd.AddCommand(rulesBenchCmd)
+	rulesBenchCmd.Flags().StringVar(&benchCorpus, "corpus", "", "Corpus directory with positive/ and negative/ subdirectories (required)")
+	rulesBenchCmd.Flags().StringVar(&benchRulesPath, "rules", "", "Path to custom rules file, or https:// URL of a rule pack .tar.gz")
+	rulesBenchCmd.Flags().StringVar(&benchRulesInclude, "rules-include", "", "Include rules matching regex pattern (comma-separated)")
+	rulesBenchCmd.Flags().StringVar(&benchRulesExclude, "rules-exclude", "", "Exclude rules matching regex pattern (comma-separated)")
+	rulesBenchCmd.Flags().StringVar(&benchCategories, "categories", "", "Only use rules with any of these categories or tags (comma-separated)")
+	rulesBenchCmd.Flags().StringVar(&benchExcludeCategories, "exclude-categories", "", "Skip rules with any of these categories or tags (comma-separated)")
+	rulesBenchCmd.Flags().StringVar(&benchRuleset, "ruleset", "default", "Ruleset to use: default, np.assets, np.hashes, all (all = no filtering)")
+	rulesBenchCmd.Flags().StringVar(&benchRulePacks, "rule-packs", "", "Rule packs to use instead of --ruleset (comma-separated name or name@version)")
+	rulesBenchCmd.Flags().StringSliceVar(&benchRulePackDirs, "rule-pack-dir", nil, "Directory containing an external rule pack (repeatable)")
+	rulesBenchCmd.Flags().StringVar(&benchFormat, "format", "table", "Output format: table, json")
+	rulesBenchCmd.Flags().Float64Var(&benchMinPrecision, "min-precision", 0, "Fail if overall precision is below this value (0-1)")
+	rulesBenchCmd.Flags().Float64Var(&benchMinRecall, "min-recall", 0, "Fail if overall recall is below this value (0-1)")
+	rulesBenchCmd.Flags().IntVar(&benchMaxFP, "max-false-positives", -1, "Fail if more negative files than this are flagged (-1 = no limit)")
+	rulesBenchCmd.MarkFlagRequired("corpus")
+}
+
+func runRulesBench(cmd *cobra.Command, args []string) error {
+	corpus, err := bench.LoadCorpus(benchCorpus)
+	if err != nil {
+		return err
+	}
+
+	rules, err := loadRuleSelection(benchRulesPath, ruleFilter(benchRulesInclude, benchRulesExclude, benchCategories, benchExcludeCategories), benchRuleset, benchRulePacks, benchRulePackDirs)
+	if err != nil {
+		return fmt.Errorf("loading rules: %w", err)
+	}
+
+	m, err := matcher.New(matcher.Config{
+		Rules: rules,
+		WarnFunc: func(format string, args ...any) {
+			fmt.Fprintf(os.Stderr, format, args...)
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("creating matcher: %w", err)
+	}
+	defer m.Close()
+
+	report, err := bench.Run(m, corpus)
+	if err != nil {
+		return err
+	}
+
+	switch benchFormat {
+	case "json":
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	case "table":
+		printBenchReport(cmd, report, len(rules))
+	default:
+		return fmt.Errorf("unknown output format: %s", benchFormat)
+	}
+
+	return checkBenchThresholds(report)
+}
+
+// printBenchReport writes the summary, per-rule table and missed files.
+func printBenchReport(cmd *cobra.Command, r *bench.Report, ruleCount int) {
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Rules: %d  Files: %d positive, %d negative\n",
+		ruleCount, r.TruePositives+r.FalseNegatives, r.FalsePositives+r.TrueNegatives)
+	fmt.Fprintf(out, "Precision: %.3f  Recall: %.3f  (TP %d, FP %d, FN %d, TN %d)\n\n",
+		r.Precision(), r.Recall(), r.TruePositives, r.FalsePositives, r.FalseNegatives, r.TrueNegatives)
+
+	if len(r.Rules) > 0 {
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "ID\tName\tTP\tFP\tPrecision\n")
+		fmt.Fprintf(w, "--\t----\t--\t--\t---------\n")
+		for _, s := range r.Rules {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%.3f\n", s.RuleID, s.RuleName, s.TruePositives, s.FalsePositives, s.Precision())
+		}
+		w.Flush()
+	}
+
+	if len(r.Missed) > 0 {
+		fmt.Fprintf(out, "\nMissed (%d):\n", len(r.Missed))
+		for _, path := range r.Missed {
+			fmt.Fprintf(out, "  %s\n", path)
+		}
+	}
+}
+
+// checkBenchThresholds returns an error when the report falls short of the
+// configured regression thresholds.
+func checkBenchThresholds(r *bench.Report) error {
+	if r.Precision() < benchMinPrecision {
+		return fmt.Errorf("precision %.3f is below --min-precision %.3f", r.Precision(), benchMinPrecision)
+	}
+	if r.Recall() < benchMinRecall {
+		return fmt.Errorf("recall %.3f is below --min-recall %.3f", r.Recall(), benchMinRecall)
+	}
+	if benchMaxFP >= 0 && r.FalsePositives > benchMaxFP {
+		return fmt.Errorf("%d false positive files exceed --max-false-positives %d", r.FalsePositives, benchMaxFP)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/sbom"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// outputSBOM writes findings as a CycloneDX BOM or an SPDX document.
+// Matches should already have their locations attached.
+func outputSBOM(cmd *cobra.Command, format string, findings []*types.Finding, matches []*types.Match, ruleMap map[string]*types.Rule) error {
+	matchesByFinding := buildFindingMatchMap(findings, matches)
+
+	var doc interface {
+		AddFinding(f *types.Finding, rule *types.Rule, matches []*types.Match)
+		ToJSON() ([]byte, error)
+	}
+	switch format {
+	case "cyclonedx":
+		doc = sbom.NewCycloneDX(version, time.Now())
+	case "spdx":
+		doc = sbom.NewSPDX(version, time.Now())
+	default:
+		return fmt.Errorf("unknown SBOM format: %s", format)
+	}
+
+	for _, f := range findings {
+		doc.AddFinding(f, ruleMap[f.RuleID], matchesByFinding[f.ID])
+	}
+
+	jsonBytes, err := doc.ToJSON()
+	if err != nil {
+		return fmt.Errorf("serializing %s: %w", format, err)
+	}
+	if _, err := cmd.OutOrStdout().Write(jsonBytes); err != nil {
+		return fmt.Errorf("writing %s output: %w", format, err)
+	}
+	return nil
+}
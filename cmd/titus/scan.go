@@ -4,20 +4,28 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
-	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/praetorian-inc/titus/pkg/datastore"
 	"github.com/praetorian-inc/titus/pkg/enum"
+	"github.com/praetorian-inc/titus/pkg/filetype"
+	"github.com/praetorian-inc/titus/pkg/fpfilter"
 	"github.com/praetorian-inc/titus/pkg/matcher"
 	"github.com/praetorian-inc/titus/pkg/rule"
 	"github.com/praetorian-inc/titus/pkg/sarif"
+	"github.com/praetorian-inc/titus/pkg/siem"
 	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/style"
 	"github.com/praetorian-inc/titus/pkg/types"
 	"github.com/praetorian-inc/titus/pkg/validator"
 	"github.com/spf13/cobra"
@@ -44,65 +52,214 @@ var (
 	scanRulesPath           string
 	scanRulesInclude        string
 	scanRulesExclude        string
+	scanCategories          string
+	scanExcludeCategories   string
 	scanOutputPath          string
 	scanOutputFormat        string
 	scanGit                 bool
+	scanSince               string
+	scanAuthor              string
+	scanRefs                []string
+	scanBrowser             bool
+	scanProfile             string
+	scanPreset              string
+	scanEnv                 bool
+	scanFollow              bool
+	scanSample              bool
+	scanSampleFullSize      string
+	scanSampleChunks        int
+	scanSampleChunkSize     string
+	scanSampleBudget        string
+	scanSampleTime          time.Duration
+	scanSampleSeed          int64
+	scanSMBUser             string
+	scanSMBPassword         string
+	scanSMBDomain           string
+	scanSMBHash             string
+	scanIMAPUser            string
+	scanIMAPPassword        string
+	scanIMAPInsecure        bool
 	scanMaxFileSize         int64
 	scanContextLines        int
+	scanContextBytes        int
 	scanIncremental         bool
 	scanValidate            bool
 	scanValidateWorkers     int
+	scanCorrelateFiles      bool
+	scanValidateAggressive  bool
+	scanValidateAudit       string
+	scanValidateTimeouts    []string
+	scanValidateBreaker     int
+	scanValidateAWSPerms    bool
+	scanValidatorPlugins    string
+	scanEnumeratorPlugins   string
 	scanStoreBlobs          bool
 	scanExtractArchivesFlag extensionsValue
 	extractMaxSize          string
 	extractMaxTotal         string
 	extractMaxDepth         int
+	extractMaxEntries       int
+	extractMaxRatio         int
 	scanSQLiteRowLimit      int
+	scanSQLiteTables        []string
+	scanDocPasswords        []string
+	scanBinaryAsText        bool
 	scanWorkers             int
 	scanRuleset             string
 	scanIgnoreFile          string
+	scanSIEMTarget          string
+	scanSIEMFormat          string
+	scanWorkDir             string
+	scanKeepClone           bool
+	scanSubmodules          bool
+	scanCloneRetries        int
+	scanNoTokenDiscovery    bool
+	scanDedupe              string
+	scanRulePacks           string
+	scanRulePackDirs        []string
+	scanOwners              bool
+	scanStatsFile           string
+	scanListOnly            bool
+	scanDashboard           bool
+	scanConfigPath          string
+	scanSigning             signingFlags
 )
 
 var scanCmd = &cobra.Command{
 	Use:   "scan <target>",
 	Short: "Scan a target for secrets",
-	Long:  "Scan a file, directory, git repository, or remote GitHub/GitLab repository for secrets using detection rules.\nSupports github.com/org/repo and gitlab.com/namespace/project URLs for direct remote scanning.",
-	Args:  cobra.ExactArgs(1),
+	Long:  "Scan a file, directory, git repository, or remote GitHub/GitLab repository for secrets using detection rules.\nSupports github.com/org/repo and gitlab.com/namespace/project URLs for direct remote scanning.\nWith --follow, tails one or more log files (paths or glob patterns) and prints matches as JSON lines.",
+	Args:  scanArgs,
 	RunE:  runScan,
 }
 
 func init() {
-	scanCmd.Flags().StringVar(&scanRulesPath, "rules", "", "Path to custom rules file or directory")
+	scanCmd.Flags().StringVar(&scanRulesPath, "rules", "", "Path to custom rules file, or https:// URL of a rule pack .tar.gz")
+	scanCmd.Flags().StringVar(&remoteRulesSHA256, "rules-sha256", "", "Expected SHA-256 of a remote --rules pack (pins the version and allows offline use of the cache)")
+	scanCmd.Flags().StringVar(&remoteRulesPubKey, "rules-pubkey", "", "Ed25519 public key file used to verify a remote --rules pack's <url>.sig signature")
 	scanCmd.Flags().StringVar(&scanRulesInclude, "rules-include", "", "Include rules matching regex pattern (comma-separated)")
 	scanCmd.Flags().StringVar(&scanRulesExclude, "rules-exclude", "", "Exclude rules matching regex pattern (comma-separated)")
-	scanCmd.Flags().StringVar(&scanRuleset, "ruleset", "default", "Ruleset to use: default, np.assets, np.hashes, all (all = no filtering)")
+	scanCmd.Flags().StringVar(&scanCategories, "categories", "", "Only use rules with any of these categories or tags (comma-separated)")
+	scanCmd.Flags().StringVar(&scanExcludeCategories, "exclude-categories", "", "Skip rules with any of these categories or tags (comma-separated)")
+	scanCmd.Flags().StringVar(&scanRuleset, "ruleset", "default", "Ruleset to use: default, memory, np.assets, np.hashes, all (all = no filtering)")
+	scanCmd.Flags().StringVar(&scanRulePacks, "rule-packs", "", "Rule packs to use instead of --ruleset (comma-separated name or name@version, e.g. cloud,ci-cd)")
+	scanCmd.Flags().StringSliceVar(&scanRulePackDirs, "rule-pack-dir", nil, "Directory containing an external rule pack (pack.yml plus rule files; repeatable)")
 	scanCmd.Flags().StringVar(&scanOutputPath, "output", "titus.ds", "Output datastore path (:memory: for in-memory, :auto: to derive from target name)")
-	scanCmd.Flags().StringVar(&scanOutputFormat, "format", "human", "Output format: json, sarif, human")
+	scanCmd.Flags().StringVar(&scanOutputFormat, "format", "human", "Output format: json, sarif, gitlab, human, github-summary")
+	scanSigning.addFlags(scanCmd)
+	scanCmd.RunE = scanSigning.wrap(runScan, &scanOutputFormat, &scanOutputPath)
 	scanCmd.Flags().BoolVar(&scanGit, "git", false, "Treat target as git repository (enumerate git history)")
+	scanCmd.Flags().StringVar(&scanSince, "since", "", "With --git, only scan blobs of commits authored on or after this date (2023-01-01 or RFC 3339)")
+	scanCmd.Flags().StringVar(&scanAuthor, "author", "", "With --git, only scan blobs of commits whose author name or email matches this regular expression")
+	scanCmd.Flags().StringSliceVar(&scanRefs, "ref", nil, "With --git, only walk the history of these branches, tags or commits (default: all refs, reflogs and dangling commits)")
+	scanCmd.Flags().BoolVar(&scanBrowser, "browser", false, "Treat target as a Chrome or Firefox profile directory (scan saved logins, cookies and localStorage)")
+	scanCmd.Flags().StringVar(&scanProfile, "profile", "", "Scan profile: memory (treat target as raw memory dumps: scan in overlapping chunks with the memory ruleset)")
+	scanCmd.Flags().StringVar(&scanPreset, "preset", "", "Scan a preset of well-known locations under the home directory given as target (default: your own): dev-caches (package manager, build tool and cloud CLI credential files and caches, with the dev-caches ruleset)")
+	scanCmd.Flags().BoolVar(&scanEnv, "env", false, "Scan the environment variables of this process (names and values) and the CI metadata files they point to, instead of a target")
+	scanCmd.Flags().BoolVar(&scanFollow, "follow", false, "Tail the target log files like tail -F (paths or quoted glob patterns), scanning appended lines and printing matches as JSONL")
+	scanCmd.Flags().BoolVar(&scanSample, "sample", false, "Sample a target too large to scan in full: likely credential and config files first, small files in full, random chunks of large files")
+	scanCmd.Flags().StringVar(&scanSampleFullSize, "sample-full-size", "1MB", "With --sample, scan files up to this size in full")
+	scanCmd.Flags().IntVar(&scanSampleChunks, "sample-chunks", 8, "With --sample, number of chunks to sample from each larger file")
+	scanCmd.Flags().StringVar(&scanSampleChunkSize, "sample-chunk-size", "64KB", "With --sample, size of each sampled chunk")
+	scanCmd.Flags().StringVar(&scanSampleBudget, "sample-budget", "", "With --sample, stop after reading this much data (e.g. 50GB; empty = no limit)")
+	scanCmd.Flags().DurationVar(&scanSampleTime, "sample-time", 0, "With --sample, stop reading new files after this long (e.g. 2h; 0 = no limit)")
+	scanCmd.Flags().Int64Var(&scanSampleSeed, "sample-seed", 1, "With --sample, seed for choosing chunk offsets")
+	scanCmd.Flags().StringVar(&scanSMBUser, "smb-user", "", "User for smb:// targets (default: $SMB_USERNAME, else from the URL)")
+	scanCmd.Flags().StringVar(&scanSMBPassword, "smb-password", "", "Password for smb:// targets (default: $SMB_PASSWORD, else from the URL)")
+	scanCmd.Flags().StringVar(&scanSMBDomain, "smb-domain", "", "NTLM domain for smb:// targets (default: $SMB_DOMAIN, else from the URL)")
+	scanCmd.Flags().StringVar(&scanSMBHash, "smb-hash", "", "Hex NT hash to authenticate to smb:// targets with instead of a password (default: $SMB_NT_HASH)")
+	scanCmd.Flags().StringVar(&scanIMAPUser, "imap-user", "", "User for imap:// and imaps:// targets (default: $IMAP_USERNAME, else from the URL)")
+	scanCmd.Flags().StringVar(&scanIMAPPassword, "imap-password", "", "Password for imap:// and imaps:// targets (default: $IMAP_PASSWORD, else from the URL)")
+	scanCmd.Flags().BoolVar(&scanIMAPInsecure, "imap-insecure", false, "Log in to imap:// servers that don't offer STARTTLS, sending credentials in plaintext")
+	scanCmd.Flags().StringVar(&scanEnumeratorPlugins, "enumerator-plugins", "", "directory of external enumerator executables for custom scheme:// targets (default: <user config dir>/titus/enumerators)")
 	scanCmd.Flags().Int64Var(&scanMaxFileSize, "max-file-size", 10*1024*1024, "Maximum file size to scan (bytes)")
 	scanCmd.Flags().IntVar(&scanContextLines, "context-lines", 3, "Lines of context before/after matches (0 to disable)")
-	scanCmd.Flags().BoolVar(&scanIncremental, "incremental", false, "Skip already-scanned blobs")
+	scanCmd.Flags().IntVar(&scanContextBytes, "context-bytes", 0, "Max bytes of context before/after matches; used alone, keeps a byte window around each match (0 = no limit)")
+	scanCmd.Flags().BoolVar(&scanIncremental, "incremental", false, "Skip already-scanned blobs, and with --git commits scanned by an earlier scan")
+	scanCmd.Flags().StringVar(&scanDedupe, "dedupe", dedupeGlobal, "Where a blob found in several places is recorded: global (first place only), per-target (every place, matched once) or none (every place, matched each time)")
 	scanCmd.Flags().BoolVar(&scanValidate, "validate", false, "validate detected secrets against their source APIs")
 	scanCmd.Flags().IntVar(&scanValidateWorkers, "validate-workers", 4, "number of concurrent validation workers")
+	scanCmd.Flags().StringSliceVar(&scanValidateTimeouts, "validate-timeout", nil, "Time limit for each validation, and per validator as name=duration (e.g. 10s,aws=30s; default 30s, or the validator's own)")
+	scanCmd.Flags().IntVar(&scanValidateBreaker, "validate-breaker", validator.DefaultBreakerFailures, "Skip a validator's endpoint, marking its matches undetermined, after this many consecutive network failures or timeouts against it (0 = never)")
+	scanCmd.Flags().BoolVar(&scanValidateAWSPerms, "validate-aws-permissions", false, "With --validate, probe what live AWS keys may do with read-only calls (iam:GetUser, iam:ListAccountAliases, s3:ListBuckets, sts:GetSessionToken) and summarize them in the result")
+	scanCmd.Flags().StringVar(&scanValidateAudit, "validate-audit", "", "Append a JSON line per outbound validation request (time, rule, validator, host, result; no secrets) to this file")
+	scanCmd.Flags().BoolVar(&scanValidateAggressive, "validate-aggressive", false, "like --validate, plus validators that log in to hosts found near a secret (SSH public-key auth with discovered private keys); only use where authorized")
+	scanCmd.Flags().StringVar(&scanValidatorPlugins, "validator-plugins", "", "directory of external validator executables (default: <user config dir>/titus/validators)")
+	scanCmd.Flags().BoolVar(&scanCorrelateFiles, "correlate-files", false, "with --validate, pair credential parts found in different files of the same directory or repository")
 	scanCmd.Flags().BoolVar(&scanStoreBlobs, "store-blobs", false, "Store file contents in blobs/ directory")
 	scanCmd.Flags().Var(&scanExtractArchivesFlag, "extract", "Extract text from binary files (extensions: xlsx,docx,pdf,zip or 'all')")
 	scanCmd.Flags().StringVar(&extractMaxSize, "extract-max-size", "10MB", "Max uncompressed size per extracted file")
 	scanCmd.Flags().StringVar(&extractMaxTotal, "extract-max-total", "100MB", "Max total bytes to extract from one archive")
 	scanCmd.Flags().IntVar(&extractMaxDepth, "extract-max-depth", 5, "Max nested archive depth")
+	scanCmd.Flags().IntVar(&extractMaxEntries, "extract-max-entries", 10000, "Max members to extract from one file, nested archives included (0 for unlimited)")
+	scanCmd.Flags().IntVar(&extractMaxRatio, "extract-max-ratio", 100, "Skip archive members that inflate to over this many times their compressed size, from 1MB (0 for unlimited)")
+	scanCmd.Flags().IntVar(&scanSQLiteRowLimit, "extract-sqlite-rows", 1000, "Max rows per table for SQLite extraction (0 for unlimited)")
 	scanCmd.Flags().IntVar(&scanSQLiteRowLimit, "sqlite-row-limit", 1000, "Max rows per table for SQLite extraction (0 for unlimited)")
+	scanCmd.Flags().MarkDeprecated("sqlite-row-limit", "use --extract-sqlite-rows")
+	scanCmd.Flags().StringSliceVar(&scanSQLiteTables, "extract-sqlite-tables", nil, "SQLite tables to extract, as TABLE or TABLE.COLUMN globs; prefix ! to exclude (e.g. credentials,*.*token*,!audit_log)")
+	scanCmd.Flags().StringSliceVar(&scanDocPasswords, "doc-passwords", nil, "Passwords to try on encrypted docx, xlsx, pptx, odt, ods and odp files (comma-separated)")
+	scanCmd.Flags().BoolVar(&scanBinaryAsText, "treat-binary-as-text", false, "Scan binary files and archive members as they are instead of skipping them (forensics)")
 	scanCmd.Flags().IntVar(&scanWorkers, "workers", runtime.NumCPU(), "Number of parallel scan workers")
 	scanCmd.Flags().StringVar(&scanIgnoreFile, "ignore", "", "Path to gitignore-style ignore file (replaces built-in defaults; use /dev/null to disable)")
+	scanCmd.Flags().StringVar(&scanSIEMTarget, "siem", "", "Stream match events to a SIEM: udp://host:port, tcp://host:port (syslog) or a file path")
+	scanCmd.Flags().StringVar(&scanSIEMFormat, "siem-format", "cef", "SIEM event format: cef, leef, ocsf, ecs")
+	scanCmd.Flags().StringVar(&scanWorkDir, "work-dir", "", "Directory for temporary clones of remote repositories (default: system temp dir)")
+	scanCmd.Flags().BoolVar(&scanKeepClone, "keep-clone", false, "Keep temporary clones of remote repositories after scanning (for debugging)")
+	scanCmd.Flags().BoolVar(&scanSubmodules, "submodules", false, "Clone the submodules of remote repositories too (nested repositories in local directories are found with --git)")
+	scanCmd.Flags().BoolVar(&scanNoTokenDiscovery, "no-token-discovery", false, "For GitHub and GitLab URLs, don't look for a token from the gh or glab CLI or git credential helpers")
+	scanCmd.Flags().IntVar(&scanCloneRetries, "clone-retries", enum.DefaultCloneRetries, "Times to retry a failed clone of a remote repository, with backoff")
+	scanCmd.Flags().BoolVar(&scanOwners, "owners", false, "With --format json, attribute matches to owners with git blame and CODEOWNERS")
+	scanCmd.Flags().StringVar(&scanStatsFile, "stats-file", "", "Write end-of-scan statistics (bytes, blobs, matches, timeouts, duration, per-rule counts) to this JSON file")
+	scanCmd.Flags().StringVar(&scanConfigPath, "config", "", "Scan config with per-path policies (default: titus.yaml in the target directory, if present)")
+	scanCmd.Flags().BoolVar(&scanDashboard, "dashboard", false, "Show live progress while scanning: top rules, recent findings, busy workers and validation queue (a status line every 10s when stderr isn't a terminal)")
+	scanCmd.Flags().BoolVar(&scanListOnly, "list-only", false, "Enumerate the target without matching: list what would be scanned with sizes, and what would be skipped and why")
 }
 
 // blobJob represents a unit of work for the worker pool.
 type blobJob struct {
-	content []byte
-	blobID  types.BlobID
-	prov    types.Provenance
+	content    []byte
+	blobID     types.BlobID
+	prov       types.Provenance
+	recordOnly bool // already matched; only record where it was found
+}
+
+// scanArgs accepts a single target, or any number of log files with --follow.
+// With --preset the target is optional, and --env takes none.
+func scanArgs(cmd *cobra.Command, args []string) error {
+	if scanFollow {
+		return cobra.MinimumNArgs(1)(cmd, args)
+	}
+	if scanEnv {
+		return cobra.NoArgs(cmd, args)
+	}
+	if scanPreset != "" {
+		return cobra.MaximumNArgs(1)(cmd, args)
+	}
+	return cobra.ExactArgs(1)(cmd, args)
 }
 
 func runScan(cmd *cobra.Command, args []string) error {
-	target := args[0]
+	if scanFollow {
+		if scanListOnly {
+			return fmt.Errorf("--list-only cannot be used with --follow")
+		}
+		return runFollow(cmd, args)
+	}
+	target, err := scanTarget(args)
+	if err != nil {
+		return err
+	}
+	if err := checkDedupeScope(scanDedupe, scanIncremental); err != nil {
+		return err
+	}
+
+	gitFilter, err := newGitHistoryFilter(scanSince, scanAuthor, scanRefs)
+	if err != nil {
+		return err
+	}
+	if gitFilter != nil && !scanGit {
+		return fmt.Errorf("--since, --author and --ref need --git")
+	}
 
 	if scanOutputPath == ":auto:" {
 		scanOutputPath = resolveAutoOutput(target)
@@ -110,20 +267,57 @@ func runScan(cmd *cobra.Command, args []string) error {
 
 	// Check if target is a GitHub or GitLab URL
 	if repoTarget, ok := parseRepoURL(target); ok {
-		return runRepoScan(cmd, repoTarget)
+		if scanListOnly {
+			return fmt.Errorf("--list-only is not supported for remote repository URLs")
+		}
+		return runRepoScan(cmd, repoTarget, gitFilter)
 	}
 
-	// Validate target exists (filesystem path)
-	if _, err := os.Stat(target); err != nil {
+	// Validate target exists (filesystem path); SMB shares, mailboxes and
+	// custom scheme:// targets are read remotely
+	if err := loadEnumeratorPlugins(target, scanEnumeratorPlugins); err != nil {
+		return err
+	}
+	if remoteTarget(target) {
+		if scanGit || scanBrowser || scanProfile != "" || scanPreset != "" || scanSample || scanConfigPath != "" {
+			return fmt.Errorf("remote targets cannot be used with --git, --browser, --profile, --preset, --sample or --config")
+		}
+	} else if _, err := os.Stat(target); err != nil {
 		return fmt.Errorf("target does not exist: %s", target)
 	}
+	if scanBrowser && scanGit {
+		return fmt.Errorf("--browser and --git cannot be used together")
+	}
+	if scanEnv && (scanGit || scanBrowser || scanProfile != "" || scanPreset != "" || scanSample) {
+		return fmt.Errorf("--env cannot be used with --git, --browser, --profile, --preset or --sample")
+	}
+	if err := applyScanProfile(cmd); err != nil {
+		return err
+	}
+	if err := applyScanPreset(cmd); err != nil {
+		return err
+	}
+	if scanSample && (scanGit || scanBrowser || scanProfile != "") {
+		return fmt.Errorf("--sample cannot be used with --git, --browser or --profile")
+	}
+	if scanListOnly {
+		return runListOnly(cmd, target)
+	}
 
 	// Load rules
-	rules, err := loadRules(scanRulesPath, scanRulesInclude, scanRulesExclude, scanRuleset)
+	rules, err := loadRuleSelection(scanRulesPath, ruleFilter(scanRulesInclude, scanRulesExclude, scanCategories, scanExcludeCategories), scanRuleset, scanRulePacks, scanRulePackDirs)
 	if err != nil {
 		return fmt.Errorf("loading rules: %w", err)
 	}
 
+	// Load per-path policies, which may add rules for some paths
+	var policies *scanPolicies
+	if !remoteTarget(target) {
+		if policies, rules, err = loadScanPolicies(target, rules); err != nil {
+			return err
+		}
+	}
+
 	// Create rule map for finding ID computation
 	ruleMap := make(map[string]*types.Rule)
 	for _, r := range rules {
@@ -131,18 +325,28 @@ func runScan(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create matcher
+	var counters scanCounters
 	m, err := matcher.New(matcher.Config{
-		Rules:        rules,
-		ContextLines: scanContextLines,
-		WarnFunc: func(format string, args ...any) {
+		Rules:                     rules,
+		ContextLines:              scanContextLines,
+		ContextBytes:              scanContextBytes,
+		KeepCorrelationComponents: crossFileCorrelationEnabled(),
+		WarnFunc: countTimeouts(func(format string, args ...any) {
 			fmt.Fprintf(os.Stderr, format, args...)
-		},
+		}, &counters.timeouts),
 	})
 	if err != nil {
 		return fmt.Errorf("creating matcher: %w", err)
 	}
 	defer m.Close()
 
+	// Filename rules are matched against paths as the enumerator finds them
+	filenames, err := matcher.NewFilenameMatcher(rules)
+	if err != nil {
+		return fmt.Errorf("creating matcher: %w", err)
+	}
+	paths := newPathMatches(filenames, policies)
+
 	// Create store (memory or datastore)
 	s, ds, err := openScanStore(scanOutputPath, scanStoreBlobs)
 	if err != nil {
@@ -161,58 +365,106 @@ func runScan(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if _, err := resolveFingerprint(s, scanFingerprintVersion); err != nil {
+		return err
+	}
+
+	run, err := beginScan(s, target, rules)
+	if err != nil {
+		return err
+	}
+
 	// Initialize validation engine (nil if validation disabled)
-	validationEngine := initValidationEngine()
+	validationEngine, err := initValidationEngine()
+	if err != nil {
+		return err
+	}
+	audit, err := openValidationAudit(scanValidateAudit, validationEngine)
+	if err != nil {
+		return err
+	}
+	if audit != nil {
+		defer closeValidationAudit(audit)
+	}
+
+	// Open SIEM sink (nil if --siem not set)
+	siemSink, err := openSIEMSink(scanSIEMTarget, scanSIEMFormat)
+	if err != nil {
+		return err
+	}
+	if siemSink != nil {
+		defer siemSink.Close()
+	}
 
 	// Wire validator awareness into the matcher's built-in deduplicator
 	if validationEngine != nil {
 		matcher.SetCanValidate(m, validationEngine.CanValidate)
 	}
 
-	// Create enumerator
-	enumerator, err := createEnumerator(target, scanGit)
+	// Create enumerator; with --git --incremental, only commits added since
+	// the last scan are walked
+	hooks := paths.hooks()
+	hooks.onSkip = counters.onSkip(cmd.ErrOrStderr())
+	var marks *gitHighWaterMarks
+	if scanGit && scanIncremental {
+		marks = newGitHighWaterMarks(s)
+		marks.readOnly = gitFilter != nil
+		hooks.scannedCommits = marks.scanned
+	}
+	enumerator, err := createEnumerator(target, scanGit, policies, hooks)
 	if err != nil {
 		return fmt.Errorf("creating enumerator: %w", err)
 	}
 
-	// Scan with parallel workers
-	ctx := context.Background()
-	var matchCount atomic.Int64
-	var findingCount atomic.Int64
-	var skippedCount atomic.Int64
-	var totalBytes atomic.Int64
-	var blobCount atomic.Int64
+	// Scan with parallel workers; the dashboard can stop the scan
+	ctx, cancelScan := context.WithCancel(context.Background())
+	defer cancelScan()
 	startTime := time.Now()
+	lifecycle := newScanLifecycle(scopeTarget(target), scanGit)
+	lifecycle.partial = scanSample || gitFilter != nil || marks != nil && marks.partial()
+	dedupe := newBlobDedupe(scanDedupe, s, scanIncremental)
 
 	numWorkers := scanWorkers
 	if numWorkers < 1 {
 		numWorkers = 1
 	}
 	jobs := make(chan blobJob, 2*numWorkers)
+	progress := newScanProgress(cmd.ErrOrStderr())
+	var dashboard *progressDashboard
+	if scanDashboard {
+		dashboard = startProgressDashboard(cmd.ErrOrStderr(), progress, ruleMap, validationEngine, numWorkers, cancelScan)
+	}
 
+	// The group context is cancelled once Wait returns; keep the parent for post-scan work
+	baseCtx := ctx
 	g, ctx := errgroup.WithContext(ctx)
 
 	// Producer: enumerate blobs and send to workers (NO DB writes)
 	g.Go(func() error {
 		defer close(jobs)
 		return enumerator.Enumerate(ctx, func(content []byte, blobID types.BlobID, prov types.Provenance) error {
-			totalBytes.Add(int64(len(content)))
-			blobCount.Add(1)
-
-			// Check for incremental scanning
-			if scanIncremental {
-				exists, err := s.BlobExists(blobID)
-				if err != nil {
-					return fmt.Errorf("checking blob: %w", err)
-				}
-				if exists {
-					skippedCount.Add(1)
-					return nil
-				}
+			// Leave out git history outside --since and --author
+			if gitFilter != nil && !gitFilter.keepBlob(prov) {
+				return nil
+			}
+			counters.bytes.Add(int64(len(content)))
+			counters.blobs.Add(1)
+			lifecycle.see(blobID)
+
+			// Skip or only record blobs already scanned, per --dedupe and --incremental
+			scan, recordOnly, err := dedupe.check(blobID)
+			if err != nil {
+				return err
+			}
+			if !scan || recordOnly {
+				counters.skippedBlobs.Add(1)
+			}
+			if !scan {
+				return nil
 			}
 
 			select {
-			case jobs <- blobJob{content: content, blobID: blobID, prov: prov}:
+			case jobs <- blobJob{content: content, blobID: blobID, prov: prov, recordOnly: recordOnly}:
 				return nil
 			case <-ctx.Done():
 				return ctx.Err()
@@ -238,36 +490,17 @@ func runScan(cmd *cobra.Command, args []string) error {
 				}
 				err := s.ExecBatch(func(tx store.Store) error {
 					for _, item := range batch {
-						if err := tx.AddBlob(item.blobID, item.size); err != nil {
-							return fmt.Errorf("storing blob: %w", err)
+						fresh, err := countNewMatches(tx, ruleMap, item.blobID, item.matches)
+						if err != nil {
+							return err
 						}
-						if err := tx.AddProvenance(item.blobID, item.prov); err != nil {
-							return fmt.Errorf("storing provenance: %w", err)
-						}
-						for _, match := range item.matches {
-							if err := tx.AddMatch(match); err != nil {
-								return fmt.Errorf("storing match: %w", err)
-							}
-							rule, ok := ruleMap[match.RuleID]
-							if !ok {
-								return fmt.Errorf("rule not found: %s", match.RuleID)
-							}
-							findingID := types.ComputeFindingID(rule.StructuralID, match.Groups)
-							exists, err := tx.FindingExists(findingID)
-							if err != nil {
-								return fmt.Errorf("checking finding: %w", err)
-							}
-							if !exists {
-								findingCount.Add(1)
-								if err := tx.AddFinding(&types.Finding{
-									ID:     findingID,
-									RuleID: match.RuleID,
-									Groups: match.Groups,
-								}); err != nil {
-									return fmt.Errorf("storing finding: %w", err)
-								}
-							}
+						created, err := recordBlob(tx, ruleMap, item.blobID, item.prov, item.size, item.matches)
+						if err != nil {
+							return err
 						}
+						counters.newMatches.Add(fresh)
+						counters.newFindings.Add(int64(len(created)))
+						progress.found(item.prov, created)
 					}
 					return nil
 				})
@@ -276,31 +509,35 @@ func runScan(cmd *cobra.Command, args []string) error {
 			}
 
 			for job := range jobs {
-				matches, err := m.MatchWithBlobID(job.content, job.blobID)
-				if err != nil {
-					// Log warning but continue scanning other files
-					fmt.Fprintf(os.Stderr, "[warn] match error (skipping blob %s): %v\n", job.blobID.Hex(), err)
-					continue
+				progress.working(1)
+				var matches []*types.Match
+				if !job.recordOnly {
+					var err error
+					matches, err = m.MatchWithBlobID(job.content, job.blobID)
+					if err != nil {
+						// Log warning but continue scanning other files
+						fmt.Fprintf(os.Stderr, "[warn] match error (skipping blob %s): %v\n", job.blobID.Hex(), err)
+						progress.working(-1)
+						continue
+					}
 				}
 
-				for _, match := range matches {
-					startLine, startCol := types.ComputeLineColumn(job.content, int(match.Location.Offset.Start))
-					endLine, endCol := types.ComputeLineColumn(job.content, int(match.Location.Offset.End))
-					match.Location.Source.Start.Line = startLine
-					match.Location.Source.Start.Column = startCol
-					match.Location.Source.End.Line = endLine
-					match.Location.Source.End.Column = endCol
-				}
+				matches = policies.filter(job.prov, matches)
+				setLineColumns(job.content, matches)
 
-				validateMatches(ctx, validationEngine, matches, verbose)
-				matchCount.Add(int64(len(matches)))
+				reported := reportableMatches(matches, ruleMap)
+				progress.blob(job.blobID, job.prov, len(job.content), reported)
+				validateMatches(ctx, validationEngine, reported, verboseAt(verboseTrace))
+				emitSIEMEvents(siemSink, reported, job.prov)
+				counters.matches.Add(int64(len(reported)))
 
 				batch = append(batch, batchItem{
 					blobID:  job.blobID,
-					prov:    job.prov,
+					prov:    filetype.Tag(job.prov, job.content),
 					size:    int64(len(job.content)),
 					matches: matches,
 				})
+				progress.working(-1)
 				if len(batch) >= batchSize {
 					if err := flush(); err != nil {
 						return err
@@ -311,13 +548,59 @@ func runScan(cmd *cobra.Command, args []string) error {
 		})
 	}
 
-	if err := g.Wait(); err != nil {
+	err = g.Wait()
+	dashboard.Stop()
+	if err != nil {
 		return fmt.Errorf("scanning: %w", err)
 	}
 
-	duration := time.Since(startTime)
-	printScanStats(cmd, scanOutputFormat, scanOutputPath,
-		totalBytes.Load(), blobCount.Load(), matchCount.Load(), skippedCount.Load(), duration)
+	recorded, err := paths.record(s, ruleMap, func(ms []*types.Match, prov types.Provenance) {
+		progress.blob(ms[0].BlobID, prov, 0, ms)
+		emitSIEMEvents(siemSink, ms, prov)
+	})
+	if err != nil {
+		return fmt.Errorf("recording filename matches: %w", err)
+	}
+	for _, blobID := range recorded.blobIDs {
+		lifecycle.see(blobID)
+	}
+	counters.matches.Add(recorded.matches)
+	counters.newMatches.Add(recorded.newMatches)
+	counters.newFindings.Add(recorded.findings)
+
+	if crossFileCorrelationEnabled() {
+		if err := correlateAcrossBlobs(baseCtx, cmd, s, rules, ruleMap, validationEngine); err != nil {
+			return err
+		}
+	}
+
+	// Update finding lifecycle states from what this scan saw
+	if _, remediated, err := lifecycle.update(s, ruleMap); err != nil {
+		return fmt.Errorf("updating finding states: %w", err)
+	} else if remediated > 0 && scanOutputFormat == "human" {
+		statusf(cmd.ErrOrStderr(), "%d finding(s) no longer present, marked remediated\n", remediated)
+	}
+
+	if marks != nil {
+		if err := marks.commit(); err != nil {
+			return err
+		}
+	}
+	if err := finishScan(s, run); err != nil {
+		return err
+	}
+	progress.printRuleStats(ruleMap)
+	stats := counters.stats(run.Target, startTime, time.Since(startTime), progress.ruleCounts())
+	printScanStats(cmd, scanOutputFormat, scanOutputPath, stats)
+	if scanStatsFile != "" {
+		err := writeStatsFile(scanStatsFile, stats)
+		if err != nil {
+			return err
+		}
+	}
+	if sampler, ok := enumerator.(*enum.SamplingEnumerator); ok {
+		printSampleCoverage(cmd, scanOutputFormat, sampler.Coverage())
+	}
 
 	return outputScanResults(cmd, s, rules, ruleMap)
 }
@@ -326,57 +609,218 @@ func runScan(cmd *cobra.Command, args []string) error {
 // HELPERS
 // =============================================================================
 
+// setLineColumns fills in line/column positions for matches from their byte offsets.
+func setLineColumns(content []byte, matches []*types.Match) {
+	if len(matches) == 0 {
+		return
+	}
+	lines := types.NewLineIndex(content)
+	for _, match := range matches {
+		startLine, startCol := lines.LineColumn(int(match.Location.Offset.Start))
+		endLine, endCol := lines.LineColumn(int(match.Location.Offset.End))
+		match.Location.Source.Start.Line = startLine
+		match.Location.Source.Start.Column = startCol
+		match.Location.Source.End.Line = endLine
+		match.Location.Source.End.Column = endCol
+	}
+}
+
+// recordBlob persists a scanned blob with its provenance and matches, creating
+// findings that are not already in the store. It returns the newly created findings.
+func recordBlob(tx store.Store, ruleMap map[string]*types.Rule, blobID types.BlobID, prov types.Provenance, size int64, matches []*types.Match) ([]*types.Finding, error) {
+	if err := tx.AddBlob(blobID, size); err != nil {
+		return nil, fmt.Errorf("storing blob: %w", err)
+	}
+	if err := tx.AddProvenance(blobID, prov); err != nil {
+		return nil, fmt.Errorf("storing provenance: %w", err)
+	}
+
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	fp, err := datastoreFingerprint(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var created []*types.Finding
+	for _, match := range matches {
+		if err := tx.AddMatch(match); err != nil {
+			return nil, fmt.Errorf("storing match: %w", err)
+		}
+		rule, ok := ruleMap[match.RuleID]
+		if !ok {
+			return nil, fmt.Errorf("rule not found: %s", match.RuleID)
+		}
+		if rule.CorrelationOnly {
+			// Kept for cross-file correlation; not a finding on its own
+			continue
+		}
+		findingID := fp.FindingID(rule, match.Groups)
+		match.FindingID = findingID
+		exists, err := tx.FindingExists(findingID)
+		if err != nil {
+			return nil, fmt.Errorf("checking finding: %w", err)
+		}
+		if !exists {
+			f := &types.Finding{
+				ID:      findingID,
+				RuleID:  match.RuleID,
+				Groups:  match.Groups,
+				Matches: []*types.Match{match},
+			}
+			if err := tx.AddFinding(f); err != nil {
+				return nil, fmt.Errorf("storing finding: %w", err)
+			}
+			created = append(created, f)
+		}
+	}
+	return created, nil
+}
+
 func loadRules(path, include, exclude, rulesetID string) ([]*types.Rule, error) {
-	loader := rule.NewLoader()
+	return loadRuleSelection(path, ruleFilter(include, exclude, "", ""), rulesetID, "", nil)
+}
 
-	var rules []*types.Rule
-	var err error
+// ruleFilter builds the rule filter for the comma-separated --rules-include,
+// --rules-exclude, --categories and --exclude-categories values.
+func ruleFilter(include, exclude, categories, excludeCategories string) rule.FilterConfig {
+	return rule.FilterConfig{
+		Include:           rule.ParsePatterns(include),
+		Exclude:           rule.ParsePatterns(exclude),
+		Categories:        rule.ParsePatterns(categories),
+		ExcludeCategories: rule.ParsePatterns(excludeCategories),
+	}
+}
 
+// rulesetRules returns the rules from --rules if given, and otherwise the
+// builtin rules in the named ruleset ("all" for every builtin rule).
+func rulesetRules(loader *rule.Loader, builtin []*types.Rule, path, rulesetID string) ([]*types.Rule, error) {
 	if path != "" {
-		// Custom rules from file — skip ruleset filtering
+		// Custom rules from file or remote pack — skip ruleset filtering
+		return loadCustomRules(loader, path)
+	}
+	if rulesetID == "all" {
+		return builtin, nil
+	}
+	rulesets, err := loader.LoadBuiltinRulesets()
+	if err != nil {
+		return nil, fmt.Errorf("loading rulesets: %w", err)
+	}
+	rs := rule.FindRuleset(rulesets, rulesetID)
+	if rs == nil {
+		available := make([]string, len(rulesets))
+		for i, r := range rulesets {
+			available[i] = r.ID
+		}
+		return nil, fmt.Errorf("unknown ruleset %q (available: %s, all)", rulesetID, strings.Join(available, ", "))
+	}
+	return rule.ApplyRuleset(builtin, rs), nil
+}
+
+// Verification settings for a remote --rules pack, shared by every command
+// that accepts --rules.
+var (
+	remoteRulesSHA256 string
+	remoteRulesPubKey string
+)
+
+// loadCustomRules loads the rules named by --rules: a single rule file, or a
+// remote rule pack archive when path is an http(s) URL.
+func loadCustomRules(loader *rule.Loader, path string) ([]*types.Rule, error) {
+	if !rule.IsRemote(path) {
 		r, err := loader.LoadRuleFile(path)
 		if err != nil {
 			return nil, err
 		}
-		rules = []*types.Rule{r}
-	} else {
-		// Builtin rules
-		rules, err = loader.LoadBuiltinRules()
+		return []*types.Rule{r}, nil
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	src := &rule.RemoteSource{
+		URL:      path,
+		SHA256:   remoteRulesSHA256,
+		CacheDir: filepath.Join(cacheDir, "titus", "rules"),
+		WarnFunc: func(format string, args ...any) {
+			fmt.Fprintf(os.Stderr, format, args...)
+		},
+	}
+	if remoteRulesPubKey != "" {
+		data, err := os.ReadFile(remoteRulesPubKey)
 		if err != nil {
+			return nil, fmt.Errorf("reading rules public key: %w", err)
+		}
+		if src.PublicKey, err = rule.ParsePublicKey(data); err != nil {
 			return nil, err
 		}
+	}
 
-		// Apply ruleset filtering (skip if "all")
-		if rulesetID != "all" {
-			rulesets, err := loader.LoadBuiltinRulesets()
-			if err != nil {
-				return nil, fmt.Errorf("loading rulesets: %w", err)
-			}
-			rs := rule.FindRuleset(rulesets, rulesetID)
-			if rs == nil {
-				available := make([]string, len(rulesets))
-				for i, r := range rulesets {
-					available[i] = r.ID
-				}
-				return nil, fmt.Errorf("unknown ruleset %q (available: %s, all)", rulesetID, strings.Join(available, ", "))
-			}
-			rules = rule.ApplyRuleset(rules, rs)
+	dir, err := src.Fetch(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("fetching rule pack: %w", err)
+	}
+	pack, err := loader.LoadPackDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return pack.Rules, nil
+}
+
+// loadRuleSelection loads the rules to scan with. With only pack directories
+// given, their rules are added on top of the --rules or ruleset selection;
+// with --rule-packs, the named packs replace the ruleset. The filter applies
+// in every case, before the components of correlated rules are added back.
+func loadRuleSelection(path string, filter rule.FilterConfig, rulesetID, packs string, packDirs []string) ([]*types.Rule, error) {
+	loader := rule.NewLoader()
+
+	// Builtin rules, also used to resolve correlation components
+	builtin, err := loader.LoadBuiltinRules()
+	if err != nil {
+		return nil, err
+	}
+
+	var external []*rule.Pack
+	for _, dir := range packDirs {
+		p, err := loader.LoadPackDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("loading rule pack %s: %w", dir, err)
 		}
+		external = append(external, p)
 	}
 
-	// Apply regex filtering if patterns specified
-	if include != "" || exclude != "" {
-		config := rule.FilterConfig{
-			Include: rule.ParsePatterns(include),
-			Exclude: rule.ParsePatterns(exclude),
+	var rules []*types.Rule
+	if packs == "" {
+		if rules, err = rulesetRules(loader, builtin, path, rulesetID); err != nil {
+			return nil, err
 		}
-		rules, err = rule.Filter(rules, config)
+		for _, p := range external {
+			rules = append(rules, p.Rules...)
+		}
+	} else {
+		available, err := loader.LoadBuiltinPacks()
 		if err != nil {
-			return nil, fmt.Errorf("filtering rules: %w", err)
+			return nil, fmt.Errorf("loading rule packs: %w", err)
+		}
+		available = append(available, external...)
+		if rules, err = rule.SelectPacks(available, builtin, rule.ParsePatterns(packs)); err != nil {
+			return nil, err
+		}
+		if path != "" {
+			custom, err := loadCustomRules(loader, path)
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, custom...)
 		}
 	}
 
-	return rules, nil
+	if rules, err = rule.Filter(rules, filter); err != nil {
+		return nil, fmt.Errorf("filtering rules: %w", err)
+	}
+	return rule.AddCorrelationComponents(rules, builtin), nil
 }
 
 // openScanStore creates the store backend based on the output path configuration.
@@ -398,21 +842,32 @@ func openScanStore(outputPath string, storeBlobs bool) (store.Store, *datastore.
 	return ds.Store, ds, nil
 }
 
-// printScanStats formats and prints scan statistics.
-func printScanStats(cmd *cobra.Command, format, outputPath string, totalBytes, blobCount, matchCount, skippedCount int64, duration time.Duration) {
-	speed := float64(totalBytes) / duration.Seconds()
-	newMatches := matchCount - skippedCount
-	statsLine := fmt.Sprintf("Scanned %d B from %d blobs in %d second (%.0f B/s); %d/%d new matches\n",
-		totalBytes, blobCount, int(duration.Seconds()), speed, newMatches, matchCount)
-
-	if format == "json" || format == "sarif" {
-		fmt.Fprint(cmd.ErrOrStderr(), statsLine)
+// printScanStats prints the one-line summary of a scan's statistics.
+func printScanStats(cmd *cobra.Command, format, outputPath string, stats scanStats) {
+	statsLine := stats.line()
+	if format == "json" || format == "sarif" || format == "gitlab" || format == githubSummaryFormat {
+		statusf(cmd.ErrOrStderr(), "%s", statsLine)
 		if outputPath != ":memory:" {
-			fmt.Fprintf(cmd.ErrOrStderr(), "Results stored in: %s/datastore.db\n\n", outputPath)
+			statusf(cmd.ErrOrStderr(), "Results stored in: %s/datastore.db\n\n", outputPath)
 		}
 	} else {
-		fmt.Fprint(cmd.OutOrStdout(), statsLine)
-		fmt.Fprintf(cmd.OutOrStdout(), "\n")
+		statusf(cmd.OutOrStdout(), "%s\n", statsLine)
+	}
+}
+
+// printSampleCoverage reports how much of the target a --sample scan read.
+func printSampleCoverage(cmd *cobra.Command, format string, c enum.SampleCoverage) {
+	pct := 100.0
+	if c.TotalBytes > 0 {
+		pct = 100 * float64(c.ScannedBytes) / float64(c.TotalBytes)
+	}
+	line := fmt.Sprintf("Sample coverage: read %s of %s (%.2f%%); %d of %d files scanned (%d in full, %d sampled), %d not reached\n",
+		style.Bytes(c.ScannedBytes), style.Bytes(c.TotalBytes), pct, c.FullFiles+c.SampledFiles, c.Files, c.FullFiles, c.SampledFiles, c.SkippedFiles)
+
+	if format == "json" || format == "sarif" || format == "gitlab" || format == githubSummaryFormat {
+		statusf(cmd.ErrOrStderr(), "%s", line)
+	} else {
+		statusf(cmd.OutOrStdout(), "%s\n", line)
 	}
 }
 
@@ -423,6 +878,12 @@ func outputScanResults(cmd *cobra.Command, s store.Store, rules []*types.Rule, r
 		if err != nil {
 			return fmt.Errorf("retrieving matches: %w", err)
 		}
+		matches = reportableMatches(matches, ruleMap)
+		newResultOrder(s).sortMatches(matches)
+		flagLikelyFalsePositives(s, matches)
+		if scanOwners {
+			attributeOwners(context.Background(), s, matches)
+		}
 		return outputMatches(cmd, matches)
 	}
 
@@ -431,7 +892,21 @@ func outputScanResults(cmd *cobra.Command, s store.Store, rules []*types.Rule, r
 		if err != nil {
 			return fmt.Errorf("retrieving matches: %w", err)
 		}
-		return outputSARIF(cmd, s, rules, matches)
+		matches = reportableMatches(matches, ruleMap)
+		newResultOrder(s).sortMatches(matches)
+		flagLikelyFalsePositives(s, matches)
+		return outputSARIF(cmd, s, rules, matches, defaultMaxLocations)
+	}
+
+	if scanOutputFormat == "gitlab" {
+		matches, err := s.GetAllMatches()
+		if err != nil {
+			return fmt.Errorf("retrieving matches: %w", err)
+		}
+		matches = reportableMatches(matches, ruleMap)
+		newResultOrder(s).sortMatches(matches)
+		flagLikelyFalsePositives(s, matches)
+		return outputGitLabReport(cmd, s, matches, ruleMap, defaultMaxLocations)
 	}
 
 	// Human format outputs findings in noseyparker table format
@@ -445,20 +920,24 @@ func outputScanResults(cmd *cobra.Command, s store.Store, rules []*types.Rule, r
 		return fmt.Errorf("retrieving matches: %w", err)
 	}
 
-	findingMatches := make(map[string][]*types.Match)
-	for _, m := range allMatches {
-		rule, ok := ruleMap[m.RuleID]
-		if !ok {
-			return fmt.Errorf("rule not found: %s", m.RuleID)
-		}
-		findingID := types.ComputeFindingID(rule.StructuralID, m.Groups)
-		findingMatches[findingID] = append(findingMatches[findingID], m)
-	}
+	order := newResultOrder(s)
+	order.sortMatches(allMatches)
+	findingMatches := buildFindingMatchMap(findings, allMatches)
 
+	order.sortFindings(findings, findingMatches)
 	for _, f := range findings {
 		f.Matches = findingMatches[f.ID]
 	}
 
+	if scanOutputFormat == githubSummaryFormat {
+		flagLikelyFalsePositives(s, allMatches)
+		return writeGitHubSummary(cmd.OutOrStdout(), s, findings, findingMatches, ruleMap)
+	}
+
+	if quiet {
+		return outputFindingLines(cmd, s, findings, ruleMap)
+	}
+	outputActiveSecrets(cmd.OutOrStdout(), s, style.New(colorEnabled), findings, findingMatches, ruleMap)
 	return outputNoseyParkerSummary(cmd, findings, ruleMap)
 }
 
@@ -488,7 +967,20 @@ func parseSize(sizeStr string) (int64, error) {
 	return val * multiplier, nil
 }
 
-func createEnumerator(target string, useGit bool) (enum.Enumerator, error) {
+// enumHooks are optional callbacks made by an enumerator as it walks a
+// target.
+type enumHooks struct {
+	onSkip func(path, reason string) // each file left out, and why
+	onFile func(path string)         // each file found that isn't ignored
+
+	// scannedCommits returns the commits of a git repository whose history
+	// is already scanned, to leave out of the walk (--incremental)
+	scannedCommits func(repoPath string) ([]string, error)
+}
+
+// createEnumerator builds the enumerator for target from the scan flags and
+// policies, if any.
+func createEnumerator(target string, useGit bool, policies *scanPolicies, hooks enumHooks) (enum.Enumerator, error) {
 	// Parse extraction limits
 	limits := enum.DefaultExtractionLimits()
 	
@@ -509,33 +1001,299 @@ func createEnumerator(target string, useGit bool) (enum.Enumerator, error) {
 	}
 	
 	limits.MaxDepth = extractMaxDepth
+	limits.MaxEntries = extractMaxEntries
+	limits.MaxRatio = extractMaxRatio
 	limits.SQLiteRowLimit = scanSQLiteRowLimit
+	scope, err := enum.NewSQLiteScope(scanSQLiteTables)
+	if err != nil {
+		return nil, fmt.Errorf("parsing extract-sqlite-tables: %w", err)
+	}
+	limits.SQLiteScope = scope
+	limits.DocPasswords = scanDocPasswords
 
 	config := enum.Config{
-		Root:            target,
-		MaxFileSize:     scanMaxFileSize,
-		FollowSymlinks:  false,
-		ExtractArchives: string(scanExtractArchivesFlag),
-		ExtractLimits:   limits,
-		IgnoreFile:      scanIgnoreFile,
+		Root:              target,
+		MaxFileSize:       scanMaxFileSize,
+		FollowSymlinks:    false,
+		ExtractArchives:   string(scanExtractArchivesFlag),
+		ExtractLimits:     limits,
+		TreatBinaryAsText: scanBinaryAsText,
+		IgnoreFile:        scanIgnoreFile,
+		OnSkip:            hooks.onSkip,
+		OnFile:            hooks.onFile,
+	}
+	if policies != nil {
+		config.PathConfig = policies.pathConfig(config)
+	}
+
+	if enum.IsSMBURL(target) {
+		cfg, err := smbConfig(target, config)
+		if err != nil {
+			return nil, err
+		}
+		return enum.NewSMBEnumerator(cfg)
+	}
+
+	if enum.IsIMAPURL(target) {
+		cfg, err := imapConfig(target, config)
+		if err != nil {
+			return nil, err
+		}
+		return enum.NewIMAPEnumerator(cfg), nil
+	}
+
+	if factory, ok := enum.Lookup(target); ok {
+		return factory(target, config)
+	}
+
+	if scanBrowser {
+		return enum.NewBrowserEnumerator(config), nil
+	}
+
+	if scanProfile == "memory" {
+		return enum.NewMemoryDumpEnumerator(config), nil
+	}
+
+	if scanPreset == "dev-caches" {
+		return enum.NewDevCacheEnumerator(config), nil
+	}
+
+	if scanEnv {
+		return enum.NewEnvEnumerator(config, os.Environ()), nil
+	}
+
+	if scanSample {
+		sample, err := sampleConfig()
+		if err != nil {
+			return nil, err
+		}
+		return enum.NewSamplingEnumerator(config, sample), nil
 	}
 
 	if useGit {
-		gitEnum := enum.NewGitEnumerator(config)
-		gitEnum.WalkAll = true
+		// Walk the history of every repository under target, not just
+		// target's own: nested clones and submodules have their own.
+		repos, err := enum.FindRepositories(target)
+		if err != nil {
+			return nil, fmt.Errorf("finding git repositories: %w", err)
+		}
+		if len(repos) == 0 {
+			// target may be a subdirectory of a repository; let git find it
+			repos = []string{target}
+		}
+		var enumerators []enum.Enumerator
+		for _, repo := range repos {
+			repoConfig := config
+			repoConfig.Root = repo
+			if policies != nil {
+				repoConfig.PathConfig = policies.repoPathConfig(config, repo)
+			}
+			gitEnum := enum.NewGitEnumerator(repoConfig)
+			gitEnum.WalkAll = true
+			gitEnum.KeepDuplicates = keepDuplicates(scanDedupe)
+			gitEnum.Refs = scanRefs
+			if hooks.scannedCommits != nil {
+				if gitEnum.Exclude, err = hooks.scannedCommits(repo); err != nil {
+					return nil, fmt.Errorf("reading scanned commits of %s: %w", repo, err)
+				}
+			}
+			enumerators = append(enumerators, gitEnum)
+		}
 		fsEnum := enum.NewFilesystemEnumerator(config)
-		return enum.NewCombinedEnumerator(gitEnum, fsEnum), nil
+		combined := enum.NewCombinedEnumerator(append(enumerators, fsEnum)...)
+		combined.KeepDuplicates = keepDuplicates(scanDedupe)
+		return combined, nil
 	}
 
 	return enum.NewFilesystemEnumerator(config), nil
 }
 
+// smbConfig builds the SMB target from an smb:// URL. Credentials given by
+// the --smb-* flags, or failing those the SMB_* environment variables,
+// override any in the URL.
+func smbConfig(target string, config enum.Config) (enum.SMBConfig, error) {
+	cfg, err := enum.ParseSMBURL(target)
+	if err != nil {
+		return cfg, err
+	}
+	config.Root = ""
+	cfg.Config = config
+	for _, cred := range []struct {
+		dst       *string
+		flag, env string
+	}{
+		{&cfg.User, scanSMBUser, "SMB_USERNAME"},
+		{&cfg.Password, scanSMBPassword, "SMB_PASSWORD"},
+		{&cfg.Domain, scanSMBDomain, "SMB_DOMAIN"},
+		{&cfg.Hash, scanSMBHash, "SMB_NT_HASH"},
+	} {
+		value := cred.flag
+		if value == "" {
+			value = os.Getenv(cred.env)
+		}
+		if value != "" {
+			*cred.dst = value
+		}
+	}
+	return cfg, nil
+}
+
+// imapConfig builds the IMAP target from an imap:// or imaps:// URL.
+// Credentials given by --imap-user and --imap-password, or failing those
+// IMAP_USERNAME and IMAP_PASSWORD, override any in the URL.
+func imapConfig(target string, config enum.Config) (enum.IMAPConfig, error) {
+	cfg, err := enum.ParseIMAPURL(target)
+	if err != nil {
+		return cfg, err
+	}
+	config.Root = ""
+	cfg.Config = config
+	cfg.Insecure = scanIMAPInsecure
+	for _, cred := range []struct {
+		dst       *string
+		flag, env string
+	}{
+		{&cfg.User, scanIMAPUser, "IMAP_USERNAME"},
+		{&cfg.Password, scanIMAPPassword, "IMAP_PASSWORD"},
+	} {
+		value := cred.flag
+		if value == "" {
+			value = os.Getenv(cred.env)
+		}
+		if value != "" {
+			*cred.dst = value
+		}
+	}
+	if cfg.User == "" {
+		return cfg, fmt.Errorf("IMAP targets require a user: use --imap-user, IMAP_USERNAME or the URL")
+	}
+	return cfg, nil
+}
+
+// scopeTarget returns the target findings are matched against when updating
+// their lifecycle: smb:// and imap:// URLs lose any credentials, as
+// provenance does.
+func scopeTarget(target string) string {
+	if cfg, err := enum.ParseSMBURL(target); err == nil {
+		return cfg.URL()
+	}
+	if cfg, err := enum.ParseIMAPURL(target); err == nil {
+		var mailbox string
+		if len(cfg.Mailboxes) == 1 {
+			mailbox = cfg.Mailboxes[0]
+		}
+		return cfg.URL(mailbox)
+	}
+	return target
+}
+
+// sampleConfig builds the sampling strategy from the --sample-* flags.
+func sampleConfig() (enum.SampleConfig, error) {
+	sample := enum.DefaultSampleConfig()
+	var err error
+	if sample.FullSize, err = parseSize(scanSampleFullSize); err != nil {
+		return sample, fmt.Errorf("parsing sample-full-size: %w", err)
+	}
+	chunkSize, err := parseSize(scanSampleChunkSize)
+	if err != nil || chunkSize <= 0 {
+		return sample, fmt.Errorf("invalid sample-chunk-size: %s", scanSampleChunkSize)
+	}
+	sample.ChunkSize = int(chunkSize)
+	if scanSampleChunks < 1 {
+		return sample, fmt.Errorf("sample-chunks must be at least 1")
+	}
+	sample.Chunks = scanSampleChunks
+	if scanSampleBudget != "" {
+		if sample.MaxBytes, err = parseSize(scanSampleBudget); err != nil {
+			return sample, fmt.Errorf("parsing sample-budget: %w", err)
+		}
+	}
+	sample.MaxDuration = scanSampleTime
+	sample.Seed = scanSampleSeed
+	return sample, nil
+}
+
+// applyScanProfile adjusts scan settings for --profile. The memory profile
+// selects the memory ruleset unless rules were chosen explicitly, and turns
+// off context lines unless set, since a binary chunk may have no newlines to
+// bound them.
+func applyScanProfile(cmd *cobra.Command) error {
+	switch scanProfile {
+	case "":
+		return nil
+	case "memory":
+		if scanGit || scanBrowser {
+			return fmt.Errorf("--profile memory cannot be used with --git or --browser")
+		}
+		if !cmd.Flags().Changed("ruleset") && scanRulesPath == "" && scanRulePacks == "" {
+			scanRuleset = "memory"
+		}
+		if !cmd.Flags().Changed("context-lines") {
+			scanContextLines = 0
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown profile %q (available: memory)", scanProfile)
+	}
+}
+
+// scanTarget returns the target argument, which --preset defaults to the
+// user's home directory. --env takes no target; the current directory stands
+// in for it, so that its policies apply and scans are recorded against it.
+func scanTarget(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	if scanEnv {
+		return ".", nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("finding home directory for --preset: %w", err)
+	}
+	return home, nil
+}
+
+// applyScanPreset adjusts scan settings for --preset. The dev-caches preset
+// selects the dev-caches ruleset unless rules were chosen explicitly, and
+// extracts .db files unless --extract is set, since gcloud keeps its
+// credentials and access tokens in SQLite databases.
+func applyScanPreset(cmd *cobra.Command) error {
+	switch scanPreset {
+	case "":
+		return nil
+	case "dev-caches":
+		if scanGit || scanBrowser || scanProfile != "" || scanSample {
+			return fmt.Errorf("--preset cannot be used with --git, --browser, --profile or --sample")
+		}
+		if !cmd.Flags().Changed("ruleset") && scanRulesPath == "" && scanRulePacks == "" {
+			scanRuleset = "dev-caches"
+		}
+		if !cmd.Flags().Changed("extract") {
+			scanExtractArchivesFlag = "db"
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown preset %q (available: dev-caches)", scanPreset)
+	}
+}
+
 // repoTarget holds parsed repository URL information.
 type repoTarget struct {
-	Platform string // "github" or "gitlab"
+	Platform string // "github", "gitlab", or "ssh" for other SSH hosts
 	Owner    string // org/user
 	Repo     string // repository/project name
 	FullPath string // "owner/repo"
+	Host     string // e.g. "github.com"
+	CloneURL string // SSH URL to clone, empty to clone over HTTPS
+}
+
+// name returns the name findings in the repository are reported under.
+func (rt repoTarget) name() string {
+	if rt.Platform == "ssh" {
+		return rt.Host + "/" + rt.FullPath
+	}
+	return rt.FullPath
 }
 
 // parseRepoURL detects if a target string is a GitHub or GitLab repository reference.
@@ -546,6 +1304,10 @@ type repoTarget struct {
 //   - gitlab.com/namespace/project
 //   - https://gitlab.com/namespace/project
 func parseRepoURL(target string) (repoTarget, bool) {
+	if rt, ok := parseSSHRepoURL(target); ok {
+		return rt, true
+	}
+
 	// Strip common URL prefixes
 	cleaned := target
 	cleaned = strings.TrimPrefix(cleaned, "https://")
@@ -577,63 +1339,121 @@ func parseRepoURL(target string) (repoTarget, bool) {
 		Owner:    owner,
 		Repo:     repo,
 		FullPath: owner + "/" + repo,
+		Host:     host,
 	}, true
 }
 
-// runRepoScan handles scanning of GitHub/GitLab repositories detected from URL-like targets.
-func runRepoScan(cmd *cobra.Command, rt repoTarget) error {
-	// Resolve token from environment
-	var token string
-	switch rt.Platform {
-	case "github":
-		token = os.Getenv("GITHUB_TOKEN")
-	case "gitlab":
-		token = os.Getenv("GITLAB_TOKEN")
+// scpLikeURL matches git's scp-like SSH syntax, user@host:path.
+var scpLikeURL = regexp.MustCompile(`^[A-Za-z0-9._-]+@([A-Za-z0-9.-]+):(.+)$`)
+
+// parseSSHRepoURL parses an SSH clone URL, either ssh://[user@]host[:port]/path
+// or git@host:path. Any host is accepted; the clone authenticates with the
+// user's SSH agent and keys.
+func parseSSHRepoURL(target string) (repoTarget, bool) {
+	var host, path string
+	if strings.HasPrefix(target, "ssh://") || strings.HasPrefix(target, "git+ssh://") {
+		u, err := url.Parse(target)
+		if err != nil || u.Hostname() == "" {
+			return repoTarget{}, false
+		}
+		host, path = u.Hostname(), u.Path
+	} else if m := scpLikeURL.FindStringSubmatch(target); m != nil {
+		host, path = m[1], m[2]
+	} else {
+		return repoTarget{}, false
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	i := strings.LastIndex(path, "/")
+	if path == "" || i == len(path)-1 {
+		return repoTarget{}, false
 	}
 
-	if token == "" {
-		fmt.Fprintf(cmd.ErrOrStderr(), "Note: No %s token provided. Using unauthenticated access (public repos only).\n\n", rt.Platform)
+	host = strings.ToLower(host)
+	platform := "ssh"
+	switch host {
+	case "github.com":
+		platform = "github"
+	case "gitlab.com":
+		platform = "gitlab"
 	}
+	return repoTarget{
+		Platform: platform,
+		Owner:    path[:max(i, 0)],
+		Repo:     path[i+1:],
+		FullPath: path,
+		Host:     host,
+		CloneURL: target,
+	}, true
+}
 
-	// Build clone URL
-	var cloneURL string
-	switch rt.Platform {
-	case "github":
-		cloneURL = "https://github.com/" + rt.FullPath + ".git"
-	case "gitlab":
-		cloneURL = "https://gitlab.com/" + rt.FullPath + ".git"
+// runRepoScan handles scanning of GitHub/GitLab repositories detected from URL-like targets.
+// gitFilter, if not nil, narrows the --git history scanned.
+func runRepoScan(cmd *cobra.Command, rt repoTarget, gitFilter *gitHistoryFilter) error {
+	// Build clone URL. SSH clones authenticate with the user's SSH agent
+	// and keys; HTTPS clones with a token from the environment, or from a
+	// CLI or credential helper the user is logged in with.
+	var token string
+	cloneURL := rt.CloneURL
+	if cloneURL != "" {
+		statusf(cmd.ErrOrStderr(), "Cloning %s over SSH.\n\n", rt.name())
+	} else {
+		token = resolveToken(cmd.ErrOrStderr(), "", strings.ToUpper(rt.Platform)+"_TOKEN", rt.Platform,
+			rt.Host, !scanNoTokenDiscovery)
+		if token == "" {
+			statusf(cmd.ErrOrStderr(), "Note: No %s token provided. Using unauthenticated access (public repos only).\n\n", rt.Platform)
+		}
+		cloneURL = "https://" + rt.Host + "/" + rt.FullPath + ".git"
 	}
 
 	repos := []enum.RepoInfo{{
-		Name:     rt.FullPath,
+		Name:     rt.name(),
 		CloneURL: cloneURL,
 	}}
 
-	cloneEnum := enum.NewCloneEnumerator(repos, enum.Config{
-		MaxFileSize: scanMaxFileSize,
-		IgnoreFile:  scanIgnoreFile,
-	})
-	cloneEnum.Git = scanGit
-	cloneEnum.Token = token
-
 	// Load rules
-	rules, err := loadRules(scanRulesPath, scanRulesInclude, scanRulesExclude, scanRuleset)
+	rules, err := loadRuleSelection(scanRulesPath, ruleFilter(scanRulesInclude, scanRulesExclude, scanCategories, scanExcludeCategories), scanRuleset, scanRulePacks, scanRulePackDirs)
 	if err != nil {
 		return fmt.Errorf("loading rules: %w", err)
 	}
 
+	// Filename rules are matched against paths as the enumerator finds them
+	filenames, err := matcher.NewFilenameMatcher(rules)
+	if err != nil {
+		return fmt.Errorf("creating matcher: %w", err)
+	}
+	paths := newPathMatches(filenames, nil)
+
+	cloneEnum := enum.NewCloneEnumerator(repos, enum.Config{
+		MaxFileSize:       scanMaxFileSize,
+		IgnoreFile:        scanIgnoreFile,
+		TreatBinaryAsText: scanBinaryAsText,
+		OnFile:            paths.hooks().onFile,
+	})
+	cloneEnum.Git = scanGit
+	cloneEnum.Refs = scanRefs
+	cloneEnum.Token = token
+	cloneEnum.WorkDir = scanWorkDir
+	cloneEnum.KeepClone = scanKeepClone
+	cloneEnum.Submodules = scanSubmodules
+	cloneEnum.KeepDuplicates = keepDuplicates(scanDedupe)
+	cloneEnum.Retries = scanCloneRetries
+
 	ruleMap := make(map[string]*types.Rule)
 	for _, r := range rules {
 		ruleMap[r.ID] = r
 	}
 
 	// Create matcher
+	var counters scanCounters
 	m, err := matcher.New(matcher.Config{
-		Rules:        rules,
-		ContextLines: scanContextLines,
-		WarnFunc: func(format string, args ...any) {
+		Rules:                     rules,
+		ContextLines:              scanContextLines,
+		ContextBytes:              scanContextBytes,
+		KeepCorrelationComponents: crossFileCorrelationEnabled(),
+		WarnFunc: countTimeouts(func(format string, args ...any) {
 			fmt.Fprintf(os.Stderr, format, args...)
-		},
+		}, &counters.timeouts),
 	})
 	if err != nil {
 		return fmt.Errorf("creating matcher: %w", err)
@@ -657,19 +1477,43 @@ func runRepoScan(cmd *cobra.Command, rt repoTarget) error {
 		}
 	}
 
-	validationEngine := initValidationEngine()
+	if _, err := resolveFingerprint(s, scanFingerprintVersion); err != nil {
+		return err
+	}
+
+	run, err := beginScan(s, rt.Host+"/"+rt.FullPath, rules)
+	if err != nil {
+		return err
+	}
+
+	validationEngine, err := initValidationEngine()
+	if err != nil {
+		return err
+	}
+	audit, err := openValidationAudit(scanValidateAudit, validationEngine)
+	if err != nil {
+		return err
+	}
+	if audit != nil {
+		defer closeValidationAudit(audit)
+	}
+
+	siemSink, err := openSIEMSink(scanSIEMTarget, scanSIEMFormat)
+	if err != nil {
+		return err
+	}
+	if siemSink != nil {
+		defer siemSink.Close()
+	}
 
 	// Wire validator awareness into the matcher's built-in deduplicator
 	if validationEngine != nil {
 		matcher.SetCanValidate(m, validationEngine.CanValidate)
 	}
 
-	ctx := context.Background()
-	var matchCount atomic.Int64
-	var findingCount atomic.Int64
-	var skippedCount atomic.Int64
-	var totalBytes atomic.Int64
-	var blobCount atomic.Int64
+	// Cancel on Ctrl+C so in-flight clones are killed and their temp dirs removed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 	startTime := time.Now()
 
 	numWorkers := scanWorkers
@@ -677,29 +1521,38 @@ func runRepoScan(cmd *cobra.Command, rt repoTarget) error {
 		numWorkers = 1
 	}
 	jobs := make(chan blobJob, 2*numWorkers)
+	dedupe := newBlobDedupe(scanDedupe, s, scanIncremental)
+	progress := newScanProgress(cmd.ErrOrStderr())
+	lifecycle := repoScanLifecycle(rt, gitFilter)
 
+	// The group context is cancelled once Wait returns; keep the parent for post-scan work
+	baseCtx := ctx
 	g, ctx := errgroup.WithContext(ctx)
 
 	// Producer
 	g.Go(func() error {
 		defer close(jobs)
 		return cloneEnum.Enumerate(ctx, func(content []byte, blobID types.BlobID, prov types.Provenance) error {
-			totalBytes.Add(int64(len(content)))
-			blobCount.Add(1)
+			if gitFilter != nil && !gitFilter.keepBlob(prov) {
+				return nil
+			}
+			counters.bytes.Add(int64(len(content)))
+			counters.blobs.Add(1)
+			lifecycle.see(blobID)
 
-			if scanIncremental {
-				exists, err := s.BlobExists(blobID)
-				if err != nil {
-					return fmt.Errorf("checking blob: %w", err)
-				}
-				if exists {
-					skippedCount.Add(1)
-					return nil
-				}
+			scan, recordOnly, err := dedupe.check(blobID)
+			if err != nil {
+				return err
+			}
+			if !scan || recordOnly {
+				counters.skippedBlobs.Add(1)
+			}
+			if !scan {
+				return nil
 			}
 
 			select {
-			case jobs <- blobJob{content: content, blobID: blobID, prov: prov}:
+			case jobs <- blobJob{content: content, blobID: blobID, prov: prov, recordOnly: recordOnly}:
 				return nil
 			case <-ctx.Done():
 				return ctx.Err()
@@ -725,36 +1578,17 @@ func runRepoScan(cmd *cobra.Command, rt repoTarget) error {
 				}
 				err := s.ExecBatch(func(tx store.Store) error {
 					for _, item := range batch {
-						if err := tx.AddBlob(item.blobID, item.size); err != nil {
-							return fmt.Errorf("storing blob: %w", err)
-						}
-						if err := tx.AddProvenance(item.blobID, item.prov); err != nil {
-							return fmt.Errorf("storing provenance: %w", err)
+						fresh, err := countNewMatches(tx, ruleMap, item.blobID, item.matches)
+						if err != nil {
+							return err
 						}
-						for _, match := range item.matches {
-							if err := tx.AddMatch(match); err != nil {
-								return fmt.Errorf("storing match: %w", err)
-							}
-							rule, ok := ruleMap[match.RuleID]
-							if !ok {
-								return fmt.Errorf("rule not found: %s", match.RuleID)
-							}
-							findingID := types.ComputeFindingID(rule.StructuralID, match.Groups)
-							exists, err := tx.FindingExists(findingID)
-							if err != nil {
-								return fmt.Errorf("checking finding: %w", err)
-							}
-							if !exists {
-								findingCount.Add(1)
-								if err := tx.AddFinding(&types.Finding{
-									ID:     findingID,
-									RuleID: match.RuleID,
-									Groups: match.Groups,
-								}); err != nil {
-									return fmt.Errorf("storing finding: %w", err)
-								}
-							}
+						created, err := recordBlob(tx, ruleMap, item.blobID, item.prov, item.size, item.matches)
+						if err != nil {
+							return err
 						}
+						counters.newMatches.Add(fresh)
+						counters.newFindings.Add(int64(len(created)))
+						progress.found(item.prov, created)
 					}
 					return nil
 				})
@@ -763,26 +1597,26 @@ func runRepoScan(cmd *cobra.Command, rt repoTarget) error {
 			}
 
 			for job := range jobs {
-				matches, err := m.MatchWithBlobID(job.content, job.blobID)
-				if err != nil {
-					return fmt.Errorf("matching content: %w", err)
+				var matches []*types.Match
+				if !job.recordOnly {
+					var err error
+					matches, err = m.MatchWithBlobID(job.content, job.blobID)
+					if err != nil {
+						return fmt.Errorf("matching content: %w", err)
+					}
 				}
 
-				for _, match := range matches {
-					startLine, startCol := types.ComputeLineColumn(job.content, int(match.Location.Offset.Start))
-					endLine, endCol := types.ComputeLineColumn(job.content, int(match.Location.Offset.End))
-					match.Location.Source.Start.Line = startLine
-					match.Location.Source.Start.Column = startCol
-					match.Location.Source.End.Line = endLine
-					match.Location.Source.End.Column = endCol
-				}
+				setLineColumns(job.content, matches)
 
-				validateMatches(ctx, validationEngine, matches, verbose)
-				matchCount.Add(int64(len(matches)))
+				reported := reportableMatches(matches, ruleMap)
+				progress.blob(job.blobID, job.prov, len(job.content), reported)
+				validateMatches(ctx, validationEngine, reported, verboseAt(verboseTrace))
+				emitSIEMEvents(siemSink, reported, job.prov)
+				counters.matches.Add(int64(len(reported)))
 
 				batch = append(batch, batchItem{
 					blobID:  job.blobID,
-					prov:    job.prov,
+					prov:    filetype.Tag(job.prov, job.content),
 					size:    int64(len(job.content)),
 					matches: matches,
 				})
@@ -799,14 +1633,63 @@ func runRepoScan(cmd *cobra.Command, rt repoTarget) error {
 	if err := g.Wait(); err != nil {
 		return fmt.Errorf("scanning: %w", err)
 	}
+	if failures := cloneEnum.Failures(); len(failures) > 0 {
+		return failures[0].Err
+	}
 
-	duration := time.Since(startTime)
-	printScanStats(cmd, scanOutputFormat, scanOutputPath,
-		totalBytes.Load(), blobCount.Load(), matchCount.Load(), skippedCount.Load(), duration)
+	recorded, err := paths.record(s, ruleMap, func(ms []*types.Match, prov types.Provenance) {
+		progress.blob(ms[0].BlobID, prov, 0, ms)
+		emitSIEMEvents(siemSink, ms, prov)
+	})
+	if err != nil {
+		return fmt.Errorf("recording filename matches: %w", err)
+	}
+	for _, blobID := range recorded.blobIDs {
+		lifecycle.see(blobID)
+	}
+	counters.matches.Add(recorded.matches)
+	counters.newMatches.Add(recorded.newMatches)
+	counters.newFindings.Add(recorded.findings)
+
+	if crossFileCorrelationEnabled() {
+		if err := correlateAcrossBlobs(baseCtx, cmd, s, rules, ruleMap, validationEngine); err != nil {
+			return err
+		}
+	}
+
+	if _, remediated, err := lifecycle.update(s, ruleMap); err != nil {
+		return fmt.Errorf("updating finding states: %w", err)
+	} else if remediated > 0 && scanOutputFormat == "human" {
+		statusf(cmd.ErrOrStderr(), "%d finding(s) no longer present, marked remediated\n", remediated)
+	}
+
+	if err := finishScan(s, run); err != nil {
+		return err
+	}
+	progress.printRuleStats(ruleMap)
+	stats := counters.stats(run.Target, startTime, time.Since(startTime), progress.ruleCounts())
+	printScanStats(cmd, scanOutputFormat, scanOutputPath, stats)
+	if scanStatsFile != "" {
+		err := writeStatsFile(scanStatsFile, stats)
+		if err != nil {
+			return err
+		}
+	}
 
 	return outputScanResults(cmd, s, rules, ruleMap)
 }
 
+// repoScanLifecycle returns the lifecycle of a scan of a remote repository,
+// scoped to its git history: blobs from a clone are located by the
+// repository's name. Only a scan of the whole history can tell a secret is
+// gone, so a scan of the checkout alone, or of history narrowed by --since,
+// --author or --ref, never marks findings remediated.
+func repoScanLifecycle(rt repoTarget, gitFilter *gitHistoryFilter) *scanLifecycle {
+	lifecycle := newScanLifecycle(rt.name(), true)
+	lifecycle.partial = !scanGit || gitFilter != nil
+	return lifecycle
+}
+
 // outputNoseyParkerSummary outputs findings in noseyparker table format
 func outputNoseyParkerSummary(cmd *cobra.Command, findings []*types.Finding, ruleMap map[string]*types.Rule) error {
 	if len(findings) == 0 {
@@ -836,25 +1719,29 @@ func outputNoseyParkerSummary(cmd *cobra.Command, findings []*types.Finding, rul
 		statsMap[f.RuleID].matches += len(f.Matches)
 	}
 
-	// Find longest rule name for column width
-	maxNameLen := len("Rule")
-	for _, stats := range statsMap {
-		if len(stats.name) > maxNameLen {
-			maxNameLen = len(stats.name)
-		}
+	s := style.New(colorEnabled)
+	table := style.Table{
+		Columns: []style.Column{
+			{Header: s.Heading.Sprint("Rule"), Shrink: true},
+			{Header: s.Heading.Sprint("Findings"), Right: true},
+			{Header: s.Heading.Sprint("Matches"), Right: true},
+		},
+		Separator: "─",
+		Width:     style.TerminalWidth(cmd.OutOrStdout()),
 	}
 
-	// Print header
-	fmt.Fprintf(cmd.OutOrStdout(), " %-*s   Findings   Matches \n", maxNameLen, "Rule")
-
-	// Print separator line using box-drawing character
-	separatorLen := maxNameLen + 3 + 10 + 3 + 8
-	fmt.Fprintf(cmd.OutOrStdout(), "%s\n", strings.Repeat("─", separatorLen))
-
-	// Print data rows
-	for _, stats := range statsMap {
-		fmt.Fprintf(cmd.OutOrStdout(), " %-*s   %8d   %7d \n",
-			maxNameLen, stats.name, stats.findings, stats.matches)
+	// One row per rule, by rule ID
+	ruleIDs := make([]string, 0, len(statsMap))
+	for ruleID := range statsMap {
+		ruleIDs = append(ruleIDs, ruleID)
+	}
+	sort.Strings(ruleIDs)
+	for _, ruleID := range ruleIDs {
+		stats := statsMap[ruleID]
+		table.Rows = append(table.Rows, []string{s.RuleName.Sprint(stats.name), strconv.Itoa(stats.findings), strconv.Itoa(stats.matches)})
+	}
+	if err := table.Render(cmd.OutOrStdout()); err != nil {
+		return err
 	}
 
 	// Print footer
@@ -863,6 +1750,33 @@ func outputNoseyParkerSummary(cmd *cobra.Command, findings []*types.Finding, rul
 	return nil
 }
 
+// outputFindingLines prints one line per finding, with the location of its
+// first match, for --quiet scans.
+func outputFindingLines(cmd *cobra.Command, s store.Store, findings []*types.Finding, ruleMap map[string]*types.Rule) error {
+	for _, f := range findings {
+		name := f.RuleID
+		if r, ok := ruleMap[f.RuleID]; ok {
+			name = r.Name
+		}
+		location := ""
+		if len(f.Matches) > 0 {
+			m := f.Matches[0]
+			location = " in " + m.BlobID.Hex()
+			if prov, err := s.GetProvenance(m.BlobID); err == nil && prov != nil {
+				location = " in " + prov.Path()
+			}
+			if line := m.Location.Source.Start.Line; line > 0 {
+				location += fmt.Sprintf(":%d", line)
+			}
+			if m.Kind != "" {
+				location += " (" + m.Kind + ")"
+			}
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s%s (id %s)\n", name, location, f.ID)
+	}
+	return nil
+}
+
 func outputMatches(cmd *cobra.Command, matches []*types.Match) error {
 	encoder := json.NewEncoder(cmd.OutOrStdout())
 	encoder.SetIndent("", "  ")
@@ -898,8 +1812,29 @@ func outputFindings(cmd *cobra.Command, findings []*types.Finding) error {
 	}
 }
 
-// outputSARIF outputs matches in SARIF 2.1.0 format
-func outputSARIF(cmd *cobra.Command, s store.Store, rules []*types.Rule, matches []*types.Match) error {
+// flagLikelyFalsePositives sets LikelyFP on matches whose snippet context or
+// provenance paths look like test, example or documentation data.
+func flagLikelyFalsePositives(s store.Store, matches []*types.Match) {
+	f := fpfilter.New()
+	pathCache := make(map[types.BlobID][]string)
+	for _, m := range matches {
+		paths, ok := pathCache[m.BlobID]
+		if !ok {
+			provs, err := s.GetAllProvenance(m.BlobID)
+			if err == nil {
+				for _, p := range provs {
+					paths = append(paths, p.Path())
+				}
+			}
+			pathCache[m.BlobID] = paths
+		}
+		f.Flag(m, paths)
+	}
+}
+
+// outputSARIF outputs matches in SARIF 2.1.0 format, listing up to
+// maxLocations of the places each match's blob was found (0 = all).
+func outputSARIF(cmd *cobra.Command, s store.Store, rules []*types.Rule, matches []*types.Match, maxLocations int) error {
 	// Create SARIF report
 	report := sarif.NewReport()
 
@@ -908,26 +1843,18 @@ func outputSARIF(cmd *cobra.Command, s store.Store, rules []*types.Rule, matches
 		report.AddRule(rule)
 	}
 
-	// Cache provenance by blob ID to avoid repeated queries
-	provenanceCache := make(map[types.BlobID]string)
-
-	// Get provenance for each match and add results
+	locations := newBlobLocations(s, maxLocations)
+	locations.attach(matches)
 	for _, match := range matches {
-		// Check cache first
-		filePath, ok := provenanceCache[match.BlobID]
-		if !ok {
-			// Query provenance
-			prov, err := s.GetProvenance(match.BlobID)
-			if err != nil {
-				// If no provenance found, use blob ID as fallback
-				filePath = match.BlobID.Hex()
-			} else {
-				filePath = prov.Path()
-			}
-			provenanceCache[match.BlobID] = filePath
+		var paths []string
+		for _, loc := range match.Locations {
+			paths = append(paths, loc.Path)
 		}
-
-		report.AddResult(match, filePath)
+		if len(paths) == 0 {
+			// If no provenance found, use blob ID as fallback
+			paths = []string{match.BlobID.Hex()}
+		}
+		report.AddResult(match, paths...)
 	}
 
 	// Serialize to JSON
@@ -946,27 +1873,58 @@ func outputSARIF(cmd *cobra.Command, s store.Store, rules []*types.Rule, matches
 }
 
 // initValidationEngine creates the validation engine if validation is enabled.
-func initValidationEngine() *validator.Engine {
-	if !scanValidate {
-		return nil
+// Plugins from --validator-plugins (or the default plugin directory) take
+// precedence over built-in validators.
+func initValidationEngine() (*validator.Engine, error) {
+	var engine *validator.Engine
+	switch {
+	case scanValidateAggressive:
+		engine = validator.NewAggressiveEngine(scanValidateWorkers)
+	case scanValidate:
+		engine = validator.NewDefaultEngine(scanValidateWorkers)
+	default:
+		if scanValidateAWSPerms {
+			return nil, fmt.Errorf("--validate-aws-permissions requires --validate")
+		}
+		return nil, nil
+	}
+
+	if scanValidateAWSPerms {
+		engine.Prepend(validator.NewAWSProbingValidator())
+	}
+	if err := setValidationLimits(engine, scanValidateTimeouts, scanValidateBreaker); err != nil {
+		return nil, err
+	}
+
+	dir := scanValidatorPlugins
+	if dir == "" {
+		dir = validator.DefaultPluginDir()
 	}
-	return validator.NewDefaultEngine(scanValidateWorkers)
+	if dir != "" {
+		plugins, err := validator.LoadPlugins(context.Background(), dir)
+		if err != nil {
+			return nil, fmt.Errorf("loading validator plugins: %w", err)
+		}
+		engine.Prepend(plugins...)
+	}
+	return engine, nil
 }
 
 // validateMatches validates matches using the validation engine.
-func validateMatches(ctx context.Context, engine *validator.Engine, matches []*types.Match, verbose bool) {
+// With trace set, it logs each match queued and each result to stderr.
+func validateMatches(ctx context.Context, engine *validator.Engine, matches []*types.Match, trace bool) {
 	if engine == nil || len(matches) == 0 {
 		return
 	}
 
-	if verbose {
+	if trace {
 		fmt.Fprintf(os.Stderr, "[validate] Starting validation for %d matches\n", len(matches))
 	}
 
 	// Submit all matches for async validation
 	results := make([]<-chan *types.ValidationResult, len(matches))
 	for i := range matches {
-		if verbose {
+		if trace {
 			fmt.Fprintf(os.Stderr, "[validate] Queueing match %d: rule=%s\n", i+1, matches[i].RuleID)
 		}
 		results[i] = engine.ValidateAsync(ctx, matches[i])
@@ -976,17 +1934,92 @@ func validateMatches(ctx context.Context, engine *validator.Engine, matches []*t
 	for i, ch := range results {
 		result := <-ch
 		matches[i].ValidationResult = result
-		if verbose {
+		if trace {
 			fmt.Fprintf(os.Stderr, "[validate] Result %d: rule=%s status=%s confidence=%.1f message=%s\n",
 				i+1, matches[i].RuleID, result.Status, result.Confidence, result.Message)
 		}
 	}
 
-	if verbose {
+	if trace {
 		fmt.Fprintf(os.Stderr, "[validate] Validation complete\n")
 	}
 }
 
+// setValidationLimits applies --validate-timeout values (a duration for
+// every validator, or name=duration for one) and --validate-breaker.
+func setValidationLimits(engine *validator.Engine, timeouts []string, breaker int) error {
+	for _, t := range timeouts {
+		name, value, perValidator := strings.Cut(t, "=")
+		if !perValidator {
+			value = name
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid --validate-timeout %q: want a duration like 10s, or name=duration", t)
+		}
+		if perValidator {
+			engine.SetValidatorTimeout(name, d)
+		} else {
+			engine.SetTimeout(d)
+		}
+	}
+	engine.SetCircuitBreaker(breaker, validator.DefaultBreakerCooldown)
+	return nil
+}
+
+// openValidationAudit opens the --validate-audit log and has engine write
+// to it, or returns nil if path is unset.
+func openValidationAudit(path string, engine *validator.Engine) (*validator.AuditLog, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if engine == nil {
+		return nil, fmt.Errorf("--validate-audit requires --validate")
+	}
+	audit, err := validator.OpenAuditLog(path)
+	if err != nil {
+		return nil, err
+	}
+	engine.SetAuditLog(audit)
+	return audit, nil
+}
+
+// closeValidationAudit closes an audit log, warning if entries were lost.
+func closeValidationAudit(audit *validator.AuditLog) {
+	if err := audit.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+}
+
+// openSIEMSink opens the SIEM event sink for a --siem target, or returns nil if unset.
+func openSIEMSink(target, formatName string) (*siem.Sink, error) {
+	if target == "" {
+		return nil, nil
+	}
+	format, err := siem.ParseFormat(formatName)
+	if err != nil {
+		return nil, err
+	}
+	sink, err := siem.Open(target, format)
+	if err != nil {
+		return nil, fmt.Errorf("opening SIEM sink: %w", err)
+	}
+	return sink, nil
+}
+
+// emitSIEMEvents sends one event per match to the SIEM sink. Delivery errors are
+// reported but never abort the scan.
+func emitSIEMEvents(sink *siem.Sink, matches []*types.Match, prov types.Provenance) {
+	if sink == nil {
+		return
+	}
+	for _, m := range matches {
+		if err := sink.Emit(siem.NewEvent(m, prov)); err != nil {
+			fmt.Fprintf(os.Stderr, "[warn] SIEM delivery failed: %v\n", err)
+		}
+	}
+}
+
 // resolveAutoName picks the best name for auto output from the available identifiers.
 // Priority: group/org > user > project/repo argument > fallback "output".
 func resolveAutoName(group, user, project string) string {
@@ -1010,6 +2043,10 @@ func resolveAutoName(group, user, project string) string {
 // For repo URLs (github.com/ or gitlab.com/), it extracts the repo name.
 // For filesystem paths, it uses the base name of the path.
 func resolveAutoOutput(target string) string {
+	if rt, ok := parseSSHRepoURL(target); ok {
+		return rt.Repo + ".ds"
+	}
+
 	// Strip scheme prefix (e.g. "https://")
 	cleaned := target
 	if idx := strings.Index(cleaned, "://"); idx >= 0 {
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/style"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// secretGroup is the findings of every rule that captured the same secret
+// (see types.SecretValue), reported as one entry by --group-by secret.
+type secretGroup struct {
+	Secret    string           `json:"secret"`
+	RuleIDs   []string         `json:"rule_ids"`
+	RuleNames []string         `json:"rule_names"`
+	Findings  []*types.Finding `json:"findings"`
+}
+
+// groupFindingsBySecret groups findings by secret value, keeping the order
+// of each group's first finding. Findings without capture groups each make
+// their own group.
+func groupFindingsBySecret(findings []*types.Finding, ruleMap map[string]*types.Rule) []*secretGroup {
+	var groups []*secretGroup
+	bySecret := make(map[string]*secretGroup)
+	for _, f := range findings {
+		secret := string(types.SecretValue(f.Groups))
+		g := bySecret[secret]
+		if g == nil || secret == "" {
+			g = &secretGroup{Secret: secret}
+			groups = append(groups, g)
+			if secret != "" {
+				bySecret[secret] = g
+			}
+		}
+		g.Findings = append(g.Findings, f)
+		if !containsString(g.RuleIDs, f.RuleID) {
+			name := f.RuleID
+			if r, ok := ruleMap[f.RuleID]; ok {
+				name = r.Name
+			}
+			g.RuleIDs = append(g.RuleIDs, f.RuleID)
+			g.RuleNames = append(g.RuleNames, name)
+		}
+	}
+	return groups
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// outputSecretGroupsJSON writes the groups with each finding's matches
+// attached.
+func outputSecretGroupsJSON(out io.Writer, groups []*secretGroup, matchesByFinding map[string][]*types.Match) error {
+	for _, g := range groups {
+		for _, f := range g.Findings {
+			f.Matches = matchesByFinding[f.ID]
+		}
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(groups)
+}
+
+// outputSecretGroupsHuman writes each secret once, followed by every rule
+// that caught it and every place it was found.
+func outputSecretGroupsHuman(out io.Writer, s store.Store, groups []*secretGroup, matchesByFinding map[string][]*types.Match, ruleMap map[string]*types.Rule) error {
+	st := style.New(colorEnabled)
+	var findings []*types.Finding
+	for _, g := range groups {
+		findings = append(findings, g.Findings...)
+	}
+	outputActiveSecrets(out, s, st, findings, matchesByFinding, ruleMap)
+
+	for i, g := range groups {
+		fmt.Fprintf(out, "%s %s\n",
+			st.FindingHeading.Sprintf("Secret %d/%d", i+1, len(groups)),
+			st.Metadata.Sprintf("(%d findings)", len(g.Findings)))
+		fmt.Fprintf(out, "%s %s\n", st.Heading.Sprint("Value:"), st.Match.Sprint(g.Secret))
+		fmt.Fprintf(out, "%s %s\n", st.Heading.Sprint("Rules:"), st.RuleName.Sprint(strings.Join(g.RuleNames, ", ")))
+
+		for _, f := range g.Findings {
+			ruleName := f.RuleID
+			if r, ok := ruleMap[f.RuleID]; ok {
+				ruleName = r.Name
+			}
+			fmt.Fprintf(out, "\n    %s (%s %s)\n",
+				st.RuleName.Sprint(ruleName),
+				st.Heading.Sprint("id"),
+				st.ID.Sprint(f.ID))
+			for _, m := range matchesByFinding[f.ID] {
+				place := m.BlobID.Hex()
+				if prov, err := s.GetProvenance(m.BlobID); err == nil && prov != nil && prov.Path() != "" {
+					place = prov.Path()
+				}
+				if m.Location.Source.Start.Line > 0 {
+					place = fmt.Sprintf("%s:%d", place, m.Location.Source.Start.Line)
+				}
+				fmt.Fprintf(out, "    %s %s\n", st.Heading.Sprint("File:"), st.Metadata.Sprint(place))
+				if len(m.Locations) > 1 {
+					for _, loc := range m.Locations[1:] {
+						fmt.Fprintf(out, "    %s %s\n", st.Heading.Sprint("Also:"), st.Metadata.Sprint(loc))
+					}
+				}
+			}
+		}
+		fmt.Fprintf(out, "\n\n")
+	}
+	return nil
+}
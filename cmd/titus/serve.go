@@ -7,8 +7,13 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/praetorian-inc/titus/pkg/enum"
+	"github.com/praetorian-inc/titus/pkg/matcher"
+	"github.com/praetorian-inc/titus/pkg/metrics"
+	"github.com/praetorian-inc/titus/pkg/rule"
 	"github.com/praetorian-inc/titus/pkg/scanner"
 	"github.com/praetorian-inc/titus/pkg/serve"
+	"github.com/praetorian-inc/titus/pkg/types"
 	"github.com/praetorian-inc/titus/pkg/validator"
 	"github.com/spf13/cobra"
 )
@@ -21,17 +26,60 @@ via stdin and outputs findings via stdout using NDJSON format.
 
 This mode is designed for integration with the Burp Suite extension.
 The process loads rules once at startup and processes requests until
-stdin closes or SIGTERM is received.`,
+stdin closes or SIGTERM is received.
+
+Use --metrics-addr to expose Prometheus metrics (blobs, bytes, matches by
+rule, validation results, scan durations) over HTTP at /metrics.
+
+All builtin rules are loaded unless --rules or --rule-pack-dir is given. With
+--rules-reload, those files are polled for changes; new rules are compiled in
+the background and swapped in between requests, and a rule set that fails to
+load or compile is reported on stderr while the current rules stay in use.`,
 	RunE: runServe,
 }
 
+var (
+	serveMetricsAddr  string
+	serveRulesPath    string
+	serveRulePackDirs []string
+	serveRulesReload  bool
+)
+
 func init() {
+	serveCmd.Flags().StringVar(&serveMetricsAddr, "metrics-addr", "", "Expose Prometheus metrics at http://<addr>/metrics (e.g., :9090; empty = disabled)")
+	serveCmd.Flags().StringVar(&serveRulesPath, "rules", "", "Path to custom rules file, or https:// URL of a rule pack .tar.gz")
+	serveCmd.Flags().StringSliceVar(&serveRulePackDirs, "rule-pack-dir", nil, "Directory containing an external rule pack (pack.yml plus rule files; repeatable)")
+	serveCmd.Flags().BoolVar(&serveRulesReload, "rules-reload", false, "Reload rules when the --rules file or --rule-pack-dir directories change")
 	rootCmd.AddCommand(serveCmd)
 }
 
 func runServe(cmd *cobra.Command, args []string) error {
-	// Create scanner core with builtin rules
-	core, err := scanner.NewCore("builtin", nil)
+	var reloadFrom []string
+	if serveRulesReload {
+		var err error
+		if reloadFrom, err = reloadPaths(serveRulesPath, serveRulePackDirs); err != nil {
+			return err
+		}
+	}
+
+	// Create scanner core with builtin rules, unless others are given
+	warnf := func(format string, args ...any) {
+		fmt.Fprintf(os.Stderr, format, args...)
+	}
+	loadServeRules := func() ([]*types.Rule, error) {
+		return loadRuleSelection(serveRulesPath, rule.FilterConfig{}, "all", "", serveRulePackDirs)
+	}
+	var core *scanner.Core
+	var err error
+	if serveRulesPath == "" && len(serveRulePackDirs) == 0 {
+		core, err = scanner.NewCore("builtin", nil)
+	} else {
+		var rules []*types.Rule
+		if rules, err = loadServeRules(); err != nil {
+			return fmt.Errorf("loading rules: %w", err)
+		}
+		core, err = scanner.NewCoreWithRules(rules, nil, warnf)
+	}
 	if err != nil {
 		return err
 	}
@@ -52,6 +100,22 @@ func runServe(cmd *cobra.Command, args []string) error {
 	// Create and run server
 	srv := serve.NewServer(core, cmd.InOrStdin(), cmd.OutOrStdout())
 	srv.SetValidator(initServeValidators())
+	if reg := startMetricsServer(ctx, serveMetricsAddr); reg != nil {
+		srv.SetMetrics(reg)
+	}
+	if serveRulesReload {
+		r := &ruleReloader{
+			paths:    reloadFrom,
+			interval: enum.DefaultWatchInterval,
+			load:     loadServeRules,
+			compile: func(rules []*types.Rule) (matcher.Matcher, error) {
+				return scanner.NewMatcher(rules, warnf)
+			},
+			publish: func(rs *ruleSet) { core.SetMatcher(rs.matcher) },
+			log:     os.Stderr,
+		}
+		go r.run(ctx)
+	}
 	return srv.Run(ctx)
 }
 
@@ -75,3 +139,19 @@ func initServeValidators() *validator.Engine {
 
 	return validator.NewEngine(4, validators...)
 }
+
+// startMetricsServer starts the /metrics endpoint in the background and returns
+// its registry, or nil when addr is empty. Listener errors are reported on stderr
+// so they never interfere with the stdout protocol.
+func startMetricsServer(ctx context.Context, addr string) *metrics.Registry {
+	if addr == "" {
+		return nil
+	}
+	reg := metrics.New()
+	go func() {
+		if err := metrics.Serve(ctx, addr, reg); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: metrics server on %s failed: %v\n", addr, err)
+		}
+	}()
+	return reg
+}
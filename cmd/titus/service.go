@@ -0,0 +1,482 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Service platforms install-service generates definitions for.
+const (
+	platformSystemd = "systemd"
+	platformWindows = "windows"
+)
+
+var (
+	serviceName     string
+	serviceMode     string
+	serviceInterval time.Duration
+	serviceOutput   string
+	servicePlatform string
+	serviceBinary   string
+	serviceUnitDir  string
+	servicePrint    bool
+)
+
+var installServiceCmd = &cobra.Command{
+	Use:   "install-service [flags] <path>... [-- <titus flags>]",
+	Short: "Set up continuous scanning as a systemd unit, Windows service or scheduled task",
+	Long: `Install titus to scan an endpoint continuously, in one of three modes:
+
+  scan     periodic incremental scans of one or more paths: a systemd service
+           run by a timer, or a Windows scheduled task, every --interval
+  watch    'titus watch' on one directory: a systemd service or Windows
+           service that scans files as they change
+  monitor  'titus monitor' with a config file: a systemd service or Windows
+           service that re-scans repositories on the config's schedule
+
+Findings are written to --output (default: /var/lib/<name>/titus.ds with
+systemd, %ProgramData%\<name>\titus.ds on Windows). Flags after -- are passed
+to the scan, watch or monitor command.
+
+With systemd, the units are written to --unit-dir and the commands that
+enable them are printed. On Windows, the scheduled task runs as SYSTEM and
+the service starts automatically, restarting if it fails. Installing needs
+root or an elevated prompt. --print writes the definitions to stdout
+instead, for any --platform, e.g. to deploy them with configuration
+management.
+
+Examples:
+  sudo titus install-service /home /srv -- --git --validate
+  sudo titus install-service --mode watch --name titus-uploads /srv/uploads
+  titus install-service --mode monitor monitor.yaml
+  titus install-service --print --platform windows --binary 'C:\Tools\titus.exe' 'C:\Users'`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runInstallService,
+}
+
+func init() {
+	installServiceCmd.Flags().StringVar(&serviceName, "name", "titus", "Name of the systemd units, Windows service or scheduled task")
+	installServiceCmd.Flags().StringVar(&serviceMode, "mode", "scan", "What to run: scan (periodic scans), watch (watch a directory) or monitor (titus monitor with a config file)")
+	installServiceCmd.Flags().DurationVar(&serviceInterval, "interval", 24*time.Hour, "Time between scans in scan mode")
+	installServiceCmd.Flags().StringVar(&serviceOutput, "output", "", "Datastore the scans write to (default: in /var/lib/<name> or %ProgramData%\\<name>)")
+	installServiceCmd.Flags().StringVar(&servicePlatform, "platform", defaultServicePlatform(), "Service platform: systemd or windows")
+	installServiceCmd.Flags().StringVar(&serviceBinary, "binary", "", "Path of the titus binary to run (default: this executable)")
+	installServiceCmd.Flags().StringVar(&serviceUnitDir, "unit-dir", "/etc/systemd/system", "Directory the systemd units are written to")
+	installServiceCmd.Flags().BoolVar(&servicePrint, "print", false, "Print the definitions instead of installing them")
+
+	rootCmd.AddCommand(installServiceCmd)
+}
+
+// defaultServicePlatform returns the service platform of this system.
+func defaultServicePlatform() string {
+	if runtime.GOOS == "windows" {
+		return platformWindows
+	}
+	return platformSystemd
+}
+
+// serviceSpec describes what install-service sets up.
+type serviceSpec struct {
+	name     string
+	mode     string   // "scan", "watch" or "monitor"
+	platform string   // platformSystemd or platformWindows
+	binary   string   // titus binary
+	targets  []string // paths to scan or watch, or the monitor config
+	output   string   // datastore
+	interval time.Duration
+	extra    []string // flags passed to the command
+}
+
+// periodic reports whether the spec runs scans on a schedule rather than
+// as a long-running service.
+func (s *serviceSpec) periodic() bool {
+	return s.mode == "scan"
+}
+
+// invocations returns the titus arguments of each command the service runs.
+func (s *serviceSpec) invocations() [][]string {
+	var invs [][]string
+	for _, target := range s.targets {
+		var args []string
+		switch s.mode {
+		case "scan":
+			args = []string{"scan", target, "--incremental", "--output", s.output}
+		case "watch":
+			args = []string{"watch", target, "--output", s.output}
+		case "monitor":
+			args = []string{"monitor", "--config", target, "--output", s.output}
+		}
+		invs = append(invs, append(args, s.extra...))
+	}
+	return invs
+}
+
+// description returns a one-line description of the service.
+func (s *serviceSpec) description() string {
+	switch s.mode {
+	case "scan":
+		return fmt.Sprintf("Titus secrets scan of %s", strings.Join(s.targets, ", "))
+	case "watch":
+		return fmt.Sprintf("Titus secrets scanning of changes in %s", s.targets[0])
+	default:
+		return fmt.Sprintf("Titus repository monitoring with %s", s.targets[0])
+	}
+}
+
+func runInstallService(cmd *cobra.Command, args []string) error {
+	targets, extra := args, []string(nil)
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		targets, extra = args[:dash], args[dash:]
+	}
+	spec, err := newServiceSpec(targets, extra)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if servicePrint {
+		return printService(out, spec)
+	}
+	if spec.platform != defaultServicePlatform() || (spec.platform == platformSystemd && runtime.GOOS != "linux") {
+		return fmt.Errorf("can't install a %s service on %s; use --print to write its definition", spec.platform, runtime.GOOS)
+	}
+	if spec.platform == platformWindows {
+		return installWindowsService(out, spec)
+	}
+	return installSystemd(out, spec, serviceUnitDir)
+}
+
+// newServiceSpec builds the spec from the install-service flags. Paths are
+// made absolute when the service is for this system.
+func newServiceSpec(targets, extra []string) (*serviceSpec, error) {
+	spec := &serviceSpec{
+		name:     serviceName,
+		mode:     serviceMode,
+		platform: servicePlatform,
+		binary:   serviceBinary,
+		output:   serviceOutput,
+		interval: serviceInterval,
+		extra:    extra,
+	}
+	if spec.name == "" || strings.ContainsAny(spec.name, `/\ "'`) {
+		return nil, fmt.Errorf("invalid --name %q", spec.name)
+	}
+	switch spec.mode {
+	case "scan":
+		if len(targets) == 0 {
+			return nil, fmt.Errorf("scan mode needs at least one path")
+		}
+		if spec.interval < time.Minute {
+			return nil, fmt.Errorf("--interval must be at least 1m")
+		}
+	case "watch":
+		if len(targets) != 1 {
+			return nil, fmt.Errorf("watch mode takes one directory; install a service with another --name for each")
+		}
+	case "monitor":
+		if len(targets) != 1 {
+			return nil, fmt.Errorf("monitor mode takes one config file")
+		}
+	default:
+		return nil, fmt.Errorf("unknown --mode %q: want scan, watch or monitor", spec.mode)
+	}
+	if spec.platform != platformSystemd && spec.platform != platformWindows {
+		return nil, fmt.Errorf("unknown --platform %q: want systemd or windows", spec.platform)
+	}
+
+	local := spec.platform == defaultServicePlatform()
+	for _, t := range targets {
+		if local {
+			abs, err := filepath.Abs(t)
+			if err != nil {
+				return nil, fmt.Errorf("resolving %s: %w", t, err)
+			}
+			if _, err := os.Stat(abs); err != nil {
+				return nil, fmt.Errorf("target does not exist: %s", t)
+			}
+			t = abs
+		}
+		spec.targets = append(spec.targets, t)
+	}
+
+	if spec.binary == "" {
+		if !local {
+			return nil, fmt.Errorf("--binary is required for a %s service built on %s", spec.platform, runtime.GOOS)
+		}
+		exe, err := os.Executable()
+		if err != nil {
+			return nil, fmt.Errorf("finding the titus binary (set --binary): %w", err)
+		}
+		spec.binary = exe
+	}
+
+	if spec.output == "" {
+		spec.output = defaultServiceDatastore(spec.platform, spec.name)
+	} else if local {
+		abs, err := filepath.Abs(spec.output)
+		if err != nil {
+			return nil, fmt.Errorf("resolving %s: %w", spec.output, err)
+		}
+		spec.output = abs
+	}
+	return spec, nil
+}
+
+// defaultServiceDatastore returns the datastore path of a service that
+// doesn't set --output.
+func defaultServiceDatastore(platform, name string) string {
+	if platform == platformWindows {
+		programData := os.Getenv("ProgramData")
+		if programData == "" {
+			programData = `C:\ProgramData`
+		}
+		return programData + `\` + name + `\titus.ds`
+	}
+	return "/var/lib/" + name + "/titus.ds"
+}
+
+// printService writes the definitions of spec, and how to install them.
+func printService(w io.Writer, spec *serviceSpec) error {
+	if spec.platform == platformSystemd {
+		for _, u := range systemdUnits(spec) {
+			fmt.Fprintf(w, "# %s\n%s\n", u.name, u.content)
+		}
+		fmt.Fprintf(w, "# Install with:\n#   systemctl daemon-reload\n#   systemctl enable --now %s\n", systemdEnableUnit(spec))
+		return nil
+	}
+	if spec.periodic() {
+		task, err := windowsTaskXML(spec, time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s\n", task)
+		fmt.Fprintf(w, "<!-- Install with: schtasks /Create /TN %s /XML <this file> /F -->\n", spec.name)
+		return nil
+	}
+	fmt.Fprintf(w, "New-Service -Name %s -BinaryPathName %s -DisplayName %s -Description %s -StartupType Automatic\n",
+		spec.name, powershellQuote(windowsCommandLine(spec.binary, spec.invocations()[0])),
+		powershellQuote("Titus ("+spec.mode+")"), powershellQuote(spec.description()))
+	fmt.Fprintf(w, "sc.exe failure %s reset= 86400 actions= restart/60000\n", spec.name)
+	fmt.Fprintf(w, "Start-Service -Name %s\n", spec.name)
+	return nil
+}
+
+// systemdUnit is a unit file.
+type systemdUnit struct {
+	name    string
+	content string
+}
+
+// systemdUnits returns the unit files of spec: a service, and a timer that
+// starts it in scan mode.
+func systemdUnits(spec *serviceSpec) []systemdUnit {
+	var svc strings.Builder
+	fmt.Fprintf(&svc, "[Unit]\nDescription=%s\n", spec.description())
+	if spec.mode == "monitor" {
+		svc.WriteString("Wants=network-online.target\nAfter=network-online.target\n")
+	}
+	svc.WriteString("\n[Service]\n")
+	if spec.periodic() {
+		svc.WriteString("Type=oneshot\n")
+	} else {
+		svc.WriteString("Type=simple\nRestart=on-failure\nRestartSec=30\n")
+	}
+	if spec.output == defaultServiceDatastore(platformSystemd, spec.name) {
+		fmt.Fprintf(&svc, "StateDirectory=%s\n", spec.name)
+	}
+	for _, inv := range spec.invocations() {
+		svc.WriteString("ExecStart=" + systemdQuote(spec.binary))
+		for _, arg := range inv {
+			svc.WriteString(" " + systemdQuote(arg))
+		}
+		svc.WriteString("\n")
+	}
+	if spec.periodic() {
+		svc.WriteString("Nice=10\nIOSchedulingClass=idle\n")
+	} else {
+		svc.WriteString("\n[Install]\nWantedBy=multi-user.target\n")
+	}
+	units := []systemdUnit{{spec.name + ".service", svc.String()}}
+
+	if spec.periodic() {
+		timer := fmt.Sprintf("[Unit]\nDescription=Run %[1]s.service every %[2]s\n\n[Timer]\nOnBootSec=5min\nOnUnitActiveSec=%[2]s\nRandomizedDelaySec=5min\n\n[Install]\nWantedBy=timers.target\n",
+			spec.name, systemdTimespan(spec.interval))
+		units = append(units, systemdUnit{spec.name + ".timer", timer})
+	}
+	return units
+}
+
+// systemdEnableUnit returns the unit to enable: the timer in scan mode,
+// else the service.
+func systemdEnableUnit(spec *serviceSpec) string {
+	if spec.periodic() {
+		return spec.name + ".timer"
+	}
+	return spec.name + ".service"
+}
+
+// systemdQuote quotes an ExecStart argument, escaping the specifiers (%)
+// and variables ($) systemd would expand.
+func systemdQuote(arg string) string {
+	arg = strings.NewReplacer("%", "%%", "$", "$$").Replace(arg)
+	if arg != "" && !strings.ContainsAny(arg, " \t\n\"'\\;") {
+		return arg
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(arg) + `"`
+}
+
+// systemdTimespan formats d as a systemd time span, e.g. 1h30min.
+func systemdTimespan(d time.Duration) string {
+	d = d.Round(time.Second)
+	var b strings.Builder
+	for _, u := range []struct {
+		unit time.Duration
+		name string
+	}{{time.Hour, "h"}, {time.Minute, "min"}, {time.Second, "s"}} {
+		if n := d / u.unit; n > 0 {
+			fmt.Fprintf(&b, "%d%s", n, u.name)
+			d -= n * u.unit
+		}
+	}
+	return b.String()
+}
+
+// installSystemd writes the units of spec to dir and prints how to enable
+// them. Existing units of the same name are replaced.
+func installSystemd(w io.Writer, spec *serviceSpec, dir string) error {
+	for _, u := range systemdUnits(spec) {
+		path := filepath.Join(dir, u.name)
+		if err := os.WriteFile(path, []byte(u.content), 0o644); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+		fmt.Fprintf(w, "Wrote %s\n", path)
+	}
+	fmt.Fprintf(w, "Enable it with:\n  systemctl daemon-reload\n  systemctl enable --now %s\n", systemdEnableUnit(spec))
+	return nil
+}
+
+// Task Scheduler XML, for the scheduled task of scan mode.
+type (
+	taskXML struct {
+		XMLName     xml.Name        `xml:"Task"`
+		Version     string          `xml:"version,attr"`
+		Xmlns       string          `xml:"xmlns,attr"`
+		Description string          `xml:"RegistrationInfo>Description"`
+		Trigger     taskTimeTrigger `xml:"Triggers>TimeTrigger"`
+		Principal   taskPrincipal   `xml:"Principals>Principal"`
+		Settings    taskSettings    `xml:"Settings"`
+		Actions     []taskExec      `xml:"Actions>Exec"`
+	}
+	taskTimeTrigger struct {
+		Interval      string `xml:"Repetition>Interval"`
+		StartBoundary string `xml:"StartBoundary"`
+		Enabled       bool   `xml:"Enabled"`
+	}
+	taskPrincipal struct {
+		ID       string `xml:"id,attr"`
+		UserID   string `xml:"UserId"`
+		RunLevel string `xml:"RunLevel"`
+	}
+	taskSettings struct {
+		MultipleInstancesPolicy    string `xml:"MultipleInstancesPolicy"`
+		DisallowStartIfOnBatteries bool   `xml:"DisallowStartIfOnBatteries"`
+		StopIfGoingOnBatteries     bool   `xml:"StopIfGoingOnBatteries"`
+		StartWhenAvailable         bool   `xml:"StartWhenAvailable"`
+		ExecutionTimeLimit         string `xml:"ExecutionTimeLimit"`
+		Priority                   int    `xml:"Priority"`
+	}
+	taskExec struct {
+		Command   string `xml:"Command"`
+		Arguments string `xml:"Arguments"`
+	}
+)
+
+// windowsTaskXML returns the Task Scheduler definition of spec: one action
+// per path, run as SYSTEM every interval from start.
+func windowsTaskXML(spec *serviceSpec, start time.Time) (string, error) {
+	task := taskXML{
+		Version:     "1.2",
+		Xmlns:       "http://schemas.microsoft.com/windows/2004/02/mit/task",
+		Description: spec.description(),
+		Trigger: taskTimeTrigger{
+			Interval:      fmt.Sprintf("PT%dM", int(spec.interval/time.Minute)),
+			StartBoundary: start.Truncate(time.Minute).Format("2006-01-02T15:04:05"),
+			Enabled:       true,
+		},
+		Principal: taskPrincipal{ID: "Author", UserID: "S-1-5-18", RunLevel: "HighestAvailable"},
+		Settings: taskSettings{
+			MultipleInstancesPolicy: "IgnoreNew",
+			StartWhenAvailable:      true,
+			ExecutionTimeLimit:      "PT0S",
+			Priority:                7,
+		},
+	}
+	for _, inv := range spec.invocations() {
+		task.Actions = append(task.Actions, taskExec{
+			Command:   spec.binary,
+			Arguments: strings.TrimPrefix(windowsCommandLine("", inv), " "),
+		})
+	}
+	if len(task.Actions) > 32 {
+		return "", fmt.Errorf("a scheduled task runs at most 32 scans; split the paths across tasks with different --name")
+	}
+	out, err := xml.MarshalIndent(task, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encoding the task: %w", err)
+	}
+	return string(out), nil
+}
+
+// windowsCommandLine joins a program and its arguments into a Windows
+// command line, quoting them as CommandLineToArgvW parses them.
+func windowsCommandLine(program string, args []string) string {
+	var b strings.Builder
+	if program != "" {
+		b.WriteString(windowsQuote(program))
+	}
+	for _, arg := range args {
+		b.WriteString(" " + windowsQuote(arg))
+	}
+	return b.String()
+}
+
+// windowsQuote quotes a command line argument if it is empty or contains
+// spaces, tabs or quotes.
+func windowsQuote(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\"") {
+		return arg
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	backslashes := 0
+	for _, c := range arg {
+		switch c {
+		case '\\':
+			backslashes++
+			continue
+		case '"':
+			// Backslashes before a quote are escapes, so double them
+			b.WriteString(strings.Repeat(`\`, 2*backslashes+1))
+		default:
+			b.WriteString(strings.Repeat(`\`, backslashes))
+		}
+		backslashes = 0
+		b.WriteRune(c)
+	}
+	b.WriteString(strings.Repeat(`\`, 2*backslashes))
+	b.WriteByte('"')
+	return b.String()
+}
+
+// powershellQuote quotes s as a PowerShell string literal.
+func powershellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// runAsService reports whether titus was started by the Windows service
+// manager, which it never is here.
+func runAsService() (bool, error) {
+	return false, nil
+}
+
+func installWindowsService(w io.Writer, spec *serviceSpec) error {
+	return fmt.Errorf("installing a Windows service requires Windows")
+}
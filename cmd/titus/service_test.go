@@ -0,0 +1,155 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemdUnits_Scan(t *testing.T) {
+	spec := &serviceSpec{
+		name:     "titus",
+		mode:     "scan",
+		platform: platformSystemd,
+		binary:   "/usr/local/bin/titus",
+		targets:  []string{"/home", "/srv/my data"},
+		output:   "/var/lib/titus/titus.ds",
+		interval: 90 * time.Minute,
+		extra:    []string{"--git"},
+	}
+	units := systemdUnits(spec)
+	require.Len(t, units, 2)
+
+	assert.Equal(t, "titus.service", units[0].name)
+	svc := units[0].content
+	assert.Contains(t, svc, "Type=oneshot\n")
+	assert.Contains(t, svc, "StateDirectory=titus\n")
+	assert.Contains(t, svc, "ExecStart=/usr/local/bin/titus scan /home --incremental --output /var/lib/titus/titus.ds --git\n")
+	assert.Contains(t, svc, `ExecStart=/usr/local/bin/titus scan "/srv/my data" --incremental`)
+	assert.NotContains(t, svc, "[Install]")
+
+	assert.Equal(t, "titus.timer", units[1].name)
+	assert.Contains(t, units[1].content, "OnUnitActiveSec=1h30min\n")
+	assert.Contains(t, units[1].content, "WantedBy=timers.target\n")
+	assert.Equal(t, "titus.timer", systemdEnableUnit(spec))
+}
+
+func TestSystemdUnits_Watch(t *testing.T) {
+	spec := &serviceSpec{
+		name:     "titus-uploads",
+		mode:     "watch",
+		platform: platformSystemd,
+		binary:   "/usr/local/bin/titus",
+		targets:  []string{"/srv/uploads"},
+		output:   "/data/titus.ds",
+	}
+	units := systemdUnits(spec)
+	require.Len(t, units, 1)
+	svc := units[0].content
+	assert.Contains(t, svc, "Restart=on-failure\n")
+	assert.Contains(t, svc, "ExecStart=/usr/local/bin/titus watch /srv/uploads --output /data/titus.ds\n")
+	assert.Contains(t, svc, "WantedBy=multi-user.target\n")
+	assert.NotContains(t, svc, "StateDirectory", "a custom datastore isn't in the state directory")
+	assert.Equal(t, "titus-uploads.service", systemdEnableUnit(spec))
+}
+
+func TestSystemdQuote(t *testing.T) {
+	assert.Equal(t, "/srv/data", systemdQuote("/srv/data"))
+	assert.Equal(t, `"a b"`, systemdQuote("a b"))
+	assert.Equal(t, `"say \"hi\""`, systemdQuote(`say "hi"`))
+	assert.Equal(t, "100%%", systemdQuote("100%"))
+	assert.Equal(t, "$$HOME", systemdQuote("$HOME"))
+	assert.Equal(t, `""`, systemdQuote(""))
+}
+
+func TestWindowsQuote(t *testing.T) {
+	assert.Equal(t, `C:\Users`, windowsQuote(`C:\Users`))
+	assert.Equal(t, `"C:\Program Files\titus.exe"`, windowsQuote(`C:\Program Files\titus.exe`))
+	assert.Equal(t, `"C:\My Docs\\"`, windowsQuote(`C:\My Docs\`))
+	assert.Equal(t, `"say \"hi\""`, windowsQuote(`say "hi"`))
+	assert.Equal(t, `""`, windowsQuote(""))
+	assert.Equal(t, `"C:\Program Files\titus.exe" watch C:\data`, windowsCommandLine(`C:\Program Files\titus.exe`, []string{"watch", `C:\data`}))
+}
+
+func TestWindowsTaskXML(t *testing.T) {
+	spec := &serviceSpec{
+		name:     "titus",
+		mode:     "scan",
+		platform: platformWindows,
+		binary:   `C:\Program Files\titus\titus.exe`,
+		targets:  []string{`C:\Users`, `D:\Shared Files`},
+		output:   `C:\ProgramData\titus\titus.ds`,
+		interval: 6 * time.Hour,
+	}
+	task, err := windowsTaskXML(spec, time.Date(2026, 3, 1, 9, 30, 15, 0, time.Local))
+	require.NoError(t, err)
+	assert.Contains(t, task, "<Interval>PT360M</Interval>")
+	assert.Contains(t, task, "<StartBoundary>2026-03-01T09:30:00</StartBoundary>")
+	assert.Contains(t, task, "<UserId>S-1-5-18</UserId>")
+	assert.Equal(t, 2, strings.Count(task, "<Exec>"))
+	assert.Contains(t, task, `<Command>C:\Program Files\titus\titus.exe</Command>`)
+	assert.Contains(t, task, `<Arguments>scan &#34;D:\Shared Files&#34; --incremental --output C:\ProgramData\titus\titus.ds</Arguments>`)
+}
+
+func TestNewServiceSpec(t *testing.T) {
+	defer func(name, mode, platform, binary, output string, interval time.Duration) {
+		serviceName, serviceMode, servicePlatform, serviceBinary, serviceOutput, serviceInterval = name, mode, platform, binary, output, interval
+	}(serviceName, serviceMode, servicePlatform, serviceBinary, serviceOutput, serviceInterval)
+
+	dir := t.TempDir()
+	serviceName, serviceMode, servicePlatform, serviceBinary, serviceOutput, serviceInterval = "titus", "scan", platformSystemd, "", "", 24*time.Hour
+
+	spec, err := newServiceSpec([]string{dir}, []string{"--git"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{dir}, spec.targets)
+	assert.Equal(t, "/var/lib/titus/titus.ds", spec.output)
+	assert.NotEmpty(t, spec.binary)
+	assert.Equal(t, []string{"scan", dir, "--incremental", "--output", "/var/lib/titus/titus.ds", "--git"}, spec.invocations()[0])
+
+	_, err = newServiceSpec([]string{filepath.Join(dir, "missing")}, nil)
+	assert.ErrorContains(t, err, "does not exist")
+
+	serviceMode = "watch"
+	_, err = newServiceSpec([]string{dir, os.TempDir()}, nil)
+	assert.ErrorContains(t, err, "one directory")
+
+	serviceMode = "daemon"
+	_, err = newServiceSpec([]string{dir}, nil)
+	assert.ErrorContains(t, err, "unknown --mode")
+
+	// Definitions for another platform keep their paths, and need --binary
+	serviceMode, servicePlatform = "scan", platformWindows
+	if defaultServicePlatform() != platformWindows {
+		_, err = newServiceSpec([]string{`C:\Users`}, nil)
+		assert.ErrorContains(t, err, "--binary is required")
+
+		serviceBinary = `C:\Tools\titus.exe`
+		spec, err = newServiceSpec([]string{`C:\Users`}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{`C:\Users`}, spec.targets)
+		assert.True(t, strings.HasSuffix(spec.output, `\titus\titus.ds`), spec.output)
+	}
+}
+
+func TestInstallSystemd(t *testing.T) {
+	dir := t.TempDir()
+	spec := &serviceSpec{
+		name:     "titus",
+		mode:     "scan",
+		platform: platformSystemd,
+		binary:   "/usr/local/bin/titus",
+		targets:  []string{"/home"},
+		output:   "/var/lib/titus/titus.ds",
+		interval: 24 * time.Hour,
+	}
+	var out strings.Builder
+	require.NoError(t, installSystemd(&out, spec, dir))
+	assert.FileExists(t, filepath.Join(dir, "titus.service"))
+	assert.FileExists(t, filepath.Join(dir, "titus.timer"))
+	assert.Contains(t, out.String(), "systemctl enable --now titus.timer")
+}
@@ -0,0 +1,136 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+	"unicode/utf16"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// runAsService runs the command line of a service installed by
+// install-service under the service manager, if titus was started by it.
+// It reports whether it was.
+func runAsService() (bool, error) {
+	isService, err := svc.IsWindowsService()
+	if err != nil || !isService {
+		return false, err
+	}
+	return true, svc.Run("titus", serviceHandler{})
+}
+
+// serviceHandler runs the titus command line, canceling its context when
+// the service is stopped.
+type serviceHandler struct{}
+
+func (serviceHandler) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- rootCmd.ExecuteContext(ctx) }()
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				return true, 1
+			}
+			return false, 0
+		case r := <-requests:
+			switch r.Cmd {
+			case svc.Interrogate:
+				status <- r.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				cancel()
+				select {
+				case <-done:
+				case <-time.After(20 * time.Second):
+				}
+				return false, 0
+			}
+		}
+	}
+}
+
+// installWindowsService installs spec as a scheduled task in scan mode,
+// else as a service that starts automatically and restarts if it fails.
+func installWindowsService(w io.Writer, spec *serviceSpec) error {
+	if err := os.MkdirAll(filepath.Dir(spec.output), 0o700); err != nil {
+		return fmt.Errorf("creating datastore directory: %w", err)
+	}
+	if spec.periodic() {
+		return installScheduledTask(w, spec)
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connecting to the service manager (run from an elevated prompt): %w", err)
+	}
+	defer m.Disconnect()
+	if s, err := m.OpenService(spec.name); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s already exists; remove it with 'sc.exe delete %s' or choose another --name", spec.name, spec.name)
+	}
+	s, err := m.CreateService(spec.name, spec.binary, mgr.Config{
+		DisplayName: "Titus (" + spec.mode + ")",
+		Description: spec.description(),
+		StartType:   mgr.StartAutomatic,
+	}, spec.invocations()[0]...)
+	if err != nil {
+		return fmt.Errorf("creating service %s: %w", spec.name, err)
+	}
+	defer s.Close()
+	if err := s.SetRecoveryActions([]mgr.RecoveryAction{{Type: mgr.ServiceRestart, Delay: time.Minute}}, 24*60*60); err != nil {
+		return fmt.Errorf("setting the recovery actions of %s: %w", spec.name, err)
+	}
+	if err := s.Start(); err != nil {
+		return fmt.Errorf("starting service %s: %w", spec.name, err)
+	}
+	fmt.Fprintf(w, "Installed and started service %s\n", spec.name)
+	return nil
+}
+
+// installScheduledTask registers the scheduled task of spec with schtasks,
+// replacing a task of the same name.
+func installScheduledTask(w io.Writer, spec *serviceSpec) error {
+	task, err := windowsTaskXML(spec, time.Now())
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp("", "titus-task-*.xml")
+	if err != nil {
+		return fmt.Errorf("creating task file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	// schtasks reads task files as UTF-16
+	doc := "\ufeff<?xml version=\"1.0\" encoding=\"UTF-16\"?>\r\n" + task
+	buf := make([]byte, 0, 2*len(doc))
+	for _, u := range utf16.Encode([]rune(doc)) {
+		buf = append(buf, byte(u), byte(u>>8))
+	}
+	_, err = f.Write(buf)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("writing task file: %w", err)
+	}
+
+	out, err := exec.Command("schtasks", "/Create", "/TN", spec.name, "/XML", f.Name(), "/F").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("creating scheduled task %s: %w: %s", spec.name, err, out)
+	}
+	fmt.Fprintf(w, "Installed scheduled task %s, running every %s\n", spec.name, spec.interval)
+	return nil
+}
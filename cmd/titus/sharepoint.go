@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/praetorian-inc/titus/pkg/enum"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sharepointToken        string
+	sharepointGraphURL     string
+	sharepointMaxFileSize  int64
+	sharepointExtract      string
+	sharepointOutputPath   string
+	sharepointOutputFormat string
+)
+
+var sharepointCmd = &cobra.Command{
+	Use:   "sharepoint [site-url...]",
+	Short: "Scan SharePoint document libraries for secrets",
+	Long: `Scan the documents in SharePoint sites' document libraries via Microsoft Graph.
+Without site URLs, every site found by searching the tenant is scanned.
+
+Authentication:
+  A Microsoft Graph access token with Sites.Read.All (or Files.Read.All)
+  in --token or SHAREPOINT_TOKEN, e.g. from:
+    az account get-access-token --resource https://graph.microsoft.com --query accessToken -o tsv
+
+Findings name the document by its library URL, folder path and file name.
+
+Examples:
+  titus sharepoint --token $TOKEN https://contoso.sharepoint.com/sites/Finance
+  titus sharepoint --token $TOKEN --max-file-size 52428800`,
+	RunE: runSharePointScan,
+}
+
+func init() {
+	sharepointCmd.Flags().StringVar(&sharepointToken, "token", "", "Microsoft Graph access token (or SHAREPOINT_TOKEN env)")
+	sharepointCmd.Flags().StringVar(&sharepointGraphURL, "graph-url", enum.DefaultGraphURL, "Microsoft Graph API endpoint (for national clouds)")
+	sharepointCmd.Flags().Int64Var(&sharepointMaxFileSize, "max-file-size", 10*1024*1024, "Maximum document size to scan (bytes)")
+	sharepointCmd.Flags().StringVar(&sharepointExtract, "extract", "all", "Extract text from binary documents (extensions: xlsx,docx,pdf,zip or 'all'; empty to disable)")
+	sharepointCmd.Flags().StringVar(&sharepointOutputPath, "output", "titus.db", "Output database path (:memory: for in-memory)")
+	sharepointCmd.Flags().StringVar(&sharepointOutputFormat, "format", "human", "Output format: json, human")
+
+	rootCmd.AddCommand(sharepointCmd)
+}
+
+func runSharePointScan(cmd *cobra.Command, args []string) error {
+	token := sharepointToken
+	if token == "" {
+		token = os.Getenv("SHAREPOINT_TOKEN")
+	}
+	if token == "" {
+		return fmt.Errorf("SharePoint scanning requires a Microsoft Graph access token: use --token or SHAREPOINT_TOKEN")
+	}
+
+	e, err := enum.NewSharePointEnumerator(enum.SharePointConfig{
+		Token:   token,
+		BaseURL: sharepointGraphURL,
+		Sites:   args,
+		Config: enum.Config{
+			MaxFileSize:     sharepointMaxFileSize,
+			ExtractArchives: sharepointExtract,
+			ExtractLimits:   enum.DefaultExtractionLimits(),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("creating SharePoint client: %w", err)
+	}
+	return scanEnumerator(cmd, e, "SharePoint", sharepointOutputPath, sharepointOutputFormat)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/praetorian-inc/titus/pkg/signing"
+	"github.com/spf13/cobra"
+)
+
+// signingFlags holds the --sign-report flags of a command that writes a
+// report.
+type signingFlags struct {
+	enabled   bool
+	key       string
+	signature string
+}
+
+func (r *signingFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&r.enabled, "sign-report", false, "Write a detached signature over the report output and datastore (keyless with cosign unless --signing-key is given)")
+	cmd.Flags().StringVar(&r.key, "signing-key", "", "PEM Ed25519 or ECDSA private key for --sign-report")
+	cmd.Flags().StringVar(&r.signature, "signature", "titus-report.sig.json", "Where --sign-report writes the signature")
+}
+
+// wrap returns run with its report output captured and signed once it
+// returns, by which time the datastore is closed and its file final.
+// format and datastore point at the command's flag values, which run may
+// resolve.
+func (r *signingFlags) wrap(run func(*cobra.Command, []string) error, format, datastore *string) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if !r.enabled {
+			return run(cmd, args)
+		}
+		if *format == "human" || *format == githubSummaryFormat {
+			return fmt.Errorf("--sign-report needs a machine-readable --format, such as json or sarif")
+		}
+
+		var output bytes.Buffer
+		cmd.SetOut(io.MultiWriter(cmd.OutOrStdout(), &output))
+		if err := run(cmd, args); err != nil {
+			return err
+		}
+
+		st, err := signing.NewStatement(*format, output.Bytes(), datastoreFile(*datastore), "titus "+version)
+		if err != nil {
+			return err
+		}
+		var sig *signing.Signature
+		if r.key != "" {
+			keyPEM, err := os.ReadFile(r.key)
+			if err != nil {
+				return fmt.Errorf("reading signing key: %w", err)
+			}
+			sig, err = signing.SignWithKey(st, keyPEM)
+			if err != nil {
+				return err
+			}
+		} else {
+			sig, err = signing.SignKeyless(context.Background(), st)
+			if err != nil {
+				return err
+			}
+		}
+		if err := sig.Write(r.signature); err != nil {
+			return fmt.Errorf("writing signature: %w", err)
+		}
+		statusf(cmd.ErrOrStderr(), "Report signature written to %s\n", r.signature)
+		return nil
+	}
+}
+
+// datastoreFile returns the database file of a datastore path, or "" for
+// an in-memory store.
+func datastoreFile(path string) string {
+	if path == "" || path == ":memory:" {
+		return ""
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return filepath.Join(path, "datastore.db")
+	}
+	return path
+}
+
+var (
+	verifySignature      string
+	verifyKey            string
+	verifyDatastore      string
+	verifyCertIdentity   string
+	verifyCertOIDCIssuer string
+)
+
+var verifyReportCmd = &cobra.Command{
+	Use:   "verify-report <report-file>",
+	Short: "Verify a report signed with --sign-report",
+	Long: `Verify a report against the detached signature written by --sign-report.
+
+The signature is checked against the trusted signer: the public key given with
+--key, or for keyless signatures the signer identity and OIDC issuer, which
+cosign checks. The report file, and the datastore if --datastore is given,
+must be byte-for-byte the ones that were signed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runVerifyReport,
+}
+
+func init() {
+	verifyReportCmd.Flags().StringVar(&verifySignature, "signature", "titus-report.sig.json", "Signature file written by --sign-report")
+	verifyReportCmd.Flags().StringVar(&verifyKey, "key", "", "Trusted PEM public key, for signatures made with --signing-key")
+	verifyReportCmd.Flags().StringVar(&verifyDatastore, "datastore", "", "Also check the datastore the report was produced from")
+	verifyReportCmd.Flags().StringVar(&verifyCertIdentity, "certificate-identity", "", "Expected signer identity, for keyless signatures")
+	verifyReportCmd.Flags().StringVar(&verifyCertOIDCIssuer, "certificate-oidc-issuer", "", "Expected OIDC issuer, for keyless signatures")
+	rootCmd.AddCommand(verifyReportCmd)
+}
+
+func runVerifyReport(cmd *cobra.Command, args []string) error {
+	output, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("reading report: %w", err)
+	}
+	sig, err := signing.Read(verifySignature)
+	if err != nil {
+		return fmt.Errorf("reading signature: %w", err)
+	}
+
+	opts := signing.VerifyOptions{
+		CertificateIdentity:   verifyCertIdentity,
+		CertificateOIDCIssuer: verifyCertOIDCIssuer,
+	}
+	if verifyKey != "" {
+		if opts.PublicKeyPEM, err = os.ReadFile(verifyKey); err != nil {
+			return fmt.Errorf("reading public key: %w", err)
+		}
+	}
+
+	st, err := sig.Verify(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+	if err := st.Check(output, datastoreFile(verifyDatastore)); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Verified %s report signed %s by %s\n", st.Format, st.Created.Format("2006-01-02 15:04:05 MST"), st.Tool)
+	return nil
+}
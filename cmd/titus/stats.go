@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/enum"
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/style"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// scanStats is the end-of-scan summary written to --stats-file, for
+// orchestration systems that track scanner coverage and performance.
+type scanStats struct {
+	Target          string         `json:"target"`
+	StartedAt       time.Time      `json:"started_at"`
+	DurationSeconds float64        `json:"duration_seconds"`
+	Bytes           int64          `json:"bytes"`
+	Blobs           int64          `json:"blobs"`
+	SkippedBlobs    int64          `json:"skipped_blobs"`
+	LimitSkipped    int64          `json:"limit_skipped"`
+	Encrypted       int64          `json:"encrypted"`
+	Matches         int64          `json:"matches"`
+	NewMatches      int64          `json:"new_matches"`
+	NewFindings     int64          `json:"new_findings"`
+	Timeouts        int64          `json:"timeouts"`
+	BytesPerSecond  float64        `json:"bytes_per_second"`
+	RuleMatches     map[string]int `json:"rule_matches"`
+}
+
+// scanCounters tallies what a scan read and found. Scan workers update it
+// concurrently.
+type scanCounters struct {
+	bytes        atomic.Int64 // content enumerated
+	blobs        atomic.Int64 // blobs enumerated
+	skippedBlobs atomic.Int64 // blobs not matched again since they were already scanned
+	limitSkipped atomic.Int64 // archive members left out by the extraction limits
+	encrypted    atomic.Int64 // encrypted documents that couldn't be decrypted
+	matches      atomic.Int64 // matches reported in the blobs matched
+	newMatches   atomic.Int64 // of those, matches the datastore didn't hold yet
+	newFindings  atomic.Int64 // findings the datastore didn't hold yet
+	timeouts     atomic.Int64 // rules skipped for a blob on regex timeout
+}
+
+// stats returns the counts of a scan of target that started at started
+// and took duration.
+func (c *scanCounters) stats(target string, started time.Time, duration time.Duration, ruleMatches map[string]int) scanStats {
+	return scanStats{
+		Target:          target,
+		StartedAt:       started,
+		DurationSeconds: duration.Seconds(),
+		Bytes:           c.bytes.Load(),
+		Blobs:           c.blobs.Load(),
+		SkippedBlobs:    c.skippedBlobs.Load(),
+		LimitSkipped:    c.limitSkipped.Load(),
+		Encrypted:       c.encrypted.Load(),
+		Matches:         c.matches.Load(),
+		NewMatches:      c.newMatches.Load(),
+		NewFindings:     c.newFindings.Load(),
+		Timeouts:        c.timeouts.Load(),
+		RuleMatches:     ruleMatches,
+	}
+}
+
+// onSkip returns an enumerator hook that counts the archive members left
+// out by the extraction limits and the encrypted documents left out, and
+// warns on w of each that looks like an archive bomb.
+func (c *scanCounters) onSkip(w io.Writer) func(path, reason string) {
+	return func(path, reason string) {
+		if strings.HasPrefix(reason, enum.SkipEncrypted) {
+			c.encrypted.Add(1)
+			return
+		}
+		limit, bomb := enum.IsLimitSkip(reason)
+		if !limit {
+			return
+		}
+		c.limitSkipped.Add(1)
+		if bomb {
+			fmt.Fprintf(w, "warning: skipped %s: %s\n", path, reason)
+		}
+	}
+}
+
+// countNewMatches counts the reportable matches of a blob that the store
+// doesn't hold yet. Call it before the blob's matches are recorded.
+func countNewMatches(tx store.Store, ruleMap map[string]*types.Rule, blobID types.BlobID, matches []*types.Match) (int64, error) {
+	reported := reportableMatches(matches, ruleMap)
+	if len(reported) == 0 {
+		return 0, nil
+	}
+	stored, err := tx.GetMatches(blobID)
+	if err != nil {
+		return 0, fmt.Errorf("retrieving stored matches: %w", err)
+	}
+	seen := make(map[string]bool, len(stored))
+	for _, m := range stored {
+		seen[m.StructuralID] = true
+	}
+	var n int64
+	for _, m := range reported {
+		if !seen[m.StructuralID] {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// line is the one-line summary printed after a scan.
+func (st scanStats) line() string {
+	duration := time.Duration(st.DurationSeconds * float64(time.Second))
+	line := fmt.Sprintf("Scanned %s from %d blobs in %s", style.Bytes(st.Bytes), st.Blobs, formatDuration(duration))
+	if duration > 0 {
+		line += fmt.Sprintf(" (%s/s)", style.Bytes(int64(float64(st.Bytes)/duration.Seconds())))
+	}
+	if st.SkippedBlobs > 0 {
+		line += fmt.Sprintf(", %d already scanned", st.SkippedBlobs)
+	}
+	if st.LimitSkipped > 0 {
+		line += fmt.Sprintf(", %d archive members over extraction limits", st.LimitSkipped)
+	}
+	if st.Encrypted > 0 {
+		line += fmt.Sprintf(", %d encrypted documents not scanned", st.Encrypted)
+	}
+	return line + fmt.Sprintf("; %d/%d new matches, %d new findings\n", st.NewMatches, st.Matches, st.NewFindings)
+}
+
+// formatDuration formats d in the unit that suits it: 850 ms, 4.2 s, or
+// 3m12s from a minute up.
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%d ms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%.1f s", d.Seconds())
+	}
+	return d.Round(time.Second).String()
+}
+
+// writeStatsFile writes stats as JSON to path.
+func writeStatsFile(path string, stats scanStats) error {
+	if stats.DurationSeconds > 0 {
+		stats.BytesPerSecond = float64(stats.Bytes) / stats.DurationSeconds
+	}
+	if stats.RuleMatches == nil {
+		stats.RuleMatches = map[string]int{}
+	}
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding scan stats: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("writing stats file: %w", err)
+	}
+	return nil
+}
+
+// countTimeouts wraps a matcher WarnFunc to count the regex timeouts it
+// reports, each of which skipped a rule for a blob.
+func countTimeouts(warn func(format string, args ...any), timeouts *atomic.Int64) func(format string, args ...any) {
+	return func(format string, args ...any) {
+		if strings.Contains(format, "regex timeout") {
+			timeouts.Add(1)
+		}
+		warn(format, args...)
+	}
+}
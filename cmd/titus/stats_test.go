@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/enum"
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteStatsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.json")
+	started := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, writeStatsFile(path, scanStats{
+		Target:          "src",
+		StartedAt:       started,
+		DurationSeconds: 2,
+		Bytes:           4096,
+		Blobs:           3,
+		Matches:         2,
+		Timeouts:        1,
+		RuleMatches:     map[string]int{"np.aws.1": 2},
+	}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, "src", got["target"])
+	assert.Equal(t, "2024-06-01T12:00:00Z", got["started_at"])
+	assert.Equal(t, 4096.0, got["bytes"])
+	assert.Equal(t, 2048.0, got["bytes_per_second"])
+	assert.Equal(t, 1.0, got["timeouts"])
+	assert.Equal(t, map[string]any{"np.aws.1": 2.0}, got["rule_matches"])
+
+	require.NoError(t, writeStatsFile(path, scanStats{}))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"rule_matches": {}`)
+}
+
+func TestCountTimeouts(t *testing.T) {
+	var timeouts atomic.Int64
+	var warnings []string
+	warn := countTimeouts(func(format string, args ...any) { warnings = append(warnings, format) }, &timeouts)
+
+	warn("[warn] rule %s regex timeout on content (skipping rule for this blob)\n", "np.aws.1")
+	warn("[warn] rule %s failed to compile: %v\n", "np.bad.1", "error")
+	assert.Equal(t, int64(1), timeouts.Load())
+	assert.Len(t, warnings, 2, "warnings are still passed on")
+}
+
+func TestScanStatsLine(t *testing.T) {
+	st := scanStats{DurationSeconds: 0.25, Bytes: 3 << 20, Blobs: 40, SkippedBlobs: 5, Matches: 7, NewMatches: 3, NewFindings: 2}
+	assert.Equal(t, "Scanned 3.0 MiB from 40 blobs in 250 ms (12.0 MiB/s), 5 already scanned; 3/7 new matches, 2 new findings\n", st.line())
+
+	st = scanStats{Bytes: 512, Blobs: 1}
+	assert.Equal(t, "Scanned 512 B from 1 blobs in 0 ms; 0/0 new matches, 0 new findings\n", st.line())
+}
+
+func TestScanCountersOnSkip(t *testing.T) {
+	var counters scanCounters
+	var warnings bytes.Buffer
+	onSkip := counters.onSkip(&warnings)
+	onSkip("photo.raw", "binary")
+	onSkip("app.jar:big.bin", enum.SkipMaxSize)
+	onSkip("bomb.zip:zeros.txt", enum.SkipMaxRatio)
+	onSkip("budget.xlsx", enum.SkipEncrypted)
+
+	st := counters.stats("target", time.Time{}, 0, nil)
+	assert.Equal(t, int64(2), st.LimitSkipped)
+	assert.Equal(t, int64(1), st.Encrypted)
+	assert.Equal(t, "warning: skipped bomb.zip:zeros.txt: "+enum.SkipMaxRatio+"\n", warnings.String())
+	assert.Contains(t, st.line(), ", 2 archive members over extraction limits, 1 encrypted documents not scanned;")
+}
+
+func TestFormatDuration(t *testing.T) {
+	assert.Equal(t, "850 ms", formatDuration(850*time.Millisecond))
+	assert.Equal(t, "4.2 s", formatDuration(4200*time.Millisecond))
+	assert.Equal(t, "3m12s", formatDuration(3*time.Minute+12400*time.Millisecond))
+}
+
+func TestCountNewMatches(t *testing.T) {
+	s := store.NewMemory()
+	blobID := types.ComputeBlobID([]byte("content"))
+	ruleMap := map[string]*types.Rule{"np.aws.1": {ID: "np.aws.1"}, "np.aws.part": {ID: "np.aws.part", CorrelationOnly: true}}
+	stored := &types.Match{BlobID: blobID, RuleID: "np.aws.1", StructuralID: "old"}
+	require.NoError(t, s.AddMatch(stored))
+
+	n, err := countNewMatches(s, ruleMap, blobID, []*types.Match{
+		stored,
+		{BlobID: blobID, RuleID: "np.aws.1", StructuralID: "new"},
+		{BlobID: blobID, RuleID: "np.aws.part", StructuralID: "component"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n, "stored and correlation-only matches aren't counted")
+}
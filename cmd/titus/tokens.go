@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// tokenDiscoveryTimeout bounds each tool asked for a token, so a credential
+// helper waiting on a login doesn't hang the scan.
+var tokenDiscoveryTimeout = 5 * time.Second
+
+// resolveToken returns the API token for platform ("github" or "gitlab") at
+// host: flagValue if set, else the envVar environment variable, else unless
+// discovery is disabled a token the user is already logged in with.
+func resolveToken(w io.Writer, flagValue, envVar, platform, host string, discover bool) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if token := os.Getenv(envVar); token != "" {
+		return token
+	}
+	if !discover {
+		return ""
+	}
+	token, source := discoverToken(platform, host)
+	if token != "" {
+		statusf(w, "Using %s token for %s from %s\n", platformName(platform), host, source)
+	}
+	return token
+}
+
+// discoverToken asks the gh or glab CLI, then git's credential helpers, for
+// a token for host. It returns the token and where it came from, or "" if
+// none was found.
+func discoverToken(platform, host string) (token, source string) {
+	ctx, cancel := context.WithTimeout(context.Background(), tokenDiscoveryTimeout)
+	defer cancel()
+
+	switch platform {
+	case "github":
+		if token := tokenCommand(ctx, "gh", "auth", "token", "--hostname", host); token != "" {
+			return token, "gh auth token"
+		}
+	case "gitlab":
+		if token := tokenCommand(ctx, "glab", "config", "get", "token", "--host", host); token != "" {
+			return token, "glab config"
+		}
+	}
+	if token := gitCredential(ctx, host); token != "" {
+		return token, "git credential helper"
+	}
+	return "", ""
+}
+
+// tokenCommand runs a command that prints a token, returning "" if it fails
+// or prints anything else.
+func tokenCommand(ctx context.Context, name string, args ...string) string {
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return ""
+	}
+	token := strings.TrimSpace(string(out))
+	if strings.ContainsAny(token, " \t\n") {
+		return ""
+	}
+	return token
+}
+
+// gitCredential asks git's credential helpers for the HTTPS password of
+// host, without letting git or the helper prompt for one.
+func gitCredential(ctx context.Context, host string) string {
+	cmd := exec.CommandContext(ctx, "git", "credential", "fill")
+	cmd.Stdin = strings.NewReader("protocol=https\nhost=" + host + "\n\n")
+	cmd.Env = append(os.Environ(),
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_ASKPASS=",
+		"SSH_ASKPASS=",
+		"GCM_INTERACTIVE=never",
+	)
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		if password, ok := strings.CutPrefix(scanner.Text(), "password="); ok {
+			return password
+		}
+	}
+	return ""
+}
+
+// tokenHost returns the host of baseURL, or defaultHost if baseURL is empty
+// or has none.
+func tokenHost(baseURL, defaultHost string) string {
+	if baseURL == "" {
+		return defaultHost
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return defaultHost
+	}
+	return u.Host
+}
+
+func platformName(platform string) string {
+	switch platform {
+	case "github":
+		return "GitHub"
+	case "gitlab":
+		return "GitLab"
+	}
+	return platform
+}
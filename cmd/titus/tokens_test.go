@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTools puts stand-ins for gh, glab and git on PATH, and only them.
+func fakeTools(t *testing.T, scripts map[string]string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("stand-in tools are shell scripts")
+	}
+	dir := t.TempDir()
+	for name, body := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+body), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestResolveToken(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	fakeTools(t, map[string]string{
+		"gh":  `[ "$*" = "auth token --hostname github.com" ] && echo gho_fromgh`,
+		"git": `[ "$*" = "credential fill" ] || exit 1; cat >/dev/null; printf 'protocol=https\nhost=ghe.corp.com\nusername=me\npassword=ghp_fromhelper\n'`,
+	})
+
+	var out bytes.Buffer
+	assert.Equal(t, "flag", resolveToken(&out, "flag", "GITHUB_TOKEN", "github", "github.com", true))
+	assert.Empty(t, out.String())
+
+	assert.Equal(t, "gho_fromgh", resolveToken(&out, "", "GITHUB_TOKEN", "github", "github.com", true))
+	assert.Contains(t, out.String(), "Using GitHub token for github.com from gh auth token")
+
+	// gh has no token for this host, so the credential helper is asked
+	out.Reset()
+	assert.Equal(t, "ghp_fromhelper", resolveToken(&out, "", "GITHUB_TOKEN", "github", "ghe.corp.com", true))
+	assert.Contains(t, out.String(), "from git credential helper")
+
+	assert.Empty(t, resolveToken(&out, "", "GITHUB_TOKEN", "github", "github.com", false))
+
+	t.Setenv("GITHUB_TOKEN", "env")
+	assert.Equal(t, "env", resolveToken(&out, "", "GITHUB_TOKEN", "github", "github.com", true))
+}
+
+func TestResolveToken_GitLab(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "")
+	fakeTools(t, map[string]string{
+		"glab": `[ "$*" = "config get token --host gitlab.example.com" ] && echo glpat-fromglab`,
+		"git":  `exit 128`,
+	})
+
+	var out bytes.Buffer
+	assert.Equal(t, "glpat-fromglab", resolveToken(&out, "", "GITLAB_TOKEN", "gitlab", "gitlab.example.com", true))
+	assert.Empty(t, resolveToken(&out, "", "GITLAB_TOKEN", "gitlab", "gitlab.com", true), "no tool has a token")
+}
+
+func TestTokenHost(t *testing.T) {
+	assert.Equal(t, "github.com", tokenHost("", "github.com"))
+	assert.Equal(t, "ghe.corp.com", tokenHost("https://ghe.corp.com/api/v3", "github.com"))
+	assert.Equal(t, "gitlab.com", tokenHost("not a url", "gitlab.com"))
+}
@@ -3,12 +3,18 @@ package main
 import (
 	"fmt"
 
+	"github.com/praetorian-inc/titus/pkg/siem"
 	"github.com/spf13/cobra"
 )
 
 // version is set at build time via -ldflags "-X main.version=..."
 var version = "dev"
 
+func init() {
+	// SIEM events name the version that produced them
+	siem.Version = version
+}
+
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Show version information",
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/praetorian-inc/titus/pkg/siem"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -26,3 +27,7 @@ func TestRunVersion(t *testing.T) {
 	assert.Contains(t, output, "Titus")
 	assert.Contains(t, output, version)
 }
+
+func TestSIEMVersion(t *testing.T) {
+	assert.Equal(t, version, siem.Version)
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/enum"
+	"github.com/praetorian-inc/titus/pkg/matcher"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/praetorian-inc/titus/pkg/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchOutputPath        string
+	watchInterval          time.Duration
+	watchPoll              bool
+	watchInitialScan       bool
+	watchRulesPath         string
+	watchRulesInclude      string
+	watchRulesExclude      string
+	watchCategories        string
+	watchExcludeCategories string
+	watchRuleset           string
+	watchValidate          bool
+	watchValidateAudit     string
+	watchValidateTimeouts  []string
+	watchValidateBreaker   int
+	watchMaxFileSize       int64
+	watchContextLines      int
+	watchContextBytes      int
+	watchIgnoreFile        string
+	watchSIEMTarget        string
+	watchSIEMFormat        string
+	watchMetricsAddr       string
+	watchRulePacks         string
+	watchRulePackDirs      []string
+	watchRulesReload       bool
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch <directory>",
+	Short: "Watch a directory and scan files as they change",
+	Long: `Run as a daemon that watches a directory tree and scans files when they are
+created or modified. Matches are appended to the datastore, and each new finding
+is printed as it is discovered.
+
+Changes are reported by the OS (inotify, kqueue or ReadDirectoryChangesW), and
+the files changed are scanned every --interval (default 2s). Network
+filesystems such as NFS and SMB send no events; use --poll to walk the tree
+every --interval instead. Existing files are only scanned at startup when
+--initial-scan is set.
+
+With --rules-reload, the --rules file and --rule-pack-dir directories are
+polled too. When they change, the rules are recompiled in the background and
+swapped in between files; if they fail to load or compile, the error is
+printed and the current rules stay in use.
+
+Examples:
+  titus watch ./build-artifacts
+  titus watch ~/src --initial-scan --validate --siem udp://siem.local:514
+  titus watch /srv/uploads --interval 10s --metrics-addr :9090
+  titus watch ./src --rule-pack-dir ./team-rules --rules-reload`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWatch,
+}
+
+func init() {
+	watchCmd.Flags().StringVar(&watchOutputPath, "output", "titus.ds", "Output datastore path (:memory: for in-memory)")
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", enum.DefaultWatchInterval, "Time between scans of the files changed (or, with --poll, between walks of the tree)")
+	watchCmd.Flags().BoolVar(&watchPoll, "poll", false, "Walk the tree every --interval instead of waiting for file events (for network filesystems)")
+	watchCmd.Flags().BoolVar(&watchInitialScan, "initial-scan", false, "Scan files that already exist before watching for changes")
+	watchCmd.Flags().StringVar(&watchRulesPath, "rules", "", "Path to custom rules file, or https:// URL of a rule pack .tar.gz")
+	watchCmd.Flags().StringVar(&remoteRulesSHA256, "rules-sha256", "", "Expected SHA-256 of a remote --rules pack (pins the version and allows offline use of the cache)")
+	watchCmd.Flags().StringVar(&remoteRulesPubKey, "rules-pubkey", "", "Ed25519 public key file used to verify a remote --rules pack's <url>.sig signature")
+	watchCmd.Flags().StringVar(&watchRulesInclude, "rules-include", "", "Include rules matching regex pattern (comma-separated)")
+	watchCmd.Flags().StringVar(&watchRulesExclude, "rules-exclude", "", "Exclude rules matching regex pattern (comma-separated)")
+	watchCmd.Flags().StringVar(&watchCategories, "categories", "", "Only use rules with any of these categories or tags (comma-separated)")
+	watchCmd.Flags().StringVar(&watchExcludeCategories, "exclude-categories", "", "Skip rules with any of these categories or tags (comma-separated)")
+	watchCmd.Flags().StringVar(&watchRuleset, "ruleset", "default", "Ruleset to use: default, np.assets, np.hashes, all (all = no filtering)")
+	watchCmd.Flags().StringVar(&watchRulePacks, "rule-packs", "", "Rule packs to use instead of --ruleset (comma-separated name or name@version, e.g. cloud,ci-cd)")
+	watchCmd.Flags().StringSliceVar(&watchRulePackDirs, "rule-pack-dir", nil, "Directory containing an external rule pack (pack.yml plus rule files; repeatable)")
+	watchCmd.Flags().BoolVar(&watchRulesReload, "rules-reload", false, "Reload rules when the --rules file or --rule-pack-dir directories change")
+	watchCmd.Flags().BoolVar(&watchValidate, "validate", false, "validate detected secrets against their source APIs")
+	watchCmd.Flags().StringVar(&watchValidateAudit, "validate-audit", "", "Append a JSON line per outbound validation request (time, rule, validator, host, result; no secrets) to this file")
+	watchCmd.Flags().StringSliceVar(&watchValidateTimeouts, "validate-timeout", nil, "Time limit for each validation, and per validator as name=duration (e.g. 10s,aws=30s; default 30s, or the validator's own)")
+	watchCmd.Flags().IntVar(&watchValidateBreaker, "validate-breaker", validator.DefaultBreakerFailures, "Skip a validator's endpoint, marking its matches undetermined, after this many consecutive network failures or timeouts against it (0 = never)")
+	watchCmd.Flags().Int64Var(&watchMaxFileSize, "max-file-size", 10*1024*1024, "Maximum file size to scan (bytes)")
+	watchCmd.Flags().IntVar(&watchContextLines, "context-lines", 3, "Lines of context before/after matches (0 to disable)")
+	watchCmd.Flags().IntVar(&watchContextBytes, "context-bytes", 0, "Max bytes of context before/after matches; used alone, keeps a byte window around each match (0 = no limit)")
+	watchCmd.Flags().StringVar(&watchIgnoreFile, "ignore", "", "Path to gitignore-style ignore file (replaces built-in defaults; use /dev/null to disable)")
+	watchCmd.Flags().StringVar(&watchSIEMTarget, "siem", "", "Stream match events to a SIEM: udp://host:port, tcp://host:port (syslog) or a file path")
+	watchCmd.Flags().StringVar(&watchSIEMFormat, "siem-format", "cef", "SIEM event format: cef, leef, ocsf, ecs")
+	watchCmd.Flags().StringVar(&watchMetricsAddr, "metrics-addr", "", "Expose Prometheus metrics at http://<addr>/metrics (empty = disabled)")
+
+	rootCmd.AddCommand(watchCmd)
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	target := args[0]
+	if _, err := os.Stat(target); err != nil {
+		return fmt.Errorf("target does not exist: %s", target)
+	}
+
+	var reloadFrom []string
+	if watchRulesReload {
+		var err error
+		if reloadFrom, err = reloadPaths(watchRulesPath, watchRulePackDirs); err != nil {
+			return err
+		}
+	}
+
+	loadWatchRules := func() ([]*types.Rule, error) {
+		return loadRuleSelection(watchRulesPath, ruleFilter(watchRulesInclude, watchRulesExclude, watchCategories, watchExcludeCategories), watchRuleset, watchRulePacks, watchRulePackDirs)
+	}
+	rules, err := loadWatchRules()
+	if err != nil {
+		return fmt.Errorf("loading rules: %w", err)
+	}
+	ruleMap := make(map[string]*types.Rule)
+	for _, r := range rules {
+		ruleMap[r.ID] = r
+	}
+
+	var engine *validator.Engine
+	if watchValidate {
+		engine = validator.NewDefaultEngine(4)
+		if err := setValidationLimits(engine, watchValidateTimeouts, watchValidateBreaker); err != nil {
+			return err
+		}
+	}
+	audit, err := openValidationAudit(watchValidateAudit, engine)
+	if err != nil {
+		return err
+	}
+	if audit != nil {
+		defer closeValidationAudit(audit)
+	}
+	compile := func(rules []*types.Rule) (matcher.Matcher, error) {
+		m, err := matcher.New(matcher.Config{
+			Rules:        rules,
+			ContextLines: watchContextLines,
+			ContextBytes: watchContextBytes,
+			WarnFunc: func(format string, args ...any) {
+				fmt.Fprintf(os.Stderr, format, args...)
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("creating matcher: %w", err)
+		}
+		if engine != nil {
+			matcher.SetCanValidate(m, engine.CanValidate)
+		}
+		return m, nil
+	}
+	m, err := compile(rules)
+	if err != nil {
+		return err
+	}
+	p := &streamPipeline{
+		matcher:   m,
+		ruleMap:   ruleMap,
+		validator: engine,
+		out:       cmd.OutOrStdout(),
+		lifecycle: newFileLifecycle(),
+	}
+	defer p.closeMatchers()
+
+	s, ds, err := openScanStore(watchOutputPath, false)
+	if err != nil {
+		return err
+	}
+	if ds != nil {
+		defer ds.Close()
+	} else {
+		defer s.Close()
+	}
+	for _, r := range rules {
+		if err := s.AddRule(r); err != nil {
+			return fmt.Errorf("storing rule: %w", err)
+		}
+	}
+	p.store = s
+
+	sink, err := openSIEMSink(watchSIEMTarget, watchSIEMFormat)
+	if err != nil {
+		return err
+	}
+	if sink != nil {
+		defer sink.Close()
+	}
+	p.siem = sink
+
+	ctx, cancel := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	reg := startMetricsServer(ctx, watchMetricsAddr)
+	p.metrics = reg
+
+	w, err := enum.NewWatcher(enum.Config{
+		Root:        target,
+		MaxFileSize: watchMaxFileSize,
+		IgnoreFile:  watchIgnoreFile,
+	})
+	if err != nil {
+		return fmt.Errorf("creating watcher: %w", err)
+	}
+	w.Interval = watchInterval
+	w.InitialScan = watchInitialScan
+	w.Polling = watchPoll
+
+	if watchRulesReload {
+		r := &ruleReloader{
+			paths:    reloadFrom,
+			interval: watchInterval,
+			load:     loadWatchRules,
+			compile:  compile,
+			publish:  p.offerRules,
+			log:      cmd.ErrOrStderr(),
+		}
+		go r.run(ctx)
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Watching %s (every %s, %d rules). Press Ctrl+C to stop.\n", target, watchInterval, len(rules))
+	err = w.Watch(ctx, func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		return p.process(ctx, content, blobID, prov)
+	}, func(changed int, took time.Duration) {
+		if changed > 0 {
+			reg.ObserveScan(took, false)
+			p.updateLifecycle(cmd.ErrOrStderr())
+		}
+	})
+	fmt.Fprintf(cmd.ErrOrStderr(), "Stopped watching %s\n", target)
+	return err
+}
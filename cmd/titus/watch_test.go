@@ -0,0 +1,17 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchCommand_Exists(t *testing.T) {
+	cmd, _, err := rootCmd.Find([]string{"watch"})
+	require.NoError(t, err)
+	assert.Equal(t, "watch", cmd.Name())
+	assert.NotNil(t, cmd.Flags().Lookup("interval"))
+	assert.NotNil(t, cmd.Flags().Lookup("initial-scan"))
+	assert.NotNil(t, cmd.Flags().Lookup("rules-reload"))
+}
@@ -171,6 +171,29 @@ async function processQueue() {
     }
 }
 
+// Validate matches that have a browser-safe validator, setting
+// match.ValidationResult so the dashboard can show live/dead status.
+// Only validators whose endpoints permit cross-origin fetch are available
+// in the WASM build; other matches are left unvalidated.
+async function validateMatches(scanResults) {
+    if (typeof TitusValidate !== 'function') return;
+
+    const pending = [];
+    for (const result of scanResults.results || []) {
+        for (const match of result.matches || []) {
+            if (!TitusCanValidate(match.RuleID)) continue;
+            pending.push(TitusValidate(JSON.stringify(match)).then(res => {
+                if (typeof res !== 'string') {
+                    console.warn(`[Titus] Validation error for ${match.RuleID}: ${res?.error}`);
+                    return;
+                }
+                match.ValidationResult = JSON.parse(res);
+            }));
+        }
+    }
+    await Promise.allSettled(pending);
+}
+
 // Process a single scan item
 async function processScanItem(item) {
     const { tabId, url, content } = item;
@@ -210,7 +233,9 @@ async function processScanItem(item) {
             if (results.total > 0) {
                 totalFindings += results.total;
                 currentScanProgress.findings = totalFindings;
+                await validateMatches(results);
                 await storeFindings(url, results);
+                TitusRecordFindings(url, JSON.stringify(results));
             }
 
             // Small yield to prevent blocking
@@ -258,6 +283,16 @@ async function processScanItem(item) {
     }
 }
 
+// Build a SARIF report from every stored finding, including those from
+// before this worker last started
+async function exportSARIF() {
+    if (!await ensureWASMReady()) {
+        return { error: 'WASM not available' };
+    }
+    const findings = await getAllFindings();
+    return TitusExportSARIF(JSON.stringify(findings));
+}
+
 // Message handler
 chrome.runtime.onMessage.addListener((message, sender, sendResponse) => {
     const tab = sender.tab;
@@ -299,6 +334,7 @@ chrome.runtime.onMessage.addListener((message, sender, sendResponse) => {
             return true;
 
         case 'clearFindings':
+            if (wasmReady) TitusClearFindings();
             clearFindings().then(() => sendResponse({ success: true }));
             return true;
 
@@ -306,6 +342,10 @@ chrome.runtime.onMessage.addListener((message, sender, sendResponse) => {
             exportFindings().then(sendResponse);
             return true;
 
+        case 'exportSARIF':
+            exportSARIF().then(sendResponse);
+            return true;
+
         case 'getStats':
             getStats().then(sendResponse);
             return true;
@@ -300,7 +300,7 @@ function createTableRow(finding, index) {
     const timestamp = new Date(finding.timestamp).toLocaleString();
 
     tr.innerHTML = `
-        <td>${escapeHtml(ruleName)}</td>
+        <td>${escapeHtml(ruleName)}${validationBadge(finding.validation)}</td>
         <td class="secret-cell" title="${escapeHtml(secret)}">${escapeHtml(truncate(secret, 40))}</td>
         <td>${escapeHtml(truncate(source, 30))}</td>
         <td title="${escapeHtml(url)}">${escapeHtml(formatUrl(url))}</td>
@@ -322,6 +322,14 @@ function createTableRow(finding, index) {
     return tr;
 }
 
+// Render a live/dead badge for a finding's validation result, if it has one
+function validationBadge(validation) {
+    if (!validation?.status) return '';
+    const labels = { valid: 'live', invalid: 'dead', undetermined: 'unknown' };
+    const label = labels[validation.status] || validation.status;
+    return ` <span class="validation-badge validation-${escapeHtml(validation.status)}" title="${escapeHtml(validation.message || '')}">${escapeHtml(label)}</span>`;
+}
+
 // Create detail row
 function createDetailRow(finding, index) {
     const template = document.getElementById('detail-row-template');
@@ -443,6 +451,23 @@ async function exportFindings() {
     URL.revokeObjectURL(url);
 }
 
+// Export every stored finding as SARIF
+async function exportSARIF() {
+    const sarif = await chrome.runtime.sendMessage({ action: 'exportSARIF' });
+    if (typeof sarif !== 'string') {
+        alert(`SARIF export failed: ${sarif?.error || 'unknown error'}`);
+        return;
+    }
+
+    const blob = new Blob([sarif], { type: 'application/sarif+json' });
+    const url = URL.createObjectURL(blob);
+    const a = document.createElement('a');
+    a.href = url;
+    a.download = `titus-findings-${new Date().toISOString().slice(0, 10)}.sarif`;
+    a.click();
+    URL.revokeObjectURL(url);
+}
+
 // Clear functionality
 async function clearFindings() {
     if (confirm('Are you sure you want to clear all findings? This cannot be undone.')) {
@@ -468,6 +493,7 @@ document.addEventListener('DOMContentLoaded', async () => {
     });
 
     document.getElementById('export-btn').addEventListener('click', exportFindings);
+    document.getElementById('export-sarif-btn').addEventListener('click', exportSARIF);
     document.getElementById('clear-btn').addEventListener('click', clearFindings);
 
     // Load initial data (with error handling)
@@ -106,6 +106,8 @@ async function storeFindings(url, scanResults) {
                 secret: secretString,
                 snippet: match.Snippet,
                 location: match.Location,
+                // Live/dead status from browser-side validation, if any
+                validation: match.ValidationResult || null,
                 timestamp: new Date().toISOString()
             });
         }
@@ -0,0 +1,193 @@
+// Package bitbucket publishes findings to Bitbucket Cloud as a Code
+// Insights report on a commit, whose annotations Bitbucket shows inline
+// in pull requests that include the commit.
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultBaseURL is the Bitbucket Cloud REST API.
+const DefaultBaseURL = "https://api.bitbucket.org/2.0"
+
+const (
+	// MaxAnnotations is how many annotations Bitbucket keeps per report.
+	MaxAnnotations = 1000
+
+	// annotationBatch is how many annotations one request may create.
+	annotationBatch = 100
+
+	// maxSummary is the longest annotation summary Bitbucket accepts.
+	maxSummary = 450
+)
+
+// Report and annotation results
+const (
+	ResultPassed  = "PASSED"
+	ResultFailed  = "FAILED"
+	ResultIgnored = "IGNORED"
+)
+
+// Annotation severities
+const (
+	SeverityCritical = "CRITICAL"
+	SeverityHigh     = "HIGH"
+	SeverityMedium   = "MEDIUM"
+	SeverityLow      = "LOW"
+)
+
+// Report is a Code Insights report: a summary shown on the commit and in
+// its pull requests.
+type Report struct {
+	Title      string      `json:"title"`
+	Details    string      `json:"details,omitempty"`
+	ReportType string      `json:"report_type"` // SECURITY, COVERAGE, TEST or BUG
+	Reporter   string      `json:"reporter,omitempty"`
+	Link       string      `json:"link,omitempty"`
+	Result     string      `json:"result,omitempty"`
+	Data       []DataField `json:"data,omitempty"`
+}
+
+// DataField is a figure shown on a report, such as a count of findings.
+type DataField struct {
+	Title string `json:"title"`
+	Type  string `json:"type"` // BOOLEAN, DATE, DURATION, LINK, NUMBER, PERCENTAGE or TEXT
+	Value any    `json:"value"`
+}
+
+// Annotation marks a finding at a line of a file.
+type Annotation struct {
+	ExternalID     string `json:"external_id"` // unique within the report
+	AnnotationType string `json:"annotation_type"`
+	Summary        string `json:"summary"`
+	Details        string `json:"details,omitempty"`
+	Path           string `json:"path,omitempty"` // relative to the repository root
+	Line           int    `json:"line,omitempty"`
+	Severity       string `json:"severity,omitempty"`
+	Result         string `json:"result,omitempty"`
+}
+
+// Client calls the Code Insights API of a repository.
+type Client struct {
+	baseURL string
+	token   string // empty when the HTTP client authenticates, as through the Pipelines proxy
+	http    *http.Client
+}
+
+// NewClient creates a client of the API at baseURL (DefaultBaseURL if
+// empty) that authenticates with an access token, unless token is empty.
+// A nil httpClient means http.DefaultClient.
+func NewClient(baseURL, token string, httpClient *http.Client) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{baseURL: strings.TrimSuffix(baseURL, "/"), token: token, http: httpClient}
+}
+
+// Publish replaces the report reportID on a commit of workspace/repo with
+// report and its annotations. Annotations past MaxAnnotations are dropped;
+// Publish returns how many were published.
+func (c *Client) Publish(ctx context.Context, workspace, repo, commit, reportID string, report Report, annotations []Annotation) (int, error) {
+	reportURL := fmt.Sprintf("%s/repositories/%s/%s/commit/%s/reports/%s", c.baseURL,
+		url.PathEscape(workspace), url.PathEscape(repo), url.PathEscape(commit), url.PathEscape(reportID))
+
+	// Deleting the previous report removes its annotations, which
+	// replacing it would keep
+	if err := c.do(ctx, http.MethodDelete, reportURL, nil, http.StatusNotFound); err != nil {
+		return 0, fmt.Errorf("deleting previous report: %w", err)
+	}
+	if err := c.do(ctx, http.MethodPut, reportURL, report); err != nil {
+		return 0, fmt.Errorf("creating report: %w", err)
+	}
+
+	if len(annotations) > MaxAnnotations {
+		annotations = annotations[:MaxAnnotations]
+	}
+	for start := 0; start < len(annotations); start += annotationBatch {
+		batch := append([]Annotation(nil), annotations[start:min(start+annotationBatch, len(annotations))]...)
+		for i := range batch {
+			batch[i].Summary = truncate(batch[i].Summary, maxSummary)
+		}
+		if err := c.do(ctx, http.MethodPost, reportURL+"/annotations", batch); err != nil {
+			return start, fmt.Errorf("creating annotations: %w", err)
+		}
+	}
+	return len(annotations), nil
+}
+
+// do sends a request with body as JSON, if not nil, and fails unless the
+// response status is 2xx or one of okStatuses.
+func (c *Client) do(ctx context.Context, method, url string, body any, okStatuses ...int) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	for _, ok := range okStatuses {
+		if resp.StatusCode == ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: %s", resp.Status, errorMessage(resp.Body))
+}
+
+// errorMessage returns the message of a Bitbucket error response, or the
+// start of the body if it has none.
+func errorMessage(body io.Reader) string {
+	data, _ := io.ReadAll(io.LimitReader(body, 4096))
+	var e struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(data, &e) == nil && e.Error.Message != "" {
+		return e.Error.Message
+	}
+	return truncate(strings.TrimSpace(string(data)), 200)
+}
+
+// truncate shortens s to at most n bytes, ending it with "..." if cut,
+// without splitting a UTF-8 character.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	cut := n - 3
+	for cut > 0 && s[cut]&0xC0 == 0x80 {
+		cut--
+	}
+	return s[:cut] + "..."
+}
@@ -0,0 +1,109 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedRequest struct {
+	method, path, auth string
+	body               []byte
+}
+
+func newRecordingServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request)) (*httptest.Server, *[]recordedRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []recordedRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, recordedRequest{r.Method, r.URL.EscapedPath(), r.Header.Get("Authorization"), body})
+		mu.Unlock()
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestPublish(t *testing.T) {
+	srv, requests := newRecordingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNotFound) // no previous report
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	annotations := make([]Annotation, 150)
+	for i := range annotations {
+		annotations[i] = Annotation{ExternalID: fmt.Sprintf("a%d", i), AnnotationType: "VULNERABILITY", Summary: "GitHub token detected", Path: "app/config.yml", Line: i + 1}
+	}
+	annotations[0].Summary = strings.Repeat("x", 600)
+
+	client := NewClient(srv.URL+"/2.0/", "secret-token", srv.Client())
+	report := Report{Title: "Titus secrets scan", ReportType: "SECURITY", Result: ResultFailed,
+		Data: []DataField{{Title: "Findings", Type: "NUMBER", Value: 3}}}
+	published, err := client.Publish(context.Background(), "acme", "my repo", "abc123", "titus", report, annotations)
+	require.NoError(t, err)
+	assert.Equal(t, 150, published)
+
+	reqs := *requests
+	require.Len(t, reqs, 4)
+	reportPath := "/2.0/repositories/acme/my%20repo/commit/abc123/reports/titus"
+	assert.Equal(t, http.MethodDelete, reqs[0].method)
+	assert.Equal(t, reportPath, reqs[0].path)
+	assert.Equal(t, http.MethodPut, reqs[1].method)
+	assert.Equal(t, reportPath, reqs[1].path)
+	assert.Equal(t, "Bearer secret-token", reqs[1].auth)
+	assert.JSONEq(t, `{"title":"Titus secrets scan","report_type":"SECURITY","result":"FAILED","data":[{"title":"Findings","type":"NUMBER","value":3}]}`, string(reqs[1].body))
+
+	var first, second []Annotation
+	assert.Equal(t, http.MethodPost, reqs[2].method)
+	assert.Equal(t, reportPath+"/annotations", reqs[2].path)
+	require.NoError(t, json.Unmarshal(reqs[2].body, &first))
+	require.NoError(t, json.Unmarshal(reqs[3].body, &second))
+	assert.Len(t, first, 100, "annotations are sent in batches of 100")
+	assert.Len(t, second, 50)
+	assert.Len(t, first[0].Summary, maxSummary)
+	assert.True(t, strings.HasSuffix(first[0].Summary, "..."))
+}
+
+func TestPublish_CapsAnnotations(t *testing.T) {
+	srv, requests := newRecordingServer(t, func(w http.ResponseWriter, r *http.Request) {})
+
+	annotations := make([]Annotation, MaxAnnotations+5)
+	published, err := NewClient(srv.URL, "", srv.Client()).Publish(context.Background(), "acme", "api", "abc123", "titus", Report{}, annotations)
+	require.NoError(t, err)
+	assert.Equal(t, MaxAnnotations, published)
+	assert.Len(t, *requests, 2+MaxAnnotations/annotationBatch)
+	assert.Empty(t, (*requests)[1].auth, "no Authorization header without a token")
+}
+
+func TestPublish_Error(t *testing.T) {
+	srv, _ := newRecordingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"type":"error","error":{"message":"Token is missing the pullrequest scope"}}`))
+		}
+	})
+
+	_, err := NewClient(srv.URL, "token", srv.Client()).Publish(context.Background(), "acme", "api", "abc123", "titus", Report{}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "creating report: 403 Forbidden: Token is missing the pullrequest scope")
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", truncate("short", 10))
+	assert.Equal(t, "abcd...", truncate("abcdefghij", 7))
+	assert.Equal(t, "a...", truncate("aéééé", 5), "doesn't split a multi-byte character")
+}
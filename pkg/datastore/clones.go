@@ -70,8 +70,9 @@ func (c *CloneCache) Update(repoURL string) error {
 		return fmt.Errorf("clone does not exist: %s", cachePath)
 	}
 
-	// Fetch all refs
-	cmd := exec.Command("git", "-C", cachePath, "fetch", "--all")
+	// Bare clones have no fetch refspec configured, so spell out the refs to mirror
+	cmd := exec.Command("git", "-C", cachePath, "fetch", "--prune", "origin",
+		"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*")
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("fetching updates: %w (output: %s)", err, string(output))
 	}
@@ -0,0 +1,154 @@
+package enum
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// ArtifactoryConfig for JFrog Artifactory API enumeration.
+type ArtifactoryConfig struct {
+	BaseURL string   // Artifactory URL, e.g. https://acme.jfrog.io/artifactory
+	User    string   // user for basic auth with Token as password or API key; empty to send Token as a bearer token
+	Token   string   // access token, API key or password
+	Repos   []string // repository keys to scan (empty = every local repository the token can read)
+	Client  *http.Client
+	Config  // Embedded base Config (MaxFileSize, ExtractArchives, ExtractLimits)
+}
+
+// ArtifactoryEnumerator enumerates the artifacts in Artifactory repositories
+// via the REST API. Artifacts are yielded with FileProvenance whose path is
+// the artifact's download URL; archives such as jars, wars and npm tarballs
+// are extracted, with members named by that URL.
+type ArtifactoryEnumerator struct {
+	config ArtifactoryConfig
+	root   string // Artifactory URL, without a trailing slash
+	api    *apiClient
+	fs     *FilesystemEnumerator
+}
+
+// NewArtifactoryEnumerator creates a new Artifactory enumerator.
+func NewArtifactoryEnumerator(cfg ArtifactoryConfig) (*ArtifactoryEnumerator, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("must specify Artifactory URL")
+	}
+	if _, err := ValidateBaseURL(cfg.BaseURL); err != nil {
+		return nil, fmt.Errorf("Artifactory URL: %w", err)
+	}
+
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Minute}
+	}
+	api := &apiClient{client: client, auth: func(req *http.Request) {
+		switch {
+		case cfg.User != "":
+			req.SetBasicAuth(cfg.User, cfg.Token)
+		case cfg.Token != "":
+			req.Header.Set("Authorization", "Bearer "+cfg.Token)
+		}
+	}}
+
+	return &ArtifactoryEnumerator{
+		config: cfg,
+		root:   strings.TrimRight(cfg.BaseURL, "/"),
+		api:    api,
+		fs:     NewFilesystemEnumerator(cfg.Config),
+	}, nil
+}
+
+type artifactoryRepo struct {
+	Key  string `json:"key"`
+	Type string `json:"type"` // LOCAL, REMOTE, VIRTUAL or FEDERATED
+}
+
+type artifactoryFileList struct {
+	Files []struct {
+		URI    string `json:"uri"` // path within the repository, with a leading slash
+		Size   int64  `json:"size"`
+		Folder bool   `json:"folder"`
+	} `json:"files"`
+}
+
+// Enumerate walks every configured repository.
+func (e *ArtifactoryEnumerator) Enumerate(ctx context.Context, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	repos := e.config.Repos
+	if len(repos) == 0 {
+		var err error
+		if repos, err = e.listRepos(ctx); err != nil {
+			return err
+		}
+	}
+
+	for _, repo := range repos {
+		var list artifactoryFileList
+		listURL := fmt.Sprintf("%s/api/storage/%s/?list&deep=1&listFolders=0", e.root, url.PathEscape(repo))
+		if err := e.api.getJSON(ctx, listURL, &list); err != nil {
+			return fmt.Errorf("listing repository %s: %w", repo, err)
+		}
+		for _, f := range list.Files {
+			if f.Folder || isChecksumFile(f.URI) {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			artifactURL := e.root + "/" + url.PathEscape(repo) + escapePath(f.URI)
+			if e.config.MaxFileSize > 0 && f.Size > e.config.MaxFileSize {
+				e.config.skip(artifactURL, "too large")
+				continue
+			}
+			content, err := e.api.download(ctx, artifactURL, e.config.MaxFileSize)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: downloading %s: %v\n", artifactURL, err)
+				continue
+			}
+			if err := e.fs.processContent(artifactURL, content, callback); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// listRepos returns the keys of every local repository the token can read.
+// Remote repositories only cache public packages, and virtual ones
+// aggregate others, so they are left out.
+func (e *ArtifactoryEnumerator) listRepos(ctx context.Context) ([]string, error) {
+	var repos []artifactoryRepo
+	if err := e.api.getJSON(ctx, e.root+"/api/repositories?type=local", &repos); err != nil {
+		return nil, fmt.Errorf("listing repositories: %w", err)
+	}
+	var keys []string
+	for _, r := range repos {
+		if r.Type == "" || strings.EqualFold(r.Type, "LOCAL") {
+			keys = append(keys, r.Key)
+		}
+	}
+	return keys, nil
+}
+
+// escapePath escapes each segment of a slash-separated path for a URL.
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// isChecksumFile reports whether path is a checksum that repositories
+// publish alongside an artifact, which can't hold secrets.
+func isChecksumFile(path string) bool {
+	switch getExtension(path) {
+	case ".md5", ".sha1", ".sha256", ".sha512":
+		return true
+	}
+	return false
+}
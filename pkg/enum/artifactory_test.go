@@ -0,0 +1,110 @@
+package enum
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// zipArchive builds a zip (or jar) holding the given files.
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestArtifactoryEnumerator(t *testing.T) {
+	jar := zipArchive(t, map[string]string{"application.properties": "db.password=hunter2\n"})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/artifactory/api/repositories", func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		assert.Equal(t, "ci", user)
+		assert.Equal(t, "api-key", pass)
+		w.Write([]byte(`[{"key":"libs-release-local","type":"LOCAL"},{"key":"npm-remote","type":"REMOTE"}]`))
+	})
+	mux.HandleFunc("/artifactory/api/storage/libs-release-local/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"files":[
+			{"uri":"/com/acme/app/1.0/app-1.0.jar","size":` + strconv.Itoa(len(jar)) + `},
+			{"uri":"/com/acme/app/1.0/app-1.0.jar.sha1","size":40},
+			{"uri":"/com/acme/app/1.0/build notes.txt","size":20},
+			{"uri":"/com/acme/app/1.0/app-1.0-dist.zip","size":999999}
+		]}`))
+	})
+	mux.HandleFunc("/artifactory/libs-release-local/com/acme/app/1.0/app-1.0.jar", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(jar)
+	})
+	mux.HandleFunc("/artifactory/libs-release-local/com/acme/app/1.0/build%20notes.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("token=abc123"))
+	})
+	mux.HandleFunc("/artifactory/libs-release-local/com/acme/app/1.0/app-1.0.jar.sha1", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("checksum was downloaded")
+	})
+	mux.HandleFunc("/artifactory/libs-release-local/com/acme/app/1.0/app-1.0-dist.zip", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("artifact over the size limit was downloaded")
+	})
+	mux.HandleFunc("/artifactory/api/storage/npm-remote/", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("remote repository was listed")
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	var skipped []string
+	e, err := NewArtifactoryEnumerator(ArtifactoryConfig{
+		BaseURL: srv.URL + "/artifactory/",
+		User:    "ci",
+		Token:   "api-key",
+		Config: Config{MaxFileSize: 4096, ExtractArchives: "all", ExtractLimits: DefaultExtractionLimits(), IgnoreFile: os.DevNull,
+			OnSkip: func(path, reason string) { skipped = append(skipped, reason) }},
+	})
+	require.NoError(t, err)
+
+	blobs := make(map[string]string)
+	err = e.Enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		switch p := prov.(type) {
+		case types.ArchiveProvenance:
+			blobs[p.ArchivePath+"!"+p.MemberPath] = string(content)
+		default:
+			blobs[prov.Path()] = string(content)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	base := srv.URL + "/artifactory/libs-release-local/com/acme/app/1.0/"
+	assert.Equal(t, map[string]string{
+		base + "app-1.0.jar!application.properties": "db.password=hunter2\n",
+		base + "build%20notes.txt":                  "token=abc123",
+	}, blobs)
+	assert.Equal(t, []string{"too large"}, skipped)
+}
+
+func TestArtifactoryEnumerator_BearerToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer access-token", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"files":[]}`))
+	}))
+	defer srv.Close()
+
+	e, err := NewArtifactoryEnumerator(ArtifactoryConfig{BaseURL: srv.URL, Token: "access-token", Repos: []string{"generic-local"}})
+	require.NoError(t, err)
+	require.NoError(t, e.Enumerate(context.Background(), func([]byte, types.BlobID, types.Provenance) error { return nil }))
+
+	_, err = NewArtifactoryEnumerator(ArtifactoryConfig{BaseURL: "file:///etc"})
+	assert.Error(t, err)
+}
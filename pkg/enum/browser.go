@@ -0,0 +1,369 @@
+package enum
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/klauspost/compress/snappy"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// Markers appended to lines rendered from browser stores, matched by the
+// np.browser rules.
+const (
+	browserLoginMarker   = "# browser:login"
+	browserSessionMarker = "# browser:session"
+)
+
+// BrowserEnumerator enumerates the credential stores of Chrome (and other
+// Chromium-based browsers) and Firefox profile directories: saved logins,
+// cookies, and localStorage/sessionStorage. Each store is rendered as
+// `name = "value"` lines and yielded with ArchiveProvenance naming the store
+// file and what it holds ("logins", "cookies", "localStorage", ...).
+//
+// Root may be a single profile or a browser's whole user data directory.
+// Passwords and cookies encrypted with an OS keychain (Chrome's v10/v11
+// values, DPAPI blobs, Firefox's logins.json) are not decrypted.
+type BrowserEnumerator struct {
+	config Config
+}
+
+// NewBrowserEnumerator creates a new browser profile enumerator.
+func NewBrowserEnumerator(config Config) *BrowserEnumerator {
+	return &BrowserEnumerator{config: config}
+}
+
+// browserStore identifies a store file by its path and renders it.
+type browserStore struct {
+	name    string
+	match   func(slashPath, base string) bool
+	extract func(e *BrowserEnumerator, path string, content []byte) ([]byte, error)
+}
+
+var browserStores = []browserStore{
+	{
+		name: "logins",
+		match: func(_, base string) bool {
+			return base == "login data" || base == "login data for account"
+		},
+		extract: (*BrowserEnumerator).chromeLogins,
+	},
+	{
+		name:    "cookies",
+		match:   func(_, base string) bool { return base == "cookies" },
+		extract: (*BrowserEnumerator).chromeCookies,
+	},
+	{
+		name:    "cookies",
+		match:   func(_, base string) bool { return base == "cookies.sqlite" },
+		extract: (*BrowserEnumerator).firefoxCookies,
+	},
+	{
+		name:    "localStorage",
+		match:   func(_, base string) bool { return base == "webappsstore.sqlite" },
+		extract: (*BrowserEnumerator).firefoxWebappsstore,
+	},
+	{
+		name: "localStorage",
+		match: func(p, base string) bool {
+			return base == "data.sqlite" && strings.HasSuffix(path.Dir(p), "/ls") && strings.Contains(p, "/storage/default/")
+		},
+		extract: (*BrowserEnumerator).firefoxLocalStorage,
+	},
+	{
+		name:    "localStorage",
+		match:   leveldbIn("/local storage/leveldb"),
+		extract: (*BrowserEnumerator).chromeLevelDB,
+	},
+	{
+		name:    "sessionStorage",
+		match:   leveldbIn("/session storage"),
+		extract: (*BrowserEnumerator).chromeLevelDB,
+	},
+}
+
+func leveldbIn(dir string) func(slashPath, base string) bool {
+	return func(p, base string) bool {
+		ext := filepath.Ext(base)
+		return (ext == ".ldb" || ext == ".log") && strings.HasSuffix(path.Dir(p), dir)
+	}
+}
+
+// Enumerate walks the profile directory and yields its credential stores.
+func (e *BrowserEnumerator) Enumerate(ctx context.Context, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	return filepath.WalkDir(e.config.Root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+
+		slashPath := strings.ToLower(filepath.ToSlash(p))
+		store := findBrowserStore(slashPath)
+		if store == nil {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && e.config.MaxFileSize > 0 && info.Size() > e.config.MaxFileSize {
+			return nil
+		}
+
+		content, err := os.ReadFile(p)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			return nil
+		}
+		rendered, err := store.extract(e, p, content)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: reading %s: %v\n", p, err)
+			return nil
+		}
+		if len(rendered) == 0 {
+			return nil
+		}
+		prov := types.ArchiveProvenance{ArchivePath: p, MemberPath: store.name}
+		return callback(rendered, types.ComputeBlobID(rendered), prov)
+	})
+}
+
+func findBrowserStore(slashPath string) *browserStore {
+	base := path.Base(slashPath)
+	for i := range browserStores {
+		if browserStores[i].match(slashPath, base) {
+			return &browserStores[i]
+		}
+	}
+	return nil
+}
+
+// chromeLogins renders saved passwords that are stored in plaintext.
+func (e *BrowserEnumerator) chromeLogins(p string, content []byte) ([]byte, error) {
+	return e.querySQLite(p, content, `SELECT origin_url, username_value, password_value FROM logins`,
+		func(out *strings.Builder, row []any) {
+			password, ok := plaintext(row[2])
+			if !ok {
+				return
+			}
+			fmt.Fprintf(out, "%s username = %q password = %q %s\n",
+				str(row[0]), str(row[1]), password, browserLoginMarker)
+		})
+}
+
+func (e *BrowserEnumerator) chromeCookies(p string, content []byte) ([]byte, error) {
+	return e.querySQLite(p, content, `SELECT host_key, name, value, encrypted_value FROM cookies`,
+		func(out *strings.Builder, row []any) {
+			value := str(row[2])
+			if value == "" {
+				value, _ = plaintext(row[3])
+			}
+			writeCookie(out, str(row[0]), str(row[1]), value)
+		})
+}
+
+func (e *BrowserEnumerator) firefoxCookies(p string, content []byte) ([]byte, error) {
+	return e.querySQLite(p, content, `SELECT host, name, value FROM moz_cookies`,
+		func(out *strings.Builder, row []any) {
+			writeCookie(out, str(row[0]), str(row[1]), str(row[2]))
+		})
+}
+
+func (e *BrowserEnumerator) firefoxWebappsstore(p string, content []byte) ([]byte, error) {
+	return e.querySQLite(p, content, `SELECT originKey, key, value FROM webappsstore2`,
+		func(out *strings.Builder, row []any) {
+			writeSetting(out, firefoxOrigin(str(row[0])), str(row[1]), str(row[2]))
+		})
+}
+
+// firefoxLocalStorage reads a per-origin localStorage database, whose values
+// may be snappy-compressed. The origin is the name of the directory above ls/,
+// with "://" and ":" escaped as "+++" and "+" (e.g. "https+++example.com+8443").
+func (e *BrowserEnumerator) firefoxLocalStorage(p string, content []byte) ([]byte, error) {
+	origin := filepath.Base(filepath.Dir(filepath.Dir(p)))
+	if scheme, host, ok := strings.Cut(origin, "+++"); ok {
+		origin = scheme + "://" + strings.ReplaceAll(host, "+", ":")
+	}
+	return e.querySQLite(p, content, `SELECT key, compression_type, value FROM data`,
+		func(out *strings.Builder, row []any) {
+			value := bytesOf(row[2])
+			if n, _ := row[1].(int64); n == 1 {
+				decoded, err := snappy.Decode(nil, value)
+				if err != nil {
+					return
+				}
+				value = decoded
+			}
+			writeSetting(out, origin, str(row[0]), string(value))
+		})
+}
+
+// chromeLevelDB renders a localStorage or sessionStorage LevelDB file. Keys
+// are "_<origin>\x00<key>" (localStorage) or "map-<id>-<key>"
+// (sessionStorage); strings are Latin-1 or UTF-16LE, with localStorage
+// prefixing each with a 1 or 0 byte to say which.
+func (e *BrowserEnumerator) chromeLevelDB(p string, content []byte) ([]byte, error) {
+	records, err := leveldbRecords(p, content)
+	if err != nil {
+		return nil, err
+	}
+	var out strings.Builder
+	for _, r := range records {
+		key := string(r.Key)
+		switch {
+		case strings.HasPrefix(key, "_"):
+			origin, k, ok := strings.Cut(key[1:], "\x00")
+			if !ok {
+				continue
+			}
+			writeSetting(&out, origin, chromeString([]byte(k), true), chromeString(r.Value, true))
+		case strings.HasPrefix(key, "map-"):
+			writeSetting(&out, "", key, chromeString(r.Value, false))
+		}
+	}
+	return []byte(out.String()), nil
+}
+
+// querySQLite runs query against a SQLite store (with its write-ahead log,
+// if any) and renders each row. If the query fails, say because a browser
+// version changed the schema, all text columns are dumped instead.
+func (e *BrowserEnumerator) querySQLite(p string, content []byte, query string, render func(out *strings.Builder, row []any)) ([]byte, error) {
+	wal, _ := os.ReadFile(p + "-wal")
+	db, cleanup, err := openSQLite(content, wal, e.config.ExtractLimits.MaxSize)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	rows, err := db.Query(query)
+	if err != nil {
+		extracted, err := dumpSQLite(db, e.config.ExtractLimits)
+		if err != nil || len(extracted) == 0 {
+			return nil, err
+		}
+		return extracted[0].Content, nil
+	}
+	defer rows.Close()
+
+	var out strings.Builder
+	cols, _ := rows.Columns()
+	row := make([]any, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range row {
+		ptrs[i] = &row[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			continue
+		}
+		render(&out, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return []byte(out.String()), nil
+}
+
+// sessionCookieName matches the names of cookies that typically hold a
+// session or authentication token.
+var sessionCookieName = regexp.MustCompile(`(?i)sess|(?:^|[_.-])sid(?:$|[_.-])|token|auth|jwt|login|remember|credential`)
+
+func writeCookie(out *strings.Builder, host, name, value string) {
+	if value == "" {
+		return
+	}
+	line := fmt.Sprintf("%s %s = %q", host, name, value)
+	if sessionCookieName.MatchString(name) {
+		line += " " + browserSessionMarker
+	}
+	out.WriteString(line + "\n")
+}
+
+// writeSetting renders a storage entry. Values with quotes or newlines, like
+// JSON documents, are written as-is so that rules can match inside them.
+func writeSetting(out *strings.Builder, origin, key, value string) {
+	if value == "" {
+		return
+	}
+	if origin != "" {
+		out.WriteString(origin + " ")
+	}
+	if strings.ContainsAny(value, "\"\r\n") {
+		fmt.Fprintf(out, "%s = %s\n", key, value)
+	} else {
+		fmt.Fprintf(out, "%s = \"%s\"\n", key, value)
+	}
+}
+
+// firefoxOrigin converts webappsstore's reversed-host origin key, e.g.
+// "moc.elpmaxe.:https:443", to "https://example.com:443".
+func firefoxOrigin(key string) string {
+	parts := strings.SplitN(key, ":", 3)
+	if len(parts) != 3 {
+		return key
+	}
+	host := []rune(strings.TrimSuffix(parts[0], "."))
+	for i, j := 0, len(host)-1; i < j; i, j = i+1, j-1 {
+		host[i], host[j] = host[j], host[i]
+	}
+	return fmt.Sprintf("%s://%s:%s", parts[1], strings.TrimPrefix(string(host), "."), parts[2])
+}
+
+// chromeString decodes a Chrome storage string. With prefixed, the first byte
+// says whether the rest is Latin-1 (1) or UTF-16LE (0); otherwise the string
+// is UTF-16LE.
+func chromeString(b []byte, prefixed bool) string {
+	if prefixed {
+		if len(b) == 0 {
+			return ""
+		}
+		if b[0] == 1 {
+			runes := make([]rune, len(b)-1)
+			for i, c := range b[1:] {
+				runes[i] = rune(c)
+			}
+			return string(runes)
+		}
+		b = b[1:]
+	}
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = uint16(b[2*i]) | uint16(b[2*i+1])<<8
+	}
+	return string(utf16.Decode(u))
+}
+
+// plaintext returns a SQLite value as text unless it is an encrypted blob:
+// Chrome's "v10"/"v11" prefixed AES values, DPAPI blobs, or anything else that
+// isn't printable UTF-8.
+func plaintext(v any) (string, bool) {
+	b := bytesOf(v)
+	if len(b) == 0 || strings.HasPrefix(string(b), "v10") || strings.HasPrefix(string(b), "v11") || !isPrintable(b) {
+		return "", false
+	}
+	return string(b), true
+}
+
+func bytesOf(v any) []byte {
+	switch v := v.(type) {
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	}
+	return nil
+}
+
+func str(v any) string {
+	return string(bytesOf(v))
+}
@@ -0,0 +1,130 @@
+package enum
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSQLiteStore creates a SQLite database at path by running stmts.
+func testSQLiteStore(t *testing.T, path string, stmts ...string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	defer db.Close()
+	for _, stmt := range stmts {
+		_, err := db.Exec(stmt)
+		require.NoError(t, err, stmt)
+	}
+}
+
+func utf16LE(s string) []byte {
+	var b []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(u), byte(u>>8))
+	}
+	return b
+}
+
+func enumerateBrowser(t *testing.T, root string) map[string]string {
+	t.Helper()
+	got := make(map[string]string)
+	err := NewBrowserEnumerator(Config{Root: root}).Enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		rel, err := filepath.Rel(root, prov.(types.ArchiveProvenance).ArchivePath)
+		require.NoError(t, err)
+		got[filepath.ToSlash(rel)+":"+prov.(types.ArchiveProvenance).MemberPath] = string(content)
+		return nil
+	})
+	require.NoError(t, err)
+	return got
+}
+
+func TestBrowserEnumerator_Chrome(t *testing.T) {
+	profile := t.TempDir()
+	testSQLiteStore(t, filepath.Join(profile, "Default", "Login Data"),
+		`CREATE TABLE logins (origin_url TEXT, username_value TEXT, password_value BLOB)`,
+		`INSERT INTO logins VALUES ('https://intranet.example.com/', 'jsmith', CAST('Winter2024!' AS BLOB))`,
+		`INSERT INTO logins VALUES ('https://mail.example.com/', 'jsmith', X'7631300102030405')`,
+	)
+	testSQLiteStore(t, filepath.Join(profile, "Default", "Network", "Cookies"),
+		`CREATE TABLE cookies (host_key TEXT, name TEXT, value TEXT, encrypted_value BLOB)`,
+		`INSERT INTO cookies VALUES ('github.com', 'user_session', 'Jq3bYx1fS0mQ8eZr2VnK5tLw', X'')`,
+		`INSERT INTO cookies VALUES ('.example.com', '_ga', 'GA1.2.123.456', X'')`,
+		`INSERT INTO cookies VALUES ('.example.com', 'sid', '', X'763131aabbcc')`,
+	)
+
+	leveldbDir := filepath.Join(profile, "Default", "Local Storage", "leveldb")
+	require.NoError(t, os.MkdirAll(leveldbDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(leveldbDir, "000003.log"), testLevelDBLog(
+		leveldbRecord{Key: []byte("VERSION"), Value: []byte("1")},
+		leveldbRecord{Key: []byte("_https://app.example.com\x00\x01access_token"), Value: append([]byte{0}, utf16LE("eyJhbGciOiJIUzI1NiJ9.e30.sig")...)},
+	), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(leveldbDir, "000005.ldb"), testLevelDBTable(true,
+		leveldbRecord{Key: []byte("_https://app.example.com\x00\x01settings"), Value: []byte("\x01{\"apiKey\":\"abc123\"}")},
+	), 0644))
+
+	sessionDir := filepath.Join(profile, "Default", "Session Storage")
+	require.NoError(t, os.MkdirAll(sessionDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(sessionDir, "000003.log"), testLevelDBLog(
+		leveldbRecord{Key: []byte("map-1-csrf"), Value: utf16LE("c5f1e2d3")},
+	), 0644))
+
+	// Not a credential store.
+	require.NoError(t, os.WriteFile(filepath.Join(profile, "Default", "Preferences"), []byte(`{"token":"x"}`), 0644))
+
+	assert.Equal(t, map[string]string{
+		"Default/Login Data:logins": `https://intranet.example.com/ username = "jsmith" password = "Winter2024!" # browser:login` + "\n",
+		"Default/Network/Cookies:cookies": `github.com user_session = "Jq3bYx1fS0mQ8eZr2VnK5tLw" # browser:session` + "\n" +
+			`.example.com _ga = "GA1.2.123.456"` + "\n",
+		"Default/Local Storage/leveldb/000003.log:localStorage": `https://app.example.com access_token = "eyJhbGciOiJIUzI1NiJ9.e30.sig"` + "\n",
+		"Default/Local Storage/leveldb/000005.ldb:localStorage": `https://app.example.com settings = {"apiKey":"abc123"}` + "\n",
+		"Default/Session Storage/000003.log:sessionStorage":     `map-1-csrf = "c5f1e2d3"` + "\n",
+	}, enumerateBrowser(t, profile))
+}
+
+func TestBrowserEnumerator_Firefox(t *testing.T) {
+	profile := t.TempDir()
+	testSQLiteStore(t, filepath.Join(profile, "cookies.sqlite"),
+		`CREATE TABLE moz_cookies (host TEXT, name TEXT, value TEXT)`,
+		`INSERT INTO moz_cookies VALUES ('.example.com', 'PHPSESSID', 'f3a9c2b7d1e84a6f9b0c')`,
+	)
+	testSQLiteStore(t, filepath.Join(profile, "webappsstore.sqlite"),
+		`CREATE TABLE webappsstore2 (originKey TEXT, key TEXT, value TEXT)`,
+		`INSERT INTO webappsstore2 VALUES ('moc.elpmaxe.ppa.:https:443', 'refresh_token', 'rt-0123456789')`,
+	)
+
+	compressed := snappy.Encode(nil, []byte("sk-live-0123456789"))
+	testSQLiteStore(t, filepath.Join(profile, "storage", "default", "https+++app.example.com", "ls", "data.sqlite"),
+		`CREATE TABLE data (key TEXT, compression_type INTEGER, value BLOB)`,
+		`INSERT INTO data VALUES ('plain', 0, CAST('hello world' AS BLOB))`,
+		`INSERT INTO data VALUES ('apiKey', 1, X'`+hex.EncodeToString(compressed)+`')`,
+	)
+
+	assert.Equal(t, map[string]string{
+		"cookies.sqlite:cookies":           `.example.com PHPSESSID = "f3a9c2b7d1e84a6f9b0c" # browser:session` + "\n",
+		"webappsstore.sqlite:localStorage": `https://app.example.com:443 refresh_token = "rt-0123456789"` + "\n",
+		"storage/default/https+++app.example.com/ls/data.sqlite:localStorage": `https://app.example.com plain = "hello world"` + "\n" +
+			`https://app.example.com apiKey = "sk-live-0123456789"` + "\n",
+	}, enumerateBrowser(t, profile))
+}
+
+func TestBrowserEnumerator_SchemaFallback(t *testing.T) {
+	profile := t.TempDir()
+	testSQLiteStore(t, filepath.Join(profile, "Cookies"),
+		`CREATE TABLE cookies_v2 (domain TEXT, token TEXT)`,
+		`INSERT INTO cookies_v2 VALUES ('example.com', 'tok-0123456789')`,
+	)
+
+	got := enumerateBrowser(t, profile)
+	assert.Contains(t, got["Cookies:cookies"], "tok-0123456789")
+}
@@ -0,0 +1,335 @@
+package enum
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Markers appended to lines rendered from CI configuration, matched by the
+// np.ci rules.
+const (
+	ciInlineMarker = "# ci:inline"
+	ciEchoMarker   = "# ci:echo"
+)
+
+// isCIConfig reports whether path is a CI pipeline definition: a GitHub
+// Actions workflow, .gitlab-ci.yml, azure-pipelines.yml or a Jenkinsfile.
+func isCIConfig(p string) bool {
+	p = strings.ToLower(strings.ReplaceAll(p, "\\", "/"))
+	base := path.Base(p)
+	switch {
+	case isJenkinsfile(base):
+		return true
+	case base == ".gitlab-ci.yml" || base == ".gitlab-ci.yaml":
+		return true
+	case base == "azure-pipelines.yml" || base == "azure-pipelines.yaml":
+		return true
+	}
+	ext := path.Ext(base)
+	return (ext == ".yml" || ext == ".yaml") &&
+		(strings.HasPrefix(p, ".github/workflows/") || strings.Contains(p, "/.github/workflows/"))
+}
+
+func isJenkinsfile(base string) bool {
+	return base == "jenkinsfile" || strings.HasPrefix(base, "jenkinsfile.")
+}
+
+// ExtractCI analyzes a CI pipeline definition and returns blobs for what it
+// finds, each named by its location in the file (e.g.
+// "jobs.deploy.steps[2].env.API_TOKEN"):
+//
+//   - literal values of settings with secret-looking names in env, with,
+//     variables and inputs blocks, rendered as `NAME = "value" # ci:inline`;
+//   - script lines that print a secret, rendered as
+//     `echo = "secrets.NAME" # ci:echo`;
+//   - base64 literals in settings and base64-decoding script lines, decoded
+//     so that every rule can scan them.
+//
+// The file itself is still scanned as-is; these blobs add what raw regexes
+// over it can't see.
+func ExtractCI(p string, content []byte) ([]ExtractedContent, error) {
+	a := &ciAnalyzer{secretVars: make(map[string]bool)}
+	if isJenkinsfile(strings.ToLower(path.Base(strings.ReplaceAll(p, "\\", "/")))) {
+		a.jenkinsfile(content)
+	} else {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(content, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse ci yaml: %w", err)
+		}
+		a.walk(&doc, "")
+		for _, s := range a.scripts {
+			for _, line := range strings.Split(s.text, "\n") {
+				a.scriptLine(s.path, line)
+			}
+		}
+	}
+	return a.results, nil
+}
+
+// ciSettingKeys are the mapping keys whose children are named settings.
+var ciSettingKeys = map[string]bool{
+	"env":         true,
+	"environment": true,
+	"variables":   true,
+	"with":        true,
+	"inputs":      true,
+}
+
+// ciScriptKeys are the mapping keys whose values are shell scripts.
+var ciScriptKeys = map[string]bool{
+	"run":           true,
+	"script":        true,
+	"before_script": true,
+	"after_script":  true,
+	"bash":          true,
+	"pwsh":          true,
+	"powershell":    true,
+	"inlinescript":  true,
+}
+
+var (
+	// ciSensitiveName matches setting names that hold credentials.
+	ciSensitiveName = regexp.MustCompile(`(?i)passw(?:or)?d|(?:^|[_-])pass(?:$|[_-])|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|credential|auth(?:$|[_-])|authorization`)
+
+	// ciSecretRef matches GitHub Actions secret references.
+	ciSecretRef = regexp.MustCompile(`\$\{\{\s*(secrets\.[A-Za-z0-9_-]+)\s*\}\}`)
+
+	// ciPrint matches commands that write their arguments to the job log.
+	ciPrint = regexp.MustCompile(`(?i)(?:^|[\s;&({'"])(?:echo|printf|print|println|write-host|write-output)\b`)
+
+	// ciVarRef matches shell, PowerShell and Azure variable references.
+	ciVarRef = regexp.MustCompile(`\$env:([A-Za-z_][A-Za-z0-9_]*)|\$\{?([A-Za-z_][A-Za-z0-9_]*)\}?|\benv\.([A-Za-z_][A-Za-z0-9_]*)|\$\(([A-Za-z_][A-Za-z0-9_]*)\)`)
+
+	// ciBase64 matches candidate base64 literals.
+	ciBase64 = regexp.MustCompile(`[A-Za-z0-9+/]{16,}={0,2}`)
+)
+
+type ciScript struct {
+	path string
+	text string
+}
+
+type ciAnalyzer struct {
+	results    []ExtractedContent
+	index      map[string]int
+	secretVars map[string]bool // settings bound to a secret reference
+	scripts    []ciScript
+}
+
+// add appends a line to the blob named name.
+func (a *ciAnalyzer) add(name string, line string) {
+	if a.index == nil {
+		a.index = make(map[string]int)
+	}
+	i, ok := a.index[name]
+	if !ok {
+		i = len(a.results)
+		a.index[name] = i
+		a.results = append(a.results, ExtractedContent{Name: name})
+	}
+	a.results[i].Content = append(a.results[i].Content, line...)
+	a.results[i].Content = append(a.results[i].Content, '\n')
+}
+
+func (a *ciAnalyzer) walk(n *yaml.Node, p string) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			a.walk(c, p)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, val := n.Content[i].Value, n.Content[i+1]
+			child := childPath(p, key)
+			switch lower := strings.ToLower(key); {
+			case ciSettingKeys[lower]:
+				a.settings(val, child)
+			case ciScriptKeys[lower]:
+				a.script(val, child)
+			default:
+				a.walk(val, child)
+			}
+		}
+	case yaml.SequenceNode:
+		for i, c := range n.Content {
+			a.walk(c, indexPath(p, i))
+		}
+	}
+}
+
+// settings handles an env-like block: a mapping of names to values (or, for
+// GitLab, to {value: ...}), or an Azure Pipelines list of {name, value}.
+func (a *ciAnalyzer) settings(n *yaml.Node, p string) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			name, val := n.Content[i].Value, n.Content[i+1]
+			if v := yamlField(val, "value"); v != nil {
+				val = v
+			}
+			if val.Kind == yaml.ScalarNode {
+				a.setting(childPath(p, name), name, val.Value)
+			} else {
+				a.walk(val, childPath(p, name))
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			name, val := yamlField(item, "name"), yamlField(item, "value")
+			if name != nil && val != nil && val.Kind == yaml.ScalarNode {
+				a.setting(indexPath(p, i), name.Value, val.Value)
+			} else {
+				a.walk(item, indexPath(p, i))
+			}
+		}
+	}
+}
+
+func (a *ciAnalyzer) setting(p, name, value string) {
+	if strings.Contains(value, "$") {
+		// A reference rather than a literal. Other CI systems don't mark
+		// secret variables, so trust the name there.
+		if ciSecretRef.MatchString(value) || ciSensitiveName.MatchString(name) {
+			a.secretVars[name] = true
+		}
+		return
+	}
+	if value == "" {
+		return
+	}
+	if ciSensitiveName.MatchString(name) && !strings.ContainsAny(value, "\"\r\n") {
+		a.add(p, fmt.Sprintf("%s = \"%s\" %s", name, value, ciInlineMarker))
+	}
+	a.decodeBase64(p, value)
+}
+
+// script records a script for analysis once all settings, and with them the
+// variables bound to secrets, are known.
+func (a *ciAnalyzer) script(n *yaml.Node, p string) {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		a.scripts = append(a.scripts, ciScript{path: p, text: n.Value})
+	case yaml.SequenceNode:
+		for i, c := range n.Content {
+			a.script(c, indexPath(p, i))
+		}
+	}
+}
+
+// scriptLine flags a line that prints a secret to the job log. Output that is
+// piped or redirected, as in `echo $TOKEN | docker login --password-stdin`,
+// and GitHub's ::add-mask:: command don't end up in the log.
+func (a *ciAnalyzer) scriptLine(p, line string) {
+	if ciPrint.MatchString(line) && !strings.ContainsAny(line, "|>") && !strings.Contains(line, "::add-mask::") {
+		for _, ref := range a.printedSecrets(line) {
+			a.add(p, fmt.Sprintf("echo = %s %s", strconv.Quote(ref), ciEchoMarker))
+		}
+	}
+	if strings.Contains(strings.ToLower(line), "base64") {
+		a.decodeBase64(p, line)
+	}
+}
+
+// printedSecrets returns the secrets a printing line references, either
+// directly or through a variable bound to one.
+func (a *ciAnalyzer) printedSecrets(line string) []string {
+	var refs []string
+	for _, m := range ciSecretRef.FindAllStringSubmatch(line, -1) {
+		refs = append(refs, m[1])
+	}
+	for _, m := range ciVarRef.FindAllStringSubmatch(line, -1) {
+		name := m[1] + m[2] + m[3] + m[4]
+		if a.secretVars[name] {
+			refs = append(refs, name)
+		}
+	}
+	return refs
+}
+
+// decodeBase64 decodes base64 literals in s that hold text and adds them as a
+// blob of their own.
+func (a *ciAnalyzer) decodeBase64(p, s string) {
+	for _, lit := range ciBase64.FindAllString(s, -1) {
+		decoded, err := base64.StdEncoding.DecodeString(lit)
+		if err != nil {
+			decoded, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(lit, "="))
+		}
+		if err == nil && isText(decoded) {
+			a.add(p+" (base64)", string(decoded))
+		}
+	}
+}
+
+var (
+	jenkinsAssign     = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(?:'([^'\n]*)'|"([^"\n]*)")\s*$`)
+	jenkinsCredential = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*)\s*=\s*credentials\(`)
+	jenkinsBinding    = regexp.MustCompile(`(?:variable|usernameVariable|passwordVariable|keyFileVariable|passphraseVariable|tokenVariable)\s*:\s*['"]([A-Za-z_][A-Za-z0-9_]*)['"]`)
+)
+
+// jenkinsfile analyzes a declarative or scripted Jenkins pipeline line by
+// line: environment assignments, credential bindings and echo/sh steps.
+func (a *ciAnalyzer) jenkinsfile(content []byte) {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		lines = append(lines, line)
+		if m := jenkinsCredential.FindStringSubmatch(line); m != nil {
+			a.secretVars[m[1]] = true
+		}
+		for _, m := range jenkinsBinding.FindAllStringSubmatch(line, -1) {
+			a.secretVars[m[1]] = true
+		}
+	}
+	for i, line := range lines {
+		p := fmt.Sprintf("line %d", i+1)
+		if m := jenkinsAssign.FindStringSubmatch(line); m != nil {
+			a.setting("environment."+m[1], m[1], m[2]+m[3])
+			continue
+		}
+		a.scriptLine(p, line)
+	}
+}
+
+// yamlField returns the value of key in mapping n, or nil.
+func yamlField(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// isText reports whether b looks like decoded text rather than binary data.
+func isText(b []byte) bool {
+	return len(b) >= 8 && isPrintable(b)
+}
+
+// isPrintable reports whether b is UTF-8 made of printable characters and
+// whitespace.
+func isPrintable(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
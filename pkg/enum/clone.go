@@ -1,12 +1,16 @@
 package enum
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/praetorian-inc/titus/pkg/types"
@@ -17,6 +21,7 @@ type RepoInfo struct {
 	Name          string // Full name (e.g., "kubernetes/kubernetes")
 	CloneURL      string // HTTPS clone URL
 	DefaultBranch string
+	Size          int64 // approximate repository size in bytes as reported by the API (0 = unknown)
 }
 
 // CloneEnumerator clones repositories and scans them.
@@ -26,18 +31,79 @@ type CloneEnumerator struct {
 	repos  []RepoInfo
 	config Config
 	Git    bool          // false = full clone + filesystem scan, true = full clone + git history (thorough)
+	Refs   []string      // in git mode, walk only the history of these refs (empty = all)
 	Depth  int           // override clone depth (0 = automatic: full clone for filesystem mode, unlimited for git mode)
 	Delay  time.Duration // delay between repository clones (0 = no delay)
 	Token  string        // API token for authenticated cloning (passed via ephemeral credential helper)
+
+	// Submodules clones each repository's submodules too. In git mode their
+	// histories are walked with RepoPath "<repo>/<submodule path>".
+	Submodules bool
+
+	// KeepDuplicates, in git mode, yields a blob at every path of the
+	// history it is found at rather than only at the first.
+	KeepDuplicates bool
+
+	WorkDir      string // parent directory for temporary clones (empty = system temp dir)
+	KeepClone    bool   // leave clones on disk after scanning (for debugging)
+	MinFreeSpace uint64 // bytes that must remain free in WorkDir after cloning (0 = DefaultMinFreeSpace)
+
+	// Retries is how many times a failed clone is retried, waiting
+	// RetryBackoff before the first retry and twice as long before each
+	// further one. Failures git reports as permanent, such as a repository
+	// that doesn't exist, are not retried.
+	Retries      int
+	RetryBackoff time.Duration
+
+	failures []RepoFailure
+}
+
+// Defaults for CloneEnumerator.Retries and RetryBackoff.
+const (
+	DefaultCloneRetries = 2
+	DefaultRetryBackoff = time.Second
+)
+
+// RepoFailure is a repository a CloneEnumerator could not scan.
+type RepoFailure struct {
+	Repo RepoInfo
+	Err  error
 }
 
+// DefaultMinFreeSpace is the headroom CloneEnumerator requires in its work
+// directory beyond the reported repository size before it starts a clone.
+const DefaultMinFreeSpace = 512 * 1024 * 1024
+
 // NewCloneEnumerator creates a new clone-based enumerator.
 func NewCloneEnumerator(repos []RepoInfo, config Config) *CloneEnumerator {
-	return &CloneEnumerator{repos: repos, config: config}
+	return &CloneEnumerator{
+		repos:        repos,
+		config:       config,
+		Retries:      DefaultCloneRetries,
+		RetryBackoff: DefaultRetryBackoff,
+	}
 }
 
-// Enumerate clones each repository, scans it, and cleans up.
+// Failures returns the repositories the last Enumerate skipped because they
+// could not be cloned or scanned, and why.
+func (e *CloneEnumerator) Failures() []RepoFailure {
+	return e.failures
+}
+
+// Enumerate clones each repository, scans it, and cleans up. A repository
+// that cannot be cloned or scanned is skipped and recorded in Failures; an
+// error returned by callback stops the enumeration.
 func (e *CloneEnumerator) Enumerate(ctx context.Context, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	e.failures = nil
+	var callbackErr error
+	report := func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		if err := callback(content, blobID, prov); err != nil {
+			callbackErr = err
+			return err
+		}
+		return nil
+	}
+
 	for i, repo := range e.repos {
 		select {
 		case <-ctx.Done():
@@ -54,9 +120,16 @@ func (e *CloneEnumerator) Enumerate(ctx context.Context, callback func(content [
 			}
 		}
 
-		if err := e.cloneAndScan(ctx, repo, callback); err != nil {
+		if err := e.cloneAndScan(ctx, repo, report); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if callbackErr != nil {
+				return callbackErr
+			}
 			// Log error and continue to next repo
 			fmt.Fprintf(os.Stderr, "warning: skipping %s: %v\n", repo.Name, err)
+			e.failures = append(e.failures, RepoFailure{Repo: repo, Err: err})
 			continue
 		}
 	}
@@ -64,11 +137,26 @@ func (e *CloneEnumerator) Enumerate(ctx context.Context, callback func(content [
 }
 
 func (e *CloneEnumerator) cloneAndScan(ctx context.Context, repo RepoInfo, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
-	tmpDir, err := os.MkdirTemp("", "titus-clone-*")
+	if e.WorkDir != "" {
+		if err := os.MkdirAll(e.WorkDir, 0o755); err != nil {
+			return fmt.Errorf("creating work dir: %w", err)
+		}
+	}
+	if err := e.checkDiskSpace(repo); err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp(e.WorkDir, "titus-clone-*")
 	if err != nil {
 		return fmt.Errorf("creating temp dir: %w", err)
 	}
-	defer os.RemoveAll(tmpDir)
+	// Cleanup also runs when ctx is cancelled (e.g. on SIGINT), since the
+	// git subprocess is bound to ctx and every error path returns through here.
+	if e.KeepClone {
+		defer fmt.Fprintf(os.Stderr, "Kept clone of %s at %s\n", repo.Name, tmpDir)
+	} else {
+		defer os.RemoveAll(tmpDir)
+	}
 
 	clonePath := filepath.Join(tmpDir, "repo")
 
@@ -81,61 +169,91 @@ func (e *CloneEnumerator) cloneAndScan(ctx context.Context, repo RepoInfo, callb
 	// Inject ephemeral credential helper when a token is provided.
 	// This avoids embedding the token in the URL (server logs) or command line (ps).
 	// The helper reads the token from TITUS_CLONE_TOKEN env var at runtime.
+	// The helper is scoped to the repository's host so that submodules
+	// hosted elsewhere are not offered the token.
 	if e.Token != "" {
+		helper := "credential.helper"
+		if u, err := url.Parse(repo.CloneURL); err == nil && u.Scheme != "" && u.Host != "" {
+			helper = "credential." + u.Scheme + "://" + u.Host + ".helper"
+		}
 		cloneArgs = append(cloneArgs,
 			"-c", `credential.helper=`,
-			"-c", `credential.helper=!f() { echo username=titus; echo password="$TITUS_CLONE_TOKEN"; }; f`,
+			"-c", helper+`=!f() { echo username=titus; echo password="$TITUS_CLONE_TOKEN"; }; f`,
 		)
 	}
 
 	cloneArgs = append(cloneArgs, "clone", "--quiet")
-	if e.Git && depth == 0 {
+	if e.Submodules {
+		// Submodules are checked out into a working tree, so no bare clone
+		cloneArgs = append(cloneArgs, "--recurse-submodules")
+	} else if e.Git && depth == 0 {
 		// Full history: bare clone for efficiency (no working tree needed)
 		cloneArgs = append(cloneArgs, "--bare")
 	}
 	if depth > 0 {
 		cloneArgs = append(cloneArgs, "--depth", strconv.Itoa(depth))
+		if e.Submodules {
+			cloneArgs = append(cloneArgs, "--shallow-submodules")
+		}
 	}
 	cloneArgs = append(cloneArgs, repo.CloneURL, clonePath)
 
 	fmt.Fprintf(os.Stderr, "Cloning %s...\n", repo.Name)
-	cmd := exec.CommandContext(ctx, "git", cloneArgs...)
-	cmd.Stderr = os.Stderr
-
-	if e.Token != "" {
-		// Isolate from user's git config to prevent credential helper conflicts,
-		// and pass the token via environment variable (not visible in ps).
-		cmd.Env = append(os.Environ(),
-			"TITUS_CLONE_TOKEN="+e.Token,
-			"GIT_CONFIG_NOSYSTEM=1",
-			"GIT_TERMINAL_PROMPT=0",
-		)
-	}
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("cloning %s: %w", repo.Name, err)
+	if err := e.clone(ctx, repo, cloneArgs, clonePath); err != nil {
+		return err
 	}
 
 	cloneConfig := e.config
 	cloneConfig.Root = clonePath
 
 	if e.Git {
-		// Git history mode: walk all commits
-		gitEnum := NewGitEnumerator(cloneConfig)
-		if depth == 0 {
-			gitEnum.WalkAll = true
-		}
-		return gitEnum.Enumerate(ctx, func(content []byte, blobID types.BlobID, prov types.Provenance) error {
-			if gp, ok := prov.(types.GitProvenance); ok {
-				gp.RepoPath = repo.Name
-				return callback(content, blobID, gp)
+		// Git history mode: walk all commits, of submodules too
+		repoPaths := []string{clonePath}
+		if e.Submodules {
+			if repoPaths, err = FindRepositories(clonePath); err != nil {
+				return fmt.Errorf("finding submodules of %s: %w", repo.Name, err)
+			}
+		}
+		for _, repoPath := range repoPaths {
+			name := repo.Name
+			if rel, err := filepath.Rel(clonePath, repoPath); err == nil && rel != "." {
+				name += "/" + filepath.ToSlash(rel)
 			}
-			return callback(content, blobID, prov)
-		})
+			repoConfig := cloneConfig
+			repoConfig.Root = repoPath
+			gitEnum := NewGitEnumerator(repoConfig)
+			gitEnum.KeepDuplicates = e.KeepDuplicates
+			if depth == 0 {
+				gitEnum.WalkAll = true
+				gitEnum.Refs = e.Refs
+			}
+			err := gitEnum.Enumerate(ctx, func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+				if gp, ok := prov.(types.GitProvenance); ok {
+					gp.RepoPath = name
+					return callback(content, blobID, gp)
+				}
+				return callback(content, blobID, prov)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
 	}
 
 	// Filesystem mode (default): fast scan of working tree
 	// Collect commit metadata for current files (best-effort; nil map is safe)
-	commitMap, _ := collectCommitMetadataForRepo(ctx, clonePath, false)
+	commitMap, _ := collectCommitMetadataForRepo(ctx, clonePath, false, history{})
+
+	// Report paths under the repository's name rather than the temp clone's
+	if onFile := cloneConfig.OnFile; onFile != nil {
+		cloneConfig.OnFile = func(path string) {
+			if rel, err := filepath.Rel(clonePath, path); err == nil {
+				path = repo.Name + "/" + filepath.ToSlash(rel)
+			}
+			onFile(path)
+		}
+	}
 
 	return NewFilesystemEnumerator(cloneConfig).Enumerate(ctx, func(content []byte, blobID types.BlobID, prov types.Provenance) error {
 		// Rewrite file provenance to include repo name
@@ -157,3 +275,107 @@ func (e *CloneEnumerator) cloneAndScan(ctx context.Context, repo RepoInfo, callb
 		return callback(content, blobID, prov)
 	})
 }
+
+// clone runs git clone, retrying failures that may be transient, such as
+// network errors and server timeouts.
+func (e *CloneEnumerator) clone(ctx context.Context, repo RepoInfo, cloneArgs []string, clonePath string) error {
+	for attempt := 1; ; attempt++ {
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "git", cloneArgs...)
+		cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
+		if e.Token != "" {
+			// Isolate from user's git config to prevent credential helper conflicts,
+			// and pass the token via environment variable (not visible in ps).
+			cmd.Env = append(os.Environ(),
+				"TITUS_CLONE_TOKEN="+e.Token,
+				"GIT_CONFIG_NOSYSTEM=1",
+				"GIT_TERMINAL_PROMPT=0",
+			)
+		}
+		err := cmd.Run()
+		if err == nil {
+			return nil
+		}
+		if reason := gitErrorReason(stderr.String()); reason != "" {
+			err = fmt.Errorf("%w: %s", err, reason)
+		}
+		if ctx.Err() != nil || attempt > e.Retries || permanentCloneError(stderr.String()) {
+			if attempt > 1 {
+				return fmt.Errorf("cloning %s (%d attempts): %w", repo.Name, attempt, err)
+			}
+			return fmt.Errorf("cloning %s: %w", repo.Name, err)
+		}
+
+		// git leaves a partial clone behind, which the next attempt can't
+		// clone into
+		os.RemoveAll(clonePath)
+		delay := e.RetryBackoff << (attempt - 1)
+		fmt.Fprintf(os.Stderr, "Cloning %s failed, retrying in %s...\n", repo.Name, delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// gitErrorReason returns the first error git wrote to stderr, which says
+// why it failed, or its last line if none is marked as an error.
+func gitErrorReason(stderr string) string {
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	for _, line := range lines {
+		for _, prefix := range []string{"fatal: ", "error: "} {
+			if reason, ok := strings.CutPrefix(strings.TrimSpace(line), prefix); ok {
+				return reason
+			}
+		}
+	}
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// permanentCloneError reports whether git's stderr shows a failure that a
+// retry won't fix.
+func permanentCloneError(stderr string) bool {
+	for _, s := range []string{
+		"not found",
+		"does not exist",
+		"does not appear to be a git repository",
+		"Authentication failed",
+		"could not read Username",
+		"Permission denied",
+		"Host key verification failed",
+	} {
+		if strings.Contains(stderr, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDiskSpace refuses to clone when the work directory would be left with
+// less than MinFreeSpace bytes. Platforms that cannot report free space are
+// not checked.
+func (e *CloneEnumerator) checkDiskSpace(repo RepoInfo) error {
+	dir := e.WorkDir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	free, err := availableDiskSpace(dir)
+	if err != nil {
+		return nil
+	}
+
+	headroom := e.MinFreeSpace
+	if headroom == 0 {
+		headroom = DefaultMinFreeSpace
+	}
+	need := headroom
+	if repo.Size > 0 {
+		need += uint64(repo.Size)
+	}
+	if free < need {
+		return fmt.Errorf("insufficient disk space in %s: %d MiB free, need %d MiB", dir,
+			free>>20, need>>20)
+	}
+	return nil
+}
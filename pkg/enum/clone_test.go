@@ -2,10 +2,13 @@ package enum
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/praetorian-inc/titus/pkg/types"
 	"github.com/stretchr/testify/assert"
@@ -142,3 +145,173 @@ func TestCloneEnumerator_ContextCancellation(t *testing.T) {
 	})
 	assert.ErrorIs(t, err, context.Canceled)
 }
+
+// newLocalCloneSource creates a one-commit repository that can be cloned via file://.
+func newLocalCloneSource(t *testing.T) string {
+	t.Helper()
+	repoDir := filepath.Join(t.TempDir(), "test-repo")
+	require.NoError(t, os.MkdirAll(repoDir, 0o755))
+
+	cmds := [][]string{
+		{"git", "init", repoDir},
+		{"git", "-C", repoDir, "config", "user.email", "test@test.com"},
+		{"git", "-C", repoDir, "config", "user.name", "Test"},
+	}
+	for _, args := range cmds {
+		cmd := exec.Command(args[0], args[1:]...)
+		require.NoError(t, cmd.Run(), "failed running: %v", args)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "file.txt"), []byte("content"), 0o644))
+	require.NoError(t, exec.Command("git", "-C", repoDir, "add", ".").Run())
+	require.NoError(t, exec.Command("git", "-C", repoDir, "commit", "-m", "initial commit").Run())
+	return repoDir
+}
+
+func TestCloneEnumerator_WorkDirCleanup(t *testing.T) {
+	repoDir := newLocalCloneSource(t)
+	workDir := filepath.Join(t.TempDir(), "work")
+
+	e := NewCloneEnumerator([]RepoInfo{{Name: "test/repo", CloneURL: "file://" + repoDir}}, Config{})
+	e.WorkDir = workDir
+
+	var sawClone bool
+	err := e.Enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		entries, _ := os.ReadDir(workDir)
+		sawClone = len(entries) == 1
+		return nil
+	})
+	require.NoError(t, err)
+	assert.True(t, sawClone, "clone should be created inside the work dir")
+
+	entries, err := os.ReadDir(workDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "clone should be removed after scanning")
+}
+
+func TestCloneEnumerator_CleanupOnCancel(t *testing.T) {
+	repoDir := newLocalCloneSource(t)
+	workDir := t.TempDir()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e := NewCloneEnumerator([]RepoInfo{{Name: "test/repo", CloneURL: "file://" + repoDir}}, Config{})
+	e.WorkDir = workDir
+
+	err := e.Enumerate(ctx, func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		cancel()
+		return ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	entries, err := os.ReadDir(workDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestCloneEnumerator_KeepClone(t *testing.T) {
+	repoDir := newLocalCloneSource(t)
+	workDir := t.TempDir()
+
+	e := NewCloneEnumerator([]RepoInfo{{Name: "test/repo", CloneURL: "file://" + repoDir}}, Config{})
+	e.WorkDir = workDir
+	e.KeepClone = true
+
+	require.NoError(t, e.Enumerate(context.Background(), func([]byte, types.BlobID, types.Provenance) error { return nil }))
+
+	entries, err := os.ReadDir(workDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.FileExists(t, filepath.Join(workDir, entries[0].Name(), "repo", "file.txt"))
+}
+
+func TestCloneEnumerator_InsufficientDiskSpace(t *testing.T) {
+	if _, err := availableDiskSpace(t.TempDir()); err != nil {
+		t.Skip("free space not reported on this platform")
+	}
+	repoDir := newLocalCloneSource(t)
+	workDir := t.TempDir()
+
+	e := NewCloneEnumerator([]RepoInfo{{Name: "test/repo", CloneURL: "file://" + repoDir}}, Config{})
+	e.WorkDir = workDir
+	e.MinFreeSpace = 1 << 62
+
+	err := e.cloneAndScan(context.Background(), e.repos[0], func([]byte, types.BlobID, types.Provenance) error { return nil })
+	assert.ErrorContains(t, err, "insufficient disk space")
+
+	entries, err := os.ReadDir(workDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "nothing should be cloned")
+}
+
+func TestCloneEnumerator_RetriesTransientFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("stand-in git is a shell script")
+	}
+	realGit, err := exec.LookPath("git")
+	require.NoError(t, err)
+	repoDir := newLocalCloneSource(t)
+
+	// A git that fails its first clone the way a dropped connection does
+	binDir := t.TempDir()
+	marker := filepath.Join(binDir, "failed")
+	script := "#!/bin/sh\n" +
+		"case \" $* \" in *\" clone \"*) if [ ! -f " + marker + " ]; then touch " + marker + "; " +
+		"echo 'fatal: unable to access: Could not resolve host: example.com' >&2; exit 128; fi;; esac\n" +
+		"exec " + realGit + " \"$@\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "git"), []byte(script), 0o755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	e := NewCloneEnumerator([]RepoInfo{{Name: "test/repo", CloneURL: "file://" + repoDir}}, Config{})
+	e.RetryBackoff = time.Millisecond
+	var count int
+	err = e.Enumerate(context.Background(), func([]byte, types.BlobID, types.Provenance) error {
+		count++
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Positive(t, count, "the retried clone should be scanned")
+	assert.Empty(t, e.Failures())
+}
+
+func TestCloneEnumerator_RecordsFailures(t *testing.T) {
+	repoDir := newLocalCloneSource(t)
+	missing := filepath.Join(t.TempDir(), "missing")
+	repos := []RepoInfo{
+		{Name: "test/missing", CloneURL: "file://" + missing},
+		{Name: "test/repo", CloneURL: "file://" + repoDir},
+	}
+	e := NewCloneEnumerator(repos, Config{})
+	e.RetryBackoff = time.Hour // a missing repository must not be retried
+
+	var count int
+	err := e.Enumerate(context.Background(), func([]byte, types.BlobID, types.Provenance) error {
+		count++
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Positive(t, count, "later repositories are still scanned")
+
+	failures := e.Failures()
+	require.Len(t, failures, 1)
+	assert.Equal(t, "test/missing", failures[0].Repo.Name)
+	assert.ErrorContains(t, failures[0].Err, "does not appear to be a git repository")
+	assert.NotContains(t, failures[0].Err.Error(), "attempts")
+}
+
+func TestCloneEnumerator_CallbackErrorStops(t *testing.T) {
+	repoDir := newLocalCloneSource(t)
+	repos := []RepoInfo{
+		{Name: "test/one", CloneURL: "file://" + repoDir},
+		{Name: "test/two", CloneURL: "file://" + repoDir},
+	}
+	e := NewCloneEnumerator(repos, Config{})
+
+	storeErr := errors.New("disk full")
+	var calls int
+	err := e.Enumerate(context.Background(), func([]byte, types.BlobID, types.Provenance) error {
+		calls++
+		return storeErr
+	})
+	assert.ErrorIs(t, err, storeErr)
+	assert.Equal(t, 1, calls)
+	assert.Empty(t, e.Failures())
+}
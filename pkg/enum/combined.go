@@ -11,6 +11,10 @@ import (
 // blobs by BlobID so each unique blob is yielded at most once.
 type CombinedEnumerator struct {
 	enumerators []Enumerator
+
+	// KeepDuplicates yields a blob from every enumerator that finds it, so
+	// that each location is reported.
+	KeepDuplicates bool
 }
 
 // NewCombinedEnumerator creates a CombinedEnumerator that wraps the provided
@@ -29,6 +33,9 @@ func (c *CombinedEnumerator) Enumerate(ctx context.Context, callback func(conten
 
 	for _, e := range c.enumerators {
 		err := e.Enumerate(ctx, func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+			if c.KeepDuplicates {
+				return callback(content, blobID, prov)
+			}
 			mu.Lock()
 			if seen[blobID] {
 				mu.Unlock()
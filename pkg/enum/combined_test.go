@@ -109,6 +109,27 @@ func TestCombinedEnumerator_DeduplicatesByBlobID(t *testing.T) {
 	assert.Contains(t, blobIDs, uniqueID)
 }
 
+func TestCombinedEnumerator_KeepDuplicates(t *testing.T) {
+	sharedID := blobIDFrom(42)
+	e1 := &mockEnumerator{blobs: []mockBlob{
+		{content: []byte("dup"), blobID: sharedID, prov: types.FileProvenance{FilePath: "first.txt"}},
+	}}
+	e2 := &mockEnumerator{blobs: []mockBlob{
+		{content: []byte("dup"), blobID: sharedID, prov: types.FileProvenance{FilePath: "second.txt"}},
+	}}
+	combined := NewCombinedEnumerator(e1, e2)
+	combined.KeepDuplicates = true
+
+	var paths []string
+	err := combined.Enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		paths = append(paths, prov.Path())
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first.txt", "second.txt"}, paths)
+}
+
 func TestCombinedEnumerator_AllUniqueBlobs(t *testing.T) {
 	e1 := &mockEnumerator{blobs: []mockBlob{
 		{content: []byte("a"), blobID: blobIDFrom(1), prov: types.FileProvenance{FilePath: "a.txt"}},
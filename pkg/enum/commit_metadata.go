@@ -14,15 +14,17 @@ import (
 // collectCommitMetadataForRepo runs git log to build a map of file path → commit metadata.
 // When firstAdded is true, uses --diff-filter=A to find the commit that first added each path.
 // When false, finds the most recent commit that touched each path.
-func collectCommitMetadataForRepo(ctx context.Context, repoPath string, firstAdded bool) (map[string]*types.CommitMetadata, error) {
-	args := []string{"log", "--all",
-		"--format=%H%x00%an%x00%ae%x00%aI%x00%cn%x00%ce%x00%cI%x00%s", "--name-only"}
+// h selects the commits walked.
+func collectCommitMetadataForRepo(ctx context.Context, repoPath string, firstAdded bool, h history) (map[string]*types.CommitMetadata, error) {
+	args := append([]string{"log"}, h.args()...)
+	args = append(args, "--format=%H%x00%an%x00%ae%x00%aI%x00%cn%x00%ce%x00%cI%x00%s", "--name-only")
 	if firstAdded {
 		args = append(args, "--diff-filter=A")
 	}
 
 	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = repoPath
+	cmd.Stdin = h.stdin()
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -0,0 +1,220 @@
+package enum
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// ConfluenceConfig for Confluence API enumeration.
+type ConfluenceConfig struct {
+	BaseURL     string   // site URL, e.g. https://acme.atlassian.net (Cloud) or https://wiki.corp.com (Data Center)
+	User        string   // account email for Cloud API tokens; empty to send Token as a bearer token
+	Token       string   // API token (Cloud) or personal access token (Data Center)
+	Spaces      []string // space keys to scan (empty = every space the token can read)
+	Attachments bool     // also scan page attachments
+	Client      *http.Client
+	Config      // Embedded base Config (MaxFileSize, ExtractArchives, ExtractLimits)
+}
+
+// ConfluenceEnumerator enumerates the pages, blog posts and attachments of
+// Confluence spaces via the REST API. Pages are yielded with FileProvenance
+// whose path is the page's URL, which names its space and title; attachments
+// are named by their page's URL followed by the file name.
+type ConfluenceEnumerator struct {
+	config ConfluenceConfig
+	root   string // URL of the Confluence web application, without a trailing slash
+	api    *apiClient
+	fs     *FilesystemEnumerator
+}
+
+// confluencePageLimit is the page size requested from paginated endpoints.
+const confluencePageLimit = 50
+
+// NewConfluenceEnumerator creates a new Confluence enumerator.
+func NewConfluenceEnumerator(cfg ConfluenceConfig) (*ConfluenceEnumerator, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("must specify Confluence URL")
+	}
+	if _, err := ValidateBaseURL(cfg.BaseURL); err != nil {
+		return nil, fmt.Errorf("Confluence URL: %w", err)
+	}
+	u, err := url.Parse(strings.TrimRight(cfg.BaseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("Confluence URL: %w", err)
+	}
+	// Confluence Cloud serves the wiki under /wiki
+	if strings.HasSuffix(u.Hostname(), ".atlassian.net") && !strings.HasPrefix(u.Path, "/wiki") {
+		u.Path = "/wiki" + u.Path
+	}
+
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+	api := &apiClient{client: client, auth: func(req *http.Request) {
+		switch {
+		case cfg.User != "":
+			req.SetBasicAuth(cfg.User, cfg.Token)
+		case cfg.Token != "":
+			req.Header.Set("Authorization", "Bearer "+cfg.Token)
+		}
+		req.Header.Set("Accept", "application/json")
+	}}
+
+	return &ConfluenceEnumerator{
+		config: cfg,
+		root:   u.String(),
+		api:    api,
+		fs:     NewFilesystemEnumerator(cfg.Config),
+	}, nil
+}
+
+// confluenceLinks holds the links Confluence returns with each object.
+type confluenceLinks struct {
+	Next     string `json:"next"`
+	WebUI    string `json:"webui"`
+	Download string `json:"download"`
+}
+
+type confluenceSpaceList struct {
+	Results []struct {
+		Key string `json:"key"`
+	} `json:"results"`
+	Links confluenceLinks `json:"_links"`
+}
+
+type confluenceContentList struct {
+	Results []confluenceContent `json:"results"`
+	Links   confluenceLinks     `json:"_links"`
+}
+
+type confluenceContent struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Body  struct {
+		Storage struct {
+			Value string `json:"value"`
+		} `json:"storage"`
+	} `json:"body"`
+	Extensions struct {
+		FileSize int64 `json:"fileSize"`
+	} `json:"extensions"`
+	Links confluenceLinks `json:"_links"`
+}
+
+// Enumerate walks every configured space.
+func (e *ConfluenceEnumerator) Enumerate(ctx context.Context, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	spaces := e.config.Spaces
+	if len(spaces) == 0 {
+		var err error
+		if spaces, err = e.listSpaces(ctx); err != nil {
+			return err
+		}
+	}
+
+	for _, space := range spaces {
+		for _, contentType := range []string{"page", "blogpost"} {
+			q := url.Values{
+				"spaceKey": {space},
+				"type":     {contentType},
+				"expand":   {"body.storage"},
+				"limit":    {fmt.Sprint(confluencePageLimit)},
+			}
+			next := e.root + "/rest/api/content?" + q.Encode()
+			for next != "" {
+				var list confluenceContentList
+				if err := e.api.getJSON(ctx, next, &list); err != nil {
+					return fmt.Errorf("listing %ss in space %s: %w", contentType, space, err)
+				}
+				for _, page := range list.Results {
+					if err := e.enumeratePage(ctx, page, callback); err != nil {
+						return err
+					}
+				}
+				next = e.link(list.Links.Next)
+			}
+		}
+	}
+	return nil
+}
+
+// listSpaces returns the keys of every space the token can read.
+func (e *ConfluenceEnumerator) listSpaces(ctx context.Context) ([]string, error) {
+	var keys []string
+	next := fmt.Sprintf("%s/rest/api/space?limit=%d", e.root, confluencePageLimit)
+	for next != "" {
+		var list confluenceSpaceList
+		if err := e.api.getJSON(ctx, next, &list); err != nil {
+			return nil, fmt.Errorf("listing spaces: %w", err)
+		}
+		for _, s := range list.Results {
+			keys = append(keys, s.Key)
+		}
+		next = e.link(list.Links.Next)
+	}
+	return keys, nil
+}
+
+// enumeratePage yields a page's body and, if enabled, its attachments.
+// Storage format is XHTML, so entities are decoded before scanning.
+func (e *ConfluenceEnumerator) enumeratePage(ctx context.Context, page confluenceContent, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	pageURL := e.link(page.Links.WebUI)
+	if pageURL == "" {
+		pageURL = fmt.Sprintf("%s/pages/viewpage.action?pageId=%s", e.root, page.ID)
+	}
+
+	body := []byte(html.UnescapeString(page.Body.Storage.Value))
+	if len(body) > 0 {
+		if err := callback(body, types.ComputeBlobID(body), types.FileProvenance{FilePath: pageURL}); err != nil {
+			return err
+		}
+	}
+	if !e.config.Attachments {
+		return nil
+	}
+
+	next := fmt.Sprintf("%s/rest/api/content/%s/child/attachment?limit=%d", e.root, url.PathEscape(page.ID), confluencePageLimit)
+	for next != "" {
+		var list confluenceContentList
+		if err := e.api.getJSON(ctx, next, &list); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: listing attachments of %s: %v\n", pageURL, err)
+			return nil
+		}
+		for _, att := range list.Results {
+			if e.config.MaxFileSize > 0 && att.Extensions.FileSize > e.config.MaxFileSize {
+				continue
+			}
+			name := pageURL + "/" + att.Title
+			content, err := e.api.download(ctx, e.link(att.Links.Download), e.config.MaxFileSize)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: downloading %s: %v\n", name, err)
+				continue
+			}
+			if err := e.fs.processContent(name, content, callback); err != nil {
+				return err
+			}
+		}
+		next = e.link(list.Links.Next)
+	}
+	return nil
+}
+
+// link resolves a link returned by the API, which is relative to the web
+// application root.
+func (e *ConfluenceEnumerator) link(rel string) string {
+	if rel == "" || strings.HasPrefix(rel, "http://") || strings.HasPrefix(rel, "https://") {
+		return rel
+	}
+	return e.root + rel
+}
@@ -0,0 +1,108 @@
+package enum
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfluenceEnumerator_Enumerate(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/api/space", func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "alice@example.com", user)
+		assert.Equal(t, "api-token", pass)
+		w.Write([]byte(`{"results":[{"key":"ENG"}],"_links":{}}`))
+	})
+	mux.HandleFunc("/rest/api/content", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "ENG", r.URL.Query().Get("spaceKey"))
+		switch {
+		case r.URL.Query().Get("type") == "blogpost":
+			w.Write([]byte(`{"results":[],"_links":{}}`))
+		case r.URL.Query().Get("start") == "":
+			w.Write([]byte(`{"results":[{"id":"1","title":"Runbook","body":{"storage":{"value":"<p>password=&quot;hunter2&quot;</p>"}},"_links":{"webui":"/spaces/ENG/pages/1/Runbook"}}],"_links":{"next":"/rest/api/content?spaceKey=ENG&type=page&start=1"}}`))
+		default:
+			w.Write([]byte(`{"results":[{"id":"2","title":"Empty","body":{"storage":{"value":""}},"_links":{"webui":"/spaces/ENG/pages/2/Empty"}}],"_links":{}}`))
+		}
+	})
+	mux.HandleFunc("/rest/api/content/1/child/attachment", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"results":[
+			{"title":"creds.txt","extensions":{"fileSize":12},"_links":{"download":"/download/attachments/1/creds.txt?version=1"}},
+			{"title":"huge.iso","extensions":{"fileSize":999999},"_links":{"download":"/download/attachments/1/huge.iso"}}
+		],"_links":{}}`))
+	})
+	mux.HandleFunc("/rest/api/content/2/child/attachment", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"results":[],"_links":{}}`))
+	})
+	mux.HandleFunc("/download/attachments/1/creds.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("token=abc123"))
+	})
+	mux.HandleFunc("/download/attachments/1/huge.iso", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("attachment over the size limit was downloaded")
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	e, err := NewConfluenceEnumerator(ConfluenceConfig{
+		BaseURL:     srv.URL,
+		User:        "alice@example.com",
+		Token:       "api-token",
+		Attachments: true,
+		Config:      Config{MaxFileSize: 1024, IgnoreFile: os.DevNull},
+	})
+	require.NoError(t, err)
+
+	blobs := make(map[string]string)
+	err = e.Enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		blobs[prov.Path()] = string(content)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		srv.URL + "/spaces/ENG/pages/1/Runbook":           `<p>password="hunter2"</p>`,
+		srv.URL + "/spaces/ENG/pages/1/Runbook/creds.txt": "token=abc123",
+	}, blobs)
+}
+
+func TestNewConfluenceEnumerator_CloudRoot(t *testing.T) {
+	e, err := NewConfluenceEnumerator(ConfluenceConfig{BaseURL: "https://acme.atlassian.net/"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://acme.atlassian.net/wiki", e.root)
+
+	e, err = NewConfluenceEnumerator(ConfluenceConfig{BaseURL: "https://acme.atlassian.net/wiki"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://acme.atlassian.net/wiki", e.root)
+
+	_, err = NewConfluenceEnumerator(ConfluenceConfig{BaseURL: "file:///etc"})
+	assert.Error(t, err)
+}
+
+func TestAPIClient_RetriesRateLimited(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	api := &apiClient{client: srv.Client(), auth: func(*http.Request) {}}
+	var v struct{ OK bool }
+	require.NoError(t, api.getJSON(context.Background(), srv.URL, &v))
+	assert.True(t, v.OK)
+	assert.Equal(t, 2, calls)
+
+	_, err := api.download(context.Background(), srv.URL, 4)
+	assert.Error(t, err)
+}
@@ -0,0 +1,130 @@
+package enum
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// devCachePaths are the files and directories, relative to a home directory,
+// in which package managers, build tools and cloud CLIs keep credentials
+// and caches. Paths under AppData and Library are the Windows and macOS
+// locations of the same files.
+var devCachePaths = []string{
+	// Maven
+	".m2/settings.xml",
+	".m2/settings-security.xml",
+
+	// Gradle
+	".gradle/gradle.properties",
+	".gradle/init.gradle",
+	".gradle/init.gradle.kts",
+	".gradle/init.d",
+
+	// npm and Yarn
+	".npmrc",
+	".yarnrc",
+	".yarnrc.yml",
+
+	// pip
+	".pypirc",
+	".pip/pip.conf",
+	".config/pip/pip.conf",
+	".cache/pip",
+	"Library/Application Support/pip/pip.conf",
+	"Library/Caches/pip",
+	"AppData/Roaming/pip/pip.ini",
+	"AppData/Local/pip/Cache",
+
+	// Other package managers
+	".nuget/NuGet/NuGet.Config",
+	"AppData/Roaming/NuGet/NuGet.Config",
+	".gem/credentials",
+	".cargo/credentials",
+	".cargo/credentials.toml",
+	".composer/auth.json",
+	".config/composer/auth.json",
+
+	// Docker and Kubernetes
+	".docker/config.json",
+	".kube/config",
+
+	// AWS
+	".aws/credentials",
+	".aws/config",
+	".aws/sso/cache",
+	".aws/cli/cache",
+
+	// Google Cloud
+	".config/gcloud",
+	"AppData/Roaming/gcloud",
+
+	// Azure
+	".azure/msal_token_cache.json",
+	".azure/accessTokens.json",
+	".azure/service_principal_entries.json",
+	".azure/azureProfile.json",
+
+	// Other CLIs and credential stores
+	".terraform.d/credentials.tfrc.json",
+	"AppData/Roaming/terraform.d/credentials.tfrc.json",
+	".vault-token",
+	".config/gh/hosts.yml",
+	".git-credentials",
+	".config/git/credentials",
+	".netrc",
+	"_netrc",
+}
+
+// DevCacheEnumerator enumerates the credential and cache files of the
+// developer tools under a home directory (Config.Root): Maven and Gradle
+// settings, npm, pip and other package manager configuration, the pip cache,
+// Docker and kubectl configuration, and AWS, Google Cloud, Azure and other
+// CLI credential files. Files are yielded as by the filesystem enumerator,
+// with their full paths.
+type DevCacheEnumerator struct {
+	config Config
+}
+
+// NewDevCacheEnumerator creates a new developer cache enumerator.
+func NewDevCacheEnumerator(config Config) *DevCacheEnumerator {
+	return &DevCacheEnumerator{config: config}
+}
+
+// Paths returns the developer tool files and directories that exist under
+// the home directory, in a fixed order.
+func (e *DevCacheEnumerator) Paths() []string {
+	var paths []string
+	for _, rel := range devCachePaths {
+		path := filepath.Join(e.config.Root, filepath.FromSlash(rel))
+		if _, err := os.Lstat(path); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// Enumerate walks each path that exists. PathConfig is still given paths
+// relative to the home directory.
+func (e *DevCacheEnumerator) Enumerate(ctx context.Context, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	for _, p := range e.Paths() {
+		config := e.config
+		config.Root = p
+		if pathConfig := e.config.PathConfig; pathConfig != nil {
+			rel, err := filepath.Rel(e.config.Root, p)
+			if err != nil {
+				return err
+			}
+			config.PathConfig = func(relPath string) Config {
+				return pathConfig(path.Join(filepath.ToSlash(rel), relPath))
+			}
+		}
+		if err := NewFilesystemEnumerator(config).Enumerate(ctx, callback); err != nil {
+			return err
+		}
+	}
+	return nil
+}
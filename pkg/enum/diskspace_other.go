@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd)
+
+package enum
+
+import "errors"
+
+// availableDiskSpace is not supported on this platform; callers skip the check.
+func availableDiskSpace(path string) (uint64, error) {
+	return 0, errors.ErrUnsupported
+}
@@ -0,0 +1,137 @@
+package enum
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+)
+
+// extractEMLWithState extracts the decoded text parts of an email and the
+// text of its attachments, which are extracted like archive members when
+// their type is supported. Attached messages are walked in turn. Content
+// that can't be parsed as a message is returned whole.
+func extractEMLWithState(content []byte, state *extractState) ([]ExtractedContent, error) {
+	if len(content) == 0 {
+		return nil, nil
+	}
+	msg, err := mail.ReadMessage(bytes.NewReader(content))
+	if err != nil {
+		return []ExtractedContent{{Name: "email", Content: content}}, nil
+	}
+
+	var results []ExtractedContent
+	walkMIMEPart(textproto.MIMEHeader(msg.Header), msg.Body, "", state, &results)
+	if len(results) == 0 {
+		return []ExtractedContent{{Name: "email", Content: content}}, nil
+	}
+	return results, nil
+}
+
+// walkMIMEPart appends the text of a MIME part and its children to results.
+// Names of parts within attached messages are prefixed by the message's name.
+func walkMIMEPart(header textproto.MIMEHeader, body io.Reader, prefix string, state *extractState, results *[]ExtractedContent) {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err != nil {
+				return
+			}
+			walkMIMEPart(part.Header, part, prefix, state, results)
+		}
+	}
+
+	name := mimeFileName(header, params)
+	data, err := io.ReadAll(io.LimitReader(body, state.limits.MaxSize+1))
+	if err != nil || len(data) == 0 {
+		return
+	}
+	if int64(len(data)) > state.limits.MaxSize {
+		state.skipped(prefix+name, SkipMaxSize)
+		return
+	}
+	if state.total+int64(len(data)) > state.limits.MaxTotal {
+		state.skipped(prefix+name, SkipMaxTotal)
+		return
+	}
+	state.total += int64(len(data))
+
+	if mediaType == "message/rfc822" && state.depth < state.limits.MaxDepth {
+		if msg, err := mail.ReadMessage(bytes.NewReader(data)); err == nil {
+			if name == "" {
+				name = "message.eml"
+			}
+			msgState := &extractState{
+				depth:        state.depth + 1,
+				total:        state.total,
+				entries:      state.entries,
+				limits:       state.limits,
+				skip:         state.skip,
+				binaryAsText: state.binaryAsText,
+			}
+			walkMIMEPart(textproto.MIMEHeader(msg.Header), msg.Body, prefix+name+":", msgState, results)
+			state.total = msgState.total
+			state.entries = msgState.entries
+			return
+		}
+	}
+
+	if name != "" && isExtractable(getExtension(name)) {
+		nestedState := state.nested(prefix + name)
+		nested, err := extractWithState(name, data, nestedState)
+		if err == nil {
+			for _, n := range nested {
+				*results = append(*results, ExtractedContent{
+					Name:    prefix + name + ":" + n.Name,
+					Content: n.Content,
+				})
+			}
+		}
+		state.total = nestedState.total
+		state.entries = nestedState.entries
+		return
+	}
+
+	data, ok := state.memberText(data)
+	if !ok {
+		return
+	}
+	if name == "" {
+		name = "body"
+		if sub := strings.TrimPrefix(mediaType, "text/"); sub != mediaType && sub != "plain" {
+			name += "." + sub
+		}
+	}
+	*results = append(*results, ExtractedContent{Name: prefix + name, Content: data})
+}
+
+// mimeFileName returns the file name of an attachment, or "" for inline
+// body parts.
+func mimeFileName(header textproto.MIMEHeader, contentTypeParams map[string]string) string {
+	name := contentTypeParams["name"]
+	if _, params, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		name = params["filename"]
+	}
+	if decoded, err := new(mime.WordDecoder).DecodeHeader(name); err == nil {
+		name = decoded
+	}
+	return strings.TrimSpace(name)
+}
@@ -0,0 +1,35 @@
+package enum
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMultipartMessage = "From: ops@example.com\r\n" +
+	"Subject: new credentials\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=XYZ\r\n" +
+	"\r\n" +
+	"--XYZ\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"The new password is hunter=3D2\r\n" +
+	"--XYZ\r\n" +
+	"Content-Type: text/plain; name=\"vpn.conf\"\r\n" +
+	"Content-Disposition: attachment; filename=\"vpn.conf\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"c2VjcmV0PXMzY3IzdCEK\r\n" +
+	"--XYZ--\r\n"
+
+func TestExtractEML_Attachments(t *testing.T) {
+	results, err := extractEML([]byte(testMultipartMessage))
+	require.NoError(t, err)
+	assert.Equal(t, []ExtractedContent{
+		{Name: "body", Content: []byte("The new password is hunter=2")},
+		{Name: "vpn.conf", Content: []byte("secret=s3cr3t!\n")},
+	}, results)
+}
@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/praetorian-inc/titus/pkg/extract"
 	"github.com/praetorian-inc/titus/pkg/types"
 )
 
@@ -18,10 +19,14 @@ type Enumerator interface {
 
 // ExtractionLimits defines safety limits for archive extraction.
 type ExtractionLimits struct {
-	MaxSize        int64 // Max uncompressed size per file (10MB default)
-	MaxTotal       int64 // Max total bytes extracted from one archive (100MB default)
-	MaxDepth       int   // Max nested archive depth (5 default)
-	SQLiteRowLimit int   // Max rows per table for SQLite extraction (0 = unlimited, default 1000)
+	MaxSize        int64        // Max uncompressed size per file (10MB default)
+	MaxTotal       int64        // Max total bytes extracted from one archive (100MB default)
+	MaxDepth       int          // Max nested archive depth (5 default)
+	MaxEntries     int          // Max members extracted from one file, nested archives included (0 = unlimited, default 10000)
+	MaxRatio       int          // Max ratio of uncompressed to compressed size of archive members (0 = unlimited, default 100)
+	SQLiteRowLimit int          // Max rows per table for SQLite extraction (0 = unlimited, default 1000)
+	SQLiteScope    *SQLiteScope // Tables and columns of SQLite databases to extract (nil = all)
+	DocPasswords   []string     // Passwords tried on encrypted office documents, besides officecrypto.DefaultPasswords
 }
 
 // DefaultExtractionLimits returns the default extraction safety limits.
@@ -30,10 +35,56 @@ func DefaultExtractionLimits() ExtractionLimits {
 		MaxSize:        10 * 1024 * 1024,
 		MaxTotal:       100 * 1024 * 1024,
 		MaxDepth:       5,
+		MaxEntries:     10000,
+		MaxRatio:       100,
 		SQLiteRowLimit: 1000,
 	}
 }
 
+// extractLimits returns the limits that pkg/extract applies to archives.
+func (l ExtractionLimits) extractLimits() extract.Limits {
+	return extract.Limits{
+		MaxSize:    l.MaxSize,
+		MaxTotal:   l.MaxTotal,
+		MaxDepth:   l.MaxDepth,
+		MaxEntries: l.MaxEntries,
+		MaxRatio:   l.MaxRatio,
+	}
+}
+
+// overRatio reports whether size bytes extracted from compressed bytes is
+// over the MaxRatio limit.
+func (l ExtractionLimits) overRatio(size, compressed int64) bool {
+	return l.extractLimits().OverRatio(size, compressed)
+}
+
+// Reasons that archive members are left out by the extraction limits, as
+// passed to Config.OnSkip. A reason may go on to say more.
+const (
+	SkipMaxSize    = extract.SkipMaxSize
+	SkipMaxTotal   = extract.SkipMaxTotal
+	SkipMaxDepth   = extract.SkipMaxDepth
+	SkipMaxEntries = extract.SkipMaxEntries
+	SkipMaxRatio   = extract.SkipMaxRatio
+)
+
+// IsLimitSkip reports whether a reason passed to Config.OnSkip is one of the
+// extraction limits, and whether it's one of those that suggest an archive
+// bomb: too many entries, or too much compression.
+func IsLimitSkip(reason string) (limit, bomb bool) {
+	for _, r := range []string{SkipMaxSize, SkipMaxTotal, SkipMaxDepth, SkipMaxEntries, SkipMaxRatio} {
+		if strings.HasPrefix(reason, r) {
+			return true, r == SkipMaxEntries || r == SkipMaxRatio
+		}
+	}
+	return false, false
+}
+
+// SkipEncrypted is the reason, passed to Config.OnSkip, that an encrypted
+// office document is left out: none of the DocPasswords opened it, or its
+// encryption isn't supported. A reason may go on to say more.
+const SkipEncrypted = "encrypted, not scanned"
+
 // Config for enumeration.
 type Config struct {
 	// Root is the starting path for enumeration.
@@ -51,10 +102,39 @@ type Config struct {
 	// ExtractLimits specifies safety limits for archive extraction.
 	ExtractLimits ExtractionLimits
 
+	// TreatBinaryAsText scans binary files and archive members as they are
+	// instead of leaving them out, for forensic use. Archives are still
+	// extracted first.
+	TreatBinaryAsText bool
+
 	// IgnoreFile is a path to a gitignore-style file of path patterns to skip.
 	// If empty, the embedded default ignore.conf is used.
 	// Use "/dev/null" to disable all ignore patterns.
 	IgnoreFile string
+
+	// OnSkip, if set, is called with the path of each file or archive member
+	// that enumeration leaves out, and why (ignored, too large, binary).
+	OnSkip func(path, reason string)
+
+	// OnFile, if set, is called with the path of each file found that isn't
+	// ignored, before its size and content are checked, so that paths can be
+	// matched even for files whose content is skipped. Only the filesystem
+	// enumerator calls it, from a single goroutine; the clone enumerator's
+	// filesystem mode passes paths as "<repo name>/<path in repo>".
+	OnFile func(path string)
+
+	// PathConfig, if set, returns the config for a file from its
+	// slash-separated path relative to Root, so that size limits and
+	// extraction can differ by directory. The filesystem and git
+	// enumerators use it; for git, Root is the repository.
+	PathConfig func(relPath string) Config
+}
+
+// skip reports that path was left out of the enumeration for reason.
+func (c Config) skip(path, reason string) {
+	if c.OnSkip != nil {
+		c.OnSkip(path, reason)
+	}
 }
 
 // ValidateBaseURL checks that a user-supplied base URL uses HTTP(S).
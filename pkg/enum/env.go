@@ -0,0 +1,145 @@
+package enum
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// EnvPathPrefix starts the path of each environment variable blob, which is
+// followed by the variable's name, e.g. "env:GITHUB_TOKEN".
+const EnvPathPrefix = "env:"
+
+// envFileVars are environment variables naming a file or directory that a CI
+// job, or the tools it runs, read credentials or job data from.
+var envFileVars = []string{
+	// GitHub Actions event payload and the files steps write variables and
+	// outputs to
+	"GITHUB_EVENT_PATH",
+	"GITHUB_ENV",
+	"GITHUB_OUTPUT",
+	"GITHUB_STATE",
+	"GITHUB_STEP_SUMMARY",
+
+	// Tool credential and configuration files
+	"GOOGLE_APPLICATION_CREDENTIALS",
+	"AWS_SHARED_CREDENTIALS_FILE",
+	"AWS_CONFIG_FILE",
+	"AZURE_CONFIG_DIR",
+	"CLOUDSDK_CONFIG",
+	"DOCKER_CONFIG",
+	"KUBECONFIG",
+	"NETRC",
+	"NPM_CONFIG_USERCONFIG",
+	"PIP_CONFIG_FILE",
+	"TF_CLI_CONFIG_FILE",
+}
+
+// envCheckoutVars are environment variables naming the directory CI checked
+// the repository out to, whose .git/config may hold the clone token.
+var envCheckoutVars = []string{
+	"GITHUB_WORKSPACE",         // GitHub Actions
+	"CI_PROJECT_DIR",           // GitLab CI
+	"BITBUCKET_CLONE_DIR",      // Bitbucket Pipelines
+	"BUILD_SOURCESDIRECTORY",   // Azure Pipelines
+	"CIRCLE_WORKING_DIRECTORY", // CircleCI
+	"WORKSPACE",                // Jenkins
+}
+
+// EnvEnumerator enumerates a process environment, for secrets exposed as
+// plaintext variables on build agents, and the CI metadata files it points
+// to: the GitHub Actions event payload and variable files, credential files
+// named by tools' configuration variables, GitLab file-type variables, and
+// the checkout's .git/config.
+//
+// Each variable is yielded as its own `NAME=value` blob with FileProvenance
+// whose path is EnvPathPrefix and the name. Files are yielded as by the
+// filesystem enumerator.
+type EnvEnumerator struct {
+	config  Config
+	environ []string
+	fs      *FilesystemEnumerator
+}
+
+// NewEnvEnumerator creates an enumerator of environ, given as by os.Environ.
+// Config.Root is ignored.
+func NewEnvEnumerator(config Config, environ []string) *EnvEnumerator {
+	return &EnvEnumerator{config: config, environ: environ, fs: NewFilesystemEnumerator(config)}
+}
+
+// Enumerate yields each non-empty variable, then each metadata file.
+func (e *EnvEnumerator) Enumerate(ctx context.Context, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	for _, kv := range e.environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || name == "" || value == "" {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := e.fs.processContent(EnvPathPrefix+name, []byte(name+"="+value+"\n"), callback); err != nil {
+			return err
+		}
+	}
+
+	for _, path := range e.Files() {
+		config := e.config
+		config.Root = path
+		config.PathConfig = nil // policies are relative to the scan target, which these are outside
+		if err := NewFilesystemEnumerator(config).Enumerate(ctx, callback); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Files returns the CI metadata files and directories named by the
+// environment that exist, each once.
+func (e *EnvEnumerator) Files() []string {
+	env := make(map[string]string, len(e.environ))
+	for _, kv := range e.environ {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			env[name] = value
+		}
+	}
+
+	var candidates []string
+	for _, name := range envFileVars {
+		if value := env[name]; value != "" {
+			// KUBECONFIG is a list of files
+			candidates = append(candidates, filepath.SplitList(value)...)
+		}
+	}
+	for _, name := range envCheckoutVars {
+		if dir := env[name]; dir != "" {
+			candidates = append(candidates, filepath.Join(dir, ".git", "config"))
+		}
+	}
+	// GitLab writes the value of each file-type variable to a file in a
+	// directory beside the checkout, and sets the variable to its path
+	if dir := env["CI_PROJECT_DIR"]; dir != "" {
+		tmp := filepath.Clean(dir) + ".tmp" + string(filepath.Separator)
+		for _, kv := range e.environ {
+			if _, value, ok := strings.Cut(kv, "="); ok && strings.HasPrefix(value, tmp) {
+				candidates = append(candidates, value)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	var files []string
+	for _, path := range candidates {
+		path = filepath.Clean(path)
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, path)
+		}
+	}
+	return files
+}
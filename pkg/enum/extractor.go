@@ -1,30 +1,29 @@
 package enum
 
 import (
-	"archive/tar"
 	"archive/zip"
 	"bytes"
-	"compress/gzip"
 	"database/sql"
-	"encoding/json"
-	"encoding/xml"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
-	"os"
+	"io/fs"
+	"maps"
 	"path/filepath"
 	"strings"
-	"unicode"
+	"time"
 
 	"github.com/bodgit/sevenzip"
 	"github.com/ledongthuc/pdf"
+	"github.com/praetorian-inc/titus/pkg/extract"
+	"github.com/praetorian-inc/titus/pkg/officecrypto"
 	_ "modernc.org/sqlite"
+	"modernc.org/sqlite/vfs"
 )
 
 // ExtractedContent represents text extracted from a binary file.
-type ExtractedContent struct {
-	Name    string // path within the archive (e.g., "xl/sharedStrings.xml")
-	Content []byte // extracted text content
-}
+type ExtractedContent = extract.Content
 
 // Extractor extracts text from binary files.
 type Extractor interface {
@@ -33,9 +32,67 @@ type Extractor interface {
 
 // extractState tracks extraction progress for recursive archive processing.
 type extractState struct {
-	depth  int
-	total  int64
-	limits ExtractionLimits
+	depth        int
+	total        int64
+	entries      int
+	limits       ExtractionLimits
+	skip         func(member, reason string) // reports members left out, if set
+	binaryAsText bool                        // keep binary members, per Config.TreatBinaryAsText
+}
+
+// skipped reports that member was left out of the extraction for reason.
+func (s *extractState) skipped(member, reason string) {
+	if s.skip != nil {
+		s.skip(member, reason)
+	}
+}
+
+// nested returns the state for extracting member, an archive nested in this
+// one.
+func (s *extractState) nested(member string) *extractState {
+	nested := &extractState{
+		depth:        s.depth + 1,
+		total:        s.total,
+		entries:      s.entries,
+		limits:       s.limits,
+		binaryAsText: s.binaryAsText,
+	}
+	if s.skip != nil {
+		nested.skip = func(m, reason string) {
+			s.skip(memberPath(member, m), reason)
+		}
+	}
+	return nested
+}
+
+// memberPath returns the path of member in archive; an empty member is the
+// archive itself.
+func memberPath(archive, member string) string {
+	if member == "" {
+		return archive
+	}
+	return archive + ":" + member
+}
+
+// countEntry counts member toward the MaxEntries limit. It returns false,
+// having reported member skipped, once the limit is reached.
+func (s *extractState) countEntry(member string) bool {
+	if s.limits.MaxEntries > 0 && s.entries >= s.limits.MaxEntries {
+		s.skipped(member, SkipMaxEntries+", remaining members skipped")
+		return false
+	}
+	s.entries++
+	return true
+}
+
+// memberText returns the content of an archive member to scan, and false
+// if it's binary and should be left out. UTF-16 text is kept as is; the
+// matcher decodes it.
+func (s *extractState) memberText(data []byte) ([]byte, bool) {
+	if isBinary(data) {
+		return data, s.binaryAsText
+	}
+	return data, true
 }
 
 
@@ -67,6 +124,13 @@ func extractWithState(path string, content []byte, state *extractState) ([]Extra
 
 	ext := getExtension(path)
 
+	switch ext {
+	case ".xlsx", ".docx", ".pptx", ".odt", ".ods", ".odp":
+		if officecrypto.IsEncrypted(content) {
+			return extractEncrypted(path, content, state)
+		}
+	}
+
 	switch ext {
 	case ".xlsx":
 		return extractXLSX(content)
@@ -87,171 +151,62 @@ func extractWithState(path string, content []byte, state *extractState) ([]Extra
 	case ".odt", ".ods", ".odp":
 		return extractOpenDocument(content)
 	case ".eml":
-		return extractEML(content)
+		return extractEMLWithState(content, state)
+	case ".mbox":
+		return extractMBOXWithState(content, state)
 	case ".rtf":
 		return extractRTF(content)
 	case ".sqlite", ".db":
 		return extractSQLite(content, state)
 	case ".7z":
 		return extract7z(content, state)
+	case ".pb", ".binpb", ".protobuf":
+		return extractProtobuf(content)
+	case ".fb":
+		return extractFlatBuffer(content)
 	default:
 		return nil, fmt.Errorf("unsupported file type: %s", ext)
 	}
 }
 
-// extractXLSX extracts text from Excel files (xlsx format).
-func extractXLSX(content []byte) ([]ExtractedContent, error) {
-	reader := bytes.NewReader(content)
-	zipReader, err := zip.NewReader(reader, int64(len(content)))
+// extractEncrypted extracts text from a password-protected office document
+// once decrypted with the DocPasswords. A document that can't be decrypted
+// is reported skipped, as the member "" of its own state.
+func extractEncrypted(path string, content []byte, state *extractState) ([]ExtractedContent, error) {
+	plain, _, err := officecrypto.Decrypt(content, state.limits.DocPasswords)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open xlsx as zip: %w", err)
-	}
-
-	var results []ExtractedContent
-
-	// Extract text from sharedStrings.xml
-	for _, file := range zipReader.File {
-		if file.Name == "xl/sharedStrings.xml" {
-			rc, err := file.Open()
-			if err != nil {
-				continue
-			}
-			data, err := io.ReadAll(rc)
-			rc.Close()
-			if err != nil {
-				continue
-			}
-
-			text := extractXMLText(data)
-			if len(text) > 0 {
-				results = append(results, ExtractedContent{
-					Name:    file.Name,
-					Content: []byte(text),
-				})
-			}
-		}
-
-		// Also extract from sheet XML files
-		if strings.HasPrefix(file.Name, "xl/worksheets/sheet") && strings.HasSuffix(file.Name, ".xml") {
-			rc, err := file.Open()
-			if err != nil {
-				continue
-			}
-			data, err := io.ReadAll(rc)
-			rc.Close()
-			if err != nil {
-				continue
-			}
-
-			text := extractXMLText(data)
-			if len(text) > 0 {
-				results = append(results, ExtractedContent{
-					Name:    file.Name,
-					Content: []byte(text),
-				})
-			}
+		reason := SkipEncrypted
+		if !errors.Is(err, officecrypto.ErrPassword) {
+			reason += ", " + err.Error()
 		}
+		state.skipped("", reason)
+		return nil, nil
 	}
+	return extractWithState(path, plain, state)
+}
 
-	return results, nil
+// extractXLSX extracts text from Excel files (xlsx format).
+func extractXLSX(content []byte) ([]ExtractedContent, error) {
+	return extract.XLSX(content)
 }
 
 // extractDOCX extracts text from Word documents (docx format).
 func extractDOCX(content []byte) ([]ExtractedContent, error) {
-	reader := bytes.NewReader(content)
-	zipReader, err := zip.NewReader(reader, int64(len(content)))
-	if err != nil {
-		return nil, fmt.Errorf("failed to open docx as zip: %w", err)
-	}
-
-	var results []ExtractedContent
-
-	// Extract text from word/document.xml
-	for _, file := range zipReader.File {
-		if file.Name == "word/document.xml" {
-			rc, err := file.Open()
-			if err != nil {
-				continue
-			}
-			data, err := io.ReadAll(rc)
-			rc.Close()
-			if err != nil {
-				continue
-			}
-
-			text := extractXMLText(data)
-			if len(text) > 0 {
-				results = append(results, ExtractedContent{
-					Name:    file.Name,
-					Content: []byte(text),
-				})
-			}
-		}
-	}
-
-	return results, nil
+	return extract.DOCX(content)
 }
 
 // extractPPTX extracts text from PowerPoint files (pptx format).
 func extractPPTX(content []byte) ([]ExtractedContent, error) {
-	reader := bytes.NewReader(content)
-	zipReader, err := zip.NewReader(reader, int64(len(content)))
-	if err != nil {
-		return nil, fmt.Errorf("failed to open pptx as zip: %w", err)
-	}
-
-	var results []ExtractedContent
-
-	// Extract text from ppt/slides/*.xml
-	for _, file := range zipReader.File {
-		if strings.HasPrefix(file.Name, "ppt/slides/slide") && strings.HasSuffix(file.Name, ".xml") {
-			rc, err := file.Open()
-			if err != nil {
-				continue
-			}
-			data, err := io.ReadAll(rc)
-			rc.Close()
-			if err != nil {
-				continue
-			}
-
-			text := extractXMLText(data)
-			if len(text) > 0 {
-				results = append(results, ExtractedContent{
-					Name:    file.Name,
-					Content: []byte(text),
-				})
-			}
-		}
-	}
-
-	return results, nil
+	return extract.PPTX(content)
 }
 
-// extractPDF extracts text from PDF files using ledongthuc/pdf.
+// extractPDF extracts text from PDF files using ledongthuc/pdf. The PDF is
+// read from memory, so its content never touches disk.
 func extractPDF(content []byte) ([]ExtractedContent, error) {
-	// Create a temporary file since ledongthuc/pdf requires a file or ReaderAt with size
-	tmpFile, err := os.CreateTemp("", "pdf-*.pdf")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temp file: %w", err)
-	}
-	defer os.Remove(tmpFile.Name())
-	defer tmpFile.Close()
-
-	// Write the PDF content to the temp file
-	if _, err := tmpFile.Write(content); err != nil {
-		return nil, fmt.Errorf("failed to write temp file: %w", err)
-	}
-
-	// Close the file so pdf.Open can read it
-	tmpFile.Close()
-
-	// Open the PDF file
-	f, r, err := pdf.Open(tmpFile.Name())
+	r, err := pdf.NewReader(bytes.NewReader(content), int64(len(content)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open PDF: %w", err)
 	}
-	defer f.Close()
 
 	// Extract text from all pages
 	var text strings.Builder
@@ -289,252 +244,69 @@ func extractPDF(content []byte) ([]ExtractedContent, error) {
 
 // extractIPYNB extracts code and markdown cells from Jupyter notebooks.
 func extractIPYNB(content []byte) ([]ExtractedContent, error) {
-	var notebook struct {
-		Cells []struct {
-			CellType string   `json:"cell_type"`
-			Source   []string `json:"source"`
-		} `json:"cells"`
-	}
-
-	if err := json.Unmarshal(content, &notebook); err != nil {
-		return nil, fmt.Errorf("failed to parse ipynb: %w", err)
-	}
-
-	var results []ExtractedContent
-	for i, cell := range notebook.Cells {
-		if cell.CellType == "code" || cell.CellType == "markdown" {
-			cellContent := strings.Join(cell.Source, "")
-			if len(strings.TrimSpace(cellContent)) > 0 {
-				results = append(results, ExtractedContent{
-					Name:    fmt.Sprintf("cell_%d_%s", i, cell.CellType),
-					Content: []byte(cellContent),
-				})
-			}
-		}
-	}
-
-	return results, nil
+	return extract.IPYNB(content)
 }
 
 // extractTar extracts text from tar archives (optionally gzipped).
 func extractTar(content []byte, isGzipped bool, state *extractState) ([]ExtractedContent, error) {
-	var reader io.Reader = bytes.NewReader(content)
-
-	// Decompress if gzipped
-	if isGzipped {
-		gzr, err := gzip.NewReader(reader)
-		if err != nil {
-			return nil, fmt.Errorf("failed to open gzip: %w", err)
-		}
-		defer gzr.Close()
-		reader = gzr
-	}
-
-	tarReader := tar.NewReader(reader)
-	var results []ExtractedContent
-
-	for {
-		header, err := tarReader.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, fmt.Errorf("failed to read tar: %w", err)
-		}
-
-		// Skip directories
-		if header.Typeflag == tar.TypeDir {
-			continue
-		}
-
-		// Check size limits
-		if header.Size > state.limits.MaxSize {
-			continue
-		}
-		if state.total+header.Size > state.limits.MaxTotal {
-			break // Stop extraction
-		}
-
-		data, err := io.ReadAll(tarReader)
-		if err != nil {
-			continue
-		}
-
-		state.total += int64(len(data))
-
-		// Check if it's a nested extractable file
-		ext := getExtension(header.Name)
-		if isExtractable(ext) {
-			// Recurse with incremented depth
-			nestedState := &extractState{
-				depth:  state.depth + 1,
-				total:  state.total,
-				limits: state.limits,
-			}
-			nested, err := extractWithState(header.Name, data, nestedState)
-			if err == nil {
-				for _, n := range nested {
-					results = append(results, ExtractedContent{
-						Name:    header.Name + ":" + n.Name,
-						Content: n.Content,
-					})
-				}
-			}
-			state.total = nestedState.total
-			continue
-		}
-
-		// Skip binary files
-		if isBinaryContent(data) {
-			continue
-		}
-
-		results = append(results, ExtractedContent{
-			Name:    header.Name,
-			Content: data,
-		})
-	}
-
-	return results, nil
+	w := state.walk()
+	results, err := extract.Tar(content, isGzipped, w)
+	state.total, state.entries = w.Total, w.Entries
+	return results, err
 }
 
 // extractXMLText extracts text content from XML data.
-// It parses XML and collects all text nodes.
 func extractXMLText(data []byte) string {
-	var text strings.Builder
-	decoder := xml.NewDecoder(bytes.NewReader(data))
-
-	for {
-		token, err := decoder.Token()
-		if err != nil {
-			break
-		}
-
-		switch t := token.(type) {
-		case xml.CharData:
-			content := string(t)
-			// Only add if it contains non-whitespace
-			if strings.TrimSpace(content) != "" {
-				if text.Len() > 0 {
-					text.WriteString(" ")
-				}
-				// Clean up the text - remove extra whitespace
-				text.WriteString(cleanText(content))
-			}
-		}
-	}
-
-	return text.String()
-}
-
-// cleanText removes extra whitespace and non-printable characters.
-func cleanText(s string) string {
-	var result strings.Builder
-	lastSpace := false
-
-	for _, r := range s {
-		if unicode.IsSpace(r) {
-			if !lastSpace {
-				result.WriteRune(' ')
-				lastSpace = true
-			}
-		} else if unicode.IsPrint(r) {
-			result.WriteRune(r)
-			lastSpace = false
-		}
-	}
-
-	return strings.TrimSpace(result.String())
+	return extract.XMLText(data)
 }
 
 // extractZIPWithState extracts text from ZIP archives with state tracking.
 func extractZIPWithState(content []byte, state *extractState) ([]ExtractedContent, error) {
-	reader := bytes.NewReader(content)
-	zipReader, err := zip.NewReader(reader, int64(len(content)))
-	if err != nil {
-		return nil, fmt.Errorf("failed to open zip: %w", err)
-	}
-
-	var results []ExtractedContent
-
-	for _, file := range zipReader.File {
-		if file.FileInfo().IsDir() {
-			continue
-		}
-
-		// Check size limits
-		if file.UncompressedSize64 > uint64(state.limits.MaxSize) {
-			continue
-		}
-		if state.total+int64(file.UncompressedSize64) > state.limits.MaxTotal {
-			break // Stop extraction
-		}
-
-		rc, err := file.Open()
-		if err != nil {
-			continue
-		}
-		data, err := io.ReadAll(rc)
-		rc.Close()
-		if err != nil {
-			continue
-		}
-
-		state.total += int64(len(data))
+	w := state.walk()
+	results, err := extract.ZIP(content, w)
+	state.total, state.entries = w.Total, w.Entries
+	return results, err
+}
 
-		// Check if it's a nested extractable file
-		ext := getExtension(file.Name)
-		if isExtractable(ext) {
-			// Recurse with incremented depth
-			nestedState := &extractState{
-				depth:  state.depth + 1,
-				total:  state.total,
-				limits: state.limits,
+// walk returns the pkg/extract walk of a zip or tar archive at state. Its
+// members are extracted in turn if they are in one of the formats this
+// package extracts, rather than only those pkg/extract does.
+func (s *extractState) walk() *extract.Walk {
+	return &extract.Walk{
+		Limits:     s.limits.extractLimits(),
+		Depth:      s.depth,
+		Total:      s.total,
+		Entries:    s.entries,
+		Skip:       s.skip,
+		KeepBinary: s.binaryAsText,
+		Nested: func(name string, data []byte, nested *extract.Walk) ([]ExtractedContent, bool, error) {
+			if !isExtractable(getExtension(name)) {
+				return nil, false, nil
 			}
-			nested, err := extractWithState(file.Name, data, nestedState)
-			if err == nil {
-				for _, n := range nested {
-					results = append(results, ExtractedContent{
-						Name:    file.Name + ":" + n.Name,
-						Content: n.Content,
-					})
-				}
+			nestedState := &extractState{
+				depth:        nested.Depth,
+				total:        nested.Total,
+				entries:      nested.Entries,
+				limits:       s.limits,
+				skip:         nested.Skip,
+				binaryAsText: s.binaryAsText,
 			}
-			state.total = nestedState.total
-			continue
-		}
-
-		// Skip binary files
-		if isBinaryContent(data) {
-			continue
-		}
-
-		results = append(results, ExtractedContent{
-			Name:    file.Name,
-			Content: data,
-		})
+			results, err := extractWithState(name, data, nestedState)
+			nested.Total, nested.Entries = nestedState.total, nestedState.entries
+			return results, true, err
+		},
 	}
-
-	return results, nil
 }
 
 // isExtractable checks if a file extension is extractable.
 func isExtractable(ext string) bool {
 	switch ext {
-	case ".zip", ".jar", ".war", ".ear", ".apk", ".ipa", ".xpi", ".crx", ".xlsx", ".docx", ".pptx", ".pdf", ".tar", ".tar.gz", ".tgz", ".ipynb", ".odt", ".ods", ".odp", ".eml", ".rtf", ".sqlite", ".db", ".7z":
+	case ".zip", ".jar", ".war", ".ear", ".apk", ".ipa", ".xpi", ".crx", ".xlsx", ".docx", ".pptx", ".pdf", ".tar", ".tar.gz", ".tgz", ".ipynb", ".odt", ".ods", ".odp", ".eml", ".mbox", ".rtf", ".sqlite", ".db", ".7z", ".pb", ".binpb", ".protobuf", ".fb":
 		return true
 	}
 	return false
 }
 
-// isBinaryContent detects if content is binary by checking for null bytes.
-func isBinaryContent(content []byte) bool {
-	checkSize := len(content)
-	if checkSize > 8192 {
-		checkSize = 8192
-	}
-	return bytes.IndexByte(content[:checkSize], 0) != -1
-}
 // extractOpenDocument extracts text from OpenDocument files (.odt, .ods, .odp).
 func extractOpenDocument(content []byte) ([]ExtractedContent, error) {
 	reader := bytes.NewReader(content)
@@ -566,13 +338,7 @@ func extractOpenDocument(content []byte) ([]ExtractedContent, error) {
 
 // extractEML extracts text from email files (.eml).
 func extractEML(content []byte) ([]ExtractedContent, error) {
-	// EML is mostly text, just return the whole content
-	// Could parse headers but body often contains secrets
-	text := string(content)
-	if len(text) == 0 {
-		return nil, nil
-	}
-	return []ExtractedContent{{Name: "email", Content: content}}, nil
+	return extractEMLWithState(content, &extractState{limits: DefaultExtractionLimits()})
 }
 
 // extractRTF extracts text from Rich Text Format files (.rtf).
@@ -611,26 +377,172 @@ func extractRTF(content []byte) ([]ExtractedContent, error) {
 
 // extractSQLite extracts text from SQLite database files (.sqlite, .db).
 func extractSQLite(content []byte, state *extractState) ([]ExtractedContent, error) {
-	// Write to temp file (SQLite needs file)
-	tmpFile, err := os.CreateTemp("", "titus-sqlite-*.db")
+	db, cleanup, err := openSQLite(content, nil, state.limits.MaxSize)
 	if err != nil {
 		return nil, err
 	}
-	defer os.Remove(tmpFile.Name())
-	defer tmpFile.Close()
+	defer cleanup()
+	return dumpSQLite(db, state.limits)
+}
 
-	if _, err := tmpFile.Write(content); err != nil {
-		return nil, err
+// openSQLite opens a database from memory, through a read-only VFS, so its
+// content never touches disk. A non-empty wal is the database's write-ahead
+// log; its committed frames are applied so that uncheckpointed changes are
+// read too, as long as the database doesn't grow past maxSize (0 for no
+// limit). The returned cleanup closes the database.
+func openSQLite(content, wal []byte, maxSize int64) (*sql.DB, func(), error) {
+	if len(content) == 0 {
+		// An empty file is an empty database, which the VFS can't read
+		db, err := sql.Open("sqlite", ":memory:")
+		if err != nil {
+			return nil, nil, err
+		}
+		return db, func() { db.Close() }, nil
 	}
-	tmpFile.Close()
 
-	db, err := sql.Open("sqlite", tmpFile.Name())
+	name, fsys, err := vfs.New(sqliteFS(sqliteImage(content, wal, maxSize)))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	db, err := sql.Open("sqlite", "file:"+sqliteFileName+"?vfs="+name+"&mode=ro&immutable=1")
+	if err != nil {
+		fsys.Close()
+		return nil, nil, err
 	}
-	defer db.Close()
+	return db, func() {
+		db.Close()
+		fsys.Close()
+	}, nil
+}
+
+// sqliteFileName is the name sqliteFS serves its database as.
+const sqliteFileName = "db.sqlite"
+
+// sqliteFS is a file system holding one database file, in memory.
+type sqliteFS []byte
 
+func (f sqliteFS) Open(name string) (fs.File, error) {
+	if name != sqliteFileName {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return sqliteFile{bytes.NewReader(f)}, nil
+}
+
+// sqliteFile is an open sqliteFS file. SQLite's VFS seeks and reads it.
+type sqliteFile struct{ *bytes.Reader }
+
+func (f sqliteFile) Stat() (fs.FileInfo, error) { return f, nil }
+func (f sqliteFile) Close() error               { return nil }
+func (f sqliteFile) Name() string               { return sqliteFileName }
+func (f sqliteFile) Mode() fs.FileMode          { return 0400 }
+func (f sqliteFile) ModTime() time.Time         { return time.Time{} }
+func (f sqliteFile) IsDir() bool                { return false }
+func (f sqliteFile) Sys() any                   { return nil }
+
+// WAL file layout; see https://www.sqlite.org/fileformat.html#the_write_ahead_log
+const (
+	walHeaderSize      = 32
+	walFrameHeaderSize = 24
+)
+
+// sqliteImage returns a copy of a database with the committed frames of its
+// write-ahead log applied, as a checkpoint would, and marked as a rollback
+// journal database, since the read-only VFS has no WAL support. Frames
+// after the last commit, or past the first that fails the log's salt or
+// checksum, are ignored, as SQLite ignores them. So are commits that would
+// grow the database past maxSize (0 for no limit), or past the size of the
+// database and log together, which only a corrupt log claims.
+func sqliteImage(content, wal []byte, maxSize int64) []byte {
+	size := len(content)
+	pageSize, pages := 0, make(map[uint32][]byte)
+	if len(wal) >= walHeaderSize {
+		limit := int64(len(content) + len(wal))
+		if maxSize > 0 && maxSize < limit {
+			limit = maxSize
+		}
+		pageSize, size, pages = walCommittedPages(wal, size, limit)
+	}
+
+	image := make([]byte, size)
+	copy(image, content)
+	for pgno, data := range pages {
+		if offset := int(pgno-1) * pageSize; offset+pageSize <= size {
+			copy(image[offset:], data)
+		}
+	}
+	if len(image) > 19 && image[18] == 2 && image[19] == 2 {
+		image[18], image[19] = 1, 1
+	}
+	return image
+}
+
+// walCommittedPages returns the page size of a write-ahead log, the size of
+// the database after its last valid commit (or size if there is none), and
+// the last committed content of each page it holds. Reading stops at a
+// commit that makes the database larger than maxSize.
+func walCommittedPages(wal []byte, size int, maxSize int64) (int, int, map[uint32][]byte) {
+	pages := make(map[uint32][]byte)
+	var order binary.ByteOrder
+	// The magic number gives the byte order of the checksums
+	switch binary.BigEndian.Uint32(wal) {
+	case 0x377f0682:
+		order = binary.LittleEndian
+	case 0x377f0683:
+		order = binary.BigEndian
+	default:
+		return 0, size, pages
+	}
+	pageSize := int(binary.BigEndian.Uint32(wal[8:]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize > 65536 || pageSize&(pageSize-1) != 0 {
+		return 0, size, pages
+	}
+	s1, s2 := walChecksum(order, 0, 0, wal[:24])
+	if s1 != binary.BigEndian.Uint32(wal[24:]) || s2 != binary.BigEndian.Uint32(wal[28:]) {
+		return 0, size, pages
+	}
+
+	pending := make(map[uint32][]byte)
+	for offset := walHeaderSize; offset+walFrameHeaderSize+pageSize <= len(wal); offset += walFrameHeaderSize + pageSize {
+		header := wal[offset : offset+walFrameHeaderSize]
+		data := wal[offset+walFrameHeaderSize : offset+walFrameHeaderSize+pageSize]
+		if !bytes.Equal(header[8:16], wal[16:24]) {
+			break
+		}
+		s1, s2 = walChecksum(order, s1, s2, header[:8])
+		s1, s2 = walChecksum(order, s1, s2, data)
+		if s1 != binary.BigEndian.Uint32(header[16:]) || s2 != binary.BigEndian.Uint32(header[20:]) {
+			break
+		}
+		pending[binary.BigEndian.Uint32(header)] = data
+		if dbPages := binary.BigEndian.Uint32(header[4:]); dbPages > 0 {
+			if int64(dbPages)*int64(pageSize) > maxSize {
+				break
+			}
+			maps.Copy(pages, pending)
+			clear(pending)
+			size = int(dbPages) * pageSize
+		}
+	}
+	return pageSize, size, pages
+}
+
+// walChecksum continues the cumulative checksum of a write-ahead log over b.
+func walChecksum(order binary.ByteOrder, s1, s2 uint32, b []byte) (uint32, uint32) {
+	for i := 0; i+8 <= len(b); i += 8 {
+		s1 += order.Uint32(b[i:]) + s2
+		s2 += order.Uint32(b[i+4:]) + s1
+	}
+	return s1, s2
+}
+
+// dumpSQLite renders the text columns of every table in limits' SQLite
+// scope, up to its row limit per table (0 for no limit).
+func dumpSQLite(db *sql.DB, limits ExtractionLimits) ([]ExtractedContent, error) {
 	var text strings.Builder
+	scope := limits.SQLiteScope
 
 	// Get all table names
 	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type='table'")
@@ -645,14 +557,16 @@ func extractSQLite(content []byte, state *extractState) ([]ExtractedContent, err
 		if err := rows.Scan(&name); err != nil {
 			continue
 		}
-		tables = append(tables, name)
+		if scope.Table(name) {
+			tables = append(tables, name)
+		}
 	}
 
 	// Extract text from each table (limit rows to prevent huge output)
 	for _, table := range tables {
 		query := fmt.Sprintf("SELECT * FROM %q", table)
-		if state.limits.SQLiteRowLimit > 0 {
-			query += fmt.Sprintf(" LIMIT %d", state.limits.SQLiteRowLimit)
+		if limits.SQLiteRowLimit > 0 {
+			query += fmt.Sprintf(" LIMIT %d", limits.SQLiteRowLimit)
 		}
 		rows, err := db.Query(query)
 		if err != nil {
@@ -664,13 +578,17 @@ func extractSQLite(content []byte, state *extractState) ([]ExtractedContent, err
 		for i := range values {
 			ptrs[i] = &values[i]
 		}
+		selected := make([]bool, len(cols))
+		for i, col := range cols {
+			selected[i] = scope.Column(table, col)
+		}
 
 		for rows.Next() {
 			if err := rows.Scan(ptrs...); err != nil {
 				continue
 			}
-			for _, v := range values {
-				if s, ok := v.(string); ok {
+			for i, v := range values {
+				if s, ok := v.(string); ok && selected[i] {
 					text.WriteString(s)
 					text.WriteString(" ")
 				}
@@ -696,16 +614,27 @@ func extract7z(content []byte, state *extractState) ([]ExtractedContent, error)
 	}
 
 	var results []ExtractedContent
+	var declared int64 // member sizes; 7z compresses members together
 	for _, file := range archive.File {
 		if file.FileInfo().IsDir() {
 			continue
 		}
+		if !state.countEntry(file.Name) {
+			break
+		}
 
 		// Check size limits
+		declared += int64(file.UncompressedSize)
+		if state.limits.overRatio(declared, int64(len(content))) {
+			state.skipped(file.Name, SkipMaxRatio+", remaining members skipped")
+			break
+		}
 		if file.UncompressedSize > uint64(state.limits.MaxSize) {
+			state.skipped(file.Name, SkipMaxSize)
 			continue
 		}
 		if state.total+int64(file.UncompressedSize) > state.limits.MaxTotal {
+			state.skipped(file.Name, SkipMaxTotal+", remaining members skipped")
 			break
 		}
 
@@ -713,11 +642,16 @@ func extract7z(content []byte, state *extractState) ([]ExtractedContent, error)
 		if err != nil {
 			continue
 		}
-		data, err := io.ReadAll(rc)
+		// Don't trust the declared size
+		data, err := io.ReadAll(io.LimitReader(rc, state.limits.MaxSize+1))
 		rc.Close()
 		if err != nil {
 			continue
 		}
+		if int64(len(data)) > state.limits.MaxSize {
+			state.skipped(file.Name, SkipMaxSize)
+			continue
+		}
 		state.total += int64(len(data))
 
 		// Check for nested extractable files
@@ -725,23 +659,33 @@ func extract7z(content []byte, state *extractState) ([]ExtractedContent, error)
 		if isExtractable(ext) {
 			state.depth++
 			if state.depth <= state.limits.MaxDepth {
+				skip := state.skip
+				if skip != nil {
+					name := file.Name
+					state.skip = func(m, reason string) { skip(memberPath(name, m), reason) }
+				}
 				nested, _ := extractWithState(file.Name, data, state)
+				state.skip = skip
 				for _, n := range nested {
 					results = append(results, ExtractedContent{
 						Name:    file.Name + ":" + n.Name,
 						Content: n.Content,
 					})
 				}
+			} else {
+				state.skipped(file.Name, SkipMaxDepth)
 			}
 			state.depth--
 			continue
 		}
 
-		if isBinaryContent(data) {
+		text, ok := state.memberText(data)
+		if !ok {
+			state.skipped(file.Name, "binary")
 			continue
 		}
 
-		results = append(results, ExtractedContent{Name: file.Name, Content: data})
+		results = append(results, ExtractedContent{Name: file.Name, Content: text})
 	}
 	return results, nil
 }
@@ -1,7 +1,12 @@
 package enum
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
 	"database/sql"
+	"encoding/binary"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -362,6 +367,129 @@ func TestExtractText_SQLite(t *testing.T) {
 	}
 }
 
+// TestExtractText_SQLiteWAL tests that changes only in a database's
+// write-ahead log are extracted.
+func TestExtractText_SQLiteWAL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	for _, stmt := range []string{
+		"PRAGMA journal_mode=WAL",
+		"PRAGMA wal_autocheckpoint=0",
+		"CREATE TABLE config (key TEXT, value TEXT)",
+		"PRAGMA wal_checkpoint(TRUNCATE)",
+		"INSERT INTO config VALUES ('aws_key', '" + testSecret + "')",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	// Read the files while the connection is open, before a checkpoint
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read database: %v", err)
+	}
+	wal, err := os.ReadFile(path + "-wal")
+	if err != nil {
+		t.Fatalf("failed to read write-ahead log: %v", err)
+	}
+
+	dump := func(wal []byte) string {
+		sqlDB, cleanup, err := openSQLite(content, wal, 0)
+		if err != nil {
+			t.Fatalf("openSQLite() error = %v", err)
+		}
+		defer cleanup()
+		results, err := dumpSQLite(sqlDB, ExtractionLimits{})
+		if err != nil {
+			t.Fatalf("dumpSQLite() error = %v", err)
+		}
+		if len(results) == 0 {
+			return ""
+		}
+		return string(results[0].Content)
+	}
+
+	if got := dump(nil); strings.Contains(got, testSecret) {
+		t.Errorf("database without its log contains the uncheckpointed row: %q", got)
+	}
+	if got := dump(wal); !strings.Contains(got, testSecret) {
+		t.Errorf("database with its log does not contain secret: %q", got)
+	}
+
+	// A torn frame at the end of the log is ignored
+	if got := dump(wal[:len(wal)-100]); strings.Contains(got, testSecret) {
+		t.Errorf("truncated log was applied: %q", got)
+	}
+}
+
+// TestSQLiteImage_CommitSize tests that a log committing a database larger
+// than the limit, or than the database and log together, isn't applied.
+func TestSQLiteImage_CommitSize(t *testing.T) {
+	const pageSize = 512
+	wal := func(dbPages uint32) []byte {
+		b := make([]byte, walHeaderSize+walFrameHeaderSize+pageSize)
+		binary.BigEndian.PutUint32(b, 0x377f0682)
+		binary.BigEndian.PutUint32(b[8:], pageSize)
+		s1, s2 := walChecksum(binary.LittleEndian, 0, 0, b[:24])
+		binary.BigEndian.PutUint32(b[24:], s1)
+		binary.BigEndian.PutUint32(b[28:], s2)
+		frame := b[walHeaderSize:]
+		binary.BigEndian.PutUint32(frame, 1)
+		binary.BigEndian.PutUint32(frame[4:], dbPages)
+		s1, s2 = walChecksum(binary.LittleEndian, s1, s2, frame[:8])
+		s1, s2 = walChecksum(binary.LittleEndian, s1, s2, frame[walFrameHeaderSize:])
+		binary.BigEndian.PutUint32(frame[16:], s1)
+		binary.BigEndian.PutUint32(frame[20:], s2)
+		return b
+	}
+	content := make([]byte, 2*pageSize)
+
+	tests := []struct {
+		name    string
+		dbPages uint32
+		maxSize int64
+		want    int
+	}{
+		{"within both", 3, 0, 3 * pageSize},
+		{"past max size", 3, 2 * pageSize, 2 * pageSize},
+		{"past database and log", 1 << 31, 0, 2 * pageSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(sqliteImage(content, wal(tt.dbPages), tt.maxSize)); got != tt.want {
+				t.Errorf("sqliteImage() size = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestExtractText_NoTempFiles tests that PDFs and SQLite databases are
+// extracted without writing their content to disk.
+func TestExtractText_NoTempFiles(t *testing.T) {
+	// Creating a temporary file fails
+	t.Setenv("TMPDIR", filepath.Join(t.TempDir(), "missing"))
+
+	for _, name := range []string{"test.pdf", "test.sqlite"} {
+		content, err := os.ReadFile(filepath.Join("../../testdata/extraction", name))
+		if err != nil {
+			t.Fatalf("failed to read test file: %v", err)
+		}
+		results, err := ExtractText(name, content, DefaultExtractionLimits())
+		if err != nil {
+			t.Fatalf("ExtractText(%s) error = %v", name, err)
+		}
+		if len(results) == 0 || !strings.Contains(string(results[0].Content), testSecret) {
+			t.Errorf("ExtractText(%s) does not contain secret: %v", name, results)
+		}
+	}
+}
+
 // TestExtractText_IPYNB tests Jupyter notebook extraction.
 func TestExtractText_IPYNB(t *testing.T) {
 	testPath := "../../testdata/extraction/test.ipynb"
@@ -447,6 +575,77 @@ func TestExtractText_Archives(t *testing.T) {
 	}
 }
 
+// TestExtractText_Encrypted tests that password-protected office documents
+// are decrypted with DocPasswords, and reported skipped otherwise.
+func TestExtractText_Encrypted(t *testing.T) {
+	tests := []struct {
+		filename string
+		member   string
+	}{
+		{"encrypted.docx", "word/document.xml"},
+		{"encrypted.odt", "content.xml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			content, err := os.ReadFile(filepath.Join("../../testdata/extraction", tt.filename))
+			if err != nil {
+				t.Fatalf("failed to read test file: %v", err)
+			}
+
+			limits := DefaultExtractionLimits()
+			limits.DocPasswords = []string{"wrong", "titus"}
+			results, err := ExtractText(tt.filename, content, limits)
+			if err != nil {
+				t.Fatalf("ExtractText() error = %v", err)
+			}
+			if len(results) != 1 || results[0].Name != tt.member || !strings.Contains(string(results[0].Content), testSecret) {
+				t.Errorf("expected the secret in %s, got %v", tt.member, results)
+			}
+
+			// Without the password, the document is reported as itself
+			var skipped []string
+			results, err = extractWithState(tt.filename, content, &extractState{
+				limits: DefaultExtractionLimits(),
+				skip: func(member, reason string) {
+					skipped = append(skipped, fmt.Sprintf("%q: %s", member, reason))
+				},
+			})
+			if err != nil || len(results) != 0 {
+				t.Errorf("expected nothing extracted, got %v, %v", results, err)
+			}
+			if want := []string{`"": ` + SkipEncrypted}; fmt.Sprint(skipped) != fmt.Sprint(want) {
+				t.Errorf("skipped = %v, want %v", skipped, want)
+			}
+		})
+	}
+
+	// Nested in an archive, the document is reported as the archive member
+	content, err := os.ReadFile("../../testdata/extraction/encrypted.docx")
+	if err != nil {
+		t.Fatalf("failed to read test file: %v", err)
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("docs/report.docx")
+	w.Write(content)
+	zw.Close()
+
+	var skipped []string
+	_, err = extractWithState("bundle.zip", buf.Bytes(), &extractState{
+		limits: DefaultExtractionLimits(),
+		skip: func(member, reason string) {
+			skipped = append(skipped, member+": "+reason)
+		},
+	})
+	if err != nil {
+		t.Fatalf("extractWithState() error = %v", err)
+	}
+	if want := []string{"docs/report.docx: " + SkipEncrypted}; fmt.Sprint(skipped) != fmt.Sprint(want) {
+		t.Errorf("skipped = %v, want %v", skipped, want)
+	}
+}
+
 // TestExtractionLimits tests that extraction limits are enforced.
 func TestExtractionLimits(t *testing.T) {
 	t.Run("MaxSize", func(t *testing.T) {
@@ -524,6 +723,87 @@ func TestExtractionLimits(t *testing.T) {
 			t.Error("Expected results at depth 0")
 		}
 	})
+
+	t.Run("MaxEntries", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		for i := range 5 {
+			w, _ := zw.Create(fmt.Sprintf("file%d.txt", i))
+			fmt.Fprintf(w, "content %d", i)
+		}
+		zw.Close()
+
+		skipped := make(map[string]string)
+		state := &extractState{
+			limits: ExtractionLimits{MaxSize: 1024, MaxTotal: 1024, MaxDepth: 5, MaxEntries: 3},
+			skip:   func(member, reason string) { skipped[member] = reason },
+		}
+		results, err := extractWithState("many.zip", buf.Bytes(), state)
+		if err != nil {
+			t.Fatalf("extractWithState() error = %v", err)
+		}
+		if len(results) != 3 {
+			t.Errorf("Expected 3 results with MaxEntries=3, got %d", len(results))
+		}
+		if limit, bomb := IsLimitSkip(skipped["file3.txt"]); !limit || !bomb {
+			t.Errorf("Expected file3.txt skipped for max entries, got %v", skipped)
+		}
+	})
+
+	t.Run("MaxRatio", func(t *testing.T) {
+		padding := bytes.Repeat([]byte("a"), 2<<20)
+		var zipBuf bytes.Buffer
+		zw := zip.NewWriter(&zipBuf)
+		w, _ := zw.Create("padding.txt")
+		w.Write(padding)
+		w, _ = zw.Create("config.txt")
+		w.Write([]byte(testSecret))
+		zw.Close()
+
+		var tgzBuf bytes.Buffer
+		gw := gzip.NewWriter(&tgzBuf)
+		tw := tar.NewWriter(gw)
+		tw.WriteHeader(&tar.Header{Name: "padding.txt", Mode: 0644, Size: int64(len(padding))})
+		tw.Write(padding)
+		tw.WriteHeader(&tar.Header{Name: "config.txt", Mode: 0644, Size: int64(len(testSecret))})
+		tw.Write([]byte(testSecret))
+		tw.Close()
+		gw.Close()
+
+		tests := []struct {
+			name    string
+			content []byte
+			want    int // results
+		}{
+			{"bomb.zip", zipBuf.Bytes(), 1}, // per member
+			{"bomb.tar.gz", tgzBuf.Bytes(), 0},
+		}
+		for _, tt := range tests {
+			skipped := make(map[string]string)
+			state := &extractState{
+				limits: DefaultExtractionLimits(),
+				skip:   func(member, reason string) { skipped[member] = reason },
+			}
+			results, err := extractWithState(tt.name, tt.content, state)
+			if err != nil {
+				t.Fatalf("extractWithState(%s) error = %v", tt.name, err)
+			}
+			if len(results) != tt.want {
+				t.Errorf("%s: expected %d results, got %d", tt.name, tt.want, len(results))
+			}
+			if !strings.HasPrefix(skipped["padding.txt"], SkipMaxRatio) {
+				t.Errorf("%s: expected padding.txt skipped for compression ratio, got %v", tt.name, skipped)
+			}
+
+			// Without the limit, the padding is extracted
+			state = &extractState{limits: DefaultExtractionLimits()}
+			state.limits.MaxRatio = 0
+			results, _ = extractWithState(tt.name, tt.content, state)
+			if len(results) != 2 {
+				t.Errorf("%s: expected 2 results with MaxRatio=0, got %d", tt.name, len(results))
+			}
+		}
+	})
 }
 
 // TestUnsupportedFormat tests that unsupported formats return an error.
@@ -581,7 +861,8 @@ func TestIsExtractable(t *testing.T) {
 	extractable := []string{
 		".zip", ".jar", ".war", ".ear", ".apk", ".ipa", ".xpi", ".crx",
 		".xlsx", ".docx", ".pptx", ".pdf", ".tar", ".tar.gz", ".tgz",
-		".ipynb", ".odt", ".ods", ".odp", ".eml", ".rtf", ".sqlite", ".db", ".7z",
+		".ipynb", ".odt", ".ods", ".odp", ".eml", ".mbox", ".rtf", ".sqlite", ".db", ".7z",
+		".pb", ".binpb", ".protobuf", ".fb",
 	}
 
 	notExtractable := []string{
@@ -605,8 +886,8 @@ func TestIsExtractable(t *testing.T) {
 	}
 }
 
-// TestIsBinaryContent tests the isBinaryContent helper function.
-func TestIsBinaryContent(t *testing.T) {
+// TestIsBinary tests the isBinary helper function.
+func TestIsBinary(t *testing.T) {
 	tests := []struct {
 		name    string
 		content []byte
@@ -627,6 +908,16 @@ func TestIsBinaryContent(t *testing.T) {
 			content: []byte("Hello\x00World"),
 			want:    true,
 		},
+		{
+			name:    "mostly text with a null byte",
+			content: []byte("password = hunter2\nuser = admin\nhost = db.internal\x00\n"),
+			want:    false,
+		},
+		{
+			name:    "UTF-16LE with byte order mark",
+			content: []byte("\xff\xfek\x00e\x00y\x00"),
+			want:    false,
+		},
 		{
 			name:    "empty content",
 			content: []byte{},
@@ -636,9 +927,9 @@ func TestIsBinaryContent(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := isBinaryContent(tt.content)
+			got := isBinary(tt.content)
 			if got != tt.want {
-				t.Errorf("isBinaryContent() = %v, want %v", got, tt.want)
+				t.Errorf("isBinary() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -729,42 +1020,3 @@ func buildSQLiteWithRows(t *testing.T, n int) []byte {
 	}
 	return content
 }
-
-// TestCleanText tests the cleanText helper function.
-func TestCleanText(t *testing.T) {
-	tests := []struct {
-		name  string
-		input string
-		want  string
-	}{
-		{
-			name:  "multiple spaces",
-			input: "Hello    World",
-			want:  "Hello World",
-		},
-		{
-			name:  "leading and trailing spaces",
-			input: "  Hello World  ",
-			want:  "Hello World",
-		},
-		{
-			name:  "newlines and tabs",
-			input: "Hello\n\tWorld",
-			want:  "Hello World",
-		},
-		{
-			name:  "normal text",
-			input: "Hello World",
-			want:  "Hello World",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := cleanText(tt.input)
-			if got != tt.want {
-				t.Errorf("cleanText() = %q, want %q", got, tt.want)
-			}
-		})
-	}
-}
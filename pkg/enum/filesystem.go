@@ -1,7 +1,6 @@
 package enum
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -10,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/praetorian-inc/titus/pkg/enum/ignore"
+	"github.com/praetorian-inc/titus/pkg/extract"
 	"github.com/praetorian-inc/titus/pkg/types"
 	"golang.org/x/sync/errgroup"
 )
@@ -27,6 +27,7 @@ func NewFilesystemEnumerator(config Config) *FilesystemEnumerator {
 // fileEntry holds metadata collected during the walk phase.
 type fileEntry struct {
 	path string
+	enum *FilesystemEnumerator // enumerator configured for this file
 }
 
 // Enumerate walks the filesystem and yields file blobs.
@@ -58,10 +59,7 @@ func (e *FilesystemEnumerator) Enumerate(ctx context.Context, callback func(cont
 		}
 
 		if info.Mode()&os.ModeSymlink != 0 && !e.config.FollowSymlinks {
-			return nil
-		}
-
-		if e.config.MaxFileSize > 0 && info.Size() > e.config.MaxFileSize {
+			e.config.skip(path, "symlink")
 			return nil
 		}
 
@@ -71,11 +69,28 @@ func (e *FilesystemEnumerator) Enumerate(ctx context.Context, callback func(cont
 				return err
 			}
 			if ig.MatchesPath(relPath) {
+				e.config.skip(path, "matches ignore pattern")
 				return nil
 			}
 		}
 
-		files = append(files, fileEntry{path: path})
+		if e.config.OnFile != nil {
+			e.config.OnFile(path)
+		}
+
+		fe := e
+		if e.config.PathConfig != nil {
+			if relPath, err := filepath.Rel(e.config.Root, path); err == nil {
+				fe = &FilesystemEnumerator{config: e.config.PathConfig(filepath.ToSlash(relPath))}
+			}
+		}
+
+		if fe.config.MaxFileSize > 0 && info.Size() > fe.config.MaxFileSize && !fe.streamsMbox(path) {
+			e.config.skip(path, fmt.Sprintf("%d B exceeds max file size", info.Size()))
+			return nil
+		}
+
+		files = append(files, fileEntry{path: path, enum: fe})
 		return nil
 	})
 	if err != nil {
@@ -109,7 +124,7 @@ func (e *FilesystemEnumerator) Enumerate(ctx context.Context, callback func(cont
 	for i := 0; i < numReaders; i++ {
 		g.Go(func() error {
 			for f := range pathsCh {
-				if err := e.processFile(ctx, f.path, callback); err != nil {
+				if err := f.enum.processFile(ctx, f.path, callback); err != nil {
 					return err
 				}
 			}
@@ -136,36 +151,94 @@ func (e *FilesystemEnumerator) processFile(ctx context.Context, path string, cal
 	default:
 	}
 
+	if e.streamsMbox(path) {
+		return e.processMbox(ctx, path, callback)
+	}
+
 	content, err := os.ReadFile(path)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		e.config.skip(path, "unreadable")
 		return nil
 	}
+	return e.processContent(path, content, callback)
+}
+
+// processContent yields the blobs of a file read from path: the file itself,
+// or the text extracted from it. Binary files are skipped unless extraction
+// is enabled for their type.
+func (e *FilesystemEnumerator) processContent(path string, content []byte, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	// Terraform state and plans are split per resource so that findings
+	// name the resource address and sensitive attributes are marked.
+	if isTerraformJSON(path, content) {
+		if extracted, err := ExtractTerraform(content); err == nil && len(extracted) > 0 {
+			return yieldExtracted(path, extracted, callback)
+		}
+	}
+
+	// CI pipeline definitions are scanned as-is below, and additionally as
+	// the inline settings, printed secrets and base64 values found in them.
+	if isCIConfig(path) {
+		if extracted, err := ExtractCI(path, content); err == nil {
+			if err := yieldExtracted(path, extracted, callback); err != nil {
+				return err
+			}
+		}
+	}
+
+	// HTML and Markdown are scanned as-is below and, with extraction
+	// enabled, additionally with entities decoded and as their links.
+	if isMarkup(path) && shouldExtract(e.config, getExtension(path)) {
+		if extracted, err := ExtractMarkup(path, content); err == nil {
+			if err := yieldExtracted(path, extracted, callback); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Keystores are binary, but with extraction enabled the keys,
+	// certificates and any common password found in them are rendered as
+	// text for the np.keystore rules.
+	if shouldExtract(e.config, getExtension(path)) {
+		if extracted, err := ExtractKeystore(path, content); err == nil && len(extracted) > 0 {
+			return yieldExtracted(path, extracted, callback)
+		}
+	}
 
 	binary := isBinary(content)
 
-	// Handle binary files with extraction enabled
-	if binary && e.config.ExtractArchives != "" {
+	// Handle binary files with extraction enabled, and mail, whose bodies and
+	// attachments are encoded
+	if (binary || isMailFile(path)) && e.config.ExtractArchives != "" {
 		ext := getExtension(path)
 		if shouldExtract(e.config, ext) {
-			extracted, err := ExtractText(path, content, e.config.ExtractLimits)
+			skippedFile := false // an encrypted document was reported skipped
+			extracted, err := extractWithState(path, content, &extractState{
+				limits:       e.config.ExtractLimits,
+				binaryAsText: e.config.TreatBinaryAsText,
+				skip: func(member, reason string) {
+					skippedFile = skippedFile || member == ""
+					e.config.skip(memberPath(path, member), reason)
+				},
+			})
 			if err == nil && len(extracted) > 0 {
-				for _, ec := range extracted {
-					blobID := types.ComputeBlobID(ec.Content)
-					prov := types.ArchiveProvenance{
-						ArchivePath: path,
-						MemberPath:  ec.Name,
-					}
-					if err := callback(ec.Content, blobID, prov); err != nil {
-						return err
-					}
-				}
+				return yieldExtracted(path, extracted, callback)
+			}
+			if skippedFile {
+				return nil
+			}
+			if binary && !e.config.TreatBinaryAsText {
+				e.config.skip(path, "binary, no text extracted")
+				return nil
 			}
-			return nil
 		}
 	}
 
-	if binary {
+	// UTF-16 text is yielded as is, like in every enumerator, for the
+	// matcher to decode, so that the blob ID and match offsets are those of
+	// the file
+	if binary && !e.config.TreatBinaryAsText {
+		e.config.skip(path, "binary")
 		return nil
 	}
 
@@ -177,6 +250,28 @@ func (e *FilesystemEnumerator) processFile(ctx context.Context, path string, cal
 	return callback(content, blobID, prov)
 }
 
+// yieldExtracted yields the contents extracted from the file at path, each
+// with the provenance of its member.
+func yieldExtracted(path string, extracted []ExtractedContent, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	for _, ec := range extracted {
+		blobID := types.ComputeBlobID(ec.Content)
+		prov := types.ArchiveProvenance{
+			ArchivePath: path,
+			MemberPath:  ec.Name,
+		}
+		if err := callback(ec.Content, blobID, prov); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isMailFile reports whether path is an email or mbox archive.
+func isMailFile(path string) bool {
+	ext := getExtension(path)
+	return ext == ".eml" || ext == ".mbox"
+}
+
 // shouldExtract checks if a file type should be extracted based on config.
 func shouldExtract(config Config, ext string) bool {
 	if config.ExtractArchives == "" {
@@ -194,11 +289,8 @@ func shouldExtract(config Config, ext string) bool {
 	return false
 }
 
-// isBinary detects if content is binary by checking first 8KB for null bytes.
+// isBinary reports whether content looks binary rather than text; see
+// extract.IsBinary.
 func isBinary(content []byte) bool {
-	checkSize := len(content)
-	if checkSize > 8192 {
-		checkSize = 8192
-	}
-	return bytes.IndexByte(content[:checkSize], 0) != -1
+	return extract.IsBinary(content)
 }
@@ -1,9 +1,14 @@
 package enum
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -158,6 +163,50 @@ func TestFilesystemEnumerator_BinaryFiles(t *testing.T) {
 	}
 }
 
+func TestFilesystemEnumerator_TreatBinaryAsText(t *testing.T) {
+	tmpDir := t.TempDir()
+	utf16 := []byte{0xFF, 0xFE}
+	for _, r := range "token=ghp_abc\n" {
+		utf16 = append(utf16, byte(r), 0)
+	}
+	files := map[string][]byte{
+		"binary.bin": {0x00, 0x01, 0x02, 'k', 'e', 'y'},
+		"utf16.txt":  utf16,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), content, 0644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+
+	enumerate := func(config Config) map[string]string {
+		var mu sync.Mutex
+		found := make(map[string]string)
+		err := NewFilesystemEnumerator(config).Enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+			mu.Lock()
+			defer mu.Unlock()
+			found[filepath.Base(prov.Path())] = string(content)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("enumerate failed: %v", err)
+		}
+		return found
+	}
+
+	// UTF-16 text is yielded as is, for the matcher to decode; binary is
+	// skipped
+	found := enumerate(Config{Root: tmpDir})
+	if len(found) != 1 || found["utf16.txt"] != string(utf16) {
+		t.Errorf("expected utf16.txt only, got %q", found)
+	}
+
+	found = enumerate(Config{Root: tmpDir, TreatBinaryAsText: true})
+	if found["binary.bin"] != string(files["binary.bin"]) {
+		t.Errorf("expected binary.bin as is with TreatBinaryAsText, got %q", found)
+	}
+}
+
 func TestFilesystemEnumerator_GitignoreNotRespected(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -427,3 +476,210 @@ func TestFilesystemEnumerator_ContextCancellation(t *testing.T) {
 		t.Errorf("expected context.Canceled error, got %v", err)
 	}
 }
+
+func TestFilesystemEnumerator_OnSkip(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range map[string][]byte{
+		"config.txt": []byte("password=x"),
+		"large.txt":  bytes.Repeat([]byte("a"), 100),
+		"image.bin":  {0x89, 0x00, 0x01},
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("failed to create zip member: %v", err)
+		}
+		w.Write(content)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to write zip: %v", err)
+	}
+
+	files := map[string][]byte{
+		"app.txt":    []byte("hello"),
+		"big.txt":    bytes.Repeat([]byte("b"), 2000),
+		"photo.raw":  {0x00, 0x01, 0x02},
+		"bundle.zip": buf.Bytes(),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), content, 0644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+
+	limits := DefaultExtractionLimits()
+	limits.MaxSize = 50
+	var mu sync.Mutex
+	skipped := map[string]string{}
+	config := Config{
+		Root:            tmpDir,
+		MaxFileSize:     1000,
+		ExtractArchives: "zip",
+		ExtractLimits:   limits,
+		IgnoreFile:      "/dev/null",
+		OnSkip: func(path, reason string) {
+			mu.Lock()
+			defer mu.Unlock()
+			rel, _ := filepath.Rel(tmpDir, path)
+			skipped[rel] = reason
+		},
+	}
+
+	var found []string
+	err := NewFilesystemEnumerator(config).Enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		mu.Lock()
+		defer mu.Unlock()
+		found = append(found, prov.Path())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Enumerate failed: %v", err)
+	}
+
+	want := map[string]string{
+		"big.txt":              "2000 B exceeds max file size",
+		"photo.raw":            "binary",
+		"bundle.zip:large.txt": "exceeds max extracted file size",
+		"bundle.zip:image.bin": "binary",
+	}
+	if len(skipped) != len(want) {
+		t.Errorf("expected %d skips, got %v", len(want), skipped)
+	}
+	for path, reason := range want {
+		if skipped[path] != reason {
+			t.Errorf("skip reason for %s: got %q, want %q", path, skipped[path], reason)
+		}
+	}
+	if len(found) != 2 {
+		t.Errorf("expected app.txt and bundle.zip:config.txt, got %v", found)
+	}
+}
+
+func TestFilesystemEnumerator_EncryptedDocuments(t *testing.T) {
+	tmpDir := t.TempDir()
+	content, err := os.ReadFile("../../testdata/extraction/encrypted.docx")
+	if err != nil {
+		t.Fatalf("failed to read test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "report.docx"), content, 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	enumerate := func(passwords ...string) (found, skipped []string) {
+		limits := DefaultExtractionLimits()
+		limits.DocPasswords = passwords
+		config := Config{
+			Root:            tmpDir,
+			ExtractArchives: "docx",
+			ExtractLimits:   limits,
+			IgnoreFile:      "/dev/null",
+			OnSkip: func(path, reason string) {
+				rel, _ := filepath.Rel(tmpDir, path)
+				skipped = append(skipped, rel+": "+reason)
+			},
+		}
+		err := NewFilesystemEnumerator(config).Enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+			rel, _ := filepath.Rel(tmpDir, prov.Path())
+			found = append(found, rel)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Enumerate failed: %v", err)
+		}
+		return found, skipped
+	}
+
+	found, skipped := enumerate()
+	if len(found) != 0 {
+		t.Errorf("expected nothing scanned, got %v", found)
+	}
+	if want := []string{"report.docx: " + SkipEncrypted}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("skipped = %v, want %v", skipped, want)
+	}
+
+	found, skipped = enumerate("titus")
+	if len(found) != 1 || len(skipped) != 0 {
+		t.Errorf("expected the decrypted document scanned, got %v, skipped %v", found, skipped)
+	}
+}
+
+func TestFilesystemEnumerator_PathConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, "backups"), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	for _, name := range []string{"app.txt", "backups/app.txt"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), bytes.Repeat([]byte("a"), 100), 0644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+
+	config := Config{Root: tmpDir, IgnoreFile: "/dev/null"}
+	config.PathConfig = func(relPath string) Config {
+		c := config
+		if strings.HasPrefix(relPath, "backups/") {
+			c.MaxFileSize = 10
+		}
+		return c
+	}
+
+	var mu sync.Mutex
+	var found []string
+	err := NewFilesystemEnumerator(config).Enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		mu.Lock()
+		defer mu.Unlock()
+		found = append(found, prov.Path())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Enumerate failed: %v", err)
+	}
+	if len(found) != 1 || found[0] != filepath.Join(tmpDir, "app.txt") {
+		t.Errorf("expected only app.txt, got %v", found)
+	}
+}
+
+func TestFilesystemEnumerator_OnFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string][]byte{
+		"app.txt":          []byte("hello"),
+		"big.txt":          bytes.Repeat([]byte("b"), 2000),
+		"server.pfx":       {0x30, 0x00, 0x01},
+		"vendor/lib.txt":   []byte("ignored"),
+		".gitignore-extra": []byte("vendor/\n"),
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+
+	var seen []string
+	config := Config{
+		Root:        tmpDir,
+		MaxFileSize: 1000,
+		IgnoreFile:  filepath.Join(tmpDir, ".gitignore-extra"),
+		OnFile: func(path string) {
+			rel, _ := filepath.Rel(tmpDir, path)
+			seen = append(seen, filepath.ToSlash(rel))
+		},
+	}
+	err := NewFilesystemEnumerator(config).Enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Enumerate failed: %v", err)
+	}
+
+	sort.Strings(seen)
+	want := []string{".gitignore-extra", "app.txt", "big.txt", "server.pfx"}
+	if !reflect.DeepEqual(seen, want) {
+		t.Errorf("OnFile paths: got %v, want %v", seen, want)
+	}
+}
@@ -0,0 +1,232 @@
+package enum
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// DefaultFollowInterval is the polling interval used when Follower.Interval is zero.
+const DefaultFollowInterval = 500 * time.Millisecond
+
+// followMaxBatch bounds how much of a file is read into one batch of lines. A
+// line longer than this is split rather than buffered indefinitely.
+const followMaxBatch = 1024 * 1024
+
+// LogLines is a batch of complete lines appended to a followed file.
+type LogLines struct {
+	Path      string
+	FirstLine int    // 1-based line number of the first line in Content
+	Content   []byte // one or more lines; only a line split at followMaxBatch lacks its '\n'
+}
+
+// followedFile is the read position recorded for each followed file.
+type followedFile struct {
+	info   os.FileInfo
+	offset int64
+	line   int // line number of the next line to be read
+}
+
+// Follower tails log files the way `tail -F` does, yielding lines as they are
+// appended. Patterns are re-globbed on every poll so files created later are
+// picked up, and a file that is truncated or replaced (log rotation) is read
+// again from its start. Only complete lines are yielded; a partial last line
+// waits until its newline is written.
+type Follower struct {
+	patterns    []string
+	files       map[string]*followedFile
+	initialized bool
+
+	Interval  time.Duration // time between polls (0 = DefaultFollowInterval)
+	FromStart bool          // yield the existing content of files on the first poll
+}
+
+// NewFollower creates a follower for the files matching patterns, which are
+// paths or filepath.Match glob patterns.
+func NewFollower(patterns []string) (*Follower, error) {
+	for _, p := range patterns {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+	}
+	return &Follower{
+		patterns: patterns,
+		files:    make(map[string]*followedFile),
+	}, nil
+}
+
+// Enumerate follows until ctx is cancelled, yielding each batch of appended
+// lines as a blob with FileProvenance. It returns nil when the context is
+// cancelled.
+func (f *Follower) Enumerate(ctx context.Context, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	return f.Follow(ctx, func(lines LogLines) error {
+		return callback(lines.Content, types.ComputeBlobID(lines.Content), types.FileProvenance{FilePath: lines.Path})
+	})
+}
+
+// Follow polls until ctx is cancelled. It returns nil when the context is
+// cancelled.
+func (f *Follower) Follow(ctx context.Context, callback func(LogLines) error) error {
+	interval := f.Interval
+	if interval <= 0 {
+		interval = DefaultFollowInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := f.Poll(ctx, callback); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll checks every matching file once and yields the lines appended since the
+// previous poll. On the first poll, existing files are read from their end
+// unless FromStart is set; files that appear later are read from the start.
+// Returns the number of batches yielded.
+func (f *Follower) Poll(ctx context.Context, callback func(LogLines) error) (int, error) {
+	var paths []string
+	for _, pattern := range f.patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return 0, err
+		}
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+
+	current := make(map[string]*followedFile, len(paths))
+	batches := 0
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return batches, err
+		}
+		if _, ok := current[path]; ok {
+			continue // matched by more than one pattern
+		}
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			// Files can disappear mid-rotation; pick them up on the next poll
+			continue
+		}
+
+		state, ok := f.files[path]
+		if !ok {
+			// A rotated file renamed to another matching path keeps its position.
+			state, ok = f.renamed(info)
+		}
+		switch {
+		case !ok && !f.initialized && !f.FromStart:
+			lines, err := countLines(path, info.Size())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+				continue
+			}
+			state = &followedFile{offset: info.Size(), line: lines + 1}
+		case !ok || !os.SameFile(state.info, info) || info.Size() < state.offset:
+			state = &followedFile{line: 1}
+		}
+		state.info = info
+		current[path] = state
+
+		n, err := f.readAppended(path, state, callback)
+		batches += n
+		if err != nil {
+			return batches, err
+		}
+	}
+
+	f.files = current
+	f.initialized = true
+	return batches, nil
+}
+
+// renamed returns the state of a followed file that is now at another path.
+func (f *Follower) renamed(info os.FileInfo) (*followedFile, bool) {
+	for _, state := range f.files {
+		if os.SameFile(state.info, info) {
+			return state, true
+		}
+	}
+	return nil, false
+}
+
+// readAppended yields the complete lines between state.offset and the end of
+// the file, advancing state past them.
+func (f *Follower) readAppended(path string, state *followedFile, callback func(LogLines) error) (int, error) {
+	if state.offset >= state.info.Size() {
+		return 0, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		return 0, nil
+	}
+	defer file.Close()
+
+	batches := 0
+	buf := make([]byte, followMaxBatch)
+	for state.offset < state.info.Size() {
+		n, err := file.ReadAt(buf[:min(int64(len(buf)), state.info.Size()-state.offset)], state.offset)
+		if err != nil && err != io.EOF {
+			return batches, fmt.Errorf("reading %s: %w", path, err)
+		}
+		end := bytes.LastIndexByte(buf[:n], '\n') + 1
+		if end == 0 {
+			if n < len(buf) {
+				return batches, nil // wait for the rest of the line
+			}
+			end = n
+		}
+
+		content := append([]byte(nil), buf[:end]...)
+		if err := callback(LogLines{Path: path, FirstLine: state.line, Content: content}); err != nil {
+			return batches, err
+		}
+		batches++
+		state.offset += int64(end)
+		state.line += bytes.Count(content, []byte{'\n'})
+	}
+	return batches, nil
+}
+
+// countLines counts the newlines in the first size bytes of a file.
+func countLines(path string, size int64) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	lines := 0
+	buf := make([]byte, 64*1024)
+	r := io.LimitReader(file, size)
+	for {
+		n, err := r.Read(buf)
+		lines += bytes.Count(buf[:n], []byte{'\n'})
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
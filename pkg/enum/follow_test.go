@@ -0,0 +1,96 @@
+package enum
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func appendFile(t *testing.T, path, s string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(s)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func pollFollower(t *testing.T, f *Follower) []LogLines {
+	t.Helper()
+	var got []LogLines
+	_, err := f.Poll(context.Background(), func(lines LogLines) error {
+		got = append(got, lines)
+		return nil
+	})
+	require.NoError(t, err)
+	return got
+}
+
+func TestFollower_AppendedLines(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "app.log")
+	appendFile(t, log, "started\nlistening\n")
+
+	f, err := NewFollower([]string{filepath.Join(dir, "*.log")})
+	require.NoError(t, err)
+	assert.Empty(t, pollFollower(t, f), "existing content is skipped")
+
+	appendFile(t, log, "request 1\nrequest 2\npartial")
+	assert.Equal(t, []LogLines{
+		{Path: log, FirstLine: 3, Content: []byte("request 1\nrequest 2\n")},
+	}, pollFollower(t, f))
+
+	appendFile(t, log, " line\n")
+	assert.Equal(t, []LogLines{
+		{Path: log, FirstLine: 5, Content: []byte("partial line\n")},
+	}, pollFollower(t, f))
+
+	assert.Empty(t, pollFollower(t, f))
+}
+
+func TestFollower_NewFilesAndRotation(t *testing.T) {
+	dir := t.TempDir()
+	f, err := NewFollower([]string{filepath.Join(dir, "*.log")})
+	require.NoError(t, err)
+	pollFollower(t, f)
+
+	log := filepath.Join(dir, "app.log")
+	appendFile(t, log, "one\ntwo\n")
+	assert.Equal(t, []LogLines{
+		{Path: log, FirstLine: 1, Content: []byte("one\ntwo\n")},
+	}, pollFollower(t, f), "files created later are read from the start")
+
+	// Rotate: the old file moves away and a new one takes its name.
+	require.NoError(t, os.Rename(log, filepath.Join(dir, "app.log.1")))
+	appendFile(t, log, "three\n")
+	assert.Equal(t, []LogLines{
+		{Path: log, FirstLine: 1, Content: []byte("three\n")},
+	}, pollFollower(t, f))
+
+	// Truncate in place.
+	require.NoError(t, os.WriteFile(log, []byte("x\n"), 0644))
+	assert.Equal(t, []LogLines{
+		{Path: log, FirstLine: 1, Content: []byte("x\n")},
+	}, pollFollower(t, f))
+}
+
+func TestFollower_FromStart(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, log, "token=abc\n")
+
+	f, err := NewFollower([]string{log})
+	require.NoError(t, err)
+	f.FromStart = true
+	assert.Equal(t, []LogLines{
+		{Path: log, FirstLine: 1, Content: []byte("token=abc\n")},
+	}, pollFollower(t, f))
+}
+
+func TestNewFollower_InvalidPattern(t *testing.T) {
+	_, err := NewFollower([]string{"[unterminated"})
+	assert.Error(t, err)
+}
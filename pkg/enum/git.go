@@ -3,6 +3,7 @@ package enum
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -16,8 +17,33 @@ type GitEnumerator struct {
 	config Config
 	// CommitRef optionally specifies a specific commit to enumerate (defaults to HEAD)
 	CommitRef string
-	// WalkAll when true walks all commits from all refs instead of single commit
+	// WalkAll when true walks all commits from all refs instead of single commit.
+	// With native git it also walks reflogs and dangling objects.
 	WalkAll bool
+	// Exclude lists commits whose history is already scanned. When WalkAll is
+	// true, blobs reachable from these commits are skipped (native git only;
+	// the go-git fallback still walks full history).
+	Exclude []string
+	// Refs limits a WalkAll walk to the history of these refs (branches,
+	// tags or commits). Reflogs and dangling objects are left out too.
+	Refs []string
+	// KeepDuplicates yields a blob at every path it is found at, so that
+	// each location is reported, rather than only at the first.
+	KeepDuplicates bool
+}
+
+// seenKey is what blobs are deduplicated by: their hash and, with
+// KeepDuplicates, their path.
+type seenKey struct {
+	hash [20]byte
+	path string
+}
+
+func (e *GitEnumerator) seenKey(hash [20]byte, path string) seenKey {
+	if !e.KeepDuplicates {
+		path = ""
+	}
+	return seenKey{hash: hash, path: path}
 }
 
 // NewGitEnumerator creates a new git enumerator.
@@ -28,14 +54,41 @@ func NewGitEnumerator(config Config) *GitEnumerator {
 	}
 }
 
+// blobConfig returns the config for the blob at path, relative to the
+// repository, applying PathConfig if set.
+func (e *GitEnumerator) blobConfig(path string) Config {
+	if e.config.PathConfig == nil {
+		return e.config
+	}
+	return e.config.PathConfig(path)
+}
+
+// yieldBlob yields a blob of the history the way the filesystem enumerator
+// yields a file, under config: binary blobs are skipped unless text is
+// extracted from them, and extracted contents are named path:member.
+func (e *GitEnumerator) yieldBlob(config Config, content []byte, prov types.GitProvenance, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	fs := &FilesystemEnumerator{config: config}
+	return fs.processContent(prov.BlobPath, content, func(content []byte, blobID types.BlobID, p types.Provenance) error {
+		blobProv := prov
+		if ap, ok := p.(types.ArchiveProvenance); ok {
+			blobProv.BlobPath = memberPath(prov.BlobPath, ap.MemberPath)
+		}
+		return callback(content, blobID, blobProv)
+	})
+}
+
 // Enumerate walks git history and yields unique blobs.
 // When WalkAll is true, it prefers native git commands (60x faster on large repos)
-// and falls back to go-git if the git binary is not available.
+// and falls back to go-git if the git binary is not available. The fallback
+// walks refs only, so it warns that reflogs and dangling objects are missed.
 func (e *GitEnumerator) Enumerate(ctx context.Context, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
 	if e.WalkAll {
 		if gitBinaryAvailable() {
 			return e.enumerateAllHistoryNative(ctx, callback)
 		}
+		if len(e.Refs) == 0 {
+			fmt.Fprintf(os.Stderr, "warning: git not found on PATH; walking %s from its refs only, without reflogs or dangling objects\n", e.config.Root)
+		}
 		return e.enumerateAllHistory(ctx, callback)
 	}
 	return e.enumerateSingleCommit(ctx, callback)
@@ -68,7 +121,7 @@ func (e *GitEnumerator) enumerateSingleCommit(ctx context.Context, callback func
 	}
 
 	// Track seen blobs to avoid duplicates
-	seen := make(map[plumbing.Hash]bool)
+	seen := make(map[seenKey]bool)
 
 	// Walk the tree
 	err = tree.Files().ForEach(func(f *object.File) error {
@@ -80,13 +133,16 @@ func (e *GitEnumerator) enumerateSingleCommit(ctx context.Context, callback func
 		}
 
 		// Skip if already seen
-		if seen[f.Hash] {
+		key := e.seenKey(f.Hash, f.Name)
+		if seen[key] {
 			return nil
 		}
-		seen[f.Hash] = true
+		seen[key] = true
 
 		// Apply size limit
-		if e.config.MaxFileSize > 0 && f.Size > e.config.MaxFileSize {
+		config := e.blobConfig(f.Name)
+		if config.MaxFileSize > 0 && f.Size > config.MaxFileSize {
+			e.config.skip(f.Name, fmt.Sprintf("%d B exceeds max file size", f.Size))
 			return nil
 		}
 
@@ -96,14 +152,6 @@ func (e *GitEnumerator) enumerateSingleCommit(ctx context.Context, callback func
 			return fmt.Errorf("failed to get contents of %s: %w", f.Name, err)
 		}
 
-		// Skip binary files
-		if isBinary([]byte(content)) {
-			return nil
-		}
-
-		// Compute blob ID
-		blobID := types.ComputeBlobID([]byte(content))
-
 		// Create git provenance with commit metadata
 		commitMeta := &types.CommitMetadata{
 			CommitID:           commit.Hash.String(),
@@ -123,7 +171,7 @@ func (e *GitEnumerator) enumerateSingleCommit(ctx context.Context, callback func
 		}
 
 		// Yield to callback
-		return callback([]byte(content), blobID, prov)
+		return e.yieldBlob(config, []byte(content), prov, callback)
 	})
 
 	if err != nil {
@@ -133,7 +181,8 @@ func (e *GitEnumerator) enumerateSingleCommit(ctx context.Context, callback func
 	return nil
 }
 
-// enumerateAllHistory walks all commits from all refs.
+// enumerateAllHistory walks all commits from all refs with go-git. Unlike
+// enumerateAllHistoryNative, it doesn't read reflogs or dangling objects.
 func (e *GitEnumerator) enumerateAllHistory(ctx context.Context, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
 	// Open repository
 	repo, err := git.PlainOpen(e.config.Root)
@@ -141,19 +190,34 @@ func (e *GitEnumerator) enumerateAllHistory(ctx context.Context, callback func(c
 		return fmt.Errorf("failed to open git repository: %w", err)
 	}
 
-	// Get commit iterator for all refs
-	commitIter, err := repo.Log(&git.LogOptions{
-		All: true,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to get commit log: %w", err)
+	// Get commit iterators for all refs, or only e.Refs
+	var commitIters []object.CommitIter
+	if len(e.Refs) == 0 {
+		commitIter, err := repo.Log(&git.LogOptions{
+			All: true,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get commit log: %w", err)
+		}
+		commitIters = append(commitIters, commitIter)
+	}
+	for _, ref := range e.Refs {
+		hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+		if err != nil {
+			return fmt.Errorf("failed to resolve ref %s: %w", ref, err)
+		}
+		commitIter, err := repo.Log(&git.LogOptions{From: *hash})
+		if err != nil {
+			return fmt.Errorf("failed to get commit log of %s: %w", ref, err)
+		}
+		commitIters = append(commitIters, commitIter)
 	}
 
 	// Track seen blobs globally (across all commits)
-	seenBlobs := make(map[plumbing.Hash]bool)
+	seenBlobs := make(map[seenKey]bool)
 
 	// Iterate all commits
-	err = commitIter.ForEach(func(commit *object.Commit) error {
+	visit := func(commit *object.Commit) error {
 		// Check context cancellation
 		select {
 		case <-ctx.Done():
@@ -177,13 +241,16 @@ func (e *GitEnumerator) enumerateAllHistory(ctx context.Context, callback func(c
 			}
 
 			// Global deduplication: skip if already processed
-			if seenBlobs[f.Hash] {
+			key := e.seenKey(f.Hash, f.Name)
+			if seenBlobs[key] {
 				return nil
 			}
-			seenBlobs[f.Hash] = true
+			seenBlobs[key] = true
 
 			// Apply size limit
-			if e.config.MaxFileSize > 0 && f.Size > e.config.MaxFileSize {
+			config := e.blobConfig(f.Name)
+			if config.MaxFileSize > 0 && f.Size > config.MaxFileSize {
+				e.config.skip(f.Name, fmt.Sprintf("%d B exceeds max file size", f.Size))
 				return nil
 			}
 
@@ -193,14 +260,6 @@ func (e *GitEnumerator) enumerateAllHistory(ctx context.Context, callback func(c
 				return fmt.Errorf("failed to get contents of %s: %w", f.Name, err)
 			}
 
-			// Skip binary files
-			if isBinary([]byte(content)) {
-				return nil
-			}
-
-			// Compute blob ID
-			blobID := types.ComputeBlobID([]byte(content))
-
 			// Create git provenance with this commit's metadata
 			// (first commit where we encountered this blob)
 			commitMeta := &types.CommitMetadata{
@@ -221,12 +280,14 @@ func (e *GitEnumerator) enumerateAllHistory(ctx context.Context, callback func(c
 			}
 
 			// Yield to callback
-			return callback([]byte(content), blobID, prov)
+			return e.yieldBlob(config, []byte(content), prov, callback)
 		})
-	})
+	}
 
-	if err != nil {
-		return fmt.Errorf("failed to walk commits: %w", err)
+	for _, commitIter := range commitIters {
+		if err := commitIter.ForEach(visit); err != nil {
+			return fmt.Errorf("failed to walk commits: %w", err)
+		}
 	}
 
 	return nil
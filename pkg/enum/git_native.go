@@ -13,7 +13,8 @@ import (
 	"github.com/praetorian-inc/titus/pkg/types"
 )
 
-// blobEntry holds a deduplicated blob hash and its first-seen path.
+// blobEntry holds a deduplicated blob hash and its first-seen path, or with
+// KeepDuplicates, one of the paths it was seen at.
 type blobEntry struct {
 	hash [20]byte
 	path string
@@ -26,24 +27,39 @@ func gitBinaryAvailable() bool {
 }
 
 // enumerateAllHistoryNative uses native git commands for fast history enumeration.
-// Phase 1: git rev-list --all --objects → collect unique blob hashes with paths.
+// Phase 0: git fsck → find dangling objects, such as dropped stashes and commits lost to resets.
+// Phase 1: git rev-list --all --reflog --objects → collect unique blob hashes with paths.
 // Phase 2: git log → collect commit metadata keyed by file path.
 // Phase 3: git cat-file --batch → stream content, filter, and invoke callback.
 func (e *GitEnumerator) enumerateAllHistoryNative(ctx context.Context, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
-	blobs, err := e.collectBlobEntries(ctx)
+	h := history{refs: e.Refs}
+	if len(e.Refs) == 0 {
+		h = history{reflog: true, dangling: danglingObjects(ctx, e.config.Root)}
+	}
+
+	blobs, err := e.collectBlobEntries(ctx, h)
 	if err != nil {
 		return err
 	}
 
-	commitMap, _ := e.collectCommitMetadata(ctx) // best-effort; nil map is safe
+	commitMap, _ := e.collectCommitMetadata(ctx, h) // best-effort; nil map is safe
 
 	return e.streamBlobContentsWithMeta(ctx, blobs, commitMap, callback)
 }
 
-// collectBlobEntries runs git rev-list --all --objects and returns deduplicated blob entries.
-func (e *GitEnumerator) collectBlobEntries(ctx context.Context) ([]blobEntry, error) {
-	cmd := exec.CommandContext(ctx, "git", "rev-list", "--all", "--objects")
+// collectBlobEntries runs git rev-list --objects over h and returns deduplicated blob entries,
+// one per blob or, with KeepDuplicates, per blob and path.
+// Objects reachable from e.Exclude are omitted; excluded commits that no longer
+// exist (e.g. after a force push) are ignored. Dangling blobs have no path.
+func (e *GitEnumerator) collectBlobEntries(ctx context.Context, h history) ([]blobEntry, error) {
+	args := append([]string{"rev-list", "--objects"}, h.args()...)
+	if len(e.Exclude) > 0 {
+		args = append(args, "--ignore-missing", "--not")
+		args = append(args, e.Exclude...)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = e.config.Root
+	cmd.Stdin = h.stdin()
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -54,7 +70,7 @@ func (e *GitEnumerator) collectBlobEntries(ctx context.Context) ([]blobEntry, er
 		return nil, fmt.Errorf("git rev-list: start: %w", err)
 	}
 
-	seen := make(map[[20]byte]bool)
+	seen := make(map[seenKey]bool)
 	var blobs []blobEntry
 
 	scanner := bufio.NewScanner(stdout)
@@ -62,7 +78,7 @@ func (e *GitEnumerator) collectBlobEntries(ctx context.Context) ([]blobEntry, er
 		line := scanner.Text()
 
 		// Lines with a space at position 40 have a path: "<40-hex> <path>"
-		// Lines without are commits or root trees — skip them.
+		// Lines without are commits — skip them. Trees are dropped by cat-file.
 		spaceIdx := strings.IndexByte(line, ' ')
 		if spaceIdx != 40 {
 			continue
@@ -78,10 +94,11 @@ func (e *GitEnumerator) collectBlobEntries(ctx context.Context) ([]blobEntry, er
 		}
 		copy(hash[:], decoded)
 
-		if seen[hash] {
+		key := e.seenKey(hash, path)
+		if seen[key] {
 			continue
 		}
-		seen[hash] = true
+		seen[key] = true
 
 		blobs = append(blobs, blobEntry{hash: hash, path: path})
 	}
@@ -98,12 +115,146 @@ func (e *GitEnumerator) collectBlobEntries(ctx context.Context) ([]blobEntry, er
 		return nil, fmt.Errorf("git rev-list: %w", err)
 	}
 
+	if e.KeepDuplicates {
+		return e.collectBlobPaths(ctx, h, seen, blobs)
+	}
 	return blobs, nil
 }
 
+// collectBlobPaths appends to blobs an entry for every other path a blob was
+// committed at, which rev-list leaves out since it lists each object once.
+// They are read from git log --raw, where a commit adding or changing a file
+// lists its new blob: ":100644 100644 <old> <new> M\t<path>".
+func (e *GitEnumerator) collectBlobPaths(ctx context.Context, h history, seen map[seenKey]bool, blobs []blobEntry) ([]blobEntry, error) {
+	args := append([]string{"-c", "core.quotePath=false", "log", "--raw", "--no-abbrev", "--no-renames", "--format="}, h.args()...)
+	if len(e.Exclude) > 0 {
+		args = append(args, "--ignore-missing", "--not")
+		args = append(args, e.Exclude...)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = e.config.Root
+	cmd.Stdin = h.stdin()
+
+	out, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+
+	for _, line := range strings.Split(string(out), "\n") {
+		meta, path, ok := strings.Cut(line, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 5 || !strings.HasPrefix(fields[0], ":") {
+			continue
+		}
+		if fields[1] == "160000" || fields[4] == "D" { // submodule commit, or deleted
+			continue
+		}
+		var hash [20]byte
+		decoded, err := hex.DecodeString(fields[3])
+		if err != nil || len(decoded) != len(hash) {
+			continue
+		}
+		copy(hash[:], decoded)
+
+		key := e.seenKey(hash, path)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		blobs = append(blobs, blobEntry{hash: hash, path: path})
+	}
+	return blobs, nil
+}
+
+// ListRefs returns the branch and tag refs of the repository at repoPath,
+// mapped to the commit (or tag object) each one points at.
+func ListRefs(ctx context.Context, repoPath string) (map[string]string, error) {
+	cmd := exec.CommandContext(ctx, "git", "for-each-ref", "--format=%(refname) %(objectname)", "refs/heads", "refs/tags")
+	cmd.Dir = repoPath
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git for-each-ref: %w", err)
+	}
+
+	refs := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		name, hash, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		refs[name] = hash
+	}
+	return refs, nil
+}
+
 // collectCommitMetadata runs git log to build a map of file path → first commit metadata.
-func (e *GitEnumerator) collectCommitMetadata(ctx context.Context) (map[string]*types.CommitMetadata, error) {
-	return collectCommitMetadataForRepo(ctx, e.config.Root, true)
+func (e *GitEnumerator) collectCommitMetadata(ctx context.Context, h history) (map[string]*types.CommitMetadata, error) {
+	return collectCommitMetadataForRepo(ctx, e.config.Root, true, h)
+}
+
+// history selects the commits a walk of a repository covers: those
+// reachable from any ref, which includes the latest stash and notes, and
+// optionally those only in reflogs, such as older stashes and commits
+// abandoned by a reset or rebase, and dangling objects that nothing points
+// at any more. If refs is set, only the commits reachable from them are
+// covered.
+type history struct {
+	refs     []string
+	reflog   bool
+	dangling []string // object IDs, given to git on stdin since there may be many
+}
+
+func (h history) args() []string {
+	if len(h.refs) > 0 {
+		return h.refs
+	}
+	args := []string{"--all"}
+	if h.reflog {
+		args = append(args, "--reflog")
+	}
+	if len(h.dangling) > 0 {
+		args = append(args, "--stdin")
+	}
+	return args
+}
+
+func (h history) stdin() io.Reader {
+	if len(h.dangling) == 0 {
+		return nil
+	}
+	return strings.NewReader(strings.Join(h.dangling, "\n") + "\n")
+}
+
+// danglingObjects returns the IDs of the dangling commits, tags, trees and
+// blobs in the repository at repoPath: objects still in its object store that
+// no ref or reflog reaches, such as dropped stashes, expired reflog entries
+// and blobs staged but never committed. They are found with git fsck, which
+// is best-effort; nil is returned if it fails.
+func danglingObjects(ctx context.Context, repoPath string) []string {
+	cmd := exec.CommandContext(ctx, "git", "fsck", "--connectivity-only", "--dangling", "--no-progress")
+	cmd.Dir = repoPath
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	return parseDangling(string(out))
+}
+
+// parseDangling returns the object IDs of git fsck's "dangling <type> <id>"
+// lines, SHA-1 or SHA-256.
+func parseDangling(out string) []string {
+	var ids []string
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 3 && fields[0] == "dangling" && (len(fields[2]) == 40 || len(fields[2]) == 64) {
+			ids = append(ids, fields[2])
+		}
+	}
+	return ids
 }
 
 // streamBlobContentsWithMeta feeds hashes to git cat-file --batch and invokes callback for text blobs.
@@ -194,7 +345,9 @@ func (e *GitEnumerator) streamBlobContentsWithMeta(ctx context.Context, blobs []
 		}
 
 		// Oversized blobs: discard.
-		if e.config.MaxFileSize > 0 && size > e.config.MaxFileSize {
+		config := e.blobConfig(blob.path)
+		if config.MaxFileSize > 0 && size > config.MaxFileSize {
+			e.config.skip(blob.path, fmt.Sprintf("%d B exceeds max file size", size))
 			if _, err := io.CopyN(io.Discard, reader, size+1); err != nil {
 				stdin.Close()
 				_ = cmd.Wait()
@@ -227,21 +380,13 @@ func (e *GitEnumerator) streamBlobContentsWithMeta(ctx context.Context, blobs []
 			return fmt.Errorf("git cat-file: read trailing newline: %w", err)
 		}
 
-		if isBinary(content) {
-			continue
-		}
-
-		// Git blob hash IS the BlobID — both use SHA-1("blob {len}\0{content}").
-		var blobID types.BlobID
-		copy(blobID[:], blob.hash[:])
-
 		prov := types.GitProvenance{
 			RepoPath: e.config.Root,
 			Commit:   commitMap[blob.path],
 			BlobPath: blob.path,
 		}
 
-		if err := callback(content, blobID, prov); err != nil {
+		if err := e.yieldBlob(config, content, prov, callback); err != nil {
 			stdin.Close()
 			_ = cmd.Wait()
 			return err
@@ -1,11 +1,16 @@
 package enum
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
 	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/praetorian-inc/titus/pkg/types"
@@ -238,6 +243,57 @@ func TestNativeGitEnumerator_MaxFileSize(t *testing.T) {
 	}
 }
 
+func TestNativeGitEnumerator_PathConfig(t *testing.T) {
+	skipIfNoGit(t)
+
+	tmpDir := t.TempDir()
+	initGitRepo(t, tmpDir)
+	if err := os.MkdirAll(filepath.Join(tmpDir, "backups"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(tmpDir, "app.txt"), strings.Repeat("a", 100))
+	writeFile(t, filepath.Join(tmpDir, "backups", "app.txt"), strings.Repeat("b", 100))
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	w, err := zw.Create("key.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("password = hunter22"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(tmpDir, "creds.zip"), archive.String())
+	gitAddCommit(t, tmpDir, "Add files")
+
+	// Blobs get the size and extraction settings of their path, as files do
+	config := Config{Root: tmpDir, ExtractArchives: "zip", ExtractLimits: DefaultExtractionLimits()}
+	config.PathConfig = func(relPath string) Config {
+		c := config
+		if strings.HasPrefix(relPath, "backups/") {
+			c.MaxFileSize = 10
+		}
+		return c
+	}
+	enumerator := NewGitEnumerator(config)
+	enumerator.WalkAll = true
+
+	var foundFiles []string
+	err = enumerator.enumerateAllHistoryNative(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		foundFiles = append(foundFiles, prov.Path())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("enumerate failed: %v", err)
+	}
+
+	sort.Strings(foundFiles)
+	if want := []string{"app.txt", "creds.zip:key.txt"}; !slices.Equal(foundFiles, want) {
+		t.Errorf("expected %v, got %v", want, foundFiles)
+	}
+}
+
 func TestNativeGitEnumerator_MultipleBranches(t *testing.T) {
 	skipIfNoGit(t)
 
@@ -284,6 +340,43 @@ func TestNativeGitEnumerator_MultipleBranches(t *testing.T) {
 	}
 }
 
+func TestNativeGitEnumerator_Refs(t *testing.T) {
+	skipIfNoGit(t)
+
+	tmpDir := t.TempDir()
+	initGitRepo(t, tmpDir)
+	writeFile(t, filepath.Join(tmpDir, "main.txt"), "main content")
+	gitAddCommit(t, tmpDir, "Main commit")
+	runGit(t, tmpDir, "branch", "-M", "main")
+	runGit(t, tmpDir, "checkout", "-b", "feature")
+	writeFile(t, filepath.Join(tmpDir, "feature.txt"), "feature content")
+	gitAddCommit(t, tmpDir, "Feature commit")
+
+	enumerator := NewGitEnumerator(Config{Root: tmpDir})
+	enumerator.WalkAll = true
+	enumerator.Refs = []string{"main"}
+
+	for name, enumerate := range map[string]func(context.Context, func([]byte, types.BlobID, types.Provenance) error) error{
+		"native": enumerator.enumerateAllHistoryNative,
+		"go-git": enumerator.enumerateAllHistory,
+	} {
+		contentSet := make(map[string]bool)
+		err := enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+			contentSet[string(content)] = true
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: enumerate failed: %v", name, err)
+		}
+		if !contentSet["main content"] {
+			t.Errorf("%s: missing main branch content", name)
+		}
+		if contentSet["feature content"] {
+			t.Errorf("%s: feature branch content outside --ref main", name)
+		}
+	}
+}
+
 func TestNativeGitEnumerator_MultipleCommits(t *testing.T) {
 	skipIfNoGit(t)
 
@@ -441,6 +534,112 @@ func TestNativeGitEnumerator_DoesNotApplyIgnorePatterns(t *testing.T) {
 	}
 }
 
+func TestNativeGitEnumerator_Exclude(t *testing.T) {
+	skipIfNoGit(t)
+
+	tmpDir := t.TempDir()
+	initGitRepo(t, tmpDir)
+
+	writeFile(t, filepath.Join(tmpDir, "old.txt"), "old content")
+	gitAddCommit(t, tmpDir, "Commit 1")
+
+	refs, err := ListRefs(context.Background(), tmpDir)
+	if err != nil {
+		t.Fatalf("ListRefs failed: %v", err)
+	}
+	if len(refs) != 1 {
+		t.Fatalf("expected 1 ref, got %d: %v", len(refs), refs)
+	}
+	var exclude []string
+	for _, hash := range refs {
+		exclude = append(exclude, hash)
+	}
+
+	writeFile(t, filepath.Join(tmpDir, "new.txt"), "new content")
+	gitAddCommit(t, tmpDir, "Commit 2")
+
+	enumerator := NewGitEnumerator(Config{Root: tmpDir})
+	enumerator.WalkAll = true
+	enumerator.Exclude = append(exclude, "0000000000000000000000000000000000000000")
+
+	var foundFiles []string
+	err = enumerator.enumerateAllHistoryNative(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		foundFiles = append(foundFiles, prov.Path())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("enumerate failed: %v", err)
+	}
+
+	if len(foundFiles) != 1 || foundFiles[0] != "new.txt" {
+		t.Errorf("expected only new.txt, got %v", foundFiles)
+	}
+}
+
+func TestNativeGitEnumerator_RecoverableHistory(t *testing.T) {
+	skipIfNoGit(t)
+
+	tmpDir := t.TempDir()
+	initGitRepo(t, tmpDir)
+	writeFile(t, filepath.Join(tmpDir, "main.txt"), "main")
+	gitAddCommit(t, tmpDir, "Commit 1")
+
+	// An older stash, only in the stash reflog, and the latest
+	writeFile(t, filepath.Join(tmpDir, "stash1.txt"), "older stash")
+	runGit(t, tmpDir, "add", "stash1.txt")
+	runGit(t, tmpDir, "stash")
+	writeFile(t, filepath.Join(tmpDir, "stash2.txt"), "latest stash")
+	runGit(t, tmpDir, "add", "stash2.txt")
+	runGit(t, tmpDir, "stash")
+
+	runGit(t, tmpDir, "notes", "add", "-m", "note content", "HEAD")
+
+	// A commit only in the HEAD reflog after a reset
+	writeFile(t, filepath.Join(tmpDir, "reset.txt"), "reset away")
+	gitAddCommit(t, tmpDir, "Commit 2")
+	runGit(t, tmpDir, "reset", "--hard", "HEAD~1")
+
+	// A commit left dangling by a dropped stash, and a blob staged but never committed
+	writeFile(t, filepath.Join(tmpDir, "dropped.txt"), "dropped stash")
+	runGit(t, tmpDir, "add", "dropped.txt")
+	runGit(t, tmpDir, "stash")
+	runGit(t, tmpDir, "stash", "drop")
+	writeFile(t, filepath.Join(tmpDir, "staged.txt"), "staged only")
+	runGit(t, tmpDir, "add", "staged.txt")
+	runGit(t, tmpDir, "rm", "--cached", "--quiet", "staged.txt")
+
+	enumerator := NewGitEnumerator(Config{Root: tmpDir})
+	enumerator.WalkAll = true
+
+	found := make(map[string]string)
+	err := enumerator.enumerateAllHistoryNative(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		found[string(content)] = prov.Path()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("enumerate failed: %v", err)
+	}
+
+	for content, path := range map[string]string{
+		"main":          "main.txt",
+		"older stash":   "stash1.txt",
+		"latest stash":  "stash2.txt",
+		"reset away":    "reset.txt",
+		"dropped stash": "dropped.txt",
+		"staged only":   "",
+	} {
+		got, ok := found[content]
+		if !ok {
+			t.Errorf("blob %q not enumerated", content)
+		} else if got != path {
+			t.Errorf("blob %q: path = %q, want %q", content, got, path)
+		}
+	}
+	if _, ok := found["note content\n"]; !ok {
+		t.Errorf("note not enumerated")
+	}
+}
+
 // --- Test helpers ---
 
 func initGitRepo(t *testing.T, dir string) {
@@ -471,3 +670,16 @@ func gitAddCommit(t *testing.T, dir, msg string) {
 	runGit(t, dir, "add", ".")
 	runGit(t, dir, "commit", "-m", msg)
 }
+
+func TestParseDangling(t *testing.T) {
+	sha1 := strings.Repeat("a", 40)
+	sha256 := strings.Repeat("b", 64)
+	out := "dangling commit " + sha1 + "\n" +
+		"dangling blob " + sha256 + "\n" +
+		"missing blob " + strings.Repeat("c", 40) + "\n" +
+		"dangling tree deadbeef\n"
+	got := parseDangling(out)
+	if want := []string{sha1, sha256}; !slices.Equal(got, want) {
+		t.Errorf("parseDangling() = %v, want %v", got, want)
+	}
+}
@@ -385,6 +385,49 @@ func TestGitEnumerator_DuplicateBlobs(t *testing.T) {
 	}
 }
 
+func TestGitEnumerator_KeepDuplicates(t *testing.T) {
+	tmpDir := setupTestGitRepo(t)
+
+	// Copy a committed file to a second path
+	if err := os.WriteFile(filepath.Join(tmpDir, "copy.txt"), []byte("hello from git"), 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	for _, args := range [][]string{{"add", "."}, {"commit", "-m", "Copy file1"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("git %s failed: %v", args[0], err)
+		}
+	}
+
+	for _, walkAll := range []bool{false, true} {
+		for _, keep := range []bool{false, true} {
+			enumerator := NewGitEnumerator(Config{Root: tmpDir})
+			enumerator.WalkAll = walkAll
+			enumerator.KeepDuplicates = keep
+
+			var paths []string
+			err := enumerator.Enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+				if string(content) == "hello from git" {
+					paths = append(paths, prov.(types.GitProvenance).BlobPath)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("enumerate failed: %v", err)
+			}
+
+			want := 1
+			if keep {
+				want = 2
+			}
+			if len(paths) != want {
+				t.Errorf("WalkAll=%v KeepDuplicates=%v: blob found at %v, expected %d paths", walkAll, keep, paths, want)
+			}
+		}
+	}
+}
+
 func TestGitEnumerator_WalkAllHistory(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -182,6 +182,7 @@ func (e *GitHubEnumerator) ListRepoURLs(ctx context.Context) ([]RepoInfo, error)
 			Name:          repo.GetFullName(),
 			CloneURL:      repo.GetCloneURL(),
 			DefaultBranch: repo.GetDefaultBranch(),
+			Size:          int64(repo.GetSize()) * 1024, // API reports KB
 		})
 	}
 	return urls, nil
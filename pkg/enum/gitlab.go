@@ -145,11 +145,15 @@ func (e *GitLabEnumerator) ListProjectURLs(ctx context.Context) ([]RepoInfo, err
 
 	var urls []RepoInfo
 	for _, p := range projects {
-		urls = append(urls, RepoInfo{
+		info := RepoInfo{
 			Name:          p.PathWithNamespace,
 			CloneURL:      p.HTTPURLToRepo,
 			DefaultBranch: p.DefaultBranch,
-		})
+		}
+		if p.Statistics != nil {
+			info.Size = p.Statistics.RepositorySize
+		}
+		urls = append(urls, info)
 	}
 	return urls, nil
 }
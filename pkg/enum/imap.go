@@ -0,0 +1,420 @@
+package enum
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// Default IMAP ports for imaps:// (implicit TLS) and imap:// URLs.
+const (
+	DefaultIMAPSPort = 993
+	DefaultIMAPPort  = 143
+)
+
+// imapTimeout bounds connecting to an IMAP server and each command.
+const imapTimeout = 2 * time.Minute
+
+// IMAPConfig configures mailbox enumeration over IMAP.
+type IMAPConfig struct {
+	Host      string   // server host name or address
+	Port      int      // server port (0 = DefaultIMAPSPort, or DefaultIMAPPort without TLS)
+	TLS       bool     // connect with implicit TLS; otherwise STARTTLS is required
+	Insecure  bool     // without TLS, log in over plaintext if STARTTLS isn't offered
+	User      string   // login user
+	Password  string   // login password
+	Mailboxes []string // mailboxes to scan (empty = every selectable mailbox)
+	Config             // Embedded base config (MaxFileSize, ExtractLimits)
+}
+
+// IsIMAPURL reports whether target is an imap:// or imaps:// URL.
+func IsIMAPURL(target string) bool {
+	lower := strings.ToLower(target)
+	return strings.HasPrefix(lower, "imap://") || strings.HasPrefix(lower, "imaps://")
+}
+
+// ParseIMAPURL parses imap[s]://[user[:password]@]host[:port][/mailbox].
+func ParseIMAPURL(raw string) (IMAPConfig, error) {
+	if !IsIMAPURL(raw) {
+		return IMAPConfig{}, fmt.Errorf("not an imap:// or imaps:// URL: %s", raw)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return IMAPConfig{}, fmt.Errorf("invalid IMAP URL: %w", err)
+	}
+	if u.Hostname() == "" {
+		return IMAPConfig{}, fmt.Errorf("invalid IMAP URL %q: missing host", raw)
+	}
+
+	cfg := IMAPConfig{Host: u.Hostname(), TLS: strings.EqualFold(u.Scheme, "imaps")}
+	if p := u.Port(); p != "" {
+		if cfg.Port, err = strconv.Atoi(p); err != nil {
+			return IMAPConfig{}, fmt.Errorf("invalid IMAP URL %q: bad port", raw)
+		}
+	}
+	if u.User != nil {
+		cfg.User = u.User.Username()
+		cfg.Password, _ = u.User.Password()
+	}
+	if mailbox := strings.Trim(u.Path, "/"); mailbox != "" {
+		cfg.Mailboxes = []string{mailbox}
+	}
+	return cfg, nil
+}
+
+// URL returns the URL of the configured server, or of a mailbox on it,
+// without credentials.
+func (c IMAPConfig) URL(mailbox string) string {
+	scheme, port := "imap", DefaultIMAPPort
+	if c.TLS {
+		scheme, port = "imaps", DefaultIMAPSPort
+	}
+	host := c.Host
+	if c.Port != 0 && c.Port != port {
+		host = net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+	}
+	return (&url.URL{Scheme: scheme, Host: host, Path: "/" + mailbox}).String()
+}
+
+// IMAPEnumerator enumerates the messages of IMAP mailboxes. Mailboxes are
+// opened read-only, so messages are not marked as read. Each message is
+// split into its decoded text parts and the text of its attachments, which
+// are yielded with ArchiveProvenance: the message's URL (mailbox and UID,
+// e.g. imaps://mail.corp.com/Shared/;UID=42) and the part's name. Messages
+// larger than MaxFileSize are skipped.
+type IMAPEnumerator struct {
+	config IMAPConfig
+}
+
+// NewIMAPEnumerator creates a new IMAP mailbox enumerator.
+func NewIMAPEnumerator(cfg IMAPConfig) *IMAPEnumerator {
+	if cfg.ExtractLimits.MaxSize == 0 {
+		cfg.ExtractLimits = DefaultExtractionLimits()
+	}
+	return &IMAPEnumerator{config: cfg}
+}
+
+// Enumerate logs in and walks every configured mailbox.
+func (e *IMAPEnumerator) Enumerate(ctx context.Context, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	c, err := e.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.close()
+
+	if _, err := c.command("LOGIN %s %s", imapQuote(e.config.User), imapQuote(e.config.Password)); err != nil {
+		return fmt.Errorf("IMAP login to %s: %w", e.config.Host, err)
+	}
+	defer c.command("LOGOUT")
+
+	mailboxes := e.config.Mailboxes
+	if len(mailboxes) == 0 {
+		if mailboxes, err = c.list(); err != nil {
+			return fmt.Errorf("listing mailboxes: %w", err)
+		}
+	}
+
+	for _, mailbox := range mailboxes {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := e.enumerateMailbox(ctx, c, mailbox, callback); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dial connects to the server, upgrading plaintext connections with STARTTLS
+// when the server offers it.
+func (e *IMAPEnumerator) dial(ctx context.Context) (*imapConn, error) {
+	port := e.config.Port
+	if port == 0 {
+		port = DefaultIMAPPort
+		if e.config.TLS {
+			port = DefaultIMAPSPort
+		}
+	}
+	addr := net.JoinHostPort(e.config.Host, strconv.Itoa(port))
+	dialer := &net.Dialer{Timeout: imapTimeout}
+
+	var conn net.Conn
+	var err error
+	if e.config.TLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: e.config.Host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %w", addr, err)
+	}
+
+	c := newIMAPConn(conn)
+	if _, err := c.readResponse(); err != nil {
+		c.close()
+		return nil, fmt.Errorf("reading IMAP greeting from %s: %w", addr, err)
+	}
+	if e.config.TLS {
+		return c, nil
+	}
+
+	caps, err := c.command("CAPABILITY")
+	if err != nil {
+		c.close()
+		return nil, fmt.Errorf("IMAP capabilities of %s: %w", addr, err)
+	}
+	if imapHasCapability(caps, "STARTTLS") {
+		if _, err := c.command("STARTTLS"); err != nil {
+			c.close()
+			return nil, fmt.Errorf("IMAP STARTTLS with %s: %w", addr, err)
+		}
+		return newIMAPConn(tls.Client(conn, &tls.Config{ServerName: e.config.Host})), nil
+	}
+	if !e.config.Insecure {
+		c.close()
+		return nil, fmt.Errorf("%s does not offer STARTTLS: use imaps://, or --imap-insecure to send credentials in plaintext", addr)
+	}
+	fmt.Fprintf(os.Stderr, "warning: %s does not offer STARTTLS; credentials will be sent in plaintext\n", addr)
+	return c, nil
+}
+
+// imapHasCapability reports whether the untagged CAPABILITY responses list
+// the capability name.
+func imapHasCapability(resps []imapResponse, name string) bool {
+	for _, r := range resps {
+		fields := strings.Fields(r.text)
+		if len(fields) < 2 || fields[0] != "*" || !strings.EqualFold(fields[1], "CAPABILITY") {
+			continue
+		}
+		for _, f := range fields[2:] {
+			if strings.EqualFold(f, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// imapFetchSize matches the UID and size in a FETCH response.
+var imapFetchSize = regexp.MustCompile(`(?i)\bUID (\d+)\b.*\bRFC822\.SIZE (\d+)|\bRFC822\.SIZE (\d+)\b.*\bUID (\d+)`)
+
+// enumerateMailbox yields the parts of every message in a mailbox. A mailbox
+// that can't be opened is reported and skipped.
+func (e *IMAPEnumerator) enumerateMailbox(ctx context.Context, c *imapConn, mailbox string, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	status, err := c.command("EXAMINE %s", imapQuote(mailbox))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: opening %s: %v\n", e.config.URL(mailbox), err)
+		return nil
+	}
+	for _, r := range status {
+		// Some servers reject a UID range in an empty mailbox
+		if strings.EqualFold(r.text, "* 0 EXISTS") {
+			return nil
+		}
+	}
+
+	resps, err := c.command("UID FETCH 1:* (UID RFC822.SIZE)")
+	if err != nil {
+		return fmt.Errorf("listing messages in %s: %w", mailbox, err)
+	}
+	for _, r := range resps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		m := imapFetchSize.FindStringSubmatch(r.text)
+		if m == nil {
+			continue
+		}
+		uid, size := m[1], m[2]
+		if uid == "" {
+			uid, size = m[4], m[3]
+		}
+		if n, _ := strconv.ParseInt(size, 10, 64); e.config.MaxFileSize > 0 && n > e.config.MaxFileSize {
+			continue
+		}
+
+		msgURL := e.config.URL(mailbox) + "/;UID=" + uid
+		body, err := c.command("UID FETCH %s BODY.PEEK[]", uid)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: fetching %s: %v\n", msgURL, err)
+			continue
+		}
+		for _, r := range body {
+			if len(r.literals) == 0 {
+				continue
+			}
+			if err := e.yieldMessage(msgURL, r.literals[0], callback); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// yieldMessage yields the text extracted from one message.
+func (e *IMAPEnumerator) yieldMessage(msgURL string, raw []byte, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	parts, err := extractEMLWithState(raw, &extractState{limits: e.config.ExtractLimits})
+	if err != nil {
+		return nil
+	}
+	for _, part := range parts {
+		prov := types.ArchiveProvenance{ArchivePath: msgURL, MemberPath: part.Name}
+		if err := callback(part.Content, types.ComputeBlobID(part.Content), prov); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// imapConn is a minimal IMAP4rev1 client connection: it sends tagged
+// commands and collects their untagged responses, including literals.
+type imapConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	tag  int
+}
+
+// imapResponse is one untagged response line, with any literals it
+// contained replaced by {n} markers in text.
+type imapResponse struct {
+	text     string
+	literals [][]byte
+}
+
+func newIMAPConn(conn net.Conn) *imapConn {
+	return &imapConn{conn: conn, r: bufio.NewReader(conn)}
+}
+
+func (c *imapConn) close() error {
+	return c.conn.Close()
+}
+
+// command sends a command and returns its untagged responses, or an error if
+// it does not complete with OK.
+func (c *imapConn) command(format string, args ...any) ([]imapResponse, error) {
+	c.tag++
+	tag := fmt.Sprintf("t%d", c.tag)
+	c.conn.SetDeadline(time.Now().Add(imapTimeout))
+	if _, err := fmt.Fprintf(c.conn, "%s %s\r\n", tag, fmt.Sprintf(format, args...)); err != nil {
+		return nil, err
+	}
+
+	var resps []imapResponse
+	for {
+		r, err := c.readResponse()
+		if err != nil {
+			return nil, err
+		}
+		if rest, ok := strings.CutPrefix(r.text, tag+" "); ok {
+			if status, _, _ := strings.Cut(rest, " "); !strings.EqualFold(status, "OK") {
+				return nil, fmt.Errorf("%s", rest)
+			}
+			return resps, nil
+		}
+		if strings.HasPrefix(r.text, "* ") {
+			resps = append(resps, r)
+		}
+	}
+}
+
+// readResponse reads one response line, following any literals it contains.
+func (c *imapConn) readResponse() (imapResponse, error) {
+	var r imapResponse
+	var text strings.Builder
+	for {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			return r, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		text.WriteString(line)
+
+		open := strings.LastIndexByte(line, '{')
+		if open < 0 || !strings.HasSuffix(line, "}") {
+			break
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(line[open+1:len(line)-1], "+"))
+		if err != nil {
+			break
+		}
+		literal := make([]byte, n)
+		if _, err := io.ReadFull(c.r, literal); err != nil {
+			return r, err
+		}
+		r.literals = append(r.literals, literal)
+	}
+	r.text = text.String()
+	return r, nil
+}
+
+// list returns the selectable mailboxes.
+func (c *imapConn) list() ([]string, error) {
+	resps, err := c.command(`LIST "" "*"`)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, r := range resps {
+		rest, ok := strings.CutPrefix(r.text, "* LIST ")
+		if !ok {
+			continue
+		}
+		flagsEnd := strings.IndexByte(rest, ')')
+		if flagsEnd < 0 {
+			continue
+		}
+		flags := strings.ToLower(rest[:flagsEnd])
+		if strings.Contains(flags, `\noselect`) || strings.Contains(flags, `\nonexistent`) {
+			continue
+		}
+		// Skip the hierarchy delimiter, then read the name
+		_, rest = imapToken(strings.TrimSpace(rest[flagsEnd+1:]))
+		name, _ := imapToken(strings.TrimSpace(rest))
+		if strings.HasPrefix(name, "{") && len(r.literals) > 0 {
+			name = string(r.literals[len(r.literals)-1])
+		}
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// imapToken splits the first atom or quoted string off s.
+func imapToken(s string) (token, rest string) {
+	if !strings.HasPrefix(s, `"`) {
+		token, rest, _ = strings.Cut(s, " ")
+		return token, rest
+	}
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+			}
+		case '"':
+			return b.String(), s[i+1:]
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), ""
+}
+
+// imapQuote returns s as an IMAP quoted string.
+func imapQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,102 @@
+package enum
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/keystore"
+)
+
+// Markers appended to lines rendered from keystores, matched by the
+// np.keystore rules.
+const (
+	keystoreKeyMarker      = "# keystore:key"
+	keystorePasswordMarker = "# keystore:weak-password"
+)
+
+// keystoreMember names the blob rendered from a keystore.
+const keystoreMember = "keystore"
+
+// ExtractKeystore reads a PKCS#12 or Java keystore, trying
+// keystore.CommonPasswords, and renders what it holds as text, one
+// `path = "value"` line per fact:
+//
+//	keystore = "pkcs12"
+//	opens_with = "changeit" # keystore:weak-password
+//	entry[0].private_key = "server (sha256:3f5a...)" # keystore:key
+//	entry[0].certificate[0].subject = "CN=api.example.com"
+//
+// Private and secret keys are marked, and so is a common password when it
+// opens a keystore holding keys. The password line is not keyed "password"
+// so that generic password rules leave it to np.keystore.2. It returns nil
+// if content isn't a keystore.
+func ExtractKeystore(path string, content []byte) ([]ExtractedContent, error) {
+	format := keystore.Detect(path, content)
+	if format == "" {
+		return nil, nil
+	}
+	ks, err := keystore.Open(format, content, keystore.CommonPasswords)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "keystore = %q\n", ks.Format)
+	if ks.Password != nil {
+		pw := *ks.Password
+		if pw == "" {
+			pw = "(empty)"
+		}
+		if ks.HasKeys() {
+			fmt.Fprintf(&b, "opens_with = %q %s\n", pw, keystorePasswordMarker)
+		} else {
+			fmt.Fprintf(&b, "opens_with = %q\n", pw)
+		}
+	} else if ks.Encrypted {
+		b.WriteString("# encrypted; no common password opened it\n")
+	}
+
+	for i, e := range ks.Entries {
+		prefix := fmt.Sprintf("entry[%d]", i)
+		if e.Alias != "" {
+			fmt.Fprintf(&b, "%s.alias = %q\n", prefix, e.Alias)
+		}
+		switch {
+		case e.PrivateKey:
+			fmt.Fprintf(&b, "%s.private_key = %q %s\n", prefix, keystoreKeyName(i, e), keystoreKeyMarker)
+		case e.SecretKey:
+			fmt.Fprintf(&b, "%s.secret_key = %q %s\n", prefix, keystoreKeyName(i, e), keystoreKeyMarker)
+		}
+		for j, cert := range e.Certificates {
+			certPrefix := fmt.Sprintf("%s.certificate[%d]", prefix, j)
+			sum := sha256.Sum256(cert.Raw)
+			fmt.Fprintf(&b, "%s.subject = %q\n", certPrefix, cert.Subject.String())
+			fmt.Fprintf(&b, "%s.issuer = %q\n", certPrefix, cert.Issuer.String())
+			fmt.Fprintf(&b, "%s.not_after = %q\n", certPrefix, cert.NotAfter.UTC().Format(time.RFC3339))
+			fmt.Fprintf(&b, "%s.sha256 = %q\n", certPrefix, hex.EncodeToString(sum[:]))
+		}
+	}
+
+	return []ExtractedContent{{Name: keystoreMember, Content: []byte(b.String())}}, nil
+}
+
+// keystoreKeyName identifies a key by its alias and the fingerprint of its
+// certificate, or its local key ID when the certificates are encrypted, so
+// that different keys sharing an alias are told apart.
+func keystoreKeyName(i int, e *keystore.Entry) string {
+	name := e.Alias
+	if name == "" {
+		name = fmt.Sprintf("#%d", i)
+	}
+	switch {
+	case len(e.Certificates) > 0:
+		sum := sha256.Sum256(e.Certificates[0].Raw)
+		name += " (sha256:" + hex.EncodeToString(sum[:8]) + ")"
+	case len(e.KeyID()) > 0:
+		name += " (key id " + hex.EncodeToString(e.KeyID()) + ")"
+	}
+	return name
+}
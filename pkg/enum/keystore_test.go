@@ -0,0 +1,78 @@
+package enum
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func extractedKeystore(t *testing.T, name string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join("../../testdata/keystore", name))
+	require.NoError(t, err)
+	extracted, err := ExtractKeystore(name, content)
+	require.NoError(t, err)
+	require.Len(t, extracted, 1)
+	assert.Equal(t, "keystore", extracted[0].Name)
+	return string(extracted[0].Content)
+}
+
+func TestExtractKeystore_CommonPassword(t *testing.T) {
+	assert.Equal(t, `keystore = "pkcs12"
+opens_with = "changeit" # keystore:weak-password
+entry[0].alias = "server"
+entry[0].private_key = "server (sha256:6f711330a3c069bd)" # keystore:key
+entry[0].certificate[0].subject = "CN=api.example.com,O=Example Corp"
+entry[0].certificate[0].issuer = "CN=api.example.com,O=Example Corp"
+entry[0].certificate[0].not_after = "2126-09-22T20:14:33Z"
+entry[0].certificate[0].sha256 = "6f711330a3c069bd93f89442b80bed8d4312cc84acca30f38be421c93bc2b449"
+`, extractedKeystore(t, "changeit.p12"))
+
+	assert.Contains(t, extractedKeystore(t, "legacy-empty.pfx"), `opens_with = "(empty)" # keystore:weak-password`)
+}
+
+func TestExtractKeystore_StrongPassword(t *testing.T) {
+	assert.Equal(t, `keystore = "pkcs12"
+# encrypted; no common password opened it
+entry[0].alias = "server"
+entry[0].private_key = "server (key id 1d171a3569b7ab30ce0671f5717ab206b0f5249e)" # keystore:key
+`, extractedKeystore(t, "strong.p12"))
+}
+
+func TestExtractKeystore_Truststore(t *testing.T) {
+	rendered := extractedKeystore(t, "truststore.p12")
+	assert.Contains(t, rendered, "opens_with = \"changeit\"\n")
+	assert.NotContains(t, rendered, "# keystore:")
+}
+
+func TestExtractKeystore_NotKeystore(t *testing.T) {
+	extracted, err := ExtractKeystore("notes.txt", []byte("hello"))
+	require.NoError(t, err)
+	assert.Nil(t, extracted)
+}
+
+func TestFilesystemEnumerator_Keystore(t *testing.T) {
+	content, err := os.ReadFile("../../testdata/keystore/changeit.p12")
+	require.NoError(t, err)
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "server.pfx")
+	require.NoError(t, os.WriteFile(path, content, 0644))
+
+	enumerate := func(config Config) []types.Provenance {
+		var provs []types.Provenance
+		err := NewFilesystemEnumerator(config).Enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+			provs = append(provs, prov)
+			return nil
+		})
+		require.NoError(t, err)
+		return provs
+	}
+
+	assert.Equal(t, []types.Provenance{types.ArchiveProvenance{ArchivePath: path, MemberPath: "keystore"}}, enumerate(Config{Root: tmpDir, ExtractArchives: "pfx"}))
+	assert.Empty(t, enumerate(Config{Root: tmpDir}), "keystores are read only with extraction enabled")
+}
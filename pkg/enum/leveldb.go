@@ -0,0 +1,232 @@
+package enum
+
+import (
+	"encoding/binary"
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/snappy"
+)
+
+// leveldbRecord is a key/value pair read from a LevelDB file.
+type leveldbRecord struct {
+	Key   []byte
+	Value []byte
+}
+
+var errLevelDBCorrupt = errors.New("corrupt leveldb file")
+
+// leveldbRecords returns the values written to a LevelDB table (.ldb, .sst)
+// or write-ahead log (.log) file, in file order. Deletions are skipped and
+// values for the same key aren't merged: for secrets scanning, every value
+// still on disk is of interest.
+func leveldbRecords(path string, content []byte) ([]leveldbRecord, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".log":
+		return leveldbLog(content)
+	case ".ldb", ".sst":
+		return leveldbTable(content)
+	}
+	return nil, nil
+}
+
+const (
+	leveldbLogBlockSize  = 32 * 1024
+	leveldbLogHeaderSize = 7
+	leveldbFooterSize    = 48
+	leveldbTableMagic    = 0xdb4775248b80fb57
+	leveldbMaxBlockSize  = 16 * 1024 * 1024
+)
+
+// leveldbLog reads the write batches of a log file. A torn or corrupt record
+// ends the log, as it does for LevelDB's own recovery.
+func leveldbLog(content []byte) ([]leveldbRecord, error) {
+	var records []leveldbRecord
+	var batch []byte
+	for block := 0; block < len(content); block += leveldbLogBlockSize {
+		end := min(block+leveldbLogBlockSize, len(content))
+		for off := block; off+leveldbLogHeaderSize <= end; {
+			length := int(binary.LittleEndian.Uint16(content[off+4:]))
+			typ := content[off+6]
+			off += leveldbLogHeaderSize
+			if off+length > end {
+				return records, nil
+			}
+			data := content[off : off+length]
+			off += length
+			switch typ {
+			case 1: // full
+				records = append(records, leveldbBatch(data)...)
+			case 2: // first
+				batch = append([]byte(nil), data...)
+			case 3: // middle
+				batch = append(batch, data...)
+			case 4: // last
+				records = append(records, leveldbBatch(append(batch, data...))...)
+				batch = nil
+			}
+		}
+	}
+	return records, nil
+}
+
+// leveldbBatch decodes a write batch: an 8-byte sequence number, a 4-byte
+// count, then tagged puts and deletions.
+func leveldbBatch(data []byte) []leveldbRecord {
+	if len(data) < 12 {
+		return nil
+	}
+	var records []leveldbRecord
+	data = data[12:]
+	for len(data) > 0 {
+		tag := data[0]
+		data = data[1:]
+		key, rest, ok := leveldbSlice(data)
+		if !ok {
+			break
+		}
+		data = rest
+		if tag == 0 { // deletion
+			continue
+		}
+		value, rest, ok := leveldbSlice(data)
+		if !ok {
+			break
+		}
+		data = rest
+		records = append(records, leveldbRecord{Key: key, Value: value})
+	}
+	return records
+}
+
+// leveldbSlice reads a varint length-prefixed byte slice.
+func leveldbSlice(data []byte) (slice, rest []byte, ok bool) {
+	n, size := binary.Uvarint(data)
+	if size <= 0 || uint64(len(data)-size) < n {
+		return nil, nil, false
+	}
+	data = data[size:]
+	return data[:n], data[n:], true
+}
+
+// leveldbTable reads the data blocks of a table file via its index block.
+func leveldbTable(content []byte) ([]leveldbRecord, error) {
+	if len(content) < leveldbFooterSize {
+		return nil, errLevelDBCorrupt
+	}
+	footer := content[len(content)-leveldbFooterSize:]
+	if binary.LittleEndian.Uint64(footer[40:]) != leveldbTableMagic {
+		return nil, errLevelDBCorrupt
+	}
+	_, n := leveldbHandle(footer) // metaindex
+	if n <= 0 {
+		return nil, errLevelDBCorrupt
+	}
+	index, m := leveldbHandle(footer[n:])
+	if m <= 0 {
+		return nil, errLevelDBCorrupt
+	}
+
+	indexBlock, err := leveldbBlock(content, index)
+	if err != nil {
+		return nil, err
+	}
+	var records []leveldbRecord
+	for _, entry := range leveldbBlockEntries(indexBlock) {
+		handle, n := leveldbHandle(entry.Value)
+		if n <= 0 {
+			continue
+		}
+		block, err := leveldbBlock(content, handle)
+		if err != nil {
+			continue
+		}
+		for _, r := range leveldbBlockEntries(block) {
+			// Keys carry an 8-byte trailer whose low byte is the value
+			// type: 0 for deletions, 1 for values.
+			if len(r.Key) < 8 || r.Key[len(r.Key)-8] != 1 {
+				continue
+			}
+			records = append(records, leveldbRecord{Key: r.Key[:len(r.Key)-8], Value: r.Value})
+		}
+	}
+	return records, nil
+}
+
+type leveldbBlockHandle struct {
+	offset, size uint64
+}
+
+func leveldbHandle(data []byte) (leveldbBlockHandle, int) {
+	offset, n := binary.Uvarint(data)
+	if n <= 0 {
+		return leveldbBlockHandle{}, n
+	}
+	size, m := binary.Uvarint(data[n:])
+	if m <= 0 {
+		return leveldbBlockHandle{}, m
+	}
+	return leveldbBlockHandle{offset: offset, size: size}, n + m
+}
+
+// leveldbBlock returns the contents of a block, decompressing it if its
+// trailer says it is snappy-compressed.
+func leveldbBlock(content []byte, h leveldbBlockHandle) ([]byte, error) {
+	end := h.offset + h.size
+	if end < h.offset || end+5 > uint64(len(content)) {
+		return nil, errLevelDBCorrupt
+	}
+	block := content[h.offset:end]
+	switch content[end] {
+	case 0:
+		return block, nil
+	case 1:
+		// Blocks are a few KB; refuse to inflate a corrupt one into many MB.
+		if n, err := snappy.DecodedLen(block); err != nil || n > leveldbMaxBlockSize {
+			return nil, errLevelDBCorrupt
+		}
+		return snappy.Decode(nil, block)
+	}
+	return nil, errLevelDBCorrupt
+}
+
+// leveldbBlockEntries decodes the prefix-compressed entries of a block.
+func leveldbBlockEntries(block []byte) []leveldbRecord {
+	if len(block) < 4 {
+		return nil
+	}
+	restarts := int(binary.LittleEndian.Uint32(block[len(block)-4:]))
+	limit := len(block) - 4 - 4*restarts
+	if restarts < 0 || limit < 0 {
+		return nil
+	}
+
+	var records []leveldbRecord
+	var key []byte
+	for data := block[:limit]; len(data) > 0; {
+		shared, n1 := binary.Uvarint(data)
+		if n1 <= 0 {
+			break
+		}
+		unshared, n2 := binary.Uvarint(data[n1:])
+		if n2 <= 0 {
+			break
+		}
+		valueLen, n3 := binary.Uvarint(data[n1+n2:])
+		if n3 <= 0 {
+			break
+		}
+		data = data[n1+n2+n3:]
+		if shared > uint64(len(key)) || unshared+valueLen > uint64(len(data)) {
+			break
+		}
+		key = append(key[:shared:shared], data[:unshared]...)
+		records = append(records, leveldbRecord{
+			Key:   key,
+			Value: data[unshared : unshared+valueLen],
+		})
+		data = data[unshared+valueLen:]
+	}
+	return records
+}
@@ -0,0 +1,111 @@
+package enum
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testLevelDBLog builds a log file holding one write batch per record.
+func testLevelDBLog(records ...leveldbRecord) []byte {
+	var log []byte
+	for i, r := range records {
+		batch := binary.LittleEndian.AppendUint64(nil, uint64(i+1))
+		batch = binary.LittleEndian.AppendUint32(batch, 1)
+		batch = append(batch, 1)
+		batch = binary.AppendUvarint(batch, uint64(len(r.Key)))
+		batch = append(batch, r.Key...)
+		batch = binary.AppendUvarint(batch, uint64(len(r.Value)))
+		batch = append(batch, r.Value...)
+
+		log = append(log, 0, 0, 0, 0) // checksum, not verified
+		log = binary.LittleEndian.AppendUint16(log, uint16(len(batch)))
+		log = append(log, 1) // full record
+		log = append(log, batch...)
+	}
+	return log
+}
+
+// testLevelDBTable builds a table file with all records in one data block.
+func testLevelDBTable(compress bool, records ...leveldbRecord) []byte {
+	var data []byte
+	for i, r := range records {
+		key := binary.LittleEndian.AppendUint64(append([]byte(nil), r.Key...), uint64(i+1)<<8|1)
+		data = binary.AppendUvarint(data, 0)
+		data = binary.AppendUvarint(data, uint64(len(key)))
+		data = binary.AppendUvarint(data, uint64(len(r.Value)))
+		data = append(data, key...)
+		data = append(data, r.Value...)
+	}
+	data = binary.LittleEndian.AppendUint32(data, 0) // restart at 0
+	data = binary.LittleEndian.AppendUint32(data, 1)
+
+	var file []byte
+	writeBlock := func(block []byte, compress bool) []byte {
+		handle := binary.AppendUvarint(nil, uint64(len(file)))
+		typ := byte(0)
+		if compress {
+			block, typ = snappy.Encode(nil, block), 1
+		}
+		handle = binary.AppendUvarint(handle, uint64(len(block)))
+		file = append(file, block...)
+		file = append(file, typ, 0, 0, 0, 0)
+		return handle
+	}
+	dataHandle := writeBlock(data, compress)
+
+	index := binary.AppendUvarint(nil, 0)
+	index = binary.AppendUvarint(index, 1)
+	index = binary.AppendUvarint(index, uint64(len(dataHandle)))
+	index = append(index, 'z')
+	index = append(index, dataHandle...)
+	index = binary.LittleEndian.AppendUint32(index, 0)
+	index = binary.LittleEndian.AppendUint32(index, 1)
+
+	metaHandle := writeBlock(binary.LittleEndian.AppendUint32(nil, 0), false)
+	indexHandle := writeBlock(index, false)
+
+	footer := append(metaHandle, indexHandle...)
+	footer = append(footer, make([]byte, 40-len(footer))...)
+	footer = binary.LittleEndian.AppendUint64(footer, leveldbTableMagic)
+	return append(file, footer...)
+}
+
+func TestLevelDBRecords(t *testing.T) {
+	records := []leveldbRecord{
+		{Key: []byte("alpha"), Value: []byte("one")},
+		{Key: []byte("beta"), Value: []byte("two")},
+	}
+
+	for name, content := range map[string][]byte{
+		"000003.log":   testLevelDBLog(records...),
+		"000005.ldb":   testLevelDBTable(false, records...),
+		"000007.ldb":   testLevelDBTable(true, records...),
+		"000009.sst":   testLevelDBTable(true, records...),
+		"MANIFEST-001": []byte("ignored"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := leveldbRecords(name, content)
+			require.NoError(t, err)
+			if name == "MANIFEST-001" {
+				assert.Empty(t, got)
+				return
+			}
+			assert.Equal(t, records, got)
+		})
+	}
+}
+
+func TestLevelDBRecords_Corrupt(t *testing.T) {
+	_, err := leveldbRecords("000005.ldb", []byte("too short"))
+	assert.Error(t, err)
+
+	// A torn log record ends the log without an error.
+	log := testLevelDBLog(leveldbRecord{Key: []byte("k"), Value: []byte("v")})
+	got, err := leveldbRecords("000003.log", append(log, 0, 0, 0, 0, 0xff, 0x00, 1))
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+}
@@ -0,0 +1,126 @@
+package enum
+
+import (
+	"bytes"
+	"fmt"
+	stdhtml "html"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// isMarkup reports whether path is an HTML or Markdown document.
+func isMarkup(p string) bool {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".html", ".htm", ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+func isMarkdown(p string) bool {
+	ext := strings.ToLower(path.Ext(p))
+	return ext == ".md" || ext == ".markdown"
+}
+
+var (
+	// mdInlineLink matches the target of a Markdown link or image,
+	// [text](url) or ![alt](url).
+	mdInlineLink = regexp.MustCompile(`\]\(\s*<?([^)\s>]+)`)
+
+	// mdReferenceLink matches the target of a link reference definition,
+	// [id]: url.
+	mdReferenceLink = regexp.MustCompile(`(?m)^ {0,3}\[[^\]]+\]:\s*<?([^\s>]+)`)
+
+	// mdURL matches autolinks and bare URLs.
+	mdURL = regexp.MustCompile(`\b(?:https?|ftp|wss?)://[^\s<>()\[\]"'` + "`" + `]+`)
+)
+
+// ExtractMarkup analyzes an HTML or Markdown document and returns blobs for
+// what plain matching misses:
+//
+//   - text and attribute values with HTML entities, decoded, as "decoded";
+//   - for Markdown, link targets and URLs, with entities and percent-encoding
+//     decoded, as "links".
+//
+// Scripts, styles, comments and attributes are left to the document itself,
+// which is scanned as-is as well.
+func ExtractMarkup(p string, content []byte) ([]ExtractedContent, error) {
+	var results []ExtractedContent
+	var decoded, links []byte
+	add := func(b *[]byte, line string) {
+		*b = append(*b, line...)
+		*b = append(*b, '\n')
+	}
+
+	z := html.NewTokenizer(bytes.NewReader(content))
+	inRawText := false // in a script or style element, whose text isn't decoded
+tokens:
+	for {
+		tt := z.Next()
+		// Entities are decoded in place as the token is read, so keep the
+		// raw token if it may hold some
+		var raw string
+		if bytes.IndexByte(z.Raw(), '&') >= 0 {
+			raw = string(z.Raw())
+		}
+		switch tt {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				return nil, fmt.Errorf("failed to parse html: %w", err)
+			}
+			break tokens
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			for _, a := range tok.Attr {
+				if raw != "" && strings.TrimSpace(a.Val) != "" && !strings.Contains(raw, a.Val) {
+					add(&decoded, a.Key+`="`+a.Val+`"`)
+				}
+			}
+			if tt == html.StartTagToken && (tok.Data == "script" || tok.Data == "style") {
+				inRawText = true
+			}
+		case html.EndTagToken:
+			inRawText = false
+		case html.TextToken:
+			text := string(z.Text())
+			if !inRawText && raw != "" && text != raw && strings.TrimSpace(text) != "" {
+				add(&decoded, strings.TrimSpace(text))
+			}
+		}
+	}
+
+	if isMarkdown(p) {
+		seen := make(map[string]bool)
+		for _, re := range []*regexp.Regexp{mdInlineLink, mdReferenceLink, mdURL} {
+			for _, m := range re.FindAllSubmatch(content, -1) {
+				link := string(m[len(m)-1])
+				link = stdhtml.UnescapeString(link)
+				if unescaped, err := url.PathUnescape(link); err == nil {
+					link = unescaped
+				}
+				if !seen[link] {
+					seen[link] = true
+					add(&links, link)
+				}
+			}
+		}
+	}
+
+	for _, b := range []struct {
+		name    string
+		content []byte
+	}{
+		{"decoded", decoded},
+		{"links", links},
+	} {
+		if len(b.content) > 0 {
+			results = append(results, ExtractedContent{Name: b.name, Content: b.content})
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,148 @@
+package enum
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// mboxQuotedFrom matches body lines that mboxrd archives quote with '>'.
+var mboxQuotedFrom = regexp.MustCompile(`^>+From `)
+
+// splitMbox calls fn with each message of an mbox archive, numbered from 1.
+// A message starts at a "From " line at the start of the archive or after a
+// blank line; lines quoted as ">From " are unquoted. Messages larger than
+// maxSize bytes are skipped. fn must not retain msg.
+func splitMbox(r io.Reader, maxSize int64, fn func(n int, msg []byte) error) error {
+	br := bufio.NewReaderSize(r, 64*1024)
+	var msg bytes.Buffer
+	n := 0
+	inMessage, oversize, prevBlank := false, false, true
+
+	flush := func() error {
+		if !inMessage || oversize || msg.Len() == 0 {
+			return nil
+		}
+		return fn(n, msg.Bytes())
+	}
+
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			if prevBlank && bytes.HasPrefix(line, []byte("From ")) {
+				if err := flush(); err != nil {
+					return err
+				}
+				n++
+				msg.Reset()
+				inMessage, oversize = true, false
+			} else if inMessage && !oversize {
+				if mboxQuotedFrom.Match(line) {
+					line = line[1:]
+				}
+				if int64(msg.Len()+len(line)) > maxSize {
+					oversize = true
+					msg.Reset()
+				} else {
+					msg.Write(line)
+				}
+			}
+			prevBlank = len(bytes.TrimRight(line, "\r\n")) == 0
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return flush()
+}
+
+// errMboxLimit stops walkMbox once the extraction limits are reached.
+var errMboxLimit = errors.New("mbox extraction limit reached")
+
+// walkMbox calls fn with the text of each message in an mbox archive, as
+// extracted from an .eml file and named "message-N:<part>". The messages
+// share the limits of state: each counts as an entry, and MaxTotal bounds
+// the archive as a whole, the remaining messages being skipped once either
+// is reached.
+func walkMbox(r io.Reader, state *extractState, fn func(ExtractedContent) error) error {
+	err := splitMbox(r, state.limits.MaxTotal, func(n int, msg []byte) error {
+		name := fmt.Sprintf("message-%d", n)
+		if state.total >= state.limits.MaxTotal {
+			state.skipped(name, SkipMaxTotal+", remaining messages skipped")
+			return errMboxLimit
+		}
+		if !state.countEntry(name) {
+			return errMboxLimit
+		}
+		msgState := state.nested(name)
+		parts, err := extractEMLWithState(msg, msgState)
+		state.total = msgState.total
+		state.entries = msgState.entries
+		if err != nil {
+			return nil
+		}
+		for _, part := range parts {
+			part.Name = name + ":" + part.Name
+			if err := fn(part); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == errMboxLimit {
+		return nil
+	}
+	return err
+}
+
+// extractMBOXWithState extracts the messages of an mbox archive.
+func extractMBOXWithState(content []byte, state *extractState) ([]ExtractedContent, error) {
+	var results []ExtractedContent
+	err := walkMbox(bytes.NewReader(content), state, func(part ExtractedContent) error {
+		results = append(results, part)
+		return nil
+	})
+	return results, err
+}
+
+// streamsMbox reports whether path is an mbox archive the filesystem
+// enumerator reads message by message. Such archives are exempt from
+// MaxFileSize, since they are never held in memory whole.
+func (e *FilesystemEnumerator) streamsMbox(path string) bool {
+	return getExtension(path) == ".mbox" && shouldExtract(e.config, ".mbox")
+}
+
+// processMbox yields the text of each message in an mbox archive on disk.
+func (e *FilesystemEnumerator) processMbox(ctx context.Context, path string, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		return nil
+	}
+	defer f.Close()
+
+	state := &extractState{
+		limits:       e.config.ExtractLimits,
+		binaryAsText: e.config.TreatBinaryAsText,
+		skip: func(member, reason string) {
+			e.config.skip(memberPath(path, member), reason)
+		},
+	}
+	return walkMbox(f, state, func(part ExtractedContent) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		prov := types.ArchiveProvenance{ArchivePath: path, MemberPath: part.Name}
+		return callback(part.Content, types.ComputeBlobID(part.Content), prov)
+	})
+}
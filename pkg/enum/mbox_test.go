@@ -0,0 +1,113 @@
+package enum
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMbox = "From alice@example.com Mon Jan  1 00:00:00 2024\n" +
+	"Subject: first\n" +
+	"\n" +
+	"token=abc123\n" +
+	">From the team\n" +
+	"From here on, not a new message\n" +
+	"\n" +
+	"From bob@example.com Tue Jan  2 00:00:00 2024\n" +
+	"Subject: second\n" +
+	"Content-Type: text/plain\n" +
+	"Content-Transfer-Encoding: base64\n" +
+	"\n" +
+	"cGFzc3dvcmQ9aHVudGVyMgo=\n"
+
+func TestSplitMbox(t *testing.T) {
+	var msgs []string
+	err := splitMbox(strings.NewReader(testMbox), 1024, func(n int, msg []byte) error {
+		assert.Equal(t, len(msgs)+1, n)
+		msgs = append(msgs, string(msg))
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	assert.Equal(t, "Subject: first\n\ntoken=abc123\nFrom the team\nFrom here on, not a new message\n\n", msgs[0])
+	assert.True(t, strings.HasPrefix(msgs[1], "Subject: second\n"))
+
+	// Oversized messages are skipped
+	var numbers []int
+	err = splitMbox(strings.NewReader(testMbox), 100, func(n int, msg []byte) error {
+		numbers = append(numbers, n)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, numbers)
+}
+
+func TestExtractText_MBOX(t *testing.T) {
+	results, err := ExtractText("archive.mbox", []byte(testMbox), DefaultExtractionLimits())
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "message-1:body", results[0].Name)
+	assert.Contains(t, string(results[0].Content), "token=abc123")
+	assert.Equal(t, ExtractedContent{Name: "message-2:body", Content: []byte("password=hunter2\n")}, results[1])
+}
+
+func TestFilesystemEnumerator_StreamsLargeMbox(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "archive.mbox")
+	require.NoError(t, os.WriteFile(path, []byte(testMbox), 0644))
+
+	// The archive is over MaxFileSize but is read message by message
+	e := NewFilesystemEnumerator(Config{
+		Root:            dir,
+		MaxFileSize:     64,
+		ExtractArchives: "mbox",
+		ExtractLimits:   DefaultExtractionLimits(),
+		IgnoreFile:      os.DevNull,
+	})
+	var provs []types.Provenance
+	err := e.Enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		provs = append(provs, prov)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []types.Provenance{
+		types.ArchiveProvenance{ArchivePath: path, MemberPath: "message-1:body"},
+		types.ArchiveProvenance{ArchivePath: path, MemberPath: "message-2:body"},
+	}, provs)
+}
+
+func TestFilesystemEnumerator_MboxLimits(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "archive.mbox")
+	require.NoError(t, os.WriteFile(path, []byte(testMbox), 0644))
+
+	// The messages share the archive's entry budget
+	limits := DefaultExtractionLimits()
+	limits.MaxEntries = 1
+	var skipped []string
+	e := NewFilesystemEnumerator(Config{
+		Root:            dir,
+		ExtractArchives: "mbox",
+		ExtractLimits:   limits,
+		IgnoreFile:      os.DevNull,
+		OnSkip: func(path, reason string) {
+			skipped = append(skipped, filepath.Base(path)+": "+reason)
+		},
+	})
+	var provs []types.Provenance
+	err := e.Enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		provs = append(provs, prov)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []types.Provenance{
+		types.ArchiveProvenance{ArchivePath: path, MemberPath: "message-1:body"},
+	}, provs)
+	assert.Equal(t, []string{"archive.mbox:message-2: " + SkipMaxEntries + ", remaining members skipped"}, skipped)
+}
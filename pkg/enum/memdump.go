@@ -0,0 +1,157 @@
+package enum
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+const (
+	// DefaultMemoryChunkSize is the number of bytes of a memory dump scanned
+	// at a time.
+	DefaultMemoryChunkSize = 16 * 1024 * 1024
+
+	// DefaultMemoryChunkOverlap is the number of bytes shared by consecutive
+	// chunks, so secrets straddling a chunk boundary are still matched whole.
+	DefaultMemoryChunkOverlap = 4 * 1024
+
+	// memoryMinUTF16String is the shortest UTF-16 string worth collecting.
+	memoryMinUTF16String = 8
+)
+
+// MemoryDumpEnumerator enumerates raw memory dumps: process dumps, core files,
+// hibernation files and the like. Root may be a single dump or a directory of
+// them. Unlike the filesystem enumerator, it neither skips binary content nor
+// loads whole files: each dump is read in fixed-size chunks that overlap by a
+// few KB, so dumps many GB in size are scanned in bounded memory.
+//
+// Each chunk is yielded with ArchiveProvenance naming the dump file and the
+// chunk's byte range ("0x1000000-0x2001000"). Windows processes keep most
+// strings as UTF-16, which byte-oriented rules can't match, so the printable
+// UTF-16LE strings of each chunk are also yielded, one per line, as a
+// separate blob ("0x1000000-0x2001000 (utf-16)").
+type MemoryDumpEnumerator struct {
+	config    Config
+	chunkSize int
+	overlap   int
+}
+
+// NewMemoryDumpEnumerator creates a new memory dump enumerator using the
+// default chunk size and overlap. Config.MaxFileSize is ignored.
+func NewMemoryDumpEnumerator(config Config) *MemoryDumpEnumerator {
+	return &MemoryDumpEnumerator{
+		config:    config,
+		chunkSize: DefaultMemoryChunkSize,
+		overlap:   DefaultMemoryChunkOverlap,
+	}
+}
+
+// Enumerate reads every regular file under Root in chunks.
+func (e *MemoryDumpEnumerator) Enumerate(ctx context.Context, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	return filepath.WalkDir(e.config.Root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		if err := e.enumerateDump(ctx, p, callback); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "warning: reading %s: %v\n", p, err)
+		}
+		return nil
+	})
+}
+
+func (e *MemoryDumpEnumerator) enumerateDump(ctx context.Context, path string, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	size := info.Size()
+
+	step := int64(e.chunkSize - e.overlap)
+	chunk := make([]byte, e.chunkSize)
+	for offset := int64(0); offset < size; offset += step {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := f.ReadAt(chunk, offset)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+
+		// The callback may hold on to content, so each blob gets its own copy.
+		content := append([]byte(nil), chunk[:n]...)
+		rng := byteRange(offset, offset+int64(n))
+		prov := types.ArchiveProvenance{ArchivePath: path, MemberPath: rng}
+		if err := callback(content, types.ComputeBlobID(content), prov); err != nil {
+			return err
+		}
+		if strs := utf16Strings(content, memoryMinUTF16String); len(strs) > 0 {
+			prov := types.ArchiveProvenance{ArchivePath: path, MemberPath: rng + " (utf-16)"}
+			if err := callback(strs, types.ComputeBlobID(strs), prov); err != nil {
+				return err
+			}
+		}
+
+		// Stop before a final chunk that would only repeat the overlap.
+		if offset+int64(n) >= size {
+			return nil
+		}
+	}
+	return nil
+}
+
+// byteRange names the bytes [start, end) of a file, e.g. "0x1000-0x2000".
+func byteRange(start, end int64) string {
+	return fmt.Sprintf("%#x-%#x", start, end)
+}
+
+// utf16Strings returns the runs of at least minLen printable ASCII characters
+// encoded as UTF-16LE in content, one per line. Runs are looked for at both
+// even and odd offsets, since chunks needn't start on a character boundary.
+func utf16Strings(content []byte, minLen int) []byte {
+	var out []byte
+	for align := 0; align < 2; align++ {
+		start, length := -1, 0
+		flush := func() {
+			if length >= minLen {
+				for i := start; i < start+2*length; i += 2 {
+					out = append(out, content[i])
+				}
+				out = append(out, '\n')
+			}
+			start, length = -1, 0
+		}
+		for i := align; i+1 < len(content); i += 2 {
+			if c := content[i]; content[i+1] == 0 && (c >= 0x20 && c < 0x7f || c == '\t') {
+				if start < 0 {
+					start = i
+				}
+				length++
+				continue
+			}
+			flush()
+		}
+		flush()
+	}
+	return out
+}
@@ -0,0 +1,79 @@
+package enum
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func enumerateMemory(t *testing.T, e *MemoryDumpEnumerator) map[string][]byte {
+	t.Helper()
+	got := make(map[string][]byte)
+	err := e.Enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		assert.Equal(t, types.ComputeBlobID(content), blobID)
+		got[prov.(types.ArchiveProvenance).MemberPath] = content
+		return nil
+	})
+	require.NoError(t, err)
+	return got
+}
+
+func TestMemoryDumpEnumerator_Chunks(t *testing.T) {
+	dump := filepath.Join(t.TempDir(), "lsass.dmp")
+	content := make([]byte, 40)
+	for i := range content {
+		content[i] = byte(0x80 + i)
+	}
+	require.NoError(t, os.WriteFile(dump, content, 0644))
+
+	e := NewMemoryDumpEnumerator(Config{Root: dump})
+	e.chunkSize, e.overlap = 16, 4
+
+	assert.Equal(t, map[string][]byte{
+		"0x0-0x10":  content[0:16],
+		"0xc-0x1c":  content[12:28],
+		"0x18-0x28": content[24:40],
+	}, enumerateMemory(t, e))
+}
+
+func TestMemoryDumpEnumerator_SecretAcrossBoundary(t *testing.T) {
+	dir := t.TempDir()
+	secret := []byte("ghp_0123456789abcdef")
+	content := append(bytes.Repeat([]byte{0xff, 0x00}, 30), secret...)
+	content = append(content, 0xfe, 0xfe)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "core.1234"), content, 0644))
+
+	e := NewMemoryDumpEnumerator(Config{Root: dir})
+	e.chunkSize, e.overlap = 64, 32
+
+	var found int
+	for _, blob := range enumerateMemory(t, e) {
+		if bytes.Contains(blob, secret) {
+			found++
+		}
+	}
+	assert.Positive(t, found, "secret should be whole in at least one chunk")
+}
+
+func TestMemoryDumpEnumerator_UTF16(t *testing.T) {
+	dump := filepath.Join(t.TempDir(), "proc.dmp")
+	content := append([]byte{0x01}, utf16LE("password=Hunter2!")...)
+	content = append(content, 0x00, 0x00, 0xff)
+	content = append(content, utf16LE("short")...)
+	require.NoError(t, os.WriteFile(dump, content, 0644))
+
+	got := enumerateMemory(t, NewMemoryDumpEnumerator(Config{Root: dump}))
+	assert.Len(t, got, 2)
+	assert.Equal(t, "password=Hunter2!\n", string(got["0x0-0x30 (utf-16)"]))
+}
+
+func TestUTF16Strings(t *testing.T) {
+	assert.Empty(t, utf16Strings([]byte("plain ascii text"), 4))
+	assert.Equal(t, "abcd\nefgh\n", string(utf16Strings(append(append(utf16LE("abcd"), 0xff, 0xff), utf16LE("efgh")...), 4)))
+}
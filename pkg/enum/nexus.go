@@ -0,0 +1,151 @@
+package enum
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// NexusConfig for Sonatype Nexus Repository API enumeration.
+type NexusConfig struct {
+	BaseURL  string   // Nexus URL, e.g. https://nexus.corp.com
+	User     string   // user name, or the name code of a user token
+	Password string   // password, or the pass code of a user token
+	Repos    []string // repository names to scan (empty = every hosted repository the user can read)
+	Client   *http.Client
+	Config   // Embedded base Config (MaxFileSize, ExtractArchives, ExtractLimits)
+}
+
+// NexusEnumerator enumerates the assets in Nexus Repository 3 repositories
+// via the REST API. Assets are yielded with FileProvenance whose path is the
+// asset's download URL; archives such as jars, wars and npm tarballs are
+// extracted, with members named by that URL.
+type NexusEnumerator struct {
+	config NexusConfig
+	root   string // Nexus URL, without a trailing slash
+	api    *apiClient
+	fs     *FilesystemEnumerator
+}
+
+// NewNexusEnumerator creates a new Nexus enumerator.
+func NewNexusEnumerator(cfg NexusConfig) (*NexusEnumerator, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("must specify Nexus URL")
+	}
+	if _, err := ValidateBaseURL(cfg.BaseURL); err != nil {
+		return nil, fmt.Errorf("Nexus URL: %w", err)
+	}
+
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Minute}
+	}
+	// Download URLs come from the server, so credentials only go to the
+	// Nexus the user named
+	base, _ := url.Parse(cfg.BaseURL)
+	api := &apiClient{client: client, auth: func(req *http.Request) {
+		if cfg.User != "" && sameOrigin(req.URL, base) {
+			req.SetBasicAuth(cfg.User, cfg.Password)
+		}
+		req.Header.Set("Accept", "application/json")
+	}}
+
+	return &NexusEnumerator{
+		config: cfg,
+		root:   strings.TrimRight(cfg.BaseURL, "/"),
+		api:    api,
+		fs:     NewFilesystemEnumerator(cfg.Config),
+	}, nil
+}
+
+// sameOrigin reports whether u has the scheme and host of base.
+func sameOrigin(u, base *url.URL) bool {
+	return strings.EqualFold(u.Scheme, base.Scheme) && strings.EqualFold(u.Host, base.Host)
+}
+
+type nexusRepo struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // hosted, proxy or group
+}
+
+type nexusAssetList struct {
+	Items []struct {
+		DownloadURL string `json:"downloadUrl"`
+		Path        string `json:"path"`
+		FileSize    int64  `json:"fileSize"` // 0 on versions that don't report it
+	} `json:"items"`
+	ContinuationToken string `json:"continuationToken"`
+}
+
+// Enumerate walks every configured repository.
+func (e *NexusEnumerator) Enumerate(ctx context.Context, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	repos := e.config.Repos
+	if len(repos) == 0 {
+		var err error
+		if repos, err = e.listRepos(ctx); err != nil {
+			return err
+		}
+	}
+
+	for _, repo := range repos {
+		token := ""
+		for {
+			q := url.Values{"repository": {repo}}
+			if token != "" {
+				q.Set("continuationToken", token)
+			}
+			var list nexusAssetList
+			if err := e.api.getJSON(ctx, e.root+"/service/rest/v1/assets?"+q.Encode(), &list); err != nil {
+				return fmt.Errorf("listing repository %s: %w", repo, err)
+			}
+			for _, asset := range list.Items {
+				if asset.DownloadURL == "" || isChecksumFile(asset.Path) {
+					continue
+				}
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if e.config.MaxFileSize > 0 && asset.FileSize > e.config.MaxFileSize {
+					e.config.skip(asset.DownloadURL, "too large")
+					continue
+				}
+				content, err := e.api.download(ctx, asset.DownloadURL, e.config.MaxFileSize)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "warning: downloading %s: %v\n", asset.DownloadURL, err)
+					continue
+				}
+				if err := e.fs.processContent(asset.DownloadURL, content, callback); err != nil {
+					return err
+				}
+			}
+			if list.ContinuationToken == "" {
+				break
+			}
+			token = list.ContinuationToken
+		}
+	}
+	return nil
+}
+
+// listRepos returns the names of every hosted repository the user can
+// read. Proxy repositories only cache public packages, and group ones
+// aggregate others, so they are left out.
+func (e *NexusEnumerator) listRepos(ctx context.Context) ([]string, error) {
+	var repos []nexusRepo
+	if err := e.api.getJSON(ctx, e.root+"/service/rest/v1/repositories", &repos); err != nil {
+		return nil, fmt.Errorf("listing repositories: %w", err)
+	}
+	var names []string
+	for _, r := range repos {
+		if r.Type == "hosted" {
+			names = append(names, r.Name)
+		}
+	}
+	return names, nil
+}
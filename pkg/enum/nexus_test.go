@@ -0,0 +1,127 @@
+package enum
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// npmTarball builds a gzipped tarball holding one file, as npm publishes.
+func npmTarball(t *testing.T, name, content string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))}))
+	_, err := tw.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestNexusEnumerator(t *testing.T) {
+	tgz := npmTarball(t, "package/.npmrc", "//registry.npmjs.org/:_authToken=npm_abc123\n")
+	var srvURL string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/service/rest/v1/repositories", func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		assert.Equal(t, "ci", user)
+		assert.Equal(t, "secret", pass)
+		w.Write([]byte(`[{"name":"npm-hosted","type":"hosted"},{"name":"npm-proxy","type":"proxy"},{"name":"npm-group","type":"group"}]`))
+	})
+	mux.HandleFunc("/service/rest/v1/assets", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "npm-hosted", r.URL.Query().Get("repository"), "only hosted repositories are scanned")
+		if r.URL.Query().Get("continuationToken") == "" {
+			w.Write([]byte(`{"items":[
+				{"downloadUrl":"` + srvURL + `/repository/npm-hosted/app/-/app-1.0.0.tgz","path":"app/-/app-1.0.0.tgz"},
+				{"downloadUrl":"` + srvURL + `/repository/npm-hosted/app/-/app-1.0.0.tgz.sha1","path":"app/-/app-1.0.0.tgz.sha1"}
+			],"continuationToken":"page2"}`))
+			return
+		}
+		w.Write([]byte(`{"items":[
+			{"downloadUrl":"` + srvURL + `/repository/npm-hosted/config.json","path":"config.json","fileSize":30},
+			{"downloadUrl":"` + srvURL + `/repository/npm-hosted/huge.bin","path":"huge.bin","fileSize":999999}
+		],"continuationToken":null}`))
+	})
+	mux.HandleFunc("/repository/npm-hosted/app/-/app-1.0.0.tgz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(tgz)
+	})
+	mux.HandleFunc("/repository/npm-hosted/config.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"password":"hunter2"}`))
+	})
+	mux.HandleFunc("/repository/npm-hosted/huge.bin", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("asset over the size limit was downloaded")
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	srvURL = srv.URL
+
+	e, err := NewNexusEnumerator(NexusConfig{
+		BaseURL:  srv.URL + "/",
+		User:     "ci",
+		Password: "secret",
+		Config:   Config{MaxFileSize: 4096, ExtractArchives: "all", ExtractLimits: DefaultExtractionLimits(), IgnoreFile: os.DevNull},
+	})
+	require.NoError(t, err)
+
+	blobs := make(map[string]string)
+	err = e.Enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		switch p := prov.(type) {
+		case types.ArchiveProvenance:
+			blobs[p.ArchivePath+"!"+p.MemberPath] = string(content)
+		default:
+			blobs[prov.Path()] = string(content)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		srv.URL + "/repository/npm-hosted/app/-/app-1.0.0.tgz!package/.npmrc": "//registry.npmjs.org/:_authToken=npm_abc123\n",
+		srv.URL + "/repository/npm-hosted/config.json":                        `{"password":"hunter2"}`,
+	}, blobs)
+}
+
+func TestNexusEnumerator_ForeignDownloadURL(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, ok := r.BasicAuth()
+		assert.False(t, ok, "credentials sent to a host other than the Nexus URL")
+		w.Write([]byte("token=abc"))
+	}))
+	defer other.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/service/rest/v1/assets", func(w http.ResponseWriter, r *http.Request) {
+		_, _, ok := r.BasicAuth()
+		assert.True(t, ok)
+		w.Write([]byte(`{"items":[{"downloadUrl":"` + other.URL + `/config.env","path":"config.env"}],"continuationToken":null}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	e, err := NewNexusEnumerator(NexusConfig{
+		BaseURL:  srv.URL,
+		User:     "ci",
+		Password: "secret",
+		Repos:    []string{"raw-hosted"},
+		Config:   Config{MaxFileSize: 4096, IgnoreFile: os.DevNull},
+	})
+	require.NoError(t, err)
+
+	var paths []string
+	err = e.Enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		paths = append(paths, prov.Path())
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{other.URL + "/config.env"}, paths)
+}
@@ -0,0 +1,236 @@
+package enum
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// DefaultPluginDescribeTimeout bounds a plugin's `describe` command.
+const DefaultPluginDescribeTimeout = 10 * time.Second
+
+// PluginDescription is what an enumerator plugin prints in response to
+// `describe`.
+type PluginDescription struct {
+	Name    string   `json:"name"`
+	Schemes []string `json:"schemes"`
+}
+
+// PluginItem is one blob an enumerator plugin prints for `enumerate`, as a
+// line of JSON. Content is given either as text or base64-encoded.
+type PluginItem struct {
+	Path          string `json:"path"` // shown as the blob's location, e.g. a URL
+	Content       string `json:"content,omitempty"`
+	ContentBase64 string `json:"content_base64,omitempty"`
+}
+
+// Plugin is an external executable that enumerates targets of the URL
+// schemes it describes, so organizations can scan proprietary data sources
+// without changing Titus.
+//
+// The protocol is JSON over stdout, one process per call:
+//
+//	<plugin> describe           prints a PluginDescription
+//	<plugin> enumerate <target> prints a PluginItem per blob, one per line
+//
+// A non-zero exit status fails the scan, with stderr as the reason.
+type Plugin struct {
+	path    string
+	name    string
+	schemes []string
+}
+
+// NewPlugin runs the executable at path with `describe`.
+func NewPlugin(ctx context.Context, path string) (*Plugin, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultPluginDescribeTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path, "describe")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, pluginError(path, "describe", err, stderr.String())
+	}
+	var desc PluginDescription
+	if err := json.Unmarshal(stdout.Bytes(), &desc); err != nil {
+		return nil, fmt.Errorf("parsing describe output: %w", err)
+	}
+	if len(desc.Schemes) == 0 {
+		return nil, fmt.Errorf("plugin reports no schemes")
+	}
+	if desc.Name == "" {
+		desc.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return &Plugin{path: path, name: desc.Name, schemes: desc.Schemes}, nil
+}
+
+// Name returns the plugin name.
+func (p *Plugin) Name() string {
+	return p.name
+}
+
+// Schemes returns the URL schemes the plugin enumerates.
+func (p *Plugin) Schemes() []string {
+	return p.schemes
+}
+
+// Enumerator returns an enumerator that runs the plugin on target. It is a
+// Factory.
+func (p *Plugin) Enumerator(target string, config Config) (Enumerator, error) {
+	return &pluginEnumerator{plugin: p, target: target, config: config, fs: NewFilesystemEnumerator(config)}, nil
+}
+
+// pluginEnumerator yields the blobs a plugin prints for a target.
+type pluginEnumerator struct {
+	plugin *Plugin
+	target string
+	config Config
+	fs     *FilesystemEnumerator // for extraction of the plugin's blobs
+}
+
+// Enumerate runs the plugin and yields each blob as it's printed. Blobs
+// over MaxFileSize are skipped, and archives and documents are extracted
+// as for files.
+func (e *pluginEnumerator) Enumerate(ctx context.Context, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, e.plugin.path, "enumerate", e.target)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return pluginError(e.plugin.path, "enumerate", err, "")
+	}
+
+	readErr := e.read(stdout, callback)
+	if readErr != nil {
+		// Stop the plugin rather than wait for it to finish
+		cancel()
+	}
+	waitErr := cmd.Wait()
+	if readErr != nil {
+		return readErr
+	}
+	if waitErr != nil {
+		return pluginError(e.plugin.path, "enumerate", waitErr, stderr.String())
+	}
+	return nil
+}
+
+// read decodes the plugin's output, passing each blob to callback.
+func (e *pluginEnumerator) read(stdout io.Reader, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	dec := json.NewDecoder(stdout)
+	for {
+		var item PluginItem
+		err := dec.Decode(&item)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("parsing output of plugin %s: %w", e.plugin.name, err)
+		}
+
+		content := []byte(item.Content)
+		if item.ContentBase64 != "" {
+			if content, err = base64.StdEncoding.DecodeString(item.ContentBase64); err != nil {
+				return fmt.Errorf("plugin %s: invalid content_base64 for %s: %w", e.plugin.name, item.Path, err)
+			}
+		}
+		path := item.Path
+		if path == "" {
+			path = e.target
+		}
+		if e.config.MaxFileSize > 0 && int64(len(content)) > e.config.MaxFileSize {
+			e.config.skip(path, "too large")
+			continue
+		}
+		if len(content) == 0 {
+			continue
+		}
+		if err := e.fs.processContent(path, content, callback); err != nil {
+			return err
+		}
+	}
+}
+
+// pluginError describes a failed plugin run, with its stderr if any.
+func pluginError(path, command string, err error, stderr string) error {
+	if msg := strings.TrimSpace(stderr); msg != "" {
+		return fmt.Errorf("running %s %s: %w: %s", filepath.Base(path), command, err, msg)
+	}
+	return fmt.Errorf("running %s %s: %w", filepath.Base(path), command, err)
+}
+
+// DefaultPluginDir returns the directory enumerator plugins are loaded from
+// when none is given: titus/enumerators under the user's config directory.
+func DefaultPluginDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "titus", "enumerators")
+}
+
+// LoadPlugins describes every executable in dir, in name order. Hidden
+// files and non-executables are skipped. A missing dir yields no plugins; a
+// plugin that fails to describe itself is an error.
+func LoadPlugins(ctx context.Context, dir string) ([]*Plugin, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading plugin directory: %w", err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var plugins []*Plugin
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !isExecutable(entry.Name(), info.Mode()) {
+			continue
+		}
+		p, err := NewPlugin(ctx, filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("loading plugin %s: %w", entry.Name(), err)
+		}
+		plugins = append(plugins, p)
+	}
+	return plugins, nil
+}
+
+// isExecutable reports whether a file can be run as a plugin.
+func isExecutable(name string, mode os.FileMode) bool {
+	if !mode.IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".exe", ".bat", ".cmd":
+			return true
+		}
+		return false
+	}
+	return mode.Perm()&0o111 != 0
+}
@@ -0,0 +1,103 @@
+package enum
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// artifactsPlugin enumerates two blobs for artifacts:// targets, one of
+// them base64-encoded, and fails for artifacts://broken.
+const artifactsPlugin = `#!/bin/sh
+case "$1" in
+describe)
+  echo '{"name": "artifacts", "schemes": ["artifacts"]}'
+  ;;
+enumerate)
+  if [ "$2" = "artifacts://broken" ]; then
+    echo "store unreachable" >&2
+    exit 3
+  fi
+  printf '%s\n' '{"path": "'"$2"'/app.env", "content": "TOKEN=abc123\n"}'
+  printf '%s\n' '{"path": "'"$2"'/notes.txt", "content_base64": "aGVsbG8gd29ybGQ="}'
+  printf '%s\n' '{"path": "'"$2"'/big.bin", "content": "0123456789012345678901234567890123456789"}'
+  ;;
+esac
+`
+
+func writeEnumeratorPlugin(t *testing.T, dir, name, script string, mode os.FileMode) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin tests use shell scripts")
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(script), mode))
+}
+
+func TestLoadPlugins(t *testing.T) {
+	dir := t.TempDir()
+	writeEnumeratorPlugin(t, dir, "artifacts", artifactsPlugin, 0o755)
+	writeEnumeratorPlugin(t, dir, "README.md", "not a plugin", 0o644)
+	writeEnumeratorPlugin(t, dir, ".hidden", artifactsPlugin, 0o755)
+
+	plugins, err := LoadPlugins(context.Background(), dir)
+	require.NoError(t, err)
+	require.Len(t, plugins, 1)
+	assert.Equal(t, "artifacts", plugins[0].Name())
+	assert.Equal(t, []string{"artifacts"}, plugins[0].Schemes())
+
+	plugins, err = LoadPlugins(context.Background(), filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, plugins)
+}
+
+func TestPluginEnumerator(t *testing.T) {
+	dir := t.TempDir()
+	writeEnumeratorPlugin(t, dir, "artifacts", artifactsPlugin, 0o755)
+	p, err := NewPlugin(context.Background(), filepath.Join(dir, "artifacts"))
+	require.NoError(t, err)
+
+	var skipped []string
+	e, err := p.Enumerator("artifacts://store/42", Config{MaxFileSize: 32, OnSkip: func(path, reason string) {
+		skipped = append(skipped, path+": "+reason)
+	}})
+	require.NoError(t, err)
+
+	blobs := make(map[string]string)
+	err = e.Enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		assert.Equal(t, types.ComputeBlobID(content), blobID)
+		blobs[prov.Path()] = string(content)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"artifacts://store/42/app.env":   "TOKEN=abc123\n",
+		"artifacts://store/42/notes.txt": "hello world",
+	}, blobs)
+	assert.Equal(t, []string{"artifacts://store/42/big.bin: too large"}, skipped)
+}
+
+func TestPluginEnumerator_Failure(t *testing.T) {
+	dir := t.TempDir()
+	writeEnumeratorPlugin(t, dir, "artifacts", artifactsPlugin, 0o755)
+	p, err := NewPlugin(context.Background(), filepath.Join(dir, "artifacts"))
+	require.NoError(t, err)
+
+	e, err := p.Enumerator("artifacts://broken", Config{})
+	require.NoError(t, err)
+	err = e.Enumerate(context.Background(), func([]byte, types.BlobID, types.Provenance) error { return nil })
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "store unreachable")
+}
+
+func TestNewPlugin_NoSchemes(t *testing.T) {
+	dir := t.TempDir()
+	writeEnumeratorPlugin(t, dir, "empty", "#!/bin/sh\necho '{\"name\": \"empty\"}'\n", 0o755)
+	_, err := NewPlugin(context.Background(), filepath.Join(dir, "empty"))
+	assert.ErrorContains(t, err, "no schemes")
+}
@@ -0,0 +1,171 @@
+package enum
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Protocol buffers and FlatBuffers are read without their schema: the
+// strings are found by the shape of the wire format alone.
+const (
+	// minSerializedString is the length from which strings are extracted,
+	// leaving out enum-like names and short identifiers.
+	minSerializedString = 4
+
+	// maxProtobufDepth bounds the nesting of messages decoded.
+	maxProtobufDepth = 32
+)
+
+var errNotProtobuf = errors.New("not a protobuf message")
+
+// protobufField is a field of a protobuf message read without its schema.
+type protobufField struct {
+	number   uint64
+	wireType uint64
+	data     []byte // the value of a length-delimited field
+}
+
+// parseProtobuf reads b as a protobuf message, or returns false if it isn't
+// one: every field must have a valid number and wire type, and the last
+// must end with b. Groups, deprecated in proto3, aren't supported.
+func parseProtobuf(b []byte) ([]protobufField, bool) {
+	var fields []protobufField
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, false
+		}
+		b = b[n:]
+		f := protobufField{number: tag >> 3, wireType: tag & 7}
+		if f.number == 0 || f.number > 1<<29-1 {
+			return nil, false
+		}
+		switch f.wireType {
+		case 0: // varint
+			if _, n = binary.Uvarint(b); n <= 0 {
+				return nil, false
+			}
+			b = b[n:]
+		case 1: // 64-bit
+			if len(b) < 8 {
+				return nil, false
+			}
+			b = b[8:]
+		case 2: // length-delimited: string, bytes, message or packed repeated
+			size, n := binary.Uvarint(b)
+			if n <= 0 || size > uint64(len(b)-n) {
+				return nil, false
+			}
+			f.data = b[n : n+int(size)]
+			b = b[n+int(size):]
+		case 5: // 32-bit
+			if len(b) < 4 {
+				return nil, false
+			}
+			b = b[4:]
+		default:
+			return nil, false
+		}
+		fields = append(fields, f)
+	}
+	return fields, len(fields) > 0
+}
+
+// isSerializedText reports whether b is printable UTF-8 text.
+func isSerializedText(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && r != '\t' && r != '\n' && r != '\r' {
+			return false
+		}
+	}
+	return true
+}
+
+// protobufStrings appends the strings of the message fields to out, one
+// per line, each after its field path, e.g. "2.1: value". A length-delimited
+// field is taken for a string if it's printable text, and otherwise for a
+// nested message if it parses as one.
+func protobufStrings(fields []protobufField, path string, depth int, out *strings.Builder) {
+	for _, f := range fields {
+		if f.wireType != 2 {
+			continue
+		}
+		p := fmt.Sprintf("%s%d", path, f.number)
+		if isSerializedText(f.data) {
+			if len(f.data) >= minSerializedString {
+				fmt.Fprintf(out, "%s: %s\n", p, f.data)
+			}
+			continue
+		}
+		if depth < maxProtobufDepth {
+			if nested, ok := parseProtobuf(f.data); ok {
+				protobufStrings(nested, p+".", depth+1, out)
+			}
+		}
+	}
+}
+
+// extractProtobuf extracts the strings of a serialized protobuf message
+// (.pb, .binpb, .protobuf), such as a config blob or a cached RPC payload,
+// without its schema. A stream of varint length-delimited messages, as
+// written by writeDelimitedTo, is read too, its messages numbered from 0
+// as in "[3].2.1: value".
+func extractProtobuf(content []byte) ([]ExtractedContent, error) {
+	var out strings.Builder
+	if fields, ok := parseProtobuf(content); ok {
+		protobufStrings(fields, "", 0, &out)
+	} else {
+		var messages [][]protobufField
+		for b := content; len(b) > 0; {
+			size, n := binary.Uvarint(b)
+			if n <= 0 || size > uint64(len(b)-n) {
+				return nil, errNotProtobuf
+			}
+			fields, ok := parseProtobuf(b[n : n+int(size)])
+			if !ok && size > 0 {
+				return nil, errNotProtobuf
+			}
+			messages = append(messages, fields)
+			b = b[n+int(size):]
+		}
+		for i, fields := range messages {
+			protobufStrings(fields, fmt.Sprintf("[%d].", i), 0, &out)
+		}
+	}
+
+	if out.Len() == 0 {
+		return nil, nil
+	}
+	return []ExtractedContent{{Name: "strings", Content: []byte(out.String())}}, nil
+}
+
+// extractFlatBuffer extracts the strings of a FlatBuffer (.fb) without its
+// schema. FlatBuffers store a string as its 32-bit little-endian length, 4
+// byte aligned, then its bytes and a NUL, so each such run of printable
+// text is taken for a string, after its offset, e.g. "@0x1c: value".
+func extractFlatBuffer(content []byte) ([]ExtractedContent, error) {
+	var out strings.Builder
+	for off := 0; off+4 <= len(content); {
+		size := int(binary.LittleEndian.Uint32(content[off:]))
+		end := off + 4 + size
+		if size >= minSerializedString && end < len(content) &&
+			content[end] == 0 && isSerializedText(content[off+4:end]) {
+			fmt.Fprintf(&out, "@%#x: %s\n", off, content[off+4:end])
+			off = (end + 4) &^ 3 // past the NUL, aligned
+			continue
+		}
+		off += 4
+	}
+
+	if out.Len() == 0 {
+		return nil, nil
+	}
+	return []ExtractedContent{{Name: "strings", Content: []byte(out.String())}}, nil
+}
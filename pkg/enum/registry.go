@@ -0,0 +1,98 @@
+package enum
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Factory creates the enumerator of a target URL. config carries the scan's
+// size and extraction settings; its Root is the target.
+type Factory func(target string, config Config) (Enumerator, error)
+
+// builtinSchemes are the target URL schemes Titus handles itself, which
+// can't be registered.
+var builtinSchemes = map[string]bool{
+	"http": true, "https": true, "ssh": true, "git+ssh": true, "file": true,
+	"smb": true, "imap": true, "imaps": true,
+}
+
+// schemePattern matches a URL scheme per RFC 3986.
+var schemePattern = regexp.MustCompile(`^[a-z][a-z0-9+.-]*$`)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register makes targets of the form scheme://... enumerable by the
+// enumerators factory creates, so that scans can read data sources Titus
+// doesn't support itself. Schemes are case-insensitive. Registering a
+// built-in scheme, or one that's already registered, is an error.
+func Register(scheme string, factory Factory) error {
+	scheme = strings.ToLower(scheme)
+	if !schemePattern.MatchString(scheme) {
+		return fmt.Errorf("invalid scheme %q", scheme)
+	}
+	if builtinSchemes[scheme] {
+		return fmt.Errorf("scheme %q is built in", scheme)
+	}
+	if factory == nil {
+		return fmt.Errorf("nil factory for scheme %q", scheme)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[scheme]; ok {
+		return fmt.Errorf("scheme %q is already registered", scheme)
+	}
+	registry[scheme] = factory
+	return nil
+}
+
+// Lookup returns the factory registered for target's scheme, if any.
+func Lookup(target string) (Factory, bool) {
+	scheme := TargetScheme(target)
+	if scheme == "" {
+		return nil, false
+	}
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	factory, ok := registry[scheme]
+	return factory, ok
+}
+
+// RegisteredSchemes returns the registered schemes, sorted.
+func RegisteredSchemes() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	schemes := make([]string, 0, len(registry))
+	for scheme := range registry {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// TargetScheme returns the lowercased scheme of a scheme://... target, or
+// "" if target isn't a URL of that form.
+func TargetScheme(target string) string {
+	scheme, _, ok := strings.Cut(target, "://")
+	if !ok {
+		return ""
+	}
+	scheme = strings.ToLower(scheme)
+	if !schemePattern.MatchString(scheme) {
+		return ""
+	}
+	return scheme
+}
+
+// IsCustomTarget reports whether target is a URL whose scheme isn't built
+// in, which only a registered enumerator can read.
+func IsCustomTarget(target string) bool {
+	scheme := TargetScheme(target)
+	return scheme != "" && !builtinSchemes[scheme]
+}
@@ -0,0 +1,66 @@
+package enum
+
+import (
+	"context"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticEnumerator yields fixed blobs.
+type staticEnumerator map[string]string
+
+func (s staticEnumerator) Enumerate(ctx context.Context, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	for path, content := range s {
+		if err := callback([]byte(content), types.ComputeBlobID([]byte(content)), types.FileProvenance{FilePath: path}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestRegister(t *testing.T) {
+	var gotTarget string
+	var gotConfig Config
+	require.NoError(t, Register("TicketsTest", func(target string, config Config) (Enumerator, error) {
+		gotTarget, gotConfig = target, config
+		return staticEnumerator{"ticket/1": "body"}, nil
+	}))
+
+	factory, ok := Lookup("ticketstest://jira.corp/PROJ")
+	require.True(t, ok, "schemes are case-insensitive")
+	e, err := factory("ticketstest://jira.corp/PROJ", Config{MaxFileSize: 42})
+	require.NoError(t, err)
+	assert.Equal(t, "ticketstest://jira.corp/PROJ", gotTarget)
+	assert.Equal(t, int64(42), gotConfig.MaxFileSize)
+	assert.IsType(t, staticEnumerator{}, e)
+	assert.Contains(t, RegisteredSchemes(), "ticketstest")
+
+	assert.Error(t, Register("ticketstest", factory), "already registered")
+	assert.Error(t, Register("smb", factory), "built in")
+	assert.Error(t, Register("https", factory), "built in")
+	assert.Error(t, Register("not a scheme", factory))
+	assert.Error(t, Register("nilfactory", nil))
+}
+
+func TestLookup_Unregistered(t *testing.T) {
+	_, ok := Lookup("unregistered-test://host/path")
+	assert.False(t, ok)
+	_, ok = Lookup("/tmp/repo")
+	assert.False(t, ok)
+}
+
+func TestTargetScheme(t *testing.T) {
+	assert.Equal(t, "artifacts", TargetScheme("Artifacts://store/builds/42"))
+	assert.Equal(t, "git+ssh", TargetScheme("git+ssh://host/repo.git"))
+	assert.Equal(t, "", TargetScheme("./repo"))
+	assert.Equal(t, "", TargetScheme(`C:\src\repo`))
+	assert.Equal(t, "", TargetScheme("dir with space://x"))
+
+	assert.True(t, IsCustomTarget("artifacts://store/builds/42"))
+	assert.False(t, IsCustomTarget("smb://fs01/Data"))
+	assert.False(t, IsCustomTarget("https://github.com/org/repo"))
+	assert.False(t, IsCustomTarget("./repo"))
+}
@@ -0,0 +1,54 @@
+package enum
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FindRepositories returns root, if it is a git repository, and every git
+// repository nested below it: working trees of other repositories checked
+// out inside it and submodules, whose .git is a file pointing into the
+// superproject. Each repository's history is separate from its parent's, so
+// walking the parent alone misses it. Directories named .git are not
+// descended into, and neither are symlinks. A bare repository has no working
+// tree to nest others in, so root alone is returned.
+func FindRepositories(root string) ([]string, error) {
+	if isBareRepository(root) {
+		return []string{root}, nil
+	}
+	var repos []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if _, err := os.Lstat(filepath.Join(path, ".git")); err == nil {
+			repos = append(repos, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+// isBareRepository reports whether dir looks like a bare repository, such
+// as one cloned with --bare.
+func isBareRepository(dir string) bool {
+	for _, name := range []string{"HEAD", "objects", "refs"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
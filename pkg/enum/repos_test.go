@@ -0,0 +1,97 @@
+package enum
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gitCommitFile commits a file to the repository at dir, creating it first
+// if needed.
+func gitCommitFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		initGitRepo(t, dir)
+	}
+	writeFile(t, filepath.Join(dir, name), content)
+	gitAddCommit(t, dir, "add "+name)
+}
+
+// allowFileSubmodules lets git clone submodules from local paths, which it
+// refuses by default.
+func allowFileSubmodules(t *testing.T) {
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+}
+
+func TestFindRepositories(t *testing.T) {
+	root := t.TempDir()
+	gitCommitFile(t, root, "top.txt", "top")
+	gitCommitFile(t, filepath.Join(root, "vendor", "lib"), "lib.txt", "lib")
+	gitCommitFile(t, filepath.Join(root, "tools", "a", "b"), "b.txt", "b")
+
+	sub := filepath.Join(t.TempDir(), "sub")
+	gitCommitFile(t, sub, "sub.txt", "sub")
+	allowFileSubmodules(t)
+	runGit(t, root, "submodule", "--quiet", "add", sub, "deps/sub")
+
+	repos, err := FindRepositories(root)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		root,
+		filepath.Join(root, "deps", "sub"),
+		filepath.Join(root, "tools", "a", "b"),
+		filepath.Join(root, "vendor", "lib"),
+	}, repos)
+
+	plain := t.TempDir()
+	repos, err = FindRepositories(plain)
+	require.NoError(t, err)
+	assert.Empty(t, repos)
+
+	bare := filepath.Join(t.TempDir(), "bare.git")
+	runGit(t, root, "clone", "--quiet", "--bare", root, bare)
+	repos, err = FindRepositories(bare)
+	require.NoError(t, err)
+	assert.Equal(t, []string{bare}, repos)
+}
+
+func TestCloneEnumerator_Submodules(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	gitCommitFile(t, sub, "old.txt", "only in the submodule's history")
+	runGit(t, sub, "rm", "--quiet", "old.txt")
+	runGit(t, sub, "commit", "--quiet", "-m", "remove old.txt")
+
+	top := filepath.Join(dir, "top")
+	gitCommitFile(t, top, "top.txt", "top")
+	allowFileSubmodules(t)
+	runGit(t, top, "submodule", "--quiet", "add", sub, "deps/sub")
+	runGit(t, top, "commit", "--quiet", "-m", "add submodule")
+
+	scan := func(submodules bool) map[string]string {
+		e := NewCloneEnumerator([]RepoInfo{{Name: "test/repo", CloneURL: "file://" + top}}, Config{})
+		e.Git = true
+		e.Submodules = submodules
+		blobs := map[string]string{}
+		err := e.Enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+			gp := prov.(types.GitProvenance)
+			blobs[string(content)] = gp.RepoPath
+			return nil
+		})
+		require.NoError(t, err)
+		return blobs
+	}
+
+	assert.NotContains(t, scan(false), "only in the submodule's history")
+	blobs := scan(true)
+	assert.Equal(t, "test/repo/deps/sub", blobs["only in the submodule's history"])
+	assert.Equal(t, "test/repo", blobs["top"])
+}
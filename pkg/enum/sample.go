@@ -0,0 +1,257 @@
+package enum
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/enum/ignore"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// SampleConfig controls how a SamplingEnumerator divides its budget.
+type SampleConfig struct {
+	// FullSize is the largest file scanned in full. Larger files are sampled,
+	// except likely credential files, which are scanned in full up to
+	// Config.MaxFileSize.
+	FullSize int64
+
+	// Chunks is the number of chunks sampled from each larger file.
+	Chunks int
+
+	// ChunkSize is the size of each sampled chunk in bytes.
+	ChunkSize int
+
+	// MaxBytes stops the scan once this many bytes have been read (0 = no limit).
+	MaxBytes int64
+
+	// MaxDuration stops the scan once it has run this long (0 = no limit).
+	MaxDuration time.Duration
+
+	// Seed seeds the choice of chunk offsets, for reproducible samples.
+	Seed int64
+}
+
+// DefaultSampleConfig returns the default sampling strategy.
+func DefaultSampleConfig() SampleConfig {
+	return SampleConfig{
+		FullSize:  1024 * 1024,
+		Chunks:    8,
+		ChunkSize: 64 * 1024,
+		Seed:      1,
+	}
+}
+
+// SampleCoverage reports how much of the target a sampling scan read.
+type SampleCoverage struct {
+	Files        int   // eligible files found
+	FullFiles    int   // files scanned in full
+	SampledFiles int   // files of which chunks were scanned
+	SkippedFiles int   // files not read before the budget ran out
+	TotalBytes   int64 // size of all eligible files
+	ScannedBytes int64 // bytes read
+}
+
+// sampleEntry is a file found during the walk phase.
+type sampleEntry struct {
+	path     string
+	size     int64
+	priority int
+}
+
+// Sampling priorities, lowest first.
+const (
+	samplePriorityCredential = iota
+	samplePriorityConfig
+	samplePriorityOther
+)
+
+// sampleCredentialName matches file names that usually hold credentials.
+var sampleCredentialName = regexp.MustCompile(`(?i)^(?:\.env(?:\..*)?|.*\.(?:env|pem|key|p12|pfx|jks|keystore|kdbx|ovpn|tfstate|tfvars|rdp|pgpass)|id_(?:rsa|dsa|ecdsa|ed25519)|\.?(?:netrc|npmrc|pypirc|git-credentials|pgpass|htpasswd|s3cfg|boto|dockercfg)|credentials(?:\..*)?|.*secrets?(?:\..*)?|.*passw(?:or)?d.*|web\.config|wp-config\.php|appsettings(?:\..*)?\.json|application(?:-.*)?\.(?:properties|ya?ml)|settings\.py|local_settings\.py|kubeconfig|unattend\.xml|sysprep\.inf|groups\.xml)$`)
+
+// sampleConfigExt lists extensions of configuration and script files.
+var sampleConfigExt = map[string]bool{
+	".cfg": true, ".conf": true, ".config": true, ".ini": true, ".json": true,
+	".properties": true, ".toml": true, ".xml": true, ".yaml": true, ".yml": true,
+	".bat": true, ".cmd": true, ".ps1": true, ".sh": true, ".sql": true,
+}
+
+// samplePriority ranks a path: likely credential files first, then
+// configuration and scripts, then everything else.
+func samplePriority(path string) int {
+	base := filepath.Base(path)
+	switch {
+	case sampleCredentialName.MatchString(base):
+		return samplePriorityCredential
+	case sampleConfigExt[strings.ToLower(filepath.Ext(base))]:
+		return samplePriorityConfig
+	}
+	return samplePriorityOther
+}
+
+// SamplingEnumerator enumerates a filesystem tree too large to scan in full.
+// The tree is walked first so that files can be read in priority order:
+// likely credential files, then configuration and scripts, then everything
+// else, smallest first within each group. Files up to SampleConfig.FullSize
+// are scanned in full, as the filesystem enumerator would; larger files are
+// sampled as a handful of chunks spread across the file, each yielded with
+// ArchiveProvenance naming its byte range. Reading stops when the byte or
+// time budget runs out, and Coverage reports what was read.
+type SamplingEnumerator struct {
+	config   Config
+	sample   SampleConfig
+	fs       *FilesystemEnumerator
+	coverage SampleCoverage
+}
+
+// NewSamplingEnumerator creates a new sampling enumerator. Config.MaxFileSize
+// limits files scanned in full, not the files that are sampled.
+func NewSamplingEnumerator(config Config, sample SampleConfig) *SamplingEnumerator {
+	return &SamplingEnumerator{
+		config: config,
+		sample: sample,
+		fs:     NewFilesystemEnumerator(config),
+	}
+}
+
+// Coverage returns the coverage of the last Enumerate call.
+func (e *SamplingEnumerator) Coverage() SampleCoverage {
+	return e.coverage
+}
+
+// Enumerate walks the tree, then reads files in priority order until done or
+// out of budget.
+func (e *SamplingEnumerator) Enumerate(ctx context.Context, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	start := time.Now()
+	e.coverage = SampleCoverage{}
+
+	files, err := e.walk(ctx)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].priority != files[j].priority {
+			return files[i].priority < files[j].priority
+		}
+		return files[i].size < files[j].size
+	})
+	for _, f := range files {
+		e.coverage.TotalBytes += f.size
+	}
+	e.coverage.Files = len(files)
+
+	rng := rand.New(rand.NewSource(e.sample.Seed))
+	for i, f := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if (e.sample.MaxBytes > 0 && e.coverage.ScannedBytes >= e.sample.MaxBytes) ||
+			(e.sample.MaxDuration > 0 && time.Since(start) >= e.sample.MaxDuration) {
+			e.coverage.SkippedFiles = len(files) - i
+			return nil
+		}
+
+		if e.scanFully(f) {
+			e.coverage.FullFiles++
+			e.coverage.ScannedBytes += f.size
+			if err := e.fs.processFile(ctx, f.path, callback); err != nil {
+				return err
+			}
+			continue
+		}
+
+		e.coverage.SampledFiles++
+		if err := e.sampleFile(f, rng, callback); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *SamplingEnumerator) scanFully(f sampleEntry) bool {
+	if f.size <= e.sample.FullSize {
+		return true
+	}
+	return f.priority == samplePriorityCredential &&
+		(e.config.MaxFileSize <= 0 || f.size <= e.config.MaxFileSize)
+}
+
+// walk collects the eligible files under Root. Unlike the filesystem
+// enumerator, large files are kept so they can be sampled.
+func (e *SamplingEnumerator) walk(ctx context.Context) ([]sampleEntry, error) {
+	ig, err := ignore.CompilePatterns(e.config.IgnoreFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []sampleEntry
+	err = filepath.Walk(e.config.Root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if info.IsDir() || !info.Mode().IsRegular() || info.Size() == 0 {
+			return nil
+		}
+		if ig != nil {
+			relPath, err := filepath.Rel(e.config.Root, path)
+			if err != nil {
+				return err
+			}
+			if ig.MatchesPath(relPath) {
+				return nil
+			}
+		}
+		files = append(files, sampleEntry{path: path, size: info.Size(), priority: samplePriority(path)})
+		return nil
+	})
+	return files, err
+}
+
+// sampleFile yields chunks of a large file: its first chunk, since headers
+// and leading settings are the most likely to hold secrets, and one chunk at
+// a random offset within each remaining equal slice of the file.
+func (e *SamplingEnumerator) sampleFile(f sampleEntry, rng *rand.Rand, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	file, err := os.Open(f.path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		return nil
+	}
+	defer file.Close()
+
+	chunkSize := int64(e.sample.ChunkSize)
+	offsets := []int64{0}
+	if n := int64(e.sample.Chunks) - 1; n > 0 {
+		slice := (f.size - chunkSize) / n
+		for i := int64(0); i < n && slice > 0; i++ {
+			offsets = append(offsets, chunkSize+i*slice+rng.Int63n(slice))
+		}
+	}
+
+	buf := make([]byte, chunkSize)
+	for _, offset := range offsets {
+		n, _ := file.ReadAt(buf, offset)
+		if n == 0 {
+			continue
+		}
+		e.coverage.ScannedBytes += int64(n)
+		content := append([]byte(nil), buf[:n]...)
+		if isBinary(content) {
+			continue
+		}
+		prov := types.ArchiveProvenance{ArchivePath: f.path, MemberPath: byteRange(offset, offset+int64(n))}
+		if err := callback(content, types.ComputeBlobID(content), prov); err != nil {
+			return err
+		}
+	}
+	return nil
+}
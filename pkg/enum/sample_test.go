@@ -0,0 +1,85 @@
+package enum
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func enumerateSample(t *testing.T, e *SamplingEnumerator) []types.Provenance {
+	t.Helper()
+	var provs []types.Provenance
+	err := e.Enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		provs = append(provs, prov)
+		return nil
+	})
+	require.NoError(t, err)
+	return provs
+}
+
+func TestSamplingEnumerator_PriorityAndChunks(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name string, content []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, content, 0644))
+		return path
+	}
+	notes := writeFile("notes.txt", []byte("hello\n"))
+	settings := writeFile("settings.yml", []byte("debug: true\n"))
+	env := writeFile(".env", bytes.Repeat([]byte("A=1\n"), 100))
+	big := writeFile("app.log", bytes.Repeat([]byte("line of log output\n"), 1000))
+
+	e := NewSamplingEnumerator(Config{Root: dir, IgnoreFile: os.DevNull}, SampleConfig{
+		FullSize: 64, Chunks: 3, ChunkSize: 100, Seed: 1,
+	})
+	provs := enumerateSample(t, e)
+
+	// Credential files come first and are read in full despite their size,
+	// then config, then the rest smallest first.
+	require.Len(t, provs, 6)
+	assert.Equal(t, types.FileProvenance{FilePath: env}, provs[0])
+	assert.Equal(t, types.FileProvenance{FilePath: settings}, provs[1])
+	assert.Equal(t, types.FileProvenance{FilePath: notes}, provs[2])
+	assert.Equal(t, types.ArchiveProvenance{ArchivePath: big, MemberPath: "0x0-0x64"}, provs[3])
+	for _, prov := range provs[4:] {
+		assert.Equal(t, big, prov.(types.ArchiveProvenance).ArchivePath)
+	}
+
+	assert.Equal(t, SampleCoverage{
+		Files:        4,
+		FullFiles:    3,
+		SampledFiles: 1,
+		TotalBytes:   6 + 12 + 400 + 19000,
+		ScannedBytes: 6 + 12 + 400 + 300,
+	}, e.Coverage())
+}
+
+func TestSamplingEnumerator_Budget(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), bytes.Repeat([]byte("x"), 50), 0644))
+	}
+
+	sample := DefaultSampleConfig()
+	sample.MaxBytes = 60
+	e := NewSamplingEnumerator(Config{Root: dir, IgnoreFile: os.DevNull}, sample)
+	assert.Len(t, enumerateSample(t, e), 2)
+	assert.Equal(t, 1, e.Coverage().SkippedFiles)
+	assert.Equal(t, int64(100), e.Coverage().ScannedBytes)
+}
+
+func TestSamplePriority(t *testing.T) {
+	assert.Equal(t, samplePriorityCredential, samplePriority("/share/app/.env.production"))
+	assert.Equal(t, samplePriorityCredential, samplePriority("/home/u/.ssh/id_rsa"))
+	assert.Equal(t, samplePriorityCredential, samplePriority("web.config"))
+	assert.Equal(t, samplePriorityCredential, samplePriority("db_passwords.xlsx"))
+	assert.Equal(t, samplePriorityConfig, samplePriority("deploy.ps1"))
+	assert.Equal(t, samplePriorityConfig, samplePriority("docker-compose.yml"))
+	assert.Equal(t, samplePriorityOther, samplePriority("README.md"))
+}
@@ -0,0 +1,214 @@
+package enum
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// DefaultGraphURL is the Microsoft Graph API endpoint used for SharePoint.
+const DefaultGraphURL = "https://graph.microsoft.com/v1.0"
+
+// SharePointConfig for SharePoint enumeration via Microsoft Graph.
+type SharePointConfig struct {
+	Token   string   // Graph access token with Sites.Read.All (or Files.Read.All)
+	BaseURL string   // Graph API endpoint (default DefaultGraphURL)
+	Sites   []string // site URLs (https://contoso.sharepoint.com/sites/Finance) or Graph site IDs; empty = every site found by search
+	Client  *http.Client
+	Config  // Embedded base Config (MaxFileSize, ExtractArchives, ExtractLimits)
+}
+
+// SharePointEnumerator enumerates the documents in SharePoint sites' document
+// libraries via Microsoft Graph. Documents are yielded with FileProvenance
+// whose path is the document's location: its library's URL followed by its
+// folder path and name.
+type SharePointEnumerator struct {
+	config SharePointConfig
+	base   string
+	api    *apiClient
+	fs     *FilesystemEnumerator
+}
+
+// NewSharePointEnumerator creates a new SharePoint enumerator.
+func NewSharePointEnumerator(cfg SharePointConfig) (*SharePointEnumerator, error) {
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("must specify a Microsoft Graph access token")
+	}
+	base := cfg.BaseURL
+	if base == "" {
+		base = DefaultGraphURL
+	}
+	if _, err := ValidateBaseURL(base); err != nil {
+		return nil, fmt.Errorf("Graph URL: %w", err)
+	}
+
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+	api := &apiClient{client: client, auth: func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+cfg.Token)
+	}}
+
+	return &SharePointEnumerator{
+		config: cfg,
+		base:   strings.TrimRight(base, "/"),
+		api:    api,
+		fs:     NewFilesystemEnumerator(cfg.Config),
+	}, nil
+}
+
+type graphSite struct {
+	ID     string `json:"id"`
+	WebURL string `json:"webUrl"`
+}
+
+type graphDrive struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	WebURL string `json:"webUrl"`
+}
+
+type graphItem struct {
+	ID     string    `json:"id"`
+	Name   string    `json:"name"`
+	Size   int64     `json:"size"`
+	File   *struct{} `json:"file"`
+	Folder *struct{} `json:"folder"`
+}
+
+// graphList is a page of a Graph collection.
+type graphList[T any] struct {
+	Value    []T    `json:"value"`
+	NextLink string `json:"@odata.nextLink"`
+}
+
+// graphListAll fetches every page of a Graph collection.
+func graphListAll[T any](ctx context.Context, api *apiClient, next string) ([]T, error) {
+	var all []T
+	for next != "" {
+		var page graphList[T]
+		if err := api.getJSON(ctx, next, &page); err != nil {
+			return nil, err
+		}
+		all = append(all, page.Value...)
+		next = page.NextLink
+	}
+	return all, nil
+}
+
+// Enumerate walks the document libraries of every configured site.
+func (e *SharePointEnumerator) Enumerate(ctx context.Context, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	sites, err := e.listSites(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, site := range sites {
+		drives, err := graphListAll[graphDrive](ctx, e.api, e.base+"/sites/"+site.ID+"/drives")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: listing document libraries of %s: %v\n", site.WebURL, err)
+			continue
+		}
+		for _, drive := range drives {
+			if err := e.walkFolder(ctx, drive, e.base+"/drives/"+url.PathEscape(drive.ID)+"/root/children", "", callback); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// listSites resolves the configured sites, or searches for every site the
+// token can read.
+func (e *SharePointEnumerator) listSites(ctx context.Context) ([]graphSite, error) {
+	if len(e.config.Sites) == 0 {
+		sites, err := graphListAll[graphSite](ctx, e.api, e.base+"/sites?search=*")
+		if err != nil {
+			return nil, fmt.Errorf("searching sites: %w", err)
+		}
+		return sites, nil
+	}
+
+	var sites []graphSite
+	for _, s := range e.config.Sites {
+		var site graphSite
+		if err := e.api.getJSON(ctx, e.base+"/sites/"+graphSiteRef(s), &site); err != nil {
+			return nil, fmt.Errorf("resolving site %s: %w", s, err)
+		}
+		sites = append(sites, site)
+	}
+	return sites, nil
+}
+
+// graphSiteRef converts a site URL to the hostname:/server-relative-path form
+// Graph accepts in place of a site ID. Other values are used as IDs.
+func graphSiteRef(site string) string {
+	u, err := url.Parse(site)
+	if err != nil || u.Host == "" {
+		return site
+	}
+	if p := strings.TrimRight(u.EscapedPath(), "/"); p != "" {
+		return u.Host + ":" + p
+	}
+	return u.Host
+}
+
+// walkFolder yields every document under a folder, whose children are listed
+// at next. Unreadable folders and documents are reported and skipped.
+func (e *SharePointEnumerator) walkFolder(ctx context.Context, drive graphDrive, next, dir string, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	items, err := graphListAll[graphItem](ctx, e.api, next)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: listing %s: %v\n", e.location(drive, dir), err)
+		return nil
+	}
+	for _, item := range items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		itemPath := strings.TrimPrefix(dir+"/"+item.Name, "/")
+		itemURL := e.base + "/drives/" + url.PathEscape(drive.ID) + "/items/" + url.PathEscape(item.ID)
+
+		if item.Folder != nil {
+			if err := e.walkFolder(ctx, drive, itemURL+"/children", itemPath, callback); err != nil {
+				return err
+			}
+			continue
+		}
+		if item.File == nil {
+			continue
+		}
+		if e.config.MaxFileSize > 0 && item.Size > e.config.MaxFileSize {
+			continue
+		}
+
+		location := e.location(drive, itemPath)
+		content, err := e.api.download(ctx, itemURL+"/content", e.config.MaxFileSize)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: downloading %s: %v\n", location, err)
+			continue
+		}
+		if err := e.fs.processContent(location, content, callback); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// location names a path within a document library by the library's URL.
+func (e *SharePointEnumerator) location(drive graphDrive, p string) string {
+	root := drive.WebURL
+	if unescaped, err := url.PathUnescape(root); err == nil {
+		root = unescaped
+	}
+	if p == "" {
+		return root
+	}
+	return strings.TrimRight(root, "/") + "/" + p
+}
@@ -0,0 +1,73 @@
+package enum
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharePointEnumerator_Enumerate(t *testing.T) {
+	var srv *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sites/contoso.sharepoint.com:/sites/Finance", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer graph-token", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"id":"site1","webUrl":"https://contoso.sharepoint.com/sites/Finance"}`))
+	})
+	mux.HandleFunc("/sites/site1/drives", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"value":[{"id":"d1","name":"Documents","webUrl":"https://contoso.sharepoint.com/sites/Finance/Shared%20Documents"}]}`))
+	})
+	mux.HandleFunc("/drives/d1/root/children", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "" {
+			w.Write([]byte(`{"value":[{"id":"f1","name":"Q1","folder":{}}],"@odata.nextLink":"` + srv.URL + `/drives/d1/root/children?page=2"}`))
+			return
+		}
+		w.Write([]byte(`{"value":[{"id":"i2","name":"big.bin","size":5000,"file":{}}]}`))
+	})
+	mux.HandleFunc("/drives/d1/items/f1/children", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"value":[{"id":"i1","name":"vpn.conf","size":15,"file":{}}]}`))
+	})
+	mux.HandleFunc("/drives/d1/items/i1/content", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secret=s3cr3t!\n"))
+	})
+	mux.HandleFunc("/drives/d1/items/i2/content", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("document over the size limit was downloaded")
+	})
+	srv = httptest.NewServer(mux)
+	defer srv.Close()
+
+	e, err := NewSharePointEnumerator(SharePointConfig{
+		Token:   "graph-token",
+		BaseURL: srv.URL,
+		Sites:   []string{"https://contoso.sharepoint.com/sites/Finance/"},
+		Config:  Config{MaxFileSize: 1024, IgnoreFile: os.DevNull},
+	})
+	require.NoError(t, err)
+
+	var provs []types.Provenance
+	err = e.Enumerate(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		assert.Equal(t, "secret=s3cr3t!\n", string(content))
+		provs = append(provs, prov)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []types.Provenance{
+		types.FileProvenance{FilePath: "https://contoso.sharepoint.com/sites/Finance/Shared Documents/Q1/vpn.conf"},
+	}, provs)
+}
+
+func TestGraphSiteRef(t *testing.T) {
+	assert.Equal(t, "contoso.sharepoint.com:/sites/Finance", graphSiteRef("https://contoso.sharepoint.com/sites/Finance/"))
+	assert.Equal(t, "contoso.sharepoint.com", graphSiteRef("https://contoso.sharepoint.com"))
+	assert.Equal(t, "contoso.sharepoint.com,1234,5678", graphSiteRef("contoso.sharepoint.com,1234,5678"))
+}
+
+func TestNewSharePointEnumerator_RequiresToken(t *testing.T) {
+	_, err := NewSharePointEnumerator(SharePointConfig{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,238 @@
+package enum
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hirochachacha/go-smb2"
+	gitignore "github.com/sabhiram/go-gitignore"
+
+	"github.com/praetorian-inc/titus/pkg/enum/ignore"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// DefaultSMBPort is the port used when an smb:// URL names none.
+const DefaultSMBPort = 445
+
+// smbDialTimeout bounds connecting to an SMB server.
+const smbDialTimeout = 30 * time.Second
+
+// SMBConfig configures SMB share enumeration.
+type SMBConfig struct {
+	Host     string // server host name or address
+	Port     int    // server port (0 = DefaultSMBPort)
+	Share    string // share name; empty to scan every listable share except hidden ($) ones
+	Path     string // directory within the share to scan (empty = whole share)
+	User     string // user name (empty for anonymous/guest access)
+	Password string // password
+	Hash     string // hex NT hash, used instead of Password (pass-the-hash)
+	Domain   string // NTLM domain
+	Config          // Embedded base config (MaxFileSize, IgnoreFile, ExtractArchives)
+}
+
+// IsSMBURL reports whether target is an smb:// URL.
+func IsSMBURL(target string) bool {
+	return strings.HasPrefix(strings.ToLower(target), "smb://")
+}
+
+// ParseSMBURL parses smb://[[domain;]user[:password]@]host[:port][/share[/path]].
+func ParseSMBURL(raw string) (SMBConfig, error) {
+	if !IsSMBURL(raw) {
+		return SMBConfig{}, fmt.Errorf("not an smb:// URL: %s", raw)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return SMBConfig{}, fmt.Errorf("invalid SMB URL: %w", err)
+	}
+	if u.Hostname() == "" {
+		return SMBConfig{}, fmt.Errorf("invalid SMB URL %q: missing host", raw)
+	}
+
+	cfg := SMBConfig{Host: u.Hostname()}
+	if p := u.Port(); p != "" {
+		if cfg.Port, err = strconv.Atoi(p); err != nil {
+			return SMBConfig{}, fmt.Errorf("invalid SMB URL %q: bad port", raw)
+		}
+	}
+	if u.User != nil {
+		cfg.User = u.User.Username()
+		cfg.Password, _ = u.User.Password()
+		if domain, user, ok := strings.Cut(cfg.User, ";"); ok {
+			cfg.Domain, cfg.User = domain, user
+		}
+	}
+	share, dir, _ := strings.Cut(strings.Trim(u.Path, "/"), "/")
+	cfg.Share = share
+	cfg.Path = strings.Trim(dir, "/")
+	return cfg, nil
+}
+
+// URL returns the smb:// URL of the configured location, without credentials.
+func (c SMBConfig) URL() string {
+	host := c.Host
+	if c.Port != 0 && c.Port != DefaultSMBPort {
+		host = net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+	}
+	return (&url.URL{Scheme: "smb", Host: host, Path: "/" + path.Join(c.Share, c.Path)}).String()
+}
+
+// smbFS is the part of *smb2.Share used to walk a share.
+type smbFS interface {
+	ReadDir(dirname string) ([]os.FileInfo, error)
+	ReadFile(filename string) ([]byte, error)
+}
+
+// SMBEnumerator enumerates files on SMB shares over the network, without a
+// local mount. Files are yielded with FileProvenance whose path is the file's
+// smb:// URL. MaxFileSize, ignore patterns (matched against the path within
+// the share) and archive extraction apply as for the filesystem enumerator.
+type SMBEnumerator struct {
+	config SMBConfig
+	fs     *FilesystemEnumerator
+	ignore *gitignore.GitIgnore
+}
+
+// NewSMBEnumerator creates a new SMB share enumerator.
+func NewSMBEnumerator(cfg SMBConfig) (*SMBEnumerator, error) {
+	if cfg.Hash != "" {
+		if _, err := hex.DecodeString(cfg.Hash); err != nil {
+			return nil, fmt.Errorf("invalid NT hash: %w", err)
+		}
+	}
+	ig, err := ignore.CompilePatterns(cfg.IgnoreFile)
+	if err != nil {
+		return nil, err
+	}
+	return &SMBEnumerator{
+		config: cfg,
+		fs:     NewFilesystemEnumerator(cfg.Config),
+		ignore: ig,
+	}, nil
+}
+
+// Enumerate connects to the server and walks the configured share, or every
+// listable share.
+func (e *SMBEnumerator) Enumerate(ctx context.Context, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	port := e.config.Port
+	if port == 0 {
+		port = DefaultSMBPort
+	}
+	addr := net.JoinHostPort(e.config.Host, strconv.Itoa(port))
+	conn, err := (&net.Dialer{Timeout: smbDialTimeout}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("connecting to %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	hash, _ := hex.DecodeString(e.config.Hash)
+	dialer := &smb2.Dialer{
+		Initiator: &smb2.NTLMInitiator{
+			User:     e.config.User,
+			Password: e.config.Password,
+			Hash:     hash,
+			Domain:   e.config.Domain,
+		},
+	}
+	session, err := dialer.DialContext(ctx, conn)
+	if err != nil {
+		return fmt.Errorf("SMB login to %s: %w", addr, err)
+	}
+	defer session.Logoff()
+	session = session.WithContext(ctx)
+
+	shares := []string{e.config.Share}
+	if e.config.Share == "" {
+		names, err := session.ListSharenames()
+		if err != nil {
+			return fmt.Errorf("listing shares on %s: %w", addr, err)
+		}
+		shares = visibleShares(names)
+	}
+
+	for _, name := range shares {
+		share, err := session.Mount(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: mounting share %s: %v\n", name, err)
+			continue
+		}
+		err = e.walkShare(ctx, share.WithContext(ctx), name, callback)
+		share.Umount()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// visibleShares drops hidden and administrative shares (IPC$, C$, ADMIN$, ...),
+// which can still be scanned by naming them in the URL.
+func visibleShares(names []string) []string {
+	var visible []string
+	for _, name := range names {
+		if !strings.HasSuffix(name, "$") {
+			visible = append(visible, name)
+		}
+	}
+	return visible
+}
+
+// walkShare yields every eligible file under the configured path of a share.
+// Unreadable directories and files are reported and skipped.
+func (e *SMBEnumerator) walkShare(ctx context.Context, share smbFS, name string, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	// at names a path within the share by its URL.
+	at := func(p string) string {
+		loc := e.config
+		loc.Share, loc.Path = name, p
+		return loc.URL()
+	}
+
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		entries, err := share.ReadDir(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: reading %s: %v\n", at(dir), err)
+			return nil
+		}
+		for _, info := range entries {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			p := path.Join(dir, info.Name())
+			rel := strings.TrimPrefix(strings.TrimPrefix(p, e.config.Path), "/")
+			if e.ignore != nil && rel != "" && e.ignore.MatchesPath(rel) {
+				continue
+			}
+			if info.IsDir() {
+				if err := walk(p); err != nil {
+					return err
+				}
+				continue
+			}
+			if !info.Mode().IsRegular() {
+				continue
+			}
+			if e.config.MaxFileSize > 0 && info.Size() > e.config.MaxFileSize {
+				continue
+			}
+
+			content, err := share.ReadFile(p)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: reading %s: %v\n", at(p), err)
+				continue
+			}
+			if err := e.fs.processContent(at(p), content, callback); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(e.config.Path)
+}
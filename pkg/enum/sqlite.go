@@ -0,0 +1,110 @@
+package enum
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// SQLiteScope selects the tables and columns of SQLite databases to extract,
+// so that scans of large application databases can skip bulk data or target
+// credential tables. A nil scope selects everything.
+type SQLiteScope struct {
+	include []sqlitePattern
+	exclude []sqlitePattern
+}
+
+// sqlitePattern matches a table, or with a column pattern, columns of
+// matching tables. Both are lowercase globs.
+type sqlitePattern struct {
+	table  string
+	column string
+}
+
+// NewSQLiteScope parses patterns of the form TABLE or TABLE.COLUMN, globs
+// matched case-insensitively like SQLite names. A pattern starting with "!"
+// excludes what it matches. If there are patterns without "!", only tables
+// they match are extracted, and of a table matched only by TABLE.COLUMN
+// patterns, only the matching columns.
+func NewSQLiteScope(patterns []string) (*SQLiteScope, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	s := &SQLiteScope{}
+	for _, raw := range patterns {
+		p := strings.ToLower(strings.TrimSpace(raw))
+		exclude := strings.HasPrefix(p, "!")
+		p = strings.TrimPrefix(p, "!")
+		table, column, _ := strings.Cut(p, ".")
+		if table == "" {
+			return nil, fmt.Errorf("invalid SQLite table pattern %q", raw)
+		}
+		for _, glob := range []string{table, column} {
+			if _, err := path.Match(glob, ""); err != nil {
+				return nil, fmt.Errorf("invalid SQLite table pattern %q: %w", raw, err)
+			}
+		}
+		if exclude {
+			s.exclude = append(s.exclude, sqlitePattern{table, column})
+		} else {
+			s.include = append(s.include, sqlitePattern{table, column})
+		}
+	}
+	return s, nil
+}
+
+// Table reports whether any of table is extracted.
+func (s *SQLiteScope) Table(table string) bool {
+	if s == nil {
+		return true
+	}
+	table = strings.ToLower(table)
+	for _, p := range s.exclude {
+		if p.column == "" && globMatch(p.table, table) {
+			return false
+		}
+	}
+	if len(s.include) == 0 {
+		return true
+	}
+	for _, p := range s.include {
+		if globMatch(p.table, table) {
+			return true
+		}
+	}
+	return false
+}
+
+// Column reports whether column of table is extracted, table being one
+// Table selects.
+func (s *SQLiteScope) Column(table, column string) bool {
+	if s == nil {
+		return true
+	}
+	table, column = strings.ToLower(table), strings.ToLower(column)
+	for _, p := range s.exclude {
+		if p.column != "" && globMatch(p.table, table) && globMatch(p.column, column) {
+			return false
+		}
+	}
+
+	// All columns, unless the table is only matched by column patterns
+	var selected, restricted bool
+	for _, p := range s.include {
+		if !globMatch(p.table, table) {
+			continue
+		}
+		if p.column == "" {
+			return true
+		}
+		restricted = true
+		selected = selected || globMatch(p.column, column)
+	}
+	return selected || !restricted
+}
+
+// globMatch reports whether name matches a glob validated by NewSQLiteScope.
+func globMatch(glob, name string) bool {
+	ok, _ := path.Match(glob, name)
+	return ok
+}
@@ -0,0 +1,93 @@
+package enum
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSQLiteScope(t *testing.T) {
+	scope, err := NewSQLiteScope(nil)
+	require.NoError(t, err)
+	assert.Nil(t, scope)
+	assert.True(t, scope.Table("anything"))
+	assert.True(t, scope.Column("anything", "at_all"))
+
+	for _, bad := range []string{"", "!", ".password", "users[", "users.pass[word"} {
+		_, err := NewSQLiteScope([]string{bad})
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestSQLiteScope(t *testing.T) {
+	scope, err := NewSQLiteScope([]string{"Credentials", "*.*token*", "!audit_*", "!credentials.notes"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		table, column         string
+		wantTable, wantColumn bool
+	}{
+		{"credentials", "password", true, true},
+		{"CREDENTIALS", "Notes", true, false},
+		{"sessions", "refresh_token", true, true},
+		{"sessions", "user_agent", true, false},
+		{"audit_log", "api_token", false, true},
+		{"messages", "body", true, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.wantTable, scope.Table(tt.table), "Table(%s)", tt.table)
+		if tt.wantTable {
+			assert.Equal(t, tt.wantColumn, scope.Column(tt.table, tt.column), "Column(%s, %s)", tt.table, tt.column)
+		}
+	}
+
+	// Without includes, everything not excluded is extracted
+	scope, err = NewSQLiteScope([]string{"!cache", "!*.avatar"})
+	require.NoError(t, err)
+	assert.False(t, scope.Table("cache"))
+	assert.True(t, scope.Table("users"))
+	assert.True(t, scope.Column("users", "password"))
+	assert.False(t, scope.Column("users", "avatar"))
+}
+
+func TestDumpSQLite_Scope(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.db")
+	testSQLiteStore(t, path,
+		`CREATE TABLE users (name TEXT, password TEXT, bio TEXT)`,
+		`INSERT INTO users VALUES ('jsmith', 'Winter2024!', 'likes hiking')`,
+		`INSERT INTO users VALUES ('adoe', 'Summer2025!', 'likes chess')`,
+		`CREATE TABLE events (payload TEXT)`,
+		`INSERT INTO events VALUES ('page_view')`,
+	)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	dump := func(rowLimit int, patterns ...string) string {
+		scope, err := NewSQLiteScope(patterns)
+		require.NoError(t, err)
+		db, cleanup, err := openSQLite(content, nil, 0)
+		require.NoError(t, err)
+		defer cleanup()
+		results, err := dumpSQLite(db, ExtractionLimits{SQLiteRowLimit: rowLimit, SQLiteScope: scope})
+		require.NoError(t, err)
+		if len(results) == 0 {
+			return ""
+		}
+		return string(results[0].Content)
+	}
+
+	got := dump(0)
+	assert.Contains(t, got, "Winter2024!")
+	assert.Contains(t, got, "page_view")
+
+	got = dump(0, "users.password")
+	assert.Equal(t, "Winter2024! \nSummer2025! \n", got)
+
+	got = dump(1, "!events", "!*.bio")
+	assert.Equal(t, "jsmith Winter2024! \n", got)
+
+	assert.Empty(t, dump(0, "sessions"))
+}
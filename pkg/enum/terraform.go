@@ -0,0 +1,291 @@
+package enum
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// tfSensitiveMarker is appended to rendered Terraform attributes that state or
+// plan files flag as sensitive, so rules can key on Terraform's own judgement.
+const tfSensitiveMarker = "# sensitive"
+
+// isTerraformJSON reports whether a file is a Terraform state file or a plan
+// rendered with `terraform show -json`.
+func isTerraformJSON(path string, content []byte) bool {
+	name := strings.ToLower(filepath.Base(path))
+	if strings.HasSuffix(name, ".tfstate") || strings.HasSuffix(name, ".tfstate.backup") {
+		return true
+	}
+	if filepath.Ext(name) != ".json" {
+		return false
+	}
+	trimmed := bytes.TrimSpace(content)
+	if len(trimmed) == 0 || trimmed[0] != '{' || !bytes.Contains(trimmed, []byte(`"terraform_version"`)) {
+		return false
+	}
+	return bytes.Contains(trimmed, []byte(`"resources"`)) ||
+		bytes.Contains(trimmed, []byte(`"resource_changes"`)) ||
+		bytes.Contains(trimmed, []byte(`"planned_values"`))
+}
+
+type tfDocument struct {
+	// State files
+	Resources []tfStateResource   `json:"resources"`
+	Outputs   map[string]tfOutput `json:"outputs"`
+
+	// Plan files
+	ResourceChanges []tfResourceChange `json:"resource_changes"`
+	PlannedValues   struct {
+		Outputs map[string]tfOutput `json:"outputs"`
+	} `json:"planned_values"`
+	Variables map[string]struct {
+		Value interface{} `json:"value"`
+	} `json:"variables"`
+	Configuration struct {
+		RootModule struct {
+			Variables map[string]struct {
+				Sensitive bool `json:"sensitive"`
+			} `json:"variables"`
+		} `json:"root_module"`
+	} `json:"configuration"`
+}
+
+type tfStateResource struct {
+	Module    string `json:"module"`
+	Mode      string `json:"mode"`
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Instances []struct {
+		IndexKey            interface{}       `json:"index_key"`
+		Attributes          interface{}       `json:"attributes"`
+		SensitiveAttributes []json.RawMessage `json:"sensitive_attributes"`
+	} `json:"instances"`
+}
+
+type tfOutput struct {
+	Value     interface{} `json:"value"`
+	Sensitive bool        `json:"sensitive"`
+}
+
+type tfResourceChange struct {
+	Address string `json:"address"`
+	Change  struct {
+		Before          interface{} `json:"before"`
+		After           interface{} `json:"after"`
+		BeforeSensitive interface{} `json:"before_sensitive"`
+		AfterSensitive  interface{} `json:"after_sensitive"`
+	} `json:"change"`
+}
+
+// ExtractTerraform splits a Terraform state or JSON plan into one blob per
+// resource instance, output and input variable, named by its address (e.g.
+// "module.db.aws_db_instance.main", "output.db_password", "var.token").
+// Every string attribute is rendered on its own `path = "value"` line, with
+// tfSensitiveMarker appended to those Terraform flags as sensitive.
+func ExtractTerraform(content []byte) ([]ExtractedContent, error) {
+	var doc tfDocument
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse terraform json: %w", err)
+	}
+
+	var results []ExtractedContent
+	add := func(name string, r *tfRenderer) {
+		if r.buf.Len() > 0 {
+			results = append(results, ExtractedContent{Name: name, Content: r.buf.Bytes()})
+		}
+	}
+
+	for _, res := range doc.Resources {
+		for _, inst := range res.Instances {
+			r := &tfRenderer{sensitive: statePaths(inst.SensitiveAttributes)}
+			r.walk("", inst.Attributes, false)
+			add(stateAddress(res, inst.IndexKey), r)
+		}
+	}
+	for _, rc := range doc.ResourceChanges {
+		r := &tfRenderer{sensitive: markedPaths("", rc.Change.AfterSensitive, nil)}
+		r.walk("", rc.Change.After, false)
+		r.sensitive = markedPaths("", rc.Change.BeforeSensitive, nil)
+		r.walk("", rc.Change.Before, false)
+		add(rc.Address, r)
+	}
+
+	outputs := doc.Outputs
+	if len(outputs) == 0 {
+		outputs = doc.PlannedValues.Outputs
+	}
+	for _, name := range sortedKeys(outputs) {
+		r := &tfRenderer{}
+		r.walk(name, outputs[name].Value, outputs[name].Sensitive)
+		add("output."+name, r)
+	}
+
+	for _, name := range sortedKeys(doc.Variables) {
+		r := &tfRenderer{}
+		r.walk(name, doc.Variables[name].Value, doc.Configuration.RootModule.Variables[name].Sensitive)
+		add("var."+name, r)
+	}
+
+	return results, nil
+}
+
+// stateAddress builds a resource instance address from its state entry.
+func stateAddress(res tfStateResource, indexKey interface{}) string {
+	addr := res.Type + "." + res.Name
+	if res.Mode == "data" {
+		addr = "data." + addr
+	}
+	if res.Module != "" {
+		addr = res.Module + "." + addr
+	}
+	switch k := indexKey.(type) {
+	case float64:
+		addr += "[" + strconv.FormatFloat(k, 'f', -1, 64) + "]"
+	case string:
+		addr += "[" + strconv.Quote(k) + "]"
+	}
+	return addr
+}
+
+// statePaths converts a state instance's sensitive_attributes, a list of
+// paths made of get_attr and index steps, into rendered attribute paths.
+func statePaths(raw []json.RawMessage) map[string]bool {
+	paths := make(map[string]bool)
+	for _, r := range raw {
+		var steps []struct {
+			Type  string          `json:"type"`
+			Value json.RawMessage `json:"value"`
+		}
+		if err := json.Unmarshal(r, &steps); err != nil {
+			continue
+		}
+		path := ""
+		for _, step := range steps {
+			switch step.Type {
+			case "get_attr":
+				var name string
+				json.Unmarshal(step.Value, &name)
+				path = childPath(path, name)
+			case "index":
+				var key struct {
+					Value interface{} `json:"value"`
+				}
+				json.Unmarshal(step.Value, &key)
+				switch k := key.Value.(type) {
+				case float64:
+					path = indexPath(path, int(k))
+				case string:
+					path = childPath(path, k)
+				}
+			}
+		}
+		if path != "" {
+			paths[path] = true
+		}
+	}
+	return paths
+}
+
+// markedPaths collects the paths set to true in a plan's before_sensitive or
+// after_sensitive structure, which mirrors the shape of the values it marks.
+func markedPaths(path string, v interface{}, paths map[string]bool) map[string]bool {
+	if paths == nil {
+		paths = make(map[string]bool)
+	}
+	switch v := v.(type) {
+	case bool:
+		if v {
+			paths[path] = true
+		}
+	case map[string]interface{}:
+		for k, child := range v {
+			markedPaths(childPath(path, k), child, paths)
+		}
+	case []interface{}:
+		for i, child := range v {
+			markedPaths(indexPath(path, i), child, paths)
+		}
+	}
+	return paths
+}
+
+// tfEscape escapes a value for a quoted string, as in HCL.
+var tfEscape = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// tfRenderer flattens attribute values into `path = "value"` lines.
+type tfRenderer struct {
+	buf       bytes.Buffer
+	sensitive map[string]bool
+}
+
+func (r *tfRenderer) walk(path string, v interface{}, sensitive bool) {
+	sensitive = sensitive || r.sensitive[path]
+	switch v := v.(type) {
+	case string:
+		if v == "" || path == "" {
+			return
+		}
+		marker := ""
+		if sensitive {
+			marker = " " + tfSensitiveMarker
+		}
+		if strings.Contains(v, "\n") {
+			fmt.Fprintf(&r.buf, "%s = <<EOT%s\n%s\nEOT\n", path, marker, strings.TrimSuffix(v, "\n"))
+		} else {
+			fmt.Fprintf(&r.buf, "%s = \"%s\"%s\n", path, tfEscape.Replace(v), marker)
+		}
+	case map[string]interface{}:
+		for _, k := range sortedKeys(v) {
+			r.walk(childPath(path, k), v[k], sensitive)
+		}
+	case []interface{}:
+		for i, child := range v {
+			r.walk(indexPath(path, i), child, sensitive)
+		}
+	}
+}
+
+// childPath appends an attribute or map key to path, quoting keys that
+// aren't plain identifiers.
+func childPath(path, key string) string {
+	if !isIdentifier(key) {
+		return path + "[" + strconv.Quote(key) + "]"
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func indexPath(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		case i > 0 && (c == '-' || c >= '0' && c <= '9'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,309 @@
+package enum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	gitignore "github.com/sabhiram/go-gitignore"
+
+	"github.com/praetorian-inc/titus/pkg/enum/ignore"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// DefaultWatchInterval is the polling interval, or the time file events are
+// collected for, used when Watcher.Interval is zero.
+const DefaultWatchInterval = 2 * time.Second
+
+// fileState is the change-detection fingerprint recorded for each watched file.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// Watcher yields blobs for files created or modified in a directory tree. It
+// is told of changes by the OS (inotify, kqueue, ReadDirectoryChangesW), and
+// scans the files changed every Interval. With Polling, or on network
+// filesystems where the OS sends no events, the tree is walked every
+// Interval instead.
+type Watcher struct {
+	config      Config
+	fs          *FilesystemEnumerator
+	ignore      *gitignore.GitIgnore
+	seen        map[string]fileState
+	initialized bool
+
+	Interval    time.Duration // time between scans of changed files (0 = DefaultWatchInterval)
+	InitialScan bool          // yield files that already exist when watching starts
+	Polling     bool          // walk the tree every Interval rather than waiting for file events
+}
+
+// NewWatcher creates a watcher for config.Root.
+func NewWatcher(config Config) (*Watcher, error) {
+	ig, err := ignore.CompilePatterns(config.IgnoreFile)
+	if err != nil {
+		return nil, err
+	}
+	return &Watcher{
+		config: config,
+		fs:     NewFilesystemEnumerator(config),
+		ignore: ig,
+		seen:   make(map[string]fileState),
+	}, nil
+}
+
+// Enumerate watches until ctx is cancelled, yielding changed files as they appear.
+// It returns nil when the context is cancelled.
+func (w *Watcher) Enumerate(ctx context.Context, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) error {
+	return w.Watch(ctx, callback, nil)
+}
+
+// Watch watches until ctx is cancelled. After every scan of changed files,
+// onPoll (if non-nil) is called with the number of files yielded and the
+// time the scan took; when polling, that is after every walk of the tree.
+func (w *Watcher) Watch(ctx context.Context, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error, onPoll func(changed int, took time.Duration)) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	if w.Polling {
+		return w.poll(ctx, interval, callback, onPoll)
+	}
+
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("watching %s: %w (use polling instead)", w.config.Root, err)
+	}
+	defer fw.Close()
+
+	// Watch the tree before the baseline walk, so no change falls between them
+	pending := make(map[string]bool)
+	if err := w.addTree(fw, w.config.Root, nil); err != nil {
+		return err
+	}
+	if _, err := w.Poll(ctx, callback); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	rescan := false
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case ev, ok := <-fw.Events:
+			if !ok {
+				return nil
+			}
+			if ev.Has(fsnotify.Create) {
+				if info, err := os.Lstat(ev.Name); err == nil && info.IsDir() {
+					// Files may have been written before the directory was watched
+					if err := w.addTree(fw, ev.Name, pending); err != nil {
+						return err
+					}
+					continue
+				}
+			}
+			if ev.Has(fsnotify.Create) || ev.Has(fsnotify.Write) {
+				pending[ev.Name] = true
+			}
+			if ev.Has(fsnotify.Remove) || ev.Has(fsnotify.Rename) {
+				delete(w.seen, ev.Name)
+			}
+
+		case err, ok := <-fw.Errors:
+			if !ok {
+				return nil
+			}
+			if !errors.Is(err, fsnotify.ErrEventOverflow) {
+				return fmt.Errorf("watching %s: %w", w.config.Root, err)
+			}
+			// Events were dropped: walk the tree to find what changed
+			rescan = true
+
+		case <-ticker.C:
+			if len(pending) == 0 && !rescan {
+				continue
+			}
+			start := time.Now()
+			var changed int
+			var err error
+			if rescan {
+				changed, err = w.Poll(ctx, callback)
+			} else {
+				changed, err = w.scanChanged(ctx, pending, callback)
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+			clear(pending)
+			rescan = false
+			if onPoll != nil {
+				onPoll(changed, time.Since(start))
+			}
+		}
+	}
+}
+
+// poll walks the tree every interval until ctx is cancelled.
+func (w *Watcher) poll(ctx context.Context, interval time.Duration, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error, onPoll func(changed int, took time.Duration)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+		changed, err := w.Poll(ctx, callback)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if onPoll != nil {
+			onPoll(changed, time.Since(start))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// addTree watches dir and the directories below it that aren't ignored. If
+// pending is non-nil, the files in them are added to it.
+func (w *Watcher) addTree(fw *fsnotify.Watcher, dir string, pending map[string]bool) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !info.IsDir() {
+			if pending != nil {
+				pending[path] = true
+			}
+			return nil
+		}
+		if path != w.config.Root && w.ignored(path) {
+			return filepath.SkipDir
+		}
+		if err := fw.Add(path); err != nil {
+			return fmt.Errorf("watching %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// ignored reports whether the ignore patterns match path.
+func (w *Watcher) ignored(path string) bool {
+	if w.ignore == nil {
+		return false
+	}
+	relPath, err := filepath.Rel(w.config.Root, path)
+	return err == nil && w.ignore.MatchesPath(relPath)
+}
+
+// watched reports whether the file at path, with info, is one the watcher
+// scans: not a directory, an unfollowed symlink, oversized or ignored.
+func (w *Watcher) watched(path string, info os.FileInfo) bool {
+	if info.IsDir() {
+		return false
+	}
+	if info.Mode()&os.ModeSymlink != 0 && !w.config.FollowSymlinks {
+		return false
+	}
+	if w.config.MaxFileSize > 0 && info.Size() > w.config.MaxFileSize {
+		return false
+	}
+	return !w.ignored(path)
+}
+
+// scanChanged yields the files of paths that still exist and whose size or
+// modification time differs from when they were last seen, in path order.
+func (w *Watcher) scanChanged(ctx context.Context, paths map[string]bool, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) (int, error) {
+	changed := 0
+	for _, path := range slices.Sorted(maps.Keys(paths)) {
+		info, err := os.Lstat(path)
+		if err != nil || !w.watched(path, info) {
+			continue
+		}
+		st := fileState{size: info.Size(), modTime: info.ModTime()}
+		if prev, ok := w.seen[path]; ok && prev == st {
+			continue
+		}
+		w.seen[path] = st
+		if err := w.fs.processFile(ctx, path, callback); err != nil {
+			return changed, fmt.Errorf("processing %s: %w", path, err)
+		}
+		changed++
+	}
+	return changed, nil
+}
+
+// Poll performs a single pass over the tree and yields every file whose size or
+// modification time changed since the previous pass. The first pass only records
+// a baseline unless InitialScan is set. Returns the number of files yielded.
+func (w *Watcher) Poll(ctx context.Context, callback func(content []byte, blobID types.BlobID, prov types.Provenance) error) (int, error) {
+	current := make(map[string]fileState, len(w.seen))
+	var changed []string
+
+	err := filepath.Walk(w.config.Root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// Files can disappear between readdir and lstat while they are being written
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if info.IsDir() && path != w.config.Root && w.ignored(path) {
+			return filepath.SkipDir
+		}
+		if !w.watched(path, info) {
+			return nil
+		}
+
+		st := fileState{size: info.Size(), modTime: info.ModTime()}
+		current[path] = st
+		if prev, ok := w.seen[path]; !ok || prev != st {
+			changed = append(changed, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	w.seen = current
+	if !w.initialized {
+		w.initialized = true
+		if !w.InitialScan {
+			return 0, nil
+		}
+	}
+
+	for _, path := range changed {
+		if err := w.fs.processFile(ctx, path, callback); err != nil {
+			return 0, fmt.Errorf("processing %s: %w", path, err)
+		}
+	}
+	return len(changed), nil
+}
@@ -0,0 +1,164 @@
+package enum
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func collectPoll(t *testing.T, w *Watcher) []string {
+	t.Helper()
+	var paths []string
+	_, err := w.Poll(context.Background(), func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+		paths = append(paths, filepath.Base(prov.Path()))
+		return nil
+	})
+	require.NoError(t, err)
+	return paths
+}
+
+func TestWatcher_BaselineThenChanges(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "existing.txt"), []byte("old"), 0644))
+
+	w, err := NewWatcher(Config{Root: dir, IgnoreFile: "/dev/null"})
+	require.NoError(t, err)
+
+	// First poll records a baseline only
+	assert.Empty(t, collectPoll(t, w))
+
+	// Unchanged tree yields nothing
+	assert.Empty(t, collectPoll(t, w))
+
+	// New file is yielded
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("fresh"), 0644))
+	assert.Equal(t, []string{"new.txt"}, collectPoll(t, w))
+
+	// Modified file is yielded (size change guarantees detection regardless of mtime granularity)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "existing.txt"), []byte("modified"), 0644))
+	assert.Equal(t, []string{"existing.txt"}, collectPoll(t, w))
+
+	// Deleting a file yields nothing
+	require.NoError(t, os.Remove(filepath.Join(dir, "new.txt")))
+	assert.Empty(t, collectPoll(t, w))
+}
+
+func TestWatcher_InitialScan(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644))
+
+	w, err := NewWatcher(Config{Root: dir, IgnoreFile: "/dev/null"})
+	require.NoError(t, err)
+	w.InitialScan = true
+
+	assert.Equal(t, []string{"a.txt"}, collectPoll(t, w))
+}
+
+func TestWatcher_SkipsBinaryAndOversized(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWatcher(Config{Root: dir, IgnoreFile: "/dev/null", MaxFileSize: 10})
+	require.NoError(t, err)
+	collectPoll(t, w)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bin.dat"), []byte{0, 1, 2}, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "big.txt"), []byte("this is more than ten bytes"), 0644))
+	assert.Empty(t, collectPoll(t, w))
+}
+
+func TestWatcher_PollingStopsOnCancel(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWatcher(Config{Root: dir, IgnoreFile: "/dev/null"})
+	require.NoError(t, err)
+	w.Interval = 10 * time.Millisecond
+	w.Polling = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	polls := 0
+	err = w.Watch(ctx, func([]byte, types.BlobID, types.Provenance) error { return nil }, func(int, time.Duration) {
+		polls++
+		if polls == 3 {
+			cancel()
+		}
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, polls)
+}
+
+func TestWatcher_Events(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "existing.txt"), []byte("old"), 0644))
+
+	w, err := NewWatcher(Config{Root: dir, IgnoreFile: "/dev/null"})
+	require.NoError(t, err)
+	w.Interval = 20 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	found := make(chan string, 10)
+	done := make(chan error)
+	go func() {
+		done <- w.Watch(ctx, func(content []byte, blobID types.BlobID, prov types.Provenance) error {
+			rel, _ := filepath.Rel(dir, prov.Path())
+			found <- rel + "=" + string(content)
+			return nil
+		}, nil)
+	}()
+
+	next := func() string {
+		t.Helper()
+		select {
+		case f := <-found:
+			return f
+		case <-time.After(5 * time.Second):
+			t.Fatal("no file scanned")
+			return ""
+		}
+	}
+
+	// Wait for the watches to be in place: the first change is seen once they are
+	require.Eventually(t, func() bool {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "existing.txt"), []byte("modified"), 0644))
+		select {
+		case f := <-found:
+			assert.Equal(t, "existing.txt=modified", f)
+			return true
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// A new directory is watched, and the files written to it before then are scanned
+	sub := filepath.Join(dir, "sub", "deeper")
+	require.NoError(t, os.MkdirAll(sub, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(sub, "new.txt"), []byte("fresh"), 0644))
+	assert.Equal(t, filepath.Join("sub", "deeper", "new.txt")+"=fresh", next())
+
+	require.NoError(t, os.WriteFile(filepath.Join(sub, "new.txt"), []byte("fresher"), 0644))
+	assert.Equal(t, filepath.Join("sub", "deeper", "new.txt")+"=fresher", next())
+
+	cancel()
+	require.NoError(t, <-done)
+	assert.Empty(t, found, "each change is scanned once")
+}
+
+func TestWatcher_PollSkipsIgnoredDirs(t *testing.T) {
+	dir := t.TempDir()
+	ignoreFile := filepath.Join(t.TempDir(), "ignore")
+	// As in git, a file can't be re-included once its directory is ignored
+	require.NoError(t, os.WriteFile(ignoreFile, []byte("build\n!build/keep.txt\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "build"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "build", "keep.txt"), []byte("k"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644))
+
+	w, err := NewWatcher(Config{Root: dir, IgnoreFile: ignoreFile})
+	require.NoError(t, err)
+	w.InitialScan = true
+
+	assert.Equal(t, []string{"a.txt"}, collectPoll(t, w))
+}
@@ -0,0 +1,92 @@
+package enum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// apiRetries is how many times a rate-limited API request is retried.
+const apiRetries = 3
+
+// apiMaxRetryWait caps the wait requested by a Retry-After header.
+const apiMaxRetryWait = time.Minute
+
+// apiClient issues authenticated GET requests to a REST API, retrying
+// requests that are rate limited.
+type apiClient struct {
+	client *http.Client
+	auth   func(req *http.Request)
+}
+
+// get returns the response body of a successful request. The caller must
+// close it.
+func (c *apiClient) get(ctx context.Context, url string) (io.ReadCloser, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+		c.auth(req)
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("requesting %s: %w", url, err)
+		}
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return resp.Body, nil
+		}
+		resp.Body.Close()
+
+		if (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) || attempt == apiRetries {
+			return nil, fmt.Errorf("requesting %s: %s", url, resp.Status)
+		}
+		wait := time.Duration(attempt+1) * time.Second
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			wait = min(time.Duration(secs)*time.Second, apiMaxRetryWait)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// getJSON decodes the response to a request into v.
+func (c *apiClient) getJSON(ctx context.Context, url string, v any) error {
+	body, err := c.get(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		return fmt.Errorf("decoding %s: %w", url, err)
+	}
+	return nil
+}
+
+// download returns the response to a request, failing if it is larger than
+// maxSize bytes (0 = no limit).
+func (c *apiClient) download(ctx context.Context, url string, maxSize int64) ([]byte, error) {
+	body, err := c.get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	var r io.Reader = body
+	if maxSize > 0 {
+		r = io.LimitReader(body, maxSize+1)
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", url, err)
+	}
+	if maxSize > 0 && int64(len(content)) > maxSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", url, maxSize)
+	}
+	return content, nil
+}
@@ -0,0 +1,152 @@
+package explore
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/praetorian-inc/titus/pkg/style"
+)
+
+// dashboardRefresh is how often the dashboard polls for new stats.
+const dashboardRefresh = 250 * time.Millisecond
+
+// ScanStats is a snapshot of a running scan, as shown by the scan dashboard.
+type ScanStats struct {
+	Elapsed         time.Duration
+	Blobs           int64
+	Bytes           int64
+	Matches         int64
+	Findings        int64
+	TopRules        []RuleCount     // rules with the most matches, most first
+	Recent          []RecentFinding // newest first
+	BusyWorkers     int
+	Workers         int
+	ValidationQueue int // matches submitted for validation and not yet validated
+	Validating      bool
+}
+
+// String formats the stats as one plain-text line, for logs and terminals
+// that can't show the dashboard.
+func (s ScanStats) String() string {
+	line := fmt.Sprintf("%s: %d blobs, %s, %d matches, %d findings, %d/%d workers busy",
+		s.Elapsed.Truncate(time.Second), s.Blobs, style.Bytes(s.Bytes), s.Matches, s.Findings, s.BusyWorkers, s.Workers)
+	if s.Validating {
+		line += fmt.Sprintf(", %d awaiting validation", s.ValidationQueue)
+	}
+	return line
+}
+
+// RuleCount is the number of matches of one rule.
+type RuleCount struct {
+	RuleID   string
+	RuleName string
+	Matches  int
+}
+
+// RecentFinding is a finding a running scan has just recorded.
+type RecentFinding struct {
+	RuleName string
+	Path     string
+}
+
+// dashboardTickMsg asks the dashboard to poll for new stats.
+type dashboardTickMsg struct{}
+
+// DashboardDoneMsg tells the dashboard that the scan has ended, so it draws
+// the final stats and exits.
+type DashboardDoneMsg struct{}
+
+// dashboardModel is a bubbletea model that redraws a running scan's stats
+// in place, below the shell prompt rather than in the alternate screen.
+type dashboardModel struct {
+	poll      func() ScanStats
+	interrupt func()
+	stats     ScanStats
+	width     int
+	done      bool
+}
+
+// NewDashboard creates the scan dashboard. poll is called to refresh the
+// stats; interrupt is called if the user presses ctrl+c or q, and should
+// cancel the scan.
+func NewDashboard(poll func() ScanStats, interrupt func()) tea.Model {
+	return &dashboardModel{poll: poll, interrupt: interrupt, stats: poll(), width: 80}
+}
+
+func dashboardTick() tea.Cmd {
+	return tea.Tick(dashboardRefresh, func(time.Time) tea.Msg { return dashboardTickMsg{} })
+}
+
+func (m *dashboardModel) Init() tea.Cmd {
+	return dashboardTick()
+}
+
+func (m *dashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q":
+			if m.interrupt != nil {
+				m.interrupt()
+			}
+			m.done = true
+			return m, tea.Quit
+		}
+	case dashboardTickMsg:
+		m.stats = m.poll()
+		return m, dashboardTick()
+	case DashboardDoneMsg:
+		m.stats = m.poll()
+		m.done = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m *dashboardModel) View() string {
+	s := m.stats
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("titus scan"))
+	b.WriteString(" ")
+	b.WriteString(statusBarStyle.Render(s.Elapsed.Truncate(time.Second).String()))
+	b.WriteString("\n")
+
+	field := func(label, value string) string {
+		return fieldLabelStyle.Render(label) + " " + fieldValueStyle.Render(value)
+	}
+	line := []string{
+		field("Blobs", fmt.Sprint(s.Blobs)),
+		field("Scanned", style.Bytes(s.Bytes)),
+		field("Matches", fmt.Sprint(s.Matches)),
+		field("Findings", fmt.Sprint(s.Findings)),
+		field("Workers", fmt.Sprintf("%d/%d busy", s.BusyWorkers, s.Workers)),
+	}
+	if s.Validating {
+		line = append(line, field("Validation queue", fmt.Sprint(s.ValidationQueue)))
+	}
+	b.WriteString(strings.Join(line, "  "))
+	b.WriteString("\n\n")
+
+	rules := []string{headerRowStyle.Render("Top rules")}
+	for _, r := range s.TopRules {
+		rules = append(rules, fmt.Sprintf("%s %s", facetCountStyle.Render(fmt.Sprintf("%6d", r.Matches)), truncateString(r.RuleName, 40)))
+	}
+	recent := []string{headerRowStyle.Render("Recent findings")}
+	for _, f := range s.Recent {
+		recent = append(recent, fmt.Sprintf("%s %s", snippetMatchStyle.Render(truncateString(f.RuleName, 28)), snippetContextStyle.Render(truncateString(f.Path, max(m.width-80, 20)))))
+	}
+	left := lipgloss.NewStyle().Width(50).Render(strings.Join(rules, "\n"))
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, left, strings.Join(recent, "\n")))
+	b.WriteString("\n")
+
+	if !m.done {
+		b.WriteString(helpKeyStyle.Render("ctrl+c") + " " + helpDescStyle.Render("stop scan") + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,49 @@
+package explore
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScanStatsString(t *testing.T) {
+	s := ScanStats{
+		Elapsed:     90*time.Second + 400*time.Millisecond,
+		Blobs:       12,
+		Bytes:       3 << 20,
+		Matches:     5,
+		Findings:    4,
+		BusyWorkers: 2,
+		Workers:     8,
+	}
+	want := "1m30s: 12 blobs, 3.0 MiB, 5 matches, 4 findings, 2/8 workers busy"
+	if got := s.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	s.Validating = true
+	s.ValidationQueue = 7
+	if got := s.String(); !strings.HasSuffix(got, ", 7 awaiting validation") {
+		t.Errorf("String() = %q, want validation queue", got)
+	}
+}
+
+func TestDashboardDone(t *testing.T) {
+	polls := 0
+	m := NewDashboard(func() ScanStats {
+		polls++
+		return ScanStats{Blobs: int64(polls)}
+	}, nil)
+
+	_, cmd := m.Update(DashboardDoneMsg{})
+	if cmd == nil {
+		t.Fatal("expected the dashboard to quit when the scan ends")
+	}
+	view := m.View()
+	if !strings.Contains(view, "2") {
+		t.Errorf("final view doesn't show the last stats: %q", view)
+	}
+	if strings.Contains(view, "stop scan") {
+		t.Errorf("final view still shows key help: %q", view)
+	}
+}
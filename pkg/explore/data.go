@@ -5,7 +5,11 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
+	"time"
 
+	"github.com/praetorian-inc/titus/pkg/filetype"
+	"github.com/praetorian-inc/titus/pkg/fpfilter"
 	"github.com/praetorian-inc/titus/pkg/rule"
 	"github.com/praetorian-inc/titus/pkg/store"
 	"github.com/praetorian-inc/titus/pkg/types"
@@ -16,6 +20,13 @@ type exploreData struct {
 	store    store.Store
 	ruleMap  map[string]*types.Rule
 	findings []*findingRow
+	author   string // recorded as the author of annotations
+
+	// Row IDs of the last match and finding loaded, and matches loaded
+	// before their finding, for following a scan that is still writing
+	lastMatch   int64
+	lastFinding int64
+	orphans     []*matchRow
 }
 
 // loadData opens a datastore and loads all findings, matches, provenance, and annotations.
@@ -49,29 +60,20 @@ func loadData(storePath string) (*exploreData, error) {
 		ruleMap[r.ID] = r
 	}
 
-	// Load findings (same as report.go:109-111)
-	findings, err := s.GetFindings()
+	// Load all matches, then all findings, so that the findings of the
+	// matches are loaded even while a scan is still writing
+	matches, lastMatch, err := s.GetMatchesSince(0)
 	if err != nil {
 		s.Close()
-		return nil, fmt.Errorf("retrieving findings: %w", err)
+		return nil, fmt.Errorf("retrieving matches: %w", err)
 	}
-
-	// Load all matches (same as report.go:114-116)
-	matches, err := s.GetAllMatches()
+	findings, lastFinding, err := s.GetFindingsSince(0)
 	if err != nil {
 		s.Close()
-		return nil, fmt.Errorf("retrieving matches: %w", err)
+		return nil, fmt.Errorf("retrieving findings: %w", err)
 	}
 
-	// Group matches by finding ID (same as report.go:buildFindingMatchMap)
-	matchesByFinding := make(map[string][]*types.Match)
-	for _, m := range matches {
-		r, ok := ruleMap[m.RuleID]
-		if ok {
-			findingID := types.ComputeFindingID(r.StructuralID, m.Groups)
-			matchesByFinding[findingID] = append(matchesByFinding[findingID], m)
-		}
-	}
+	matchesByFinding := groupMatches(findings, matches)
 
 	// Build view models
 	rows := make([]*findingRow, 0, len(findings))
@@ -82,20 +84,114 @@ func loadData(storePath string) (*exploreData, error) {
 	}
 
 	return &exploreData{
-		store:    s,
-		ruleMap:  ruleMap,
-		findings: rows,
+		store:       s,
+		ruleMap:     ruleMap,
+		findings:    rows,
+		lastMatch:   lastMatch,
+		lastFinding: lastFinding,
 	}, nil
 }
 
+// groupMatches groups matches by the ID of the finding they belong to
+// (same as report.go:buildFindingMatchMap).
+func groupMatches(findings []*types.Finding, matches []*types.Match) map[string][]*types.Match {
+	findingsByContent := make(map[string][]string, len(findings))
+	for _, f := range findings {
+		key := types.FindingContentKey(f.RuleID, f.Groups)
+		findingsByContent[key] = append(findingsByContent[key], f.ID)
+	}
+	matchesByFinding := make(map[string][]*types.Match)
+	for _, m := range matches {
+		for _, id := range findingsByContent[types.FindingContentKey(m.RuleID, m.Groups)] {
+			matchesByFinding[id] = append(matchesByFinding[id], m)
+		}
+	}
+	return matchesByFinding
+}
+
+// newRows holds what a scan still writing to the datastore has added
+// since it was last loaded.
+type newRows struct {
+	findings    []*findingRow // rows of new findings, without their matches
+	matches     []*matchRow
+	lastMatch   int64
+	lastFinding int64
+}
+
+// loadNew reads the matches and findings stored after the row IDs
+// lastMatch and lastFinding. It only reads the datastore, so that it can
+// run in the background; apply adds the rows to the loaded data.
+func loadNew(s store.Store, ruleMap map[string]*types.Rule, lastMatch, lastFinding int64) (*newRows, error) {
+	matches, lastMatch, err := s.GetMatchesSince(lastMatch)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving matches: %w", err)
+	}
+	findings, lastFinding, err := s.GetFindingsSince(lastFinding)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving findings: %w", err)
+	}
+	n := &newRows{lastMatch: lastMatch, lastFinding: lastFinding}
+	for _, f := range findings {
+		n.findings = append(n.findings, buildFindingRow(f, nil, ruleMap, s))
+	}
+	for _, m := range matches {
+		n.matches = append(n.matches, buildMatchRow(m, s))
+	}
+	return n, nil
+}
+
+// apply adds new findings to d.findings and new matches to the rows of
+// their findings, updating those in place so that views holding them see
+// the change. A match whose finding isn't loaded yet is kept until it is.
+// It returns the number of new findings and whether anything changed.
+func (d *exploreData) apply(n *newRows) (int, bool) {
+	d.lastMatch, d.lastFinding = n.lastMatch, n.lastFinding
+	d.findings = append(d.findings, n.findings...)
+
+	byContent := make(map[string][]*findingRow, len(d.findings))
+	for _, row := range d.findings {
+		key := types.FindingContentKey(row.RuleID, row.Groups)
+		byContent[key] = append(byContent[key], row)
+	}
+	added := make(map[*findingRow][]*matchRow)
+	var orphans []*matchRow
+	for _, mr := range append(d.orphans, n.matches...) {
+		rows := byContent[types.FindingContentKey(mr.RuleID, mr.Groups)]
+		if len(rows) == 0 {
+			orphans = append(orphans, mr)
+			continue
+		}
+		for _, row := range rows {
+			added[row] = append(added[row], mr)
+		}
+	}
+	d.orphans = orphans
+
+	for row, matches := range added {
+		row.setMatches(append(row.Matches, matches...))
+	}
+	return len(n.findings), len(n.findings) > 0 || len(added) > 0
+}
+
+// refresh loads and applies what a scan still writing to the datastore has
+// added since the last load.
+func (d *exploreData) refresh() (int, bool, error) {
+	n, err := loadNew(d.store, d.ruleMap, d.lastMatch, d.lastFinding)
+	if err != nil {
+		return 0, false, err
+	}
+	added, changed := d.apply(n)
+	return added, changed, nil
+}
+
 // buildFindingRow creates a findingRow from a Finding and its matches.
 func buildFindingRow(f *types.Finding, matches []*types.Match, ruleMap map[string]*types.Rule, s store.Store) *findingRow {
 	row := &findingRow{
-		FindingID:  f.ID,
-		RuleID:     f.RuleID,
-		RuleName:   f.RuleID, // fallback
-		Groups:     f.Groups,
-		MatchCount: len(matches),
+		FindingID: f.ID,
+		RuleID:    f.RuleID,
+		RuleName:  f.RuleID, // fallback
+		Groups:    f.Groups,
+		State:     f.State,
 	}
 
 	// Populate rule metadata
@@ -104,33 +200,6 @@ func buildFindingRow(f *types.Finding, matches []*types.Match, ruleMap map[strin
 		row.Categories = r.Categories
 	}
 
-	// Aggregate validation status from matches
-	var totalConf float64
-	var confCount int
-	statusCounts := make(map[string]int)
-	for _, m := range matches {
-		if m.ValidationResult != nil {
-			statusCounts[string(m.ValidationResult.Status)]++
-			totalConf += m.ValidationResult.Confidence
-			confCount++
-		}
-	}
-	if confCount > 0 {
-		row.Confidence = totalConf / float64(confCount)
-	}
-	// Pick dominant validation status
-	if len(statusCounts) == 1 {
-		for status := range statusCounts {
-			row.ValidationStatus = status
-		}
-	} else if statusCounts["valid"] > 0 {
-		row.ValidationStatus = "valid"
-	} else if statusCounts["invalid"] > 0 {
-		row.ValidationStatus = "invalid"
-	} else if statusCounts["undetermined"] > 0 {
-		row.ValidationStatus = "undetermined"
-	}
-
 	// Load annotation for this finding
 	if s != nil {
 		status, comment, err := s.GetAnnotation("finding", f.ID)
@@ -140,11 +209,30 @@ func buildFindingRow(f *types.Finding, matches []*types.Match, ruleMap map[strin
 		}
 	}
 
-	// Build match rows
-	row.Matches = make([]*matchRow, 0, len(matches))
+	matchRows := make([]*matchRow, 0, len(matches))
 	for _, m := range matches {
-		mr := buildMatchRow(m, s)
-		row.Matches = append(row.Matches, mr)
+		matchRows = append(matchRows, buildMatchRow(m, s))
+	}
+	row.setMatches(matchRows)
+	return row
+}
+
+// setMatches sets the match rows of row, and what it shows of them.
+func (row *findingRow) setMatches(matches []*matchRow) {
+	row.Matches = matches
+	row.MatchCount = len(matches)
+
+	// The finding is a likely false positive only if all of its matches are
+	row.LikelyFP = len(matches) > 0
+	for _, mr := range matches {
+		row.LikelyFP = row.LikelyFP && mr.LikelyFP
+	}
+	row.aggregateValidation()
+
+	// Estimate when the secret was introduced from commit dates
+	row.Introduced = time.Time{}
+	for _, mr := range row.Matches {
+		row.introducedAt(types.EarliestCommit(mr.Provenance))
 	}
 
 	// Extract unique repository paths from match provenance
@@ -163,7 +251,81 @@ func buildFindingRow(f *types.Finding, matches []*types.Match, ruleMap map[strin
 	sort.Strings(repos)
 	row.Repositories = repos
 
-	return row
+	// Extract unique file types from match provenance
+	typeSet := make(map[string]struct{})
+	for _, mr := range row.Matches {
+		for _, prov := range mr.Provenance {
+			if ft := filetype.Of(prov); ft != "" {
+				typeSet[ft] = struct{}{}
+			}
+		}
+	}
+	fileTypes := make([]string, 0, len(typeSet))
+	for ft := range typeSet {
+		fileTypes = append(fileTypes, ft)
+	}
+	sort.Strings(fileTypes)
+	row.FileTypes = fileTypes
+
+	// Extract the directories of match provenance paths; git paths are
+	// placed under their repository
+	dirSet := make(map[string]struct{})
+	for _, mr := range row.Matches {
+		for _, prov := range mr.Provenance {
+			path := prov.Path()
+			if gp, ok := prov.(types.GitProvenance); ok && gp.RepoPath != "" {
+				path = strings.TrimSuffix(gp.RepoPath, "/") + "/" + path
+			}
+			for _, dir := range pathDirs(path) {
+				dirSet[dir] = struct{}{}
+			}
+		}
+	}
+	dirs := make([]string, 0, len(dirSet))
+	for dir := range dirSet {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	row.Dirs = dirs
+}
+
+// aggregateValidation sets the validation status and confidence of a
+// finding from those of its matches.
+func (row *findingRow) aggregateValidation() {
+	var totalConf float64
+	var confCount int
+	statusCounts := make(map[string]int)
+	for _, mr := range row.Matches {
+		if mr.ValidationStatus != "" {
+			statusCounts[mr.ValidationStatus]++
+			totalConf += mr.Confidence
+			confCount++
+		}
+	}
+	row.Confidence = 0
+	if confCount > 0 {
+		row.Confidence = totalConf / float64(confCount)
+	}
+	// Pick dominant validation status
+	row.ValidationStatus = ""
+	if len(statusCounts) == 1 {
+		for status := range statusCounts {
+			row.ValidationStatus = status
+		}
+	} else if statusCounts["valid"] > 0 {
+		row.ValidationStatus = "valid"
+	} else if statusCounts["invalid"] > 0 {
+		row.ValidationStatus = "invalid"
+	} else if statusCounts["undetermined"] > 0 {
+		row.ValidationStatus = "undetermined"
+	}
+}
+
+// introducedAt moves the row's Introduced date back to t if t is earlier.
+func (row *findingRow) introducedAt(t time.Time) {
+	if !t.IsZero() && (row.Introduced.IsZero() || t.Before(row.Introduced)) {
+		row.Introduced = t
+	}
 }
 
 // buildMatchRow creates a matchRow from a Match.
@@ -171,6 +333,7 @@ func buildMatchRow(m *types.Match, s store.Store) *matchRow {
 	mr := &matchRow{
 		StructuralID: m.StructuralID,
 		BlobID:       m.BlobID,
+		RuleID:       m.RuleID,
 		RuleName:     m.RuleName,
 		Location:     m.Location,
 		Groups:       m.Groups,
@@ -199,6 +362,13 @@ func buildMatchRow(m *types.Match, s store.Store) *matchRow {
 		}
 	}
 
+	paths := make([]string, 0, len(mr.Provenance))
+	for _, prov := range mr.Provenance {
+		paths = append(paths, prov.Path())
+	}
+	mr.FPReasons = fpfilter.New().Reasons(m, paths)
+	mr.LikelyFP = len(mr.FPReasons) > 0
+
 	return mr
 }
 
@@ -211,11 +381,57 @@ func (d *exploreData) close() error {
 }
 
 // setFindingAnnotation persists a finding annotation and updates the view model.
+// Accepting or rejecting a finding also moves it to the triaged state.
 func (d *exploreData) setFindingAnnotation(findingID, status, comment string) error {
-	return d.store.SetAnnotation("finding", findingID, status, comment)
+	if err := d.putAnnotation("finding", findingID, status, comment); err != nil {
+		return err
+	}
+	if status == "" {
+		return nil
+	}
+	for _, row := range d.findings {
+		if row.FindingID != findingID {
+			continue
+		}
+		if state := row.State.Triaged(); state != row.State {
+			if err := d.store.SetFindingState(findingID, state); err != nil {
+				return err
+			}
+			row.State = state
+		}
+	}
+	return nil
+}
+
+// setMatchValidation persists the result of validating a match and updates
+// the view models of the match and its finding.
+func (d *exploreData) setMatchValidation(f *findingRow, mr *matchRow, result *types.ValidationResult) error {
+	if err := d.store.SetMatchValidation(mr.StructuralID, result); err != nil {
+		return err
+	}
+	mr.ValidationStatus = string(result.Status)
+	mr.Confidence = result.Confidence
+	mr.Message = result.Message
+	f.aggregateValidation()
+	for _, member := range f.Members {
+		member.aggregateValidation()
+	}
+	return nil
 }
 
 // setMatchAnnotation persists a match annotation and updates the view model.
 func (d *exploreData) setMatchAnnotation(matchID, status, comment string) error {
-	return d.store.SetAnnotation("match", matchID, status, comment)
+	return d.putAnnotation("match", matchID, status, comment)
+}
+
+// putAnnotation persists an annotation made now by d.author.
+func (d *exploreData) putAnnotation(targetType, targetID, status, comment string) error {
+	return d.store.PutAnnotation(&types.Annotation{
+		TargetType: targetType,
+		TargetID:   targetID,
+		Status:     status,
+		Comment:    comment,
+		Author:     d.author,
+		UpdatedAt:  time.Now(),
+	})
 }
@@ -3,10 +3,13 @@ package explore
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
+	"github.com/praetorian-inc/titus/pkg/filetype"
+	"github.com/praetorian-inc/titus/pkg/style"
 	"github.com/praetorian-inc/titus/pkg/types"
 )
 
@@ -118,6 +121,11 @@ func (dp detailsPane) View() string {
 			}
 		}
 
+		if !f.Introduced.IsZero() {
+			lines = append(lines, fmt.Sprintf("  %s %s",
+				fieldLabelStyle.Render("Introduced:"),
+				fieldValueStyle.Render(fmt.Sprintf("%s (%s ago)", f.Introduced.Format("2006-01-02"), style.Age(f.Introduced, time.Now(), true)))))
+		}
 		if f.AnnotationStatus != "" {
 			lines = append(lines, fmt.Sprintf("  %s %s",
 				fieldLabelStyle.Render("Status:"),
@@ -188,6 +196,16 @@ func (dp detailsPane) View() string {
 	return lipgloss.JoinVertical(lipgloss.Left, title, content)
 }
 
+// appendFileType adds a Type line for provenance of a known file type.
+func appendFileType(lines []string, prov types.Provenance) []string {
+	if ft := filetype.Of(prov); ft != "" && ft != filetype.Unknown {
+		lines = append(lines, fmt.Sprintf("  %s %s",
+			fieldLabelStyle.Render("Type:"),
+			fieldValueStyle.Render(ft)))
+	}
+	return lines
+}
+
 func renderMatchDetails(m *matchRow, maxWidth int) []string {
 	var lines []string
 
@@ -199,6 +217,7 @@ func renderMatchDetails(m *matchRow, maxWidth int) []string {
 				lines = append(lines, fmt.Sprintf("  %s %s",
 					fieldLabelStyle.Render("File:"),
 					fieldValueStyle.Render(p.FilePath)))
+				lines = appendFileType(lines, p)
 			case types.GitProvenance:
 				lines = append(lines, fmt.Sprintf("  %s %s",
 					fieldLabelStyle.Render("Repo:"),
@@ -206,6 +225,7 @@ func renderMatchDetails(m *matchRow, maxWidth int) []string {
 				lines = append(lines, fmt.Sprintf("  %s %s",
 					fieldLabelStyle.Render("Path:"),
 					fieldValueStyle.Render(p.BlobPath)))
+				lines = appendFileType(lines, p)
 				if p.Commit != nil {
 					lines = append(lines, fmt.Sprintf("  %s %s",
 						fieldLabelStyle.Render("Commit:"),
@@ -254,6 +274,13 @@ func renderMatchDetails(m *matchRow, maxWidth int) []string {
 		lines = append(lines, valLine)
 	}
 
+	// False positive heuristics
+	if m.LikelyFP {
+		lines = append(lines, fmt.Sprintf("  %s %s",
+			fieldLabelStyle.Render("Likely FP:"),
+			fieldValueStyle.Render(strings.Join(m.FPReasons, ", "))))
+	}
+
 	// Named groups
 	if len(m.NamedGroups) > 0 {
 		lines = append(lines, fmt.Sprintf("  %s", fieldLabelStyle.Render("Named Groups:")))
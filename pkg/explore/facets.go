@@ -1,7 +1,10 @@
 package explore
 
 import (
+	"path/filepath"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/praetorian-inc/titus/pkg/types"
 )
@@ -14,6 +17,9 @@ const (
 	facetCategory
 	facetValidation
 	facetRepository
+	facetPath
+	facetFileType
+	facetLikelyFP
 )
 
 // facetDef defines a facet category.
@@ -27,6 +33,9 @@ var facetDefs = []facetDef{
 	{facetCategory, "Category"},
 	{facetValidation, "Validation"},
 	{facetRepository, "Repository"},
+	{facetPath, "Path"},
+	{facetFileType, "File Type"},
+	{facetLikelyFP, "Likely FP"},
 }
 
 // facetValue is a single selectable value within a facet.
@@ -35,6 +44,11 @@ type facetValue struct {
 	Value    string
 	Count    int
 	Selected bool
+
+	// Path facet values form a directory tree: Label is the part of Value
+	// below the parent directory, and Depth the number of ancestors.
+	Label string
+	Depth int
 }
 
 // facetState holds the complete filter state.
@@ -57,6 +71,9 @@ func buildFacets(findings []*findingRow) *facetState {
 	categories := make(map[string]int)
 	validations := make(map[string]int)
 	repositories := make(map[string]int)
+	fileTypes := make(map[string]int)
+	dirs := make(map[string]int)
+	likelyFPs := make(map[string]int)
 
 	for _, f := range findings {
 		ruleNames[f.RuleName]++
@@ -74,16 +91,37 @@ func buildFacets(findings []*findingRow) *facetState {
 		for _, repo := range f.Repositories {
 			repositories[repo]++
 		}
+
+		for _, ft := range f.FileTypes {
+			fileTypes[ft]++
+		}
+
+		for _, dir := range f.Dirs {
+			dirs[dir]++
+		}
+
+		likelyFPs[likelyFPValue(f)]++
 	}
 
 	fs.Values[facetRuleName] = mapToFacetValues(facetRuleName, ruleNames)
 	fs.Values[facetCategory] = mapToFacetValues(facetCategory, categories)
 	fs.Values[facetValidation] = mapToFacetValues(facetValidation, validations)
 	fs.Values[facetRepository] = mapToFacetValues(facetRepository, repositories)
+	fs.Values[facetPath] = dirTreeValues(dirs)
+	fs.Values[facetFileType] = mapToFacetValues(facetFileType, fileTypes)
+	fs.Values[facetLikelyFP] = mapToFacetValues(facetLikelyFP, likelyFPs)
 
 	return fs
 }
 
+// likelyFPValue returns the Likely FP facet value for a finding.
+func likelyFPValue(f *findingRow) string {
+	if f.LikelyFP {
+		return "yes"
+	}
+	return "no"
+}
+
 func mapToFacetValues(id facetID, counts map[string]int) []*facetValue {
 	values := make([]*facetValue, 0, len(counts))
 	for v, c := range counts {
@@ -95,6 +133,66 @@ func mapToFacetValues(id facetID, counts map[string]int) []*facetValue {
 	return values
 }
 
+// dirTreeValues builds the path facet from the number of findings under
+// each directory, in tree order. A directory with a single subdirectory
+// holding all of its findings is folded into it, so deep common prefixes
+// such as /home/user/src/ take one row.
+func dirTreeValues(counts map[string]int) []*facetValue {
+	dirs := make([]string, 0, len(counts))
+	children := make(map[string]int)
+	lastChild := make(map[string]string)
+	for dir := range counts {
+		dirs = append(dirs, dir)
+		parent := parentDir(dir)
+		children[parent]++
+		lastChild[parent] = dir
+	}
+	// With trailing slashes, byte order lists every directory before its
+	// subdirectories
+	sort.Strings(dirs)
+
+	values := make([]*facetValue, 0, len(dirs))
+	var ancestors []*facetValue
+	for _, dir := range dirs {
+		if children[dir] == 1 && counts[lastChild[dir]] == counts[dir] {
+			continue
+		}
+		for len(ancestors) > 0 && !strings.HasPrefix(dir, ancestors[len(ancestors)-1].Value) {
+			ancestors = ancestors[:len(ancestors)-1]
+		}
+		label := dir
+		if len(ancestors) > 0 {
+			label = strings.TrimPrefix(dir, ancestors[len(ancestors)-1].Value)
+		}
+		v := &facetValue{FacetID: facetPath, Value: dir, Count: counts[dir], Label: label, Depth: len(ancestors)}
+		values = append(values, v)
+		ancestors = append(ancestors, v)
+	}
+	return values
+}
+
+// pathDirs returns the directories containing path, outermost first, each
+// with a trailing slash.
+func pathDirs(path string) []string {
+	path = filepath.ToSlash(path)
+	var dirs []string
+	for i := 1; i < len(path); i++ {
+		if path[i] == '/' && path[i-1] != '/' {
+			dirs = append(dirs, path[:i+1])
+		}
+	}
+	return dirs
+}
+
+// parentDir returns the directory containing dir, or "" for a top-level one.
+func parentDir(dir string) string {
+	i := strings.LastIndexByte(strings.TrimSuffix(dir, "/"), '/')
+	if i <= 0 {
+		return ""
+	}
+	return dir[:i+1]
+}
+
 // selectedValues returns the set of selected values for a facet.
 func (fs *facetState) selectedValues(id facetID) map[string]bool {
 	selected := make(map[string]bool)
@@ -106,6 +204,17 @@ func (fs *facetState) selectedValues(id facetID) map[string]bool {
 	return selected
 }
 
+// keepSelections selects the values of fs that were selected in prev, so
+// rebuilding the facets when findings change keeps the active filters.
+func (fs *facetState) keepSelections(prev *facetState) {
+	for id, values := range fs.Values {
+		selected := prev.selectedValues(id)
+		for _, v := range values {
+			v.Selected = selected[v.Value]
+		}
+	}
+}
+
 // hasActiveFilters returns true if any facet has selections.
 func (fs *facetState) hasActiveFilters() bool {
 	for _, values := range fs.Values {
@@ -171,6 +280,32 @@ func (fs *facetState) matchesFinding(f *findingRow) bool {
 			if !found {
 				return false
 			}
+		case facetPath:
+			found := false
+			for _, dir := range f.Dirs {
+				if selected[dir] {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		case facetFileType:
+			found := false
+			for _, ft := range f.FileTypes {
+				if selected[ft] {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		case facetLikelyFP:
+			if !selected[likelyFPValue(f)] {
+				return false
+			}
 		}
 	}
 	return true
@@ -220,6 +355,28 @@ func (fs *facetState) updateCounts(findings []*findingRow) {
 				}
 			}
 		}
+		for _, v := range fs.Values[facetPath] {
+			for _, dir := range f.Dirs {
+				if v.Value == dir {
+					v.Count++
+					break
+				}
+			}
+		}
+		for _, v := range fs.Values[facetFileType] {
+			for _, ft := range f.FileTypes {
+				if v.Value == ft {
+					v.Count++
+					break
+				}
+			}
+		}
+		likelyFP := likelyFPValue(f)
+		for _, v := range fs.Values[facetLikelyFP] {
+			if v.Value == likelyFP {
+				v.Count++
+			}
+		}
 	}
 }
 
@@ -231,19 +388,26 @@ type findingRow struct {
 	RuleName         string
 	Categories       []string
 	Repositories     []string // unique repo paths from match provenance
+	FileTypes        []string // unique file types from match provenance
+	Dirs             []string // unique directories of match provenance paths, with trailing slashes
 	Groups           [][]byte
 	MatchCount       int
 	ValidationStatus string  // aggregated: "valid", "invalid", "undetermined", or ""
 	Confidence       float64 // mean confidence across matches
 	AnnotationStatus string  // "accept", "reject", or ""
 	Comment          string
+	State            types.FindingState
+	LikelyFP         bool      // every match looks like test/example data
+	Introduced       time.Time // earliest commit date of its matches; zero if not in git history
 	Matches          []*matchRow
+	Members          []*findingRow // findings merged into this row when grouping by secret
 }
 
 // matchRow is the denormalized view model for a match.
 type matchRow struct {
 	StructuralID     string
 	BlobID           types.BlobID
+	RuleID           string
 	RuleName         string
 	Location         types.Location
 	Groups           [][]byte
@@ -255,4 +419,6 @@ type matchRow struct {
 	Provenance       []types.Provenance
 	AnnotationStatus string
 	Comment          string
+	LikelyFP         bool
+	FPReasons        []string
 }
@@ -118,3 +118,103 @@ func TestFacetCrossFacetFiltering(t *testing.T) {
 		t.Error("expected Slack to NOT match (valid but chat, not cloud)")
 	}
 }
+
+func TestFacetFiltering_LikelyFP(t *testing.T) {
+	findings := []*findingRow{
+		{RuleName: "AWS API Key"},
+		{RuleName: "GitHub Token", LikelyFP: true},
+	}
+
+	fs := buildFacets(findings)
+	if len(fs.Values[facetLikelyFP]) != 2 { // no, yes
+		t.Fatalf("expected 2 likely FP values, got %d", len(fs.Values[facetLikelyFP]))
+	}
+
+	for _, v := range fs.Values[facetLikelyFP] {
+		if v.Value == "no" {
+			v.Selected = true
+		}
+	}
+
+	if !fs.matchesFinding(findings[0]) {
+		t.Error("expected AWS to match likely FP = no")
+	}
+	if fs.matchesFinding(findings[1]) {
+		t.Error("expected GitHub not to match likely FP = no")
+	}
+}
+
+func TestFacetFiltering_FileType(t *testing.T) {
+	findings := []*findingRow{
+		{RuleName: "AWS API Key", FileTypes: []string{"Terraform"}},
+		{RuleName: "GitHub Token", FileTypes: []string{"Markdown"}},
+		{RuleName: "Slack Token", FileTypes: []string{"Markdown", "YAML"}},
+	}
+
+	fs := buildFacets(findings)
+	if len(fs.Values[facetFileType]) != 3 { // Markdown, Terraform, YAML
+		t.Fatalf("expected 3 file types, got %d", len(fs.Values[facetFileType]))
+	}
+	for _, v := range fs.Values[facetFileType] {
+		if v.Value == "Terraform" || v.Value == "YAML" {
+			v.Selected = true
+		}
+	}
+
+	if !fs.matchesFinding(findings[0]) {
+		t.Error("expected Terraform finding to match")
+	}
+	if fs.matchesFinding(findings[1]) {
+		t.Error("expected Markdown-only finding not to match")
+	}
+	if !fs.matchesFinding(findings[2]) {
+		t.Error("expected finding also in YAML to match")
+	}
+}
+
+func TestFacetFiltering_Path(t *testing.T) {
+	findings := []*findingRow{
+		{RuleName: "AWS API Key", Dirs: pathDirs("/src/mono/services/billing/config.yml")},
+		{RuleName: "GitHub Token", Dirs: pathDirs("/src/mono/services/auth/.env")},
+		{RuleName: "Slack Token", Dirs: pathDirs("/src/mono/tools/notify.sh")},
+	}
+
+	fs := buildFacets(findings)
+	type node struct {
+		label string
+		depth int
+		count int
+	}
+	var got []node
+	for _, v := range fs.Values[facetPath] {
+		got = append(got, node{v.Label, v.Depth, v.Count})
+	}
+	// /src/ and /src/mono/ hold every finding, so they fold into one row
+	want := []node{
+		{"/src/mono/", 0, 3},
+		{"services/", 1, 2},
+		{"auth/", 2, 1},
+		{"billing/", 2, 1},
+		{"tools/", 1, 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("path facet = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("path facet[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	for _, v := range fs.Values[facetPath] {
+		if v.Value == "/src/mono/services/" {
+			v.Selected = true
+		}
+	}
+	if !fs.matchesFinding(findings[0]) || !fs.matchesFinding(findings[1]) {
+		t.Error("expected findings under services/ to match")
+	}
+	if fs.matchesFinding(findings[2]) {
+		t.Error("expected finding under tools/ not to match")
+	}
+}
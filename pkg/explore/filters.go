@@ -18,6 +18,9 @@ type filterPane struct {
 	height  int
 	offset  int // scroll offset
 	focused bool
+
+	collapsed    map[facetID]bool // facets whose values are hidden
+	expandedDirs map[string]bool  // path facet directories whose subdirectories are shown
 }
 
 type filterItemKind int
@@ -33,17 +36,24 @@ type filterItem struct {
 	FacetID  facetID
 	ValueIdx int // index into facets.Values[FacetID]
 	Expanded bool
+
+	// Path facet directories
+	Depth       int
+	HasChildren bool
 }
 
 func newFilterPane(facets *facetState) filterPane {
 	fp := filterPane{
-		facets: facets,
+		facets:       facets,
+		collapsed:    make(map[facetID]bool),
+		expandedDirs: make(map[string]bool),
 	}
 	fp.rebuildItems()
 	return fp
 }
 
-// rebuildItems flattens the facet tree into a list of items.
+// rebuildItems flattens the facet tree into a list of items. Path facet
+// directories start collapsed, showing only the top of the tree.
 func (fp *filterPane) rebuildItems() {
 	fp.items = nil
 	for _, def := range facetDefs {
@@ -55,19 +65,35 @@ func (fp *filterPane) rebuildItems() {
 			Kind:     filterItemCategory,
 			Label:    def.Label,
 			FacetID:  def.ID,
-			Expanded: true,
+			Expanded: !fp.collapsed[def.ID],
 		})
-		// Find the category item we just added to check expanded
-		catIdx := len(fp.items) - 1
-		if fp.items[catIdx].Expanded {
-			for i, v := range values {
-				fp.items = append(fp.items, filterItem{
-					Kind:     filterItemValue,
-					Label:    v.Value,
-					FacetID:  def.ID,
-					ValueIdx: i,
-				})
+		if fp.collapsed[def.ID] {
+			continue
+		}
+		hideBelow := -1 // depth of a collapsed directory whose subdirectories are skipped
+		for i, v := range values {
+			if hideBelow >= 0 && v.Depth > hideBelow {
+				continue
 			}
+			hideBelow = -1
+			item := filterItem{
+				Kind:     filterItemValue,
+				Label:    v.Value,
+				FacetID:  def.ID,
+				ValueIdx: i,
+				Depth:    v.Depth,
+			}
+			if v.Label != "" {
+				item.Label = v.Label
+			}
+			if i+1 < len(values) && values[i+1].Depth > v.Depth {
+				item.HasChildren = true
+				item.Expanded = fp.expandedDirs[v.Value]
+				if !item.Expanded {
+					hideBelow = v.Depth
+				}
+			}
+			fp.items = append(fp.items, item)
 		}
 	}
 }
@@ -104,6 +130,10 @@ func (fp filterPane) Update(msg tea.Msg) (filterPane, tea.Cmd) {
 			fp.ensureVisible()
 		case keyMatches(msg, defaultKeys.ToggleFilter):
 			fp.toggleCurrent()
+		case keyMatches(msg, defaultKeys.Right):
+			fp.setExpanded(true)
+		case keyMatches(msg, defaultKeys.Left):
+			fp.setExpanded(false)
 		case keyMatches(msg, defaultKeys.ResetFilter):
 			fp.facets.resetAll()
 		}
@@ -119,7 +149,7 @@ func (fp *filterPane) toggleCurrent() {
 	item := &fp.items[fp.cursor]
 	switch item.Kind {
 	case filterItemCategory:
-		item.Expanded = !item.Expanded
+		fp.collapsed[item.FacetID] = !fp.collapsed[item.FacetID]
 		fp.rebuildItems()
 		// Keep cursor on category
 		for i, it := range fp.items {
@@ -136,6 +166,41 @@ func (fp *filterPane) toggleCurrent() {
 	}
 }
 
+// setExpanded expands or collapses the facet or directory under the cursor.
+// Collapsing a directory that is already collapsed, or has no
+// subdirectories, moves the cursor to its parent.
+func (fp *filterPane) setExpanded(expand bool) {
+	if fp.cursor < 0 || fp.cursor >= len(fp.items) {
+		return
+	}
+	item := fp.items[fp.cursor]
+	switch {
+	case item.Kind == filterItemCategory:
+		fp.collapsed[item.FacetID] = !expand
+	case item.HasChildren && item.Expanded != expand:
+		fp.expandedDirs[fp.facets.Values[item.FacetID][item.ValueIdx].Value] = expand
+	case !expand && item.Depth > 0:
+		for i := fp.cursor - 1; i >= 0; i-- {
+			if fp.items[i].Depth < item.Depth {
+				fp.cursor = i
+				fp.ensureVisible()
+				break
+			}
+		}
+		return
+	default:
+		return
+	}
+	fp.rebuildItems()
+	for i, it := range fp.items {
+		if it.Kind == item.Kind && it.FacetID == item.FacetID && it.ValueIdx == item.ValueIdx {
+			fp.cursor = i
+			break
+		}
+	}
+	fp.ensureVisible()
+}
+
 func (fp filterPane) View() string {
 	if fp.width <= 0 || fp.height <= 0 {
 		return ""
@@ -169,12 +234,22 @@ func (fp filterPane) View() string {
 					marker = " "
 				}
 			}
-			label := truncateString(item.Label, fp.width-12)
+			tree := ""
+			if item.FacetID == facetPath {
+				tree = strings.Repeat("  ", item.Depth) + "  "
+				if item.HasChildren {
+					tree = strings.Repeat("  ", item.Depth) + "▸ "
+					if item.Expanded {
+						tree = strings.Repeat("  ", item.Depth) + "▾ "
+					}
+				}
+			}
+			label := truncateString(item.Label, fp.width-12-lipgloss.Width(tree))
 			countStr := facetCountStyle.Render(fmt.Sprintf("(%d)", count))
 			if marker == "+" {
-				line = fmt.Sprintf("   %s %s %s", facetSelectedStyle.Render(marker), facetSelectedStyle.Render(label), countStr)
+				line = fmt.Sprintf("   %s %s%s %s", facetSelectedStyle.Render(marker), tree, facetSelectedStyle.Render(label), countStr)
 			} else {
-				line = fmt.Sprintf("   %s %s %s", marker, label, countStr)
+				line = fmt.Sprintf("   %s %s%s %s", marker, tree, label, countStr)
 			}
 		}
 
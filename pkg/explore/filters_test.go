@@ -59,3 +59,53 @@ func TestSanitizeForDisplay(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterPane_PathTree(t *testing.T) {
+	findings := []*findingRow{
+		{RuleName: "AWS API Key", Dirs: pathDirs("app/services/billing/config.yml")},
+		{RuleName: "GitHub Token", Dirs: pathDirs("app/services/auth/.env")},
+		{RuleName: "Slack Token", Dirs: pathDirs("tools/notify.sh")},
+	}
+	fp := newFilterPane(buildFacets(findings))
+
+	pathLabels := func() []string {
+		var labels []string
+		for _, it := range fp.items {
+			if it.Kind == filterItemValue && it.FacetID == facetPath {
+				labels = append(labels, it.Label)
+			}
+		}
+		return labels
+	}
+	cursorTo := func(label string) {
+		for i, it := range fp.items {
+			if it.FacetID == facetPath && it.Label == label {
+				fp.cursor = i
+				return
+			}
+		}
+		t.Fatalf("no path item %q", label)
+	}
+
+	// Directories start collapsed
+	if got := pathLabels(); len(got) != 2 || got[0] != "app/services/" || got[1] != "tools/" {
+		t.Fatalf("collapsed tree = %v", got)
+	}
+
+	cursorTo("app/services/")
+	fp.setExpanded(true)
+	if got := pathLabels(); len(got) != 4 || got[1] != "auth/" || got[2] != "billing/" {
+		t.Fatalf("expanded tree = %v", got)
+	}
+
+	// Collapsing a leaf moves to its parent, collapsing that hides the leaves
+	cursorTo("billing/")
+	fp.setExpanded(false)
+	if fp.items[fp.cursor].Label != "app/services/" {
+		t.Errorf("cursor on %q, want the parent", fp.items[fp.cursor].Label)
+	}
+	fp.setExpanded(false)
+	if got := pathLabels(); len(got) != 2 {
+		t.Errorf("collapsed tree = %v", got)
+	}
+}
@@ -3,9 +3,11 @@ package explore
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/praetorian-inc/titus/pkg/style"
 )
 
 // sortField defines which column to sort by.
@@ -17,11 +19,12 @@ const (
 	sortByValidation
 	sortByConfidence
 	sortByStatus
+	sortByAge
 	sortFieldCount // sentinel
 )
 
 var sortFieldNames = [sortFieldCount]string{
-	"Rule Name", "Matches", "Validation", "Confidence", "Status",
+	"Rule Name", "Matches", "Validation", "Confidence", "Status", "Age",
 }
 
 // findingsPane is the top-right findings table.
@@ -43,6 +46,7 @@ type findingsPane struct {
 	colValidation int
 	colConfidence int
 	colStatus     int
+	colAge        int
 }
 
 func newFindingsPane(rows []*findingRow) findingsPane {
@@ -121,6 +125,14 @@ func (fp *findingsPane) sort() {
 		sortSlice(fp.rows, func(a, b *findingRow) bool { return a.Confidence < b.Confidence }, fp.sortAsc)
 	case sortByStatus:
 		sortSlice(fp.rows, func(a, b *findingRow) bool { return a.AnnotationStatus < b.AnnotationStatus }, fp.sortAsc)
+	case sortByAge:
+		// Youngest first; findings of unknown age count as oldest
+		sortSlice(fp.rows, func(a, b *findingRow) bool {
+			if a.Introduced.IsZero() || b.Introduced.IsZero() {
+				return !a.Introduced.IsZero() && b.Introduced.IsZero()
+			}
+			return a.Introduced.After(b.Introduced)
+		}, fp.sortAsc)
 	}
 }
 
@@ -151,8 +163,9 @@ func (fp findingsPane) View() string {
 	fp.colValidation = 8
 	fp.colConfidence = 8
 	fp.colStatus = 8
+	fp.colAge = 6
 	fp.colGroups = min(30, contentWidth/4)
-	fp.colRuleName = contentWidth - fp.colGroups - fp.colMatches - fp.colValidation - fp.colConfidence - fp.colStatus - 5 // separators
+	fp.colRuleName = contentWidth - fp.colGroups - fp.colMatches - fp.colValidation - fp.colConfidence - fp.colStatus - fp.colAge - 6 // separators
 	if fp.colRuleName < 10 {
 		fp.colRuleName = 10
 	}
@@ -170,13 +183,14 @@ func (fp findingsPane) View() string {
 		return ""
 	}
 
-	header := fmt.Sprintf(" %-*s %-*s %*s %-*s %*s %-*s",
+	header := fmt.Sprintf(" %-*s %-*s %*s %-*s %*s %-*s %*s",
 		fp.colRuleName, "Rule Name"+sortIndicator(sortByRuleName),
 		fp.colGroups, "Groups",
 		fp.colMatches, "Matches"+sortIndicator(sortByMatches),
 		fp.colValidation, "Valid"+sortIndicator(sortByValidation),
 		fp.colConfidence, "Conf"+sortIndicator(sortByConfidence),
 		fp.colStatus, "Status"+sortIndicator(sortByStatus),
+		fp.colAge, "Age"+sortIndicator(sortByAge),
 	)
 	b.WriteString(headerRowStyle.Width(contentWidth).Render(truncateString(header, contentWidth)))
 	b.WriteString("\n")
@@ -186,6 +200,7 @@ func (fp findingsPane) View() string {
 	b.WriteString("\n")
 
 	// Data rows
+	now := time.Now()
 	visibleEnd := min(fp.offset+fp.visibleRows(), len(fp.rows))
 	for i := fp.offset; i < visibleEnd; i++ {
 		row := fp.rows[i]
@@ -198,14 +213,19 @@ func (fp findingsPane) View() string {
 			confStr = fmt.Sprintf("%.2f", row.Confidence)
 		}
 		statusStr := renderAnnotationStatus(row.AnnotationStatus)
+		ageStr := ""
+		if !row.Introduced.IsZero() {
+			ageStr = style.Age(row.Introduced, now, true)
+		}
 
-		line := fmt.Sprintf(" %-*s %-*s %*d %-*s %*s %-*s",
+		line := fmt.Sprintf(" %-*s %-*s %*d %-*s %*s %-*s %*s",
 			fp.colRuleName, truncateString(row.RuleName, fp.colRuleName),
 			fp.colGroups, groupStr,
 			fp.colMatches, row.MatchCount,
 			fp.colValidation, valStr,
 			fp.colConfidence, confStr,
 			fp.colStatus, statusStr,
+			fp.colAge, ageStr,
 		)
 
 		if isCurrent && fp.focused {
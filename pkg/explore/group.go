@@ -0,0 +1,111 @@
+package explore
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// groupRowsBySecret merges findings of different rules that captured the
+// same secret (see types.SecretValue) into one row listing every rule and
+// match. A row that merges several findings keeps them as Members, so
+// annotating it annotates each of them. Findings without capture groups,
+// and secrets only one finding has, keep their own rows.
+func groupRowsBySecret(rows []*findingRow) []*findingRow {
+	bySecret := make(map[string][]*findingRow)
+	for _, f := range rows {
+		if secret := string(types.SecretValue(f.Groups)); secret != "" {
+			bySecret[secret] = append(bySecret[secret], f)
+		}
+	}
+	var grouped []*findingRow
+	for _, f := range rows {
+		secret := string(types.SecretValue(f.Groups))
+		members := bySecret[secret]
+		switch {
+		case len(members) <= 1:
+			grouped = append(grouped, f)
+		case members[0] == f:
+			grouped = append(grouped, mergeRows([]byte(secret), members))
+		}
+	}
+	return grouped
+}
+
+// mergeRows builds the row of a secret caught by several findings.
+func mergeRows(secret []byte, members []*findingRow) *findingRow {
+	row := &findingRow{
+		FindingID:        members[0].FindingID,
+		Groups:           [][]byte{secret},
+		State:            members[0].State,
+		AnnotationStatus: members[0].AnnotationStatus,
+		Comment:          members[0].Comment,
+		LikelyFP:         true,
+		Members:          members,
+	}
+	var ruleIDs, ruleNames, categories, repos, fileTypes, dirs []string
+	for _, f := range members {
+		ruleIDs = appendUnique(ruleIDs, f.RuleID)
+		ruleNames = appendUnique(ruleNames, f.RuleName)
+		categories = appendUnique(categories, f.Categories...)
+		repos = appendUnique(repos, f.Repositories...)
+		fileTypes = appendUnique(fileTypes, f.FileTypes...)
+		dirs = appendUnique(dirs, f.Dirs...)
+		row.MatchCount += f.MatchCount
+		row.Matches = append(row.Matches, f.Matches...)
+		row.LikelyFP = row.LikelyFP && f.LikelyFP
+		row.introducedAt(f.Introduced)
+		if f.AnnotationStatus != row.AnnotationStatus {
+			row.AnnotationStatus = ""
+		}
+		if f.Comment != row.Comment {
+			row.Comment = ""
+		}
+	}
+	row.RuleID = strings.Join(ruleIDs, ", ")
+	row.RuleName = strings.Join(ruleNames, ", ")
+	row.Categories = categories
+	sort.Strings(repos)
+	sort.Strings(fileTypes)
+	sort.Strings(dirs)
+	row.Repositories, row.FileTypes, row.Dirs = repos, fileTypes, dirs
+	row.aggregateValidation()
+	return row
+}
+
+// findingRows returns the findings a row shows: its members when it merges
+// several, else the row itself.
+func (f *findingRow) findingRows() []*findingRow {
+	if len(f.Members) > 0 {
+		return f.Members
+	}
+	return []*findingRow{f}
+}
+
+// hasFinding reports whether the row shows the finding with this ID.
+func (f *findingRow) hasFinding(id string) bool {
+	for _, member := range f.findingRows() {
+		if member.FindingID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// appendUnique appends the values not already in list.
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, have := range list {
+			if have == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
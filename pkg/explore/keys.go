@@ -29,6 +29,9 @@ type keyMap struct {
 	RejectNext key.Binding
 	Comment    key.Binding
 
+	// Validation
+	Validate key.Binding
+
 	// Views
 	OpenSource    key.Binding
 	ToggleHelp    key.Binding
@@ -40,6 +43,9 @@ type keyMap struct {
 	// Sort
 	SortNext key.Binding
 
+	// Grouping
+	GroupBySecret key.Binding
+
 	// Exclusion filter
 	Exclude key.Binding
 
@@ -126,6 +132,10 @@ var defaultKeys = keyMap{
 		key.WithKeys("c"),
 		key.WithHelp("c", "comment"),
 	),
+	Validate: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "validate"),
+	),
 	OpenSource: key.NewBinding(
 		key.WithKeys("o"),
 		key.WithHelp("o", "source"),
@@ -146,6 +156,10 @@ var defaultKeys = keyMap{
 		key.WithKeys("s"),
 		key.WithHelp("s", "sort"),
 	),
+	GroupBySecret: key.NewBinding(
+		key.WithKeys("b"),
+		key.WithHelp("b", "group by secret"),
+	),
 	Exclude: key.NewBinding(
 		key.WithKeys("/"),
 		key.WithHelp("/", "exclude"),
@@ -11,6 +11,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
 	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/praetorian-inc/titus/pkg/validator"
 )
 
 // focusedPane tracks which pane has keyboard focus.
@@ -39,6 +40,15 @@ type pagerFinishedMsg struct{ err error }
 // clearFlashMsg clears the flash status message after a delay.
 type clearFlashMsg struct{}
 
+// followTickMsg asks a following model to reload the datastore.
+type followTickMsg struct{}
+
+// reloadedMsg carries what a follow reload read from the datastore.
+type reloadedMsg struct {
+	rows *newRows
+	err  error
+}
+
 // Model is the root Bubble Tea model for the explore TUI.
 type Model struct {
 	data     *exploreData
@@ -78,6 +88,18 @@ type Model struct {
 	// Pending clipboard data (written via OSC 52 on next render)
 	pendingClipboard string
 
+	// Reload interval while following a scan that is still writing (0 = off)
+	followInterval time.Duration
+
+	// Grouping by secret: one row per secret value, across rules
+	groupBySecret bool
+	secretRows    []*findingRow
+
+	// Validation engine for on-demand validation (nil = unavailable)
+	validator  *validator.Engine
+	validating bool
+	pluginDir  string // validator plugins to load on first use ("" = none)
+
 	width  int
 	height int
 	err    error
@@ -113,10 +135,30 @@ func New(datastorePath string) (Model, error) {
 	return m, nil
 }
 
+// Follow makes the model reload the datastore every interval, adding the
+// findings that a scan still writing to it records, so triage can start
+// before the scan ends.
+func (m *Model) Follow(interval time.Duration) {
+	m.followInterval = interval
+}
+
+// SetAuthor sets the name recorded as the author of annotations made in
+// the TUI, so merged triage decisions can be told apart.
+func (m *Model) SetAuthor(author string) {
+	m.data.author = author
+}
+
 func (m Model) Init() tea.Cmd {
+	if m.followInterval > 0 {
+		return tea.Batch(tea.SetWindowTitle("titus explore"), m.followTick())
+	}
 	return tea.SetWindowTitle("titus explore")
 }
 
+func (m Model) followTick() tea.Cmd {
+	return tea.Tick(m.followInterval, func(time.Time) tea.Msg { return followTickMsg{} })
+}
+
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Clear pending clipboard after one render cycle
 	m.pendingClipboard = ""
@@ -136,6 +178,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.flashMsg = ""
 		return m, nil
 
+	case followTickMsg:
+		return m, m.reload()
+
+	case reloadedMsg:
+		return m, tea.Batch(m.refresh(msg), m.followTick())
+
+	case validatedMsg:
+		return m, m.applyValidation(msg)
+
 	case tea.MouseMsg:
 		if m.activeOverlay != overlayNone {
 			return m, nil
@@ -172,6 +223,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.showFilters = !m.showFilters
 			m.updateLayout()
 			return m, nil
+		case keyMatches(msg, defaultKeys.GroupBySecret):
+			m.groupBySecret = !m.groupBySecret
+			m.reapply()
+			return m, nil
 		case keyMatches(msg, defaultKeys.Exclude):
 			m.activeOverlay = overlayExclude
 			m.excludeInput = ""
@@ -225,6 +280,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case keyMatches(msg, defaultKeys.CopySecret):
 				cmd := m.copySecretToClipboard()
 				return m, cmd
+			case keyMatches(msg, defaultKeys.Validate):
+				cmd := m.validate()
+				return m, cmd
 			}
 		}
 
@@ -431,17 +489,26 @@ func (m *Model) renderStatusBar() string {
 		if len(m.excludePatterns) > 0 {
 			exclusionInfo = fmt.Sprintf(" | %d exclusion(s)", len(m.excludePatterns))
 		}
-		left = statusBarStyle.Render(fmt.Sprintf(" %d findings | %d filtered%s",
-			len(m.data.findings), len(m.findings.rows), exclusionInfo))
+		if m.followInterval > 0 {
+			exclusionInfo += " | following"
+		}
+		noun := "findings"
+		if m.groupBySecret {
+			noun = "secrets"
+		}
+		left = statusBarStyle.Render(fmt.Sprintf(" %d %s | %d filtered%s",
+			len(m.tableRows()), noun, len(m.findings.rows), exclusionInfo))
 	}
 
-	right := fmt.Sprintf("%s:%s  %s:%s  %s:%s  %s:%s  %s:%s  %s:%s  %s:%s  %s:%s  %s:%s  %s:%s  %s:%s",
+	right := fmt.Sprintf("%s:%s  %s:%s  %s:%s  %s:%s  %s:%s  %s:%s  %s:%s  %s:%s  %s:%s  %s:%s  %s:%s  %s:%s  %s:%s",
 		helpKeyStyle.Render("j/k"), helpDescStyle.Render("nav"),
 		helpKeyStyle.Render("f/d"), helpDescStyle.Render("focus"),
 		helpKeyStyle.Render("a/r"), helpDescStyle.Render("accept/reject"),
 		helpKeyStyle.Render("c"), helpDescStyle.Render("comment"),
+		helpKeyStyle.Render("v"), helpDescStyle.Render("validate"),
 		helpKeyStyle.Render("y"), helpDescStyle.Render("copy"),
 		helpKeyStyle.Render("s"), helpDescStyle.Render("sort"),
+		helpKeyStyle.Render("b"), helpDescStyle.Render("by secret"),
 		helpKeyStyle.Render("o"), helpDescStyle.Render("source"),
 		helpKeyStyle.Render("F7"), helpDescStyle.Render("filters"),
 		helpKeyStyle.Render("/"), helpDescStyle.Render("exclude"),
@@ -688,9 +755,90 @@ func (m *Model) hitTestPane(x, y, contentHeight int) focusedPane {
 	return paneDetails
 }
 
+// reload reads what a running scan has written since the last reload in
+// the background, so that a large datastore doesn't block the UI. The next
+// tick is scheduled once its reloadedMsg arrives.
+func (m *Model) reload() tea.Cmd {
+	s, ruleMap := m.data.store, m.data.ruleMap
+	lastMatch, lastFinding := m.data.lastMatch, m.data.lastFinding
+	return func() tea.Msg {
+		rows, err := loadNew(s, ruleMap, lastMatch, lastFinding)
+		return reloadedMsg{rows: rows, err: err}
+	}
+}
+
+// refresh adds the findings and matches of a reload, keeping the active
+// filters and the selected finding.
+func (m *Model) refresh(msg reloadedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.flashMsg = fmt.Sprintf("Reload failed: %v", msg.err)
+		return tea.Tick(2*time.Second, func(time.Time) tea.Msg { return clearFlashMsg{} })
+	}
+	added, changed := m.data.apply(msg.rows)
+	if !changed {
+		return nil
+	}
+	m.reapply()
+
+	if added == 0 {
+		return nil
+	}
+	m.flashMsg = fmt.Sprintf("%d new finding(s)", added)
+	return tea.Tick(2*time.Second, func(time.Time) tea.Msg { return clearFlashMsg{} })
+}
+
+// reapply rebuilds the facets and the filtered findings after findings
+// change, keeping the active filters and the selected finding.
+func (m *Model) reapply() {
+	selected := m.findings.selectedFinding()
+	details := m.details
+	m.secretRows = nil
+	if m.groupBySecret {
+		m.secretRows = groupRowsBySecret(m.data.findings)
+	}
+	facets := buildFacets(m.tableRows())
+	facets.keepSelections(m.filters.facets)
+	m.filters.facets = facets
+	m.filters.rebuildItems()
+	if m.filters.cursor >= len(m.filters.items) {
+		m.filters.cursor = max(0, len(m.filters.items)-1)
+	}
+	m.findings.allRows = m.tableRows()
+
+	m.applyFilters()
+	m.findings.sort()
+	if selected == nil {
+		return
+	}
+	for i, f := range m.findings.rows {
+		if f.hasFinding(selected.FindingID) {
+			m.findings.cursor = i
+			m.findings.ensureVisible()
+			if f != selected {
+				m.details.setFinding(f)
+			}
+			break
+		}
+	}
+	if m.findings.selectedFinding() == selected {
+		// applyFilters reset the details pane; keep the analyst's place
+		m.details.matchCursor = details.matchCursor
+		m.details.offset = details.offset
+	}
+}
+
+// tableRows returns the rows the findings table shows before filtering: a
+// row per finding, or a row per secret when grouping by secret.
+func (m *Model) tableRows() []*findingRow {
+	if m.groupBySecret {
+		return m.secretRows
+	}
+	return m.data.findings
+}
+
 func (m *Model) applyFilters() {
 	var filtered []*findingRow
-	for _, f := range m.data.findings {
+	for _, f := range m.tableRows() {
 		if m.filters.facets.hasActiveFilters() && !m.filters.facets.matchesFinding(f) {
 			continue
 		}
@@ -700,7 +848,7 @@ func (m *Model) applyFilters() {
 		filtered = append(filtered, f)
 	}
 	m.findings.setFilteredRows(filtered)
-	m.filters.facets.updateCounts(m.data.findings)
+	m.filters.facets.updateCounts(m.tableRows())
 
 	if f := m.findings.selectedFinding(); f != nil {
 		m.details.setFinding(f)
@@ -768,11 +916,12 @@ func (m *Model) setAnnotation(status string) {
 		}
 		// Toggle: if same status, clear it
 		if f.AnnotationStatus == status {
-			f.AnnotationStatus = ""
-			_ = m.data.setFindingAnnotation(f.FindingID, "", f.Comment)
-		} else {
-			f.AnnotationStatus = status
-			_ = m.data.setFindingAnnotation(f.FindingID, status, f.Comment)
+			status = ""
+		}
+		f.AnnotationStatus = status
+		for _, member := range f.findingRows() {
+			member.AnnotationStatus = status
+			_ = m.data.setFindingAnnotation(member.FindingID, status, member.Comment)
 		}
 	} else if m.focus == paneDetails {
 		match := m.details.selectedMatch()
@@ -835,7 +984,10 @@ func (m *Model) saveComment() {
 		f := m.findings.selectedFinding()
 		if f != nil {
 			f.Comment = m.commentInput
-			_ = m.data.setFindingAnnotation(f.FindingID, f.AnnotationStatus, f.Comment)
+			for _, member := range f.findingRows() {
+				member.Comment = m.commentInput
+				_ = m.data.setFindingAnnotation(member.FindingID, member.AnnotationStatus, member.Comment)
+			}
 		}
 	} else if m.commentTarget == "match" {
 		match := m.details.selectedMatch()
@@ -925,6 +1077,7 @@ FOCUS
 
 FILTERS
   x or Space        Toggle filter value
+  h/l               Collapse/expand a facet or Path directory
   Ctrl+r            Reset all filters
 
 EXCLUSIONS
@@ -942,8 +1095,13 @@ ANNOTATIONS
   R                 Reject and move to next
   c                 Add/edit comment
 
+VALIDATION
+  v                 Validate the finding's matches (findings pane) or the
+                    selected match (details pane) against the live service
+
 VIEWS
   s                 Cycle sort column
+  b                 Group findings by secret value across rules
   o                 Open source (pager for files, overlay for git)
   ?                 Toggle this help screen
 
@@ -0,0 +1,197 @@
+package explore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/praetorian-inc/titus/pkg/matcher"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/praetorian-inc/titus/pkg/validator"
+)
+
+// validateTimeout bounds an on-demand validation from the UI.
+const validateTimeout = 30 * time.Second
+
+// validatedMsg carries the results of validating matches of a finding.
+type validatedMsg struct {
+	finding *findingRow
+	matches []*matchRow
+	results []*types.ValidationResult
+	expired bool   // the validation was cancelled or ran out of time
+	warning string // e.g. validator plugins failing to load
+}
+
+// SetValidator enables on-demand validation of findings with engine.
+func (m *Model) SetValidator(engine *validator.Engine) {
+	m.validator = engine
+}
+
+// SetValidatorPlugins has the external validators in dir loaded the first
+// time a finding is validated, so that exploring doesn't start them.
+func (m *Model) SetValidatorPlugins(dir string) {
+	m.pluginDir = dir
+}
+
+// validate starts validating the selected finding's matches, or only the
+// selected match when the details pane has focus. Validation runs in the
+// background; its results arrive as a validatedMsg.
+func (m *Model) validate() tea.Cmd {
+	clearFlash := tea.Tick(2*time.Second, func(time.Time) tea.Msg { return clearFlashMsg{} })
+	if m.validator == nil {
+		m.flashMsg = "Validation is not available"
+		return clearFlash
+	}
+	if m.validating {
+		m.flashMsg = "Validation already running"
+		return clearFlash
+	}
+
+	f := m.findings.selectedFinding()
+	if m.focus == paneDetails {
+		f = m.details.finding
+	}
+	if f == nil {
+		return nil
+	}
+	rows := f.Matches
+	if m.focus == paneDetails {
+		mr := m.details.selectedMatch()
+		if mr == nil {
+			return nil
+		}
+		rows = []*matchRow{mr}
+	}
+	if len(rows) == 0 {
+		m.flashMsg = "No matches to validate"
+		return clearFlash
+	}
+
+	matches := make([]*types.Match, len(rows))
+	for i, mr := range rows {
+		matches[i] = mr.toMatch(m.data.ruleMap[mr.RuleID])
+	}
+	m.validating = true
+	m.flashMsg = fmt.Sprintf("Validating %d match(es)...", len(rows))
+
+	engine := m.validator
+	pluginDir := m.pluginDir
+	m.pluginDir = ""
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), validateTimeout)
+		defer cancel()
+		// Only one validation runs at a time, so the engine can be changed
+		var warning string
+		if pluginDir != "" {
+			plugins, err := validator.LoadPlugins(ctx, pluginDir)
+			if err != nil {
+				warning = fmt.Sprintf("validator plugins not loaded: %v", err)
+			}
+			engine.Prepend(plugins...)
+		}
+		pending := make([]<-chan *types.ValidationResult, len(matches))
+		for i, match := range matches {
+			pending[i] = engine.ValidateAsync(ctx, match)
+		}
+		results := make([]*types.ValidationResult, len(matches))
+		for i, ch := range pending {
+			results[i] = <-ch
+		}
+		return validatedMsg{finding: f, matches: rows, results: results, expired: ctx.Err() != nil, warning: warning}
+	}
+}
+
+// applyValidation records validation results in the datastore and shows
+// them. Results of validations that timed out or were cancelled say
+// nothing about the secret, so they are shown but not recorded.
+func (m *Model) applyValidation(msg validatedMsg) tea.Cmd {
+	m.validating = false
+	counts := make(map[types.ValidationStatus]int)
+	unsaved := 0
+	for i, mr := range msg.matches {
+		result := msg.results[i]
+		if result.Status == types.StatusUndetermined &&
+			(msg.expired || strings.HasPrefix(result.Message, "timed out")) {
+			unsaved++
+			counts[result.Status]++
+			continue
+		}
+		if err := m.data.setMatchValidation(msg.finding, mr, result); err != nil {
+			m.flashMsg = fmt.Sprintf("Saving validation failed: %v", err)
+			return tea.Tick(2*time.Second, func(time.Time) tea.Msg { return clearFlashMsg{} })
+		}
+		counts[result.Status]++
+	}
+	m.reapply()
+
+	if len(msg.matches) == 1 {
+		m.flashMsg = fmt.Sprintf("Validated: %s (%s)", msg.results[0].Status, msg.results[0].Message)
+	} else {
+		m.flashMsg = fmt.Sprintf("Validated %d matches: %d valid, %d invalid, %d undetermined",
+			len(msg.matches), counts[types.StatusValid], counts[types.StatusInvalid], counts[types.StatusUndetermined])
+	}
+	if unsaved > 0 {
+		m.flashMsg += fmt.Sprintf("; %d timed out, not saved", unsaved)
+	}
+	if msg.warning != "" {
+		m.flashMsg += "; " + msg.warning
+	}
+	return tea.Tick(4*time.Second, func(time.Time) tea.Msg { return clearFlashMsg{} })
+}
+
+// toMatch rebuilds the match a row shows, for validation. The datastore
+// doesn't keep named capture groups, which most validators need, so they
+// are recovered by matching rule r against the snippet again.
+func (mr *matchRow) toMatch(r *types.Rule) *types.Match {
+	match := &types.Match{
+		BlobID:       mr.BlobID,
+		StructuralID: mr.StructuralID,
+		RuleID:       mr.RuleID,
+		RuleName:     mr.RuleName,
+		Location:     mr.Location,
+		Groups:       mr.Groups,
+		NamedGroups:  mr.NamedGroups,
+		Snippet:      mr.Snippet,
+	}
+	if len(match.NamedGroups) == 0 && r != nil && r.Pattern != "" {
+		match.NamedGroups = recoverNamedGroups(r, mr)
+	}
+	return match
+}
+
+// recoverNamedGroups finds rule r's match in the snippet of mr with the same
+// capture groups and returns its named groups, or nil if there isn't one.
+func recoverNamedGroups(r *types.Rule, mr *matchRow) map[string][]byte {
+	rm, err := matcher.NewRegexp([]*types.Rule{r}, 0, nil)
+	if err != nil {
+		return nil
+	}
+	defer rm.Close()
+
+	content := bytes.Join([][]byte{mr.Snippet.Before, mr.Snippet.Matching, mr.Snippet.After}, nil)
+	found, err := rm.Match(content)
+	if err != nil {
+		return nil
+	}
+	for _, f := range found {
+		if sameGroups(f.Groups, mr.Groups) {
+			return f.NamedGroups
+		}
+	}
+	return nil
+}
+
+func sameGroups(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
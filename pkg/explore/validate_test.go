@@ -0,0 +1,123 @@
+package explore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/store"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/praetorian-inc/titus/pkg/validator"
+)
+
+// keyValidator reports a match valid if its "key" named group is "live".
+type keyValidator struct{}
+
+func (keyValidator) Name() string                   { return "key" }
+func (keyValidator) CanValidate(ruleID string) bool { return ruleID == "test.key" }
+func (keyValidator) Validate(_ context.Context, m *types.Match) (*types.ValidationResult, error) {
+	if string(m.NamedGroups["key"]) == "live" {
+		return types.NewValidationResult(types.StatusValid, 1, "key is live"), nil
+	}
+	return types.NewValidationResult(types.StatusInvalid, 1, "key is revoked"), nil
+}
+
+func TestValidate(t *testing.T) {
+	r := &types.Rule{ID: "test.key", Name: "Test Key", Pattern: `key=(?P<key>[a-z]+)`}
+	s := store.NewMemory()
+	groups := [][]byte{[]byte("live")}
+	match := &types.Match{
+		StructuralID: "m1",
+		RuleID:       r.ID,
+		Groups:       groups,
+		Snippet:      types.Snippet{Before: []byte("config "), Matching: []byte("key=live"), After: []byte("\n")},
+	}
+	if err := s.AddMatch(match); err != nil {
+		t.Fatal(err)
+	}
+	row := buildFindingRow(&types.Finding{ID: "f1", RuleID: r.ID, Groups: groups}, []*types.Match{match}, map[string]*types.Rule{r.ID: r}, nil)
+
+	data := &exploreData{store: s, ruleMap: map[string]*types.Rule{r.ID: r}, findings: []*findingRow{row}}
+	m := Model{data: data, filters: newFilterPane(buildFacets(data.findings)), findings: newFindingsPane(data.findings), focus: paneFindings}
+	m.SetValidator(validator.NewEngine(1, keyValidator{}))
+
+	cmd := m.validate()
+	if cmd == nil || !m.validating {
+		t.Fatal("expected validation to start")
+	}
+	msg, ok := cmd().(validatedMsg)
+	if !ok {
+		t.Fatal("expected a validatedMsg")
+	}
+	m.applyValidation(msg)
+
+	if row.ValidationStatus != "valid" || row.Matches[0].Message != "key is live" {
+		t.Errorf("expected the finding to be valid, got %q (%q)", row.ValidationStatus, row.Matches[0].Message)
+	}
+	if match.ValidationResult == nil || match.ValidationResult.Status != types.StatusValid {
+		t.Error("expected the validation result to be saved")
+	}
+	if m.validating {
+		t.Error("expected validation to be finished")
+	}
+}
+
+func TestValidate_Unavailable(t *testing.T) {
+	m := Model{data: &exploreData{}}
+	m.validate()
+	if m.flashMsg != "Validation is not available" {
+		t.Errorf("flash = %q", m.flashMsg)
+	}
+}
+
+// slowValidator blocks until its context is done.
+type slowValidator struct{}
+
+func (slowValidator) Name() string                   { return "slow" }
+func (slowValidator) CanValidate(ruleID string) bool { return ruleID == "test.key" }
+func (slowValidator) Validate(ctx context.Context, _ *types.Match) (*types.ValidationResult, error) {
+	<-ctx.Done()
+	return types.NewValidationResult(types.StatusUndetermined, 0, ctx.Err().Error()), nil
+}
+
+func TestValidate_TimeoutNotSaved(t *testing.T) {
+	r := &types.Rule{ID: "test.key", Name: "Test Key"}
+	s := store.NewMemory()
+	match := &types.Match{StructuralID: "m1", RuleID: r.ID, Groups: [][]byte{[]byte("live")}, NamedGroups: map[string][]byte{"key": []byte("live")}}
+	if err := s.AddMatch(match); err != nil {
+		t.Fatal(err)
+	}
+	row := buildFindingRow(&types.Finding{ID: "f1", RuleID: r.ID, Groups: match.Groups}, []*types.Match{match}, map[string]*types.Rule{r.ID: r}, nil)
+
+	data := &exploreData{store: s, ruleMap: map[string]*types.Rule{r.ID: r}, findings: []*findingRow{row}}
+	m := Model{data: data, filters: newFilterPane(buildFacets(data.findings)), findings: newFindingsPane(data.findings), focus: paneFindings}
+	engine := validator.NewEngine(1, slowValidator{})
+	engine.SetValidatorTimeout("slow", 10*time.Millisecond)
+	m.SetValidator(engine)
+
+	// A plugin directory that can't be read is reported, not fatal
+	notDir := filepath.Join(t.TempDir(), "plugins")
+	if err := os.WriteFile(notDir, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	m.SetValidatorPlugins(notDir)
+
+	msg, ok := m.validate()().(validatedMsg)
+	if !ok {
+		t.Fatal("expected a validatedMsg")
+	}
+	m.applyValidation(msg)
+
+	if match.ValidationResult != nil {
+		t.Errorf("expected the timed out validation not to be saved, got %+v", match.ValidationResult)
+	}
+	if !strings.Contains(m.flashMsg, "1 timed out, not saved") || !strings.Contains(m.flashMsg, "validator plugins not loaded") {
+		t.Errorf("flash = %q", m.flashMsg)
+	}
+	if m.pluginDir != "" {
+		t.Error("expected plugins to be loaded only once")
+	}
+}
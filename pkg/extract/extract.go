@@ -0,0 +1,432 @@
+// Package extract pulls text out of documents and archives using only the
+// standard library. It covers the formats the WASM build can afford: zip
+// archives, Office Open XML documents, Jupyter notebooks and tar archives.
+// PDF, SQLite and 7z extraction needs large dependencies and stays in
+// pkg/enum, which uses this package for the formats they share.
+package extract
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// Content is text extracted from a document or archive member.
+type Content struct {
+	Name    string // path within the archive (e.g., "xl/sharedStrings.xml")
+	Content []byte // extracted text content
+}
+
+// Limits bounds how much an archive may expand.
+type Limits struct {
+	MaxSize    int64 // Max uncompressed size per member
+	MaxTotal   int64 // Max total bytes extracted from one archive
+	MaxDepth   int   // Max nested archive depth
+	MaxEntries int   // Max members extracted from one archive, nested archives included (0 = unlimited)
+	MaxRatio   int   // Max ratio of uncompressed to compressed size of archive members (0 = unlimited)
+}
+
+// DefaultLimits returns the same limits as enum.DefaultExtractionLimits.
+func DefaultLimits() Limits {
+	return Limits{
+		MaxSize:    10 * 1024 * 1024,
+		MaxTotal:   100 * 1024 * 1024,
+		MaxDepth:   5,
+		MaxEntries: 10000,
+		MaxRatio:   100,
+	}
+}
+
+// ratioMinSize is the size from which MaxRatio applies, so that small, very
+// repetitive files aren't taken for archive bombs.
+const ratioMinSize = 1 << 20
+
+// OverRatio reports whether size bytes extracted from compressed bytes is
+// over the MaxRatio limit.
+func (l Limits) OverRatio(size, compressed int64) bool {
+	return l.MaxRatio > 0 && size >= ratioMinSize && size > compressed*int64(l.MaxRatio)
+}
+
+// Reasons that archive members are left out by the limits, as passed to
+// Walk.Skip. A reason may go on to say more.
+const (
+	SkipMaxSize    = "exceeds max extracted file size"
+	SkipMaxTotal   = "max total extraction reached"
+	SkipMaxDepth   = "exceeds max archive depth"
+	SkipMaxEntries = "max archive entries reached"
+	SkipMaxRatio   = "compression ratio over limit, possible archive bomb"
+)
+
+// Walk is an extraction through nested archives. The bytes and members
+// extracted count against Limits across every level of nesting.
+type Walk struct {
+	Limits  Limits
+	Depth   int   // nesting depth of the archive being walked
+	Total   int64 // bytes extracted so far
+	Entries int   // members extracted so far
+
+	// Skip, if set, is told of each member left out, and why.
+	Skip func(member, reason string)
+	// Nested, if set, extracts a member that is itself an archive or
+	// document, with nested, the walk one level down. It reports false for
+	// members it doesn't extract. By default the formats Text supports are
+	// extracted.
+	Nested func(name string, data []byte, nested *Walk) ([]Content, bool, error)
+	// KeepBinary keeps binary members rather than leaving them out.
+	KeepBinary bool
+}
+
+// skipped reports that member was left out for reason.
+func (w *Walk) skipped(member, reason string) {
+	if w.Skip != nil {
+		w.Skip(member, reason)
+	}
+}
+
+// countEntry counts member against MaxEntries. It returns false, having
+// reported member skipped, once the limit is reached.
+func (w *Walk) countEntry(member string) bool {
+	if w.Limits.MaxEntries > 0 && w.Entries >= w.Limits.MaxEntries {
+		w.skipped(member, SkipMaxEntries+", remaining members skipped")
+		return false
+	}
+	w.Entries++
+	return true
+}
+
+// nested returns the walk of member, an archive nested in this one. Members
+// it skips are reported as "member:inner"; an empty inner name is member
+// itself.
+func (w *Walk) nested(member string) *Walk {
+	nested := *w
+	nested.Depth++
+	if w.Skip != nil {
+		nested.Skip = func(m, reason string) {
+			name := member
+			if m != "" {
+				name += ":" + m
+			}
+			w.Skip(name, reason)
+		}
+	}
+	return &nested
+}
+
+// Extension returns the lower-cased file extension, handling .tar.gz
+// specially: path.Ext("file.tar.gz") returns ".gz".
+func Extension(name string) string {
+	lower := strings.ToLower(name)
+	if strings.HasSuffix(lower, ".tar.gz") {
+		return ".tar.gz"
+	}
+	return path.Ext(lower)
+}
+
+// Supported reports whether Text can extract the named file.
+func Supported(name string) bool {
+	switch Extension(name) {
+	case ".zip", ".jar", ".war", ".ear", ".apk", ".ipa", ".xpi", ".crx",
+		".xlsx", ".docx", ".pptx", ".ipynb", ".tar", ".tar.gz", ".tgz":
+		return true
+	}
+	return false
+}
+
+// Text extracts text from a supported document or archive. Archive members
+// that are themselves supported are extracted in turn, and named
+// "outer:inner". Binary members are left out.
+func Text(name string, content []byte, limits Limits) ([]Content, error) {
+	return extract(name, content, &Walk{Limits: limits})
+}
+
+func extract(name string, content []byte, w *Walk) ([]Content, error) {
+	if w.Depth > w.Limits.MaxDepth {
+		return nil, nil // Silently skip - too deep
+	}
+
+	switch ext := Extension(name); ext {
+	case ".xlsx":
+		return XLSX(content)
+	case ".docx":
+		return DOCX(content)
+	case ".pptx":
+		return PPTX(content)
+	case ".ipynb":
+		return IPYNB(content)
+	case ".zip", ".jar", ".war", ".ear", ".apk", ".ipa", ".xpi", ".crx":
+		return ZIP(content, w)
+	case ".tar":
+		return Tar(content, false, w)
+	case ".tar.gz", ".tgz":
+		return Tar(content, true, w)
+	default:
+		return nil, fmt.Errorf("unsupported file type: %s", ext)
+	}
+}
+
+// XLSX extracts text from the shared strings and sheets of an Excel
+// workbook.
+func XLSX(content []byte) ([]Content, error) {
+	return zipXMLText(content, "xlsx", func(name string) bool {
+		return name == "xl/sharedStrings.xml" ||
+			strings.HasPrefix(name, "xl/worksheets/sheet") && strings.HasSuffix(name, ".xml")
+	})
+}
+
+// DOCX extracts text from the body of a Word document.
+func DOCX(content []byte) ([]Content, error) {
+	return zipXMLText(content, "docx", func(name string) bool {
+		return name == "word/document.xml"
+	})
+}
+
+// PPTX extracts text from the slides of a PowerPoint presentation.
+func PPTX(content []byte) ([]Content, error) {
+	return zipXMLText(content, "pptx", func(name string) bool {
+		return strings.HasPrefix(name, "ppt/slides/slide") && strings.HasSuffix(name, ".xml")
+	})
+}
+
+// zipXMLText extracts the text of the XML parts of a zip-based document
+// selected by want.
+func zipXMLText(content []byte, format string, want func(name string) bool) ([]Content, error) {
+	zipReader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s as zip: %w", format, err)
+	}
+
+	var results []Content
+	for _, file := range zipReader.File {
+		if !want(file.Name) {
+			continue
+		}
+		data, err := readZipFile(file)
+		if err != nil {
+			continue
+		}
+		if text := XMLText(data); len(text) > 0 {
+			results = append(results, Content{Name: file.Name, Content: []byte(text)})
+		}
+	}
+	return results, nil
+}
+
+// IPYNB extracts code and markdown cells from Jupyter notebooks.
+func IPYNB(content []byte) ([]Content, error) {
+	var notebook struct {
+		Cells []struct {
+			CellType string   `json:"cell_type"`
+			Source   []string `json:"source"`
+		} `json:"cells"`
+	}
+
+	if err := json.Unmarshal(content, &notebook); err != nil {
+		return nil, fmt.Errorf("failed to parse ipynb: %w", err)
+	}
+
+	var results []Content
+	for i, cell := range notebook.Cells {
+		if cell.CellType == "code" || cell.CellType == "markdown" {
+			cellContent := strings.Join(cell.Source, "")
+			if len(strings.TrimSpace(cellContent)) > 0 {
+				results = append(results, Content{
+					Name:    fmt.Sprintf("cell_%d_%s", i, cell.CellType),
+					Content: []byte(cellContent),
+				})
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// ZIP extracts the members of a zip archive within the limits of w.
+func ZIP(content []byte, w *Walk) ([]Content, error) {
+	zipReader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip: %w", err)
+	}
+
+	var results []Content
+	for _, file := range zipReader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		if !w.countEntry(file.Name) {
+			break
+		}
+		if w.Limits.OverRatio(int64(file.UncompressedSize64), int64(file.CompressedSize64)) {
+			w.skipped(file.Name, SkipMaxRatio)
+			continue
+		}
+		if file.UncompressedSize64 > uint64(w.Limits.MaxSize) {
+			w.skipped(file.Name, SkipMaxSize)
+			continue
+		}
+		if w.Total+int64(file.UncompressedSize64) > w.Limits.MaxTotal {
+			w.skipped(file.Name, SkipMaxTotal+", remaining members skipped")
+			break
+		}
+		data, err := readZipFile(file)
+		if err != nil {
+			continue
+		}
+		results = w.add(results, file.Name, data)
+	}
+	return results, nil
+}
+
+// Tar extracts the members of a tar archive, optionally gzipped, within the
+// limits of w.
+func Tar(content []byte, isGzipped bool, w *Walk) ([]Content, error) {
+	var reader io.Reader = bytes.NewReader(content)
+	if isGzipped {
+		gzr, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gzip: %w", err)
+		}
+		defer gzr.Close()
+		reader = gzr
+	}
+
+	tarReader := tar.NewReader(reader)
+	var results []Content
+	var declared int64 // the size of the members so far, as their headers declare
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar: %w", err)
+		}
+		if header.Typeflag == tar.TypeDir {
+			continue
+		}
+		if !w.countEntry(header.Name) {
+			break
+		}
+		// Skipping a member still inflates it, so stop at a gzip bomb
+		declared += header.Size
+		if isGzipped && w.Limits.OverRatio(declared, int64(len(content))) {
+			w.skipped(header.Name, SkipMaxRatio+", remaining members skipped")
+			break
+		}
+		if header.Size > w.Limits.MaxSize {
+			w.skipped(header.Name, SkipMaxSize)
+			continue
+		}
+		if w.Total+header.Size > w.Limits.MaxTotal {
+			w.skipped(header.Name, SkipMaxTotal+", remaining members skipped")
+			break
+		}
+		data, err := io.ReadAll(tarReader)
+		if err != nil {
+			continue
+		}
+		results = w.add(results, header.Name, data)
+	}
+	return results, nil
+}
+
+// add appends an archive member to results, extracting it first if it is
+// itself an archive or document.
+func (w *Walk) add(results []Content, name string, data []byte) []Content {
+	w.Total += int64(len(data))
+
+	nested := w.nested(name)
+	extractNested := w.Nested
+	if extractNested == nil {
+		extractNested = extractSupported
+	}
+	if inner, ok, err := extractNested(name, data, nested); ok {
+		if nested.Depth > w.Limits.MaxDepth {
+			w.skipped(name, SkipMaxDepth)
+		}
+		if err == nil {
+			for _, n := range inner {
+				results = append(results, Content{Name: name + ":" + n.Name, Content: n.Content})
+			}
+		}
+		w.Total = nested.Total
+		w.Entries = nested.Entries
+		return results
+	}
+
+	if IsBinary(data) && !w.KeepBinary {
+		w.skipped(name, "binary")
+		return results
+	}
+	return append(results, Content{Name: name, Content: data})
+}
+
+// extractSupported is the default Walk.Nested: the formats Text supports.
+func extractSupported(name string, data []byte, nested *Walk) ([]Content, bool, error) {
+	if !Supported(name) {
+		return nil, false, nil
+	}
+	results, err := extract(name, data, nested)
+	return results, true, err
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// XMLText returns the text nodes of an XML document, joined by spaces.
+func XMLText(data []byte) string {
+	var text strings.Builder
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+
+		if t, ok := token.(xml.CharData); ok {
+			content := string(t)
+			if strings.TrimSpace(content) != "" {
+				if text.Len() > 0 {
+					text.WriteString(" ")
+				}
+				text.WriteString(cleanText(content))
+			}
+		}
+	}
+
+	return text.String()
+}
+
+// cleanText removes extra whitespace and non-printable characters.
+func cleanText(s string) string {
+	var result strings.Builder
+	lastSpace := false
+
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			if !lastSpace {
+				result.WriteRune(' ')
+				lastSpace = true
+			}
+		} else if unicode.IsPrint(r) {
+			result.WriteRune(r)
+			lastSpace = false
+		}
+	}
+
+	return strings.TrimSpace(result.String())
+}
@@ -0,0 +1,232 @@
+package extract
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"strings"
+	"testing"
+)
+
+const testSecret = "AKIATESTKEY1234567890"
+
+// TestText_Formats extracts the secret from each supported test file.
+func TestText_Formats(t *testing.T) {
+	for _, name := range []string{
+		"test.xlsx", "test.docx", "test.pptx", "test.ipynb", "test.zip", "test.jar",
+		"test.apk", "test.tar", "test.tar.gz", "test.tgz",
+	} {
+		t.Run(name, func(t *testing.T) {
+			content, err := os.ReadFile("../../testdata/extraction/" + name)
+			if err != nil {
+				t.Fatalf("failed to read test file: %v", err)
+			}
+			if !Supported(name) {
+				t.Fatalf("Supported(%q) = false", name)
+			}
+			results, err := Text(name, content, DefaultLimits())
+			if err != nil {
+				t.Fatalf("Text() error = %v", err)
+			}
+			for _, r := range results {
+				if strings.Contains(string(r.Content), testSecret) {
+					return
+				}
+			}
+			t.Errorf("Text() did not extract the secret from %s", name)
+		})
+	}
+}
+
+// TestText_Unsupported verifies that formats needing large dependencies are
+// left to pkg/enum.
+func TestText_Unsupported(t *testing.T) {
+	for _, name := range []string{"test.pdf", "test.sqlite", "test.7z", "test.txt"} {
+		if Supported(name) {
+			t.Errorf("Supported(%q) = true", name)
+		}
+		if _, err := Text(name, nil, DefaultLimits()); err == nil {
+			t.Errorf("Text(%q) expected error", name)
+		}
+	}
+}
+
+// TestText_NestedAndLimits checks member naming in nested archives and the
+// size and depth limits.
+func TestText_NestedAndLimits(t *testing.T) {
+	inner := buildZip(t, map[string]string{"config.env": "KEY=" + testSecret})
+	outer := buildZip(t, map[string]string{
+		"inner.zip": string(inner),
+		"big.txt":   strings.Repeat("x", 100),
+		"blob.bin":  "\x00\x01\x02",
+	})
+
+	results, err := Text("outer.zip", outer, DefaultLimits())
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	names := map[string]bool{}
+	for _, r := range results {
+		names[r.Name] = true
+	}
+	if !names["inner.zip:config.env"] || !names["big.txt"] || names["blob.bin"] {
+		t.Errorf("unexpected members: %v", names)
+	}
+
+	limits := DefaultLimits()
+	limits.MaxSize = 50
+	limits.MaxDepth = 0
+	results, err = Text("outer.zip", outer, limits)
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("expected no members within limits, got %d", len(results))
+	}
+}
+
+// TestText_BombLimits tests that archive members are bounded in number and
+// compression ratio, nested archives included.
+func TestText_BombLimits(t *testing.T) {
+	inner := buildZip(t, map[string]string{"a.env": "A=1", "b.env": "B=2"})
+	outer := buildZip(t, map[string]string{"inner.zip": string(inner)})
+	limits := DefaultLimits()
+	limits.MaxEntries = 2 // inner.zip and one of its members
+	results, err := Text("outer.zip", outer, limits)
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("expected 1 member within MaxEntries, got %d", len(results))
+	}
+
+	bomb := buildZip(t, map[string]string{
+		"zeros.txt": strings.Repeat("0", 2<<20),
+		"notes.txt": "token=abc",
+	})
+	results, err = Text("bomb.zip", bomb, DefaultLimits())
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Name != "notes.txt" {
+		t.Errorf("expected only notes.txt within MaxRatio, got %d members", len(results))
+	}
+
+	var tgz bytes.Buffer
+	gz := gzip.NewWriter(&tgz)
+	tw := tar.NewWriter(gz)
+	for _, name := range []string{"zeros.txt", "notes.txt"} {
+		data := []byte("token=abc")
+		if name == "zeros.txt" {
+			data = bytes.Repeat([]byte("0"), 2<<20)
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data))}); err != nil {
+			t.Fatalf("failed to write tar header: %v", err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatalf("failed to write tar member: %v", err)
+		}
+	}
+	tw.Close()
+	gz.Close()
+	results, err = Text("bomb.tgz", tgz.Bytes(), DefaultLimits())
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("expected a gzip bomb to stop extraction, got %d members", len(results))
+	}
+}
+
+// TestWalk_Skip tests that members left out are reported with their path
+// through nested archives, and that KeepBinary keeps binary members.
+func TestWalk_Skip(t *testing.T) {
+	inner := buildZip(t, map[string]string{"big.txt": strings.Repeat("x", 1000)})
+	outer := buildZip(t, map[string]string{"inner.zip": string(inner), "blob.bin": "\x00\x01\x02"})
+
+	skipped := map[string]string{}
+	limits := DefaultLimits()
+	limits.MaxSize = int64(len(inner))
+	w := &Walk{Limits: limits, Skip: func(member, reason string) { skipped[member] = reason }}
+	results, err := ZIP(outer, w)
+	if err != nil {
+		t.Fatalf("ZIP() error = %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("expected no members, got %d", len(results))
+	}
+	if skipped["inner.zip:big.txt"] != SkipMaxSize || skipped["blob.bin"] != "binary" {
+		t.Errorf("unexpected skips: %v", skipped)
+	}
+	if w.Entries != 3 || w.Total != int64(len(inner))+3 {
+		t.Errorf("Entries, Total = %d, %d", w.Entries, w.Total)
+	}
+
+	results, err = ZIP(outer, &Walk{Limits: DefaultLimits(), KeepBinary: true})
+	if err != nil {
+		t.Fatalf("ZIP() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("expected both members with KeepBinary, got %d", len(results))
+	}
+}
+
+func buildZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// TestCleanText tests the cleanText helper function.
+func TestCleanText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "multiple spaces",
+			input: "Hello    World",
+			want:  "Hello World",
+		},
+		{
+			name:  "leading and trailing spaces",
+			input: "  Hello World  ",
+			want:  "Hello World",
+		},
+		{
+			name:  "newlines and tabs",
+			input: "Hello\n\tWorld",
+			want:  "Hello World",
+		},
+		{
+			name:  "normal text",
+			input: "Hello World",
+			want:  "Hello World",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cleanText(tt.input)
+			if got != tt.want {
+				t.Errorf("cleanText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package extract
+
+import (
+	"bytes"
+	"encoding/binary"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const (
+	// binarySampleSize is how much of the content IsBinary looks at.
+	binarySampleSize = 8192
+
+	// minTextRatio is the share of printable bytes above which content with
+	// NUL bytes is still taken as text, such as a log with stray padding.
+	minTextRatio = 0.95
+
+	// minUTF16Ratio is the share of 16-bit units that must be ASCII for
+	// content without a byte order mark to be taken as UTF-16 text.
+	minUTF16Ratio = 0.9
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// IsBinary reports whether content looks binary rather than text, judging
+// by its first 8 KiB. Content with a UTF-8 or UTF-16 byte order mark, or
+// that reads as UTF-16 text without one, is text. Otherwise content with
+// no NUL byte is text, and content with NUL bytes is binary unless nearly
+// all of it is printable.
+func IsBinary(content []byte) bool {
+	sample := content[:min(len(content), binarySampleSize)]
+	if bytes.IndexByte(sample, 0) == -1 {
+		return false
+	}
+	if utf16Order(sample) != nil {
+		return false
+	}
+
+	printable := 0
+	for _, b := range sample {
+		if isTextByte(b) {
+			printable++
+		}
+	}
+	return float64(printable) < minTextRatio*float64(len(sample))
+}
+
+// DecodeText returns content as UTF-8: UTF-16 text, with a byte order mark
+// or detected as such, is transcoded and a UTF-8 byte order mark removed.
+// Other content is returned unchanged.
+func DecodeText(content []byte) []byte {
+	text, _ := decodeText(content, false)
+	return text
+}
+
+// DecodeTextOffsets is DecodeText, also returning the offset in content of
+// each byte of the text, and of its end, so that positions in the text can
+// be mapped back. offsets is nil if content is returned unchanged.
+func DecodeTextOffsets(content []byte) (text []byte, offsets []int) {
+	return decodeText(content, true)
+}
+
+func decodeText(content []byte, withOffsets bool) ([]byte, []int) {
+	base := 0 // the length of a byte order mark removed
+	if bytes.HasPrefix(content, bomUTF8) {
+		text := content[len(bomUTF8):]
+		if !withOffsets {
+			return text, nil
+		}
+		offsets := make([]int, len(text)+1)
+		for i := range offsets {
+			offsets[i] = len(bomUTF8) + i
+		}
+		return text, offsets
+	}
+	order := utf16Order(content[:min(len(content), binarySampleSize)])
+	if order == nil {
+		return content, nil
+	}
+	if bytes.HasPrefix(content, bomUTF16LE) || bytes.HasPrefix(content, bomUTF16BE) {
+		base = 2
+	}
+
+	units := (len(content) - base) / 2
+	unit := func(i int) rune { return rune(order.Uint16(content[base+2*i:])) }
+	out := make([]byte, 0, units)
+	var offsets []int
+	for i := 0; i < units; i++ {
+		start := base + 2*i
+		r := unit(i)
+		if utf16.IsSurrogate(r) {
+			if i+1 < units {
+				r = utf16.DecodeRune(r, unit(i+1))
+			} else {
+				r = utf8.RuneError
+			}
+			if r != utf8.RuneError {
+				i++
+			}
+		}
+		n := len(out)
+		out = utf8.AppendRune(out, r)
+		if withOffsets {
+			for range len(out) - n {
+				offsets = append(offsets, start)
+			}
+		}
+	}
+	if withOffsets {
+		offsets = append(offsets, base+2*units)
+	}
+	return out, offsets
+}
+
+// utf16Order returns the byte order of UTF-16 text, from its byte order mark
+// or, failing that, from NUL high bytes in most of its 16-bit units, or nil
+// if sample doesn't look like UTF-16.
+func utf16Order(sample []byte) binary.ByteOrder {
+	switch {
+	case bytes.HasPrefix(sample, bomUTF16LE):
+		return binary.LittleEndian
+	case bytes.HasPrefix(sample, bomUTF16BE):
+		return binary.BigEndian
+	}
+	if bytes.IndexByte(sample, 0) == -1 {
+		return nil
+	}
+	units := len(sample) / 2
+	if units == 0 {
+		return nil
+	}
+	var le, be int
+	for i := 0; i+1 < len(sample); i += 2 {
+		lo, hi := sample[i], sample[i+1]
+		switch {
+		case hi == 0 && isASCIIText(lo):
+			le++
+		case lo == 0 && isASCIIText(hi):
+			be++
+		}
+	}
+	switch {
+	case float64(le) >= minUTF16Ratio*float64(units):
+		return binary.LittleEndian
+	case float64(be) >= minUTF16Ratio*float64(units):
+		return binary.BigEndian
+	}
+	return nil
+}
+
+// isASCIIText reports whether b is printable ASCII or common whitespace.
+func isASCIIText(b byte) bool {
+	return b < 0x80 && b != 0 && isTextByte(b)
+}
+
+// isTextByte reports whether b is likely part of text: printable ASCII,
+// common whitespace, or a byte of a UTF-8 multibyte sequence.
+func isTextByte(b byte) bool {
+	switch {
+	case b >= 0x20 && b != 0x7F:
+		return true
+	case b == '\t', b == '\n', b == '\r', b == '\f', b == '\v', b == 0x1B:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package extract
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+// utf16LE encodes s as UTF-16LE.
+func utf16LE(s string) []byte {
+	var b []byte
+	for _, r := range s {
+		b = append(b, byte(r), byte(r>>8))
+	}
+	return b
+}
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		want    bool
+	}{
+		{"text", []byte("password = hunter2\n"), false},
+		{"non-ASCII text", []byte("mot de passe = «sécurité» 密码\n"), false},
+		{"null bytes", []byte{0x7F, 'E', 'L', 'F', 0x02, 0x01, 0x01, 0x00, 0x00, 0x00}, true},
+		{"mostly text with padding", append(bytes.Repeat([]byte("log line\n"), 20), 0, 0), false},
+		{"UTF-16LE with BOM", append([]byte{0xFF, 0xFE}, utf16LE("key=value")...), false},
+		{"UTF-16LE without BOM", utf16LE("[credentials]\r\naws_secret_access_key = abc\r\n"), false},
+		{"UTF-16BE with BOM", []byte{0xFE, 0xFF, 0, 'k', 0, 'e', 0, 'y'}, false},
+		{"high bytes with nulls", []byte{0x89, 0x00, 0x01}, true},
+		{"empty", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsBinary(tt.content); got != tt.want {
+				t.Errorf("IsBinary() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeText(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		want    string
+	}{
+		{"UTF-8", []byte("key=value"), "key=value"},
+		{"UTF-8 BOM", []byte("\xEF\xBB\xBFkey=value"), "key=value"},
+		{"UTF-16LE BOM", append([]byte{0xFF, 0xFE}, utf16LE("clé=värde")...), "clé=värde"},
+		{"UTF-16LE", utf16LE("token: ghp_abc\n"), "token: ghp_abc\n"},
+		{"UTF-16BE BOM", []byte{0xFE, 0xFF, 0, 'k', 0, '=', 0, 'v'}, "k=v"},
+		{"binary", []byte{0x89, 0x00, 0x01}, "\x89\x00\x01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(DecodeText(tt.content)); got != tt.want {
+				t.Errorf("DecodeText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeTextOffsets(t *testing.T) {
+	// "é" is one UTF-16 unit but two UTF-8 bytes
+	content := append([]byte{0xFF, 0xFE}, utf16LE("é=k")...)
+	text, offsets := DecodeTextOffsets(content)
+	if string(text) != "é=k" {
+		t.Fatalf("DecodeTextOffsets() text = %q, want %q", text, "é=k")
+	}
+	want := []int{2, 2, 4, 6, 8}
+	if !slices.Equal(offsets, want) {
+		t.Errorf("DecodeTextOffsets() offsets = %v, want %v", offsets, want)
+	}
+
+	text, offsets = DecodeTextOffsets([]byte("\xEF\xBB\xBFk=v"))
+	if string(text) != "k=v" || !slices.Equal(offsets, []int{3, 4, 5, 6}) {
+		t.Errorf("DecodeTextOffsets() = %q, %v for UTF-8 with a byte order mark", text, offsets)
+	}
+
+	if _, offsets := DecodeTextOffsets([]byte("k=v")); offsets != nil {
+		t.Errorf("DecodeTextOffsets() offsets = %v for UTF-8, want nil", offsets)
+	}
+}
@@ -0,0 +1,239 @@
+// Package filetype identifies the language or file type of scanned blobs, so
+// findings can be filtered to, say, Terraform or away from Markdown docs.
+package filetype
+
+import (
+	"bytes"
+	"path"
+	"strings"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// Unknown is the type of blobs that match no known extension, file name or
+// shebang.
+const Unknown = "Unknown"
+
+// byName maps well-known file names (lower-cased) to their type.
+var byName = map[string]string{
+	"dockerfile":          "Dockerfile",
+	"containerfile":       "Dockerfile",
+	"makefile":            "Makefile",
+	"gnumakefile":         "Makefile",
+	"jenkinsfile":         "Groovy",
+	"vagrantfile":         "Ruby",
+	"gemfile":             "Ruby",
+	"rakefile":            "Ruby",
+	"podfile":             "Ruby",
+	"procfile":            "Procfile",
+	".env":                "Dotenv",
+	".bashrc":             "Shell",
+	".bash_profile":       "Shell",
+	".profile":            "Shell",
+	".zshrc":              "Shell",
+	".npmrc":              "INI",
+	".pypirc":             "INI",
+	".gitconfig":          "INI",
+	".netrc":              "Netrc",
+	".htpasswd":           "Htpasswd",
+	"docker-compose.yml":  "YAML",
+	"docker-compose.yaml": "YAML",
+	"id_rsa":              "Private Key",
+	"id_dsa":              "Private Key",
+	"id_ecdsa":            "Private Key",
+	"id_ed25519":          "Private Key",
+}
+
+// byExt maps file extensions (lower-cased, with the dot) to their type.
+var byExt = map[string]string{
+	".go":         "Go",
+	".py":         "Python",
+	".pyw":        "Python",
+	".ipynb":      "Jupyter Notebook",
+	".js":         "JavaScript",
+	".mjs":        "JavaScript",
+	".cjs":        "JavaScript",
+	".jsx":        "JavaScript",
+	".ts":         "TypeScript",
+	".tsx":        "TypeScript",
+	".java":       "Java",
+	".kt":         "Kotlin",
+	".kts":        "Kotlin",
+	".scala":      "Scala",
+	".groovy":     "Groovy",
+	".gradle":     "Groovy",
+	".rb":         "Ruby",
+	".php":        "PHP",
+	".cs":         "C#",
+	".c":          "C",
+	".h":          "C",
+	".cc":         "C++",
+	".cpp":        "C++",
+	".hpp":        "C++",
+	".rs":         "Rust",
+	".swift":      "Swift",
+	".m":          "Objective-C",
+	".dart":       "Dart",
+	".pl":         "Perl",
+	".lua":        "Lua",
+	".r":          "R",
+	".sh":         "Shell",
+	".bash":       "Shell",
+	".zsh":        "Shell",
+	".ps1":        "PowerShell",
+	".psm1":       "PowerShell",
+	".bat":        "Batch",
+	".cmd":        "Batch",
+	".sql":        "SQL",
+	".tf":         "Terraform",
+	".tfvars":     "Terraform",
+	".tfstate":    "Terraform",
+	".hcl":        "HCL",
+	".bicep":      "Bicep",
+	".yml":        "YAML",
+	".yaml":       "YAML",
+	".json":       "JSON",
+	".toml":       "TOML",
+	".ini":        "INI",
+	".cfg":        "INI",
+	".conf":       "Config",
+	".properties": "Properties",
+	".env":        "Dotenv",
+	".xml":        "XML",
+	".config":     "XML",
+	".plist":      "XML",
+	".html":       "HTML",
+	".htm":        "HTML",
+	".vue":        "Vue",
+	".svelte":     "Svelte",
+	".css":        "CSS",
+	".scss":       "CSS",
+	".md":         "Markdown",
+	".markdown":   "Markdown",
+	".rst":        "reStructuredText",
+	".txt":        "Text",
+	".log":        "Log",
+	".csv":        "CSV",
+	".pem":        "Private Key",
+	".key":        "Private Key",
+	".ppk":        "Private Key",
+	".p12":        "Certificate",
+	".pfx":        "Certificate",
+	".crt":        "Certificate",
+	".cer":        "Certificate",
+}
+
+// byInterpreter maps shebang interpreters to their type.
+var byInterpreter = map[string]string{
+	"sh":      "Shell",
+	"bash":    "Shell",
+	"zsh":     "Shell",
+	"ksh":     "Shell",
+	"dash":    "Shell",
+	"python":  "Python",
+	"python2": "Python",
+	"python3": "Python",
+	"node":    "JavaScript",
+	"deno":    "TypeScript",
+	"ruby":    "Ruby",
+	"perl":    "Perl",
+	"php":     "PHP",
+	"pwsh":    "PowerShell",
+	"lua":     "Lua",
+	"Rscript": "R",
+}
+
+// Detect returns the type of a blob from its path, falling back to the
+// shebang line of its content. The path may use either slash.
+func Detect(p string, content []byte) string {
+	base := strings.ToLower(path.Base(strings.ReplaceAll(p, "\\", "/")))
+	if t, ok := byName[base]; ok {
+		return t
+	}
+	if strings.HasPrefix(base, ".env.") {
+		return "Dotenv"
+	}
+	if strings.HasPrefix(base, "dockerfile.") {
+		return "Dockerfile"
+	}
+	if strings.HasSuffix(base, ".tfstate.backup") {
+		return "Terraform"
+	}
+	if t, ok := byExt[path.Ext(base)]; ok {
+		return t
+	}
+	if t := shebang(content); t != "" {
+		return t
+	}
+	return Unknown
+}
+
+// shebang returns the type named by a #! line's interpreter, handling
+// "#!/usr/bin/env python3" and versioned names like python3.11.
+func shebang(content []byte) string {
+	if !bytes.HasPrefix(content, []byte("#!")) {
+		return ""
+	}
+	line := content[2:]
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	fields := strings.Fields(string(line))
+	if len(fields) == 0 {
+		return ""
+	}
+	interp := path.Base(fields[0])
+	if interp == "env" {
+		fields = fields[1:]
+		for len(fields) > 0 && strings.HasPrefix(fields[0], "-") {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			return ""
+		}
+		interp = path.Base(fields[0])
+	}
+	if t, ok := byInterpreter[interp]; ok {
+		return t
+	}
+	if i := strings.IndexByte(interp, '.'); i > 0 {
+		return byInterpreter[interp[:i]]
+	}
+	return ""
+}
+
+// Tag returns prov with its file type detected from its path and content.
+// Provenance without a path is returned unchanged.
+func Tag(prov types.Provenance, content []byte) types.Provenance {
+	switch p := prov.(type) {
+	case types.FileProvenance:
+		p.FileType = Detect(p.FilePath, content)
+		return p
+	case types.GitProvenance:
+		p.FileType = Detect(p.BlobPath, content)
+		return p
+	}
+	return prov
+}
+
+// Of returns the file type recorded with prov, detecting it from the path for
+// provenance stored before file types were recorded.
+func Of(prov types.Provenance) string {
+	var t string
+	switch p := prov.(type) {
+	case types.FileProvenance:
+		t = p.FileType
+	case types.GitProvenance:
+		t = p.FileType
+	case types.ArchiveProvenance:
+		if t = Detect(p.MemberPath, nil); t == Unknown {
+			t = Detect(p.ArchivePath, nil)
+		}
+	default:
+		return ""
+	}
+	if t == "" {
+		t = Detect(prov.Path(), nil)
+	}
+	return t
+}
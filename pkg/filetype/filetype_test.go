@@ -0,0 +1,56 @@
+package filetype
+
+import (
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		path    string
+		content string
+		want    string
+	}{
+		{"infra/main.tf", "", "Terraform"},
+		{"prod.tfvars", "", "Terraform"},
+		{"terraform.tfstate.backup", "", "Terraform"},
+		{"README.md", "", "Markdown"},
+		{"src/App.TSX", "", "TypeScript"},
+		{"deploy/values.yaml", "", "YAML"},
+		{"Dockerfile", "", "Dockerfile"},
+		{"build/Dockerfile.prod", "", "Dockerfile"},
+		{".env.production", "", "Dotenv"},
+		{`C:\repo\config\app.json`, "", "JSON"},
+		{"bin/deploy", "#!/usr/bin/env python3\nimport os\n", "Python"},
+		{"bin/run", "#!/bin/bash -e\necho hi\n", "Shell"},
+		{"bin/serve", "#!/usr/bin/env -S node --harmony\n", "JavaScript"},
+		{"bin/tool", "#!/usr/local/bin/python3.11\n", "Python"},
+		{"notes", "just some text", Unknown},
+		{"bin/custom", "#!/opt/custom-interp\n", Unknown},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Detect(tt.path, []byte(tt.content)), tt.path)
+	}
+}
+
+func TestTag(t *testing.T) {
+	prov := Tag(types.GitProvenance{RepoPath: "/repo", BlobPath: "main.tf"}, nil)
+	assert.Equal(t, "Terraform", prov.(types.GitProvenance).FileType)
+
+	prov = Tag(types.FileProvenance{FilePath: "scripts/setup"}, []byte("#!/bin/sh\n"))
+	assert.Equal(t, "Shell", prov.(types.FileProvenance).FileType)
+
+	ext := types.ExtendedProvenance{Payload: map[string]interface{}{"k": "v"}}
+	assert.Equal(t, ext, Tag(ext, nil))
+}
+
+func TestOf(t *testing.T) {
+	assert.Equal(t, "Shell", Of(types.FileProvenance{FilePath: "setup", FileType: "Shell"}))
+	// Provenance stored before file types were recorded falls back to the path
+	assert.Equal(t, "Markdown", Of(types.GitProvenance{BlobPath: "docs/setup.md"}))
+	assert.Equal(t, "Terraform", Of(types.ArchiveProvenance{ArchivePath: "prod.tfstate", MemberPath: "aws_db_instance.main"}))
+	assert.Equal(t, "JSON", Of(types.ArchiveProvenance{ArchivePath: "bundle.zip", MemberPath: "config.json"}))
+	assert.Equal(t, "", Of(types.ExtendedProvenance{}))
+}
@@ -0,0 +1,44 @@
+package fix
+
+import (
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// noNewline is the marker a unified diff puts after a last line that has no
+// trailing newline.
+const noNewline = "\\ No newline at end of file\n"
+
+// UnifiedDiff returns a unified diff of a file's old and new contents, with
+// a/ and b/ path prefixes so it can be applied with `git apply` or `patch -p1`.
+// It returns "" when the contents are equal.
+func UnifiedDiff(path string, before, after []byte) (string, error) {
+	if string(before) == string(after) {
+		return "", nil
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(string(before)),
+		B:        splitLines(string(after)),
+		FromFile: "a/" + path,
+		ToFile:   "b/" + path,
+		Context:  3,
+	})
+}
+
+// splitLines splits s into lines that keep their newlines. Unlike
+// difflib.SplitLines it adds no empty line after a trailing newline, and a
+// last line without one carries the no-newline marker, so it is printed
+// after the line and the line differs from the same text with a newline.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if last := len(lines) - 1; lines[last] == "" {
+		lines = lines[:last]
+	} else {
+		lines[last] += "\n" + noNewline
+	}
+	return lines
+}
@@ -0,0 +1,355 @@
+// Package fix rewrites files to remove secrets found by a scan, replacing
+// each secret with an environment-variable reference in the idiom of the
+// file's language, or with a placeholder where no such idiom exists.
+package fix
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Style selects how secrets are replaced.
+type Style string
+
+const (
+	// StyleEnv replaces secrets with an environment-variable reference where
+	// the file's language has one, and with the placeholder elsewhere.
+	StyleEnv Style = "env"
+	// StylePlaceholder always replaces secrets with the placeholder.
+	StylePlaceholder Style = "placeholder"
+)
+
+// DefaultPlaceholder is the text secrets are replaced with when no
+// environment-variable reference applies.
+const DefaultPlaceholder = "REDACTED"
+
+// Language is a file type with its own replacement idiom.
+type Language string
+
+const (
+	LangDotenv Language = "dotenv"
+	LangYAML   Language = "yaml"
+	LangJS     Language = "js"
+	LangGo     Language = "go"
+	LangOther  Language = "other"
+)
+
+// Options configures a Fixer.
+type Options struct {
+	Style       Style
+	Placeholder string
+}
+
+// Secret is a secret to remove from a file.
+type Secret struct {
+	Value    []byte
+	Start    int64  // byte offset of the match containing Value, used to find it
+	End      int64  // end of the match
+	RuleName string // used to name the variable when the context has no key
+}
+
+// Replacement describes one rewrite made in a file.
+type Replacement struct {
+	Line   int    // 1-based line of the secret
+	EnvVar string // variable name, empty for placeholder replacements
+	Old    string // replaced text, including quotes when they were replaced
+	New    string
+}
+
+// Fixer rewrites file contents.
+type Fixer struct {
+	opts Options
+}
+
+// New creates a Fixer. Zero-valued options default to StyleEnv and
+// DefaultPlaceholder.
+func New(opts Options) *Fixer {
+	if opts.Style == "" {
+		opts.Style = StyleEnv
+	}
+	if opts.Placeholder == "" {
+		opts.Placeholder = DefaultPlaceholder
+	}
+	return &Fixer{opts: opts}
+}
+
+// DetectLanguage returns the language of a file from its name.
+func DetectLanguage(path string) Language {
+	base := strings.ToLower(filepath.Base(path))
+	switch {
+	case base == ".env" || strings.HasPrefix(base, ".env.") || strings.HasSuffix(base, ".env"):
+		return LangDotenv
+	}
+	switch filepath.Ext(base) {
+	case ".yaml", ".yml":
+		return LangYAML
+	case ".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx":
+		return LangJS
+	case ".go":
+		return LangGo
+	}
+	return LangOther
+}
+
+// edit is a pending replacement of content[start:end].
+type edit struct {
+	start, end int
+	repl       Replacement
+}
+
+// Fix rewrites content, the contents of the file at path, removing secrets.
+// Secrets that no longer appear in content are skipped. It returns the new
+// content and the replacements made, in file order.
+func (f *Fixer) Fix(path string, content []byte, secrets []Secret) ([]byte, []Replacement, error) {
+	lang := DetectLanguage(path)
+	var consts [][2]int
+	if lang == LangGo {
+		consts = goConstDecls(content)
+	}
+
+	var edits []edit
+	for _, s := range secrets {
+		start := locate(content, s)
+		if start < 0 {
+			continue
+		}
+		inConst := slices.ContainsFunc(consts, func(d [2]int) bool { return d[0] <= start && start < d[1] })
+		edits = append(edits, f.plan(lang, content, start, start+len(s.Value), s, inConst))
+	}
+	if len(edits) == 0 {
+		return content, nil, nil
+	}
+
+	// Apply back to front so earlier offsets stay valid, skipping overlaps
+	// (the same secret reported by two rules)
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	kept := edits[:0]
+	for _, e := range edits {
+		if len(kept) > 0 && e.start < kept[len(kept)-1].end {
+			continue
+		}
+		kept = append(kept, e)
+	}
+
+	out := content
+	for i := len(kept) - 1; i >= 0; i-- {
+		e := kept[i]
+		next := make([]byte, 0, len(out)-(e.end-e.start)+len(e.repl.New))
+		next = append(next, out[:e.start]...)
+		next = append(next, e.repl.New...)
+		next = append(next, out[e.end:]...)
+		out = next
+	}
+
+	replacements := make([]Replacement, len(kept))
+	usesGetenv := false
+	for i, e := range kept {
+		replacements[i] = e.repl
+		if lang == LangGo && e.repl.EnvVar != "" {
+			usesGetenv = true
+		}
+	}
+	if usesGetenv {
+		var err error
+		if out, err = ensureImport(out, "os"); err != nil {
+			return nil, nil, fmt.Errorf("adding os import to %s: %w", path, err)
+		}
+	}
+	return out, replacements, nil
+}
+
+// locate returns the offset of the secret, preferring the occurrence inside
+// its recorded match and falling back to the first in the file (the file may
+// have changed since the scan). It returns -1 if the secret is gone.
+func locate(content []byte, s Secret) int {
+	if len(s.Value) == 0 {
+		return -1
+	}
+	if s.Start >= 0 && s.End <= int64(len(content)) && s.Start < s.End {
+		if i := bytes.Index(content[s.Start:s.End], s.Value); i >= 0 {
+			return int(s.Start) + i
+		}
+	}
+	return bytes.Index(content, s.Value)
+}
+
+// plan decides the replacement for the secret at content[start:end]. inConst
+// reports that it is in a Go const declaration, where a call can't go.
+func (f *Fixer) plan(lang Language, content []byte, start, end int, s Secret, inConst bool) edit {
+	lineStart := bytes.LastIndexByte(content[:start], '\n') + 1
+	line := 1 + bytes.Count(content[:start], []byte{'\n'})
+	placeholder := edit{start: start, end: end, repl: Replacement{
+		Line: line,
+		Old:  string(content[start:end]),
+		New:  f.opts.Placeholder,
+	}}
+	if f.opts.Style == StylePlaceholder {
+		return placeholder
+	}
+
+	name := envVarName(content[lineStart:start], s.RuleName)
+	quoted := isQuoted(content, start, end)
+
+	var expr string
+	switch lang {
+	case LangJS:
+		expr = "process.env." + name
+	case LangGo:
+		if inConst {
+			return placeholder
+		}
+		expr = fmt.Sprintf("os.Getenv(%q)", name)
+	case LangYAML:
+		// Interpolation also works inside a longer scalar, e.g. a URL
+		expr = "${" + name + "}"
+	default:
+		// .env files define variables rather than reference them, and other
+		// languages have no single idiom, so the value is blanked instead
+		return placeholder
+	}
+
+	if !quoted && lang != LangYAML {
+		// Part of a longer string literal: an expression can't go there
+		return placeholder
+	}
+	if quoted {
+		start--
+		end++
+	}
+	return edit{start: start, end: end, repl: Replacement{
+		Line:   line,
+		EnvVar: name,
+		Old:    string(content[start:end]),
+		New:    expr,
+	}}
+}
+
+// isQuoted reports whether content[start:end] is exactly the body of a
+// quoted string.
+func isQuoted(content []byte, start, end int) bool {
+	if start == 0 || end >= len(content) {
+		return false
+	}
+	q := content[start-1]
+	return (q == '"' || q == '\'' || q == '`') && content[end] == q
+}
+
+// keyPattern finds the key a value is assigned to in the text preceding it on
+// its line: `key = "`, `key: '`, `"key": "`, `KEY=`, `key := "`, and values
+// with a prefix such as `url: https://`.
+var keyPattern = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_.-]*)["']?\s*(?::=|=|:)\s*["'` + "`" + `]?[^\s"'` + "`" + `]*$`)
+
+// envVarName derives a variable name from the assignment key before the
+// secret, falling back to the rule name.
+func envVarName(before []byte, ruleName string) string {
+	if m := keyPattern.FindSubmatch(before); m != nil {
+		key := string(m[1])
+		if i := strings.LastIndexByte(key, '.'); i >= 0 {
+			// obj.apiKey, config.github.token
+			key = key[i+1:]
+		}
+		if name := screamingSnake(key); name != "" {
+			return name
+		}
+	}
+	if name := screamingSnake(ruleName); name != "" {
+		return name
+	}
+	return "SECRET"
+}
+
+// screamingSnake converts an identifier or phrase to UPPER_SNAKE_CASE:
+// apiKey, api-key and "API Key" all become API_KEY. Phrases are split on
+// separators only, so "GitHub Token" becomes GITHUB_TOKEN.
+func screamingSnake(s string) string {
+	splitCamel := !strings.ContainsAny(s, " -_.")
+	var b strings.Builder
+	prevLower := false
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if splitCamel && unicode.IsUpper(r) && prevLower {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToUpper(r))
+			prevLower = unicode.IsLower(r) || unicode.IsDigit(r)
+		default:
+			if b.Len() > 0 && !strings.HasSuffix(b.String(), "_") {
+				b.WriteByte('_')
+			}
+			prevLower = false
+		}
+	}
+	name := strings.Trim(b.String(), "_")
+	if name != "" && unicode.IsDigit(rune(name[0])) {
+		name = "_" + name
+	}
+	return name
+}
+
+// goConstDecls returns the byte ranges of the const declarations in Go
+// source. Source that doesn't parse has none.
+func goConstDecls(src []byte) [][2]int {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	var decls [][2]int
+	ast.Inspect(file, func(n ast.Node) bool {
+		if gen, ok := n.(*ast.GenDecl); ok && gen.Tok == token.CONST {
+			decls = append(decls, [2]int{fset.Position(gen.Pos()).Offset, fset.Position(gen.End()).Offset})
+			return false
+		}
+		return true
+	})
+	return decls
+}
+
+// ensureImport adds an import of pkg to Go source that lacks one. Source
+// that doesn't parse is returned unchanged.
+func ensureImport(src []byte, pkg string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ImportsOnly)
+	if err != nil {
+		return src, nil
+	}
+	for _, imp := range file.Imports {
+		if path, _ := strconv.Unquote(imp.Path.Value); path == pkg && (imp.Name == nil || imp.Name.Name == pkg) {
+			return src, nil
+		}
+	}
+
+	spec := strconv.Quote(pkg)
+	// Insert into the first parenthesized import block, or add a new
+	// declaration after the package clause
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if ok && gen.Tok == token.IMPORT && gen.Lparen.IsValid() {
+			return splice(src, fset.Position(gen.Lparen).Offset+1, "\n\t"+spec), nil
+		}
+	}
+	nameEnd := fset.Position(file.Name.End()).Offset
+	if nameEnd > len(src) {
+		return nil, fmt.Errorf("invalid package clause")
+	}
+	return splice(src, nameEnd, "\n\nimport "+spec), nil
+}
+
+// splice inserts text into src at offset.
+func splice(src []byte, offset int, text string) []byte {
+	out := make([]byte, 0, len(src)+len(text))
+	out = append(out, src[:offset]...)
+	out = append(out, text...)
+	return append(out, src[offset:]...)
+}
@@ -0,0 +1,96 @@
+// Package fpfilter flags matches that look like test, example or documentation
+// data rather than live credentials. Flagged matches are kept, not dropped: the
+// verdict is surfaced as Match.LikelyFP so outputs and the explore TUI can
+// downgrade or filter them.
+package fpfilter
+
+import (
+	"path"
+	"strings"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// DefaultContextMarkers are case-insensitive substrings that mark a snippet as
+// sample data when they appear in the match or its surrounding context.
+var DefaultContextMarkers = []string{
+	"example",
+	"dummy",
+	"placeholder",
+	"xxxx",
+	"lorem ipsum",
+	"fake",
+	"sample",
+}
+
+// DefaultPathMarkers are directory names (compared case-insensitively against
+// each path segment) that hold tests, fixtures or documentation.
+var DefaultPathMarkers = []string{
+	"test",
+	"tests",
+	"__tests__",
+	"testdata",
+	"fixtures",
+	"__fixtures__",
+	"mocks",
+	"docs",
+	"doc",
+	"examples",
+	"samples",
+}
+
+// Filter decides whether a match is likely a false positive.
+type Filter struct {
+	ContextMarkers []string
+	PathMarkers    []string
+}
+
+// New creates a Filter with the default markers.
+func New() *Filter {
+	return &Filter{
+		ContextMarkers: DefaultContextMarkers,
+		PathMarkers:    DefaultPathMarkers,
+	}
+}
+
+// Reasons returns why m looks like a false positive, or nil if it does not.
+// paths are the provenance paths of the blob the match was found in.
+func (f *Filter) Reasons(m *types.Match, paths []string) []string {
+	var reasons []string
+
+	text := strings.ToLower(string(m.Snippet.Before) + string(m.Snippet.Matching) + string(m.Snippet.After))
+	for _, marker := range f.ContextMarkers {
+		if strings.Contains(text, strings.ToLower(marker)) {
+			reasons = append(reasons, "context contains "+marker)
+		}
+	}
+
+	for _, p := range paths {
+		if dir := f.pathMarker(p); dir != "" {
+			reasons = append(reasons, "path is under "+dir+"/")
+			break
+		}
+	}
+
+	return reasons
+}
+
+// Flag sets m.LikelyFP and m.FPReasons from Reasons and reports the verdict.
+func (f *Filter) Flag(m *types.Match, paths []string) bool {
+	m.FPReasons = f.Reasons(m, paths)
+	m.LikelyFP = len(m.FPReasons) > 0
+	return m.LikelyFP
+}
+
+// pathMarker returns the first directory of p that is a path marker, or "".
+func (f *Filter) pathMarker(p string) string {
+	dir := path.Dir(strings.ReplaceAll(p, "\\", "/"))
+	for _, seg := range strings.Split(dir, "/") {
+		for _, marker := range f.PathMarkers {
+			if strings.EqualFold(seg, marker) {
+				return seg
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package fpfilter
+
+import (
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func snippetMatch(before, matching, after string) *types.Match {
+	return &types.Match{Snippet: types.Snippet{
+		Before:   []byte(before),
+		Matching: []byte(matching),
+		After:    []byte(after),
+	}}
+}
+
+func TestFilter_ContextMarkers(t *testing.T) {
+	f := New()
+
+	testCases := []struct {
+		name string
+		m    *types.Match
+		want bool
+	}{
+		{"clean", snippetMatch("config:\n", "token: ghp_abc123", "\n"), false},
+		{"marker in context", snippetMatch("# Example configuration\n", "token: ghp_abc123", "\n"), true},
+		{"marker in match", snippetMatch("", "token: DUMMY_TOKEN_1234", ""), true},
+		{"masked value", snippetMatch("", "password: xxxxxxxx", ""), true},
+		{"lorem ipsum", snippetMatch("Lorem ipsum dolor sit amet\n", "secret=abc", ""), true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, f.Flag(tc.m, nil))
+			assert.Equal(t, tc.want, tc.m.LikelyFP)
+			if tc.want {
+				assert.NotEmpty(t, tc.m.FPReasons)
+			}
+		})
+	}
+}
+
+func TestFilter_PathMarkers(t *testing.T) {
+	f := New()
+	m := snippetMatch("", "token: ghp_abc123", "")
+
+	assert.False(t, f.Flag(m, []string{"src/config.go"}))
+	assert.False(t, f.Flag(m, []string{"src/test.go"}), "file names are not path markers")
+	assert.False(t, f.Flag(m, []string{"contest/app.yml"}), "segments must match exactly")
+
+	assert.True(t, f.Flag(m, []string{"src/app.go", "pkg/Fixtures/creds.json"}))
+	assert.Equal(t, []string{"path is under Fixtures/"}, m.FPReasons)
+
+	assert.True(t, f.Flag(m, []string{"/repo/docs/setup.md"}))
+	assert.True(t, f.Flag(m, []string{`C:\src\test\app.config`}))
+}
+
+func TestFilter_CustomMarkers(t *testing.T) {
+	f := &Filter{ContextMarkers: []string{"NOT-A-SECRET"}}
+	assert.True(t, f.Flag(snippetMatch("// not-a-secret\n", "key=1", ""), []string{"test/a.go"}))
+	assert.False(t, f.Flag(snippetMatch("// example\n", "key=1", ""), []string{"test/a.go"}))
+}
@@ -0,0 +1,233 @@
+// Package gitlabreport writes findings as a GitLab secret detection report,
+// which GitLab CI shows in merge request security widgets and the
+// vulnerability report when a job uploads it as its
+// artifacts:reports:secret_detection artifact.
+package gitlabreport
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// Report format constants
+const (
+	SchemaVersion = "15.0.7"
+	ScannerID     = "titus"
+	ScannerName   = "Titus"
+	ScannerURL    = "https://github.com/praetorian-inc/titus"
+	Vendor        = "Praetorian"
+
+	// timeLayout is the schema's timestamp format, which has no zone.
+	timeLayout = "2006-01-02T15:04:05"
+
+	// noCommit stands in for the commit of secrets not found in git
+	// history, which the schema requires.
+	noCommit = "0000000"
+)
+
+// Severities, from the schema's enumeration
+const (
+	SeverityCritical = "Critical"
+	SeverityHigh     = "High"
+	SeverityLow      = "Low"
+	SeverityInfo     = "Info"
+)
+
+// Report is the top-level report structure.
+type Report struct {
+	Version         string          `json:"version"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities"`
+	Scan            Scan            `json:"scan"`
+}
+
+// Vulnerability is one secret found at one location.
+type Vulnerability struct {
+	ID          string       `json:"id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Severity    string       `json:"severity"`
+	Solution    string       `json:"solution,omitempty"`
+	Location    Location     `json:"location"`
+	Identifiers []Identifier `json:"identifiers"`
+	Details     Details      `json:"details,omitempty"`
+}
+
+// Location is where a secret was found.
+type Location struct {
+	File      string `json:"file"`
+	StartLine int    `json:"start_line,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+	Commit    Commit `json:"commit"`
+}
+
+// Commit is the commit a secret was found in.
+type Commit struct {
+	SHA string `json:"sha"`
+}
+
+// Identifier names the rule that found a secret.
+type Identifier struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	URL   string `json:"url,omitempty"`
+}
+
+// Details holds extra fields shown on a vulnerability's page, keyed by an
+// identifier of the field.
+type Details map[string]DetailText
+
+// DetailText is a named text field of Details.
+type DetailText struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"` // always "text"
+	Value string `json:"value"`
+}
+
+// Scan describes the scan that produced the report.
+type Scan struct {
+	Analyzer  Tool   `json:"analyzer"`
+	Scanner   Tool   `json:"scanner"`
+	Type      string `json:"type"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+	Status    string `json:"status"`
+}
+
+// Tool is the analyzer or scanner of a Scan.
+type Tool struct {
+	ID      string     `json:"id"`
+	Name    string     `json:"name"`
+	URL     string     `json:"url,omitempty"`
+	Version string     `json:"version"`
+	Vendor  ToolVendor `json:"vendor"`
+}
+
+// ToolVendor is the maker of a Tool.
+type ToolVendor struct {
+	Name string `json:"name"`
+}
+
+// NewReport creates an empty report of a scan by the given version of
+// Titus that ran from start to end.
+func NewReport(version string, start, end time.Time) *Report {
+	tool := Tool{ID: ScannerID, Name: ScannerName, URL: ScannerURL, Version: version, Vendor: ToolVendor{Name: Vendor}}
+	return &Report{
+		Version:         SchemaVersion,
+		Vulnerabilities: []Vulnerability{},
+		Scan: Scan{
+			Analyzer:  tool,
+			Scanner:   tool,
+			Type:      "secret_detection",
+			StartTime: start.UTC().Format(timeLayout),
+			EndTime:   end.UTC().Format(timeLayout),
+			Status:    "success",
+		},
+	}
+}
+
+// AddVulnerability adds a match found at loc. rule may be nil if the
+// match's rule is unknown.
+func (r *Report) AddVulnerability(match *types.Match, rule *types.Rule, loc types.BlobLocation) {
+	name := match.RuleName
+	if rule != nil && rule.Name != "" {
+		name = rule.Name
+	}
+	if name == "" {
+		name = match.RuleID
+	}
+
+	identifier := Identifier{
+		Type:  "titus_rule_id",
+		Name:  "Titus rule " + match.RuleID,
+		Value: match.RuleID,
+	}
+	description := name + " detected"
+	if rule != nil {
+		if rule.Description != "" {
+			description = rule.Description
+		}
+		if len(rule.References) > 0 {
+			identifier.URL = rule.References[0]
+		}
+	}
+
+	commit := loc.Commit
+	if commit == "" {
+		commit = noCommit
+	}
+	v := Vulnerability{
+		ID:          vulnerabilityID(match, loc),
+		Name:        name,
+		Description: description,
+		Severity:    Severity(match),
+		Solution:    "Revoke the secret, issue a new one, and remove it from the source and its history.",
+		Location: Location{
+			File:      reportPath(loc.Path),
+			StartLine: match.Location.Source.Start.Line,
+			EndLine:   match.Location.Source.End.Line,
+			Commit:    Commit{SHA: commit},
+		},
+		Identifiers: []Identifier{identifier},
+	}
+
+	details := Details{}
+	if vr := match.ValidationResult; vr != nil {
+		value := string(vr.Status)
+		if vr.Message != "" {
+			value += ": " + vr.Message
+		}
+		details["validation"] = DetailText{Name: "Validation", Type: "text", Value: value}
+	}
+	if match.LikelyFP {
+		details["likely_fp"] = DetailText{Name: "Likely test data", Type: "text", Value: strings.Join(match.FPReasons, ", ")}
+	}
+	if len(details) > 0 {
+		v.Details = details
+	}
+
+	r.Vulnerabilities = append(r.Vulnerabilities, v)
+}
+
+// Severity ranks a match for GitLab: confirmed-live secrets are critical,
+// ones validation rejected low, likely test data informational, and any
+// other secret high.
+func Severity(match *types.Match) string {
+	switch {
+	case match.Active():
+		return SeverityCritical
+	case match.ValidationResult != nil && match.ValidationResult.Status == types.StatusInvalid:
+		return SeverityLow
+	case match.LikelyFP:
+		return SeverityInfo
+	}
+	return SeverityHigh
+}
+
+// vulnerabilityID identifies a match at a location, so the same secret in
+// the same place keeps its ID, and its triage in GitLab, across pipelines.
+func vulnerabilityID(match *types.Match, loc types.BlobLocation) string {
+	sum := sha256.Sum256([]byte(match.StructuralID + "\x00" + loc.Path + "\x00" + loc.Commit))
+	return hex.EncodeToString(sum[:16])
+}
+
+// reportPath returns a path in the form GitLab links to files in a
+// repository: slash-separated, without a leading "./".
+func reportPath(path string) string {
+	path = filepath.ToSlash(path)
+	for strings.HasPrefix(path, "./") {
+		path = path[2:]
+	}
+	return path
+}
+
+// ToJSON serializes the report to JSON bytes.
+func (r *Report) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
@@ -0,0 +1,259 @@
+// Package graph builds a secret sprawl graph, relating each secret to the
+// files it was found in, the repositories holding those files and the
+// authors who committed them, and writes it as GraphML, Graphviz DOT or a
+// JSON adjacency list for visualization tools.
+package graph
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Node kinds
+const (
+	KindSecret = "secret"
+	KindFile   = "file"
+	KindRepo   = "repository"
+	KindAuthor = "author"
+)
+
+// Edge kinds
+const (
+	EdgeFoundIn   = "found_in"  // secret to file
+	EdgePartOf    = "part_of"   // file to repository
+	EdgeCommitted = "committed" // author to file
+)
+
+// Node is a secret, file, repository or author.
+type Node struct {
+	ID         string            `json:"id"`
+	Kind       string            `json:"kind"`
+	Label      string            `json:"label"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// Edge relates two nodes.
+type Edge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Kind   string `json:"kind"`
+}
+
+// Graph is a set of nodes and the edges between them. Nodes and edges are
+// kept in the order they were first added, so output is deterministic.
+type Graph struct {
+	nodes   []*Node
+	byID    map[string]*Node
+	edges   []Edge
+	edgeSet map[Edge]bool
+}
+
+// New creates an empty graph.
+func New() *Graph {
+	return &Graph{byID: make(map[string]*Node), edgeSet: make(map[Edge]bool)}
+}
+
+// AddNode adds the node of the given kind and key, whose ID is kind:key, and
+// returns it. Adding a node that exists returns the existing node, so its
+// attributes can be extended.
+func (g *Graph) AddNode(kind, key, label string) *Node {
+	id := kind + ":" + key
+	if n, ok := g.byID[id]; ok {
+		return n
+	}
+	n := &Node{ID: id, Kind: kind, Label: label, Attributes: make(map[string]string)}
+	g.nodes = append(g.nodes, n)
+	g.byID[id] = n
+	return n
+}
+
+// AddEdge adds an edge between two nodes, once.
+func (g *Graph) AddEdge(source, target *Node, kind string) {
+	e := Edge{Source: source.ID, Target: target.ID, Kind: kind}
+	if g.edgeSet[e] {
+		return
+	}
+	g.edgeSet[e] = true
+	g.edges = append(g.edges, e)
+}
+
+// Nodes returns the nodes in the order they were added.
+func (g *Graph) Nodes() []*Node {
+	return g.nodes
+}
+
+// Edges returns the edges in the order they were added.
+func (g *Graph) Edges() []Edge {
+	return g.edges
+}
+
+// attributeKeys returns the attribute names used by any node, sorted.
+func (g *Graph) attributeKeys() []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, n := range g.nodes {
+		for k := range n.Attributes {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// adjacency is a node of the JSON adjacency list.
+type adjacency struct {
+	*Node
+	Adjacent []adjacent `json:"adjacent"`
+}
+
+type adjacent struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+}
+
+// WriteJSON writes the graph as a JSON adjacency list: every node, with the
+// nodes its outgoing edges lead to.
+func (g *Graph) WriteJSON(w io.Writer) error {
+	out := struct {
+		Nodes []adjacency `json:"nodes"`
+	}{Nodes: make([]adjacency, 0, len(g.nodes))}
+	index := make(map[string]int, len(g.nodes))
+	for i, n := range g.nodes {
+		index[n.ID] = i
+		out.Nodes = append(out.Nodes, adjacency{Node: n, Adjacent: []adjacent{}})
+	}
+	for _, e := range g.edges {
+		a := &out.Nodes[index[e.Source]]
+		a.Adjacent = append(a.Adjacent, adjacent{ID: e.Target, Kind: e.Kind})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language, with a shape per
+// node kind.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	shapes := map[string]string{KindSecret: "diamond", KindFile: "note", KindRepo: "folder", KindAuthor: "ellipse"}
+
+	var b strings.Builder
+	b.WriteString("digraph sprawl {\n")
+	b.WriteString("  rankdir=LR;\n")
+	for _, n := range g.nodes {
+		fmt.Fprintf(&b, "  %s [label=%s, shape=%s", dotQuote(n.ID), dotQuote(n.Label), shapes[n.Kind])
+		for _, k := range sortedKeys(n.Attributes) {
+			fmt.Fprintf(&b, ", %s=%s", dotQuote(k), dotQuote(n.Attributes[k]))
+		}
+		b.WriteString("];\n")
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", dotQuote(e.Source), dotQuote(e.Target), dotQuote(e.Kind))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotQuote quotes s as a DOT string ID.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// GraphML document structure
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteGraphML writes the graph as GraphML, which Gephi, yEd and Cytoscape
+// import. Node kinds, labels and attributes, and edge kinds, are declared
+// as string keys.
+func (g *Graph) WriteGraphML(w io.Writer) error {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "kind", For: "node", AttrName: "kind", AttrType: "string"},
+			{ID: "label", For: "node", AttrName: "label", AttrType: "string"},
+			{ID: "edge_kind", For: "edge", AttrName: "kind", AttrType: "string"},
+		},
+		Graph: graphMLGraph{ID: "sprawl", EdgeDefault: "directed"},
+	}
+	attrKeys := g.attributeKeys()
+	for _, k := range attrKeys {
+		doc.Keys = append(doc.Keys, graphMLKey{ID: "attr_" + k, For: "node", AttrName: k, AttrType: "string"})
+	}
+	for _, n := range g.nodes {
+		node := graphMLNode{ID: n.ID, Data: []graphMLData{{Key: "kind", Value: n.Kind}, {Key: "label", Value: n.Label}}}
+		for _, k := range attrKeys {
+			if v, ok := n.Attributes[k]; ok {
+				node.Data = append(node.Data, graphMLData{Key: "attr_" + k, Value: v})
+			}
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+	for _, e := range g.edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{Source: e.Source, Target: e.Target, Data: []graphMLData{{Key: "edge_kind", Value: e.Kind}}})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,87 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleGraph() *Graph {
+	g := New()
+	secret := g.AddNode(KindSecret, "abc123", `AWS "prod" key`)
+	secret.Attributes["rule_ids"] = "np.aws.1"
+	file := g.AddNode(KindFile, "repo:config.env", "config.env")
+	repo := g.AddNode(KindRepo, "repo", "repo")
+	author := g.AddNode(KindAuthor, "alice@example.com", "Alice <alice@example.com>")
+	g.AddEdge(secret, file, EdgeFoundIn)
+	g.AddEdge(secret, file, EdgeFoundIn)
+	g.AddEdge(file, repo, EdgePartOf)
+	g.AddEdge(author, file, EdgeCommitted)
+	return g
+}
+
+func TestGraph_AddNodeAndEdge(t *testing.T) {
+	g := sampleGraph()
+	assert.Len(t, g.Nodes(), 4)
+	assert.Len(t, g.Edges(), 3, "duplicate edges are added once")
+
+	n := g.AddNode(KindSecret, "abc123", "other label")
+	assert.Equal(t, "secret:abc123", n.ID)
+	assert.Equal(t, `AWS "prod" key`, n.Label, "existing node is returned")
+	assert.Len(t, g.Nodes(), 4)
+}
+
+func TestGraph_WriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, sampleGraph().WriteJSON(&buf))
+
+	var out struct {
+		Nodes []struct {
+			ID         string            `json:"id"`
+			Kind       string            `json:"kind"`
+			Attributes map[string]string `json:"attributes"`
+			Adjacent   []struct {
+				ID   string `json:"id"`
+				Kind string `json:"kind"`
+			} `json:"adjacent"`
+		} `json:"nodes"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	require.Len(t, out.Nodes, 4)
+	assert.Equal(t, "secret:abc123", out.Nodes[0].ID)
+	assert.Equal(t, "np.aws.1", out.Nodes[0].Attributes["rule_ids"])
+	require.Len(t, out.Nodes[0].Adjacent, 1)
+	assert.Equal(t, "file:repo:config.env", out.Nodes[0].Adjacent[0].ID)
+	assert.Equal(t, EdgeFoundIn, out.Nodes[0].Adjacent[0].Kind)
+	assert.NotNil(t, out.Nodes[2].Adjacent, "nodes without edges have an empty list")
+}
+
+func TestGraph_WriteDOT(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, sampleGraph().WriteDOT(&buf))
+	dot := buf.String()
+
+	assert.Contains(t, dot, "digraph sprawl {\n")
+	assert.Contains(t, dot, `"secret:abc123" [label="AWS \"prod\" key", shape=diamond, "rule_ids"="np.aws.1"];`)
+	assert.Contains(t, dot, `"file:repo:config.env" -> "repository:repo" [label="part_of"];`)
+	assert.Contains(t, dot, `"author:alice@example.com" -> "file:repo:config.env" [label="committed"];`)
+}
+
+func TestGraph_WriteGraphML(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, sampleGraph().WriteGraphML(&buf))
+
+	var doc graphML
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, "directed", doc.Graph.EdgeDefault)
+	assert.Len(t, doc.Graph.Nodes, 4)
+	assert.Len(t, doc.Graph.Edges, 3)
+	assert.Contains(t, doc.Keys, graphMLKey{ID: "attr_rule_ids", For: "node", AttrName: "rule_ids", AttrType: "string"})
+	assert.Contains(t, doc.Graph.Nodes[0].Data, graphMLData{Key: "label", Value: `AWS "prod" key`})
+	assert.Contains(t, doc.Graph.Nodes[0].Data, graphMLData{Key: "attr_rule_ids", Value: "np.aws.1"})
+	assert.Equal(t, []graphMLData{{Key: "edge_kind", Value: EdgeCommitted}}, doc.Graph.Edges[2].Data)
+}
@@ -0,0 +1,156 @@
+package keystore
+
+import (
+	"crypto/sha1"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+)
+
+// JKS entry tags.
+const (
+	jksPrivateKey  = 1
+	jksTrustedCert = 2
+	jksSecretKey   = 3 // JCEKS only
+)
+
+var errTruncated = errors.New("truncated java keystore")
+
+// openJKS reads a JKS or JCEKS keystore. Aliases and certificates are
+// stored in the clear; a password is checked against the keyed SHA-1 digest
+// that ends the file.
+func openJKS(content []byte, passwords []string) (*Keystore, error) {
+	r := &jksReader{data: content}
+	magic := r.u32()
+	version := r.u32()
+	count := r.u32()
+	if r.err != nil {
+		return nil, r.err
+	}
+	if version != 1 && version != 2 {
+		return nil, fmt.Errorf("unsupported java keystore version %d", version)
+	}
+
+	ks := &Keystore{Format: FormatJKS}
+	if magic == 0xcececece {
+		ks.Format = FormatJCEKS
+	}
+
+	for i := uint32(0); i < count && r.err == nil; i++ {
+		tag := r.u32()
+		e := &Entry{Alias: r.utf()}
+		r.skip(8) // creation time
+		switch tag {
+		case jksPrivateKey:
+			e.PrivateKey = true
+			r.skip(int(r.u32())) // encrypted key
+			chain := r.u32()
+			for j := uint32(0); j < chain && r.err == nil; j++ {
+				if cert := r.cert(version); cert != nil {
+					e.Certificates = append(e.Certificates, cert)
+				}
+			}
+		case jksTrustedCert:
+			if cert := r.cert(version); cert != nil {
+				e.Certificates = append(e.Certificates, cert)
+			}
+		case jksSecretKey:
+			// A serialized Java object with no length prefix: record the
+			// entry, but nothing after it can be read.
+			e.SecretKey = true
+			ks.Entries = append(ks.Entries, e)
+			r.err = errStop
+		default:
+			r.err = fmt.Errorf("unknown java keystore entry tag %d", tag)
+		}
+		if r.err == nil {
+			ks.Entries = append(ks.Entries, e)
+		}
+	}
+	if r.err != nil && r.err != errStop {
+		return nil, r.err
+	}
+
+	if len(content) >= sha1.Size {
+		body, digest := content[:len(content)-sha1.Size], content[len(content)-sha1.Size:]
+		for _, pw := range passwords {
+			if subtle.ConstantTimeCompare(jksDigest(pw, body), digest) == 1 {
+				ks.setPassword(pw)
+				break
+			}
+		}
+	}
+	return ks, nil
+}
+
+// errStop ends reading entries without failing.
+var errStop = errors.New("stop")
+
+// jksDigest computes the integrity digest of a Java keystore body.
+func jksDigest(password string, body []byte) []byte {
+	h := sha1.New()
+	for _, c := range utf16.Encode([]rune(password)) {
+		h.Write([]byte{byte(c >> 8), byte(c)})
+	}
+	h.Write([]byte("Mighty Aphrodite"))
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+// jksReader reads the big-endian fields of a Java keystore, recording the
+// first error.
+type jksReader struct {
+	data []byte
+	err  error
+}
+
+func (r *jksReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data) {
+		r.err = errTruncated
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *jksReader) skip(n int) { r.next(n) }
+
+func (r *jksReader) u32() uint32 {
+	b := r.next(4)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+func (r *jksReader) utf() string {
+	b := r.next(2)
+	if b == nil {
+		return ""
+	}
+	return string(r.next(int(binary.BigEndian.Uint16(b))))
+}
+
+// cert reads a certificate, returning nil if it isn't a parsable X.509 one.
+func (r *jksReader) cert(version uint32) *x509.Certificate {
+	certType := "X.509"
+	if version == 2 {
+		certType = r.utf()
+	}
+	der := r.next(int(r.u32()))
+	if r.err != nil || certType != "X.509" {
+		return nil
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil
+	}
+	return cert
+}
@@ -0,0 +1,121 @@
+// Package keystore reads PKCS#12 (.p12, .pfx) and Java (JKS, JCEKS)
+// keystores far enough to tell what they hold: the aliases of their entries,
+// whether those are private or secret keys, and their certificates. It also
+// tries a list of common passwords, since keystores checked into
+// repositories are often protected by a default one.
+package keystore
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// Keystore formats.
+const (
+	FormatPKCS12 = "pkcs12"
+	FormatJKS    = "jks"
+	FormatJCEKS  = "jceks"
+)
+
+// CommonPasswords are the passwords tried on every keystore: Java's default
+// "changeit", Android's debug keystore password and other usual suspects.
+var CommonPasswords = []string{
+	"",
+	"changeit",
+	"changeme",
+	"password",
+	"android",
+	"secret",
+	"keystore",
+	"storepass",
+	"keypass",
+	"123456",
+	"12345678",
+	"1234",
+	"admin",
+	"test",
+	"pass",
+}
+
+// Keystore is what could be read from a keystore.
+type Keystore struct {
+	Format  string
+	Entries []*Entry
+
+	// Password is the password that opened the keystore, or nil if none of
+	// those tried did.
+	Password *string
+
+	// Encrypted is set if part of the keystore couldn't be read without
+	// its password. PKCS#12 files usually encrypt their certificates.
+	Encrypted bool
+}
+
+// Entry is a keystore entry.
+type Entry struct {
+	Alias        string
+	PrivateKey   bool
+	SecretKey    bool
+	Certificates []*x509.Certificate // chain of a private key, or a trusted certificate
+
+	keyID []byte // PKCS#12 local key ID
+}
+
+// HasKeys reports whether the keystore holds private or secret keys.
+func (ks *Keystore) HasKeys() bool {
+	for _, e := range ks.Entries {
+		if e.PrivateKey || e.SecretKey {
+			return true
+		}
+	}
+	return false
+}
+
+func (ks *Keystore) setPassword(pw string) {
+	ks.Password = &pw
+}
+
+// KeyID returns the local key ID of a PKCS#12 key entry, which identifies
+// the key when its certificates are encrypted.
+func (e *Entry) KeyID() []byte {
+	return e.keyID
+}
+
+var (
+	jksMagic   = []byte{0xfe, 0xed, 0xfe, 0xed}
+	jceksMagic = []byte{0xce, 0xce, 0xce, 0xce}
+)
+
+// Detect returns the format of a keystore, or "" if content isn't one. Java
+// keystores are recognized by their magic number; PKCS#12, which is plain
+// DER, by a keystore file extension.
+func Detect(path string, content []byte) string {
+	switch {
+	case bytes.HasPrefix(content, jksMagic):
+		return FormatJKS
+	case bytes.HasPrefix(content, jceksMagic):
+		return FormatJCEKS
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".p12", ".pfx", ".jks", ".keystore":
+		// A DER SEQUENCE; Java 9 and later write PKCS#12 to .jks files
+		if len(content) > 0 && content[0] == 0x30 {
+			return FormatPKCS12
+		}
+	}
+	return ""
+}
+
+// Open reads a keystore of the given format, trying each of passwords.
+func Open(format string, content []byte, passwords []string) (*Keystore, error) {
+	switch format {
+	case FormatPKCS12:
+		return openPKCS12(content, passwords)
+	case FormatJKS, FormatJCEKS:
+		return openJKS(content, passwords)
+	}
+	return nil, errors.New("unknown keystore format " + format)
+}
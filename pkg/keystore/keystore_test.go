@@ -0,0 +1,186 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readTestKeystore(t *testing.T, name string) []byte {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join("../../testdata/keystore", name))
+	require.NoError(t, err)
+	return content
+}
+
+func TestOpen_PKCS12(t *testing.T) {
+	tests := []struct {
+		file      string
+		password  *string
+		encrypted bool
+		certs     int
+	}{
+		// OpenSSL 3 defaults: PBES2 with AES-256 and a SHA-256 MAC
+		{file: "changeit.p12", password: ptr("changeit"), certs: 1},
+		// -legacy: RC2-40 certificates and a 3DES key with a SHA-1 MAC
+		{file: "legacy-empty.pfx", password: ptr(""), certs: 1},
+		{file: "strong.p12", encrypted: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			content := readTestKeystore(t, tt.file)
+			require.Equal(t, FormatPKCS12, Detect(tt.file, content))
+
+			ks, err := Open(FormatPKCS12, content, CommonPasswords)
+			require.NoError(t, err)
+			assert.Equal(t, tt.password, ks.Password)
+			assert.Equal(t, tt.encrypted, ks.Encrypted)
+			assert.True(t, ks.HasKeys())
+			require.Len(t, ks.Entries, 1)
+
+			e := ks.Entries[0]
+			assert.Equal(t, "server", e.Alias)
+			assert.True(t, e.PrivateKey)
+			assert.NotEmpty(t, e.KeyID())
+			require.Len(t, e.Certificates, tt.certs)
+			if tt.certs > 0 {
+				assert.Equal(t, "CN=api.example.com,O=Example Corp", e.Certificates[0].Subject.String())
+			}
+		})
+	}
+}
+
+func TestOpen_PKCS12Truststore(t *testing.T) {
+	ks, err := Open(FormatPKCS12, readTestKeystore(t, "truststore.p12"), CommonPasswords)
+	require.NoError(t, err)
+	assert.False(t, ks.HasKeys())
+	require.Len(t, ks.Entries, 1)
+	require.Len(t, ks.Entries[0].Certificates, 1)
+	assert.Equal(t, "CN=Example Root CA", ks.Entries[0].Certificates[0].Subject.String())
+}
+
+func TestOpen_JKS(t *testing.T) {
+	cert := testCertificate(t, "signing.example.com")
+	content := buildJKS(t, "changeit", cert)
+	require.Equal(t, FormatJKS, Detect("release.keystore", content))
+
+	ks, err := Open(FormatJKS, content, CommonPasswords)
+	require.NoError(t, err)
+	require.NotNil(t, ks.Password)
+	assert.Equal(t, "changeit", *ks.Password)
+	require.Len(t, ks.Entries, 2)
+
+	assert.Equal(t, "release", ks.Entries[0].Alias)
+	assert.True(t, ks.Entries[0].PrivateKey)
+	require.Len(t, ks.Entries[0].Certificates, 1)
+	assert.Equal(t, "CN=signing.example.com", ks.Entries[0].Certificates[0].Subject.String())
+
+	assert.Equal(t, "root", ks.Entries[1].Alias)
+	assert.False(t, ks.Entries[1].PrivateKey)
+	assert.Len(t, ks.Entries[1].Certificates, 1)
+
+	ks, err = Open(FormatJKS, buildJKS(t, "k7#Qz!v9Lw2pXr", cert), CommonPasswords)
+	require.NoError(t, err)
+	assert.Nil(t, ks.Password)
+	assert.True(t, ks.HasKeys())
+}
+
+func TestOpen_JKSTruncated(t *testing.T) {
+	content := buildJKS(t, "changeit", testCertificate(t, "x"))
+	_, err := Open(FormatJKS, content[:40], CommonPasswords)
+	assert.Error(t, err)
+}
+
+func TestPBEDecrypt_IterationBound(t *testing.T) {
+	pbe, err := asn1.Marshal(pbeParams{Salt: []byte("salt"), Iterations: maxIterations + 1})
+	require.NoError(t, err)
+	_, err = pbeDecrypt(pkix.AlgorithmIdentifier{Algorithm: oidPBEWithSHA3DES, Parameters: asn1.RawValue{FullBytes: pbe}}, make([]byte, 16), "changeit")
+	assert.ErrorIs(t, err, errIterations)
+
+	kdf, err := asn1.Marshal(pbkdf2Params{Salt: []byte("salt"), IterationCount: 1 << 30})
+	require.NoError(t, err)
+	pbes2, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: kdf}},
+		EncryptionScheme:  pkix.AlgorithmIdentifier{Algorithm: oidAES256CBC},
+	})
+	require.NoError(t, err)
+	_, err = pbeDecrypt(pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: pbes2}}, make([]byte, 16), "changeit")
+	assert.ErrorIs(t, err, errIterations)
+
+	md := &macData{MacSalt: []byte("salt"), Iterations: 1 << 30}
+	md.Mac.Algorithm.Algorithm = oidSHA1
+	assert.False(t, verifyMAC(md, []byte("auth safe"), "changeit"))
+}
+
+func TestDetect(t *testing.T) {
+	assert.Equal(t, FormatJCEKS, Detect("keys.bin", []byte{0xce, 0xce, 0xce, 0xce, 0, 0, 0, 2}))
+	assert.Equal(t, FormatPKCS12, Detect("app.jks", []byte{0x30, 0x82}))
+	assert.Empty(t, Detect("cert.pem", []byte{0x30, 0x82}))
+	assert.Empty(t, Detect("notes.p12", []byte("not a keystore")))
+}
+
+func ptr(s string) *string { return &s }
+
+func testCertificate(t *testing.T, commonName string) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Unix(0, 0),
+		NotAfter:     time.Unix(0, 0).AddDate(100, 0, 0),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	return der
+}
+
+// buildJKS writes a version 2 JKS keystore with a private key entry for
+// cert, whose key bytes are a placeholder, and a trusted certificate entry.
+func buildJKS(t *testing.T, password string, cert []byte) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	u32 := func(v uint32) { binary.Write(&b, binary.BigEndian, v) }
+	utf := func(s string) {
+		binary.Write(&b, binary.BigEndian, uint16(len(s)))
+		b.WriteString(s)
+	}
+
+	b.Write(jksMagic)
+	u32(2)
+	u32(2)
+
+	u32(jksPrivateKey)
+	utf("release")
+	b.Write(make([]byte, 8))
+	u32(16)
+	b.Write(bytes.Repeat([]byte{0xaa}, 16))
+	u32(1)
+	utf("X.509")
+	u32(uint32(len(cert)))
+	b.Write(cert)
+
+	u32(jksTrustedCert)
+	utf("root")
+	b.Write(make([]byte, 8))
+	utf("X.509")
+	u32(uint32(len(cert)))
+	b.Write(cert)
+
+	b.Write(jksDigest(password, b.Bytes()))
+	return b.Bytes()
+}
@@ -0,0 +1,515 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"hash"
+	"unicode/utf16"
+)
+
+var (
+	oidData          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidEncryptedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+
+	oidKeyBag           = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 1}
+	oidShroudedKeyBag   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	oidCertBag          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidSecretBag        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 5}
+	oidSafeContentsBag  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 6}
+	oidX509Certificate  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+	oidFriendlyName     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 20}
+	oidLocalKeyID       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 21}
+	oidPBEWithSHA3DES   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 3}
+	oidPBEWithSHA128RC2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 5}
+	oidPBEWithSHA40RC2  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 6}
+	oidPBES2            = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2           = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACWithSHA1     = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidSHA1             = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256           = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384           = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512           = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+	oidAES128CBC        = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC        = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC        = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDESEDE3CBC       = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+)
+
+type pfxPDU struct {
+	Version  int
+	AuthSafe contentInfo
+	MacData  macData `asn1:"optional"`
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0,explicit,optional"`
+}
+
+type macData struct {
+	Mac struct {
+		Algorithm pkix.AlgorithmIdentifier
+		Digest    []byte
+	}
+	MacSalt    []byte
+	Iterations int `asn1:"optional,default:1"`
+}
+
+type encryptedData struct {
+	Version              int
+	EncryptedContentInfo struct {
+		ContentType      asn1.ObjectIdentifier
+		Algorithm        pkix.AlgorithmIdentifier
+		EncryptedContent []byte `asn1:"tag:0,optional"`
+	}
+}
+
+type safeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue `asn1:"tag:0,explicit"`
+	Attributes []struct {
+		ID    asn1.ObjectIdentifier
+		Value asn1.RawValue `asn1:"set"`
+	} `asn1:"set,optional"`
+}
+
+type certBag struct {
+	ID   asn1.ObjectIdentifier
+	Data []byte `asn1:"tag:0,explicit"`
+}
+
+type pbeParams struct {
+	Salt       []byte
+	Iterations int
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// errWrongPassword is returned when decryption fails with a password.
+var errWrongPassword = errors.New("wrong password")
+
+// maxIterations bounds the iteration counts of the key derivations, which
+// are read from the file and rerun for every password tried. OpenSSL uses
+// 2048 and Java 10000 by default.
+const maxIterations = 1_000_000
+
+// errIterations is returned for a key derivation whose iteration count is
+// out of bounds.
+var errIterations = errors.New("iteration count out of bounds")
+
+// openPKCS12 reads a PKCS#12 file. Key and certificate bags stored in plain
+// data are read without a password; encrypted safes, which usually hold the
+// certificates, only when one of passwords opens the file.
+func openPKCS12(content []byte, passwords []string) (*Keystore, error) {
+	var pfx pfxPDU
+	if _, err := asn1.Unmarshal(content, &pfx); err != nil {
+		return nil, fmt.Errorf("parsing pkcs12: %w", err)
+	}
+	if pfx.Version != 3 || !pfx.AuthSafe.ContentType.Equal(oidData) {
+		return nil, fmt.Errorf("unsupported pkcs12 version %d", pfx.Version)
+	}
+	var authSafe []byte
+	if _, err := asn1.Unmarshal(pfx.AuthSafe.Content.Bytes, &authSafe); err != nil {
+		return nil, fmt.Errorf("parsing pkcs12 auth safe: %w", err)
+	}
+	var safes []contentInfo
+	if _, err := asn1.Unmarshal(authSafe, &safes); err != nil {
+		return nil, fmt.Errorf("parsing pkcs12 auth safe: %w", err)
+	}
+
+	ks := &Keystore{Format: FormatPKCS12}
+	_, macSupported := digestHash(pfx.MacData.Mac.Algorithm.Algorithm)
+	macChecked := len(pfx.MacData.Mac.Digest) > 0 && macSupported
+	if macChecked {
+		for _, pw := range passwords {
+			if verifyMAC(&pfx.MacData, authSafe, pw) {
+				ks.setPassword(pw)
+				break
+			}
+		}
+	}
+
+	var bags []safeBag
+	for _, ci := range safes {
+		switch {
+		case ci.ContentType.Equal(oidData):
+			var data []byte
+			if _, err := asn1.Unmarshal(ci.Content.Bytes, &data); err != nil {
+				return nil, fmt.Errorf("parsing pkcs12 safe: %w", err)
+			}
+			safeBags, err := parseSafeContents(data)
+			if err != nil {
+				return nil, err
+			}
+			bags = append(bags, safeBags...)
+		case ci.ContentType.Equal(oidEncryptedData):
+			safeBags, err := ks.decryptSafe(ci.Content.Bytes, passwords, macChecked)
+			if err != nil {
+				ks.Encrypted = true
+				continue
+			}
+			bags = append(bags, safeBags...)
+		}
+	}
+	ks.addBags(bags)
+	return ks, nil
+}
+
+// decryptSafe decrypts an encryptedData safe with the password verified by
+// the MAC, or, if there was no MAC to check passwords against, with the first
+// of passwords that decrypts it.
+func (ks *Keystore) decryptSafe(raw []byte, passwords []string, macChecked bool) ([]safeBag, error) {
+	var ed encryptedData
+	if _, err := asn1.Unmarshal(raw, &ed); err != nil {
+		return nil, fmt.Errorf("parsing pkcs12 encrypted safe: %w", err)
+	}
+	eci := ed.EncryptedContentInfo
+	candidates := passwords
+	if ks.Password != nil {
+		candidates = []string{*ks.Password}
+	} else if macChecked {
+		return nil, errWrongPassword
+	}
+	for _, pw := range candidates {
+		data, err := pbeDecrypt(eci.Algorithm, eci.EncryptedContent, pw)
+		if err != nil {
+			continue
+		}
+		bags, err := parseSafeContents(data)
+		if err != nil {
+			continue
+		}
+		ks.setPassword(pw)
+		return bags, nil
+	}
+	return nil, errWrongPassword
+}
+
+func parseSafeContents(data []byte) ([]safeBag, error) {
+	var bags []safeBag
+	if _, err := asn1.Unmarshal(data, &bags); err != nil {
+		return nil, fmt.Errorf("parsing pkcs12 safe contents: %w", err)
+	}
+	var out []safeBag
+	for _, bag := range bags {
+		if bag.ID.Equal(oidSafeContentsBag) {
+			nested, err := parseSafeContents(bag.Value.FullBytes)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, nested...)
+			continue
+		}
+		out = append(out, bag)
+	}
+	return out, nil
+}
+
+// addBags turns bags into entries: one for each key or secret, with the
+// certificates sharing its local key ID, and one for each other certificate.
+func (ks *Keystore) addBags(bags []safeBag) {
+	byKeyID := make(map[string]*Entry)
+	var certs []safeBag
+	for _, bag := range bags {
+		alias, keyID := bagAttributes(bag)
+		switch {
+		case bag.ID.Equal(oidKeyBag) || bag.ID.Equal(oidShroudedKeyBag):
+			ks.Entries = append(ks.Entries, &Entry{Alias: alias, PrivateKey: true, keyID: keyID})
+		case bag.ID.Equal(oidSecretBag):
+			ks.Entries = append(ks.Entries, &Entry{Alias: alias, SecretKey: true, keyID: keyID})
+		case bag.ID.Equal(oidCertBag):
+			certs = append(certs, bag)
+			continue
+		default:
+			continue
+		}
+		if len(keyID) > 0 {
+			byKeyID[string(keyID)] = ks.Entries[len(ks.Entries)-1]
+		}
+	}
+	for _, bag := range certs {
+		var cb certBag
+		if _, err := asn1.Unmarshal(bag.Value.Bytes, &cb); err != nil || !cb.ID.Equal(oidX509Certificate) {
+			continue
+		}
+		cert, err := x509.ParseCertificate(cb.Data)
+		if err != nil {
+			continue
+		}
+		alias, keyID := bagAttributes(bag)
+		if e, ok := byKeyID[string(keyID)]; ok && len(keyID) > 0 {
+			e.Certificates = append(e.Certificates, cert)
+			continue
+		}
+		ks.Entries = append(ks.Entries, &Entry{Alias: alias, Certificates: []*x509.Certificate{cert}})
+	}
+}
+
+// bagAttributes returns the friendly name and local key ID of a bag.
+func bagAttributes(bag safeBag) (alias string, keyID []byte) {
+	for _, attr := range bag.Attributes {
+		switch {
+		case attr.ID.Equal(oidFriendlyName):
+			var raw asn1.RawValue
+			if _, err := asn1.Unmarshal(attr.Value.Bytes, &raw); err == nil {
+				alias = decodeBMPString(raw.Bytes)
+			}
+		case attr.ID.Equal(oidLocalKeyID):
+			var id []byte
+			if _, err := asn1.Unmarshal(attr.Value.Bytes, &id); err == nil {
+				keyID = id
+			}
+		}
+	}
+	return alias, keyID
+}
+
+// verifyMAC reports whether password produces the MAC of the auth safe.
+// The empty password is tried both as an empty BMPString with its NUL
+// terminator and as no bytes at all, since implementations differ.
+func verifyMAC(md *macData, authSafe []byte, password string) bool {
+	h, ok := digestHash(md.Mac.Algorithm.Algorithm)
+	if !ok || md.Iterations <= 0 || md.Iterations > maxIterations {
+		return false
+	}
+	encodings := [][]byte{bmpPassword(password)}
+	if password == "" {
+		encodings = append(encodings, nil)
+	}
+	for _, pw := range encodings {
+		key := pkcs12KDF(h, md.MacSalt, pw, md.Iterations, 3, h().Size())
+		mac := hmac.New(h, key)
+		mac.Write(authSafe)
+		if hmac.Equal(mac.Sum(nil), md.Mac.Digest) {
+			return true
+		}
+	}
+	return false
+}
+
+func digestHash(oid asn1.ObjectIdentifier) (func() hash.Hash, bool) {
+	switch {
+	case oid.Equal(oidSHA1):
+		return sha1.New, true
+	case oid.Equal(oidSHA256):
+		return sha256.New, true
+	case oid.Equal(oidSHA384):
+		return sha512.New384, true
+	case oid.Equal(oidSHA512):
+		return sha512.New, true
+	}
+	return nil, false
+}
+
+// pbeDecrypt decrypts data encrypted with a PKCS#12 or PBES2 password-based
+// scheme.
+func pbeDecrypt(alg pkix.AlgorithmIdentifier, data []byte, password string) ([]byte, error) {
+	var block cipher.Block
+	var iv []byte
+	switch {
+	case alg.Algorithm.Equal(oidPBEWithSHA3DES), alg.Algorithm.Equal(oidPBEWithSHA128RC2), alg.Algorithm.Equal(oidPBEWithSHA40RC2):
+		var params pbeParams
+		if _, err := asn1.Unmarshal(alg.Parameters.FullBytes, &params); err != nil {
+			return nil, err
+		}
+		if params.Iterations <= 0 || params.Iterations > maxIterations {
+			return nil, errIterations
+		}
+		pw := bmpPassword(password)
+		iv = pkcs12KDF(sha1.New, params.Salt, pw, params.Iterations, 2, 8)
+		switch {
+		case alg.Algorithm.Equal(oidPBEWithSHA3DES):
+			var err error
+			if block, err = des.NewTripleDESCipher(pkcs12KDF(sha1.New, params.Salt, pw, params.Iterations, 1, 24)); err != nil {
+				return nil, err
+			}
+		case alg.Algorithm.Equal(oidPBEWithSHA128RC2):
+			block = newRC2(pkcs12KDF(sha1.New, params.Salt, pw, params.Iterations, 1, 16), 128)
+		default:
+			block = newRC2(pkcs12KDF(sha1.New, params.Salt, pw, params.Iterations, 1, 5), 40)
+		}
+	case alg.Algorithm.Equal(oidPBES2):
+		var err error
+		if block, iv, err = pbes2Cipher(alg.Parameters.FullBytes, password); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported encryption algorithm %s", alg.Algorithm)
+	}
+
+	if len(data) == 0 || len(data)%block.BlockSize() != 0 || len(iv) != block.BlockSize() {
+		return nil, errWrongPassword
+	}
+	out := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, data)
+	return unpad(out, block.BlockSize())
+}
+
+// pbes2Cipher derives the cipher and IV of PBES2 parameters. The password
+// is used as UTF-8, as OpenSSL does for PKCS#12.
+func pbes2Cipher(raw []byte, password string) (cipher.Block, []byte, error) {
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(raw, &params); err != nil {
+		return nil, nil, err
+	}
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, nil, fmt.Errorf("unsupported key derivation function %s", params.KeyDerivationFunc.Algorithm)
+	}
+	var kdf pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdf); err != nil {
+		return nil, nil, err
+	}
+	if kdf.IterationCount <= 0 || kdf.IterationCount > maxIterations {
+		return nil, nil, errIterations
+	}
+	prf := sha1.New
+	switch prfOID := kdf.PRF.Algorithm; {
+	case len(prfOID) == 0, prfOID.Equal(oidHMACWithSHA1):
+	case prfOID.Equal(oidHMACWithSHA256):
+		prf = sha256.New
+	case prfOID.Equal(oidHMACWithSHA384):
+		prf = sha512.New384
+	case prfOID.Equal(oidHMACWithSHA512):
+		prf = sha512.New
+	default:
+		return nil, nil, fmt.Errorf("unsupported pbkdf2 prf %s", prfOID)
+	}
+
+	var keyLen int
+	newCipher := aes.NewCipher
+	switch enc := params.EncryptionScheme.Algorithm; {
+	case enc.Equal(oidAES128CBC):
+		keyLen = 16
+	case enc.Equal(oidAES192CBC):
+		keyLen = 24
+	case enc.Equal(oidAES256CBC):
+		keyLen = 32
+	case enc.Equal(oidDESEDE3CBC):
+		keyLen = 24
+		newCipher = des.NewTripleDESCipher
+	default:
+		return nil, nil, fmt.Errorf("unsupported encryption scheme %s", enc)
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, nil, err
+	}
+	key, err := pbkdf2.Key(prf, password, kdf.Salt, kdf.IterationCount, keyLen)
+	if err != nil {
+		return nil, nil, err
+	}
+	block, err := newCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return block, iv, nil
+}
+
+// unpad removes PKCS#7 padding, failing as a wrong password would if it's
+// malformed.
+func unpad(data []byte, blockSize int) ([]byte, error) {
+	n := int(data[len(data)-1])
+	if n == 0 || n > blockSize || n > len(data) {
+		return nil, errWrongPassword
+	}
+	if !bytes.Equal(data[len(data)-n:], bytes.Repeat([]byte{byte(n)}, n)) {
+		return nil, errWrongPassword
+	}
+	return data[:len(data)-n], nil
+}
+
+// pkcs12KDF derives key material from a password as in RFC 7292, appendix
+// B.2. id is 1 for keys, 2 for IVs and 3 for MAC keys.
+func pkcs12KDF(h func() hash.Hash, salt, password []byte, iterations int, id byte, size int) []byte {
+	d := h()
+	u, v := d.Size(), d.BlockSize()
+
+	fill := func(b []byte) []byte {
+		if len(b) == 0 {
+			return nil
+		}
+		out := make([]byte, v*((len(b)+v-1)/v))
+		for i := range out {
+			out[i] = b[i%len(b)]
+		}
+		return out
+	}
+	I := append(fill(salt), fill(password)...)
+	D := bytes.Repeat([]byte{id}, v)
+
+	var out []byte
+	for len(out) < size {
+		d.Reset()
+		d.Write(D)
+		d.Write(I)
+		a := d.Sum(nil)
+		for i := 1; i < iterations; i++ {
+			d.Reset()
+			d.Write(a)
+			a = d.Sum(a[:0])
+		}
+		out = append(out, a...)
+		if len(out) >= size {
+			break
+		}
+
+		// I_j = (I_j + B + 1) mod 2^(8v) for each v-byte block I_j of I
+		b := make([]byte, v)
+		for i := range b {
+			b[i] = a[i%u]
+		}
+		for j := 0; j < len(I); j += v {
+			carry := 1
+			for k := v - 1; k >= 0; k-- {
+				sum := int(I[j+k]) + int(b[k]) + carry
+				I[j+k] = byte(sum)
+				carry = sum >> 8
+			}
+		}
+	}
+	return out[:size]
+}
+
+// bmpPassword encodes a password as a NUL-terminated big-endian UTF-16
+// string, as PKCS#12 key derivation expects.
+func bmpPassword(password string) []byte {
+	units := utf16.Encode([]rune(password))
+	out := make([]byte, 0, 2*len(units)+2)
+	for _, c := range units {
+		out = append(out, byte(c>>8), byte(c))
+	}
+	return append(out, 0, 0)
+}
+
+func decodeBMPString(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
@@ -0,0 +1,63 @@
+package matcher
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Matcher backend names accepted by NewBackend.
+const (
+	// BackendVectorscan uses Hyperscan/Vectorscan as a prefilter for regexp2
+	// (requires the vectorscan build tag and CGO).
+	BackendVectorscan = "vectorscan"
+	// BackendPortable uses regexp2, matching large blobs with a worker per CPU.
+	BackendPortable = "portable"
+	// BackendRegexp uses regexp2 in a single pass, as in WASM builds.
+	BackendRegexp = "regexp"
+)
+
+// backends maps each backend available in this build to the constructor of
+// its inner matcher. Entries are registered by the files that implement them.
+var backends = map[string]func(Config) (Matcher, error){}
+
+// Backends returns the matcher backends available in this build, with the
+// one New uses first.
+func Backends() []string {
+	names := []string{defaultBackend}
+	var rest []string
+	for name := range backends {
+		if name != defaultBackend {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	return append(names, rest...)
+}
+
+// NewBackend creates a matcher like New, using the named backend instead of
+// the build's default.
+func NewBackend(name string, cfg Config) (Matcher, error) {
+	newInner, ok := backends[name]
+	if !ok {
+		return nil, fmt.Errorf("matcher backend %q is not available in this build (available: %v)", name, Backends())
+	}
+	cfg.Rules = contentRules(cfg.Rules) // filename rules are matched by FilenameMatcher
+	inner, err := newInner(cfg)
+	if err != nil {
+		return nil, err
+	}
+	filtered := newFilteringMatcher(inner, cfg.Rules)
+	correlated := newCorrelatingMatcher(filtered, cfg.Rules, cfg.KeepCorrelationComponents)
+	return newDecodingMatcher(newDedupMatcher(correlated, cfg.Rules), cfg.Rules), nil
+}
+
+func init() {
+	backends[BackendRegexp] = func(cfg Config) (Matcher, error) {
+		inner, err := NewRegexp(cfg.Rules, cfg.ContextLines, cfg.WarnFunc)
+		if err != nil {
+			return nil, err
+		}
+		inner.context.Bytes = cfg.ContextBytes
+		return inner, nil
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !wasm
+
+package matcher
+
+func init() {
+	backends[BackendPortable] = func(cfg Config) (Matcher, error) {
+		inner, err := NewPortableRegexp(cfg.Rules, cfg.ContextLines, cfg.WarnFunc)
+		if err != nil {
+			return nil, err
+		}
+		inner.context.Bytes = cfg.ContextBytes
+		return inner, nil
+	}
+}
@@ -0,0 +1,130 @@
+// Package bench measures matcher throughput: how fast each matcher backend
+// scans a corpus, how much it allocates, and which rules cost the most time.
+// It complements pkg/rule/bench, which measures rule accuracy.
+package bench
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/matcher"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// Options configures a throughput run.
+type Options struct {
+	Iterations int  // passes over the corpus (minimum 1)
+	PerRule    bool // also time each rule on its own
+}
+
+// RuleTiming is the time one rule takes to scan the corpus on its own.
+type RuleTiming struct {
+	RuleID   string        `json:"rule_id"`
+	RuleName string        `json:"rule_name"`
+	Duration time.Duration `json:"duration_ns"`
+	Matches  int           `json:"matches"`
+}
+
+// Result is the outcome of scanning a corpus with one backend. Counts and
+// allocations are per pass over the corpus.
+type Result struct {
+	Backend    string        `json:"backend"`
+	Blobs      int           `json:"blobs"`
+	Bytes      int64         `json:"bytes"`
+	Matches    int           `json:"matches"`
+	Iterations int           `json:"iterations"`
+	Setup      time.Duration `json:"setup_ns"`    // compiling the rules
+	Duration   time.Duration `json:"duration_ns"` // mean time per pass
+	Allocs     uint64        `json:"allocs"`
+	AllocBytes uint64        `json:"alloc_bytes"`
+	Rules      []RuleTiming  `json:"rules,omitempty"` // slowest first
+}
+
+// MBPerSec returns the scan throughput in megabytes per second.
+func (r *Result) MBPerSec() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Bytes) / (1 << 20) / r.Duration.Seconds()
+}
+
+// Run scans corpus with the named backend and rules.
+func Run(backend string, rules []*types.Rule, corpus []Blob, opts Options) (*Result, error) {
+	iterations := max(opts.Iterations, 1)
+	result := &Result{Backend: backend, Blobs: len(corpus), Iterations: iterations}
+	for _, blob := range corpus {
+		result.Bytes += int64(len(blob.Content))
+	}
+
+	start := time.Now()
+	m, err := matcher.NewBackend(backend, matcher.Config{Rules: rules, ContextLines: 3})
+	if err != nil {
+		return nil, err
+	}
+	defer m.Close()
+	result.Setup = time.Since(start)
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start = time.Now()
+	for i := 0; i < iterations; i++ {
+		matches, err := scan(m, corpus)
+		if err != nil {
+			return nil, err
+		}
+		result.Matches = matches
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	result.Duration = elapsed / time.Duration(iterations)
+	result.Allocs = (after.Mallocs - before.Mallocs) / uint64(iterations)
+	result.AllocBytes = (after.TotalAlloc - before.TotalAlloc) / uint64(iterations)
+
+	if opts.PerRule {
+		if result.Rules, err = timeRules(backend, rules, corpus); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// scan matches every blob in the corpus and returns the number of matches.
+func scan(m matcher.Matcher, corpus []Blob) (int, error) {
+	n := 0
+	for _, blob := range corpus {
+		matches, err := m.Match(blob.Content)
+		if err != nil {
+			return 0, fmt.Errorf("matching %s: %w", blob.Name, err)
+		}
+		n += len(matches)
+	}
+	return n, nil
+}
+
+// timeRules scans the corpus once per rule, with a matcher holding only
+// that rule, and returns the timings slowest first.
+func timeRules(backend string, rules []*types.Rule, corpus []Blob) ([]RuleTiming, error) {
+	timings := make([]RuleTiming, 0, len(rules))
+	for _, r := range rules {
+		m, err := matcher.NewBackend(backend, matcher.Config{Rules: []*types.Rule{r}, ContextLines: 3})
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", r.ID, err)
+		}
+		start := time.Now()
+		matches, err := scan(m, corpus)
+		elapsed := time.Since(start)
+		m.Close()
+		if err != nil {
+			return nil, err
+		}
+		timings = append(timings, RuleTiming{RuleID: r.ID, RuleName: r.Name, Duration: elapsed, Matches: matches})
+	}
+	sort.SliceStable(timings, func(i, j int) bool {
+		return timings[i].Duration > timings[j].Duration
+	})
+	return timings, nil
+}
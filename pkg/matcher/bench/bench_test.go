@@ -0,0 +1,104 @@
+package bench
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/matcher"
+	"github.com/praetorian-inc/titus/pkg/rule"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRules(t testing.TB) []*types.Rule {
+	rules := []*types.Rule{
+		{ID: "test.aws", Name: "AWS", Pattern: `\b(AKIA[A-Z2-7]{16})\b`},
+		{ID: "test.github", Name: "GitHub", Pattern: `\b(ghp_[A-Za-z0-9]{36})\b`},
+	}
+	for _, r := range rules {
+		r.StructuralID = r.ComputeStructuralID()
+	}
+	return rules
+}
+
+func TestBundledCorpus(t *testing.T) {
+	corpus := BundledCorpus(3 << 20)
+	again := BundledCorpus(3 << 20)
+	assert.Equal(t, corpus, again, "the bundled corpus is deterministic")
+
+	total := 0
+	for _, blob := range corpus {
+		assert.LessOrEqual(t, len(blob.Content), maxBundledBlob)
+		total += len(blob.Content)
+	}
+	assert.Equal(t, 3<<20, total)
+	assert.Len(t, corpus, 5, "one blob of each shape")
+}
+
+func TestRun(t *testing.T) {
+	corpus := BundledCorpus(256 << 10)
+	for _, backend := range matcher.Backends() {
+		t.Run(backend, func(t *testing.T) {
+			result, err := Run(backend, testRules(t), corpus, Options{Iterations: 2, PerRule: true})
+			require.NoError(t, err)
+			assert.Equal(t, backend, result.Backend)
+			assert.Equal(t, int64(256<<10), result.Bytes)
+			assert.Greater(t, result.Matches, 0)
+			assert.Greater(t, result.MBPerSec(), 0.0)
+			assert.Greater(t, result.Allocs, uint64(0))
+
+			require.Len(t, result.Rules, 2)
+			assert.GreaterOrEqual(t, result.Rules[0].Duration, result.Rules[1].Duration)
+			assert.Equal(t, result.Matches, result.Rules[0].Matches+result.Rules[1].Matches)
+		})
+	}
+
+	_, err := Run("nonexistent", testRules(t), corpus, Options{})
+	assert.ErrorContains(t, err, "not available")
+}
+
+func TestLoadCorpus(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("small"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "b.txt"), []byte("larger file"), 0644))
+
+	blobs, err := LoadCorpus(dir, 8)
+	require.NoError(t, err)
+	assert.Equal(t, []Blob{{Name: filepath.Join(dir, "a.txt"), Content: []byte("small")}}, blobs)
+
+	_, err = LoadCorpus(t.TempDir(), 0)
+	assert.Error(t, err)
+}
+
+// BenchmarkBackends scans the bundled corpus with the built-in rules on
+// every backend in this build. Compare runs with benchstat to catch
+// throughput or allocation regressions.
+func BenchmarkBackends(b *testing.B) {
+	rules, err := rule.NewLoader().LoadBuiltinRules()
+	require.NoError(b, err)
+	corpus := BundledCorpus(512 << 10)
+	var size int64
+	for _, blob := range corpus {
+		size += int64(len(blob.Content))
+	}
+
+	for _, backend := range matcher.Backends() {
+		b.Run(backend, func(b *testing.B) {
+			m, err := matcher.NewBackend(backend, matcher.Config{Rules: rules, ContextLines: 3})
+			require.NoError(b, err)
+			defer m.Close()
+
+			b.SetBytes(size)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := scan(m, corpus); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package bench
+
+import (
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Blob is one input of a throughput corpus.
+type Blob struct {
+	Name    string
+	Content []byte
+}
+
+// maxBundledBlob caps the size of each generated blob.
+const maxBundledBlob = 1 << 20
+
+// bundledShapes generate the content shapes that stress matchers differently.
+var bundledShapes = []struct {
+	name string
+	line func(r *rand.Rand) string
+	join string
+}{
+	// Source files with a secret every few dozen lines
+	{"source.go", sourceLine, "\n"},
+	// Minified bundles: one very long line
+	{"bundle.min.js", minifiedToken, ";"},
+	// Match-dense files, such as lockfiles or dumps full of credentials
+	{"dense.env", denseLine, "\n"},
+	// Non-ASCII text, where regexp2's rune offsets differ from byte offsets
+	{"unicode.md", unicodeLine, "\n"},
+	// Logs with stray high bytes
+	{"service.log", logLine, "\n"},
+}
+
+// BundledCorpus returns a synthetic corpus of size bytes. It is
+// generated from a fixed seed, so results are comparable between runs and
+// releases.
+func BundledCorpus(size int) []Blob {
+	r := rand.New(rand.NewSource(1))
+	// Split small corpora evenly so that every shape is represented
+	perBlob := min(maxBundledBlob, (size+len(bundledShapes)-1)/len(bundledShapes))
+	var blobs []Blob
+	for total, i := 0, 0; total < size; i++ {
+		shape := bundledShapes[i%len(bundledShapes)]
+		limit := min(perBlob, size-total)
+
+		var b strings.Builder
+		for b.Len() < limit {
+			b.WriteString(shape.line(r))
+			b.WriteString(shape.join)
+		}
+		blobs = append(blobs, Blob{
+			Name:    fmt.Sprintf("%d-%s", i/len(bundledShapes), shape.name),
+			Content: []byte(b.String()[:limit]),
+		})
+		total += limit
+	}
+	return blobs
+}
+
+// LoadCorpus reads the regular files under dir, skipping files larger than
+// maxFileSize bytes (0 = no limit).
+func LoadCorpus(dir string, maxFileSize int64) ([]Blob, error) {
+	var blobs []Blob
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err != nil || (maxFileSize > 0 && info.Size() > maxFileSize) {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", path, err)
+		}
+		blobs = append(blobs, Blob{Name: path, Content: content})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("loading corpus: %w", err)
+	}
+	if len(blobs) == 0 {
+		return nil, fmt.Errorf("corpus %s has no files", dir)
+	}
+	return blobs, nil
+}
+
+const (
+	upperDigits = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+	alnum       = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+func randString(r *rand.Rand, charset string, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = charset[r.Intn(len(charset))]
+	}
+	return string(b)
+}
+
+// fakeSecret returns a value shaped like a common provider credential.
+func fakeSecret(r *rand.Rand) string {
+	switch r.Intn(5) {
+	case 0:
+		return "AKIA" + randString(r, upperDigits, 16)
+	case 1:
+		return "ghp_" + randString(r, alnum, 36)
+	case 2:
+		return "xoxb-" + randString(r, "0123456789", 12) + "-" + randString(r, "0123456789", 12) + "-" + randString(r, alnum, 24)
+	case 3:
+		return "sk_live_" + randString(r, alnum, 24)
+	default:
+		return "glpat-" + randString(r, alnum, 20)
+	}
+}
+
+var identifiers = []string{"client", "config", "request", "handler", "buffer", "result", "session", "token", "user", "value"}
+
+func ident(r *rand.Rand) string {
+	return identifiers[r.Intn(len(identifiers))]
+}
+
+func sourceLine(r *rand.Rand) string {
+	switch n := r.Intn(40); {
+	case n == 0:
+		return fmt.Sprintf("\tapiKey := %q", fakeSecret(r))
+	case n < 10:
+		return fmt.Sprintf("\t%s, err := %s.Get(ctx, %q)", ident(r), ident(r), ident(r))
+	case n < 20:
+		return fmt.Sprintf("\tif err != nil {\n\t\treturn nil, fmt.Errorf(\"%s: %%w\", err)\n\t}", ident(r))
+	default:
+		return fmt.Sprintf("\t// %s updates the %s for each %s", ident(r), ident(r), ident(r))
+	}
+}
+
+func minifiedToken(r *rand.Rand) string {
+	if r.Intn(200) == 0 {
+		return fmt.Sprintf("var %s=%q", ident(r)[:1], fakeSecret(r))
+	}
+	return fmt.Sprintf("function %s(%s){return %s.%s(%d)}", ident(r)[:2], ident(r)[:1], ident(r)[:1], ident(r), r.Intn(1000))
+}
+
+func denseLine(r *rand.Rand) string {
+	return fmt.Sprintf("%s_%s=%s", strings.ToUpper(ident(r)), strings.ToUpper(ident(r)), fakeSecret(r))
+}
+
+var unicodeWords = []string{"café", "naïve", "日本語", "данные", "ключ", "größe", "😀", "ñandú", "jalapeño"}
+
+func unicodeLine(r *rand.Rand) string {
+	words := make([]string, 8)
+	for i := range words {
+		words[i] = unicodeWords[r.Intn(len(unicodeWords))]
+	}
+	if r.Intn(20) == 0 {
+		words[r.Intn(len(words))] = "clé=" + fakeSecret(r)
+	}
+	return strings.Join(words, " ")
+}
+
+func logLine(r *rand.Rand) string {
+	line := fmt.Sprintf("2024-01-%02dT%02d:%02d:%02dZ level=info msg=%q id=%08x", r.Intn(28)+1, r.Intn(24), r.Intn(60), r.Intn(60), ident(r), r.Uint32())
+	if r.Intn(50) == 0 {
+		line += " auth=" + fakeSecret(r)
+	}
+	if r.Intn(10) == 0 {
+		line += " raw=" + string([]byte{0xc3, 0x28, 0xff, 0x00, 0x9f})
+	}
+	return line
+}
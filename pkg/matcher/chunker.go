@@ -2,6 +2,7 @@ package matcher
 
 import (
 	"bytes"
+	"unicode/utf8"
 
 	"github.com/praetorian-inc/titus/pkg/types"
 )
@@ -10,13 +11,19 @@ import (
 type ChunkConfig struct {
 	MaxChunkSize int // Maximum size of a chunk in bytes (default: 5MB)
 	OverlapLines int // Number of lines to overlap between chunks (default: 10)
+	// MaxSecretLength is the length in bytes of the longest match that must
+	// not be split: consecutive chunks overlap by at least this much, so any
+	// match up to this long lies wholly within one chunk (default: 64KB).
+	// It is capped at a quarter of MaxChunkSize.
+	MaxSecretLength int
 }
 
 // DefaultChunkConfig returns production defaults
 func DefaultChunkConfig() ChunkConfig {
 	return ChunkConfig{
-		MaxChunkSize: 5 * 1024 * 1024, // 5MB
-		OverlapLines: 10,
+		MaxChunkSize:    5 * 1024 * 1024, // 5MB
+		OverlapLines:    10,
+		MaxSecretLength: 64 * 1024, // 64KB, well beyond a PEM block
 	}
 }
 
@@ -28,13 +35,17 @@ type Chunk struct {
 	Index       int    // Chunk number (0-indexed)
 }
 
-// ChunkContent splits content at line boundaries with overlap
-// If content is smaller than MaxChunkSize, returns a single chunk
-// Otherwise splits at newlines, ensuring chunks don't exceed MaxChunkSize
-// and overlap by OverlapLines between consecutive chunks
+// ChunkContent splits content into overlapping chunks of at most
+// MaxChunkSize bytes. If content is no larger, it returns a single chunk.
+//
+// Chunk boundaries are snapped back to the end of a line, or failing that
+// to whitespace, so that they rarely fall inside a match. Each chunk starts
+// at least MaxSecretLength bytes before the previous one ends, and at least
+// OverlapLines lines before when that is not too far back, again snapped to
+// the start of a line. Chunks share content's memory.
 func ChunkContent(content []byte, config ChunkConfig) []Chunk {
 	// If content fits in a single chunk, return it
-	if len(content) <= config.MaxChunkSize {
+	if config.MaxChunkSize <= 0 || len(content) <= config.MaxChunkSize {
 		return []Chunk{{
 			Content:     content,
 			StartOffset: 0,
@@ -43,86 +54,68 @@ func ChunkContent(content []byte, config ChunkConfig) []Chunk {
 		}}
 	}
 
-	var chunks []Chunk
-	lines := bytes.Split(content, []byte("\n"))
-
-	// Edge case: empty content returns empty line slice
-	if len(lines) == 0 {
-		return []Chunk{{
-			Content:     content,
-			StartOffset: 0,
-			EndOffset:   len(content),
-			Index:       0,
-		}}
-	}
-
-	var currentChunk []byte
-	var chunkStartOffset int
-	var overlapStartLine int
+	size := config.MaxChunkSize
+	overlap := min(max(config.MaxSecretLength, 0), size/4)
 
-	for lineIdx := 0; lineIdx < len(lines); lineIdx++ {
-		line := lines[lineIdx]
-
-		// Add newline back (except for last line)
-		lineWithNewline := line
-		if lineIdx < len(lines)-1 {
-			lineWithNewline = append(line, '\n')
-		}
-
-		// Check if adding this line would exceed chunk size
-		if len(currentChunk)+len(lineWithNewline) > config.MaxChunkSize && len(currentChunk) > 0 {
-			// Save current chunk (keep newlines intact for pattern matching)
-			chunks = append(chunks, Chunk{
-				Content:     currentChunk,
-				StartOffset: chunkStartOffset,
-				EndOffset:   chunkStartOffset + len(currentChunk),
-				Index:       len(chunks),
-			})
-
-			// Calculate overlap starting point
-			overlapStartLine = maxInt(0, lineIdx-config.OverlapLines)
-
-			// Find byte offset for overlap start
-			chunkStartOffset = 0
-			for i := 0; i < overlapStartLine; i++ {
-				chunkStartOffset += len(lines[i]) + 1 // +1 for newline
-			}
-
-			// Rebuild chunk from overlap point to current line
-			currentChunk = nil
-			for i := overlapStartLine; i < lineIdx; i++ {
-				currentChunk = append(currentChunk, lines[i]...)
-				if i < len(lines)-1 {
-					currentChunk = append(currentChunk, '\n')
-				}
-			}
+	var chunks []Chunk
+	start := 0
+	for {
+		end := len(content)
+		if end-start > size {
+			// Chunks are at least half the maximum size
+			end = snapBoundary(content, start+size/2, start+size)
 		}
-
-		// Add current line to chunk
-		currentChunk = append(currentChunk, lineWithNewline...)
-	}
-
-	// Don't forget the last chunk (keep newlines intact)
-	if len(currentChunk) > 0 {
 		chunks = append(chunks, Chunk{
-			Content:     currentChunk,
-			StartOffset: chunkStartOffset,
-			EndOffset:   len(content),
+			Content:     content[start:end:end],
+			StartOffset: start,
+			EndOffset:   end,
 			Index:       len(chunks),
 		})
+		if end == len(content) {
+			return chunks
+		}
+
+		// Overlap by MaxSecretLength bytes and OverlapLines lines, within a
+		// quarter of the maximum size so that chunks make progress
+		limit := end - size/4
+		next := end - overlap
+		if lines := linesBefore(content, end, config.OverlapLines, limit); lines < next {
+			next = lines
+		}
+		start = snapBoundary(content, limit, next)
 	}
+}
 
-	// If no chunks were created (shouldn't happen), return original as single chunk
-	if len(chunks) == 0 {
-		return []Chunk{{
-			Content:     content,
-			StartOffset: 0,
-			EndOffset:   len(content),
-			Index:       0,
-		}}
+// snapBoundary returns the boundary in content[lo:hi] closest to hi: a
+// position just after a newline, else just after a space or tab, else hi
+// itself, moved back to the start of a UTF-8 sequence.
+func snapBoundary(content []byte, lo, hi int) int {
+	if i := bytes.LastIndexByte(content[lo:hi], '\n'); i >= 0 {
+		return lo + i + 1
+	}
+	if i := bytes.LastIndexAny(content[lo:hi], " \t"); i >= 0 {
+		return lo + i + 1
 	}
+	for p := hi; p > lo && hi-p < utf8.UTFMax; p-- {
+		if utf8.RuneStart(content[p]) {
+			return p
+		}
+	}
+	return hi
+}
 
-	return chunks
+// linesBefore returns the start of the line n lines before the one starting
+// at end, looking no further back than lo.
+func linesBefore(content []byte, end, n, lo int) int {
+	p := end
+	for i := 0; i < n && p-1 > lo; i++ {
+		j := bytes.LastIndexByte(content[lo:p-1], '\n')
+		if j < 0 {
+			return lo
+		}
+		p = lo + j + 1
+	}
+	return p
 }
 
 // AdjustMatchOffset converts chunk-relative offsets to file-absolute offsets
@@ -130,11 +123,3 @@ func AdjustMatchOffset(match *types.Match, chunk Chunk) {
 	match.Location.Offset.Start += int64(chunk.StartOffset)
 	match.Location.Offset.End += int64(chunk.StartOffset)
 }
-
-// maxInt returns the maximum of two integers
-func maxInt(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
-}
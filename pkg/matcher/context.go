@@ -1,38 +1,95 @@
 package matcher
 
-// ExtractContext extracts N lines before and after a match.
-// Returns before, after byte slices that are independent copies (not sub-slices
-// of content), so storing them will not pin the original content in memory.
-// Handles file boundaries gracefully (returns empty if at start/end).
-// Context starts immediately before the start offset and ends immediately after the end offset.
-// The matched content itself (between start and end) is not duplicated in the context.
-func ExtractContext(content []byte, start, end int, lines int) (before, after []byte) {
-	if lines <= 0 {
-		return nil, nil
-	}
-	if start < 0 || start > len(content) {
-		return nil, nil
+import (
+	"unicode/utf8"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// ContextWindow bounds the context kept before and after a match.
+// Lines limits it to whole lines; Bytes limits it to a byte count, which
+// suits minified or binary-ish content where one line can be megabytes.
+// When both are set, the line window is trimmed to Bytes on each side.
+// Zero disables a limit; a window with neither set keeps no context.
+type ContextWindow struct {
+	Lines int
+	Bytes int
+}
+
+// enabled reports whether the window keeps any context.
+func (w ContextWindow) enabled() bool {
+	return w.Lines > 0 || w.Bytes > 0
+}
+
+// forRule applies a rule's context override, if any.
+func (w ContextWindow) forRule(rule *types.Rule) ContextWindow {
+	if rule == nil || rule.Context == nil {
+		return w
 	}
-	if end < 0 || end > len(content) {
-		return nil, nil
+	if rule.Context.Lines != nil {
+		w.Lines = *rule.Context.Lines
 	}
-	if start > end {
-		return nil, nil
+	if rule.Context.Bytes != nil {
+		w.Bytes = *rule.Context.Bytes
 	}
+	return w
+}
 
-	// Copy sub-slices to decouple from the original content backing array.
-	// Without this, storing a small context snippet keeps the entire
-	// file content pinned in memory via the shared underlying array.
-	if b := extractBefore(content, start, lines); len(b) > 0 {
+// Extract returns the context around content[start:end]. The slices are
+// independent copies, so storing them does not pin content in memory.
+func (w ContextWindow) Extract(content []byte, start, end int) (before, after []byte) {
+	b, a := w.around(content, start, end)
+	if len(b) > 0 {
 		before = append([]byte{}, b...)
 	}
-	if a := extractAfter(content, end, lines); len(a) > 0 {
+	if len(a) > 0 {
 		after = append([]byte{}, a...)
 	}
+	return before, after
+}
 
+// around returns the context around content[start:end] as sub-slices of
+// content.
+func (w ContextWindow) around(content []byte, start, end int) (before, after []byte) {
+	if !w.enabled() || start < 0 || end > len(content) || start > end {
+		return nil, nil
+	}
+
+	if w.Lines > 0 {
+		before, after = extractBefore(content, start, w.Lines), extractAfter(content, end, w.Lines)
+	} else {
+		before, after = content[:start], content[end:]
+	}
+	if w.Bytes <= 0 {
+		return before, after
+	}
+	// Trim to the byte budget without splitting a UTF-8 sequence.
+	if len(before) > w.Bytes {
+		before = before[len(before)-w.Bytes:]
+		for len(before) > 0 && !utf8.RuneStart(before[0]) {
+			before = before[1:]
+		}
+	}
+	if len(after) > w.Bytes {
+		cut := w.Bytes
+		for cut > 0 && !utf8.RuneStart(after[cut]) {
+			cut--
+		}
+		after = after[:cut]
+	}
 	return before, after
 }
 
+// ExtractContext extracts N lines before and after a match.
+// Returns before, after byte slices that are independent copies (not sub-slices
+// of content), so storing them will not pin the original content in memory.
+// Handles file boundaries gracefully (returns empty if at start/end).
+// Context starts immediately before the start offset and ends immediately after the end offset.
+// The matched content itself (between start and end) is not duplicated in the context.
+func ExtractContext(content []byte, start, end int, lines int) (before, after []byte) {
+	return ContextWindow{Lines: lines}.Extract(content, start, end)
+}
+
 // extractBefore finds N lines before the start offset.
 // Walks backward from start, counting newlines.
 func extractBefore(content []byte, start, lines int) []byte {
@@ -3,7 +3,9 @@ package matcher
 import (
 	"testing"
 
+	"github.com/praetorian-inc/titus/pkg/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestExtractContext(t *testing.T) {
@@ -271,3 +273,69 @@ func TestExtractContext_ReturnsIndependentCopies(t *testing.T) {
 	assert.Equal(t, expectedAfter, string(after),
 		"after context was corrupted after zeroing content — it shares the original backing array instead of being an independent copy")
 }
+
+func TestContextWindow_Extract(t *testing.T) {
+	content := []byte("line1\nvar a=1,b=2,key=\"SECRET\",c=3,d=4;\nline3\n")
+	start := len("line1\nvar a=1,b=2,key=\"")
+	end := start + len("SECRET")
+
+	tests := []struct {
+		name   string
+		window ContextWindow
+		before string
+		after  string
+	}{
+		{"disabled", ContextWindow{}, "", ""},
+		{"lines only", ContextWindow{Lines: 1}, "line1\nvar a=1,b=2,key=\"", "\",c=3,d=4;\n"},
+		{"bytes only", ContextWindow{Bytes: 5}, "key=\"", "\",c=3"},
+		{"lines capped by bytes", ContextWindow{Lines: 1, Bytes: 8}, "=2,key=\"", "\",c=3,d="},
+		{"bytes past boundaries", ContextWindow{Bytes: 1000}, "line1\nvar a=1,b=2,key=\"", "\",c=3,d=4;\nline3\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, after := tt.window.Extract(content, start, end)
+			assert.Equal(t, tt.before, string(before))
+			assert.Equal(t, tt.after, string(after))
+		})
+	}
+}
+
+func TestContextWindow_ExtractKeepsRunesWhole(t *testing.T) {
+	content := []byte("ééTOKENéé")
+	start := len("éé")
+	end := start + len("TOKEN")
+
+	// Three bytes would split the second é on either side
+	before, after := ContextWindow{Bytes: 3}.Extract(content, start, end)
+	assert.Equal(t, "é", string(before))
+	assert.Equal(t, "é", string(after))
+}
+
+func TestNew_ContextBytesAndRuleOverride(t *testing.T) {
+	zero, wide := 0, 12
+	rules := []*types.Rule{
+		{ID: "test.short", Name: "Short", Pattern: `TOKEN_[0-9]+`},
+		{ID: "test.wide", Name: "Wide", Pattern: `KEY_[0-9]+`, Context: &types.ContextOverride{Lines: &zero, Bytes: &wide}},
+	}
+	for _, r := range rules {
+		r.StructuralID = r.ComputeStructuralID()
+	}
+	m, err := New(Config{Rules: rules, ContextLines: 3, ContextBytes: 4})
+	require.NoError(t, err)
+	defer m.Close()
+
+	content := []byte("aaaaaaaaaaaa TOKEN_1 bbbbbbbbbbbb KEY_2 cccccccccccc")
+	matches, err := m.Match(content)
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+
+	snippets := make(map[string]types.Snippet)
+	for _, match := range matches {
+		snippets[match.RuleID] = match.Snippet
+	}
+	assert.Equal(t, "aaa ", string(snippets["test.short"].Before))
+	assert.Equal(t, " bbb", string(snippets["test.short"].After))
+	assert.Equal(t, "bbbbbbbbbbb ", string(snippets["test.wide"].Before))
+	assert.Equal(t, " ccccccccccc", string(snippets["test.wide"].After))
+}
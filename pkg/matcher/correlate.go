@@ -0,0 +1,225 @@
+package matcher
+
+import (
+	"sort"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// DefaultCorrelationDistance is the maximum number of bytes between component
+// matches when a rule's correlation does not set max_distance.
+const DefaultCorrelationDistance = 4096
+
+// Correlator pairs related credentials found near each other in a blob (an
+// access key ID and its secret, a client ID and client secret, a username and
+// password) into a single match of the correlated rule that declares them.
+//
+// Component matches that are paired are replaced by the composite match, so
+// one credential set yields one finding and validators see every part of it.
+// Matches of CorrelationOnly rules that cannot be paired are dropped unless
+// KeepUnpaired is set.
+type Correlator struct {
+	// KeepUnpaired keeps unpaired matches of CorrelationOnly rules so they can
+	// be stored and paired across blobs after the scan (see CorrelateBlobs).
+	KeepUnpaired bool
+
+	rules      map[string]*types.Rule
+	correlated []*types.Rule
+}
+
+// NewCorrelator creates a correlator for the correlated rules in rules.
+func NewCorrelator(rules []*types.Rule) *Correlator {
+	c := &Correlator{rules: make(map[string]*types.Rule, len(rules))}
+	for _, r := range rules {
+		c.rules[r.ID] = r
+		if r.Correlation != nil && len(r.Correlation.Components) > 1 {
+			c.correlated = append(c.correlated, r)
+		}
+	}
+	return c
+}
+
+// Correlate takes all matches from a single blob and returns them with
+// paired components replaced by composite matches.
+func (c *Correlator) Correlate(content []byte, matches []*types.Match) []*types.Match {
+	if len(matches) == 0 {
+		return matches
+	}
+
+	consumed := make(map[*types.Match]bool)
+	var composites []*types.Match
+	for _, r := range c.correlated {
+		composites = append(composites, c.correlateRule(r, content, matches, consumed)...)
+	}
+
+	out := make([]*types.Match, 0, len(matches)+len(composites))
+	for _, m := range matches {
+		if consumed[m] {
+			continue
+		}
+		if r, ok := c.rules[m.RuleID]; ok && r.CorrelationOnly && !c.KeepUnpaired {
+			continue
+		}
+		out = append(out, m)
+	}
+	return append(out, composites...)
+}
+
+// correlateRule builds composite matches for one correlated rule. The first
+// component anchors each composite; every other component contributes its
+// nearest unpaired match within the rule's maximum distance.
+func (c *Correlator) correlateRule(r *types.Rule, content []byte, matches []*types.Match, consumed map[*types.Match]bool) []*types.Match {
+	maxDistance := int64(r.Correlation.MaxDistance)
+	if maxDistance <= 0 {
+		maxDistance = DefaultCorrelationDistance
+	}
+
+	// Values already reported by a direct match of the correlated rule need no pairing
+	existing := make(map[string]bool)
+	for _, m := range matches {
+		if m.RuleID == r.ID {
+			for _, g := range m.Groups {
+				existing[string(g)] = true
+			}
+		}
+	}
+
+	candidates := make([][]*types.Match, len(r.Correlation.Components))
+	for i, comp := range r.Correlation.Components {
+		ids := make(map[string]bool, len(comp.RuleIDs))
+		for _, id := range comp.RuleIDs {
+			ids[id] = true
+		}
+		for _, m := range matches {
+			if ids[m.RuleID] && !consumed[m] && !existing[string(componentValue(m, comp.Group))] {
+				candidates[i] = append(candidates[i], m)
+			}
+		}
+		if len(candidates[i]) == 0 {
+			return nil
+		}
+	}
+
+	var composites []*types.Match
+	for _, anchor := range candidates[0] {
+		if consumed[anchor] {
+			continue
+		}
+		parts := []*types.Match{anchor}
+		for _, cands := range candidates[1:] {
+			best := nearestMatch(anchor, cands, parts, consumed, maxDistance)
+			if best == nil {
+				break
+			}
+			parts = append(parts, best)
+		}
+		if len(parts) != len(candidates) {
+			continue
+		}
+		for _, p := range parts {
+			consumed[p] = true
+		}
+		composites = append(composites, buildCorrelatedMatch(r, content, parts))
+	}
+	return composites
+}
+
+// nearestMatch returns the candidate closest to anchor that is not consumed,
+// not already part of the composite, and within maxDistance bytes.
+func nearestMatch(anchor *types.Match, cands, parts []*types.Match, consumed map[*types.Match]bool, maxDistance int64) *types.Match {
+	var best *types.Match
+	bestDistance := maxDistance + 1
+outer:
+	for _, m := range cands {
+		if consumed[m] {
+			continue
+		}
+		for _, p := range parts {
+			if p == m {
+				continue outer
+			}
+		}
+		if d := matchDistance(anchor, m); d < bestDistance {
+			best, bestDistance = m, d
+		}
+	}
+	return best
+}
+
+// matchDistance is the number of bytes between two match spans (0 if they overlap).
+func matchDistance(a, b *types.Match) int64 {
+	switch {
+	case a.Location.Offset.End <= b.Location.Offset.Start:
+		return b.Location.Offset.Start - a.Location.Offset.End
+	case b.Location.Offset.End <= a.Location.Offset.Start:
+		return a.Location.Offset.Start - b.Location.Offset.End
+	default:
+		return 0
+	}
+}
+
+// componentValue returns the value a match contributes to a composite: its
+// named group of the same name, else its only named group, else its first
+// capture, else the matched text.
+func componentValue(m *types.Match, group string) []byte {
+	if v, ok := m.NamedGroups[group]; ok {
+		return v
+	}
+	if len(m.NamedGroups) == 1 {
+		for _, v := range m.NamedGroups {
+			return v
+		}
+	}
+	if len(m.Groups) > 0 {
+		return m.Groups[0]
+	}
+	return m.Snippet.Matching
+}
+
+// buildCorrelatedMatch creates the composite match for rule r from its parts,
+// given in component order. The match spans from the first part to the last.
+func buildCorrelatedMatch(r *types.Rule, content []byte, parts []*types.Match) *types.Match {
+	groups := make([][]byte, len(parts))
+	named := make(map[string][]byte, len(parts))
+	for i, p := range parts {
+		v := componentValue(p, r.Correlation.Components[i].Group)
+		groups[i] = v
+		named[r.Correlation.Components[i].Group] = v
+	}
+
+	ordered := append([]*types.Match(nil), parts...)
+	sort.Slice(ordered, func(i, j int) bool {
+		return ordered[i].Location.Offset.Start < ordered[j].Location.Offset.Start
+	})
+	first, last := ordered[0], ordered[0]
+	for _, p := range ordered[1:] {
+		if p.Location.Offset.End > last.Location.Offset.End {
+			last = p
+		}
+	}
+	start, end := first.Location.Offset.Start, last.Location.Offset.End
+
+	matching := first.Snippet.Matching
+	if start >= 0 && end <= int64(len(content)) && start <= end {
+		matching = append([]byte(nil), content[start:end]...)
+	}
+
+	m := &types.Match{
+		BlobID:   first.BlobID,
+		RuleID:   r.ID,
+		RuleName: r.Name,
+		Location: types.Location{
+			Offset: types.OffsetSpan{Start: start, End: end},
+		},
+		Groups:      groups,
+		NamedGroups: named,
+		Snippet: types.Snippet{
+			Before:   first.Snippet.Before,
+			Matching: matching,
+			After:    last.Snippet.After,
+		},
+	}
+	m.StructuralID = m.ComputeStructuralID(r.StructuralID)
+	m.FindingID = types.ComputeFindingID(r.StructuralID, groups)
+	return m
+}
@@ -0,0 +1,217 @@
+package matcher
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// DefaultMaxBlobCombinations limits how many candidate credential sets are
+// assembled for each anchor match when correlating across blobs.
+const DefaultMaxBlobCombinations = 16
+
+// CorrelateBlobs assembles candidate credential sets from component matches
+// that live in different blobs of the same scope, such as an access key ID in
+// .env and its secret in config.yml. scopes maps each blob to the scopes it
+// belongs to (typically its directory or repository); blobs with no scope are
+// not paired.
+//
+// Each candidate is a composite match of a correlated rule, anchored on a
+// match of the rule's first component: it takes the anchor's blob, location
+// and snippet, and its groups hold one value per component. An anchor may
+// yield several candidates (at most DefaultMaxBlobCombinations per scope), all
+// sharing its StructuralID. Candidates are unverified guesses; callers are
+// expected to validate them and keep at most one per StructuralID.
+//
+// Values already reported by a match of the correlated rule are not paired
+// again, and candidates whose parts all come from one blob are skipped since
+// those were already considered by Correlate.
+func (c *Correlator) CorrelateBlobs(matches []*types.Match, scopes map[types.BlobID][]string) []*types.Match {
+	if len(matches) == 0 || len(c.correlated) == 0 {
+		return nil
+	}
+
+	// Scopes in first-seen order keep the output deterministic
+	var order []string
+	byScope := make(map[string][]*types.Match)
+	for _, m := range matches {
+		for _, scope := range scopes[m.BlobID] {
+			if _, ok := byScope[scope]; !ok {
+				order = append(order, scope)
+			}
+			byScope[scope] = append(byScope[scope], m)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var out []*types.Match
+	for _, r := range c.correlated {
+		existing := make(map[string]bool)
+		for _, m := range matches {
+			if m.RuleID == r.ID {
+				for _, g := range m.Groups {
+					existing[string(g)] = true
+				}
+			}
+		}
+		for _, scope := range order {
+			for _, cand := range c.correlateBlobsInScope(r, byScope[scope], existing) {
+				key := cand.StructuralID + "\x00" + groupsKey(cand.Groups)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				out = append(out, cand)
+			}
+		}
+	}
+	return out
+}
+
+// correlateBlobsInScope builds candidates for rule r from the matches of one scope.
+func (c *Correlator) correlateBlobsInScope(r *types.Rule, matches []*types.Match, existing map[string]bool) []*types.Match {
+	candidates := make([][]*types.Match, len(r.Correlation.Components))
+	for i, comp := range r.Correlation.Components {
+		ids := make(map[string]bool, len(comp.RuleIDs))
+		for _, id := range comp.RuleIDs {
+			ids[id] = true
+		}
+		values := make(map[string]bool)
+		for _, m := range matches {
+			if !ids[m.RuleID] {
+				continue
+			}
+			v := string(componentValue(m, comp.Group))
+			if existing[v] || values[v] {
+				continue
+			}
+			values[v] = true
+			candidates[i] = append(candidates[i], m)
+		}
+		if len(candidates[i]) == 0 {
+			return nil
+		}
+	}
+
+	var out []*types.Match
+	for _, anchor := range candidates[0] {
+		n := 0
+		combine(candidates[1:], []*types.Match{anchor}, func(parts []*types.Match) bool {
+			if sameBlob(parts) {
+				return true
+			}
+			out = append(out, buildBlobCorrelatedMatch(r, parts))
+			n++
+			return n < DefaultMaxBlobCombinations
+		})
+	}
+	return out
+}
+
+// combine calls fn with parts extended by one match from each of rest, in
+// order, until fn returns false. It reports whether enumeration should continue.
+func combine(rest [][]*types.Match, parts []*types.Match, fn func([]*types.Match) bool) bool {
+	if len(rest) == 0 {
+		return fn(parts)
+	}
+	for _, m := range rest[0] {
+		next := append(parts[:len(parts):len(parts)], m)
+		if !combine(rest[1:], next, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameBlob reports whether all parts come from one blob.
+func sameBlob(parts []*types.Match) bool {
+	for _, p := range parts[1:] {
+		if p.BlobID != parts[0].BlobID {
+			return false
+		}
+	}
+	return true
+}
+
+// buildBlobCorrelatedMatch creates the composite match for rule r from parts
+// found in different blobs, given in component order. The first part anchors
+// the match.
+func buildBlobCorrelatedMatch(r *types.Rule, parts []*types.Match) *types.Match {
+	groups := make([][]byte, len(parts))
+	named := make(map[string][]byte, len(parts))
+	for i, p := range parts {
+		v := componentValue(p, r.Correlation.Components[i].Group)
+		groups[i] = v
+		named[r.Correlation.Components[i].Group] = v
+	}
+
+	anchor := parts[0]
+	m := &types.Match{
+		BlobID:      anchor.BlobID,
+		RuleID:      r.ID,
+		RuleName:    r.Name,
+		Location:    anchor.Location,
+		Groups:      groups,
+		NamedGroups: named,
+		Snippet:     anchor.Snippet,
+	}
+	m.StructuralID = m.ComputeStructuralID(r.StructuralID)
+	m.FindingID = types.ComputeFindingID(r.StructuralID, groups)
+	return m
+}
+
+// groupsKey joins groups into a single comparable string.
+func groupsKey(groups [][]byte) string {
+	parts := make([]string, len(groups))
+	for i, g := range groups {
+		parts[i] = string(g)
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// BlobScopes returns the correlation scopes of a blob given its provenance:
+// the repository for git blobs and the containing directory for files.
+func BlobScopes(provs []types.Provenance) []string {
+	var scopes []string
+	for _, p := range provs {
+		var scope string
+		switch p := p.(type) {
+		case types.GitProvenance:
+			scope = "repo:" + p.RepoPath
+		case types.FileProvenance:
+			scope = "dir:" + parentDir(p.FilePath)
+		default:
+			continue
+		}
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+	return dedupStrings(scopes)
+}
+
+// parentDir returns the directory part of a slash- or backslash-separated path.
+func parentDir(path string) string {
+	i := strings.LastIndexAny(path, `/\`)
+	if i < 0 {
+		return "."
+	}
+	if i == 0 {
+		return path[:1]
+	}
+	return path[:i]
+}
+
+// dedupStrings removes adjacent duplicates from a sorted slice.
+func dedupStrings(s []string) []string {
+	if len(s) < 2 {
+		return s
+	}
+	out := s[:1]
+	for _, v := range s[1:] {
+		if v != out[len(out)-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,114 @@
+package matcher
+
+import (
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blobMatch creates a component match in the blob identified by b.
+func blobMatch(b byte, ruleID, group, value string) *types.Match {
+	m := spanMatch(ruleID, group, value, 0)
+	m.BlobID[0] = b
+	return m
+}
+
+func blobID(b byte) types.BlobID {
+	var id types.BlobID
+	id[0] = b
+	return id
+}
+
+func TestCorrelator_CorrelateBlobs(t *testing.T) {
+	c := NewCorrelator(correlationRules(0))
+	matches := []*types.Match{
+		blobMatch(1, "test.id", "key_id", "ID1234"),
+		blobMatch(2, "test.secret", "secret", "S5678"),
+		blobMatch(3, "test.secret", "secret", "S0000"),
+	}
+	scopes := map[types.BlobID][]string{
+		blobID(1): {"dir:app"},
+		blobID(2): {"dir:app"},
+		blobID(3): {"dir:other"},
+	}
+
+	result := c.CorrelateBlobs(matches, scopes)
+
+	require.Len(t, result, 1)
+	m := result[0]
+	assert.Equal(t, "test.pair", m.RuleID)
+	assert.Equal(t, blobID(1), m.BlobID)
+	assert.Equal(t, [][]byte{[]byte("ID1234"), []byte("S5678")}, m.Groups)
+	assert.Equal(t, []byte("S5678"), m.NamedGroups["secret"])
+	assert.NotEmpty(t, m.StructuralID)
+	assert.NotEmpty(t, m.FindingID)
+}
+
+func TestCorrelator_CorrelateBlobs_AllCombinations(t *testing.T) {
+	c := NewCorrelator(correlationRules(0))
+	matches := []*types.Match{
+		blobMatch(1, "test.id", "key_id", "ID1234"),
+		blobMatch(2, "test.secret", "secret", "S5678"),
+		blobMatch(3, "test.secret", "secret", "S0000"),
+		blobMatch(4, "test.secret", "secret", "S5678"), // same value as blob 2
+	}
+	scopes := map[types.BlobID][]string{
+		blobID(1): {"repo:r"},
+		blobID(2): {"repo:r"},
+		blobID(3): {"repo:r"},
+		blobID(4): {"repo:r"},
+	}
+
+	result := c.CorrelateBlobs(matches, scopes)
+
+	require.Len(t, result, 2)
+	assert.Equal(t, result[0].StructuralID, result[1].StructuralID, "candidates share their anchor")
+	assert.Equal(t, []byte("S5678"), result[0].Groups[1])
+	assert.Equal(t, []byte("S0000"), result[1].Groups[1])
+}
+
+func TestCorrelator_CorrelateBlobs_Skips(t *testing.T) {
+	c := NewCorrelator(correlationRules(0))
+	scopes := map[types.BlobID][]string{
+		blobID(1): {"dir:app"},
+		blobID(2): {"dir:app"},
+	}
+
+	t.Run("same blob", func(t *testing.T) {
+		matches := []*types.Match{
+			blobMatch(1, "test.id", "key_id", "ID1234"),
+			blobMatch(1, "test.secret", "secret", "S5678"),
+		}
+		assert.Empty(t, c.CorrelateBlobs(matches, scopes))
+	})
+
+	t.Run("already reported", func(t *testing.T) {
+		matches := []*types.Match{
+			blobMatch(1, "test.id", "key_id", "ID1234"),
+			blobMatch(2, "test.secret", "secret", "S5678"),
+			{RuleID: "test.pair", Groups: [][]byte{[]byte("ID1234"), []byte("S9999")}},
+		}
+		assert.Empty(t, c.CorrelateBlobs(matches, scopes))
+	})
+
+	t.Run("no shared scope", func(t *testing.T) {
+		matches := []*types.Match{
+			blobMatch(1, "test.id", "key_id", "ID1234"),
+			blobMatch(3, "test.secret", "secret", "S5678"),
+		}
+		assert.Empty(t, c.CorrelateBlobs(matches, scopes))
+	})
+}
+
+func TestBlobScopes(t *testing.T) {
+	scopes := BlobScopes([]types.Provenance{
+		types.FileProvenance{FilePath: "app/config/.env"},
+		types.FileProvenance{FilePath: "app/config/settings.yml"},
+		types.FileProvenance{FilePath: "main.go"},
+		types.GitProvenance{RepoPath: "/src/repo", BlobPath: "a/b.txt"},
+		types.ExtendedProvenance{},
+	})
+	assert.Equal(t, []string{"dir:.", "dir:app/config", "repo:/src/repo"}, scopes)
+}
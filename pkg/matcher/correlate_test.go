@@ -0,0 +1,156 @@
+package matcher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// spanMatch creates a match for ruleID with a single named group at [start, end).
+func spanMatch(ruleID, group, value string, start int64) *types.Match {
+	return &types.Match{
+		RuleID:      ruleID,
+		Groups:      [][]byte{[]byte(value)},
+		NamedGroups: map[string][]byte{group: []byte(value)},
+		Location: types.Location{
+			Offset: types.OffsetSpan{Start: start, End: start + int64(len(value))},
+		},
+		Snippet: types.Snippet{Matching: []byte(value)},
+	}
+}
+
+func correlationRules(maxDistance int) []*types.Rule {
+	pair := &types.Rule{
+		ID:      "test.pair",
+		Name:    "Test Credential Pair",
+		Pattern: `(?P<key_id>ID[0-9]{4}).{0,5}(?P<secret>S[0-9]{4})`,
+		Correlation: &types.Correlation{
+			MaxDistance: maxDistance,
+			Components: []types.CorrelationComponent{
+				{Group: "key_id", RuleIDs: []string{"test.id"}},
+				{Group: "secret", RuleIDs: []string{"test.secret"}},
+			},
+		},
+	}
+	pair.StructuralID = pair.ComputeStructuralID()
+	return []*types.Rule{
+		{ID: "test.id", Name: "Test ID"},
+		{ID: "test.secret", Name: "Test Secret"},
+		pair,
+	}
+}
+
+func TestCorrelator_PairsComponents(t *testing.T) {
+	content := []byte("ID1234" + strings.Repeat(" ", 100) + "S5678")
+	c := NewCorrelator(correlationRules(0))
+
+	result := c.Correlate(content, []*types.Match{
+		spanMatch("test.id", "key_id", "ID1234", 0),
+		spanMatch("test.secret", "secret", "S5678", 106),
+	})
+
+	require.Len(t, result, 1)
+	m := result[0]
+	assert.Equal(t, "test.pair", m.RuleID)
+	assert.Equal(t, "Test Credential Pair", m.RuleName)
+	assert.Equal(t, [][]byte{[]byte("ID1234"), []byte("S5678")}, m.Groups)
+	assert.Equal(t, []byte("ID1234"), m.NamedGroups["key_id"])
+	assert.Equal(t, []byte("S5678"), m.NamedGroups["secret"])
+	assert.Equal(t, types.OffsetSpan{Start: 0, End: 111}, m.Location.Offset)
+	assert.Equal(t, content, m.Snippet.Matching)
+	assert.NotEmpty(t, m.StructuralID)
+	assert.NotEmpty(t, m.FindingID)
+}
+
+func TestCorrelator_RespectsMaxDistance(t *testing.T) {
+	c := NewCorrelator(correlationRules(50))
+	matches := []*types.Match{
+		spanMatch("test.id", "key_id", "ID1234", 0),
+		spanMatch("test.secret", "secret", "S5678", 106),
+	}
+
+	result := c.Correlate(nil, matches)
+	assert.Equal(t, matches, result, "components too far apart are left alone")
+}
+
+func TestCorrelator_PairsNearest(t *testing.T) {
+	c := NewCorrelator(correlationRules(0))
+	result := c.Correlate(nil, []*types.Match{
+		spanMatch("test.id", "key_id", "ID0001", 0),
+		spanMatch("test.id", "key_id", "ID0002", 500),
+		spanMatch("test.secret", "secret", "S0002", 520),
+		spanMatch("test.secret", "secret", "S0001", 20),
+	})
+
+	require.Len(t, result, 2)
+	pairs := make(map[string]string)
+	for _, m := range result {
+		require.Equal(t, "test.pair", m.RuleID)
+		pairs[string(m.NamedGroups["key_id"])] = string(m.NamedGroups["secret"])
+	}
+	assert.Equal(t, map[string]string{"ID0001": "S0001", "ID0002": "S0002"}, pairs)
+}
+
+func TestCorrelator_DropsUnpairedCorrelationOnly(t *testing.T) {
+	rules := correlationRules(0)
+	rules[0].CorrelationOnly = true
+	c := NewCorrelator(rules)
+
+	secret := spanMatch("test.secret", "secret", "S5678", 0)
+	result := c.Correlate(nil, []*types.Match{
+		spanMatch("test.id", "key_id", "ID1234", 10000),
+		secret,
+	})
+	assert.Equal(t, []*types.Match{secret}, result)
+}
+
+func TestCorrelator_KeepUnpaired(t *testing.T) {
+	rules := correlationRules(0)
+	rules[0].CorrelationOnly = true
+	c := NewCorrelator(rules)
+	c.KeepUnpaired = true
+
+	result := c.Correlate(nil, []*types.Match{
+		spanMatch("test.id", "key_id", "ID1234", 10000),
+		spanMatch("test.secret", "secret", "S5678", 0),
+	})
+	assert.Len(t, result, 2)
+}
+
+func TestCorrelator_SkipsValuesOfDirectMatch(t *testing.T) {
+	c := NewCorrelator(correlationRules(0))
+	direct := &types.Match{
+		RuleID: "test.pair",
+		Groups: [][]byte{[]byte("ID1234"), []byte("S5678")},
+	}
+	matches := []*types.Match{
+		spanMatch("test.id", "key_id", "ID1234", 0),
+		spanMatch("test.secret", "secret", "S5678", 8),
+		direct,
+	}
+
+	result := c.Correlate(nil, matches)
+	assert.Equal(t, matches, result, "no duplicate composite when the rule matched directly")
+}
+
+func TestNew_CorrelatesAcrossBlob(t *testing.T) {
+	rules := correlationRules(0)
+	rules[0].Pattern = `\b(?P<key_id>ID[0-9]{4})\b`
+	rules[1].Pattern = `\b(?P<secret>S[0-9]{4})\b`
+	for _, r := range rules[:2] {
+		r.StructuralID = r.ComputeStructuralID()
+	}
+
+	m, err := New(Config{Rules: rules})
+	require.NoError(t, err)
+	defer m.Close()
+
+	matches, err := m.Match([]byte("id = ID1234\n# unrelated\n# lines\nsecret = S5678\n"))
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "test.pair", matches[0].RuleID)
+	assert.Equal(t, []byte("ID1234\n# unrelated\n# lines\nsecret = S5678"), matches[0].Snippet.Matching)
+}
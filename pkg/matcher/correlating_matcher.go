@@ -0,0 +1,40 @@
+package matcher
+
+import "github.com/praetorian-inc/titus/pkg/types"
+
+// correlatingMatcher wraps a Matcher and pairs related credential matches
+// into composite matches of correlated rules.
+type correlatingMatcher struct {
+	inner      Matcher
+	correlator *Correlator
+}
+
+// newCorrelatingMatcher wraps a matcher with credential correlation.
+func newCorrelatingMatcher(inner Matcher, rules []*types.Rule, keepUnpaired bool) *correlatingMatcher {
+	correlator := NewCorrelator(rules)
+	correlator.KeepUnpaired = keepUnpaired
+	return &correlatingMatcher{
+		inner:      inner,
+		correlator: correlator,
+	}
+}
+
+func (c *correlatingMatcher) Match(content []byte) ([]*types.Match, error) {
+	matches, err := c.inner.Match(content)
+	if err != nil {
+		return nil, err
+	}
+	return c.correlator.Correlate(content, matches), nil
+}
+
+func (c *correlatingMatcher) MatchWithBlobID(content []byte, blobID types.BlobID) ([]*types.Match, error) {
+	matches, err := c.inner.MatchWithBlobID(content, blobID)
+	if err != nil {
+		return nil, err
+	}
+	return c.correlator.Correlate(content, matches), nil
+}
+
+func (c *correlatingMatcher) Close() error {
+	return c.inner.Close()
+}
@@ -0,0 +1,66 @@
+package matcher
+
+import (
+	"github.com/praetorian-inc/titus/pkg/extract"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// decodingMatcher wraps a Matcher and matches UTF-16 text, and text with a
+// byte order mark, as UTF-8. Offsets of the matches are mapped back to the
+// content as given, so that they point into the blob its ID was computed
+// from; snippets and capture groups stay decoded.
+type decodingMatcher struct {
+	inner Matcher
+	rules map[string]*types.Rule
+}
+
+// newDecodingMatcher wraps a matcher with text decoding.
+func newDecodingMatcher(inner Matcher, rules []*types.Rule) *decodingMatcher {
+	ruleMap := make(map[string]*types.Rule, len(rules))
+	for _, r := range rules {
+		ruleMap[r.ID] = r
+	}
+	return &decodingMatcher{inner: inner, rules: ruleMap}
+}
+
+func (d *decodingMatcher) Match(content []byte) ([]*types.Match, error) {
+	text, offsets := extract.DecodeTextOffsets(content)
+	matches, err := d.inner.Match(text)
+	if err != nil {
+		return nil, err
+	}
+	return d.mapOffsets(matches, offsets), nil
+}
+
+func (d *decodingMatcher) MatchWithBlobID(content []byte, blobID types.BlobID) ([]*types.Match, error) {
+	text, offsets := extract.DecodeTextOffsets(content)
+	matches, err := d.inner.MatchWithBlobID(text, blobID)
+	if err != nil {
+		return nil, err
+	}
+	return d.mapOffsets(matches, offsets), nil
+}
+
+// mapOffsets maps the offsets of matches in decoded text back to the
+// content it was decoded from, recomputing the structural IDs that depend
+// on them. offsets is nil if the content wasn't decoded.
+func (d *decodingMatcher) mapOffsets(matches []*types.Match, offsets []int) []*types.Match {
+	if offsets == nil {
+		return matches
+	}
+	at := func(i int64) int64 {
+		return int64(offsets[min(max(i, 0), int64(len(offsets)-1))])
+	}
+	for _, m := range matches {
+		m.Location.Offset.Start = at(m.Location.Offset.Start)
+		m.Location.Offset.End = at(m.Location.Offset.End)
+		if r, ok := d.rules[m.RuleID]; ok {
+			m.StructuralID = m.ComputeStructuralID(r.StructuralID)
+		}
+	}
+	return matches
+}
+
+func (d *decodingMatcher) Close() error {
+	return d.inner.Close()
+}
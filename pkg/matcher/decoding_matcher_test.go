@@ -0,0 +1,40 @@
+package matcher
+
+import (
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodingMatcher_UTF16(t *testing.T) {
+	rule := &types.Rule{
+		ID:           "test.password",
+		Name:         "Test Password",
+		Pattern:      `password\s*=\s*"([^"]+)"`,
+		StructuralID: "test-structural-id",
+	}
+	m, err := New(Config{Rules: []*types.Rule{rule}})
+	require.NoError(t, err)
+	defer m.Close()
+
+	text := "# é\npassword = \"hunter22\"\n"
+	content := []byte{0xFF, 0xFE}
+	for _, r := range text {
+		content = append(content, byte(r), byte(r>>8))
+	}
+	blobID := types.ComputeBlobID(content)
+
+	matches, err := m.MatchWithBlobID(content, blobID)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	match := matches[0]
+	assert.Equal(t, []byte("hunter22"), match.Groups[0], "capture groups are decoded")
+
+	// Offsets point into the UTF-16 content: a 2-byte BOM, then 2 bytes per
+	// unit of "# é\n"
+	assert.Equal(t, types.OffsetSpan{Start: 2 + 2*4, End: 2 + 2*int64(len([]rune(text))-1)}, match.Location.Offset)
+	assert.Equal(t, match.ComputeStructuralID(rule.StructuralID), match.StructuralID)
+	assert.Equal(t, blobID, match.BlobID)
+}
@@ -44,6 +44,9 @@ func (d *dedupMatcher) Close() error {
 // SetCanValidate upgrades the deduplicator in a matcher chain with validator awareness.
 // If the matcher doesn't contain a dedupMatcher, this is a no-op.
 func SetCanValidate(m Matcher, fn func(ruleID string) bool) {
+	if dc, ok := m.(*decodingMatcher); ok {
+		m = dc.inner
+	}
 	if dm, ok := m.(*dedupMatcher); ok {
 		dm.dedup.SetCanValidate(fn)
 	}
@@ -7,31 +7,52 @@ import (
 	"strings"
 )
 
-// stripExtendedMode preprocesses a regex pattern to remove extended mode syntax.
+// stripExtendedMode preprocesses a regex pattern to remove extended mode syntax,
+// as stripFreeSpacing does, and also removes the inline (?s) and (?m) modifiers
+// from extended mode patterns.
+//
+// This is necessary because the Hyperscan library (gohs) doesn't support the Extended flag,
+// which allows free-spacing mode with comments in regex patterns.
+func stripExtendedMode(pattern string) string {
+	pattern, extended := stripFreeSpacing(pattern)
+	if !extended {
+		return pattern
+	}
+
+	// Remove inline flag modifiers that Hyperscan doesn't support
+	// (?s) - DotAll - already set via hyperscan.DotAll flag
+	// (?m) - MultiLine - already set via hyperscan.MultiLine flag
+	pattern = strings.ReplaceAll(pattern, "(?s)", "")
+	pattern = strings.ReplaceAll(pattern, "(?m)", "")
+	return pattern
+}
+
+// stripFreeSpacing rewrites a pattern in extended mode into the equivalent
+// pattern without it, and reports whether the pattern was in extended mode.
 // If the pattern starts with a flag group containing 'x' (e.g., (?x), (?xi), (?xis)), this function:
 // 1. Removes only the 'x' flag from the group (other flags like i, s, m are preserved)
 // 2. Removes all whitespace (except escaped whitespace like \s or \ )
 // 3. Removes all comments in the form (?# ... )
 // 4. Removes all # line-end comments (from unescaped # outside character classes to end of line)
 //
-// This is necessary because the Hyperscan library (gohs) doesn't support the Extended flag,
-// which allows free-spacing mode with comments in regex patterns.
-func stripExtendedMode(pattern string) string {
+// Go's regexp package doesn't support extended mode either.
+func stripFreeSpacing(pattern string) (string, bool) {
 	// Check if pattern uses extended mode by looking for (?...) with x flag
 	pattern = strings.TrimSpace(pattern)
 	if len(pattern) < 4 || pattern[0:2] != "(?" {
-		return pattern
+		return pattern, false
 	}
 
 	// Find the closing ) of the flag group
 	closeIdx := strings.Index(pattern[2:], ")")
 	if closeIdx == -1 {
-		return pattern
+		return pattern, false
 	}
 
+	// A group such as (?P<x>...) is not a flag group
 	flags := pattern[2 : 2+closeIdx]
-	if !strings.Contains(flags, "x") {
-		return pattern
+	if strings.Trim(flags, "imsxU-") != "" || !strings.Contains(flags, "x") {
+		return pattern, false
 	}
 
 	// Remove only the 'x' flag, preserve other flags
@@ -50,17 +71,11 @@ func stripExtendedMode(pattern string) string {
 	commentRegex := regexp.MustCompile(`\(\?#[^)]*\)`)
 	pattern = commentRegex.ReplaceAllString(pattern, "")
 
-	// Remove inline flag modifiers that Hyperscan doesn't support
-	// (?s) - DotAll - already set via hyperscan.DotAll flag
-	// (?m) - MultiLine - already set via hyperscan.MultiLine flag
-	pattern = strings.ReplaceAll(pattern, "(?s)", "")
-	pattern = strings.ReplaceAll(pattern, "(?m)", "")
-
 	// Remove unescaped whitespace and # line-end comments.
 	// We need to be careful to preserve:
 	// - Escaped spaces: \ (backslash followed by space)
 	// - Whitespace escape sequences: \s, \t, \n, \r, etc.
-	// - # inside character classes [...] (not a comment there)
+	// - # and whitespace inside character classes [...] (literal there)
 	//
 	// Strategy: Process byte by byte (pattern is ASCII-safe after flag removal),
 	// tracking escape and character-class state.
@@ -120,8 +135,9 @@ func stripExtendedMode(pattern string) string {
 			continue
 		}
 
-		// Skip unescaped whitespace (space, tab, newline, carriage return)
-		if char == ' ' || char == '\t' || char == '\n' || char == '\r' {
+		// Skip unescaped whitespace (space, tab, newline, carriage return) outside
+		// character classes, where extended mode keeps it literal
+		if !inCharClass && (char == ' ' || char == '\t' || char == '\n' || char == '\r') {
 			i++
 			continue
 		}
@@ -131,5 +147,5 @@ func stripExtendedMode(pattern string) string {
 		i++
 	}
 
-	return result.String()
+	return result.String(), true
 }
@@ -100,6 +100,16 @@ test\ pattern    (?# with escaped space )
 			input:    `(?ixm) test$`,
 			expected: `(?im)test$`,
 		},
+		{
+			name:     "pattern with (?x) keeps whitespace in character classes",
+			input:    `(?x) [a-z /+=]+ \s`,
+			expected: `[a-z /+=]+\s`,
+		},
+		{
+			name:     "named group with x in its name is not a flag group",
+			input:    `(?P<x>a b)`,
+			expected: `(?P<x>a b)`,
+		},
 	}
 
 	for _, tt := range tests {
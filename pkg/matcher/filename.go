@@ -0,0 +1,85 @@
+package matcher
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// FilenameMatcher matches filename rules against the paths of files, for
+// leaks evident from a name alone (id_rsa, .npmrc, *.pfx) whatever the
+// file's content, including content that is binary or can't be read.
+type FilenameMatcher struct {
+	rules    []*types.Rule
+	patterns []*regexp.Regexp
+}
+
+// NewFilenameMatcher compiles the filename rules among rules. Content rules
+// are ignored.
+func NewFilenameMatcher(rules []*types.Rule) (*FilenameMatcher, error) {
+	f := &FilenameMatcher{}
+	for _, r := range rules {
+		if r.Kind != types.RuleKindFilename {
+			continue
+		}
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("compiling filename rule %s: %w", r.ID, err)
+		}
+		f.rules = append(f.rules, r)
+		f.patterns = append(f.patterns, re)
+	}
+	return f, nil
+}
+
+// Empty reports whether there are no filename rules to match.
+func (f *FilenameMatcher) Empty() bool {
+	return f == nil || len(f.rules) == 0
+}
+
+// Match returns a match of each filename rule whose pattern matches path,
+// which is tested with forward slashes. The matches belong to the blob of
+// the path itself, PathBlobID(path), so that they are kept apart from the
+// matches in the file's content; their snippet and only group is the path.
+func (f *FilenameMatcher) Match(path string) []*types.Match {
+	if f.Empty() {
+		return nil
+	}
+	slashed := filepath.ToSlash(path)
+	var matches []*types.Match
+	for i, re := range f.patterns {
+		if !re.MatchString(slashed) {
+			continue
+		}
+		m := newMatchResult(PathBlobID(path), f.rules[i], 0, len(path),
+			types.Snippet{Matching: []byte(path)}, [][]byte{[]byte(path)}, nil)
+		m.Kind = types.RuleKindFilename
+		matches = append(matches, m)
+	}
+	return matches
+}
+
+// PathBlobID returns the ID of the blob that filename matches of path
+// belong to: the ID of the path's text.
+func PathBlobID(path string) types.BlobID {
+	return types.ComputeBlobID([]byte(path))
+}
+
+// contentRules returns the rules that match blob content, dropping filename
+// rules.
+func contentRules(rules []*types.Rule) []*types.Rule {
+	for _, r := range rules {
+		if r.Kind == types.RuleKindFilename {
+			result := make([]*types.Rule, 0, len(rules))
+			for _, r := range rules {
+				if r.Kind != types.RuleKindFilename {
+					result = append(result, r)
+				}
+			}
+			return result
+		}
+	}
+	return rules
+}
@@ -0,0 +1,47 @@
+package matcher
+
+import (
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilenameMatcher(t *testing.T) {
+	sshKey := &types.Rule{ID: "test.filename.1", Name: "SSH Key File", Kind: types.RuleKindFilename, Pattern: `(?:^|/)id_rsa$`}
+	sshKey.StructuralID = sshKey.ComputeStructuralID()
+	content := &types.Rule{ID: "test.content.1", Name: "Content", Pattern: `id_rsa`}
+	content.StructuralID = content.ComputeStructuralID()
+
+	f, err := NewFilenameMatcher([]*types.Rule{sshKey, content})
+	require.NoError(t, err)
+	assert.False(t, f.Empty())
+
+	matches := f.Match("/home/alice/.ssh/id_rsa")
+	require.Len(t, matches, 1)
+	m := matches[0]
+	assert.Equal(t, "test.filename.1", m.RuleID)
+	assert.Equal(t, types.RuleKindFilename, m.Kind)
+	assert.Equal(t, PathBlobID("/home/alice/.ssh/id_rsa"), m.BlobID)
+	assert.Equal(t, []byte("/home/alice/.ssh/id_rsa"), m.Snippet.Matching)
+	assert.Equal(t, types.ComputeFindingID(sshKey.StructuralID, m.Groups), m.FindingID)
+
+	assert.Empty(t, f.Match("/home/alice/.ssh/id_rsa.pub"))
+
+	// Content matchers skip filename rules
+	cm, err := New(Config{Rules: []*types.Rule{sshKey, content}})
+	require.NoError(t, err)
+	defer cm.Close()
+	got, err := cm.Match([]byte("cp id_rsa backup"))
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "test.content.1", got[0].RuleID)
+}
+
+func TestFilenameMatcher_NoRules(t *testing.T) {
+	f, err := NewFilenameMatcher(nil)
+	require.NoError(t, err)
+	assert.True(t, f.Empty())
+	assert.Nil(t, f.Match("id_rsa"))
+}
@@ -0,0 +1,271 @@
+//go:build !wasm
+
+package matcher
+
+import (
+	"bytes"
+	"regexp/syntax"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// cutKind is what a pattern's empty-width assertions need to see at the
+// edges of a span of a blob for a search of the span alone to find the
+// matches within it that a search of the whole blob finds.
+type cutKind int
+
+const (
+	cutRune cutKind = iota // no assertions: any rune boundary will do
+	cutWord                // \b or \B: a non-word byte outside the span
+	cutLine                // ^ or $: a newline outside the span
+	cutNone                // \A or \z: only the whole blob will do
+)
+
+// maxLiteralCoverage is the fraction of a blob above which the spans around
+// literal hits are searched as the whole blob instead.
+const maxLiteralCoverage = 0.5
+
+// scanHints records what stdlib-tier matching needs to search only the
+// parts of a blob near hits of a pattern's required literals. Each match
+// contains a hit, so it lies within width bytes of it, on the hit's line
+// when singleLine is set.
+type scanHints struct {
+	// width is the most bytes a match can span, or -1 if it is unbounded.
+	width int
+	// singleLine is set if no match can span a newline.
+	singleLine bool
+	cut        cutKind
+}
+
+// newScanHints analyzes a pattern parsed with the flags it is compiled with.
+func newScanHints(re *syntax.Regexp) scanHints {
+	return scanHints{width: maxWidth(re), singleLine: !matchesNewline(re), cut: assertionCut(re)}
+}
+
+// localized reports whether hits bound where matches can be.
+func (h scanHints) localized() bool {
+	return h.cut != cutNone && (h.width >= 0 || h.singleLine)
+}
+
+// maxWidth returns the most bytes a match of re can span, or -1 if there is
+// no bound.
+func maxWidth(re *syntax.Regexp) int {
+	switch re.Op {
+	case syntax.OpLiteral:
+		width := 0
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 {
+				r = maxFoldRune(r)
+			}
+			width += runeWidth(r)
+		}
+		return width
+	case syntax.OpCharClass:
+		if len(re.Rune) == 0 {
+			return 0
+		}
+		return runeWidth(re.Rune[len(re.Rune)-1])
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return utf8.UTFMax
+	case syntax.OpCapture, syntax.OpQuest:
+		return maxWidth(re.Sub[0])
+	case syntax.OpStar, syntax.OpPlus:
+		if maxWidth(re.Sub[0]) == 0 {
+			return 0
+		}
+		return -1
+	case syntax.OpRepeat:
+		width := maxWidth(re.Sub[0])
+		if width <= 0 {
+			return width
+		}
+		if re.Max < 0 {
+			return -1
+		}
+		return width * re.Max
+	case syntax.OpConcat, syntax.OpAlternate:
+		total := 0
+		for _, sub := range re.Sub {
+			width := maxWidth(sub)
+			if width < 0 {
+				return -1
+			}
+			if re.Op == syntax.OpConcat {
+				total += width
+			} else {
+				total = max(total, width)
+			}
+		}
+		return total
+	}
+	return 0 // empty-width
+}
+
+// runeWidth returns the length of r's UTF-8 encoding. Runes that have none,
+// such as surrogates, count as the longest.
+func runeWidth(r rune) int {
+	if n := utf8.RuneLen(r); n > 0 {
+		return n
+	}
+	return utf8.UTFMax
+}
+
+// maxFoldRune returns the largest rune in r's case folding orbit, the one
+// with the longest encoding.
+func maxFoldRune(r rune) rune {
+	most := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		most = max(most, f)
+	}
+	return most
+}
+
+// matchesNewline reports whether a match of re can contain a newline.
+func matchesNewline(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if r == '\n' {
+				return true
+			}
+		}
+		return false
+	case syntax.OpCharClass:
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			if re.Rune[i] <= '\n' && '\n' <= re.Rune[i+1] {
+				return true
+			}
+		}
+		return false
+	case syntax.OpAnyChar:
+		return true
+	}
+	for _, sub := range re.Sub {
+		if matchesNewline(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// assertionCut returns the cutKind re's empty-width assertions need.
+func assertionCut(re *syntax.Regexp) cutKind {
+	cut := cutRune
+	switch re.Op {
+	case syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		cut = cutWord
+	case syntax.OpBeginLine, syntax.OpEndLine:
+		cut = cutLine
+	case syntax.OpBeginText, syntax.OpEndText:
+		return cutNone
+	}
+	for _, sub := range re.Sub {
+		cut = max(cut, assertionCut(sub))
+	}
+	return cut
+}
+
+// literalSpans returns the spans of content around each hit of literals,
+// the required literals of a pattern, that hold all its matches. It reports
+// whole instead if the whole blob must be searched: when hits don't bound
+// where matches can be, or the spans would cover most of the blob. It
+// returns neither if content holds no hits. folded is content's caseFold
+// form, if the literals need it.
+func literalSpans(content, folded []byte, literals []requiredLiteral, hints scanHints) (spans []byteSpan, whole bool) {
+	if literals == nil {
+		return nil, true
+	}
+
+	// Folding shortens a few runes, such as the Kelvin sign, moving the
+	// offsets of hits after them
+	localized := hints.localized()
+	for _, lit := range literals {
+		if lit.fold && len(folded) != len(content) {
+			localized = false
+		}
+	}
+
+	var hits []byteSpan
+	for _, lit := range literals {
+		text := content
+		if lit.fold {
+			text = folded
+		}
+		for pos := 0; ; {
+			i := bytes.Index(text[pos:], lit.text)
+			if i < 0 {
+				break
+			}
+			if !localized {
+				return nil, true
+			}
+			hits = append(hits, byteSpan{pos + i, pos + i + len(lit.text)})
+			pos += i + 1
+		}
+	}
+	if len(hits) == 0 {
+		return nil, false
+	}
+
+	sort.Slice(hits, func(i, j int) bool { return hits[i].from < hits[j].from })
+	spans = make([]byteSpan, 0, len(hits))
+	line := byteSpan{-1, -1}
+	for _, hit := range hits {
+		span := byteSpan{0, len(content)}
+		if hints.width >= 0 {
+			span = byteSpan{max(0, hit.to-hints.width), min(len(content), hit.from+hints.width)}
+		}
+		if hints.singleLine {
+			if hit.to > line.to {
+				line = lineAround(content, hit)
+			}
+			span = byteSpan{max(span.from, line.from), min(span.to, line.to)}
+		}
+		spans = append(spans, hints.widen(content, span))
+	}
+	spans = mergeSpans(spans)
+
+	covered := 0
+	for _, span := range spans {
+		covered += span.to - span.from
+	}
+	if float64(covered) > maxLiteralCoverage*float64(len(content)) {
+		return nil, true
+	}
+	return spans, false
+}
+
+// lineAround returns the span of the line in content that holds s, which
+// contains no newline, without its line ending.
+func lineAround(content []byte, s byteSpan) byteSpan {
+	line := byteSpan{bytes.LastIndexByte(content[:s.from], '\n') + 1, len(content)}
+	if i := bytes.IndexByte(content[s.to:], '\n'); i >= 0 {
+		line.to = s.to + i
+	}
+	return line
+}
+
+// widen extends span until content can be cut at its edges as the pattern's
+// assertions need.
+func (h scanHints) widen(content []byte, span byteSpan) byteSpan {
+	for span.from > 0 && !h.cuttable(content, span.from, content[span.from-1]) {
+		span.from--
+	}
+	for span.to < len(content) && !h.cuttable(content, span.to, content[span.to]) {
+		span.to++
+	}
+	return span
+}
+
+// cuttable reports whether content can be cut at i, where outside is the
+// byte on the side of the cut away from the span.
+func (h scanHints) cuttable(content []byte, i int, outside byte) bool {
+	switch h.cut {
+	case cutWord:
+		return outside < utf8.RuneSelf && !syntax.IsWordChar(rune(outside))
+	case cutLine:
+		return outside == '\n'
+	}
+	return utf8.RuneStart(content[i])
+}
@@ -0,0 +1,122 @@
+//go:build !wasm
+
+package matcher
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewScanHints(t *testing.T) {
+	tests := []struct {
+		pattern string
+		hints   scanHints
+	}{
+		{`AKIA[A-Z0-9]{16}`, scanHints{width: 20, singleLine: true, cut: cutRune}},
+		{`\bghp_[a-zA-Z0-9]{36}\b`, scanHints{width: 40, singleLine: true, cut: cutWord}},
+		{`(?i)key=(\w+)$`, scanHints{width: -1, singleLine: true, cut: cutLine}},
+		{`(?i)k`, scanHints{width: 3, singleLine: true, cut: cutRune}}, // the Kelvin sign
+		{`token\s*=\s*"([^"]{8,64})"`, scanHints{width: -1, cut: cutRune}},
+		{`(?s)-----BEGIN KEY-----.{0,100}`, scanHints{width: 419, cut: cutRune}},
+		{`secret[ \t]*=\x{e9}+`, scanHints{width: -1, singleLine: true, cut: cutRune}},
+		{`\Atoken=\w+`, scanHints{width: -1, singleLine: true, cut: cutNone}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			p := compileStdlib(tt.pattern)
+			require.NotNil(t, p)
+			assert.Equal(t, tt.hints, p.hints)
+		})
+	}
+}
+
+func TestLiteralSpans(t *testing.T) {
+	spans := func(pattern, content string) ([]byteSpan, bool) {
+		p := compileStdlib(pattern)
+		require.NotNil(t, p)
+		return literalSpans([]byte(content), caseFold([]byte(content)), p.literals, p.hints)
+	}
+	filler := strings.Repeat("lorem ipsum dolor ", 20)
+
+	// Spans reach the pattern's width around each hit
+	got, whole := spans(`AKIA[A-Z0-9]{4}`, filler+"xAKIA1234x"+filler+"AKIAAKIA"+filler)
+	require.False(t, whole)
+	n := len(filler)
+	assert.Equal(t, []byteSpan{{n - 3, n + 9}, {2*n + 10 - 4, 2*n + 10 + 12}}, got)
+
+	// Word boundaries widen spans to a non-word byte
+	got, whole = spans(`\bAKIA[A-Z0-9]{4}\b`, filler+"xxxxxxAKIA1234x"+filler)
+	require.False(t, whole)
+	assert.Equal(t, []byteSpan{{n, n + 20}}, got)
+
+	// Single-line patterns are searched on the hit's line
+	got, whole = spans(`(?i)password[ \t]*=[ \t]*(\S+)`, filler+"\n"+filler+"\nPassWord = hunter2\n"+filler)
+	require.False(t, whole)
+	assert.Equal(t, []byteSpan{{2*n + 2, 2*n + 20}}, got)
+
+	// No hits: nothing to search
+	got, whole = spans(`AKIA[A-Z0-9]{4}`, filler)
+	assert.False(t, whole)
+	assert.Empty(t, got)
+
+	// Hits that don't bound matches, or cover most of the blob
+	_, whole = spans(`token\s*=\s*"([^"]+)"`, filler+`token = "abc"`+filler)
+	assert.True(t, whole)
+	_, whole = spans(`(?i)password[ \t]*=[ \t]*(\S+)`, "password = hunter2 "+filler)
+	assert.True(t, whole)
+	_, whole = spans(`(?i)secret=(\w+)`, "\u212a"+filler+"secret=1"+filler)
+	assert.True(t, whole, "the Kelvin sign folds to K, moving offsets in the folded blob")
+}
+
+func TestLiteralSpans_MatchesWholeBlobSearch(t *testing.T) {
+	patterns := []string{
+		`AKIA[A-Z0-9]{4}`,
+		`\bAKIA[A-Z0-9]{4}\b`,
+		`(?i)\bkey\b[ \t]*=[ \t]*(\w{2,8})`,
+		`^key=(\w+)$`,
+		`(?i)kéy[:=]\s?([\w.]{1,10})`,
+		`(?s)BEGIN.{0,20}END`,
+		`\Bkey\d`,
+	}
+	words := []string{"key", "KEY", "kéy", "Kéy", "AKIA", "AKIAA", "BEGIN", "END", "=", ":", " ", "\t", "\n", "K", "x", "9", "é", ".", "\xff"}
+	filler := []string{"lorem", "ipsum", " ", " ", "\n"}
+	rng := rand.New(rand.NewSource(1))
+	for _, pattern := range patterns {
+		p := compileStdlib(pattern)
+		require.NotNil(t, p, pattern)
+		require.NotNil(t, p.literals, pattern)
+		for i := 0; i < 500; i++ {
+			var b strings.Builder
+			for b.Len() < 2000 {
+				if rng.Intn(10) == 0 {
+					b.WriteString(words[rng.Intn(len(words))])
+				} else {
+					b.WriteString(filler[rng.Intn(len(filler))])
+				}
+			}
+			content := []byte(b.String())
+
+			want := p.re.FindAllSubmatchIndex(content, -1)
+			spans, whole := literalSpans(content, caseFold(content), p.literals, p.hints)
+			if whole {
+				continue
+			}
+			var got [][]int
+			for _, span := range spans {
+				for _, loc := range p.re.FindAllSubmatchIndex(content[span.from:span.to], -1) {
+					for i := range loc {
+						if loc[i] >= 0 {
+							loc[i] += span.from
+						}
+					}
+					got = append(got, loc)
+				}
+			}
+			require.Equal(t, want, got, "%s in %q", pattern, content)
+		}
+	}
+}
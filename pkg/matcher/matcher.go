@@ -26,6 +26,19 @@ type Config struct {
 	// ContextLines is the number of lines of context to extract before/after matches (0 = none)
 	ContextLines int
 
+	// ContextBytes caps the context before/after matches at this many bytes
+	// (0 = no cap). Used alone, it keeps a byte window centered on the match.
+	ContextBytes int
+
+	// MaxSecretLength is the longest match, in bytes, that is never split
+	// across the chunks large blobs are scanned in (0 = the default, 64KB).
+	// Only the vectorscan backend chunks blobs.
+	MaxSecretLength int
+
+	// KeepCorrelationComponents keeps matches of CorrelationOnly rules that
+	// could not be paired within their blob, for cross-blob correlation.
+	KeepCorrelationComponents bool
+
 	// WarnFunc, if non-nil, is called for non-fatal regex warnings
 	// (timeouts, pattern errors). If nil, warnings are silently discarded.
 	WarnFunc func(format string, args ...any)
@@ -2,16 +2,14 @@
 
 package matcher
 
+// defaultBackend is the backend New uses in this build.
+const defaultBackend = BackendPortable
+
 // New creates a regexp-based matcher using pure Go (no CGO required).
 // Uses regexp2 for Perl-compatible regex matching with these characteristics:
 // - Fully portable: builds with CGO_ENABLED=0 on any platform
 // - High detection accuracy: finds 20% more secrets than NoseyParker v0.24.0
 // - Performance: comparable on small files, sufficient for most use cases
 func New(cfg Config) (Matcher, error) {
-	inner, err := NewPortableRegexp(cfg.Rules, cfg.ContextLines, cfg.WarnFunc)
-	if err != nil {
-		return nil, err
-	}
-	filtered := newFilteringMatcher(inner, cfg.Rules)
-	return newDedupMatcher(filtered, cfg.Rules), nil
+	return NewBackend(defaultBackend, cfg)
 }
@@ -2,6 +2,9 @@
 
 package matcher
 
+// defaultBackend is the backend New uses in this build.
+const defaultBackend = BackendVectorscan
+
 // New creates a new Matcher using the Vectorscan/Hyperscan engine.
 // This is the high-performance implementation that requires CGO and
 // the Hyperscan/Vectorscan C library installed on the system.
@@ -13,10 +16,19 @@ package matcher
 // - CGO is enabled
 // - The "vectorscan" build tag is specified
 func New(cfg Config) (Matcher, error) {
-	inner, err := NewVectorscan(cfg.Rules, cfg.ContextLines, cfg.WarnFunc)
-	if err != nil {
-		return nil, err
+	return NewBackend(defaultBackend, cfg)
+}
+
+func init() {
+	backends[BackendVectorscan] = func(cfg Config) (Matcher, error) {
+		inner, err := NewVectorscan(cfg.Rules, cfg.ContextLines, cfg.WarnFunc)
+		if err != nil {
+			return nil, err
+		}
+		inner.context.Bytes = cfg.ContextBytes
+		if cfg.MaxSecretLength > 0 {
+			inner.chunking.MaxSecretLength = cfg.MaxSecretLength
+		}
+		return inner, nil
 	}
-	filtered := newFilteringMatcher(inner, cfg.Rules)
-	return newDedupMatcher(filtered, cfg.Rules), nil
 }
@@ -2,12 +2,10 @@
 
 package matcher
 
+// defaultBackend is the backend New uses in this build.
+const defaultBackend = BackendRegexp
+
 // New creates a regexp-based matcher for WASM builds.
 func New(cfg Config) (Matcher, error) {
-	inner, err := NewRegexp(cfg.Rules, cfg.ContextLines, cfg.WarnFunc)
-	if err != nil {
-		return nil, err
-	}
-	filtered := newFilteringMatcher(inner, cfg.Rules)
-	return newDedupMatcher(filtered, cfg.Rules), nil
+	return NewBackend(defaultBackend, cfg)
 }
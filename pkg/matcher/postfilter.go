@@ -5,6 +5,7 @@ import (
 	"unicode"
 
 	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/praetorian-inc/titus/pkg/verifier"
 )
 
 // defaultSpecialChars is the set of characters considered "special" when
@@ -104,14 +105,27 @@ func passesPatternRequirements(text []byte, reqs *types.PatternRequirements) boo
 	return true
 }
 
-// filterMatches iterates matches, looks up each rule, applies entropy and
-// pattern_requirements checks, and returns only the passing matches.
+// passesVerifier runs the named post-match verifier on text. Rules are
+// validated when loaded, so an unknown name only reaches here for rules
+// built in code; those pass unchecked.
+func passesVerifier(text []byte, name string) bool {
+	if name == "" {
+		return true
+	}
+	fn, ok := verifier.Lookup(name)
+	return !ok || fn(text)
+}
+
+// filterMatches iterates matches, looks up each rule, applies entropy,
+// pattern_requirements and verify checks, then drops the matches of rules
+// that fall short of their min_matches in the blob, and returns the rest.
 func filterMatches(matches []*types.Match, rules map[string]*types.Rule) []*types.Match {
 	if len(matches) == 0 {
 		return matches
 	}
 
 	out := matches[:0:len(matches)]
+	counts := map[string]int{}
 	for _, m := range matches {
 		rule, ok := rules[m.RuleID]
 		if !ok {
@@ -128,8 +142,20 @@ func filterMatches(matches []*types.Match, rules map[string]*types.Rule) []*type
 		if !passesPatternRequirements(secret, rule.PatternRequirements) {
 			continue
 		}
+		if !passesVerifier(secret, rule.Verify) {
+			continue
+		}
 
 		out = append(out, m)
+		counts[m.RuleID]++
+	}
+
+	kept := out[:0]
+	for _, m := range out {
+		if rule, ok := rules[m.RuleID]; ok && counts[m.RuleID] < rule.MinMatches {
+			continue
+		}
+		kept = append(kept, m)
 	}
-	return out
+	return kept
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/praetorian-inc/titus/pkg/verifier"
 )
 
 // --- findSecretCapture tests ---
@@ -254,3 +255,43 @@ func TestFilterMatches_PatternRequirementsFiltering(t *testing.T) {
 		t.Errorf("unexpected match content: %q", result[0].NamedGroups["token"])
 	}
 }
+
+// --- verifier and min_matches tests ---
+
+func TestFilterMatches_Verify(t *testing.T) {
+	rules := map[string]*types.Rule{
+		"np.test.card": {ID: "np.test.card", Verify: verifier.Luhn},
+	}
+	matches := []*types.Match{
+		{RuleID: "np.test.card", Groups: [][]byte{[]byte("full"), []byte("4111111111111112")}},
+		{RuleID: "np.test.card", Groups: [][]byte{[]byte("full"), []byte("4111111111111111")}},
+	}
+	result := filterMatches(matches, rules)
+	if len(result) != 1 || string(result[0].Groups[1]) != "4111111111111111" {
+		t.Errorf("expected only the Luhn-valid match, got %d", len(result))
+	}
+}
+
+func TestFilterMatches_MinMatches(t *testing.T) {
+	rules := map[string]*types.Rule{
+		"np.test.email": {ID: "np.test.email", MinMatches: 3},
+		"np.test.other": {ID: "np.test.other"},
+	}
+	blob := func(emails ...string) []*types.Match {
+		matches := []*types.Match{{RuleID: "np.test.other", Groups: [][]byte{[]byte("x")}}}
+		for _, e := range emails {
+			matches = append(matches, &types.Match{RuleID: "np.test.email", Groups: [][]byte{[]byte(e)}})
+		}
+		return matches
+	}
+
+	result := filterMatches(blob("a@example.com", "b@example.com"), rules)
+	if len(result) != 1 || result[0].RuleID != "np.test.other" {
+		t.Fatalf("expected email matches below min_matches to be dropped, got %d matches", len(result))
+	}
+
+	result = filterMatches(blob("a@example.com", "b@example.com", "c@example.com"), rules)
+	if len(result) != 4 {
+		t.Errorf("expected all matches once min_matches is reached, got %d", len(result))
+	}
+}
@@ -1,5 +1,3 @@
-//go:build wasm
-
 package matcher
 
 import (
@@ -12,14 +10,15 @@ import (
 )
 
 // RegexpMatcher implements Matcher using regexp2 for Perl-style regex support.
-// Used for WASM builds where Hyperscan (CGO) is unavailable.
+// Used for WASM builds where Hyperscan (CGO) is unavailable, and available
+// elsewhere as the "regexp" backend.
 // Unlike HyperscanMatcher which uses a two-stage pipeline, RegexpMatcher
 // performs pattern matching and capture extraction in a single pass.
 type RegexpMatcher struct {
-	rules        []*types.Rule
-	regexCache   map[string]*regexp2.Regexp
-	contextLines int
-	warnf        func(string, ...any)
+	rules      []*types.Rule
+	regexCache map[string]*regexp2.Regexp
+	context    ContextWindow
+	warnf      func(string, ...any)
 }
 
 // NewRegexp creates a new regexp-based matcher.
@@ -29,10 +28,10 @@ func NewRegexp(rules []*types.Rule, contextLines int, warnf func(string, ...any)
 	}
 
 	m := &RegexpMatcher{
-		rules:        rules,
-		regexCache:   make(map[string]*regexp2.Regexp),
-		contextLines: contextLines,
-		warnf:        warnf,
+		rules:      rules,
+		regexCache: make(map[string]*regexp2.Regexp),
+		context:    ContextWindow{Lines: contextLines},
+		warnf:      warnf,
 	}
 
 	// Pre-compile all patterns to catch errors early
@@ -64,7 +63,7 @@ func (m *RegexpMatcher) Match(content []byte) ([]*types.Match, error) {
 func (m *RegexpMatcher) MatchWithBlobID(content []byte, blobID types.BlobID) ([]*types.Match, error) {
 	var matches []*types.Match
 	dedup := NewDeduplicator()
-	contentStr := string(content)
+	text := newRuneText(content)
 
 	for _, rule := range m.rules {
 		re := m.regexCache[rule.Pattern]
@@ -72,8 +71,10 @@ func (m *RegexpMatcher) MatchWithBlobID(content []byte, blobID types.BlobID) ([]
 			continue
 		}
 
+		groupNames := re.GetGroupNames()
+
 		// Find first match
-		match, err := re.FindStringMatch(contentStr)
+		match, err := re.FindRunesMatch(text.runes)
 		if err != nil {
 			if m.warnf != nil {
 				if strings.Contains(err.Error(), "match timeout") {
@@ -87,65 +88,7 @@ func (m *RegexpMatcher) MatchWithBlobID(content []byte, blobID types.BlobID) ([]
 
 		// Loop through all matches
 		for match != nil {
-			// regexp2's Match.Index and Match.Length are rune-based, not byte-based
-			matchedText := []byte(match.String())
-			start, end := runeSpanToByteSpan(content, match.Index, match.Length)
-
-			// Extract capture groups (positional, for backwards compatibility)
-			var groups [][]byte
-			matchGroups := match.Groups()
-			for i := 1; i < len(matchGroups); i++ {
-				group := matchGroups[i]
-				if len(group.Captures) > 0 {
-					capture := group.Captures[0]
-					groups = append(groups, []byte(capture.String()))
-				}
-			}
-
-			// Extract named capture groups
-			namedGroups := make(map[string][]byte)
-			groupNames := re.GetGroupNames()
-			for _, name := range groupNames {
-				// Skip numbered groups (they show up as "0", "1", etc.)
-				if name == "" || (len(name) > 0 && name[0] >= '0' && name[0] <= '9') {
-					continue
-				}
-				group := match.GroupByName(name)
-				if group != nil && len(group.Captures) > 0 {
-					namedGroups[name] = []byte(group.Captures[0].String())
-				}
-			}
-
-			// Extract context using corrected positions
-			var before, after []byte
-			if m.contextLines > 0 {
-				before, after = ExtractContext(content, start, end, m.contextLines)
-			}
-
-			result := &types.Match{
-				BlobID:   blobID,
-				RuleID:   rule.ID,
-				RuleName: rule.Name,
-				Location: types.Location{
-					Offset: types.OffsetSpan{
-						Start: int64(start),
-						End:   int64(end),
-					},
-				},
-				Groups:      groups,
-				NamedGroups: namedGroups,
-				Snippet: types.Snippet{
-					Before:   before,
-					Matching: matchedText,
-					After:    after,
-				},
-			}
-
-			// Compute structural ID for deduplication
-			result.StructuralID = result.ComputeStructuralID(rule.StructuralID)
-
-			// Compute finding ID for content-based deduplication (NoseyParker-compatible)
-			result.FindingID = types.ComputeFindingID(rule.StructuralID, groups)
+			result := buildMatchResult(blobID, rule, match, groupNames, text, m.context.forRule(rule))
 
 			// Deduplicate
 			if !dedup.IsDuplicate(result) {
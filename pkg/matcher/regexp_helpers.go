@@ -1,57 +1,133 @@
 package matcher
 
 import (
+	"bytes"
+	"sort"
 	"unicode/utf8"
 
 	"github.com/dlclark/regexp2"
 	"github.com/praetorian-inc/titus/pkg/types"
 )
 
-// runeSpanToByteSpan converts a rune-based span to a byte-based span.
-// regexp2 returns Match.Index and Match.Length as rune counts, not byte counts.
-// See: https://github.com/dlclark/regexp2/blob/master/match.go (Capture struct documentation)
-func runeSpanToByteSpan(content []byte, runeStart, runeLength int) (byteStart, byteEnd int) {
-	runeEnd := runeStart + runeLength
-	byteStart = len(content)
-	byteEnd = len(content)
-
-	runeIdx := 0
-	byteIdx := 0
-	for byteIdx < len(content) {
-		if runeIdx == runeStart {
-			byteStart = byteIdx
+// runeCheckpoint is the spacing, in runes, of the byte offsets runeText
+// records for non-ASCII content.
+const runeCheckpoint = 64
+
+// runeText is a blob decoded once into the runes regexp2 matches on, shared
+// by every rule (and every worker) that scans the blob. regexp2 reports
+// positions in runes, not bytes; runeText maps them back to byte offsets
+// without rescanning the blob from the start for each match.
+// It is read-only after construction and safe for concurrent use.
+type runeText struct {
+	content []byte
+	runes   []rune
+	// checkpoints[k] is the byte offset of rune k*runeCheckpoint. It is nil
+	// when every rune is one byte and rune offsets equal byte offsets.
+	checkpoints []int
+}
+
+// newRuneText decodes content. Invalid UTF-8 bytes decode to one
+// utf8.RuneError each, as in a []rune(string(content)) conversion.
+func newRuneText(content []byte) *runeText {
+	n := utf8.RuneCount(content)
+	t := &runeText{content: content, runes: make([]rune, 0, n)}
+	oneByte := n == len(content)
+	for i := 0; i < len(content); {
+		if !oneByte && len(t.runes)%runeCheckpoint == 0 {
+			t.checkpoints = append(t.checkpoints, i)
 		}
-		if runeIdx == runeEnd {
-			byteEnd = byteIdx
-			return
+		r, size := rune(content[i]), 1
+		if r >= utf8.RuneSelf {
+			r, size = utf8.DecodeRune(content[i:])
 		}
-		_, size := utf8.DecodeRune(content[byteIdx:])
-		byteIdx += size
+		t.runes = append(t.runes, r)
+		i += size
+	}
+	return t
+}
+
+// byteOffset converts a rune offset to a byte offset, clamped to the content.
+func (t *runeText) byteOffset(runeIdx int) int {
+	if runeIdx <= 0 {
+		return 0
+	}
+	if runeIdx >= len(t.runes) {
+		return len(t.content)
+	}
+	if t.checkpoints == nil {
+		return runeIdx
+	}
+	pos := t.checkpoints[runeIdx/runeCheckpoint]
+	for i := runeIdx % runeCheckpoint; i > 0; i-- {
+		_, size := utf8.DecodeRune(t.content[pos:])
+		pos += size
+	}
+	return pos
+}
+
+// runeOffset converts a byte offset to the offset of the rune containing it,
+// clamped to the content.
+func (t *runeText) runeOffset(byteIdx int) int {
+	if byteIdx <= 0 {
+		return 0
+	}
+	if byteIdx >= len(t.content) {
+		return len(t.runes)
+	}
+	if t.checkpoints == nil {
+		return byteIdx
+	}
+	k := sort.SearchInts(t.checkpoints, byteIdx+1) - 1
+	pos, runeIdx := t.checkpoints[k], k*runeCheckpoint
+	for {
+		_, size := utf8.DecodeRune(t.content[pos:])
+		if pos+size > byteIdx {
+			return runeIdx
+		}
+		pos += size
 		runeIdx++
 	}
-	// If runeEnd lands exactly at the end of content
-	if runeIdx == runeEnd {
-		byteEnd = byteIdx
+}
+
+// span converts a rune-based span, as in regexp2's Match.Index and
+// Match.Length, to a byte-based span.
+// See: https://github.com/dlclark/regexp2/blob/master/match.go (Capture struct documentation)
+func (t *runeText) span(runeStart, runeLength int) (byteStart, byteEnd int) {
+	return t.byteOffset(runeStart), t.byteOffset(runeStart + runeLength)
+}
+
+// captureBytes returns the bytes of a capture, sharing the match's copy
+// when the capture lies within the match.
+func (t *runeText) captureBytes(c regexp2.Capture, matchStart int, matching []byte) []byte {
+	start, end := t.span(c.Index, c.Length)
+	return shareCapture(t.content, start, end, matchStart, matching)
+}
+
+// shareCapture returns content[start:end]. A capture within the match
+// shares the match's copy, capped so that appending to one reallocates
+// rather than overwriting the other; any other capture is copied.
+func shareCapture(content []byte, start, end, matchStart int, matching []byte) []byte {
+	if start >= matchStart && end <= matchStart+len(matching) {
+		return matching[start-matchStart : end-matchStart : end-matchStart]
 	}
-	return
+	return bytes.Clone(content[start:end])
 }
 
 // extractCaptureGroups extracts positional capture groups from a regexp2 match.
-func extractCaptureGroups(match *regexp2.Match) [][]byte {
+func extractCaptureGroups(match *regexp2.Match, text *runeText, matchStart int, matching []byte) [][]byte {
 	var groups [][]byte
 	matchGroups := match.Groups()
 	for i := 1; i < len(matchGroups); i++ {
 		group := matchGroups[i]
 		if len(group.Captures) > 0 {
-			capture := group.Captures[0]
-			groups = append(groups, []byte(capture.String()))
+			groups = append(groups, text.captureBytes(group.Captures[0], matchStart, matching))
 		}
 	}
 	return groups
 }
 
 // extractNamedGroups extracts named capture groups from a regexp2 match.
-func extractNamedGroups(match *regexp2.Match, groupNames []string) map[string][]byte {
+func extractNamedGroups(match *regexp2.Match, groupNames []string, text *runeText, matchStart int, matching []byte) map[string][]byte {
 	namedGroups := make(map[string][]byte)
 	for _, name := range groupNames {
 		// Skip numbered groups (they show up as "0", "1", etc.)
@@ -60,38 +136,64 @@ func extractNamedGroups(match *regexp2.Match, groupNames []string) map[string][]
 		}
 		group := match.GroupByName(name)
 		if group != nil && len(group.Captures) > 0 {
-			namedGroups[name] = []byte(group.Captures[0].String())
+			namedGroups[name] = text.captureBytes(group.Captures[0], matchStart, matching)
 		}
 	}
 	return namedGroups
 }
 
-// buildMatchResult constructs a types.Match from match data.
-// runeStart and runeLength come from regexp2.Match.Index and Match.Length (rune-based).
-// matchedText should be obtained from regexp2.Match.String().
+// buildMatchResult constructs a types.Match from a regexp2 match on text.
 func buildMatchResult(
 	blobID types.BlobID,
 	rule *types.Rule,
-	runeStart int,
-	runeLength int,
-	matchedText []byte,
-	groups [][]byte,
-	namedGroups map[string][]byte,
-	content []byte,
-	contextLines int,
+	match *regexp2.Match,
+	groupNames []string,
+	text *runeText,
+	window ContextWindow,
 ) *types.Match {
-	// Convert rune-based span to byte-based span
-	start, end := runeSpanToByteSpan(content, runeStart, runeLength)
+	start, end := text.span(match.Index, match.Length)
+	snippet := copySnippet(text.content, start, end, window)
+	groups := extractCaptureGroups(match, text, start, snippet.Matching)
+	namedGroups := extractNamedGroups(match, groupNames, text, start, snippet.Matching)
+	return newMatchResult(blobID, rule, start, end, snippet, groups, namedGroups)
+}
+
+// copySnippet copies the match content[start:end] and its context out of the
+// blob once, into a single buffer. The snippet's parts are capped sub-slices
+// of it, so a stored match holds no reference to the blob.
+func copySnippet(content []byte, start, end int, window ContextWindow) types.Snippet {
+	b, a := window.around(content, start, end)
+
+	buf := make([]byte, 0, len(b)+(end-start)+len(a))
+	buf = append(buf, b...)
+	buf = append(buf, content[start:end]...)
+	buf = append(buf, a...)
+	matchEnd := len(b) + end - start
 
-	var before, after []byte
-	if contextLines > 0 {
-		before, after = ExtractContext(content, start, end, contextLines)
+	snippet := types.Snippet{Matching: buf[len(b):matchEnd:matchEnd]}
+	if len(b) > 0 {
+		snippet.Before = buf[:len(b):len(b)]
 	}
+	if len(a) > 0 {
+		snippet.After = buf[matchEnd:]
+	}
+	return snippet
+}
 
+// newMatchResult assembles a types.Match for the byte span [start, end).
+func newMatchResult(
+	blobID types.BlobID,
+	rule *types.Rule,
+	start, end int,
+	snippet types.Snippet,
+	groups [][]byte,
+	namedGroups map[string][]byte,
+) *types.Match {
 	result := &types.Match{
 		BlobID:   blobID,
 		RuleID:   rule.ID,
 		RuleName: rule.Name,
+		Kind:     rule.Kind,
 		Location: types.Location{
 			Offset: types.OffsetSpan{
 				Start: int64(start),
@@ -100,11 +202,7 @@ func buildMatchResult(
 		},
 		Groups:      groups,
 		NamedGroups: namedGroups,
-		Snippet: types.Snippet{
-			Before:   before,
-			Matching: matchedText,
-			After:    after,
-		},
+		Snippet:     snippet,
 	}
 
 	// Compute structural ID for deduplication
@@ -115,3 +213,26 @@ func buildMatchResult(
 
 	return result
 }
+
+// byteSpan is the byte range [from, to) of part of a blob.
+type byteSpan struct {
+	from, to int
+}
+
+// mergeSpans sorts spans and merges those that overlap or touch, in place.
+func mergeSpans(spans []byteSpan) []byteSpan {
+	if len(spans) == 0 {
+		return spans
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].from < spans[j].from })
+	merged := spans[:1]
+	for _, s := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if s.from <= last.to {
+			last.to = max(last.to, s.to)
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
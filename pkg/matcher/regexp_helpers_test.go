@@ -0,0 +1,93 @@
+package matcher
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuneText_ByteOffset(t *testing.T) {
+	contents := []string{
+		"",
+		"plain ascii",
+		"héllo wörld ✓ " + string(make([]byte, 200)) + "日本語 token",
+		"bad \xff\xfe utf-8 \xe2\x82 here",
+	}
+	for _, content := range contents {
+		text := newRuneText([]byte(content))
+		assert.Equal(t, []rune(content), text.runes)
+
+		// Walk the content rune by rune as the reference mapping
+		offset := 0
+		for i := 0; i <= len(text.runes); i++ {
+			assert.Equal(t, offset, text.byteOffset(i), "rune %d of %q", i, content)
+			if offset < len(content) {
+				_, size := utf8.DecodeRuneInString(content[offset:])
+				offset += size
+			}
+		}
+		assert.Equal(t, len(content), text.byteOffset(len(text.runes)+5))
+	}
+}
+
+func TestRuneText_RuneOffset(t *testing.T) {
+	content := "héllo wörld ✓ " + strings.Repeat("日本語 ", 40) + "bad \xff\xfe utf-8 \xe2\x82 token"
+	text := newRuneText([]byte(content))
+
+	// Every byte maps to the rune containing it
+	runeIdx := 0
+	for offset := 0; offset < len(content); {
+		_, size := utf8.DecodeRuneInString(content[offset:])
+		for b := offset; b < offset+size; b++ {
+			assert.Equal(t, runeIdx, text.runeOffset(b), "byte %d", b)
+		}
+		offset += size
+		runeIdx++
+	}
+	assert.Equal(t, len(text.runes), text.runeOffset(len(content)))
+	assert.Equal(t, 7, newRuneText([]byte("ascii only")).runeOffset(7))
+}
+
+func TestBuildMatchResult_SharesOneCopy(t *testing.T) {
+	rules := []*types.Rule{{ID: "test.kv", Name: "KV", Pattern: `(?P<key>ké[a-z]+)=(?P<value>[0-9]+)`}}
+	rules[0].StructuralID = rules[0].ComputeStructuralID()
+	m, err := NewPortableRegexp(rules, 1, nil)
+	require.NoError(t, err)
+
+	content := []byte("first ✓ line\nkéy=12345 ✓\nlast line\n")
+	matches, err := m.Match(content)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	match := matches[0]
+
+	start := len("first ✓ line\n")
+	assert.Equal(t, int64(start), match.Location.Offset.Start)
+	assert.Equal(t, int64(start+len("kéy=12345")), match.Location.Offset.End)
+	assert.Equal(t, "first ✓ line\n", string(match.Snippet.Before))
+	assert.Equal(t, "kéy=12345", string(match.Snippet.Matching))
+	assert.Equal(t, " ✓\n", string(match.Snippet.After))
+	assert.Equal(t, [][]byte{[]byte("kéy"), []byte("12345")}, match.Groups)
+	assert.Equal(t, []byte("12345"), match.NamedGroups["value"])
+
+	// Appending to a capture must not overwrite the snippet that shares its bytes
+	key := append(match.Groups[0], '!')
+	assert.Equal(t, "kéy!", string(key))
+	assert.Equal(t, "kéy=12345", string(match.Snippet.Matching))
+
+	// Nothing refers back to the blob
+	for i := range content {
+		content[i] = 0
+	}
+	assert.Equal(t, "kéy=12345", string(match.Snippet.Matching))
+	assert.Equal(t, "first ✓ line\n", string(match.Snippet.Before))
+}
+
+func TestMergeSpans(t *testing.T) {
+	spans := []byteSpan{{10, 20}, {0, 4}, {15, 30}, {4, 6}, {40, 41}}
+	assert.Equal(t, []byteSpan{{0, 6}, {10, 30}, {40, 41}}, mergeSpans(spans))
+	assert.Empty(t, mergeSpans(nil))
+}
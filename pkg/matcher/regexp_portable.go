@@ -26,7 +26,9 @@ const parallelThreshold = 10000 // bytes
 // - Suitable for library mode where CGO dependencies are undesirable
 //
 // Unlike HyperscanMatcher which uses a two-stage pipeline (Hyperscan for location + Go regexp for captures),
-// PortableRegexpMatcher performs pattern matching and capture extraction in a single pass using regexp2.
+// PortableRegexpMatcher performs pattern matching and capture extraction in a single pass.
+// Patterns that Go's regexp package supports with the same semantics run on it, in linear time;
+// only the remainder (lookaround, backreferences and the like) run on regexp2.
 //
 // Thread Safety: PortableRegexpMatcher is NOT safe for concurrent use.
 // If you need to scan multiple files concurrently, create separate matcher instances per goroutine.
@@ -35,10 +37,12 @@ const parallelThreshold = 10000 // bytes
 // instance may race due to the shared dedup state.
 type PortableRegexpMatcher struct {
 	rules          []*types.Rule
-	regexCache     map[string]*regexp2.Regexp   // read-only after init, safe for concurrent reads
-	groupNameCache map[string][]string          // read-only after init, safe for concurrent reads
+	stdlibCache    map[string]*stdlibPattern  // read-only after init, safe for concurrent reads
+	regexCache     map[string]*regexp2.Regexp // read-only after init, safe for concurrent reads
+	groupNameCache map[string][]string        // read-only after init, safe for concurrent reads
+	foldLiterals   bool                       // whether any stdlib pattern needs case-folded blobs
 	dedup          *Deduplicator
-	contextLines   int
+	context        ContextWindow
 	warnf          func(string, ...any)
 }
 
@@ -57,10 +61,11 @@ func NewPortableRegexp(rules []*types.Rule, contextLines int, warnf func(string,
 
 	m := &PortableRegexpMatcher{
 		rules:          rules,
+		stdlibCache:    make(map[string]*stdlibPattern),
 		regexCache:     make(map[string]*regexp2.Regexp),
 		groupNameCache: make(map[string][]string),
 		dedup:          NewContentDeduplicator(),
-		contextLines:   contextLines,
+		context:        ContextWindow{Lines: contextLines},
 		warnf:          warnf,
 	}
 
@@ -68,6 +73,14 @@ func NewPortableRegexp(rules []*types.Rule, contextLines int, warnf func(string,
 	for _, rule := range rules {
 		// Try RE2 mode first (safer, no backtracking)
 		re, err := regexp2.Compile(rule.Pattern, regexp2.RE2|regexp2.Multiline)
+		if err == nil {
+			// RE2-compatible patterns run on the stdlib engine when it gives the same results
+			if std := compileStdlib(rule.Pattern); std != nil {
+				m.stdlibCache[rule.Pattern] = std
+				m.foldLiterals = m.foldLiterals || std.needsFold()
+				continue
+			}
+		}
 		if err != nil {
 			// Fallback to default Perl-compatible mode if RE2 fails (for advanced features like (?x))
 			re, err = regexp2.Compile(rule.Pattern, regexp2.None)
@@ -108,16 +121,33 @@ func (m *PortableRegexpMatcher) matchSequential(content []byte, blobID types.Blo
 	}
 	matches := make([]*types.Match, 0, estimatedMatches)
 	m.dedup.Reset()
-	contentRunes := []rune(string(content))
+	var text *runeText
+	if len(m.regexCache) > 0 {
+		text = newRuneText(content)
+	}
+	var folded []byte
+	if m.foldLiterals {
+		folded = caseFold(content)
+	}
 
 	for _, rule := range m.rules {
+		if std := m.stdlibCache[rule.Pattern]; std != nil {
+			for _, result := range std.match(blobID, rule, content, folded, m.context.forRule(rule)) {
+				if !m.dedup.IsDuplicate(result) {
+					m.dedup.Add(result)
+					matches = append(matches, result)
+				}
+			}
+			continue
+		}
+
 		re := m.regexCache[rule.Pattern]
 		if re == nil {
 			continue
 		}
 
 		// Find first match
-		match, err := re.FindRunesMatch(contentRunes)
+		match, err := re.FindRunesMatch(text.runes)
 		if err != nil {
 			if m.warnf != nil {
 				if strings.Contains(err.Error(), "match timeout") {
@@ -131,12 +161,8 @@ func (m *PortableRegexpMatcher) matchSequential(content []byte, blobID types.Blo
 
 		// Loop through all matches
 		for match != nil {
-			// Extract capture groups
-			groups := extractCaptureGroups(match)
-			namedGroups := extractNamedGroups(match, m.groupNameCache[rule.Pattern])
-
 			// Build match result (convert rune-based Index/Length to byte offsets)
-			result := buildMatchResult(blobID, rule, match.Index, match.Length, []byte(match.String()), groups, namedGroups, content, m.contextLines)
+			result := buildMatchResult(blobID, rule, match, m.groupNameCache[rule.Pattern], text, m.context.forRule(rule))
 
 			// Deduplicate
 			if !m.dedup.IsDuplicate(result) {
@@ -165,11 +191,19 @@ func (m *PortableRegexpMatcher) matchSequential(content []byte, blobID types.Blo
 // matchParallel performs parallel matching with worker pool.
 func (m *PortableRegexpMatcher) matchParallel(content []byte, blobID types.BlobID) ([]*types.Match, error) {
 	numWorkers := runtime.GOMAXPROCS(0)
-	contentRunes := []rune(string(content))
+	var text *runeText
+	if len(m.regexCache) > 0 {
+		text = newRuneText(content)
+	}
+	var folded []byte
+	if m.foldLiterals {
+		folded = caseFold(content)
+	}
 
 	// Job channel for distributing rules to workers
 	type job struct {
 		rule *types.Rule
+		std  *stdlibPattern
 		re   *regexp2.Regexp
 	}
 	jobs := make(chan job, len(m.rules))
@@ -210,8 +244,13 @@ func (m *PortableRegexpMatcher) matchParallel(content []byte, blobID types.BlobI
 				rule := j.rule
 				re := j.re
 
+				if j.std != nil {
+					workerMatches = append(workerMatches, j.std.match(blobID, rule, content, folded, m.context.forRule(rule))...)
+					continue
+				}
+
 				// Find first match
-				match, err := re.FindRunesMatch(contentRunes)
+				match, err := re.FindRunesMatch(text.runes)
 				if err != nil {
 					if m.warnf != nil {
 						if strings.Contains(err.Error(), "match timeout") {
@@ -225,10 +264,8 @@ func (m *PortableRegexpMatcher) matchParallel(content []byte, blobID types.BlobI
 
 				// Loop through all matches
 				for match != nil {
-					// Extract capture groups and build result (convert rune-based Index/Length to byte offsets)
-					groups := extractCaptureGroups(match)
-					namedGroups := extractNamedGroups(match, m.groupNameCache[rule.Pattern])
-					matchResult := buildMatchResult(blobID, rule, match.Index, match.Length, []byte(match.String()), groups, namedGroups, content, m.contextLines)
+					// Build result (convert rune-based Index/Length to byte offsets)
+					matchResult := buildMatchResult(blobID, rule, match, m.groupNameCache[rule.Pattern], text, m.context.forRule(rule))
 					workerMatches = append(workerMatches, matchResult)
 
 					// Find next match
@@ -252,8 +289,9 @@ func (m *PortableRegexpMatcher) matchParallel(content []byte, blobID types.BlobI
 
 	// Distribute jobs
 	for _, rule := range m.rules {
-		re := m.regexCache[rule.Pattern]
-		if re != nil {
+		if std := m.stdlibCache[rule.Pattern]; std != nil {
+			jobs <- job{rule: rule, std: std}
+		} else if re := m.regexCache[rule.Pattern]; re != nil {
 			jobs <- job{rule: rule, re: re}
 		}
 	}
@@ -0,0 +1,252 @@
+//go:build !wasm
+
+package matcher
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// stdlibPattern is a rule pattern compiled with Go's regexp package. The
+// stdlib engine runs in time linear in the input, with no backtracking and
+// no timeouts, and reports byte offsets, so blobs need no rune decoding.
+// It is read-only after construction and safe for concurrent use.
+type stdlibPattern struct {
+	re *regexp.Regexp
+	// groups lists subexpression indices in regexp2's group numbering:
+	// unnamed groups first, then named groups, each in pattern order.
+	groups []int
+	// literals lists strings one of which every match contains, or is nil.
+	// A blob containing none of them is skipped without running the engine,
+	// which, lacking regexp2's prefix scans, is slow on blobs it can't match;
+	// in others, only the spans around them that hints bound are searched.
+	literals []requiredLiteral
+	hints    scanHints
+}
+
+// spacedBraces matches braces with whitespace inside, such as {1, 5}, which
+// regexp2 takes literally but which are quantifiers once extended mode
+// whitespace is stripped.
+var spacedBraces = regexp.MustCompile(`\{[0-9,]*\s[0-9,\s]*\}`)
+
+// requiredLiteral is a string a match must contain. A case-insensitive
+// literal holds its caseFold form and is looked for in the folded blob.
+type requiredLiteral struct {
+	text []byte
+	fold bool
+}
+
+// minLiteralLen is the length below which required literals are too common
+// to be worth checking for.
+const minLiteralLen = 3
+
+// compileStdlib compiles a pattern for the stdlib tier, with the semantics
+// regexp2 gives it in RE2|Multiline mode. It returns nil for patterns that
+// need regexp2: those Go's syntax rejects (such as lookaround or
+// backreferences, after any extended mode syntax is stripped), and those whose captures could differ between the engines.
+func compileStdlib(pattern string) *stdlibPattern {
+	expr, extended := stripFreeSpacing(pattern)
+	if extended && spacedBraces.MatchString(pattern) {
+		return nil
+	}
+	// regexp2 records a repeated group's first capture and Go its last
+	tree, err := syntax.Parse(expr, syntax.Perl&^syntax.OneLine)
+	if err != nil || repeatsCapture(tree, false) {
+		return nil
+	}
+	re, err := regexp.Compile("(?m)" + expr)
+	if err != nil {
+		return nil
+	}
+
+	p := &stdlibPattern{re: re, hints: newScanHints(tree)}
+	if lits := requiredLiterals(tree); shortestLiteral(lits) >= minLiteralLen {
+		p.literals = lits
+	}
+	names := re.SubexpNames()
+	for i := 1; i < len(names); i++ {
+		if names[i] == "" {
+			p.groups = append(p.groups, i)
+		}
+	}
+	for i := 1; i < len(names); i++ {
+		if names[i] != "" {
+			p.groups = append(p.groups, i)
+		}
+	}
+	return p
+}
+
+// repeatsCapture reports whether a capture group in re can match more than
+// once.
+func repeatsCapture(re *syntax.Regexp, repeated bool) bool {
+	switch re.Op {
+	case syntax.OpCapture:
+		if repeated {
+			return true
+		}
+	case syntax.OpStar, syntax.OpPlus:
+		repeated = true
+	case syntax.OpRepeat:
+		repeated = repeated || re.Max != 1
+	}
+	for _, sub := range re.Sub {
+		if repeatsCapture(sub, repeated) {
+			return true
+		}
+	}
+	return false
+}
+
+// requiredLiterals returns strings one of which every match of re contains,
+// or nil if it finds none.
+func requiredLiterals(re *syntax.Regexp) []requiredLiteral {
+	switch re.Op {
+	case syntax.OpLiteral:
+		text := string(re.Rune)
+		if strings.ContainsRune(text, utf8.RuneError) {
+			return nil // matches invalid UTF-8 too
+		}
+		fold := re.Flags&syntax.FoldCase != 0
+		if fold {
+			text = string(caseFold([]byte(text)))
+		}
+		return []requiredLiteral{{text: []byte(text), fold: fold}}
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredLiterals(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min > 0 {
+			return requiredLiterals(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		var best []requiredLiteral
+		for _, sub := range re.Sub {
+			if lits := requiredLiterals(sub); shortestLiteral(lits) > shortestLiteral(best) {
+				best = lits
+			}
+		}
+		return best
+	case syntax.OpAlternate:
+		var lits []requiredLiteral
+		for _, sub := range re.Sub {
+			subLits := requiredLiterals(sub)
+			if subLits == nil {
+				return nil
+			}
+			lits = append(lits, subLits...)
+		}
+		return lits
+	}
+	return nil
+}
+
+// shortestLiteral returns the length of the shortest of lits, or 0 if there
+// are none.
+func shortestLiteral(lits []requiredLiteral) int {
+	shortest := 0
+	for i, lit := range lits {
+		if i == 0 || len(lit.text) < shortest {
+			shortest = len(lit.text)
+		}
+	}
+	return shortest
+}
+
+// needsFold reports whether checking p's literals needs the folded blob.
+func (p *stdlibPattern) needsFold() bool {
+	for _, lit := range p.literals {
+		if lit.fold {
+			return true
+		}
+	}
+	return false
+}
+
+// caseFold maps each rune of b to the smallest rune it matches case-
+// insensitively, so that b contains a folded literal exactly when it
+// contains the literal in any case. Invalid UTF-8 bytes are kept as they are.
+// No rune folds to a longer one, so b and its folded form have the same
+// length only if every rune keeps its length and offset.
+func caseFold(b []byte) []byte {
+	folded := make([]byte, 0, len(b))
+	for i := 0; i < len(b); {
+		c := b[i]
+		if c < utf8.RuneSelf {
+			if 'a' <= c && c <= 'z' {
+				c -= 'a' - 'A'
+			}
+			folded = append(folded, c)
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(b[i:])
+		if r == utf8.RuneError && size == 1 {
+			folded = append(folded, c)
+		} else {
+			folded = utf8.AppendRune(folded, foldRune(r))
+		}
+		i += size
+	}
+	return folded
+}
+
+// foldRune returns the smallest rune in r's case folding orbit.
+func foldRune(r rune) rune {
+	least := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < least {
+			least = f
+		}
+	}
+	return least
+}
+
+// match returns the matches of p in content, built like regexp2 matches.
+// folded is content's caseFold form, if p needs it.
+func (p *stdlibPattern) match(blobID types.BlobID, rule *types.Rule, content, folded []byte, window ContextWindow) []*types.Match {
+	spans, whole := literalSpans(content, folded, p.literals, p.hints)
+	var locs [][]int
+	if whole {
+		locs = p.re.FindAllSubmatchIndex(content, -1)
+	}
+	for _, span := range spans {
+		for _, loc := range p.re.FindAllSubmatchIndex(content[span.from:span.to], -1) {
+			for i := range loc {
+				if loc[i] >= 0 {
+					loc[i] += span.from
+				}
+			}
+			locs = append(locs, loc)
+		}
+	}
+	if len(locs) == 0 {
+		return nil
+	}
+	names := p.re.SubexpNames()
+	matches := make([]*types.Match, 0, len(locs))
+	for _, loc := range locs {
+		start, end := loc[0], loc[1]
+		snippet := copySnippet(content, start, end, window)
+
+		var groups [][]byte
+		namedGroups := make(map[string][]byte)
+		for _, i := range p.groups {
+			// Groups that did not participate in the match are skipped
+			if loc[2*i] < 0 {
+				continue
+			}
+			group := shareCapture(content, loc[2*i], loc[2*i+1], start, snippet.Matching)
+			groups = append(groups, group)
+			if names[i] != "" {
+				namedGroups[names[i]] = group
+			}
+		}
+		matches = append(matches, newMatchResult(blobID, rule, start, end, snippet, groups, namedGroups))
+	}
+	return matches
+}
@@ -0,0 +1,146 @@
+//go:build !wasm
+
+package matcher
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dlclark/regexp2"
+
+	"github.com/praetorian-inc/titus/pkg/rule"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileStdlib(t *testing.T) {
+	tests := []struct {
+		pattern string
+		stdlib  bool
+	}{
+		{`\b(AKIA[A-Z0-9]{16})\b`, true},
+		{`(?i)password\s*[:=]\s*"(?P<secret>[^"]+)"`, true},
+		{`(?:key|token)=([a-z0-9]{32})`, true},
+		{`(?x) token = ([a-z]+) # comment`, true}, // extended mode is stripped
+		{`(?xi) [a-z]+ (?#comment)`, true},
+		{`(?x) a .{1, 5} b`, false}, // braces regexp2 takes literally
+		{`(?<=key=)[a-z]+`, false},  // lookbehind
+		{`(['"])[a-z]+\1`, false},   // backreference
+		{`(a+)+b`, false},           // repeated capture
+		{`(?:-([0-9]{4}))*`, false}, // repeated capture
+		{`(x)?y`, true},             // optional capture matches at most once
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			assert.Equal(t, tt.stdlib, compileStdlib(tt.pattern) != nil)
+		})
+	}
+}
+
+func TestStdlibPattern_RequiredLiterals(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		literals []requiredLiteral
+	}{
+		{`\bghp_[a-zA-Z0-9]{36}\b`, []requiredLiteral{{text: []byte("ghp_")}}},
+		{`(?i)secret\s*=\s*(\w+)`, []requiredLiteral{{text: []byte("SECRET"), fold: true}}},
+		{`(?:token|apikey)=(\w+)`, []requiredLiteral{{text: []byte("token")}, {text: []byte("apikey")}}},
+		{`(?:key)?[a-f0-9]{32}`, nil}, // optional
+		{`xy[a-f0-9]{32}`, nil},       // too short
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			p := compileStdlib(tt.pattern)
+			require.NotNil(t, p)
+			assert.Equal(t, tt.literals, p.literals)
+		})
+	}
+
+	p := compileStdlib(`(?i)secret=(\w+)`)
+	content := []byte("ſEcReT=1")
+	_, whole := literalSpans(content, caseFold(content), p.literals, p.hints)
+	assert.True(t, whole, "ſ folds to s, shortening the folded blob")
+	content = []byte("token=1")
+	spans, whole := literalSpans(content, caseFold(content), p.literals, p.hints)
+	assert.False(t, whole)
+	assert.Empty(t, spans)
+}
+
+func TestCaseFold(t *testing.T) {
+	assert.Equal(t, "ABC_SK\xff\u00c9", string(caseFold([]byte("aBc_\u017f\u212a\xff\u00e9"))))
+}
+
+func TestStdlibPattern_GroupOrderMatchesRegexp2(t *testing.T) {
+	// regexp2 numbers unnamed groups before named ones and skips groups that
+	// did not participate
+	rules := []*types.Rule{{ID: "test.order", Name: "Order", Pattern: `(?P<a>x)(y)(z)?(?P<b>w)`}}
+	rules[0].StructuralID = rules[0].ComputeStructuralID()
+	content := []byte("--xyw--")
+
+	portable, err := NewPortableRegexp(rules, 0, nil)
+	require.NoError(t, err)
+	require.Contains(t, portable.stdlibCache, rules[0].Pattern)
+	reference := regexp2Only(t, rules, 0)
+
+	got, err := portable.Match(content)
+	require.NoError(t, err)
+	want, err := reference.Match(content)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, [][]byte{[]byte("y"), []byte("x"), []byte("w")}, got[0].Groups)
+	assert.Equal(t, want, got)
+}
+
+// TestStdlibTier_BuiltinRuleParity checks that every built-in rule the
+// stdlib tier takes over finds the same matches as regexp2 on the rule's
+// examples, where regexp2 finishes.
+func TestStdlibTier_BuiltinRuleParity(t *testing.T) {
+	rules, err := rule.NewLoader().LoadBuiltinRules()
+	require.NoError(t, err)
+
+	stdlibRules := 0
+	for _, r := range rules {
+		if compileStdlib(r.Pattern) == nil {
+			continue
+		}
+		stdlibRules++
+		content := []byte(strings.Join(append(append([]string{}, r.Examples...), r.NegativeExamples...), "\n~~~\n") + "\nnon-ASCII: é ✓ 日本\n")
+
+		portable, err := NewPortableRegexp([]*types.Rule{r}, 2, nil)
+		require.NoError(t, err)
+		reference := regexp2Only(t, []*types.Rule{r}, 2)
+		timedOut := false
+		reference.warnf = func(string, ...any) { timedOut = true }
+
+		got, err := portable.Match(content)
+		require.NoError(t, err)
+		want, err := reference.Match(content)
+		require.NoError(t, err)
+		if timedOut {
+			// The stdlib engine doesn't backtrack, so it can still match
+			assert.NotEmpty(t, got, "rule %s", r.ID)
+			continue
+		}
+		assert.Equal(t, want, got, "rule %s", r.ID)
+	}
+	assert.Greater(t, stdlibRules, len(rules)/2, "most built-in rules should run on the stdlib engine")
+}
+
+// regexp2Only returns a portable matcher that runs every rule on regexp2,
+// with a shorter timeout than usual.
+func regexp2Only(t *testing.T, rules []*types.Rule, contextLines int) *PortableRegexpMatcher {
+	t.Helper()
+	m, err := NewPortableRegexp(rules, contextLines, nil)
+	require.NoError(t, err)
+	for pattern := range m.stdlibCache {
+		re, err := regexp2.Compile(pattern, regexp2.RE2|regexp2.Multiline)
+		require.NoError(t, err)
+		re.MatchTimeout = time.Second
+		m.regexCache[pattern] = re
+		m.groupNameCache[pattern] = re.GetGroupNames()
+	}
+	m.stdlibCache = map[string]*stdlibPattern{}
+	return m
+}
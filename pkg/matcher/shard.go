@@ -0,0 +1,148 @@
+package matcher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// maxShardPatterns is the most patterns compiled into one Hyperscan
+// database. Compile time and memory grow faster than linearly with the
+// pattern count, so large rule sets are split across several databases,
+// each scanned in turn.
+const maxShardPatterns = 500
+
+// shardPlan records how a rule set's Hyperscan patterns, identified by their
+// index, are split across databases: which patterns don't compile and go to
+// the regexp2 fallback, and which compile with SOM_LEFTMOST. Finding these
+// takes many trial compiles, so plans are cached on disk under a key derived
+// from the patterns.
+type shardPlan struct {
+	Key          string  `json:"key"`
+	Shards       [][]int `json:"shards"`
+	Incompatible []int   `json:"incompatible,omitempty"`
+	SOM          []int   `json:"som,omitempty"`
+}
+
+// planShards splits patterns 0..n-1 into shards of at most size patterns,
+// leaving out those that don't compile. compiles reports whether a set of
+// patterns compiles into one database.
+func planShards(n, size int, compiles func(idx []int) bool) shardPlan {
+	var plan shardPlan
+	for start := 0; start < n; start += size {
+		shard := make([]int, 0, min(size, n-start))
+		for i := start; i < min(start+size, n); i++ {
+			shard = append(shard, i)
+		}
+		if !compiles(shard) {
+			incompatible := findIncompatible(shard, compiles)
+			plan.Incompatible = append(plan.Incompatible, incompatible...)
+			shard = without(shard, incompatible)
+		}
+		if len(shard) > 0 {
+			plan.Shards = append(plan.Shards, shard)
+		}
+	}
+	return plan
+}
+
+// findIncompatible uses binary search to identify the patterns in idx that
+// don't compile, in O(log n) compiles when there are few of them.
+func findIncompatible(idx []int, compiles func(idx []int) bool) []int {
+	if len(idx) == 0 || compiles(idx) {
+		return nil
+	}
+	if len(idx) == 1 {
+		return idx
+	}
+	mid := len(idx) / 2
+	return append(findIncompatible(idx[:mid], compiles), findIncompatible(idx[mid:], compiles)...)
+}
+
+// without returns the elements of idx not in remove.
+func without(idx, remove []int) []int {
+	drop := make(map[int]bool, len(remove))
+	for _, i := range remove {
+		drop[i] = true
+	}
+	kept := make([]int, 0, len(idx))
+	for _, i := range idx {
+		if !drop[i] {
+			kept = append(kept, i)
+		}
+	}
+	return kept
+}
+
+// shardPlanKey identifies a plan by the Hyperscan version and the patterns,
+// each given as its expression and compile flags.
+func shardPlanKey(version string, patterns []string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%d\n", version, maxShardPatterns)
+	for _, p := range patterns {
+		fmt.Fprintf(h, "%d:%s\n", len(p), p)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// shardPlanPath returns the file a plan with the given key is cached in.
+func shardPlanPath(dir, key string) string {
+	return filepath.Join(dir, "shards-"+key[:16]+".json")
+}
+
+// loadShardPlan reads the cached plan for key, if there is a valid one for
+// n patterns.
+func loadShardPlan(dir, key string, n int) (shardPlan, bool) {
+	var plan shardPlan
+	if dir == "" {
+		return plan, false
+	}
+	data, err := os.ReadFile(shardPlanPath(dir, key))
+	if err != nil || json.Unmarshal(data, &plan) != nil || plan.Key != key {
+		return shardPlan{}, false
+	}
+	for _, list := range append([][]int{plan.Incompatible, plan.SOM}, plan.Shards...) {
+		for _, i := range list {
+			if i < 0 || i >= n {
+				return shardPlan{}, false
+			}
+		}
+	}
+	return plan, true
+}
+
+// saveShardPlan caches plan in dir.
+func saveShardPlan(dir string, plan shardPlan) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating shard plan directory: %w", err)
+	}
+	data, err := json.Marshal(plan)
+	if err != nil {
+		return fmt.Errorf("encoding shard plan: %w", err)
+	}
+
+	// Write atomically using temp file + rename
+	path := shardPlanPath(dir, plan.Key)
+	tempPath := path + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		return fmt.Errorf("writing shard plan: %w", err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("renaming shard plan: %w", err)
+	}
+	return nil
+}
+
+// defaultShardPlanDir returns the directory shard plans are cached in, or ""
+// if there is no user cache directory.
+func defaultShardPlanDir() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cacheDir, "titus", "hyperscan")
+}
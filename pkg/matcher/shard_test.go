@@ -0,0 +1,61 @@
+package matcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanShards(t *testing.T) {
+	// Patterns 3, 4 and 9 don't compile
+	bad := map[int]bool{3: true, 4: true, 9: true}
+	compiles := 0
+	plan := planShards(11, 4, func(idx []int) bool {
+		compiles++
+		for _, i := range idx {
+			if bad[i] {
+				return false
+			}
+		}
+		return true
+	})
+
+	assert.Equal(t, [][]int{{0, 1, 2}, {5, 6, 7}, {8, 10}}, plan.Shards)
+	assert.Equal(t, []int{3, 4, 9}, plan.Incompatible)
+	assert.Less(t, compiles, 20)
+
+	// A shard that compiles at once takes one compile
+	compiles = 0
+	plan = planShards(1000, maxShardPatterns, func([]int) bool { compiles++; return true })
+	assert.Len(t, plan.Shards, 2)
+	assert.Empty(t, plan.Incompatible)
+	assert.Equal(t, 2, compiles)
+}
+
+func TestShardPlanCache(t *testing.T) {
+	dir := t.TempDir()
+	key := shardPlanKey("5.4.11", []string{"0:foo[0-9]+", "1:bar"})
+	assert.NotEqual(t, key, shardPlanKey("5.4.12", []string{"0:foo[0-9]+", "1:bar"}))
+	assert.NotEqual(t, key, shardPlanKey("5.4.11", []string{"0:foo[0-9]+", "0:bar"}))
+
+	_, ok := loadShardPlan(dir, key, 2)
+	assert.False(t, ok)
+
+	plan := shardPlan{Key: key, Shards: [][]int{{0}}, Incompatible: []int{1}, SOM: []int{0}}
+	require.NoError(t, saveShardPlan(dir, plan))
+	got, ok := loadShardPlan(dir, key, 2)
+	require.True(t, ok)
+	assert.Equal(t, plan, got)
+
+	// Plans that don't fit the patterns are ignored
+	_, ok = loadShardPlan(dir, key, 1)
+	assert.False(t, ok)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shards-"+key[:16]+".json"), []byte("{"), 0644))
+	_, ok = loadShardPlan(dir, key, 2)
+	assert.False(t, ok)
+	_, ok = loadShardPlan("", key, 2)
+	assert.False(t, ok)
+}
@@ -0,0 +1,39 @@
+package matcher
+
+import (
+	"github.com/dlclark/regexp2"
+)
+
+// findInSpans calls fn with the matches of re in text that regexp2 would
+// find scanning the whole blob, searching only the merged spans Hyperscan
+// reported for the pattern. Every match lies within one of them, since
+// Hyperscan reports each end position a match can have, with its leftmost
+// start. Each search sees the blob from its start up to one rune past the
+// span, so anchors and word boundaries at a match's edges behave as in a
+// whole-blob search.
+func findInSpans(re *regexp2.Regexp, text *runeText, spans []byteSpan, fn func(*regexp2.Match)) error {
+	lastEnd := 0
+	for _, s := range spans {
+		start, end := text.runeOffset(s.from), text.runeOffset(s.to)
+		if text.byteOffset(end) < s.to {
+			end++ // the span ends within a rune
+		}
+		if end < lastEnd {
+			continue
+		}
+		start = max(start, lastEnd)
+
+		match, err := re.FindRunesMatchStartingAt(text.runes[:min(end+1, len(text.runes))], start)
+		for ; err == nil && match != nil; match, err = re.FindNextMatch(match) {
+			if match.Index+match.Length > end {
+				break // runs past the span, where the search is cut short
+			}
+			fn(match)
+			lastEnd = match.Index + match.Length
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
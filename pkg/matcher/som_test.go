@@ -0,0 +1,43 @@
+package matcher
+
+import (
+	"testing"
+
+	"github.com/dlclark/regexp2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindInSpans_MatchesWholeBlobSearch(t *testing.T) {
+	re := regexp2.MustCompile(`\bkéy\d+\b`, regexp2.RE2|regexp2.Multiline)
+	text := newRuneText([]byte("xkéy12 kéy34 kéy5x6 ✓ kéy78\nkéy9"))
+
+	// Every match of a whole-blob search, as Hyperscan would report them,
+	// plus a span with no match in it
+	var want []string
+	var spans []byteSpan
+	match, err := re.FindRunesMatch(text.runes)
+	for ; err == nil && match != nil; match, err = re.FindNextMatch(match) {
+		want = append(want, match.String())
+		from, to := text.span(match.Index, match.Length)
+		spans = append(spans, byteSpan{from, to})
+	}
+	require.NoError(t, err)
+	require.Equal(t, []string{"kéy34", "kéy78", "kéy9"}, want)
+	spans = append(spans, byteSpan{0, 7})
+
+	var got []string
+	err = findInSpans(re, text, mergeSpans(spans), func(m *regexp2.Match) {
+		got = append(got, m.String())
+	})
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	// A span cut short of the match's end finds nothing
+	got = nil
+	err = findInSpans(re, text, []byteSpan{{spans[0].from, spans[0].to - 1}}, func(m *regexp2.Match) {
+		got = append(got, m.String())
+	})
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
@@ -26,17 +26,21 @@ import (
 // - 10-100x faster than pure Go regex implementations for large content
 // - Requires CGO and the Hyperscan/Vectorscan C library
 //
+// Large rule sets are split across several databases (see maxShardPatterns),
+// each scanned in turn.
+//
 // Thread Safety:
-// - The compiled database is immutable and safe for concurrent use
+// - The compiled databases are immutable and safe for concurrent use
 // - Each goroutine needs its own scratch space (handled via sync.Pool)
 // - Match() is safe for concurrent calls from multiple goroutines
 type VectorscanMatcher struct {
-	rules        []*types.Rule
-	db           hyperscan.BlockDatabase
-	scratch      *hyperscan.Scratch
-	scratchPool  sync.Pool
-	prefilter    *prefilter.Prefilter
-	contextLines int
+	rules       []*types.Rule
+	dbs         []hyperscan.BlockDatabase
+	scratch     *hyperscan.Scratch
+	scratchPool sync.Pool
+	prefilter   *prefilter.Prefilter
+	context     ContextWindow
+	chunking    ChunkConfig
 
 	// Pattern ID to rule mapping (Hyperscan uses integer IDs)
 	patternToRule map[uint]*types.Rule
@@ -50,6 +54,13 @@ type VectorscanMatcher struct {
 	hsRules       []*types.Rule // Rules compiled into Hyperscan
 	fallbackRules []*types.Rule // Rules that require regexp2 fallback
 
+	// Pattern IDs compiled with SOM_LEFTMOST, whose matches regexp2 only
+	// confirms within the spans Hyperscan reports
+	somPatterns map[uint]bool
+
+	// Directory the shard plan is cached in ("" disables the cache)
+	planDir string
+
 	warnf func(string, ...any)
 }
 
@@ -85,24 +96,36 @@ func NewVectorscan(rules []*types.Rule, contextLines int, warnf func(string, ...
 
 	m := &VectorscanMatcher{
 		rules:          rules,
-		contextLines:   contextLines,
+		context:        ContextWindow{Lines: contextLines},
+		chunking:       DefaultChunkConfig(),
 		patternToRule:  make(map[uint]*types.Rule),
 		regexCache:     make(map[string]*regexp2.Regexp),
 		groupNameCache: make(map[string][]string),
 		prefilter:      prefilter.New(rules),
+		planDir:        defaultShardPlanDir(),
 		warnf:          warnf,
 	}
 
-	// Compile patterns into Hyperscan database
+	// Compile patterns into Hyperscan databases
 	if err := m.compilePatterns(); err != nil {
 		return nil, fmt.Errorf("compile patterns: %w", err)
 	}
 
-	// Only initialize scratch space if we have a Hyperscan database
-	if m.db != nil {
-		scratch, err := hyperscan.NewScratch(m.db)
+	// Only initialize scratch space if we have a Hyperscan database; one
+	// scratch, sized for the largest, serves them all
+	if len(m.dbs) > 0 {
+		scratch, err := hyperscan.NewScratch(m.dbs[0])
+		for _, db := range m.dbs[1:] {
+			if err != nil {
+				break
+			}
+			err = scratch.Realloc(db)
+		}
 		if err != nil {
-			m.db.Close()
+			if scratch != nil {
+				scratch.Free()
+			}
+			closeDatabases(m.dbs)
 			return nil, fmt.Errorf("allocate scratch: %w", err)
 		}
 		m.scratch = scratch
@@ -124,25 +147,23 @@ func NewVectorscan(rules []*types.Rule, contextLines int, warnf func(string, ...
 
 // compilePatterns compiles rule patterns using an optimized hybrid approach:
 // 1. Check each rule against knownIncompatiblePatterns and route to fallback immediately
-// 2. Attempt to compile remaining patterns at once (fast path)
-// 3. If that fails, use binary search to identify newly incompatible patterns
+// 2. Split the remaining patterns into shards of maxShardPatterns, compiling each at once (fast path)
+// 3. If a shard fails to compile, use binary search to identify its incompatible patterns
 // 4. Incompatible patterns use regexp2 fallback
+// 5. Patterns that compile with SOM_LEFTMOST get it
 //
-// This optimization reduces compilation time from O(n) to O(1) in the common
-// case where all patterns are compatible, or O(log n) when a few are not.
+// The resulting shard plan is cached on disk, keyed by the patterns, so later
+// runs with the same rules compile each shard once and skip the trials.
 func (m *VectorscanMatcher) compilePatterns() error {
 	// Build pattern list with preprocessing
-	type patternInfo struct {
-		rule    *types.Rule
-		pattern *hyperscan.Pattern
-		index   int
-	}
-	allPatterns := make([]patternInfo, 0, len(m.rules))
+	var hsCandidates []*types.Rule
+	var patterns []*hyperscan.Pattern
+	var planPatterns []string
 
 	// Track known incompatible rules separately
 	var knownFallbackRules []*types.Rule
 
-	for i, rule := range m.rules {
+	for _, rule := range m.rules {
 		// Check if this is a known incompatible pattern
 		if knownIncompatiblePatterns[rule.ID] {
 			knownFallbackRules = append(knownFallbackRules, rule)
@@ -165,58 +186,55 @@ func (m *VectorscanMatcher) compilePatterns() error {
 		}
 
 		p := hyperscan.NewPattern(pattern, flags)
-		p.Id = i
-		allPatterns = append(allPatterns, patternInfo{rule: rule, pattern: p, index: i})
+		p.Id = len(patterns)
+		patterns = append(patterns, p)
+		hsCandidates = append(hsCandidates, rule)
+		planPatterns = append(planPatterns, fmt.Sprintf("%d:%s", flags, pattern))
 	}
 
-	// Try to compile all patterns at once (fast path)
-	hsPatternList := make([]*hyperscan.Pattern, len(allPatterns))
-	for i, pi := range allPatterns {
-		hsPatternList[i] = pi.pattern
+	key := shardPlanKey(hyperscan.Version(), planPatterns)
+	plan, cached := loadShardPlan(m.planDir, key, len(patterns))
+	if !cached {
+		plan = planHyperscanShards(patterns)
+		plan.Key = key
 	}
 
-	var hsPatterns []*hyperscan.Pattern
-	var hsRules []*types.Rule
-	var fallbackRules []*types.Rule
-	var discoveredFallbackRules []*types.Rule // Track which fallback rules were discovered (not known)
-	hsPatternToRule := make(map[uint]*types.Rule)
+	dbs, err := compileShards(patterns, plan)
+	if err != nil && cached {
+		// The cached plan no longer holds (e.g. a different Hyperscan build)
+		plan = planHyperscanShards(patterns)
+		plan.Key = key
+		cached = false
+		dbs, err = compileShards(patterns, plan)
+	}
+	if err != nil {
+		return err
+	}
+	if !cached && m.planDir != "" {
+		if err := saveShardPlan(m.planDir, plan); err != nil && m.warnf != nil {
+			m.warnf("[warn] caching Hyperscan shard plan: %v\n", err)
+		}
+	}
 
 	// Start with known incompatible patterns in fallback
-	fallbackRules = append(fallbackRules, knownFallbackRules...)
-
-	// Try to compile all patterns at once (fast path)
-	// If successful, we'll reuse this database instead of compiling again
-	var firstCompileDB hyperscan.BlockDatabase
-	firstCompileDB, err := hyperscan.NewBlockDatabase(hsPatternList...)
-	if err == nil {
-		// All patterns are compatible! Fast path - reuse this compilation
-		for i, pi := range allPatterns {
-			pi.pattern.Id = i
-			hsPatterns = append(hsPatterns, pi.pattern)
-			hsRules = append(hsRules, pi.rule)
-			hsPatternToRule[uint(i)] = pi.rule
-		}
-	} else {
-		// Some patterns are incompatible - use binary search to find them
-		incompatibleIndices := findIncompatiblePatterns(hsPatternList)
-		incompatibleSet := make(map[int]bool)
-		for _, idx := range incompatibleIndices {
-			incompatibleSet[idx] = true
-		}
-
-		// Separate compatible from incompatible
-		for _, pi := range allPatterns {
-			if incompatibleSet[pi.index] {
-				fallbackRules = append(fallbackRules, pi.rule)
-				discoveredFallbackRules = append(discoveredFallbackRules, pi.rule)
-			} else {
-				pi.pattern.Id = len(hsPatterns)
-				hsPatterns = append(hsPatterns, pi.pattern)
-				hsRules = append(hsRules, pi.rule)
-				hsPatternToRule[uint(len(hsPatterns)-1)] = pi.rule
-			}
+	fallbackRules := append([]*types.Rule(nil), knownFallbackRules...)
+	var discoveredFallbackRules []*types.Rule // Track which fallback rules were discovered (not known)
+	for _, i := range plan.Incompatible {
+		fallbackRules = append(fallbackRules, hsCandidates[i])
+		discoveredFallbackRules = append(discoveredFallbackRules, hsCandidates[i])
+	}
+	var hsRules []*types.Rule
+	hsPatternToRule := make(map[uint]*types.Rule)
+	for _, shard := range plan.Shards {
+		for _, i := range shard {
+			hsRules = append(hsRules, hsCandidates[i])
+			hsPatternToRule[uint(i)] = hsCandidates[i]
 		}
 	}
+	somPatterns := make(map[uint]bool, len(plan.SOM))
+	for _, i := range plan.SOM {
+		somPatterns[uint(i)] = true
+	}
 
 	// Build regex cache for ALL rules (needed for capture extraction and fallback)
 	for _, rule := range m.rules {
@@ -225,6 +243,7 @@ func (m *VectorscanMatcher) compilePatterns() error {
 			// Fallback to Perl-compatible mode
 			re, err = regexp2.Compile(rule.Pattern, regexp2.None)
 			if err != nil {
+				closeDatabases(dbs)
 				return fmt.Errorf("failed to compile pattern %q for rule %s: %w", rule.Pattern, rule.ID, err)
 			}
 		}
@@ -237,25 +256,12 @@ func (m *VectorscanMatcher) compilePatterns() error {
 	m.hsRules = hsRules
 	m.fallbackRules = fallbackRules
 	m.patternToRule = hsPatternToRule
-
-	// Set the database - either reuse from fast path or compile incompatible subset
-	if len(hsPatterns) > 0 {
-		if firstCompileDB != nil {
-			// Fast path: reuse the first successful compilation
-			m.db = firstCompileDB
-		} else {
-			// Slow path: compile only the compatible patterns after binary search
-			db, err := hyperscan.NewBlockDatabase(hsPatterns...)
-			if err != nil {
-				return fmt.Errorf("compile database: %w", err)
-			}
-			m.db = db
-		}
-	}
+	m.somPatterns = somPatterns
+	m.dbs = dbs
 
 	// Print diagnostic info about pattern compilation
-	fmt.Fprintf(os.Stderr, "[vectorscan] %d/%d rules compiled for Hyperscan, %d rules use regexp2 fallback\n",
-		len(hsRules), len(m.rules), len(fallbackRules))
+	fmt.Fprintf(os.Stderr, "[vectorscan] %d/%d rules compiled for Hyperscan (%d with start of match) in %d databases, %d rules use regexp2 fallback\n",
+		len(hsRules), len(m.rules), len(somPatterns), len(dbs), len(fallbackRules))
 
 	// Print which rules are using fallback (for debugging)
 	if len(knownFallbackRules) > 0 {
@@ -274,33 +280,108 @@ func (m *VectorscanMatcher) compilePatterns() error {
 	return nil
 }
 
-// findIncompatiblePatterns uses binary search to identify patterns that
-// cannot be compiled by Hyperscan. Returns the indices of incompatible patterns.
-func findIncompatiblePatterns(patterns []*hyperscan.Pattern) []int {
-	if len(patterns) == 0 {
-		return nil
+// planHyperscanShards finds the shard plan for patterns by trial compiles.
+func planHyperscanShards(patterns []*hyperscan.Pattern) shardPlan {
+	plan := planShards(len(patterns), maxShardPatterns, func(idx []int) bool {
+		db, err := hyperscan.NewBlockDatabase(selectPatterns(patterns, idx, nil)...)
+		if err != nil {
+			return false
+		}
+		db.Close()
+		return true
+	})
+
+	// Patterns that compile with SOM_LEFTMOST one at a time may still not
+	// compile together; such shards do without it
+	for _, shard := range plan.Shards {
+		som := somCompatible(selectPatterns(patterns, shard, nil))
+		if len(som) == 0 {
+			continue
+		}
+		db, err := hyperscan.NewBlockDatabase(selectPatterns(patterns, shard, som)...)
+		if err != nil {
+			continue
+		}
+		db.Close()
+		plan.SOM = append(plan.SOM, som...)
 	}
+	return plan
+}
 
-	// Try to compile all patterns
-	_, err := hyperscan.NewBlockDatabase(patterns...)
-	if err == nil {
-		return nil // All patterns are compatible
+// somCompatible returns the IDs of the patterns that compile with
+// SOM_LEFTMOST. Most patterns don't: Hyperscan can't track the start of match
+// for them within its limits. Each pattern is tried on its own, so that the
+// failures don't compound, with the trials spread across CPUs.
+func somCompatible(patterns []*hyperscan.Pattern) []int {
+	ok := make([]bool, len(patterns))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				som := *patterns[i]
+				som.Flags |= hyperscan.SomLeftMost
+				if db, err := hyperscan.NewBlockDatabase(&som); err == nil {
+					db.Close()
+					ok[i] = true
+				}
+			}
+		}()
+	}
+	for i := range patterns {
+		jobs <- i
 	}
+	close(jobs)
+	wg.Wait()
 
-	// If only one pattern, it's the incompatible one
-	if len(patterns) == 1 {
-		return []int{patterns[0].Id}
+	var ids []int
+	for i, p := range patterns {
+		if ok[i] {
+			ids = append(ids, p.Id)
+		}
 	}
+	return ids
+}
 
-	// Split and recurse (binary search)
-	mid := len(patterns) / 2
-	left := patterns[:mid]
-	right := patterns[mid:]
+// selectPatterns returns copies of the patterns with the given indices, with
+// SOM_LEFTMOST set on those whose IDs are in som.
+func selectPatterns(patterns []*hyperscan.Pattern, idx []int, som []int) []*hyperscan.Pattern {
+	withSOM := make(map[int]bool, len(som))
+	for _, id := range som {
+		withSOM[id] = true
+	}
+	selected := make([]*hyperscan.Pattern, len(idx))
+	for i, j := range idx {
+		p := *patterns[j]
+		if withSOM[p.Id] {
+			p.Flags |= hyperscan.SomLeftMost
+		}
+		selected[i] = &p
+	}
+	return selected
+}
 
-	incompatible := findIncompatiblePatterns(left)
-	incompatible = append(incompatible, findIncompatiblePatterns(right)...)
+// compileShards compiles one database per shard of plan.
+func compileShards(patterns []*hyperscan.Pattern, plan shardPlan) ([]hyperscan.BlockDatabase, error) {
+	dbs := make([]hyperscan.BlockDatabase, 0, len(plan.Shards))
+	for i, shard := range plan.Shards {
+		db, err := hyperscan.NewBlockDatabase(selectPatterns(patterns, shard, plan.SOM)...)
+		if err != nil {
+			closeDatabases(dbs)
+			return nil, fmt.Errorf("compile database %d/%d: %w", i+1, len(plan.Shards), err)
+		}
+		dbs = append(dbs, db)
+	}
+	return dbs, nil
+}
 
-	return incompatible
+// closeDatabases releases dbs.
+func closeDatabases(dbs []hyperscan.BlockDatabase) {
+	for _, db := range dbs {
+		db.Close()
+	}
 }
 
 // preprocessPatternForHyperscan modifies a pattern for Hyperscan compatibility.
@@ -401,8 +482,7 @@ func (m *VectorscanMatcher) MatchWithBlobID(content []byte, blobID types.BlobID)
 // MatchWithBlobIDAndOptions scans content with options.
 func (m *VectorscanMatcher) MatchWithBlobIDAndOptions(content []byte, blobID types.BlobID, opts Options) (*MatchResult, error) {
 	// Check if chunking is needed for large files
-	chunkConfig := DefaultChunkConfig()
-	chunks := ChunkContent(content, chunkConfig)
+	chunks := ChunkContent(content, m.chunking)
 
 	// Single chunk: direct matching
 	if len(chunks) == 1 {
@@ -416,15 +496,17 @@ func (m *VectorscanMatcher) MatchWithBlobIDAndOptions(content []byte, blobID typ
 // matchChunk performs matching on a single chunk of content.
 //
 // Hyperscan is used as a prefilter: its callback fires for every end position of a
-// match (since we do not use HS_FLAG_SOM_LEFTMOST, which would make 183/196 rules
-// incompatible). Rather than using the inaccurate (from=0, to=end) locations,
-// we collect only the *set* of matched rule IDs from Hyperscan and then use
-// regexp2 (already compiled in m.regexCache) to find the precise match locations.
+// match. Most patterns can't be compiled with HS_FLAG_SOM_LEFTMOST, so their
+// locations are inaccurate (from=0, to=end); for those we collect only the *set* of
+// matched rule IDs from Hyperscan and then use regexp2 (already compiled in
+// m.regexCache) to find the precise match locations across the whole chunk.
+// For patterns compiled with SOM_LEFTMOST, regexp2 searches only the spans
+// Hyperscan reported.
 func (m *VectorscanMatcher) matchChunk(content []byte, blobID types.BlobID, opts Options) (*MatchResult, error) {
 	var scratch *hyperscan.Scratch
 
 	// Only get scratch from pool if we have a Hyperscan database
-	if m.db != nil {
+	if len(m.dbs) > 0 {
 		scratchI := m.scratchPool.Get()
 		if scratchI == nil {
 			return nil, fmt.Errorf("failed to get scratch space from pool")
@@ -433,22 +515,27 @@ func (m *VectorscanMatcher) matchChunk(content []byte, blobID types.BlobID, opts
 		defer m.scratchPool.Put(scratch)
 	}
 
-	// Use Hyperscan only as a prefilter: collect the set of rule IDs that had any match.
-	// We deliberately ignore from/to because without SOM_LEFTMOST, from=0 for all matches
-	// and Hyperscan fires a callback for every valid end position (not just one per match).
+	// Use Hyperscan as a prefilter: collect the set of rule IDs that had any match.
+	// We ignore from/to for patterns without SOM_LEFTMOST, because from=0 for all their
+	// matches and Hyperscan fires a callback for every valid end position (not just one
+	// per match).
 	matchedRuleIDs := make(map[uint]bool)
+	somSpans := make(map[uint][]byteSpan)
 	var mu sync.Mutex
 
 	handler := hyperscan.MatchHandler(func(id uint, from, to uint64, flags uint, context interface{}) error {
 		mu.Lock()
 		matchedRuleIDs[id] = true
+		if m.somPatterns[id] {
+			somSpans[id] = append(somSpans[id], byteSpan{from: int(from), to: int(to)})
+		}
 		mu.Unlock()
 		return nil
 	})
 
-	// Perform Hyperscan scan (only if we have Hyperscan-compiled patterns)
-	if m.db != nil {
-		if err := m.db.Scan(content, scratch, handler, nil); err != nil {
+	// Perform Hyperscan scan with each database (if we have Hyperscan-compiled patterns)
+	for _, db := range m.dbs {
+		if err := db.Scan(content, scratch, handler, nil); err != nil {
 			return nil, fmt.Errorf("hyperscan scan: %w", err)
 		}
 	}
@@ -457,7 +544,7 @@ func (m *VectorscanMatcher) matchChunk(content []byte, blobID types.BlobID, opts
 	matches := make([]*types.Match, 0)
 	ruleStats := make(map[string]RuleStat)
 	dedup := NewDeduplicator()
-	contentRunes := []rune(string(content))
+	text := newRuneText(content)
 
 	// For each Hyperscan-identified rule, use regexp2 to find precise match locations.
 	for ruleIdx := range matchedRuleIDs {
@@ -480,8 +567,30 @@ func (m *VectorscanMatcher) matchChunk(content []byte, blobID types.BlobID, opts
 			Error:    nil,
 		}
 
+		// Confirm SOM_LEFTMOST matches with regexp2 within the reported spans only
+		if spans, ok := somSpans[ruleIdx]; ok {
+			err := findInSpans(re, text, mergeSpans(spans), func(match *regexp2.Match) {
+				newMatch := buildMatchResult(blobID, rule, match, m.groupNameCache[rule.Pattern], text, m.context.forRule(rule))
+				if !dedup.IsDuplicate(newMatch) {
+					dedup.Add(newMatch)
+					matches = append(matches, newMatch)
+					stat.Matches++
+				}
+			})
+			if err != nil && m.warnf != nil {
+				if strings.Contains(err.Error(), "match timeout") {
+					m.warnf("[warn] rule %s regex timeout on content (skipping rule for this blob)\n", rule.ID)
+				} else {
+					m.warnf("[warn] rule %s regex error (skipping rule for this blob): %v\n", rule.ID, err)
+				}
+			}
+			stat.Duration = time.Since(startTime)
+			ruleStats[rule.ID] = stat
+			continue
+		}
+
 		// Find all precise matches using regexp2
-		match, err := re.FindRunesMatch(contentRunes)
+		match, err := re.FindRunesMatch(text.runes)
 		if err != nil {
 			if m.warnf != nil {
 				if strings.Contains(err.Error(), "match timeout") {
@@ -505,7 +614,7 @@ func (m *VectorscanMatcher) matchChunk(content []byte, blobID types.BlobID, opts
 			}
 
 			// Bounds check
-			if start < 0 || end > len(content) || start > end {
+			if start < 0 || end > len(text.runes) || start > end {
 				match, err = re.FindNextMatch(match)
 				if err != nil {
 					if m.warnf != nil {
@@ -522,7 +631,7 @@ func (m *VectorscanMatcher) matchChunk(content []byte, blobID types.BlobID, opts
 
 			lastEnd = end
 
-			newMatch := m.buildMatchFromRegexp2(content, blobID, rule, match)
+			newMatch := buildMatchResult(blobID, rule, match, m.groupNameCache[rule.Pattern], text, m.context.forRule(rule))
 
 			// Deduplicate
 			if !dedup.IsDuplicate(newMatch) {
@@ -550,7 +659,7 @@ func (m *VectorscanMatcher) matchChunk(content []byte, blobID types.BlobID, opts
 
 	// Match fallback rules using regexp2
 	if len(m.fallbackRules) > 0 {
-		fallbackMatches := m.matchFallbackRules(content, blobID)
+		fallbackMatches := m.matchFallbackRules(text, blobID)
 		for _, match := range fallbackMatches {
 			if !dedup.IsDuplicate(match) {
 				dedup.Add(match)
@@ -637,92 +746,14 @@ func (m *VectorscanMatcher) matchChunked(content []byte, chunks []Chunk, blobID
 	}, nil
 }
 
-// buildMatchFromRegexp2 constructs a types.Match from a regexp2 match result.
-// It extracts positional and named capture groups, context lines, and computes
-// the structural and finding IDs. This is the shared match-building logic used
-// by both matchChunk and matchFallbackRules.
-func (m *VectorscanMatcher) buildMatchFromRegexp2(
-	content []byte,
-	blobID types.BlobID,
-	rule *types.Rule,
-	re2match *regexp2.Match,
-) *types.Match {
-	// regexp2's Match.Index and Match.Length are rune-based, not byte-based.
-	// Convert to byte offsets for correct slicing.
-	matchedText := []byte(re2match.String())
-	start, end := runeSpanToByteSpan(content, re2match.Index, re2match.Length)
-
-	// Extract positional and named capture groups
-	groups, namedGroups := m.extractGroupsFromMatch(re2match, rule)
-
-	// Extract context
-	var before, after []byte
-	if m.contextLines > 0 {
-		before, after = ExtractContext(content, start, end, m.contextLines)
-	}
-
-	result := &types.Match{
-		BlobID:   blobID,
-		RuleID:   rule.ID,
-		RuleName: rule.Name,
-		Location: types.Location{
-			Offset: types.OffsetSpan{
-				Start: int64(start),
-				End:   int64(end),
-			},
-		},
-		Groups:      groups,
-		NamedGroups: namedGroups,
-		Snippet: types.Snippet{
-			Before:   before,
-			Matching: matchedText,
-			After:    after,
-		},
-	}
-	result.StructuralID = result.ComputeStructuralID(rule.StructuralID)
-	result.FindingID = types.ComputeFindingID(rule.StructuralID, groups)
-
-	return result
-}
-
-// extractGroupsFromMatch extracts positional and named capture groups from a regexp2 match.
-func (m *VectorscanMatcher) extractGroupsFromMatch(match *regexp2.Match, rule *types.Rule) ([][]byte, map[string][]byte) {
-	// Extract positional capture groups
-	var groups [][]byte
-	matchGroups := match.Groups()
-	for i := 1; i < len(matchGroups); i++ {
-		group := matchGroups[i]
-		if len(group.Captures) > 0 {
-			capture := group.Captures[0]
-			groups = append(groups, []byte(capture.String()))
-		}
-	}
-
-	// Extract named capture groups
-	namedGroups := make(map[string][]byte)
-	groupNames := m.groupNameCache[rule.Pattern]
-	for _, name := range groupNames {
-		if name == "" || (len(name) > 0 && name[0] >= '0' && name[0] <= '9') {
-			continue
-		}
-		group := match.GroupByName(name)
-		if group != nil && len(group.Captures) > 0 {
-			namedGroups[name] = []byte(group.Captures[0].String())
-		}
-	}
-
-	return groups, namedGroups
-}
-
 // matchFallbackRules uses regexp2 to match patterns that are incompatible with Hyperscan.
 // It applies prefiltering to only check patterns whose keywords are found in content.
-func (m *VectorscanMatcher) matchFallbackRules(content []byte, blobID types.BlobID) []*types.Match {
+func (m *VectorscanMatcher) matchFallbackRules(text *runeText, blobID types.BlobID) []*types.Match {
 	var matches []*types.Match
-	contentRunes := []rune(string(content))
 
 	// Use prefilter to determine which fallback rules might match
 	// This dramatically reduces the number of regex executions
-	candidateRules := m.prefilter.Filter(content)
+	candidateRules := m.prefilter.Filter(text.content)
 
 	// Build a set of candidate rule IDs for O(1) lookup
 	candidateSet := make(map[string]bool, len(candidateRules))
@@ -742,7 +773,7 @@ func (m *VectorscanMatcher) matchFallbackRules(content []byte, blobID types.Blob
 		}
 
 		// Find all matches for this rule
-		match, err := re.FindRunesMatch(contentRunes)
+		match, err := re.FindRunesMatch(text.runes)
 		if err != nil {
 			if m.warnf != nil {
 				if strings.Contains(err.Error(), "match timeout") {
@@ -764,7 +795,7 @@ func (m *VectorscanMatcher) matchFallbackRules(content []byte, blobID types.Blob
 			}
 
 			// Bounds check
-			if start < 0 || end > len(content) || start > end {
+			if start < 0 || end > len(text.runes) || start > end {
 				match, err = re.FindNextMatch(match)
 				if err != nil {
 					if m.warnf != nil {
@@ -781,7 +812,7 @@ func (m *VectorscanMatcher) matchFallbackRules(content []byte, blobID types.Blob
 
 			lastEnd = end
 
-			newMatch := m.buildMatchFromRegexp2(content, blobID, rule, match)
+			newMatch := buildMatchResult(blobID, rule, match, m.groupNameCache[rule.Pattern], text, m.context.forRule(rule))
 			matches = append(matches, newMatch)
 
 			match, err = re.FindNextMatch(match)
@@ -813,9 +844,9 @@ func (m *VectorscanMatcher) Close() error {
 		}
 	}
 
-	// Close database
-	if m.db != nil {
-		if err := m.db.Close(); err != nil {
+	// Close databases
+	for _, db := range m.dbs {
+		if err := db.Close(); err != nil {
 			return fmt.Errorf("close database: %w", err)
 		}
 	}
@@ -578,3 +578,33 @@ func TestVectorscanMatcher_CombinedFlagsCaseInsensitive(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, matches, 1, "(?xi) pattern should match case-insensitively")
 }
+
+func TestVectorscanMatcher_SOMLeftmost(t *testing.T) {
+	rules := []*types.Rule{
+		{ID: "som-rule", Name: "SOM", Pattern: `\btoken_([a-z0-9]{8})\b`},
+	}
+	for _, r := range rules {
+		r.StructuralID = r.ComputeStructuralID()
+	}
+
+	matcher, err := NewVectorscan(rules, 0, nil)
+	require.NoError(t, err)
+	defer matcher.Close()
+	require.True(t, matcher.somPatterns[0], "a simple pattern should compile with SOM_LEFTMOST")
+
+	// The matches confirmed within Hyperscan's spans are those of a
+	// whole-blob regexp2 search
+	content := []byte("é token_abc12345 xtoken_zzzzzzzz token_deadbeef\ntoken_00000000x token_11111111")
+	matches, err := matcher.Match(content)
+	require.NoError(t, err)
+	reference, err := NewPortableRegexp(rules, 0, nil)
+	require.NoError(t, err)
+	want, err := reference.Match(content)
+	require.NoError(t, err)
+
+	require.Len(t, matches, 3)
+	for i := range want {
+		assert.Equal(t, want[i].Location.Offset, matches[i].Location.Offset)
+		assert.Equal(t, want[i].Groups, matches[i].Groups)
+	}
+}
@@ -0,0 +1,221 @@
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// DurationBuckets are the histogram upper bounds (seconds) for scan durations.
+var DurationBuckets = []float64{0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300}
+
+// Registry accumulates scanner counters for long-lived modes (serve, watch)
+// and renders them in the Prometheus text exposition format.
+// It is safe for concurrent use.
+type Registry struct {
+	mu sync.Mutex
+
+	blobs       uint64
+	bytes       uint64
+	scans       uint64
+	scanErrors  uint64
+	matches     map[string]uint64 // by rule ID
+	validations map[string]uint64 // by validation status
+
+	bucketCounts []uint64
+	durationSum  float64
+	durationN    uint64
+
+	started time.Time
+}
+
+// New creates an empty registry.
+func New() *Registry {
+	return &Registry{
+		matches:      make(map[string]uint64),
+		validations:  make(map[string]uint64),
+		bucketCounts: make([]uint64, len(DurationBuckets)),
+		started:      time.Now(),
+	}
+}
+
+// ObserveBlob records one scanned blob of the given size.
+func (r *Registry) ObserveBlob(size int64) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.blobs++
+	if size > 0 {
+		r.bytes += uint64(size)
+	}
+}
+
+// ObserveMatches records matches by rule and any attached validation results.
+func (r *Registry) ObserveMatches(matches []*types.Match) {
+	if r == nil || len(matches) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range matches {
+		r.matches[m.RuleID]++
+		if m.ValidationResult != nil {
+			r.validations[string(m.ValidationResult.Status)]++
+		}
+	}
+}
+
+// ObserveValidation records a standalone validation outcome.
+func (r *Registry) ObserveValidation(status types.ValidationStatus) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.validations[string(status)]++
+}
+
+// ObserveScan records the duration of one scan operation and whether it failed.
+func (r *Registry) ObserveScan(d time.Duration, failed bool) {
+	if r == nil {
+		return
+	}
+	secs := d.Seconds()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scans++
+	if failed {
+		r.scanErrors++
+	}
+	for i, le := range DurationBuckets {
+		if secs <= le {
+			r.bucketCounts[i]++
+		}
+	}
+	r.durationSum += secs
+	r.durationN++
+}
+
+// WriteTo renders all metrics in the Prometheus text format (version 0.0.4).
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+
+	counter(cw, "titus_blobs_scanned_total", "Blobs scanned.", r.blobs)
+	counter(cw, "titus_bytes_scanned_total", "Bytes of content scanned.", r.bytes)
+	counter(cw, "titus_scans_total", "Scan operations performed.", r.scans)
+	counter(cw, "titus_scan_errors_total", "Scan operations that failed.", r.scanErrors)
+
+	fmt.Fprintf(cw, "# HELP titus_matches_total Matches found, by rule.\n# TYPE titus_matches_total counter\n")
+	for _, id := range sortedKeys(r.matches) {
+		fmt.Fprintf(cw, "titus_matches_total{rule=\"%s\"} %d\n", escapeLabel(id), r.matches[id])
+	}
+
+	fmt.Fprintf(cw, "# HELP titus_validations_total Validation results, by status.\n# TYPE titus_validations_total counter\n")
+	for _, status := range sortedKeys(r.validations) {
+		fmt.Fprintf(cw, "titus_validations_total{status=\"%s\"} %d\n", escapeLabel(status), r.validations[status])
+	}
+
+	fmt.Fprintf(cw, "# HELP titus_scan_duration_seconds Duration of scan operations.\n# TYPE titus_scan_duration_seconds histogram\n")
+	for i, le := range DurationBuckets {
+		fmt.Fprintf(cw, "titus_scan_duration_seconds_bucket{le=\"%s\"} %d\n", formatFloat(le), r.bucketCounts[i])
+	}
+	fmt.Fprintf(cw, "titus_scan_duration_seconds_bucket{le=\"+Inf\"} %d\n", r.durationN)
+	fmt.Fprintf(cw, "titus_scan_duration_seconds_sum %s\n", formatFloat(r.durationSum))
+	fmt.Fprintf(cw, "titus_scan_duration_seconds_count %d\n", r.durationN)
+
+	fmt.Fprintf(cw, "# HELP titus_uptime_seconds Seconds since the process started collecting metrics.\n# TYPE titus_uptime_seconds gauge\n")
+	fmt.Fprintf(cw, "titus_uptime_seconds %s\n", formatFloat(time.Since(r.started).Seconds()))
+
+	if cw.err != nil {
+		return cw.n, cw.err
+	}
+	return cw.n, cw.w.(*bufio.Writer).Flush()
+}
+
+// Handler returns an http.Handler serving the metrics page.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteTo(w)
+	})
+}
+
+// Serve exposes /metrics on addr until ctx is cancelled.
+func Serve(ctx context.Context, addr string, r *Registry) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", r.Handler())
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func counter(w io.Writer, name, help string, v uint64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, help, name, name, v)
+}
+
+func sortedKeys(m map[string]uint64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// escapeLabel escapes a label value per the exposition format.
+func escapeLabel(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return strings.ReplaceAll(s, "\n", `\n`)
+}
+
+// countingWriter tracks bytes written and the first error for WriteTo.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Counters(t *testing.T) {
+	r := New()
+	r.ObserveBlob(100)
+	r.ObserveBlob(50)
+	r.ObserveMatches([]*types.Match{
+		{RuleID: "np.aws.1", ValidationResult: types.NewValidationResult(types.StatusValid, 1, "")},
+		{RuleID: "np.aws.1"},
+		{RuleID: "np.github.1"},
+	})
+	r.ObserveValidation(types.StatusInvalid)
+	r.ObserveScan(20*time.Millisecond, false)
+	r.ObserveScan(2*time.Second, true)
+
+	var buf bytes.Buffer
+	_, err := r.WriteTo(&buf)
+	require.NoError(t, err)
+	out := buf.String()
+
+	assert.Contains(t, out, "titus_blobs_scanned_total 2\n")
+	assert.Contains(t, out, "titus_bytes_scanned_total 150\n")
+	assert.Contains(t, out, "titus_scans_total 2\n")
+	assert.Contains(t, out, "titus_scan_errors_total 1\n")
+	assert.Contains(t, out, `titus_matches_total{rule="np.aws.1"} 2`)
+	assert.Contains(t, out, `titus_matches_total{rule="np.github.1"} 1`)
+	assert.Contains(t, out, `titus_validations_total{status="valid"} 1`)
+	assert.Contains(t, out, `titus_validations_total{status="invalid"} 1`)
+	assert.Contains(t, out, `titus_scan_duration_seconds_bucket{le="0.05"} 1`)
+	assert.Contains(t, out, `titus_scan_duration_seconds_bucket{le="5"} 2`)
+	assert.Contains(t, out, `titus_scan_duration_seconds_bucket{le="+Inf"} 2`)
+	assert.Contains(t, out, "titus_scan_duration_seconds_count 2\n")
+	assert.Contains(t, out, "# TYPE titus_scan_duration_seconds histogram")
+}
+
+func TestRegistry_NilIsNoop(t *testing.T) {
+	var r *Registry
+	r.ObserveBlob(1)
+	r.ObserveMatches([]*types.Match{{RuleID: "x"}})
+	r.ObserveValidation(types.StatusValid)
+	r.ObserveScan(time.Second, false)
+}
+
+func TestEscapeLabel(t *testing.T) {
+	assert.Equal(t, `a\"b\\c\nd`, escapeLabel("a\"b\\c\nd"))
+}
+
+func TestHandler(t *testing.T) {
+	r := New()
+	r.ObserveBlob(10)
+
+	srv := httptest.NewServer(r.Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "version=0.0.4")
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "titus_blobs_scanned_total 1")
+}
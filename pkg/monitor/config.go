@@ -0,0 +1,90 @@
+// Package monitor implements the configuration for scheduled repository
+// monitoring: which repositories to watch and how often to re-scan them.
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultInterval is the time between monitoring cycles when the config does
+// not specify one.
+const DefaultInterval = time.Hour
+
+// Config lists the repositories to monitor.
+//
+//	interval: "@hourly"
+//	repos:
+//	  - https://github.com/org/repo
+//	github_orgs:
+//	  - my-org
+//	gitlab_groups:
+//	  - my-group
+type Config struct {
+	Interval     string   `yaml:"interval"`
+	Repos        []string `yaml:"repos"`
+	GitHubOrgs   []string `yaml:"github_orgs"`
+	GitLabGroups []string `yaml:"gitlab_groups"`
+	SkipForks    bool     `yaml:"skip_forks"`
+}
+
+// LoadConfig reads and validates a monitor config file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading monitor config: %w", err)
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig parses and validates monitor config YAML.
+func ParseConfig(data []byte) (*Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing monitor config: %w", err)
+	}
+	if len(cfg.Repos) == 0 && len(cfg.GitHubOrgs) == 0 && len(cfg.GitLabGroups) == 0 {
+		return nil, fmt.Errorf("monitor config lists no repos, github_orgs or gitlab_groups")
+	}
+	if _, err := cfg.Schedule(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Schedule returns the configured interval between cycles.
+func (c *Config) Schedule() (time.Duration, error) {
+	if c.Interval == "" {
+		return DefaultInterval, nil
+	}
+	return ParseSchedule(c.Interval)
+}
+
+// ParseSchedule parses a cron-style shorthand (@hourly, @daily, @weekly,
+// @every <duration>) or a plain Go duration such as "30m".
+func ParseSchedule(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	var d time.Duration
+	switch s {
+	case "@hourly":
+		d = time.Hour
+	case "@daily", "@midnight":
+		d = 24 * time.Hour
+	case "@weekly":
+		d = 7 * 24 * time.Hour
+	default:
+		var err error
+		d, err = time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(s, "@every")))
+		if err != nil {
+			return 0, fmt.Errorf("invalid schedule %q: use @hourly, @daily, @weekly, @every <duration> or a duration like 30m", s)
+		}
+	}
+	if d < time.Minute {
+		return 0, fmt.Errorf("invalid schedule %q: interval must be at least 1m", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,67 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"@hourly", time.Hour},
+		{"@daily", 24 * time.Hour},
+		{"@weekly", 7 * 24 * time.Hour},
+		{"@every 15m", 15 * time.Minute},
+		{"90m", 90 * time.Minute},
+	}
+	for _, tt := range tests {
+		got, err := ParseSchedule(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	for _, bad := range []string{"", "0 * * * *", "@yearly-ish", "10s"} {
+		_, err := ParseSchedule(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "monitor.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+interval: "@every 30m"
+repos:
+  - https://github.com/org/repo
+github_orgs:
+  - my-org
+`), 0644))
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://github.com/org/repo"}, cfg.Repos)
+	assert.Equal(t, []string{"my-org"}, cfg.GitHubOrgs)
+
+	d, err := cfg.Schedule()
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Minute, d)
+}
+
+func TestParseConfig_Invalid(t *testing.T) {
+	_, err := ParseConfig([]byte("interval: \"@hourly\"\n"))
+	assert.ErrorContains(t, err, "no repos")
+
+	_, err = ParseConfig([]byte("interval: sometimes\nrepos: [x]\n"))
+	assert.ErrorContains(t, err, "invalid schedule")
+
+	cfg, err := ParseConfig([]byte("repos: [x]\n"))
+	require.NoError(t, err)
+	d, _ := cfg.Schedule()
+	assert.Equal(t, DefaultInterval, d)
+}
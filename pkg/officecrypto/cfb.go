@@ -0,0 +1,199 @@
+package officecrypto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"unicode/utf16"
+)
+
+// cfbMagic starts a Compound File Binary (OLE2) file, the container of
+// encrypted Office Open XML documents.
+var cfbMagic = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// cfbEndOfChain ends a chain of sectors.
+const cfbEndOfChain = 0xFFFFFFFE
+
+var errCorrupt = errors.New("corrupt compound file")
+
+// compoundFile reads the streams of a Compound File Binary file. Only what
+// encrypted documents need is supported: streams are found by name,
+// wherever they are in the storage tree.
+type compoundFile struct {
+	data           []byte
+	sectorSize     int
+	miniSectorSize int
+	miniCutoff     uint64
+	fat            []uint32
+	miniFAT        []uint32
+	entries        []cfbEntry
+	miniStream     []byte
+}
+
+// cfbEntry is a directory entry.
+type cfbEntry struct {
+	name        string
+	kind        byte // 1 storage, 2 stream, 5 root
+	startSector uint32
+	size        uint64
+}
+
+// openCompoundFile reads the header, FAT and directory of a compound file.
+func openCompoundFile(data []byte) (*compoundFile, error) {
+	if len(data) < 512 || !bytes.HasPrefix(data, cfbMagic) {
+		return nil, errCorrupt
+	}
+	le := binary.LittleEndian
+	sectorShift, miniShift := le.Uint16(data[30:]), le.Uint16(data[32:])
+	if sectorShift != 9 && sectorShift != 12 || miniShift != 6 {
+		return nil, errCorrupt
+	}
+	cf := &compoundFile{
+		data:           data,
+		sectorSize:     1 << sectorShift,
+		miniSectorSize: 1 << miniShift,
+		miniCutoff:     uint64(le.Uint32(data[56:])),
+	}
+
+	// The FAT's sectors are listed in the header and then in a chain of
+	// DIFAT sectors
+	var fatSectors []uint32
+	for i := range 109 {
+		fatSectors = append(fatSectors, le.Uint32(data[76+4*i:]))
+	}
+	perSector := cf.sectorSize / 4
+	next := le.Uint32(data[68:])
+	for n := le.Uint32(data[72:]); n > 0 && next < cfbEndOfChain && len(fatSectors) < len(data)/4; n-- {
+		sector, err := cf.sector(next)
+		if err != nil {
+			return nil, err
+		}
+		for i := range perSector - 1 {
+			fatSectors = append(fatSectors, le.Uint32(sector[4*i:]))
+		}
+		next = le.Uint32(sector[4*(perSector-1):])
+	}
+	numFAT := int(le.Uint32(data[44:]))
+	if numFAT > len(fatSectors) {
+		return nil, errCorrupt
+	}
+	for _, s := range fatSectors[:numFAT] {
+		sector, err := cf.sector(s)
+		if err != nil {
+			return nil, err
+		}
+		for i := range perSector {
+			cf.fat = append(cf.fat, le.Uint32(sector[4*i:]))
+		}
+	}
+
+	dir, err := cf.chain(le.Uint32(data[48:]), -1)
+	if err != nil {
+		return nil, err
+	}
+	for off := 0; off+128 <= len(dir); off += 128 {
+		e := dir[off : off+128]
+		nameLen := int(le.Uint16(e[64:]))
+		if nameLen < 2 || nameLen > 64 {
+			continue
+		}
+		units := make([]uint16, nameLen/2-1)
+		for i := range units {
+			units[i] = le.Uint16(e[2*i:])
+		}
+		size := le.Uint64(e[120:])
+		if cf.sectorSize == 512 {
+			size &= 0xFFFFFFFF // the high half is undefined in version 3 files
+		}
+		cf.entries = append(cf.entries, cfbEntry{
+			name:        string(utf16.Decode(units)),
+			kind:        e[66],
+			startSector: le.Uint32(e[116:]),
+			size:        size,
+		})
+	}
+	if len(cf.entries) == 0 || cf.entries[0].kind != 5 {
+		return nil, errCorrupt
+	}
+
+	// Streams under the cutoff size are stored in mini sectors of the mini
+	// stream, the root entry's stream
+	root := cf.entries[0]
+	if cf.miniStream, err = cf.chain(root.startSector, int64(root.size)); err != nil {
+		return nil, err
+	}
+	miniFAT, err := cf.chain(le.Uint32(data[60:]), -1)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i+4 <= len(miniFAT); i += 4 {
+		cf.miniFAT = append(cf.miniFAT, le.Uint32(miniFAT[i:]))
+	}
+	return cf, nil
+}
+
+// sector returns the content of sector n.
+func (cf *compoundFile) sector(n uint32) ([]byte, error) {
+	off := (int64(n) + 1) * int64(cf.sectorSize)
+	if n >= cfbEndOfChain || off+int64(cf.sectorSize) > int64(len(cf.data)) {
+		return nil, errCorrupt
+	}
+	return cf.data[off : off+int64(cf.sectorSize)], nil
+}
+
+// chain returns the sectors of the FAT chain starting at start, cut to size
+// bytes if size isn't negative.
+func (cf *compoundFile) chain(start uint32, size int64) ([]byte, error) {
+	var out []byte
+	for n, steps := start, 0; n != cfbEndOfChain; n, steps = cf.fat[n], steps+1 {
+		if int(n) >= len(cf.fat) || steps > len(cf.fat) {
+			return nil, errCorrupt
+		}
+		sector, err := cf.sector(n)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sector...)
+	}
+	return truncate(out, size)
+}
+
+// miniChain returns the mini sectors of the mini FAT chain starting at
+// start, cut to size bytes.
+func (cf *compoundFile) miniChain(start uint32, size int64) ([]byte, error) {
+	var out []byte
+	for n, steps := start, 0; n != cfbEndOfChain; n, steps = cf.miniFAT[n], steps+1 {
+		off := int64(n) * int64(cf.miniSectorSize)
+		if int(n) >= len(cf.miniFAT) || steps > len(cf.miniFAT) || off+int64(cf.miniSectorSize) > int64(len(cf.miniStream)) {
+			return nil, errCorrupt
+		}
+		out = append(out, cf.miniStream[off:off+int64(cf.miniSectorSize)]...)
+	}
+	return truncate(out, size)
+}
+
+// truncate cuts b to size bytes, unless size is negative.
+func truncate(b []byte, size int64) ([]byte, error) {
+	if size < 0 {
+		return b, nil
+	}
+	if size > int64(len(b)) {
+		return nil, errCorrupt
+	}
+	return b[:size], nil
+}
+
+// stream returns the content of the stream named name, or nil if there's
+// none.
+func (cf *compoundFile) stream(name string) ([]byte, error) {
+	for _, e := range cf.entries[1:] {
+		if e.kind != 2 || e.name != name {
+			continue
+		}
+		if e.size < cf.miniCutoff {
+			return cf.miniChain(e.startSector, int64(e.size))
+		}
+		return cf.chain(e.startSector, int64(e.size))
+	}
+	return nil, nil
+}
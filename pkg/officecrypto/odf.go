@@ -0,0 +1,280 @@
+package officecrypto
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/blowfish"
+)
+
+// odfManifest is the part of META-INF/manifest.xml that describes how each
+// file of an OpenDocument package is encrypted.
+type odfManifest struct {
+	Entries []struct {
+		FullPath   string         `xml:"full-path,attr"`
+		Encryption *odfEncryption `xml:"encryption-data"`
+	} `xml:"file-entry"`
+}
+
+// odfEncryption is how a file of an OpenDocument package is encrypted.
+type odfEncryption struct {
+	ChecksumType string `xml:"checksum-type,attr"`
+	Checksum     string `xml:"checksum,attr"`
+	Algorithm    struct {
+		Name string `xml:"algorithm-name,attr"`
+		IV   string `xml:"initialisation-vector,attr"`
+	} `xml:"algorithm"`
+	StartKey struct {
+		Name string `xml:"start-key-generation-name,attr"`
+	} `xml:"start-key-generation"`
+	KeyDerivation struct {
+		Name       string `xml:"key-derivation-name,attr"`
+		KeySize    int    `xml:"key-size,attr"`
+		Iterations int    `xml:"iteration-count,attr"`
+		Salt       string `xml:"salt,attr"`
+	} `xml:"key-derivation"`
+}
+
+// Algorithm names of ODF encryption
+const (
+	odfAES256CBC   = "http://www.w3.org/2001/04/xmlenc#aes256-cbc"
+	odfBlowfishCFB = "Blowfish CFB"
+	odfSHA256      = "http://www.w3.org/2000/09/xmldsig#sha256"
+	odfSHA256Alt   = "http://www.w3.org/2001/04/xmlenc#sha256"
+)
+
+// maxODFIterations bounds the PBKDF2 iterations of the key derivation,
+// which LibreOffice sets to 100000 at most.
+const maxODFIterations = 10_000_000
+
+// odfManifestEntries returns the manifest of an OpenDocument package, or
+// nil if content isn't one.
+func odfManifestEntries(zr *zip.Reader) *odfManifest {
+	for _, f := range zr.File {
+		if f.Name != "META-INF/manifest.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil
+		}
+		defer rc.Close()
+		var m odfManifest
+		if err := xml.NewDecoder(io.LimitReader(rc, 1<<20)).Decode(&m); err != nil {
+			return nil
+		}
+		return &m
+	}
+	return nil
+}
+
+// isEncryptedODF reports whether content is an OpenDocument package with
+// encrypted files.
+func isEncryptedODF(content []byte) bool {
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return false
+	}
+	m := odfManifestEntries(zr)
+	if m == nil {
+		return false
+	}
+	for _, e := range m.Entries {
+		if e.Encryption != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// decryptODF decrypts the encrypted files of an OpenDocument package and
+// returns the package with them in the clear, less the manifest describing
+// their encryption. The files share a password, which is found with the
+// first encrypted file.
+func decryptODF(content []byte, passwords []string) ([]byte, string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, "", err
+	}
+	m := odfManifestEntries(zr)
+	if m == nil {
+		return nil, "", errCorrupt
+	}
+	encrypted := make(map[string]*odfEncryption)
+	for _, e := range m.Entries {
+		if e.Encryption != nil {
+			encrypted[e.FullPath] = e.Encryption
+		}
+	}
+
+	var out bytes.Buffer
+	zw := zip.NewWriter(&out)
+	password, found := "", false
+	for _, f := range zr.File {
+		if f.Name == "META-INF/manifest.xml" {
+			continue
+		}
+		data, err := readZipFile(f)
+		if err != nil {
+			return nil, "", err
+		}
+		if enc := encrypted[f.Name]; enc != nil {
+			if !found {
+				for _, p := range passwords {
+					plain, err := decryptODFFile(enc, p, data)
+					if err == ErrPassword {
+						continue
+					}
+					if err != nil {
+						return nil, "", err
+					}
+					data, password, found = plain, p, true
+					break
+				}
+				if !found {
+					return nil, "", ErrPassword
+				}
+			} else if data, err = decryptODFFile(enc, password, data); err != nil {
+				return nil, "", err
+			}
+		}
+		w, err := zw.Create(f.Name)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, "", err
+	}
+	return out.Bytes(), password, nil
+}
+
+// readZipFile returns the content of f, up to 256 MiB.
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(io.LimitReader(rc, 256<<20))
+}
+
+// decryptODFFile decrypts and inflates a file of an OpenDocument package.
+// It returns ErrPassword if the checksum of the decrypted data doesn't
+// match, or it doesn't inflate.
+func decryptODFFile(enc *odfEncryption, password string, data []byte) ([]byte, error) {
+	salt, err := base64.StdEncoding.DecodeString(enc.KeyDerivation.Salt)
+	if err != nil {
+		return nil, errCorrupt
+	}
+	iv, err := base64.StdEncoding.DecodeString(enc.Algorithm.IV)
+	if err != nil {
+		return nil, errCorrupt
+	}
+	kd := enc.KeyDerivation
+	if kd.Name != "PBKDF2" {
+		return nil, fmt.Errorf("%w: key derivation %s", ErrUnsupported, kd.Name)
+	}
+	if kd.Iterations <= 0 || kd.Iterations > maxODFIterations {
+		return nil, errCorrupt
+	}
+
+	var startKey []byte
+	switch enc.StartKey.Name {
+	case odfSHA256, odfSHA256Alt:
+		sum := sha256.Sum256([]byte(password))
+		startKey = sum[:]
+	case "", "SHA1":
+		sum := sha1.Sum([]byte(password))
+		startKey = sum[:]
+	default:
+		return nil, fmt.Errorf("%w: start key %s", ErrUnsupported, enc.StartKey.Name)
+	}
+
+	var plain []byte
+	switch enc.Algorithm.Name {
+	case odfAES256CBC:
+		keySize := kd.KeySize
+		if keySize == 0 {
+			keySize = 32
+		}
+		key, err := pbkdf2.Key(sha1.New, string(startKey), salt, kd.Iterations, keySize)
+		if err != nil {
+			return nil, err
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil || len(iv) != aes.BlockSize || len(data)%aes.BlockSize != 0 {
+			return nil, errCorrupt
+		}
+		plain = make([]byte, len(data))
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+		// W3C padding: the last byte is the padding's length
+		pad := 0
+		if len(plain) > 0 {
+			pad = int(plain[len(plain)-1])
+		}
+		if pad == 0 || pad > aes.BlockSize || pad > len(plain) {
+			return nil, ErrPassword
+		}
+		plain = plain[:len(plain)-pad]
+	case odfBlowfishCFB:
+		keySize := kd.KeySize
+		if keySize == 0 {
+			keySize = 16
+		}
+		key, err := pbkdf2.Key(sha1.New, string(startKey), salt, kd.Iterations, keySize)
+		if err != nil {
+			return nil, err
+		}
+		block, err := blowfish.NewCipher(key)
+		if err != nil || len(iv) != blowfish.BlockSize {
+			return nil, errCorrupt
+		}
+		plain = make([]byte, len(data))
+		cipher.NewCFBDecrypter(block, iv).XORKeyStream(plain, data)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, enc.Algorithm.Name)
+	}
+
+	if enc.Checksum != "" {
+		var h hash.Hash
+		switch {
+		case strings.HasSuffix(enc.ChecksumType, "sha256-1k"):
+			h = sha256.New()
+		case strings.HasSuffix(enc.ChecksumType, "SHA1/1K"), enc.ChecksumType == "":
+			h = sha1.New()
+		default:
+			return nil, fmt.Errorf("%w: checksum %s", ErrUnsupported, enc.ChecksumType)
+		}
+		want, err := base64.StdEncoding.DecodeString(enc.Checksum)
+		if err != nil {
+			return nil, errCorrupt
+		}
+		h.Write(plain[:min(len(plain), 1024)])
+		if subtle.ConstantTimeCompare(h.Sum(nil), want) != 1 {
+			return nil, ErrPassword
+		}
+	}
+
+	inflated, err := io.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(plain)), 256<<20))
+	if err != nil {
+		return nil, ErrPassword
+	}
+	return inflated, nil
+}
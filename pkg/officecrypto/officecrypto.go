@@ -0,0 +1,56 @@
+// Package officecrypto detects password-protected Office Open XML (docx,
+// xlsx, pptx) and OpenDocument (odt, ods, odp) documents, and decrypts them
+// with a list of candidate passwords so that their text can be scanned.
+//
+// Office documents use agile encryption (Office 2010 and later, AES with a
+// SHA-1 or SHA-2 key derivation). OpenDocument files use AES-256-CBC
+// (ODF 1.2) or Blowfish CFB (ODF 1.0 and 1.1) with PBKDF2. Other schemes,
+// such as Office 2007's standard encryption, are detected but not
+// decrypted.
+package officecrypto
+
+import (
+	"bytes"
+	"errors"
+)
+
+var (
+	// ErrPassword is returned when none of the passwords opens a document.
+	ErrPassword = errors.New("no password opened the document")
+
+	// ErrUnsupported is returned for encryption schemes that can't be
+	// decrypted.
+	ErrUnsupported = errors.New("unsupported encryption")
+)
+
+// DefaultPasswords are tried on every document. Excel encrypts workbooks
+// that are only protected from editing with "VelvetSweatshop", so they
+// open without a password.
+var DefaultPasswords = []string{"VelvetSweatshop"}
+
+// IsEncrypted reports whether content is an encrypted Office Open XML or
+// OpenDocument document.
+func IsEncrypted(content []byte) bool {
+	if bytes.HasPrefix(content, cfbMagic) {
+		cf, err := openCompoundFile(content)
+		if err != nil {
+			return false
+		}
+		pkg, err := cf.stream("EncryptedPackage")
+		return err == nil && pkg != nil
+	}
+	return isEncryptedODF(content)
+}
+
+// Decrypt decrypts an encrypted document with DefaultPasswords and then
+// passwords, and returns it as the zip package it would be unencrypted,
+// along with the password that opened it. It returns ErrPassword if none
+// did, and ErrUnsupported if the document's encryption can't be
+// decrypted.
+func Decrypt(content []byte, passwords []string) ([]byte, string, error) {
+	candidates := append(append([]string{}, DefaultPasswords...), passwords...)
+	if bytes.HasPrefix(content, cfbMagic) {
+		return decryptOOXML(content, candidates)
+	}
+	return decryptODF(content, candidates)
+}
@@ -0,0 +1,259 @@
+package officecrypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"hash"
+	"unicode/utf16"
+)
+
+// Block keys of agile encryption, from MS-OFFCRYPTO 2.3.4.11 and 2.3.4.13
+var (
+	blockVerifierInput = []byte{0xfe, 0xa7, 0xd2, 0x76, 0x3b, 0x4b, 0x9e, 0x79}
+	blockVerifierHash  = []byte{0xd7, 0xaa, 0x0f, 0x6d, 0x30, 0x61, 0x34, 0x4e}
+	blockKeyValue      = []byte{0x14, 0x6e, 0x0b, 0xe7, 0xab, 0xac, 0xd0, 0xd6}
+)
+
+// maxSpinCount bounds the hash iterations of the key derivation, which
+// Office sets to 100000.
+const maxSpinCount = 10_000_000
+
+// agileSegmentSize is the size of the separately encrypted segments of an
+// encrypted package.
+const agileSegmentSize = 4096
+
+// agileInfo is the XML descriptor of agile encryption in the
+// EncryptionInfo stream.
+type agileInfo struct {
+	KeyData       agileParams `xml:"keyData"`
+	KeyEncryptors []struct {
+		URI          string            `xml:"uri,attr"`
+		EncryptedKey agileEncryptedKey `xml:"encryptedKey"`
+	} `xml:"keyEncryptors>keyEncryptor"`
+}
+
+type agileParams struct {
+	SaltSize        int    `xml:"saltSize,attr"`
+	BlockSize       int    `xml:"blockSize,attr"`
+	KeyBits         int    `xml:"keyBits,attr"`
+	HashSize        int    `xml:"hashSize,attr"`
+	CipherAlgorithm string `xml:"cipherAlgorithm,attr"`
+	CipherChaining  string `xml:"cipherChaining,attr"`
+	HashAlgorithm   string `xml:"hashAlgorithm,attr"`
+	SaltValue       string `xml:"saltValue,attr"`
+}
+
+type agileEncryptedKey struct {
+	agileParams
+	SpinCount                  int    `xml:"spinCount,attr"`
+	EncryptedVerifierHashInput string `xml:"encryptedVerifierHashInput,attr"`
+	EncryptedVerifierHashValue string `xml:"encryptedVerifierHashValue,attr"`
+	EncryptedKeyValue          string `xml:"encryptedKeyValue,attr"`
+}
+
+// passwordKeyEncryptor is the URI of the key encryptor that derives its key
+// from a password, as opposed to a certificate.
+const passwordKeyEncryptor = "http://schemas.microsoft.com/office/2006/keyEncryptor/password"
+
+// decryptOOXML decrypts the EncryptedPackage stream of a compound file.
+func decryptOOXML(content []byte, passwords []string) ([]byte, string, error) {
+	cf, err := openCompoundFile(content)
+	if err != nil {
+		return nil, "", err
+	}
+	infoStream, err := cf.stream("EncryptionInfo")
+	if err != nil {
+		return nil, "", err
+	}
+	pkg, err := cf.stream("EncryptedPackage")
+	if err != nil {
+		return nil, "", err
+	}
+	if len(infoStream) < 8 || len(pkg) < 8 {
+		return nil, "", errCorrupt
+	}
+	major, minor := binary.LittleEndian.Uint16(infoStream), binary.LittleEndian.Uint16(infoStream[2:])
+	if major != 4 || minor != 4 {
+		return nil, "", fmt.Errorf("%w: version %d.%d", ErrUnsupported, major, minor)
+	}
+
+	var info agileInfo
+	if err := xml.Unmarshal(infoStream[8:], &info); err != nil {
+		return nil, "", fmt.Errorf("reading encryption info: %w", err)
+	}
+	var key *agileEncryptedKey
+	for i := range info.KeyEncryptors {
+		if info.KeyEncryptors[i].URI == passwordKeyEncryptor {
+			key = &info.KeyEncryptors[i].EncryptedKey
+		}
+	}
+	if key == nil {
+		return nil, "", fmt.Errorf("%w: no password key encryptor", ErrUnsupported)
+	}
+	for _, p := range []agileParams{info.KeyData, key.agileParams} {
+		if err := p.check(); err != nil {
+			return nil, "", err
+		}
+	}
+	if key.SpinCount < 0 || key.SpinCount > maxSpinCount {
+		return nil, "", errCorrupt
+	}
+
+	for _, password := range passwords {
+		secret, err := key.secretKey(password)
+		if err != nil {
+			return nil, "", err
+		}
+		if secret != nil {
+			plain, err := info.KeyData.decryptPackage(secret, pkg)
+			return plain, password, err
+		}
+	}
+	return nil, "", ErrPassword
+}
+
+// check reports whether p describes a cipher and hash that can be used.
+func (p agileParams) check() error {
+	if p.CipherAlgorithm != "AES" || p.CipherChaining != "ChainingModeCBC" {
+		return fmt.Errorf("%w: %s %s", ErrUnsupported, p.CipherAlgorithm, p.CipherChaining)
+	}
+	if p.newHash() == nil {
+		return fmt.Errorf("%w: hash %s", ErrUnsupported, p.HashAlgorithm)
+	}
+	switch {
+	case p.KeyBits != 128 && p.KeyBits != 192 && p.KeyBits != 256,
+		p.BlockSize != aes.BlockSize,
+		p.HashSize != p.newHash().Size():
+		return errCorrupt
+	}
+	return nil
+}
+
+func (p agileParams) newHash() hash.Hash {
+	switch p.HashAlgorithm {
+	case "SHA1":
+		return sha1.New()
+	case "SHA256":
+		return sha256.New()
+	case "SHA384":
+		return sha512.New384()
+	case "SHA512":
+		return sha512.New()
+	}
+	return nil
+}
+
+// digest hashes the concatenation of parts.
+func (p agileParams) digest(parts ...[]byte) []byte {
+	h := p.newHash()
+	for _, b := range parts {
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+// fit truncates b to n bytes, or pads it with 0x36 bytes.
+func fit(b []byte, n int) []byte {
+	out := make([]byte, n)
+	for i := range out {
+		out[i] = 0x36
+	}
+	copy(out, b)
+	return out
+}
+
+// secretKey derives the document's secret key from password, or returns
+// nil if password isn't the document's.
+func (k *agileEncryptedKey) secretKey(password string) ([]byte, error) {
+	salt, err := base64.StdEncoding.DecodeString(k.SaltValue)
+	if err != nil {
+		return nil, err
+	}
+	var pw []byte
+	for _, u := range utf16.Encode([]rune(password)) {
+		pw = binary.LittleEndian.AppendUint16(pw, u)
+	}
+
+	h := k.digest(salt, pw)
+	var iter [4]byte
+	for i := range k.SpinCount {
+		binary.LittleEndian.PutUint32(iter[:], uint32(i))
+		h = k.digest(iter[:], h)
+	}
+	iv := fit(salt, k.BlockSize)
+	decrypt := func(blockKey []byte, encoded string) ([]byte, error) {
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, err
+		}
+		return decryptCBC(fit(k.digest(h, blockKey), k.KeyBits/8), iv, data)
+	}
+
+	input, err := decrypt(blockVerifierInput, k.EncryptedVerifierHashInput)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := decrypt(blockVerifierHash, k.EncryptedVerifierHashValue)
+	if err != nil {
+		return nil, err
+	}
+	if len(input) < k.SaltSize || len(verifier) < k.HashSize ||
+		subtle.ConstantTimeCompare(k.digest(input[:k.SaltSize]), verifier[:k.HashSize]) != 1 {
+		return nil, nil
+	}
+	secret, err := decrypt(blockKeyValue, k.EncryptedKeyValue)
+	if err != nil || len(secret) < k.KeyBits/8 {
+		return nil, errCorrupt
+	}
+	return secret[:k.KeyBits/8], nil
+}
+
+// decryptPackage decrypts an EncryptedPackage stream: its size, then
+// segments each encrypted with an IV derived from the segment's index.
+func (p agileParams) decryptPackage(secret, pkg []byte) ([]byte, error) {
+	salt, err := base64.StdEncoding.DecodeString(p.SaltValue)
+	if err != nil {
+		return nil, err
+	}
+	size := binary.LittleEndian.Uint64(pkg)
+	pkg = pkg[8:]
+	if size > uint64(len(pkg)) {
+		return nil, errCorrupt
+	}
+
+	out := make([]byte, 0, len(pkg))
+	var index [4]byte
+	for i := 0; len(pkg) > 0; i++ {
+		segment := pkg[:min(len(pkg), agileSegmentSize)]
+		pkg = pkg[len(segment):]
+		binary.LittleEndian.PutUint32(index[:], uint32(i))
+		plain, err := decryptCBC(secret, fit(p.digest(salt, index[:]), p.BlockSize), segment)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, plain...)
+	}
+	return out[:size], nil
+}
+
+// decryptCBC decrypts data with AES in CBC mode.
+func decryptCBC(key, iv, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)%aes.BlockSize != 0 {
+		return nil, errCorrupt
+	}
+	out := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, data)
+	return out, nil
+}
@@ -0,0 +1,68 @@
+package owner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Author is the author of a line according to git blame.
+type Author struct {
+	Name   string
+	Email  string
+	Commit string
+}
+
+// notCommitted is the hash git blame reports for uncommitted lines.
+const notCommitted = "0000000000000000000000000000000000000000"
+
+// Blame runs git blame on one line of path in repoPath at rev. An empty rev
+// blames the working tree. Returns nil if the line isn't committed yet.
+func Blame(ctx context.Context, repoPath, rev, path string, line int) (*Author, error) {
+	args := []string{"blame", "--porcelain", "-L", strconv.Itoa(line) + ",+1"}
+	if rev != "" {
+		args = append(args, rev)
+	}
+	args = append(args, "--", path)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = repoPath
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git blame: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return parsePorcelain(out), nil
+}
+
+// parsePorcelain reads the author of the first line of git blame --porcelain
+// output.
+func parsePorcelain(out []byte) *Author {
+	var a Author
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case a.Commit == "":
+			a.Commit, _, _ = strings.Cut(line, " ")
+		case strings.HasPrefix(line, "author "):
+			a.Name = strings.TrimPrefix(line, "author ")
+		case strings.HasPrefix(line, "author-mail "):
+			a.Email = strings.Trim(strings.TrimPrefix(line, "author-mail "), "<>")
+		case strings.HasPrefix(line, "\t"):
+			// The line content ends the header
+			if a.Commit == notCommitted {
+				return nil
+			}
+			return &a
+		}
+	}
+	if a.Commit == "" || a.Commit == notCommitted {
+		return nil
+	}
+	return &a
+}
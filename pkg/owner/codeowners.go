@@ -0,0 +1,67 @@
+package owner
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	gitignore "github.com/sabhiram/go-gitignore"
+)
+
+// CodeownersPaths are the locations GitHub and GitLab read a CODEOWNERS file
+// from, in order of precedence.
+var CodeownersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"}
+
+// Codeowners maps repository paths to their owners.
+type Codeowners struct {
+	rules []codeownersRule
+}
+
+type codeownersRule struct {
+	pattern *gitignore.GitIgnore
+	owners  []string
+}
+
+// ParseCodeowners reads a CODEOWNERS file. Each line is a gitignore-style
+// pattern followed by owners (@user, @org/team or an email address);
+// comments, blank lines and GitLab [Section] headers are skipped.
+func ParseCodeowners(r io.Reader) (*Codeowners, error) {
+	c := &Codeowners{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
+			continue
+		}
+		fields := strings.Fields(line)
+		rule := codeownersRule{pattern: gitignore.CompileIgnoreLines(fields[0])}
+		if len(fields) > 1 {
+			rule.owners = fields[1:]
+		}
+		c.rules = append(c.rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Owners returns the owners of a slash-separated path relative to the
+// repository root. As in GitHub, the last matching pattern wins, and a
+// matching pattern without owners leaves the path unowned.
+func (c *Codeowners) Owners(path string) []string {
+	if c == nil {
+		return nil
+	}
+	path = strings.TrimPrefix(path, "/")
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.MatchesPath(path) {
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
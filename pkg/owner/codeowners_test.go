@@ -0,0 +1,42 @@
+package owner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeowners_Owners(t *testing.T) {
+	c, err := ParseCodeowners(strings.NewReader(`
+# Default owners
+*                 @org/platform
+
+*.js              @org/frontend   # inline comment
+/deploy/          @org/sre ops@example.com
+docs/**           @docs-writer
+
+[Security]
+/config/secrets.yml @org/security
+/vendor/
+`))
+	require.NoError(t, err)
+
+	tests := map[string][]string{
+		"main.go":                {"@org/platform"},
+		"web/app.js":             {"@org/frontend"},
+		"deploy/prod/values.yml": {"@org/sre", "ops@example.com"},
+		"docs/guide/setup.md":    {"@docs-writer"},
+		"/config/secrets.yml":    {"@org/security"},
+		"vendor/lib/lib.go":      nil, // owners explicitly removed
+	}
+	for path, want := range tests {
+		assert.Equal(t, want, c.Owners(path), path)
+	}
+}
+
+func TestCodeowners_Nil(t *testing.T) {
+	var c *Codeowners
+	assert.Nil(t, c.Owners("main.go"))
+}
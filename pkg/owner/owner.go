@@ -0,0 +1,75 @@
+// Package owner attributes findings to the people responsible for them, using
+// git blame and CODEOWNERS, so remediation can be routed automatically.
+package owner
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// Attributor resolves the owners of lines in local git repositories. Each
+// repository's CODEOWNERS file is read once, from HEAD (or the working tree
+// when blaming it), since routing should follow current ownership.
+type Attributor struct {
+	mu         sync.Mutex
+	codeowners map[string]*Codeowners // keyed by repository path and worktree flag
+}
+
+// NewAttributor creates an Attributor.
+func NewAttributor() *Attributor {
+	return &Attributor{codeowners: make(map[string]*Codeowners)}
+}
+
+// Attribute returns the owner of a line of path, relative to repoPath, at rev
+// (the working tree if empty). Attribution is best-effort: it returns nil when
+// neither git blame nor CODEOWNERS know who owns the line.
+func (a *Attributor) Attribute(ctx context.Context, repoPath, rev, path string, line int) *types.Owner {
+	o := &types.Owner{Codeowners: a.loadCodeowners(ctx, repoPath, rev == "").Owners(path)}
+	if line > 0 {
+		if author, err := Blame(ctx, repoPath, rev, path, line); err == nil && author != nil {
+			o.AuthorName, o.AuthorEmail, o.Commit = author.Name, author.Email, author.Commit
+		}
+	}
+	if o.IsZero() {
+		return nil
+	}
+	return o
+}
+
+func (a *Attributor) loadCodeowners(ctx context.Context, repoPath string, worktree bool) *Codeowners {
+	key := repoPath
+	if worktree {
+		key += "\x00worktree"
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if c, ok := a.codeowners[key]; ok {
+		return c
+	}
+
+	var c *Codeowners
+	for _, p := range CodeownersPaths {
+		var content []byte
+		var err error
+		if worktree {
+			content, err = os.ReadFile(filepath.Join(repoPath, filepath.FromSlash(p)))
+		} else {
+			content, err = exec.CommandContext(ctx, "git", "-C", repoPath, "show", "HEAD:"+p).Output()
+		}
+		if err != nil {
+			continue
+		}
+		if parsed, err := ParseCodeowners(bytes.NewReader(content)); err == nil {
+			c = parsed
+			break
+		}
+	}
+	a.codeowners[key] = c
+	return c
+}
@@ -0,0 +1,71 @@
+package owner
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePorcelain(t *testing.T) {
+	out := []byte("4f1d9e0c2b7a6d5e3f1a0b9c8d7e6f5a4b3c2d1e 2 2 1\n" +
+		"author Jane Doe\n" +
+		"author-mail <jane@example.com>\n" +
+		"author-time 1714550400\n" +
+		"summary add config\n" +
+		"filename config.yml\n" +
+		"\ttoken: ghp_example\n")
+	a := parsePorcelain(out)
+	require.NotNil(t, a)
+	assert.Equal(t, Author{Name: "Jane Doe", Email: "jane@example.com", Commit: "4f1d9e0c2b7a6d5e3f1a0b9c8d7e6f5a4b3c2d1e"}, *a)
+
+	uncommitted := []byte(notCommitted + " 1 1 1\nauthor Not Committed Yet\nauthor-mail <not.committed.yet>\n\tx\n")
+	assert.Nil(t, parsePorcelain(uncommitted))
+}
+
+func TestAttributor(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init")
+	git("config", "user.email", "jane@example.com")
+	git("config", "user.name", "Jane Doe")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".github"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".github", "CODEOWNERS"), []byte("config/ @org/platform\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "config"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "config", "app.yml"), []byte("name: app\ntoken: secret\n"), 0o644))
+	git("add", ".")
+	git("commit", "-m", "add config")
+
+	ctx := context.Background()
+	a := NewAttributor()
+
+	o := a.Attribute(ctx, repo, "HEAD", "config/app.yml", 2)
+	require.NotNil(t, o)
+	assert.Equal(t, "Jane Doe", o.AuthorName)
+	assert.Equal(t, "jane@example.com", o.AuthorEmail)
+	assert.Len(t, o.Commit, 40)
+	assert.Equal(t, []string{"@org/platform"}, o.Codeowners)
+
+	// Uncommitted lines in the working tree still get CODEOWNERS owners
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "config", "app.yml"), []byte("name: app\ntoken: other\n"), 0o644))
+	o = a.Attribute(ctx, repo, "", "config/app.yml", 2)
+	require.NotNil(t, o)
+	assert.Empty(t, o.AuthorName)
+	assert.Equal(t, []string{"@org/platform"}, o.Codeowners)
+
+	// Nothing known about paths outside CODEOWNERS that git can't blame
+	assert.Nil(t, a.Attribute(ctx, repo, "HEAD", "missing.txt", 1))
+}
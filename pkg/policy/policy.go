@@ -0,0 +1,152 @@
+// Package policy implements per-path scan policies: the rule packs, size
+// limits and archive extraction settings that apply to the files under a
+// path, read from the policies block of a titus.yaml scan config.
+package policy
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	gitignore "github.com/sabhiram/go-gitignore"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the scan config file looked for in the root of a scan target.
+const FileName = "titus.yaml"
+
+// Config is a scan config.
+//
+//	policies:
+//	  - paths: ["src/"]
+//	    rule_packs: [generic]
+//	  - paths: ["backups/"]
+//	    extract: none
+//	    max_file_size: 1MB
+//	  - paths: ["**/testdata/"]
+//	    exclude_rules: ["^np\\.generic\\."]
+type Config struct {
+	Policies []*Policy `yaml:"policies"`
+}
+
+// Policy holds the settings for the files matching any of its paths.
+type Policy struct {
+	// Paths are gitignore-style patterns, relative to the scan root.
+	Paths []string `yaml:"paths"`
+
+	// RulePacks are rule packs whose rules are reported under these paths,
+	// in addition to the rules selected for the scan.
+	RulePacks []string `yaml:"rule_packs"`
+
+	// ExcludeRules are rule ID regexes whose matches are not reported under
+	// these paths.
+	ExcludeRules []string `yaml:"exclude_rules"`
+
+	// MaxFileSize overrides --max-file-size, e.g. "1MB".
+	MaxFileSize string `yaml:"max_file_size"`
+
+	// Extract overrides --extract; "none" disables extraction.
+	Extract *string `yaml:"extract"`
+
+	pattern *gitignore.GitIgnore
+	exclude []*regexp.Regexp
+}
+
+// Settings are the combined settings of the policies matching a path.
+type Settings struct {
+	RulePacks   []string
+	MaxFileSize string  // empty if no policy sets it
+	Extract     *string // nil if no policy sets it; "" disables extraction
+
+	exclude []*regexp.Regexp
+}
+
+// Excludes reports whether matches of ruleID are excluded.
+func (s Settings) Excludes(ruleID string) bool {
+	for _, re := range s.exclude {
+		if re.MatchString(ruleID) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadConfig reads and validates a scan config file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading scan config: %w", err)
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig parses and validates scan config YAML.
+func ParseConfig(data []byte) (*Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing scan config: %w", err)
+	}
+	for i, p := range cfg.Policies {
+		if len(p.Paths) == 0 {
+			return nil, fmt.Errorf("policy %d has no paths", i+1)
+		}
+		p.pattern = gitignore.CompileIgnoreLines(p.Paths...)
+		for _, pattern := range p.ExcludeRules {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("policy %d: invalid exclude_rules pattern %q: %w", i+1, pattern, err)
+			}
+			p.exclude = append(p.exclude, re)
+		}
+		if p.Extract != nil && strings.EqualFold(*p.Extract, "none") {
+			none := ""
+			p.Extract = &none
+		}
+	}
+	return &cfg, nil
+}
+
+// For returns the settings for path, a slash-separated path relative to the
+// scan root. Every matching policy applies, in order: later policies override
+// the size and extraction settings of earlier ones, while rule packs and
+// exclusions accumulate.
+func (c *Config) For(path string) Settings {
+	var s Settings
+	if c == nil {
+		return s
+	}
+	path = strings.TrimPrefix(path, "/")
+	for _, p := range c.Policies {
+		if !p.pattern.MatchesPath(path) {
+			continue
+		}
+		s.RulePacks = append(s.RulePacks, p.RulePacks...)
+		s.exclude = append(s.exclude, p.exclude...)
+		if p.MaxFileSize != "" {
+			s.MaxFileSize = p.MaxFileSize
+		}
+		if p.Extract != nil {
+			s.Extract = p.Extract
+		}
+	}
+	return s
+}
+
+// RulePacks returns the rule packs named by any policy.
+func (c *Config) RulePacks() []string {
+	if c == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var packs []string
+	for _, p := range c.Policies {
+		for _, name := range p.RulePacks {
+			if !seen[name] {
+				seen[name] = true
+				packs = append(packs, name)
+			}
+		}
+	}
+	return packs
+}
@@ -0,0 +1,70 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigFor(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`
+policies:
+  - paths: ["src/"]
+    rule_packs: [generic]
+  - paths: ["backups/", "*.bak"]
+    extract: none
+    max_file_size: 1MB
+  - paths: ["backups/db/"]
+    max_file_size: 50MB
+  - paths: ["**/testdata/"]
+    exclude_rules: ["^np\\.generic\\."]
+`))
+	require.NoError(t, err)
+
+	s := cfg.For("src/app/main.go")
+	assert.Equal(t, []string{"generic"}, s.RulePacks)
+	assert.Nil(t, s.Extract)
+	assert.Empty(t, s.MaxFileSize)
+
+	s = cfg.For("backups/db/dump.sql")
+	require.NotNil(t, s.Extract)
+	assert.Equal(t, "", *s.Extract, "none disables extraction")
+	assert.Equal(t, "50MB", s.MaxFileSize, "later policies override earlier ones")
+
+	s = cfg.For("src/pkg/testdata/key.pem")
+	assert.Equal(t, []string{"generic"}, s.RulePacks)
+	assert.True(t, s.Excludes("np.generic.1"))
+	assert.False(t, s.Excludes("np.aws.1"))
+
+	s = cfg.For("docs/readme.md")
+	assert.Empty(t, s.RulePacks)
+	assert.False(t, s.Excludes("np.generic.1"))
+
+	assert.Equal(t, []string{"generic"}, cfg.RulePacks())
+
+	var none *Config
+	assert.Empty(t, none.For("src/main.go").RulePacks)
+}
+
+func TestParseConfig_Invalid(t *testing.T) {
+	_, err := ParseConfig([]byte("policies:\n  - rule_packs: [generic]\n"))
+	assert.ErrorContains(t, err, "no paths")
+
+	_, err = ParseConfig([]byte("policies:\n  - paths: [src/]\n    exclude_rules: [\"(\"]\n"))
+	assert.ErrorContains(t, err, "invalid exclude_rules pattern")
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	require.NoError(t, os.WriteFile(path, []byte("policies:\n  - paths: [vendor/]\n    max_file_size: 100KB\n"), 0644))
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "100KB", cfg.For("vendor/lib.js").MaxFileSize)
+
+	_, err = LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,272 @@
+// Package purge builds plans for scrubbing leaked secrets from git history
+// with git-filter-repo or BFG Repo-Cleaner: the replace-text and paths files
+// those tools read, and the commands to run them.
+package purge
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultReplacement is the text secrets are replaced with. Both tools use it
+// when a replace-text line has no replacement.
+const DefaultReplacement = "***REMOVED***"
+
+// Tool is a history rewriting tool.
+type Tool string
+
+const (
+	ToolFilterRepo Tool = "filter-repo"
+	ToolBFG        Tool = "bfg"
+)
+
+// credentialFiles are file names (matched against the base name, case
+// insensitively) that hold nothing but credentials. Files with a finding and
+// one of these names are deleted from history outright rather than edited.
+var credentialFiles = []string{
+	"id_rsa", "id_dsa", "id_ecdsa", "id_ed25519",
+	"*.pem", "*.key", "*.p12", "*.pfx", "*.jks", "*.keystore",
+	".env", ".env.*", "*.env",
+	".netrc", ".pgpass", ".git-credentials", "credentials",
+}
+
+// IsCredentialFile reports whether a file's name marks it as holding only
+// credentials, so it should be deleted from history rather than rewritten.
+func IsCredentialFile(p string) bool {
+	base := strings.ToLower(path.Base(strings.ReplaceAll(p, "\\", "/")))
+	for _, pattern := range credentialFiles {
+		if ok, _ := path.Match(pattern, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Plan collects the secrets and files to remove from one or more repositories.
+type Plan struct {
+	secrets map[string]bool
+	paths   map[string]map[string]bool // by repository
+	repos   map[string]bool
+}
+
+// RepoFiles locates what a plan's commands use for one repository.
+type RepoFiles struct {
+	// Mirror is the directory the repository is mirror-cloned into and
+	// rewritten in.
+	Mirror string
+	// PathsFile is where the repository's paths were written with
+	// WritePaths. It's ignored when the repository has no paths.
+	PathsFile string
+}
+
+// NewPlan creates an empty plan.
+func NewPlan() *Plan {
+	return &Plan{
+		secrets: make(map[string]bool),
+		paths:   make(map[string]map[string]bool),
+		repos:   make(map[string]bool),
+	}
+}
+
+// AddSecret adds a secret value to replace throughout history.
+func (p *Plan) AddSecret(value []byte) {
+	if s := string(value); strings.TrimSpace(s) != "" {
+		p.secrets[s] = true
+	}
+}
+
+// AddPath adds a file path, relative to repo, to delete from repo's history
+// if it is a credential file, reporting whether it was added.
+func (p *Plan) AddPath(repo, filePath string) bool {
+	if filePath == "" || !IsCredentialFile(filePath) {
+		return false
+	}
+	if p.paths[repo] == nil {
+		p.paths[repo] = make(map[string]bool)
+	}
+	p.paths[repo][strings.ReplaceAll(filePath, "\\", "/")] = true
+	return true
+}
+
+// AddRepo adds a repository the plan applies to.
+func (p *Plan) AddRepo(repoPath string) {
+	if repoPath != "" {
+		p.repos[repoPath] = true
+	}
+}
+
+// Secrets returns the secrets to replace, sorted.
+func (p *Plan) Secrets() []string { return sortedKeys(p.secrets) }
+
+// Paths returns the files to delete from repo, sorted.
+func (p *Plan) Paths(repo string) []string { return sortedKeys(p.paths[repo]) }
+
+// PathCount returns the number of files to delete across all repositories.
+func (p *Plan) PathCount() int {
+	n := 0
+	for _, paths := range p.paths {
+		n += len(paths)
+	}
+	return n
+}
+
+// Repos returns the repositories to rewrite, sorted.
+func (p *Plan) Repos() []string { return sortedKeys(p.repos) }
+
+// WriteReplaceText writes the secrets in the --replace-text format shared by
+// git-filter-repo and BFG: one `secret==>replacement` per line. Secrets that
+// span lines or could be mistaken for a pattern prefix are written as
+// escaped `regex:` expressions.
+func (p *Plan) WriteReplaceText(w io.Writer, replacement string) error {
+	if replacement == "" {
+		replacement = DefaultReplacement
+	}
+	bw := bufio.NewWriter(w)
+	for _, s := range p.Secrets() {
+		fmt.Fprintf(bw, "%s==>%s\n", replaceTextExpr(s), replacement)
+	}
+	return bw.Flush()
+}
+
+// WritePaths writes the files to delete from repo, one per line, in the
+// format of git-filter-repo's --paths-from-file.
+func (p *Plan) WritePaths(w io.Writer, repo string) error {
+	bw := bufio.NewWriter(w)
+	for _, fp := range p.Paths(repo) {
+		fmt.Fprintln(bw, fp)
+	}
+	return bw.Flush()
+}
+
+// Commands returns the shell commands that apply the plan with tool, given
+// where the replace-text file was written and files, which locates each
+// repository's mirror clone and paths file. Each repository is rewritten in a
+// fresh mirror clone of its origin, since both tools rewrite every ref in
+// place and git-filter-repo refuses to run in a working checkout. When the
+// plan has no repositories, the commands apply to the current directory's,
+// keyed by "".
+func (p *Plan) Commands(tool Tool, replaceTextFile string, files func(repo string) RepoFiles) []string {
+	repos := p.Repos()
+	if len(repos) == 0 {
+		repos = []string{""}
+	}
+
+	var cmds []string
+	for _, repo := range repos {
+		src := repo
+		if src == "" {
+			src = "."
+		}
+		f := files(repo)
+		mirror := shellQuote(f.Mirror)
+		paths := p.Paths(repo)
+		cmds = append(cmds, fmt.Sprintf(`git clone --mirror "$(git -C %s remote get-url origin)" %s`, shellQuote(src), mirror))
+
+		switch tool {
+		case ToolBFG:
+			// BFG leaves the files in HEAD alone unless told otherwise; the
+			// secrets are still live there
+			cmd := "bfg --no-blob-protection --replace-text " + shellQuote(replaceTextFile)
+			if len(paths) > 0 {
+				cmd += " --delete-files " + shellQuote(bfgNameGlob(paths))
+			}
+			cmds = append(cmds,
+				cmd+" "+mirror,
+				fmt.Sprintf("git -C %s reflog expire --expire=now --all && git -C %[1]s gc --prune=now --aggressive", mirror),
+			)
+		default:
+			cmd := "git -C " + mirror + " filter-repo --replace-text " + shellQuote(replaceTextFile)
+			if len(paths) > 0 {
+				cmd += " --invert-paths --paths-from-file " + shellQuote(f.PathsFile)
+			}
+			cmds = append(cmds, cmd)
+		}
+	}
+	return cmds
+}
+
+// bfgNameGlob returns a --delete-files glob for the base names of paths; BFG
+// matches file names, not paths.
+func bfgNameGlob(paths []string) string {
+	names := make(map[string]bool)
+	for _, fp := range paths {
+		names[path.Base(fp)] = true
+	}
+	sorted := sortedKeys(names)
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	return "{" + strings.Join(sorted, ",") + "}"
+}
+
+// replaceTextExpr returns the left-hand side of a replace-text line for a
+// literal secret.
+func replaceTextExpr(secret string) string {
+	needsRegex := strings.ContainsAny(secret, "\r\n") || strings.Contains(secret, "==>")
+	for _, prefix := range []string{"regex:", "glob:", "literal:"} {
+		if strings.HasPrefix(secret, prefix) {
+			needsRegex = true
+		}
+	}
+	if !needsRegex {
+		return secret
+	}
+	return "regex:" + escapeRegex(secret)
+}
+
+// escapeRegex escapes a literal for both Python (git-filter-repo) and Java
+// (BFG) regular expressions: ASCII punctuation is backslash-escaped, which
+// both treat as literal (and which breaks up any "==>"), and control
+// characters use their escape sequences.
+func escapeRegex(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x80 && !isAlnum(r) && r != ' ' && r != '_':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func isAlnum(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+// shellQuote quotes s for a POSIX shell if it contains anything but safe
+// characters.
+func shellQuote(s string) string {
+	safe := s != ""
+	for _, r := range s {
+		if !isAlnum(r) && !strings.ContainsRune("-_./:=@%+,", r) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func sortedKeys(m map[string]bool) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
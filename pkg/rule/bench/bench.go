@@ -0,0 +1,234 @@
+// Package bench measures rule accuracy against a labeled corpus of files.
+//
+// A corpus is a directory with two subdirectories:
+//
+//	positive/   files that each contain at least one real secret
+//	negative/   known-clean files; any match is a false positive
+//
+// An optional labels.yml at the corpus root maps positive files (relative to
+// the corpus root) to the rule IDs expected to fire on them:
+//
+//	positive/aws.env: [np.aws.2]
+//
+// For a labeled file only the listed rules count as true positives; any other
+// rule that fires is counted as a false positive for that rule.
+package bench
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/praetorian-inc/titus/pkg/matcher"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// Corpus is a set of labeled files to benchmark rules against.
+type Corpus struct {
+	Dir      string
+	Positive []string            // paths relative to Dir
+	Negative []string            // paths relative to Dir
+	Labels   map[string][]string // positive path -> expected rule IDs
+}
+
+// LoadCorpus reads the corpus layout under dir.
+func LoadCorpus(dir string) (*Corpus, error) {
+	c := &Corpus{Dir: dir, Labels: make(map[string][]string)}
+
+	var err error
+	if c.Positive, err = listFiles(dir, "positive"); err != nil {
+		return nil, err
+	}
+	if c.Negative, err = listFiles(dir, "negative"); err != nil {
+		return nil, err
+	}
+	if len(c.Positive) == 0 && len(c.Negative) == 0 {
+		return nil, fmt.Errorf("corpus %s has no files under positive/ or negative/", dir)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "labels.yml"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading labels: %w", err)
+	}
+	if err == nil {
+		var labels map[string][]string
+		if err := yaml.Unmarshal(data, &labels); err != nil {
+			return nil, fmt.Errorf("parsing labels.yml: %w", err)
+		}
+		for path, ids := range labels {
+			c.Labels[filepath.ToSlash(filepath.Clean(path))] = ids
+		}
+	}
+	return c, nil
+}
+
+// listFiles returns the regular files under dir/sub as slash-separated paths
+// relative to dir. A missing subdirectory yields no files.
+func listFiles(dir, sub string) ([]string, error) {
+	root := filepath.Join(dir, sub)
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing %s: %w", root, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// RuleStats counts, per rule, the corpus files on which it fired correctly
+// and incorrectly.
+type RuleStats struct {
+	RuleID         string   `json:"rule_id"`
+	RuleName       string   `json:"rule_name"`
+	TruePositives  int      `json:"true_positives"`
+	FalsePositives int      `json:"false_positives"`
+	FPFiles        []string `json:"fp_files,omitempty"`
+}
+
+// Precision returns TP / (TP + FP), or 1 for a rule that never fired.
+func (s RuleStats) Precision() float64 {
+	return ratio(s.TruePositives, s.TruePositives+s.FalsePositives)
+}
+
+// Report is the outcome of running rules over a corpus. File-level counts
+// treat each corpus file as one sample.
+type Report struct {
+	TruePositives  int         `json:"true_positives"`
+	FalsePositives int         `json:"false_positives"`
+	FalseNegatives int         `json:"false_negatives"`
+	TrueNegatives  int         `json:"true_negatives"`
+	Missed         []string    `json:"missed,omitempty"` // positive files with no expected detection
+	Rules          []RuleStats `json:"rules"`            // rules that fired, most false positives first
+}
+
+// Precision returns the fraction of flagged files that were flagged correctly.
+func (r *Report) Precision() float64 {
+	return ratio(r.TruePositives, r.TruePositives+r.FalsePositives)
+}
+
+// Recall returns the fraction of positive files that were detected.
+func (r *Report) Recall() float64 {
+	return ratio(r.TruePositives, r.TruePositives+r.FalseNegatives)
+}
+
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 1
+	}
+	return float64(n) / float64(d)
+}
+
+// Run scans every corpus file with m and scores the results.
+func Run(m matcher.Matcher, c *Corpus) (*Report, error) {
+	report := &Report{}
+	stats := make(map[string]*RuleStats)
+	stat := func(match *types.Match) *RuleStats {
+		s, ok := stats[match.RuleID]
+		if !ok {
+			s = &RuleStats{RuleID: match.RuleID, RuleName: match.RuleName}
+			stats[match.RuleID] = s
+		}
+		return s
+	}
+
+	for _, path := range c.Positive {
+		fired, err := firedRules(m, c.Dir, path)
+		if err != nil {
+			return nil, err
+		}
+		expected := make(map[string]bool)
+		for _, id := range c.Labels[path] {
+			expected[id] = true
+		}
+
+		detected := false
+		for _, match := range fired {
+			if len(expected) == 0 || expected[match.RuleID] {
+				detected = true
+				stat(match).TruePositives++
+			} else {
+				s := stat(match)
+				s.FalsePositives++
+				s.FPFiles = append(s.FPFiles, path)
+			}
+		}
+		if detected {
+			report.TruePositives++
+		} else {
+			report.FalseNegatives++
+			report.Missed = append(report.Missed, path)
+		}
+	}
+
+	for _, path := range c.Negative {
+		fired, err := firedRules(m, c.Dir, path)
+		if err != nil {
+			return nil, err
+		}
+		if len(fired) == 0 {
+			report.TrueNegatives++
+			continue
+		}
+		report.FalsePositives++
+		for _, match := range fired {
+			s := stat(match)
+			s.FalsePositives++
+			s.FPFiles = append(s.FPFiles, path)
+		}
+	}
+
+	for _, s := range stats {
+		report.Rules = append(report.Rules, *s)
+	}
+	sort.Slice(report.Rules, func(i, j int) bool {
+		a, b := report.Rules[i], report.Rules[j]
+		if a.FalsePositives != b.FalsePositives {
+			return a.FalsePositives > b.FalsePositives
+		}
+		return a.RuleID < b.RuleID
+	})
+	return report, nil
+}
+
+// firedRules returns one match per distinct rule that fires on the file.
+func firedRules(m matcher.Matcher, dir, path string) ([]*types.Match, error) {
+	content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(path)))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	matches, err := m.MatchWithBlobID(content, types.ComputeBlobID(content))
+	if err != nil {
+		return nil, fmt.Errorf("matching %s: %w", path, err)
+	}
+
+	seen := make(map[string]bool)
+	var fired []*types.Match
+	for _, match := range matches {
+		if !seen[match.RuleID] {
+			seen[match.RuleID] = true
+			fired = append(fired, match)
+		}
+	}
+	return fired, nil
+}
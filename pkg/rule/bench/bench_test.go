@@ -0,0 +1,73 @@
+package bench
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/matcher"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCorpusFile(t *testing.T, dir, path, content string) {
+	t.Helper()
+	full := filepath.Join(dir, filepath.FromSlash(path))
+	require.NoError(t, os.MkdirAll(filepath.Dir(full), 0o755))
+	require.NoError(t, os.WriteFile(full, []byte(content), 0o644))
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	writeCorpusFile(t, dir, "positive/token.txt", "token = tok_abcdefghijklmnop\n")
+	writeCorpusFile(t, dir, "positive/labeled.txt", "key = key_abcdefghijklmnop and tok_qrstuvwxyzabcdef\n")
+	writeCorpusFile(t, dir, "positive/missed.txt", "nothing to see\n")
+	writeCorpusFile(t, dir, "negative/clean.txt", "just text\n")
+	writeCorpusFile(t, dir, "negative/noisy.txt", "example tok_exampleexampleex\n")
+	writeCorpusFile(t, dir, "labels.yml", "positive/labeled.txt: [test.key]\n")
+
+	rules := []*types.Rule{
+		{ID: "test.tok", Name: "Token", Pattern: `tok_[a-z]{16}`},
+		{ID: "test.key", Name: "Key", Pattern: `key_[a-z]{16}`},
+	}
+	for _, r := range rules {
+		r.StructuralID = r.ComputeStructuralID()
+	}
+	m, err := matcher.New(matcher.Config{Rules: rules})
+	require.NoError(t, err)
+	defer m.Close()
+
+	corpus, err := LoadCorpus(dir)
+	require.NoError(t, err)
+	assert.Len(t, corpus.Positive, 3)
+	assert.Len(t, corpus.Negative, 2)
+
+	report, err := Run(m, corpus)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, report.TruePositives)
+	assert.Equal(t, 1, report.FalseNegatives)
+	assert.Equal(t, 1, report.FalsePositives)
+	assert.Equal(t, 1, report.TrueNegatives)
+	assert.Equal(t, []string{"positive/missed.txt"}, report.Missed)
+	assert.InDelta(t, 2.0/3.0, report.Precision(), 0.001)
+	assert.InDelta(t, 2.0/3.0, report.Recall(), 0.001)
+
+	require.Len(t, report.Rules, 2)
+	tok := report.Rules[0]
+	assert.Equal(t, "test.tok", tok.RuleID, "noisiest rule first")
+	assert.Equal(t, 1, tok.TruePositives)
+	assert.Equal(t, 2, tok.FalsePositives, "fires on a negative file and off-label on a labeled file")
+	assert.ElementsMatch(t, []string{"positive/labeled.txt", "negative/noisy.txt"}, tok.FPFiles)
+
+	key := report.Rules[1]
+	assert.Equal(t, 1, key.TruePositives)
+	assert.Equal(t, 0, key.FalsePositives)
+	assert.Equal(t, 1.0, key.Precision())
+}
+
+func TestLoadCorpus_Empty(t *testing.T) {
+	_, err := LoadCorpus(t.TempDir())
+	assert.ErrorContains(t, err, "no files")
+}
@@ -0,0 +1,8 @@
+package rule
+
+import "testing"
+
+func TestBrowserRules_Examples(t *testing.T) {
+	assertRuleExamples(t, "np.browser.1")
+	assertRuleExamples(t, "np.browser.2")
+}
@@ -0,0 +1,8 @@
+package rule
+
+import "testing"
+
+func TestCIRules_Examples(t *testing.T) {
+	assertRuleExamples(t, "np.ci.1")
+	assertRuleExamples(t, "np.ci.2")
+}
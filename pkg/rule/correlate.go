@@ -0,0 +1,41 @@
+package rule
+
+import "github.com/praetorian-inc/titus/pkg/types"
+
+// AddCorrelationComponents returns selected plus any component rules that
+// correlated rules in selected depend on but that were not selected
+// themselves. Component rules are looked up in all; the added copies are
+// marked CorrelationOnly so they only surface as part of a correlated match.
+func AddCorrelationComponents(selected, all []*types.Rule) []*types.Rule {
+	have := make(map[string]bool, len(selected))
+	for _, r := range selected {
+		have[r.ID] = true
+	}
+	byID := make(map[string]*types.Rule, len(all))
+	for _, r := range all {
+		byID[r.ID] = r
+	}
+
+	out := append([]*types.Rule(nil), selected...)
+	for _, r := range selected {
+		if r.Correlation == nil {
+			continue
+		}
+		for _, c := range r.Correlation.Components {
+			for _, id := range c.RuleIDs {
+				if have[id] {
+					continue
+				}
+				comp, ok := byID[id]
+				if !ok {
+					continue
+				}
+				cp := *comp
+				cp.CorrelationOnly = true
+				out = append(out, &cp)
+				have[id] = true
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,9 @@
+package rule
+
+import "testing"
+
+func TestDevCacheRules_Examples(t *testing.T) {
+	for _, id := range []string{"np.maven.1", "np.gradle.2", "np.npm.2", "np.google.9", "np.azure.7"} {
+		assertRuleExamples(t, id)
+	}
+}
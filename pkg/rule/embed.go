@@ -2,7 +2,7 @@ package rule
 
 import "embed"
 
-// builtinFS embeds the built-in rules and rulesets directories.
+// builtinFS embeds the built-in rules, rulesets and rule packs directories.
 //
-//go:embed rules/*.yml rulesets/*.yml
+//go:embed rules/*.yml rulesets/*.yml packs/*.yml
 var builtinFS embed.FS
@@ -0,0 +1,41 @@
+package rule
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/matcher"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilenameRules_Examples(t *testing.T) {
+	rules, err := NewLoader().LoadBuiltinRules()
+	require.NoError(t, err)
+
+	var count int
+	for _, r := range rules {
+		if !strings.HasPrefix(r.ID, "np.filename.") {
+			continue
+		}
+		count++
+		assert.Equal(t, types.RuleKindFilename, r.Kind, r.ID)
+		require.NoError(t, ValidateRule(r))
+
+		m, err := matcher.NewFilenameMatcher([]*types.Rule{r})
+		require.NoError(t, err)
+		for _, ex := range r.Examples {
+			assert.NotEmpty(t, m.Match(ex), "%s: expected match for: %s", r.ID, ex)
+		}
+		for _, ex := range r.NegativeExamples {
+			assert.Empty(t, m.Match(ex), "%s: expected no match for: %s", r.ID, ex)
+		}
+	}
+	assert.Equal(t, 9, count)
+}
+
+func TestValidateRule_Kind(t *testing.T) {
+	r := &types.Rule{ID: "test.1", Name: "Test", Pattern: "x", Kind: "path"}
+	assert.ErrorContains(t, ValidateRule(r), "unknown kind")
+}
@@ -12,6 +12,12 @@ import (
 type FilterConfig struct {
 	Include []string // Regex patterns - only matching rules included
 	Exclude []string // Regex patterns - matching rules excluded
+
+	// Categories keeps only rules with at least one of these categories or
+	// tags; ExcludeCategories drops rules with any of them. Names are
+	// compared case-insensitively.
+	Categories        []string
+	ExcludeCategories []string
 }
 
 // ParsePatterns splits a comma-separated string into individual patterns.
@@ -32,8 +38,8 @@ func ParsePatterns(patterns string) []string {
 	return result
 }
 
-// Filter applies include and exclude patterns to rules.
-// Include is applied first, then exclude.
+// Filter applies include and exclude patterns to rules, then the category
+// filters. Include is applied first, then exclude.
 // Empty include means "include all".
 // Returns error if any pattern is invalid regex.
 func Filter(rules []*types.Rule, config FilterConfig) ([]*types.Rule, error) {
@@ -72,6 +78,14 @@ func Filter(rules []*types.Rule, config FilterConfig) ([]*types.Rule, error) {
 		filtered = applyExclude(filtered, excludeRegexes)
 	}
 
+	// Apply category filters
+	if len(config.Categories) > 0 {
+		filtered = filterLabels(filtered, config.Categories, true)
+	}
+	if len(config.ExcludeCategories) > 0 {
+		filtered = filterLabels(filtered, config.ExcludeCategories, false)
+	}
+
 	return filtered, nil
 }
 
@@ -99,6 +113,34 @@ func applyExclude(rules []*types.Rule, regexes []*regexp.Regexp) []*types.Rule {
 	return result
 }
 
+// filterLabels keeps the rules that have one of labels as a category or
+// tag when keep is true, and the rules that have none of them otherwise.
+func filterLabels(rules []*types.Rule, labels []string, keep bool) []*types.Rule {
+	result := make([]*types.Rule, 0)
+	for _, rule := range rules {
+		if hasLabel(rule, labels) == keep {
+			result = append(result, rule)
+		}
+	}
+	return result
+}
+
+func hasLabel(rule *types.Rule, labels []string) bool {
+	for _, label := range labels {
+		for _, c := range rule.Categories {
+			if strings.EqualFold(c, label) {
+				return true
+			}
+		}
+		for _, t := range rule.Tags {
+			if strings.EqualFold(t, label) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func matchesAny(ruleID string, regexes []*regexp.Regexp) bool {
 	for _, re := range regexes {
 		if re.MatchString(ruleID) {
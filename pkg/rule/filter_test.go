@@ -280,6 +280,59 @@ func TestFilter_InvalidRegex(t *testing.T) {
 	}
 }
 
+func TestFilter_Categories(t *testing.T) {
+	rules := []*types.Rule{
+		{ID: "np.aws.1", Categories: []string{"api", "secret"}, Tags: []string{"validated-capable"}},
+		{ID: "np.ssn.1", Categories: []string{"identifier"}, Tags: []string{"PII"}},
+		{ID: "np.generic.1", Categories: []string{"secret", "fuzzy"}},
+		{ID: "np.hash.1"},
+	}
+
+	tests := []struct {
+		name     string
+		config   FilterConfig
+		expected []string
+	}{
+		{
+			name:     "category",
+			config:   FilterConfig{Categories: []string{"secret"}},
+			expected: []string{"np.aws.1", "np.generic.1"},
+		},
+		{
+			name:     "tag matches case-insensitively",
+			config:   FilterConfig{Categories: []string{"pii"}},
+			expected: []string{"np.ssn.1"},
+		},
+		{
+			name:     "any of several",
+			config:   FilterConfig{Categories: []string{"validated-capable", "identifier"}},
+			expected: []string{"np.aws.1", "np.ssn.1"},
+		},
+		{
+			name:     "exclude",
+			config:   FilterConfig{ExcludeCategories: []string{"fuzzy", "pii"}},
+			expected: []string{"np.aws.1", "np.hash.1"},
+		},
+		{
+			name:     "combined with id patterns",
+			config:   FilterConfig{Include: []string{"^np\\.(aws|generic)"}, ExcludeCategories: []string{"fuzzy"}},
+			expected: []string{"np.aws.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, err := Filter(rules, tt.config)
+			require.NoError(t, err)
+			var ids []string
+			for _, r := range filtered {
+				ids = append(ids, r.ID)
+			}
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+}
+
 func TestFilter_NilRules(t *testing.T) {
 	config := FilterConfig{
 		Include: []string{".*"},
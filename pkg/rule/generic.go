@@ -0,0 +1,134 @@
+package rule
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Defaults applied to zero-valued GenericSpec fields.
+var (
+	DefaultGenericKeySuffixes = []string{"Password", "Passwd", "Pass", "Pwd", "Secret", "Token"}
+	DefaultGenericBinders     = []string{":", "=", "=>", ":="}
+)
+
+const (
+	DefaultGenericQuotes    = "\"'`"
+	DefaultGenericMinLength = 6
+	DefaultGenericMaxLength = 128
+)
+
+// genericValueLeadExclusions are characters that may not start a value. They
+// suppress interpolation and template forms such as ${VAR}, <placeholder>, %s,
+// {{ var }}, @var and #{var}, as well as relative paths.
+const genericValueLeadExclusions = `$<%@.,&/#{}()+-`
+
+// GenericSpec describes a generic credential assignment as a key, a binder and
+// a quoted value. Rules declare a spec under `generic:` instead of a pattern,
+// and the loader expands it into a single regex, so one spec covers every
+// combination of key style, binder and quote character.
+//
+// Keys match in two styles:
+//   - camelCase identifiers ending in a suffix, e.g. dbPassword, clientSecret
+//   - the bare suffix, optionally after a _ . or - separated prefix, in any
+//     case, e.g. password, DB_PASSWORD, client-secret, config.token
+//
+// The key may itself be quoted (JSON, Python dicts) and the binder may be
+// surrounded by spaces or tabs. The first capture group is the value.
+type GenericSpec struct {
+	KeySuffixes []string // identifier endings, written in camelCase form (e.g. "Secret")
+	Binders     []string // operators between key and value (e.g. ":", "=", "=>")
+	Quotes      string   // characters that may delimit the value
+	MinLength   int      // minimum value length
+	MaxLength   int      // maximum value length
+}
+
+// withDefaults returns a copy of s with zero-valued fields set to their defaults.
+func (s GenericSpec) withDefaults() GenericSpec {
+	if len(s.KeySuffixes) == 0 {
+		s.KeySuffixes = DefaultGenericKeySuffixes
+	}
+	if len(s.Binders) == 0 {
+		s.Binders = DefaultGenericBinders
+	}
+	if s.Quotes == "" {
+		s.Quotes = DefaultGenericQuotes
+	}
+	if s.MinLength <= 0 {
+		s.MinLength = DefaultGenericMinLength
+	}
+	if s.MaxLength <= 0 {
+		s.MaxLength = DefaultGenericMaxLength
+	}
+	return s
+}
+
+// Pattern expands the spec into a regex. The pattern avoids backreferences and
+// lookaround so it compiles under every matcher backend; as a consequence the
+// opening and closing quotes are matched independently rather than paired.
+func (s GenericSpec) Pattern() string {
+	s = s.withDefaults()
+
+	camel := make([]string, 0, len(s.KeySuffixes))
+	plain := make([]string, 0, len(s.KeySuffixes))
+	for _, suffix := range longestFirst(s.KeySuffixes) {
+		camel = append(camel, regexp.QuoteMeta(upperFirst(suffix)))
+		plain = append(plain, regexp.QuoteMeta(strings.ToLower(suffix)))
+	}
+
+	binders := make([]string, 0, len(s.Binders))
+	for _, b := range longestFirst(s.Binders) {
+		binders = append(binders, regexp.QuoteMeta(strings.TrimSpace(b)))
+	}
+
+	quotes := classEscape(s.Quotes)
+
+	var b strings.Builder
+	// Key
+	b.WriteString(`\b(?:[A-Za-z0-9_$]*[a-z0-9](?:`)
+	b.WriteString(strings.Join(camel, "|"))
+	b.WriteString(`)|(?:[A-Za-z0-9_$]*[_.\-])?(?i:`)
+	b.WriteString(strings.Join(plain, "|"))
+	b.WriteString(`))`)
+	// Optional closing quote around the key, then the binder
+	fmt.Fprintf(&b, `[%s]?[ \t]*(?:%s)[ \t]*`, quotes, strings.Join(binders, "|"))
+	// Quoted value
+	fmt.Fprintf(&b, `[%s]([^%s\s%s][^%s\s]{%d,%d})[%s]`,
+		quotes, quotes, classEscape(genericValueLeadExclusions), quotes,
+		s.MinLength-1, s.MaxLength-1, quotes)
+	return b.String()
+}
+
+// longestFirst returns a copy of values sorted by descending length so that
+// alternations prefer e.g. "=>" over "=" and "Password" over "Pass".
+func longestFirst(values []string) []string {
+	out := append([]string(nil), values...)
+	sort.SliceStable(out, func(i, j int) bool {
+		return len(out[i]) > len(out[j])
+	})
+	return out
+}
+
+// upperFirst upper-cases the first rune of s.
+func upperFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// classEscape escapes chars for use inside a regex character class.
+func classEscape(chars string) string {
+	var b strings.Builder
+	for _, r := range chars {
+		if strings.ContainsRune(`\]^-[`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
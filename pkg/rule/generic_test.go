@@ -0,0 +1,98 @@
+package rule
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/matcher"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenericSpec_Pattern(t *testing.T) {
+	re := regexp.MustCompile(GenericSpec{}.Pattern())
+
+	testCases := []struct {
+		input string
+		want  string
+	}{
+		{`{dbPassword: "S3cr3tP@ss!"}`, "S3cr3tP@ss!"},
+		{`{dbPassword:"S3cr3tP@ss!"}`, "S3cr3tP@ss!"},
+		{`clientSecret = 'abc123def456'`, "abc123def456"},
+		{`authToken=>` + "`tok_123456`", "tok_123456"},
+		{`"DB_PASSWORD": "hunter2hunter2"`, "hunter2hunter2"},
+		{`password := "hunter2hunter2"`, "hunter2hunter2"},
+		{`config.token: "abcdef123456"`, "abcdef123456"},
+		{`userPwd	=	"p4ssw0rd!"`, "p4ssw0rd!"},
+		{`dbPassword: ""`, ""},
+		{`dbPassword: "short"`, ""},
+		{`dbPassword: "${DB_PASSWORD}"`, ""},
+		{`dbPassword: "%s"`, ""},
+		{`dbPassword: process.env.DB_PASSWORD`, ""},
+		{`dbPassword: "has spaces in it"`, ""},
+		{`password == "hunter2hunter2"`, ""},
+		{`passwordHash: "5f4dcc3b5aa765d6"`, ""},
+		{`mypassword: "hunter2hunter2"`, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			m := re.FindStringSubmatch(tc.input)
+			if tc.want == "" {
+				assert.Nil(t, m)
+				return
+			}
+			require.NotNil(t, m)
+			assert.Equal(t, tc.want, m[1])
+		})
+	}
+}
+
+func TestGenericSpec_Parameters(t *testing.T) {
+	spec := GenericSpec{
+		KeySuffixes: []string{"apiKey"},
+		Binders:     []string{"->"},
+		Quotes:      `"`,
+		MinLength:   3,
+		MaxLength:   5,
+	}
+	re := regexp.MustCompile(spec.Pattern())
+
+	assert.True(t, re.MatchString(`stripeApiKey -> "abcd"`))
+	assert.True(t, re.MatchString(`APIKEY->"abc"`))
+	assert.False(t, re.MatchString(`stripeApiKey: "abcd"`), "binder not in spec")
+	assert.False(t, re.MatchString(`stripeApiKey -> 'abcd'`), "quote not in spec")
+	assert.False(t, re.MatchString(`stripeApiKey -> "abcdef"`), "value too long")
+	assert.False(t, re.MatchString(`stripeApiKey -> "ab"`), "value too short")
+}
+
+func TestGenericRule_Examples(t *testing.T) {
+	rules, err := NewLoader().LoadBuiltinRules()
+	require.NoError(t, err)
+
+	var generic *types.Rule
+	for _, r := range rules {
+		if r.ID == "np.generic.17" {
+			generic = r
+			break
+		}
+	}
+	require.NotNil(t, generic, "np.generic.17 rule should exist")
+	require.NoError(t, ValidateRule(generic))
+	assert.Equal(t, GenericSpec{}.Pattern(), generic.Pattern)
+
+	m, err := matcher.New(matcher.Config{Rules: []*types.Rule{generic}})
+	require.NoError(t, err)
+	defer m.Close()
+
+	for _, ex := range generic.Examples {
+		matches, err := m.Match([]byte(ex))
+		require.NoError(t, err)
+		assert.NotEmpty(t, matches, "expected match for: %s", ex)
+	}
+	for _, ex := range generic.NegativeExamples {
+		matches, err := m.Match([]byte(ex))
+		require.NoError(t, err)
+		assert.Empty(t, matches, "expected no match for: %s", ex)
+	}
+}
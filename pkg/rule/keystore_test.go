@@ -0,0 +1,8 @@
+package rule
+
+import "testing"
+
+func TestKeystoreRules_Examples(t *testing.T) {
+	assertRuleExamples(t, "np.keystore.1")
+	assertRuleExamples(t, "np.keystore.2")
+}
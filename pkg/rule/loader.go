@@ -5,6 +5,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/praetorian-inc/titus/pkg/types"
 	"gopkg.in/yaml.v3"
@@ -158,17 +159,31 @@ func (l *Loader) LoadBuiltinRulesets() ([]*types.Ruleset, error) {
 }
 
 // convertYAMLRule converts yamlRule to types.Rule and computes StructuralID.
+// Rules that declare a generic spec instead of a pattern get the expanded pattern.
 func convertYAMLRule(yr yamlRule) *types.Rule {
 	r := &types.Rule{
 		ID:               yr.ID,
 		Name:             yr.Name,
+		Kind:             yr.Kind,
 		Pattern:          yr.Pattern,
 		Description:      yr.Description,
 		Examples:         yr.Examples,
 		NegativeExamples: yr.NegativeExamples,
 		References:       yr.References,
 		Categories:       yr.Categories,
+		Tags:             yr.Tags,
 		MinEntropy:       yr.MinEntropy,
+		Verify:           yr.Verify,
+		MinMatches:       yr.MinMatches,
+	}
+	if r.Pattern == "" && yr.Generic != nil {
+		r.Pattern = GenericSpec{
+			KeySuffixes: yr.Generic.KeySuffixes,
+			Binders:     yr.Generic.Binders,
+			Quotes:      yr.Generic.Quotes,
+			MinLength:   yr.Generic.MinLength,
+			MaxLength:   yr.Generic.MaxLength,
+		}.Pattern()
 	}
 	if yr.PatternRequirements != nil {
 		r.PatternRequirements = &types.PatternRequirements{
@@ -180,6 +195,30 @@ func convertYAMLRule(yr yamlRule) *types.Rule {
 			IgnoreIfContains: yr.PatternRequirements.IgnoreIfContains,
 		}
 	}
+	if yr.Correlate != nil {
+		r.Correlation = &types.Correlation{MaxDistance: yr.Correlate.MaxDistance}
+		for _, c := range yr.Correlate.Components {
+			r.Correlation.Components = append(r.Correlation.Components, types.CorrelationComponent{
+				Group:   c.Group,
+				RuleIDs: c.Rules,
+			})
+		}
+	}
+	if yr.Context != nil {
+		r.Context = &types.ContextOverride{Lines: yr.Context.Lines, Bytes: yr.Context.Bytes}
+	}
+	if v := yr.Validation; v != nil && v.Type == "Http" && len(v.Content.Request.Unsupported) == 0 {
+		req := v.Content.Request
+		r.Validation = &types.HTTPValidation{
+			Method:          req.Method,
+			URL:             strings.TrimSpace(req.URL),
+			Headers:         req.Headers,
+			Body:            req.Body,
+			ResponseMatcher: req.ResponseMatcher,
+			SecretGroup:     v.SecretGroup,
+			TimeoutSeconds:  req.TimeoutSeconds,
+		}
+	}
 	r.StructuralID = r.ComputeStructuralID()
 	return r
 }
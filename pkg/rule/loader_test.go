@@ -1,6 +1,7 @@
 package rule
 
 import (
+	"reflect"
 	"testing"
 	"testing/fstest"
 
@@ -26,6 +27,8 @@ func TestLoadRule_Valid(t *testing.T) {
     categories:
       - secret
       - api
+    tags:
+      - validated-capable
 `
 
 	rule, err := loader.LoadRule([]byte(validYAML))
@@ -57,11 +60,72 @@ func TestLoadRule_Valid(t *testing.T) {
 	if len(rule.Categories) != 2 {
 		t.Errorf("expected 2 categories, got %d", len(rule.Categories))
 	}
+	if len(rule.Tags) != 1 || rule.Tags[0] != "validated-capable" {
+		t.Errorf("expected tag validated-capable, got %v", rule.Tags)
+	}
 	if rule.StructuralID == "" {
 		t.Error("expected StructuralID to be computed")
 	}
 }
 
+func TestLoadRule_Validation(t *testing.T) {
+	loader := NewLoader()
+
+	ruleYAML := `rules:
+  - name: Airbrake User Key
+    id: kingfisher.airbrake.1
+    pattern: airbrake.{0,32}?(?P<token>[A-Z0-9-]{40})
+    validation:
+      type: Http
+      content:
+        request:
+          headers:
+            Content-Type: application/json
+          method: GET
+          response_matcher:
+            - report_response: true
+            - status:
+                - 200
+              type: StatusMatch
+            - words:
+                - '"id"'
+              type: WordMatch
+          url: https://api.airbrake.io/api/v4/projects?key={{ TOKEN }}
+`
+	rule, err := loader.LoadRule([]byte(ruleYAML))
+	if err != nil {
+		t.Fatalf("LoadRule failed: %v", err)
+	}
+	want := &types.HTTPValidation{
+		Method:  "GET",
+		URL:     "https://api.airbrake.io/api/v4/projects?key={{ TOKEN }}",
+		Headers: map[string]string{"Content-Type": "application/json"},
+		ResponseMatcher: []types.ResponseMatcher{
+			{ReportResponse: true},
+			{Type: types.StatusMatch, Status: []int{200}},
+			{Type: types.WordMatch, Words: []string{`"id"`}},
+		},
+	}
+	if !reflect.DeepEqual(rule.Validation, want) {
+		t.Errorf("expected validation %+v, got %+v", want, rule.Validation)
+	}
+
+	// Requests with settings titus can't apply, and other kinds of
+	// validation, are left out
+	for _, validation := range []string{
+		"{type: Http, content: {request: {method: GET, url: 'https://example.com', digest: '{{ TOKEN }}'}}}",
+		"{type: MongoDB}",
+	} {
+		rule, err := loader.LoadRule([]byte("rules:\n  - name: Test\n    id: test.1\n    pattern: x\n    validation: " + validation + "\n"))
+		if err != nil {
+			t.Fatalf("LoadRule failed: %v", err)
+		}
+		if rule.Validation != nil {
+			t.Errorf("expected no validation for %s, got %+v", validation, rule.Validation)
+		}
+	}
+}
+
 func TestLoadRule_InvalidYAML(t *testing.T) {
 	loader := NewLoader()
 
@@ -390,6 +454,38 @@ func TestLoadRule_WithPatternRequirements(t *testing.T) {
 	}
 }
 
+func TestLoadRule_WithContext(t *testing.T) {
+	loader := NewLoader()
+
+	yaml := `rules:
+  - name: Test Rule With Context
+    id: np.test.context.1
+    pattern: 'eyJ[A-Za-z0-9_-]{20,}'
+    context:
+      lines: 0
+      bytes: 64
+`
+	rule, err := loader.LoadRule([]byte(yaml))
+	if err != nil {
+		t.Fatalf("LoadRule failed: %v", err)
+	}
+	if rule.Context == nil || rule.Context.Lines == nil || rule.Context.Bytes == nil {
+		t.Fatalf("expected lines and bytes context overrides, got %+v", rule.Context)
+	}
+	if *rule.Context.Lines != 0 || *rule.Context.Bytes != 64 {
+		t.Errorf("expected lines 0 and bytes 64, got %d and %d", *rule.Context.Lines, *rule.Context.Bytes)
+	}
+	if err := ValidateRule(rule); err != nil {
+		t.Errorf("ValidateRule failed: %v", err)
+	}
+
+	negative := -1
+	rule.Context.Bytes = &negative
+	if err := ValidateRule(rule); err == nil {
+		t.Error("expected negative context bytes to be rejected")
+	}
+}
+
 func TestLoadRule_NoPatternRequirements(t *testing.T) {
 	loader := NewLoader()
 
@@ -0,0 +1,190 @@
+package rule
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// PackManifest is the file that marks a directory as an external rule pack.
+const PackManifest = "pack.yml"
+
+// Pack is a named, versioned collection of rules.
+//
+// Built-in packs select from the built-in rules by ID glob pattern, and can
+// also ship rules of their own that are only used when the pack is selected.
+// External packs are directories containing a pack.yml manifest alongside
+// their own rule files.
+type Pack struct {
+	Name        string
+	Version     string
+	Description string
+	RuleIDs     []string      // rule ID glob patterns (built-in packs)
+	Rules       []*types.Rule // rules shipped with the pack
+	Dir         string        // source directory; empty for built-in packs
+}
+
+// Select returns the pack's rules: the rules it ships, and for built-in
+// packs the rules in builtin whose IDs match one of the pack's patterns.
+func (p *Pack) Select(builtin []*types.Rule) []*types.Rule {
+	rules := append([]*types.Rule(nil), p.Rules...)
+	if p.Dir != "" {
+		return rules
+	}
+	for _, r := range builtin {
+		for _, pattern := range p.RuleIDs {
+			if ok, _ := path.Match(pattern, r.ID); ok {
+				rules = append(rules, r)
+				break
+			}
+		}
+	}
+	return rules
+}
+
+// LoadBuiltinPacks loads the pack manifest from the embedded filesystem.
+func (l *Loader) LoadBuiltinPacks() ([]*Pack, error) {
+	var packs []*Pack
+
+	err := fs.WalkDir(l.fs, "packs", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(p) != ".yml" {
+			return nil
+		}
+
+		data, err := fs.ReadFile(l.fs, p)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", p, err)
+		}
+
+		var yamlFile yamlPacksFile
+		if err := yaml.Unmarshal(data, &yamlFile); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", p, err)
+		}
+
+		for _, yp := range yamlFile.Packs {
+			for _, pattern := range yp.RuleIDs {
+				if _, err := path.Match(pattern, ""); err != nil {
+					return fmt.Errorf("pack %s: invalid rule ID pattern %q: %w", yp.Name, pattern, err)
+				}
+			}
+			pack := &Pack{
+				Name:        yp.Name,
+				Version:     yp.Version,
+				Description: yp.Description,
+				RuleIDs:     yp.RuleIDs,
+			}
+			for _, yr := range yp.Rules {
+				pack.Rules = append(pack.Rules, convertYAMLRule(yr))
+			}
+			packs = append(packs, pack)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return packs, nil
+}
+
+// LoadPackDir loads an external rule pack from dir. The directory must contain
+// a pack.yml manifest with a name and version; every other .yml or .yaml file
+// in the directory is parsed as a rules file.
+func (l *Loader) LoadPackDir(dir string) (*Pack, error) {
+	data, err := os.ReadFile(filepath.Join(dir, PackManifest))
+	if err != nil {
+		return nil, fmt.Errorf("reading pack manifest: %w", err)
+	}
+
+	var yp yamlPack
+	if err := yaml.Unmarshal(data, &yp); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Join(dir, PackManifest), err)
+	}
+	if yp.Name == "" || yp.Version == "" {
+		return nil, fmt.Errorf("pack manifest in %s must set name and version", dir)
+	}
+
+	pack := &Pack{
+		Name:        yp.Name,
+		Version:     yp.Version,
+		Description: yp.Description,
+		Dir:         dir,
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading pack directory: %w", err)
+	}
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || e.Name() == PackManifest || (ext != ".yml" && ext != ".yaml") {
+			continue
+		}
+
+		p := filepath.Join(dir, e.Name())
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", p, err)
+		}
+		var yamlFile yamlRulesFile
+		if err := yaml.Unmarshal(data, &yamlFile); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", p, err)
+		}
+		for _, yr := range yamlFile.Rules {
+			pack.Rules = append(pack.Rules, convertYAMLRule(yr))
+		}
+	}
+
+	if len(pack.Rules) == 0 {
+		return nil, fmt.Errorf("pack %s in %s contains no rules", pack.Name, dir)
+	}
+	return pack, nil
+}
+
+// SelectPacks resolves pack selectors of the form "name" or "name@version"
+// against the available packs and returns the union of their rules, ordered
+// by rule ID. A version, when given, must match the pack's version exactly.
+func SelectPacks(available []*Pack, builtin []*types.Rule, selectors []string) ([]*types.Rule, error) {
+	byName := make(map[string]*Pack, len(available))
+	for _, p := range available {
+		byName[p.Name] = p // later packs (external dirs) override built-ins
+	}
+
+	seen := make(map[string]bool)
+	var rules []*types.Rule
+	for _, sel := range selectors {
+		name, version, _ := strings.Cut(sel, "@")
+		p, ok := byName[name]
+		if !ok {
+			names := make([]string, 0, len(byName))
+			for n := range byName {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("unknown rule pack %q (available: %s)", name, strings.Join(names, ", "))
+		}
+		if version != "" && version != p.Version {
+			return nil, fmt.Errorf("rule pack %s is version %s, not %s", name, p.Version, version)
+		}
+
+		for _, r := range p.Select(builtin) {
+			if !seen[r.ID] {
+				seen[r.ID] = true
+				rules = append(rules, r)
+			}
+		}
+	}
+
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules, nil
+}
@@ -0,0 +1,165 @@
+package rule
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/matcher"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadBuiltinPacks(t *testing.T) {
+	loader := NewLoader()
+	packs, err := loader.LoadBuiltinPacks()
+	require.NoError(t, err)
+
+	names := make(map[string]bool)
+	for _, p := range packs {
+		names[p.Name] = true
+		assert.NotEmpty(t, p.Version, p.Name)
+		assert.NotEmpty(t, p.Description, p.Name)
+	}
+	for _, want := range []string{"core", "cloud", "ci-cd", "crypto"} {
+		assert.True(t, names[want], "missing pack %s", want)
+	}
+}
+
+func TestBuiltinPacks_PatternsMatchRules(t *testing.T) {
+	loader := NewLoader()
+	builtin, err := loader.LoadBuiltinRules()
+	require.NoError(t, err)
+	packs, err := loader.LoadBuiltinPacks()
+	require.NoError(t, err)
+
+	// Every pattern should select at least one rule so stale entries are caught
+	for _, p := range packs {
+		for _, pattern := range p.RuleIDs {
+			single := &Pack{Name: p.Name, RuleIDs: []string{pattern}}
+			assert.NotEmpty(t, single.Select(builtin), "pack %s pattern %s matches no rules", p.Name, pattern)
+		}
+	}
+}
+
+func TestSelectPacks(t *testing.T) {
+	loader := NewLoader()
+	builtin, err := loader.LoadBuiltinRules()
+	require.NoError(t, err)
+	packs, err := loader.LoadBuiltinPacks()
+	require.NoError(t, err)
+
+	cloud, err := SelectPacks(packs, builtin, []string{"cloud"})
+	require.NoError(t, err)
+	ids := make(map[string]bool)
+	for _, r := range cloud {
+		ids[r.ID] = true
+	}
+	assert.True(t, ids["np.aws.2"])
+	assert.False(t, ids["np.github.1"])
+
+	// Connection strings, Redis ones included, are in core
+	core, err := SelectPacks(packs, builtin, []string{"core"})
+	require.NoError(t, err)
+	var redis []string
+	for _, r := range core {
+		if strings.HasPrefix(r.ID, "np.redis.") {
+			redis = append(redis, r.ID)
+		}
+	}
+	assert.ElementsMatch(t, []string{"np.redis.1", "np.redis.2"}, redis)
+
+	// Union is deduplicated
+	both, err := SelectPacks(packs, builtin, []string{"cloud", "cloud@1.0.0", "ci-cd"})
+	require.NoError(t, err)
+	seen := make(map[string]bool)
+	for _, r := range both {
+		assert.False(t, seen[r.ID], "duplicate rule %s", r.ID)
+		seen[r.ID] = true
+	}
+	assert.Greater(t, len(both), len(cloud))
+
+	_, err = SelectPacks(packs, builtin, []string{"nope"})
+	assert.ErrorContains(t, err, "unknown rule pack")
+
+	_, err = SelectPacks(packs, builtin, []string{"cloud@9.9.9"})
+	assert.ErrorContains(t, err, "version")
+}
+
+func TestBuiltinPacks_PII(t *testing.T) {
+	loader := NewLoader()
+	builtin, err := loader.LoadBuiltinRules()
+	require.NoError(t, err)
+	packs, err := loader.LoadBuiltinPacks()
+	require.NoError(t, err)
+
+	// PII rules are off by default: they aren't built-in rules
+	for _, r := range builtin {
+		assert.NotEqual(t, types.RuleKindPII, r.Kind, r.ID)
+	}
+
+	rules, err := SelectPacks(packs, builtin, []string{"pii"})
+	require.NoError(t, err)
+	require.NotEmpty(t, rules)
+	for _, r := range rules {
+		assert.Equal(t, types.RuleKindPII, r.Kind, r.ID)
+
+		m, err := matcher.New(matcher.Config{Rules: []*types.Rule{r}})
+		require.NoError(t, err)
+		for _, ex := range r.Examples {
+			matches, err := m.Match([]byte(ex))
+			require.NoError(t, err)
+			require.NotEmpty(t, matches, "%s: expected match for: %s", r.ID, ex)
+			assert.Equal(t, types.RuleKindPII, matches[0].Kind)
+		}
+		for _, ex := range r.NegativeExamples {
+			matches, err := m.Match([]byte(ex))
+			require.NoError(t, err)
+			assert.Empty(t, matches, "%s: expected no match for: %s", r.ID, ex)
+		}
+		m.Close()
+	}
+}
+
+func TestLoadPackDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, PackManifest), []byte("name: acme\nversion: 2.1.0\ndescription: Internal tokens\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tokens.yml"), []byte(`rules:
+  - name: Acme Token
+    id: acme.token.1
+    pattern: 'acme_[a-z0-9]{32}'
+  - name: Acme Secret
+    id: acme.secret.1
+    pattern: 'acmesec_[a-z0-9]{32}'
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a rule"), 0644))
+
+	loader := NewLoader()
+	pack, err := loader.LoadPackDir(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", pack.Name)
+	assert.Equal(t, "2.1.0", pack.Version)
+	assert.Len(t, pack.Rules, 2)
+
+	rules, err := SelectPacks([]*Pack{pack}, nil, []string{"acme@2.1.0"})
+	require.NoError(t, err)
+	assert.Len(t, rules, 2)
+}
+
+func TestLoadPackDir_Invalid(t *testing.T) {
+	loader := NewLoader()
+
+	_, err := loader.LoadPackDir(t.TempDir())
+	assert.ErrorContains(t, err, "pack manifest")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, PackManifest), []byte("name: empty\n"), 0644))
+	_, err = loader.LoadPackDir(dir)
+	assert.ErrorContains(t, err, "name and version")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, PackManifest), []byte("name: empty\nversion: 1.0.0\n"), 0644))
+	_, err = loader.LoadPackDir(dir)
+	assert.ErrorContains(t, err, "no rules")
+}
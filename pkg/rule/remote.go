@@ -0,0 +1,325 @@
+package rule
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// maxRemoteArchiveSize bounds the size of a downloaded rule pack archive.
+	maxRemoteArchiveSize = 50 * 1024 * 1024
+	// maxRemoteExtractSize bounds the total uncompressed size of a rule pack archive.
+	maxRemoteExtractSize = 100 * 1024 * 1024
+)
+
+// RemoteSource is a rule pack published as a .tar.gz archive over HTTP(S).
+//
+// The archive must contain a pack directory (pack.yml plus rule files) at its
+// root or in a single top-level directory. A plain http:// URL is refused
+// unless SHA256 or PublicKey pins the pack, since anyone on the network path
+// could otherwise swap in their own rules. Downloads are cached under
+// CacheDir; a cached copy is used without contacting the server when it
+// matches SHA256, and as a fallback when the server cannot be reached.
+type RemoteSource struct {
+	URL       string
+	SHA256    string            // expected hex digest of the archive (optional)
+	PublicKey ed25519.PublicKey // verifies the detached signature at URL+".sig" (optional)
+	CacheDir  string
+	Client    *http.Client                     // nil = client with a 60s timeout
+	WarnFunc  func(format string, args ...any) // optional; receives cache fallback warnings
+}
+
+// IsRemote reports whether a --rules argument refers to a remote rule pack.
+func IsRemote(path string) bool {
+	return strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://")
+}
+
+// ParsePublicKey parses an Ed25519 public key in PEM (PKIX) form or as the
+// base64 encoding of the raw 32-byte key.
+func ParsePublicKey(data []byte) (ed25519.PublicKey, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing public key: %w", err)
+		}
+		edKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public key is %T, expected Ed25519", key)
+		}
+		return edKey, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key must be PEM or base64 of %d bytes", ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// Fetch returns the directory of the verified, extracted pack, downloading it
+// if the cache cannot be used.
+func (s *RemoteSource) Fetch(ctx context.Context) (string, error) {
+	if !strings.HasPrefix(s.URL, "https://") && s.SHA256 == "" && s.PublicKey == nil {
+		return "", fmt.Errorf("refusing unpinned rule pack over plain HTTP: use https:// or pin it with --rules-sha256 or --rules-pubkey")
+	}
+	if s.SHA256 != "" {
+		if _, err := hex.DecodeString(s.SHA256); err != nil || len(s.SHA256) != 64 {
+			return "", fmt.Errorf("invalid sha256 %q", s.SHA256)
+		}
+	}
+
+	urlKey := sha256.Sum256([]byte(s.URL))
+	cacheDir := filepath.Join(s.CacheDir, hex.EncodeToString(urlKey[:8]))
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return "", fmt.Errorf("creating rules cache: %w", err)
+	}
+	archivePath := filepath.Join(cacheDir, "pack.tar.gz")
+	sigPath := archivePath + ".sig"
+
+	// A pinned digest that matches the cache needs no network access
+	digest, cacheErr := fileSHA256(archivePath)
+	if cacheErr != nil || s.SHA256 == "" || !strings.EqualFold(digest, s.SHA256) {
+		fetched, err := s.download(ctx, cacheDir, archivePath, sigPath)
+		switch {
+		case err == nil:
+			digest = fetched
+		case cacheErr == nil && s.SHA256 == "":
+			s.warn("[warn] fetching %s failed, using cached copy: %v\n", s.URL, err)
+		default:
+			return "", err
+		}
+	}
+
+	if err := s.verify(archivePath, sigPath, digest); err != nil {
+		return "", err
+	}
+
+	extractDir := filepath.Join(cacheDir, digest)
+	if _, err := os.Stat(extractDir); err != nil {
+		if err := extractArchive(archivePath, extractDir); err != nil {
+			return "", err
+		}
+	}
+	return findPackRoot(extractDir)
+}
+
+// download fetches the archive (and signature, when a key is configured) into
+// the cache, replacing any previous copy only once both have been verified.
+func (s *RemoteSource) download(ctx context.Context, cacheDir, archivePath, sigPath string) (string, error) {
+	tmpArchive := archivePath + ".tmp"
+	tmpSig := sigPath + ".tmp"
+	defer os.Remove(tmpArchive)
+	defer os.Remove(tmpSig)
+
+	if err := s.get(ctx, s.URL, tmpArchive, maxRemoteArchiveSize); err != nil {
+		return "", err
+	}
+	if s.PublicKey != nil {
+		if err := s.get(ctx, s.URL+".sig", tmpSig, 4096); err != nil {
+			return "", fmt.Errorf("fetching signature: %w", err)
+		}
+	}
+
+	digest, err := fileSHA256(tmpArchive)
+	if err != nil {
+		return "", err
+	}
+	if err := s.verify(tmpArchive, tmpSig, digest); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(tmpArchive, archivePath); err != nil {
+		return "", fmt.Errorf("caching rule pack: %w", err)
+	}
+	if s.PublicKey != nil {
+		if err := os.Rename(tmpSig, sigPath); err != nil {
+			return "", fmt.Errorf("caching signature: %w", err)
+		}
+	}
+	return digest, nil
+}
+
+// get downloads url to path, refusing bodies larger than limit.
+func (s *RemoteSource) get(ctx context.Context, url, path string, limit int64) error {
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetching %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching %s: HTTP %d", url, resp.StatusCode)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, io.LimitReader(resp.Body, limit+1))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("downloading %s: %w", url, err)
+	}
+	if n > limit {
+		return fmt.Errorf("downloading %s: response exceeds %d bytes", url, limit)
+	}
+	return nil
+}
+
+// verify checks the archive against the pinned digest and signature.
+func (s *RemoteSource) verify(archivePath, sigPath, digest string) error {
+	if s.SHA256 != "" && !strings.EqualFold(digest, s.SHA256) {
+		return fmt.Errorf("rule pack sha256 mismatch: got %s, want %s", digest, s.SHA256)
+	}
+	if s.PublicKey == nil {
+		return nil
+	}
+
+	sigData, err := os.ReadFile(sigPath)
+	if err != nil {
+		return fmt.Errorf("reading signature: %w", err)
+	}
+	sig := sigData
+	if len(sig) != ed25519.SignatureSize {
+		if sig, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(sigData))); err != nil {
+			return fmt.Errorf("signature must be raw or base64-encoded Ed25519")
+		}
+	}
+	data, err := os.ReadFile(archivePath)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(s.PublicKey, data, sig) {
+		return errors.New("rule pack signature verification failed")
+	}
+	return nil
+}
+
+func (s *RemoteSource) warn(format string, args ...any) {
+	if s.WarnFunc != nil {
+		s.WarnFunc(format, args...)
+	}
+}
+
+// fileSHA256 returns the hex SHA-256 digest of the file at path.
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// extractArchive unpacks the regular files of a .tar.gz into dest. Entries
+// that would land outside dest are rejected. The archive is extracted into a
+// temporary directory first so a failed extraction never leaves a partial pack.
+func extractArchive(archivePath, dest string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("reading rule pack archive: %w", err)
+	}
+	defer gz.Close()
+
+	tmp, err := os.MkdirTemp(filepath.Dir(dest), "extract-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	var total int64
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("reading rule pack archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := filepath.Clean(filepath.FromSlash(hdr.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("rule pack archive entry %q escapes the pack directory", hdr.Name)
+		}
+		total += hdr.Size
+		if total > maxRemoteExtractSize {
+			return fmt.Errorf("rule pack archive exceeds %d bytes uncompressed", maxRemoteExtractSize)
+		}
+
+		target := filepath.Join(tmp, name)
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		out, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(out, io.LimitReader(tr, hdr.Size))
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("extracting %s: %w", hdr.Name, err)
+		}
+	}
+
+	return os.Rename(tmp, dest)
+}
+
+// findPackRoot returns dir if it holds a pack manifest, or its single
+// subdirectory that does.
+func findPackRoot(dir string) (string, error) {
+	if _, err := os.Stat(filepath.Join(dir, PackManifest)); err == nil {
+		return dir, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		sub := filepath.Join(dir, entries[0].Name())
+		if _, err := os.Stat(filepath.Join(sub, PackManifest)); err == nil {
+			return sub, nil
+		}
+	}
+	return "", fmt.Errorf("rule pack archive has no %s at its root", PackManifest)
+}
@@ -0,0 +1,179 @@
+package rule
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildPackArchive returns a .tar.gz holding files under prefix.
+func buildPackArchive(t *testing.T, prefix string, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: prefix + name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+var testPackFiles = map[string]string{
+	"pack.yml":  "name: remote\nversion: 1.2.0\n",
+	"rules.yml": "rules:\n  - name: Remote Token\n    id: remote.1\n    pattern: 'rmt_[a-z0-9]{32}'\n",
+}
+
+func servePack(t *testing.T, archive, sig []byte) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pack.tar.gz":
+			hits.Add(1)
+			w.Write(archive)
+		case "/pack.tar.gz.sig":
+			w.Write(sig)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func TestIsRemote(t *testing.T) {
+	assert.True(t, IsRemote("https://rules.example.com/pack.tar.gz"))
+	assert.True(t, IsRemote("http://localhost/pack.tar.gz"))
+	assert.False(t, IsRemote("rules/custom.yml"))
+}
+
+func TestRemoteSource_FetchAndCache(t *testing.T) {
+	archive := buildPackArchive(t, "remote-pack/", testPackFiles)
+	sum := sha256.Sum256(archive)
+	srv, hits := servePack(t, archive, nil)
+
+	src := &RemoteSource{URL: srv.URL + "/pack.tar.gz", Client: srv.Client(), SHA256: hex.EncodeToString(sum[:]), CacheDir: t.TempDir()}
+	dir, err := src.Fetch(context.Background())
+	require.NoError(t, err)
+
+	pack, err := NewLoader().LoadPackDir(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "remote", pack.Name)
+	require.Len(t, pack.Rules, 1)
+
+	// Pinned digest matching the cache: no second download, even if the server is gone
+	srv.Close()
+	dir2, err := src.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, dir, dir2)
+	assert.Equal(t, int32(1), hits.Load())
+}
+
+func TestRemoteSource_UnpinnedFallsBackToCache(t *testing.T) {
+	archive := buildPackArchive(t, "", testPackFiles)
+	srv, _ := servePack(t, archive, nil)
+
+	var warned bool
+	src := &RemoteSource{URL: srv.URL + "/pack.tar.gz", Client: srv.Client(), CacheDir: t.TempDir(),
+		WarnFunc: func(string, ...any) { warned = true }}
+	_, err := src.Fetch(context.Background())
+	require.NoError(t, err)
+
+	srv.Close()
+	_, err = src.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.True(t, warned)
+}
+
+func TestRemoteSource_SHA256Mismatch(t *testing.T) {
+	archive := buildPackArchive(t, "", testPackFiles)
+	srv, _ := servePack(t, archive, nil)
+
+	src := &RemoteSource{URL: srv.URL + "/pack.tar.gz", Client: srv.Client(), SHA256: hex.EncodeToString(make([]byte, 32)), CacheDir: t.TempDir()}
+	_, err := src.Fetch(context.Background())
+	assert.ErrorContains(t, err, "sha256 mismatch")
+}
+
+func TestRemoteSource_Signature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	archive := buildPackArchive(t, "", testPackFiles)
+	sig := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, archive)))
+
+	srv, _ := servePack(t, archive, sig)
+	src := &RemoteSource{URL: srv.URL + "/pack.tar.gz", Client: srv.Client(), PublicKey: pub, CacheDir: t.TempDir()}
+	_, err = src.Fetch(context.Background())
+	require.NoError(t, err)
+
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	src = &RemoteSource{URL: srv.URL + "/pack.tar.gz", Client: srv.Client(), PublicKey: otherPub, CacheDir: t.TempDir()}
+	_, err = src.Fetch(context.Background())
+	assert.ErrorContains(t, err, "signature verification failed")
+}
+
+func TestRemoteSource_RejectsPathTraversal(t *testing.T) {
+	archive := buildPackArchive(t, "../", testPackFiles)
+	srv, _ := servePack(t, archive, nil)
+
+	src := &RemoteSource{URL: srv.URL + "/pack.tar.gz", Client: srv.Client(), CacheDir: t.TempDir()}
+	_, err := src.Fetch(context.Background())
+	assert.ErrorContains(t, err, "escapes")
+}
+
+func TestRemoteSource_RejectsUnpinnedHTTP(t *testing.T) {
+	archive := buildPackArchive(t, "", testPackFiles)
+	sum := sha256.Sum256(archive)
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write(archive)
+	}))
+	t.Cleanup(srv.Close)
+
+	src := &RemoteSource{URL: srv.URL + "/pack.tar.gz", CacheDir: t.TempDir()}
+	_, err := src.Fetch(context.Background())
+	assert.ErrorContains(t, err, "plain HTTP")
+	assert.Equal(t, int32(0), hits.Load())
+
+	// A pinned digest makes plain HTTP safe
+	src.SHA256 = hex.EncodeToString(sum[:])
+	_, err = src.Fetch(context.Background())
+	require.NoError(t, err)
+}
+
+func TestParsePublicKey(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	fromPEM, err := ParsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	require.NoError(t, err)
+	assert.Equal(t, pub, fromPEM)
+
+	fromB64, err := ParsePublicKey([]byte(base64.StdEncoding.EncodeToString(pub)))
+	require.NoError(t, err)
+	assert.Equal(t, pub, fromB64)
+
+	_, err = ParsePublicKey([]byte("nope"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,47 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/matcher"
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertRuleExamples checks a builtin rule against its own examples and
+// negative examples.
+func assertRuleExamples(t *testing.T, ruleID string) {
+	t.Helper()
+	rules, err := NewLoader().LoadBuiltinRules()
+	require.NoError(t, err)
+
+	var r *types.Rule
+	for _, candidate := range rules {
+		if candidate.ID == ruleID {
+			r = candidate
+			break
+		}
+	}
+	require.NotNil(t, r, "%s rule should exist", ruleID)
+
+	m, err := matcher.New(matcher.Config{Rules: []*types.Rule{r}})
+	require.NoError(t, err)
+	defer m.Close()
+
+	for _, ex := range r.Examples {
+		matches, err := m.Match([]byte(ex))
+		require.NoError(t, err)
+		assert.NotEmpty(t, matches, "expected match for: %s", ex)
+	}
+	for _, ex := range r.NegativeExamples {
+		matches, err := m.Match([]byte(ex))
+		require.NoError(t, err)
+		assert.Empty(t, matches, "expected no match for: %s", ex)
+	}
+}
+
+func TestTerraformRule_Examples(t *testing.T) {
+	assertRuleExamples(t, "np.terraform.1")
+	assertRuleExamples(t, "np.terraform.2")
+}
@@ -3,8 +3,10 @@ package rule
 import (
 	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/praetorian-inc/titus/pkg/verifier"
 )
 
 // ValidateRule checks rule consistency and required fields.
@@ -24,6 +26,22 @@ func ValidateRule(r *types.Rule) error {
 	if r.Pattern == "" {
 		return fmt.Errorf("rule pattern is required")
 	}
+	switch r.Kind {
+	case types.RuleKindContent, types.RuleKindFilename, types.RuleKindPII:
+	default:
+		return fmt.Errorf("rule %s has unknown kind %q", r.ID, r.Kind)
+	}
+	if r.Kind == types.RuleKindFilename && r.Correlation != nil {
+		return fmt.Errorf("filename rule %s cannot be correlated", r.ID)
+	}
+	if r.Verify != "" {
+		if _, ok := verifier.Lookup(r.Verify); !ok {
+			return fmt.Errorf("rule %s has unknown verifier %q (known: %s)", r.ID, r.Verify, strings.Join(verifier.Names(), ", "))
+		}
+	}
+	if r.MinMatches < 0 {
+		return fmt.Errorf("rule %s min_matches must not be negative", r.ID)
+	}
 
 	// Validate pattern is a valid regex
 	_, err := regexp.Compile(r.Pattern)
@@ -31,6 +49,25 @@ func ValidateRule(r *types.Rule) error {
 		return fmt.Errorf("invalid pattern regex for rule %s: %w", r.ID, err)
 	}
 
+	// Validate correlation components
+	if r.Correlation != nil {
+		if len(r.Correlation.Components) < 2 {
+			return fmt.Errorf("rule %s correlation needs at least two components", r.ID)
+		}
+		for _, c := range r.Correlation.Components {
+			if c.Group == "" || len(c.RuleIDs) == 0 {
+				return fmt.Errorf("rule %s correlation components need a group and at least one rule", r.ID)
+			}
+		}
+	}
+
+	// Validate context overrides
+	if c := r.Context; c != nil {
+		if (c.Lines != nil && *c.Lines < 0) || (c.Bytes != nil && *c.Bytes < 0) {
+			return fmt.Errorf("rule %s context lines and bytes must not be negative", r.ID)
+		}
+	}
+
 	// Validate StructuralID matches computed value
 	expectedID := r.ComputeStructuralID()
 	if r.StructuralID != "" && r.StructuralID != expectedID {
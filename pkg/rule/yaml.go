@@ -1,5 +1,7 @@
 package rule
 
+import "github.com/praetorian-inc/titus/pkg/types"
+
 // yamlPatternRequirements is the intermediate struct for parsing pattern requirements.
 type yamlPatternRequirements struct {
 	MinDigits        int      `yaml:"min_digits,omitempty"`
@@ -10,19 +12,77 @@ type yamlPatternRequirements struct {
 	IgnoreIfContains []string `yaml:"ignore_if_contains,omitempty"`
 }
 
+// yamlGenericSpec is the intermediate struct for parsing a generic credential spec.
+type yamlGenericSpec struct {
+	KeySuffixes []string `yaml:"key_suffixes,omitempty"`
+	Binders     []string `yaml:"binders,omitempty"`
+	Quotes      string   `yaml:"quotes,omitempty"`
+	MinLength   int      `yaml:"min_length,omitempty"`
+	MaxLength   int      `yaml:"max_length,omitempty"`
+}
+
+// yamlCorrelation is the intermediate struct for parsing a rule's correlate block.
+type yamlCorrelation struct {
+	MaxDistance int                        `yaml:"max_distance,omitempty"`
+	Components  []yamlCorrelationComponent `yaml:"components"`
+}
+
+// yamlCorrelationComponent is one component of a correlate block.
+type yamlCorrelationComponent struct {
+	Group string   `yaml:"group"`
+	Rules []string `yaml:"rules"`
+}
+
+// yamlContext is the intermediate struct for parsing a rule's context block.
+type yamlContext struct {
+	Lines *int `yaml:"lines,omitempty"`
+	Bytes *int `yaml:"bytes,omitempty"`
+}
+
+// yamlValidation is the intermediate struct for parsing a rule's validation
+// block. Only Http validations are used; others name checks titus doesn't
+// have, such as MongoDB logins.
+type yamlValidation struct {
+	Type        string `yaml:"type"`
+	SecretGroup string `yaml:"secret_group,omitempty"`
+	Content     struct {
+		Request yamlValidationRequest `yaml:"request"`
+	} `yaml:"content"`
+}
+
+// yamlValidationRequest is the request of an Http validation block. Other
+// keys, such as digest for digest authentication, go in Unsupported.
+type yamlValidationRequest struct {
+	Method          string                  `yaml:"method"`
+	URL             string                  `yaml:"url"`
+	Headers         map[string]string       `yaml:"headers,omitempty"`
+	Body            string                  `yaml:"body,omitempty"`
+	ResponseMatcher []types.ResponseMatcher `yaml:"response_matcher,omitempty"`
+	TimeoutSeconds  int                     `yaml:"timeout_seconds,omitempty"`
+	Unsupported     map[string]any          `yaml:",inline"`
+}
+
 // yamlRule is the intermediate struct for parsing NoseyParker YAML rule format.
 // Maps YAML fields to types.Rule structure.
 type yamlRule struct {
 	Name                string                   `yaml:"name"`
 	ID                  string                   `yaml:"id"`
+	Kind                string                   `yaml:"kind,omitempty"`
 	Pattern             string                   `yaml:"pattern"`
 	Description         string                   `yaml:"description,omitempty"`
 	Examples            []string                 `yaml:"examples,omitempty"`
 	NegativeExamples    []string                 `yaml:"negative_examples,omitempty"`
 	References          []string                 `yaml:"references,omitempty"`
 	Categories          []string                 `yaml:"categories,omitempty"`
+	Tags                []string                 `yaml:"tags,omitempty"`
 	MinEntropy          float64                  `yaml:"min_entropy,omitempty"`
 	PatternRequirements *yamlPatternRequirements `yaml:"pattern_requirements,omitempty"`
+	Verify              string                   `yaml:"verify,omitempty"`
+	MinMatches          int                      `yaml:"min_matches,omitempty"`
+	Generic             *yamlGenericSpec         `yaml:"generic,omitempty"`
+	Correlate           *yamlCorrelation         `yaml:"correlate,omitempty"`
+	Context             *yamlContext             `yaml:"context,omitempty"`
+	Validation          *yamlValidation          `yaml:"validation,omitempty"`
 }
 
 // yamlRulesFile represents the top-level structure of a rules YAML file.
@@ -43,3 +103,17 @@ type yamlRuleset struct {
 type yamlRulesetsFile struct {
 	Rulesets []yamlRuleset `yaml:"rulesets"`
 }
+
+// yamlPack is the intermediate struct for parsing a rule pack definition.
+type yamlPack struct {
+	Name        string     `yaml:"name"`
+	Version     string     `yaml:"version"`
+	Description string     `yaml:"description,omitempty"`
+	RuleIDs     []string   `yaml:"include_rule_ids,omitempty"`
+	Rules       []yamlRule `yaml:"rules,omitempty"` // rules of a built-in pack that aren't built-in rules
+}
+
+// yamlPacksFile represents the top-level structure of the built-in packs manifest.
+type yamlPacksFile struct {
+	Packs []yamlPack `yaml:"packs"`
+}
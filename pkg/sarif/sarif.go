@@ -56,10 +56,11 @@ type ShortDescription struct {
 
 // Result represents a single finding
 type Result struct {
-	RuleID    string     `json:"ruleId"`
-	Level     string     `json:"level"`
-	Message   Message    `json:"message"`
-	Locations []Location `json:"locations"`
+	RuleID     string         `json:"ruleId"`
+	Level      string         `json:"level"`
+	Message    Message        `json:"message"`
+	Locations  []Location     `json:"locations"`
+	Properties map[string]any `json:"properties,omitempty"`
 }
 
 // Message contains the result message
@@ -135,11 +136,11 @@ func (r *Report) AddRule(rule *types.Rule) {
 	r.Runs[0].Tool.Driver.Rules = append(r.Runs[0].Tool.Driver.Rules, sarifRule)
 }
 
-// AddResult adds a finding result to the report
-func (r *Report) AddResult(match *types.Match, filePath string) {
-	// Convert file path to URI format
-	uri := formatFileURI(filePath)
-
+// AddResult adds a finding result to the report, with one location per
+// file path its blob was found at. When the match's LocationCount says the
+// blob was found in more places than are given, the total is recorded in the
+// location_count property.
+func (r *Report) AddResult(match *types.Match, filePaths ...string) {
 	// Create region with line/column information
 	region := Region{
 		StartLine:   match.Location.Source.Start.Line,
@@ -161,16 +162,39 @@ func (r *Report) AddResult(match *types.Match, filePath string) {
 		Message: Message{
 			Text: match.RuleName,
 		},
-		Locations: []Location{
-			{
-				PhysicalLocation: PhysicalLocation{
-					ArtifactLocation: ArtifactLocation{
-						URI: uri,
-					},
-					Region: region,
+		Locations: []Location{},
+	}
+	for _, filePath := range filePaths {
+		result.Locations = append(result.Locations, Location{
+			PhysicalLocation: PhysicalLocation{
+				ArtifactLocation: ArtifactLocation{
+					// Convert file path to URI format
+					URI: formatFileURI(filePath),
 				},
+				Region: region,
 			},
-		},
+		})
+	}
+
+	// Matches that look like test or example data are downgraded to notes
+	if match.LikelyFP {
+		result.Level = "note"
+		result.Properties = map[string]any{
+			"likely_fp":  true,
+			"fp_reasons": match.FPReasons,
+		}
+	}
+
+	// Secrets validation confirmed live are errors, whatever their context
+	if match.Active() {
+		result.Level = "error"
+	}
+
+	if match.LocationCount > len(filePaths) {
+		if result.Properties == nil {
+			result.Properties = map[string]any{}
+		}
+		result.Properties["location_count"] = match.LocationCount
 	}
 
 	r.Runs[0].Results = append(r.Runs[0].Results, result)
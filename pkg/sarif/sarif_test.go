@@ -80,6 +80,44 @@ func TestAddResult(t *testing.T) {
 	assert.Equal(t, 25, location.PhysicalLocation.Region.EndColumn)
 }
 
+func TestAddResult_LikelyFPDowngraded(t *testing.T) {
+	report := NewReport()
+
+	match := &types.Match{
+		RuleID:    "np.aws.1",
+		RuleName:  "AWS API Key",
+		LikelyFP:  true,
+		FPReasons: []string{"path is under testdata/"},
+	}
+	report.AddResult(match, "testdata/creds.txt")
+
+	result := report.Runs[0].Results[0]
+	assert.Equal(t, "note", result.Level)
+	assert.Equal(t, true, result.Properties["likely_fp"])
+	assert.Equal(t, []string{"path is under testdata/"}, result.Properties["fp_reasons"])
+}
+
+func TestAddResult_MultipleLocations(t *testing.T) {
+	report := NewReport()
+
+	match := &types.Match{
+		RuleID:        "np.aws.1",
+		RuleName:      "AWS API Key",
+		LocationCount: 3,
+	}
+	report.AddResult(match, "a/creds.txt", "b/creds.txt")
+
+	result := report.Runs[0].Results[0]
+	require.Len(t, result.Locations, 2)
+	assert.Equal(t, "a/creds.txt", result.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, "b/creds.txt", result.Locations[1].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, 3, result.Properties["location_count"])
+
+	match.LocationCount = 1
+	report.AddResult(match, "a/creds.txt")
+	assert.Nil(t, report.Runs[0].Results[1].Properties)
+}
+
 func TestToJSON(t *testing.T) {
 	report := NewReport()
 
@@ -208,3 +246,14 @@ func TestSnippetInRegion(t *testing.T) {
 	assert.NotNil(t, region.Snippet)
 	assert.Equal(t, "SECRET_VALUE_HERE", region.Snippet.Text)
 }
+
+func TestAddResult_Active(t *testing.T) {
+	report := NewReport()
+	match := &types.Match{
+		RuleID:           "np.aws.1",
+		LikelyFP:         true,
+		ValidationResult: types.NewValidationResult(types.StatusValid, 1, ""),
+	}
+	report.AddResult(match, "config.env")
+	assert.Equal(t, "error", report.Runs[0].Results[0].Level, "a live secret is an error even in test-looking data")
+}
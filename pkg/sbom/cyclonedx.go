@@ -0,0 +1,172 @@
+// Package sbom writes findings in software bill of materials formats, so
+// secret-scan results can travel through the same pipelines as SBOMs:
+// CycloneDX, with each finding as a data component and the places it was
+// found as evidence, and SPDX, with each match as a snippet of the file it
+// was found in.
+//
+// Neither format carries secret values; findings are identified by rule
+// and finding ID.
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// CycloneDX 1.6 constants
+const (
+	CycloneDXFormat      = "CycloneDX"
+	CycloneDXSpecVersion = "1.6"
+	CycloneDXSchemaURI   = "http://cyclonedx.org/schema/bom-1.6.schema.json"
+)
+
+// ToolName names titus as the tool that produced a document.
+const ToolName = "titus"
+
+// BOM is a CycloneDX bill of materials.
+type BOM struct {
+	Schema       string      `json:"$schema"`
+	BOMFormat    string      `json:"bomFormat"`
+	SpecVersion  string      `json:"specVersion"`
+	SerialNumber string      `json:"serialNumber"`
+	Version      int         `json:"version"`
+	Metadata     BOMMetadata `json:"metadata"`
+	Components   []Component `json:"components"`
+}
+
+// BOMMetadata describes when and by what the BOM was produced.
+type BOMMetadata struct {
+	Timestamp string   `json:"timestamp"`
+	Tools     BOMTools `json:"tools"`
+}
+
+// BOMTools lists the tools that produced the BOM.
+type BOMTools struct {
+	Components []Component `json:"components"`
+}
+
+// Component is a CycloneDX component. Findings are components of type data.
+type Component struct {
+	Type        string     `json:"type"`
+	BOMRef      string     `json:"bom-ref,omitempty"`
+	Name        string     `json:"name"`
+	Version     string     `json:"version,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Properties  []Property `json:"properties,omitempty"`
+	Evidence    *Evidence  `json:"evidence,omitempty"`
+}
+
+// Property is a name-value pair. Titus properties are prefixed "titus:".
+type Property struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Evidence records where a component was found.
+type Evidence struct {
+	Occurrences []Occurrence `json:"occurrences"`
+}
+
+// Occurrence is one place a finding was found.
+type Occurrence struct {
+	Location          string `json:"location"`
+	Line              int    `json:"line,omitempty"`
+	Offset            int64  `json:"offset,omitempty"`
+	AdditionalContext string `json:"additionalContext,omitempty"`
+}
+
+// NewCycloneDX creates an empty BOM produced by titus at the given version.
+func NewCycloneDX(toolVersion string, timestamp time.Time) *BOM {
+	return &BOM{
+		Schema:       CycloneDXSchemaURI,
+		BOMFormat:    CycloneDXFormat,
+		SpecVersion:  CycloneDXSpecVersion,
+		SerialNumber: "urn:uuid:" + uuid.NewString(),
+		Version:      1,
+		Metadata: BOMMetadata{
+			Timestamp: timestamp.UTC().Format(time.RFC3339),
+			Tools: BOMTools{
+				Components: []Component{{Type: "application", Name: ToolName, Version: toolVersion}},
+			},
+		},
+		Components: []Component{},
+	}
+}
+
+// AddFinding adds a finding as a data component, with an occurrence for
+// each location of each of its matches. rule may be nil.
+func (b *BOM) AddFinding(f *types.Finding, rule *types.Rule, matches []*types.Match) {
+	c := Component{
+		Type:   "data",
+		BOMRef: "finding:" + f.ID,
+		Name:   f.RuleID,
+		Properties: []Property{
+			{Name: "titus:finding_id", Value: f.ID},
+			{Name: "titus:rule_id", Value: f.RuleID},
+		},
+	}
+	if rule != nil {
+		c.Name = rule.Name
+		c.Description = rule.Description
+	}
+	if f.State != "" {
+		c.Properties = append(c.Properties, Property{Name: "titus:state", Value: string(f.State)})
+	}
+	if status := validationStatus(matches); status != "" {
+		c.Properties = append(c.Properties, Property{Name: "titus:validation_status", Value: status})
+	}
+
+	var occurrences []Occurrence
+	for _, m := range matches {
+		for _, loc := range matchLocations(m) {
+			occ := Occurrence{
+				Location: loc.Path,
+				Line:     m.Location.Source.Start.Line,
+				Offset:   m.Location.Offset.Start,
+			}
+			if loc.Commit != "" {
+				occ.AdditionalContext = fmt.Sprintf("repository %s, commit %s", loc.Repository, loc.Commit)
+			}
+			occurrences = append(occurrences, occ)
+		}
+	}
+	if len(occurrences) > 0 {
+		c.Evidence = &Evidence{Occurrences: occurrences}
+	}
+
+	b.Components = append(b.Components, c)
+}
+
+// ToJSON serializes the BOM to JSON bytes.
+func (b *BOM) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(b, "", "  ")
+}
+
+// matchLocations returns the places a match's blob was found, falling back
+// to its blob ID when they are unknown.
+func matchLocations(m *types.Match) []types.BlobLocation {
+	if len(m.Locations) > 0 {
+		return m.Locations
+	}
+	return []types.BlobLocation{{Kind: "blob", Path: m.BlobID.Hex()}}
+}
+
+// validationStatus returns "valid" if any match's secret was confirmed
+// live, and otherwise the status of the first validated match.
+func validationStatus(matches []*types.Match) string {
+	for _, m := range matches {
+		if m.Active() {
+			return string(types.StatusValid)
+		}
+	}
+	for _, m := range matches {
+		if m.ValidationResult != nil {
+			return string(m.ValidationResult.Status)
+		}
+	}
+	return ""
+}
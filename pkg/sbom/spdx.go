@@ -0,0 +1,187 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// SPDX 2.3 constants
+const (
+	SPDXVersion     = "SPDX-2.3"
+	SPDXDataLicense = "CC0-1.0"
+	SPDXDocumentID  = "SPDXRef-DOCUMENT"
+	SPDXNoAssertion = "NOASSERTION"
+)
+
+// SPDXDocument is an SPDX document listing the files secrets were found in
+// and a snippet for each match.
+type SPDXDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      SPDXCreationInfo   `json:"creationInfo"`
+	Files             []SPDXFile         `json:"files"`
+	Snippets          []SPDXSnippet      `json:"snippets"`
+	Relationships     []SPDXRelationship `json:"relationships"`
+
+	fileIDs map[string]string // location key -> file SPDXID
+}
+
+// SPDXCreationInfo records when and by what the document was created.
+type SPDXCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+// SPDXFile is a file a secret was found in. SPDX requires a SHA1 checksum
+// of each file; titus identifies file content by its git blob ID, the SHA-1
+// of the content after a "blob <size>\x00" header, so that is given, and
+// the comment says so.
+type SPDXFile struct {
+	SPDXID           string         `json:"SPDXID"`
+	FileName         string         `json:"fileName"`
+	Checksums        []SPDXChecksum `json:"checksums"`
+	LicenseConcluded string         `json:"licenseConcluded"`
+	CopyrightText    string         `json:"copyrightText"`
+	Comment          string         `json:"comment,omitempty"`
+}
+
+// SPDXChecksum is a checksum of a file.
+type SPDXChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+// SPDXSnippet is the part of a file a match covers.
+type SPDXSnippet struct {
+	SPDXID           string             `json:"SPDXID"`
+	SnippetFromFile  string             `json:"snippetFromFile"`
+	Ranges           []SPDXSnippetRange `json:"ranges"`
+	Name             string             `json:"name"`
+	LicenseConcluded string             `json:"licenseConcluded"`
+	CopyrightText    string             `json:"copyrightText"`
+	Comment          string             `json:"comment"`
+}
+
+// SPDXSnippetRange is a byte or line range within a file.
+type SPDXSnippetRange struct {
+	StartPointer SPDXPointer `json:"startPointer"`
+	EndPointer   SPDXPointer `json:"endPointer"`
+}
+
+// SPDXPointer is a 1-based byte offset or line number in a file.
+type SPDXPointer struct {
+	Reference  string `json:"reference"`
+	Offset     int64  `json:"offset,omitempty"`
+	LineNumber int    `json:"lineNumber,omitempty"`
+}
+
+// SPDXRelationship relates two SPDX elements.
+type SPDXRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// NewSPDX creates an empty SPDX document created by titus at the given
+// version.
+func NewSPDX(toolVersion string, created time.Time) *SPDXDocument {
+	return &SPDXDocument{
+		SPDXVersion:       SPDXVersion,
+		DataLicense:       SPDXDataLicense,
+		SPDXID:            SPDXDocumentID,
+		Name:              "titus-secrets",
+		DocumentNamespace: "https://spdx.org/spdxdocs/titus-secrets-" + uuid.NewString(),
+		CreationInfo: SPDXCreationInfo{
+			Created:  created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: " + ToolName + "-" + toolVersion},
+		},
+		Files:         []SPDXFile{},
+		Snippets:      []SPDXSnippet{},
+		Relationships: []SPDXRelationship{},
+		fileIDs:       make(map[string]string),
+	}
+}
+
+// AddFinding adds a snippet for each location of each of a finding's
+// matches, adding the files they are in as needed. rule may be nil.
+func (d *SPDXDocument) AddFinding(f *types.Finding, rule *types.Rule, matches []*types.Match) {
+	name := f.RuleID
+	if rule != nil {
+		name = rule.Name
+	}
+	comment := fmt.Sprintf("titus finding %s, rule %s", f.ID, f.RuleID)
+	if status := validationStatus(matches); status != "" {
+		comment += ", validation " + status
+	}
+
+	for _, m := range matches {
+		for _, loc := range matchLocations(m) {
+			fileID := d.file(loc, m.BlobID)
+			snippet := SPDXSnippet{
+				SPDXID:          fmt.Sprintf("SPDXRef-Snippet-%d", len(d.Snippets)+1),
+				SnippetFromFile: fileID,
+				Ranges: []SPDXSnippetRange{{
+					StartPointer: SPDXPointer{Reference: fileID, Offset: m.Location.Offset.Start + 1},
+					EndPointer:   SPDXPointer{Reference: fileID, Offset: max(m.Location.Offset.End, m.Location.Offset.Start+1)},
+				}},
+				Name:             name,
+				LicenseConcluded: SPDXNoAssertion,
+				CopyrightText:    SPDXNoAssertion,
+				Comment:          comment,
+			}
+			if start := m.Location.Source.Start.Line; start > 0 {
+				snippet.Ranges = append(snippet.Ranges, SPDXSnippetRange{
+					StartPointer: SPDXPointer{Reference: fileID, LineNumber: start},
+					EndPointer:   SPDXPointer{Reference: fileID, LineNumber: max(m.Location.Source.End.Line, start)},
+				})
+			}
+			d.Snippets = append(d.Snippets, snippet)
+		}
+	}
+}
+
+// file returns the SPDXID of the file at loc, adding it if needed.
+func (d *SPDXDocument) file(loc types.BlobLocation, blob types.BlobID) string {
+	key := loc.String() + "\x00" + blob.Hex()
+	if id, ok := d.fileIDs[key]; ok {
+		return id
+	}
+
+	id := fmt.Sprintf("SPDXRef-File-%d", len(d.Files)+1)
+	comment := "git blob " + blob.Hex()
+	if loc.Commit != "" {
+		comment += fmt.Sprintf(" in repository %s, commit %s", loc.Repository, loc.Commit)
+	}
+	fileName := loc.Path
+	if !strings.HasPrefix(fileName, "/") && !strings.HasPrefix(fileName, "./") {
+		fileName = "./" + fileName
+	}
+	d.Files = append(d.Files, SPDXFile{
+		SPDXID:           id,
+		FileName:         fileName,
+		Checksums:        []SPDXChecksum{{Algorithm: "SHA1", ChecksumValue: blob.Hex()}},
+		LicenseConcluded: SPDXNoAssertion,
+		CopyrightText:    SPDXNoAssertion,
+		Comment:          comment,
+	})
+	d.Relationships = append(d.Relationships, SPDXRelationship{
+		SPDXElementID:      SPDXDocumentID,
+		RelationshipType:   "DESCRIBES",
+		RelatedSPDXElement: id,
+	})
+	d.fileIDs[key] = id
+	return id
+}
+
+// ToJSON serializes the document to JSON bytes.
+func (d *SPDXDocument) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
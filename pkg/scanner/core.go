@@ -28,9 +28,11 @@ func loadBuiltinRulesCached() ([]*types.Rule, error) {
 
 // Core wraps the matcher and store for scanning operations
 type Core struct {
-	matcher matcher.Matcher
-	store   store.Store
-	logger  DebugLogger
+	mu          sync.RWMutex // guards matcher and canValidate
+	matcher     matcher.Matcher
+	canValidate func(ruleID string) bool
+	store       store.Store
+	logger      DebugLogger
 }
 
 // NewCore creates a new Core scanner with the given rules
@@ -66,10 +68,7 @@ func NewCore(rulesJSON string, logger DebugLogger) (*Core, error) {
 
 	// Create matcher
 	logger.Log("Creating matcher with %d rules...", len(rules))
-	m, err := matcher.New(matcher.Config{
-		Rules:        rules,
-		ContextLines: 2,
-	})
+	m, err := NewMatcher(rules, nil)
 	if err != nil {
 		logger.Log("matcher.New failed: %v", err)
 		return nil, err
@@ -96,6 +95,8 @@ func NewCore(rulesJSON string, logger DebugLogger) (*Core, error) {
 
 // Scan scans a single content string
 func (c *Core) Scan(content, source string) (*ScanResult, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	matches, err := c.matcher.Match([]byte(content))
 	if err != nil {
 		return nil, err
@@ -109,6 +110,8 @@ func (c *Core) Scan(content, source string) (*ScanResult, error) {
 
 // ScanBatch scans multiple content items
 func (c *Core) ScanBatch(items []ContentItem) (*BatchScanResult, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	var results []ScanResult
 	total := 0
 
@@ -134,6 +137,8 @@ func (c *Core) ScanBatch(items []ContentItem) (*BatchScanResult, error) {
 
 // Close releases scanner resources
 func (c *Core) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.matcher != nil {
 		c.matcher.Close()
 	}
@@ -147,9 +152,28 @@ func (c *Core) Close() {
 // prefer rules that have validators during cross-rule tie-breaking.
 // Passing nil reverts to treating all rules as having no validator.
 func (c *Core) SetCanValidate(fn func(ruleID string) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.canValidate = fn
 	matcher.SetCanValidate(c.matcher, fn)
 }
 
+// SetMatcher replaces the matcher, for example with one compiled from
+// reloaded rules, and closes the old one. Scans in progress finish with the
+// old matcher; later scans use m. Any SetCanValidate function carries over.
+func (c *Core) SetMatcher(m matcher.Matcher) {
+	c.mu.Lock()
+	if c.canValidate != nil {
+		matcher.SetCanValidate(m, c.canValidate)
+	}
+	old := c.matcher
+	c.matcher = m
+	c.mu.Unlock()
+	if old != nil {
+		old.Close()
+	}
+}
+
 // NewCoreWithRules creates a new Core scanner with pre-loaded rules.
 // This avoids JSON round-tripping when the caller already has []*types.Rule.
 func NewCoreWithRules(rules []*types.Rule, logger DebugLogger, warnFunc func(string, ...any)) (*Core, error) {
@@ -159,11 +183,7 @@ func NewCoreWithRules(rules []*types.Rule, logger DebugLogger, warnFunc func(str
 
 	logger.Log("NewCoreWithRules starting with %d rules...", len(rules))
 
-	m, err := matcher.New(matcher.Config{
-		Rules:        rules,
-		ContextLines: 2,
-		WarnFunc:     warnFunc,
-	})
+	m, err := NewMatcher(rules, warnFunc)
 	if err != nil {
 		logger.Log("matcher.New failed: %v", err)
 		return nil, err
@@ -186,6 +206,16 @@ func NewCoreWithRules(rules []*types.Rule, logger DebugLogger, warnFunc func(str
 	}, nil
 }
 
+// NewMatcher compiles rules into a matcher configured like a Core's, for
+// use with SetMatcher.
+func NewMatcher(rules []*types.Rule, warnFunc func(string, ...any)) (matcher.Matcher, error) {
+	return matcher.New(matcher.Config{
+		Rules:        rules,
+		ContextLines: 2,
+		WarnFunc:     warnFunc,
+	})
+}
+
 // GetBuiltinRules returns the built-in rules (cached)
 func GetBuiltinRules() ([]*types.Rule, error) {
 	return loadBuiltinRulesCached()
@@ -0,0 +1,26 @@
+package scanner
+
+import (
+	"fmt"
+
+	"github.com/praetorian-inc/titus/pkg/extract"
+)
+
+// ScanDocument extracts the text of a document or archive, such as a file
+// downloaded in the browser, and scans each part. Each result's source is
+// name followed by the member it came from, e.g. "report.docx:word/document.xml".
+func (c *Core) ScanDocument(name string, content []byte) (*BatchScanResult, error) {
+	parts, err := extract.Text(name, content, extract.DefaultLimits())
+	if err != nil {
+		return nil, fmt.Errorf("extracting %s: %w", name, err)
+	}
+
+	items := make([]ContentItem, 0, len(parts))
+	for _, p := range parts {
+		items = append(items, ContentItem{
+			Source:  name + ":" + p.Name,
+			Content: string(p.Content),
+		})
+	}
+	return c.ScanBatch(items)
+}
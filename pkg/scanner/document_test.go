@@ -0,0 +1,37 @@
+//go:build !wasm
+
+package scanner
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCore_ScanDocument(t *testing.T) {
+	rules := []*types.Rule{{ID: "test.secret", Name: "Secret", Pattern: `(secret_[a-z0-9]{10})`}}
+	core, err := NewCore(buildRulesJSON(rules), nil)
+	require.NoError(t, err)
+	defer core.Close()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.Create("config/app.env")
+	require.NoError(t, err)
+	_, err = f.Write([]byte("TOKEN=secret_abcdef1234"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	result, err := core.ScanDocument("download.zip", buf.Bytes())
+	require.NoError(t, err)
+	require.Len(t, result.Results, 1)
+	assert.Equal(t, "download.zip:config/app.env", result.Results[0].Source)
+	assert.Equal(t, 1, result.Total)
+
+	_, err = core.ScanDocument("report.pdf", []byte("%PDF"))
+	assert.Error(t, err)
+}
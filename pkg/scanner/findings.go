@@ -0,0 +1,104 @@
+package scanner
+
+import (
+	"sync"
+
+	"github.com/praetorian-inc/titus/pkg/sarif"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// Finding is a secret found during a browsing session, with every page it
+// was found on.
+type Finding struct {
+	FindingID string       `json:"finding_id"`
+	RuleID    string       `json:"rule_id"`
+	RuleName  string       `json:"rule_name"`
+	Match     *types.Match `json:"match"`   // the first match, updated with any later validation result
+	URLs      []string     `json:"urls"`    // pages it was found on, in the order found
+	Sources   []string     `json:"sources"` // content it was found in, e.g. "script:external:https://..."
+	Count     int          `json:"count"`   // how many times it was matched
+}
+
+// Findings accumulates the results of scans across pages, grouping matches
+// of the same secret by finding ID. It is safe for concurrent use.
+type Findings struct {
+	mu    sync.Mutex
+	order []*Finding
+	byID  map[string]*Finding
+}
+
+// NewFindings creates an empty findings buffer.
+func NewFindings() *Findings {
+	return &Findings{byID: make(map[string]*Finding)}
+}
+
+// Add records the matches of scan results from the page at url.
+func (f *Findings) Add(url string, results []ScanResult) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, r := range results {
+		for _, m := range r.Matches {
+			id := m.FindingID
+			if id == "" {
+				id = types.ComputeFindingID(m.RuleID, m.Groups)
+			}
+			finding, ok := f.byID[id]
+			if !ok {
+				finding = &Finding{FindingID: id, RuleID: m.RuleID, RuleName: m.RuleName, Match: m}
+				f.byID[id] = finding
+				f.order = append(f.order, finding)
+			} else if finding.Match.ValidationResult == nil && m.ValidationResult != nil {
+				finding.Match.ValidationResult = m.ValidationResult
+			}
+			finding.URLs = appendUnique(finding.URLs, url)
+			finding.Sources = appendUnique(finding.Sources, r.Source)
+			finding.Count++
+		}
+	}
+}
+
+// List returns the findings in the order they were first found.
+func (f *Findings) List() []*Finding {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*Finding(nil), f.order...)
+}
+
+// Clear discards all findings.
+func (f *Findings) Clear() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.order = nil
+	f.byID = make(map[string]*Finding)
+}
+
+// SARIF returns a SARIF report with one result per finding, located at
+// each page it was found on. Rules are described from rules where present.
+func (f *Findings) SARIF(rules map[string]*types.Rule) *sarif.Report {
+	report := sarif.NewReport()
+	added := make(map[string]bool)
+	for _, finding := range f.List() {
+		if !added[finding.RuleID] {
+			added[finding.RuleID] = true
+			rule, ok := rules[finding.RuleID]
+			if !ok {
+				rule = &types.Rule{ID: finding.RuleID, Name: finding.RuleName}
+			}
+			report.AddRule(rule)
+		}
+		report.AddResult(finding.Match, finding.URLs...)
+	}
+	return report
+}
+
+func appendUnique(list []string, s string) []string {
+	if s == "" {
+		return list
+	}
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
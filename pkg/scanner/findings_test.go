@@ -0,0 +1,48 @@
+//go:build !wasm
+
+package scanner
+
+import (
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindings(t *testing.T) {
+	rules := []*types.Rule{{ID: "test.secret", Name: "Secret", Pattern: `(secret_[a-z0-9]{10})`}}
+	core, err := NewCore(buildRulesJSON(rules), nil)
+	require.NoError(t, err)
+	defer core.Close()
+
+	scan := func(content, source string) []ScanResult {
+		result, err := core.Scan(content, source)
+		require.NoError(t, err)
+		return []ScanResult{*result}
+	}
+
+	findings := NewFindings()
+	findings.Add("https://app.example.com/", scan("key = 'secret_abcdef1234'", "script:inline:0"))
+	findings.Add("https://app.example.com/settings", scan("token=secret_abcdef1234", "localStorage"))
+	findings.Add("https://app.example.com/settings", scan("secret_zzzzzzzzzz", "localStorage"))
+
+	list := findings.List()
+	require.Len(t, list, 2)
+	assert.Equal(t, "test.secret", list[0].RuleID)
+	assert.Equal(t, []string{"https://app.example.com/", "https://app.example.com/settings"}, list[0].URLs)
+	assert.Equal(t, []string{"script:inline:0", "localStorage"}, list[0].Sources)
+	assert.Equal(t, 2, list[0].Count)
+	assert.Equal(t, []string{"https://app.example.com/settings"}, list[1].URLs)
+
+	report := findings.SARIF(map[string]*types.Rule{})
+	require.Len(t, report.Runs[0].Tool.Driver.Rules, 1)
+	assert.Equal(t, "Secret", report.Runs[0].Tool.Driver.Rules[0].Name)
+	require.Len(t, report.Runs[0].Results, 2)
+	locations := report.Runs[0].Results[0].Locations
+	require.Len(t, locations, 2)
+	assert.Equal(t, "https://app.example.com/", locations[0].PhysicalLocation.ArtifactLocation.URI)
+
+	findings.Clear()
+	assert.Empty(t, findings.List())
+}
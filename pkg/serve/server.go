@@ -5,7 +5,9 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"time"
 
+	"github.com/praetorian-inc/titus/pkg/metrics"
 	"github.com/praetorian-inc/titus/pkg/scanner"
 	"github.com/praetorian-inc/titus/pkg/types"
 	"github.com/praetorian-inc/titus/pkg/validator"
@@ -18,6 +20,7 @@ const Version = "1.0.0"
 type Server struct {
 	core      *scanner.Core
 	validator *validator.Engine
+	metrics   *metrics.Registry
 	encoder   *json.Encoder
 	decoder   *json.Decoder
 }
@@ -36,6 +39,11 @@ func (s *Server) SetValidator(v *validator.Engine) {
 	s.validator = v
 }
 
+// SetMetrics sets the registry that records scan and validation counters
+func (s *Server) SetMetrics(r *metrics.Registry) {
+	s.metrics = r
+}
+
 // Run starts the server main loop
 func (s *Server) Run(ctx context.Context) error {
 	// Send ready signal
@@ -123,11 +131,15 @@ func (s *Server) handleScan(payload json.RawMessage) {
 		return
 	}
 
+	start := time.Now()
 	result, err := s.core.Scan(p.Content, p.Source)
+	s.metrics.ObserveScan(time.Since(start), err != nil)
 	if err != nil {
 		s.sendError("scan", err.Error())
 		return
 	}
+	s.metrics.ObserveBlob(int64(len(p.Content)))
+	s.metrics.ObserveMatches(result.Matches)
 
 	data, _ := json.Marshal(result)
 	s.encoder.Encode(Response{
@@ -144,11 +156,19 @@ func (s *Server) handleScanBatch(payload json.RawMessage) {
 		return
 	}
 
+	start := time.Now()
 	result, err := s.core.ScanBatch(p.Items)
+	s.metrics.ObserveScan(time.Since(start), err != nil)
 	if err != nil {
 		s.sendError("scan_batch", err.Error())
 		return
 	}
+	for _, item := range p.Items {
+		s.metrics.ObserveBlob(int64(len(item.Content)))
+	}
+	for _, r := range result.Results {
+		s.metrics.ObserveMatches(r.Matches)
+	}
 
 	data, _ := json.Marshal(result)
 	s.encoder.Encode(Response{
@@ -193,6 +213,7 @@ func (s *Server) handleValidate(ctx context.Context, payload json.RawMessage) {
 		s.sendError("validate", err.Error())
 		return
 	}
+	s.metrics.ObserveValidation(validationResult.Status)
 
 	// Convert ValidationResult to ValidateResult
 	result := ValidateResult{
@@ -0,0 +1,88 @@
+package siem
+
+import "time"
+
+// ecsVersion is the Elastic Common Schema version events conform to.
+const ecsVersion = "8.11.0"
+
+type ecsEvent struct {
+	Timestamp string         `json:"@timestamp"`
+	Message   string         `json:"message"`
+	ECS       ecsVersionObj  `json:"ecs"`
+	Event     ecsEventObj    `json:"event"`
+	Rule      ecsRule        `json:"rule"`
+	Observer  ecsObserver    `json:"observer"`
+	File      *ecsFile       `json:"file,omitempty"`
+	Titus     map[string]any `json:"titus"`
+}
+
+type ecsVersionObj struct {
+	Version string `json:"version"`
+}
+
+type ecsEventObj struct {
+	Kind     string   `json:"kind"`
+	Category []string `json:"category"`
+	Type     []string `json:"type"`
+	Severity int      `json:"severity"`
+	Dataset  string   `json:"dataset"`
+}
+
+type ecsRule struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Ruleset string `json:"ruleset"`
+}
+
+type ecsObserver struct {
+	Vendor  string `json:"vendor"`
+	Product string `json:"product"`
+	Version string `json:"version"`
+}
+
+type ecsFile struct {
+	Path string `json:"path"`
+}
+
+// EncodeECS renders an event as an Elastic Common Schema JSON document.
+// Fields with no ECS counterpart, such as the finding and blob IDs, go in
+// the titus namespace. The blob ID is a git object ID rather than a hash of
+// the file content, so it is not mapped to file.hash.
+func EncodeECS(ev Event) string {
+	doc := ecsEvent{
+		Timestamp: ev.Time.UTC().Format(time.RFC3339Nano),
+		Message:   message(ev),
+		ECS:       ecsVersionObj{Version: ecsVersion},
+		Event: ecsEventObj{
+			Kind:     "alert",
+			Category: []string{"file"},
+			Type:     []string{"info"},
+			Severity: ev.Severity,
+			Dataset:  "titus.finding",
+		},
+		Rule:     ecsRule{ID: ev.RuleID, Name: ev.RuleName, Ruleset: "titus"},
+		Observer: ecsObserver{Vendor: Vendor, Product: Product, Version: Version},
+		Titus: map[string]any{
+			"validation_status": statusOrNone(ev.ValidationStatus),
+		},
+	}
+	if ev.Path != "" {
+		doc.File = &ecsFile{Path: ev.Path}
+	}
+	if ev.FindingID != "" {
+		doc.Titus["finding_id"] = ev.FindingID
+	}
+	if ev.BlobID != "" {
+		doc.Titus["blob_id"] = ev.BlobID
+	}
+	if ev.Line > 0 {
+		doc.Titus["line"] = ev.Line
+	}
+	if ev.RepoPath != "" {
+		doc.Titus["repository"] = ev.RepoPath
+	}
+	if ev.CommitID != "" {
+		doc.Titus["commit"] = ev.CommitID
+	}
+	return encodeJSON(doc)
+}
@@ -0,0 +1,146 @@
+package siem
+
+import "encoding/json"
+
+// OCSF Security Finding (class 2001) identifiers.
+const (
+	ocsfVersion          = "1.1.0"
+	ocsfCategoryUID      = 2    // Findings
+	ocsfClassUID         = 2001 // Security Finding
+	ocsfActivityCreate   = 1
+	ocsfStateNew         = 1
+	ocsfAnalyticRule     = 1
+	ocsfSeverityLow      = 2
+	ocsfSeverityMedium   = 3
+	ocsfSeverityCritical = 5
+)
+
+type ocsfFinding struct {
+	ActivityID  int            `json:"activity_id"`
+	Activity    string         `json:"activity_name"`
+	CategoryUID int            `json:"category_uid"`
+	Category    string         `json:"category_name"`
+	ClassUID    int            `json:"class_uid"`
+	Class       string         `json:"class_name"`
+	TypeUID     int            `json:"type_uid"`
+	Time        int64          `json:"time"`
+	SeverityID  int            `json:"severity_id"`
+	Severity    string         `json:"severity"`
+	StateID     int            `json:"state_id"`
+	State       string         `json:"state"`
+	Message     string         `json:"message"`
+	Metadata    ocsfMetadata   `json:"metadata"`
+	Finding     ocsfFindingObj `json:"finding"`
+	Analytic    ocsfAnalytic   `json:"analytic"`
+	Resources   []ocsfResource `json:"resources,omitempty"`
+	Unmapped    map[string]any `json:"unmapped,omitempty"`
+}
+
+type ocsfMetadata struct {
+	Version string      `json:"version"`
+	Product ocsfProduct `json:"product"`
+}
+
+type ocsfProduct struct {
+	Name       string `json:"name"`
+	VendorName string `json:"vendor_name"`
+	Version    string `json:"version"`
+}
+
+type ocsfFindingObj struct {
+	UID   string   `json:"uid"`
+	Title string   `json:"title"`
+	Types []string `json:"types"`
+}
+
+type ocsfAnalytic struct {
+	UID    string `json:"uid"`
+	Name   string `json:"name"`
+	TypeID int    `json:"type_id"`
+	Type   string `json:"type"`
+}
+
+type ocsfResource struct {
+	Type string         `json:"type"`
+	Name string         `json:"name"`
+	UID  string         `json:"uid,omitempty"`
+	Data map[string]any `json:"data,omitempty"`
+}
+
+// EncodeOCSF renders an event as a JSON OCSF Security Finding. Fields with
+// no OCSF attribute, such as the validation status, go under unmapped.
+func EncodeOCSF(ev Event) string {
+	severityID, severity := ocsfSeverity(ev.Severity)
+	f := ocsfFinding{
+		ActivityID:  ocsfActivityCreate,
+		Activity:    "Create",
+		CategoryUID: ocsfCategoryUID,
+		Category:    "Findings",
+		ClassUID:    ocsfClassUID,
+		Class:       "Security Finding",
+		TypeUID:     ocsfClassUID*100 + ocsfActivityCreate,
+		Time:        ev.Time.UnixMilli(),
+		SeverityID:  severityID,
+		Severity:    severity,
+		StateID:     ocsfStateNew,
+		State:       "New",
+		Message:     message(ev),
+		Metadata: ocsfMetadata{
+			Version: ocsfVersion,
+			Product: ocsfProduct{Name: Product, VendorName: Vendor, Version: Version},
+		},
+		Finding: ocsfFindingObj{UID: ev.FindingID, Title: ev.RuleName, Types: []string{"secret"}},
+		Analytic: ocsfAnalytic{
+			UID:    ev.RuleID,
+			Name:   ev.RuleName,
+			TypeID: ocsfAnalyticRule,
+			Type:   "Rule",
+		},
+		Unmapped: map[string]any{"validation_status": statusOrNone(ev.ValidationStatus)},
+	}
+	if ev.Path != "" || ev.BlobID != "" {
+		data := map[string]any{}
+		if ev.Line > 0 {
+			data["line"] = ev.Line
+		}
+		if ev.RepoPath != "" {
+			data["repository"] = ev.RepoPath
+		}
+		if ev.CommitID != "" {
+			data["commit"] = ev.CommitID
+		}
+		if len(data) == 0 {
+			data = nil
+		}
+		f.Resources = []ocsfResource{{Type: "File", Name: ev.Path, UID: ev.BlobID, Data: data}}
+	}
+	return encodeJSON(f)
+}
+
+// ocsfSeverity maps the CEF 0-10 scale onto OCSF severity IDs.
+func ocsfSeverity(cef int) (int, string) {
+	switch {
+	case cef >= 8:
+		return ocsfSeverityCritical, "Critical"
+	case cef >= 5:
+		return ocsfSeverityMedium, "Medium"
+	default:
+		return ocsfSeverityLow, "Low"
+	}
+}
+
+// message summarizes an event in one line.
+func message(ev Event) string {
+	if ev.Path == "" {
+		return ev.RuleName
+	}
+	return ev.RuleName + " in " + ev.Path
+}
+
+func encodeJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
@@ -0,0 +1,245 @@
+package siem
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// Product identification used in CEF/LEEF headers
+const (
+	Vendor  = "Praetorian"
+	Product = "Titus"
+)
+
+// Version is the product version in event headers. The titus command sets it
+// to its build version.
+var Version = "dev"
+
+// Format selects the event encoding
+type Format string
+
+const (
+	FormatCEF  Format = "cef"
+	FormatLEEF Format = "leef"
+	FormatOCSF Format = "ocsf" // JSON OCSF Security Finding
+	FormatECS  Format = "ecs"  // JSON Elastic Common Schema document
+)
+
+// ParseFormat converts a user-supplied format name to a Format.
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(s))) {
+	case FormatCEF:
+		return FormatCEF, nil
+	case FormatLEEF:
+		return FormatLEEF, nil
+	case FormatOCSF:
+		return FormatOCSF, nil
+	case FormatECS:
+		return FormatECS, nil
+	default:
+		return "", fmt.Errorf("unknown SIEM format %q (supported: cef, leef, ocsf, ecs)", s)
+	}
+}
+
+// Event is a single finding occurrence flattened for SIEM ingestion.
+type Event struct {
+	Time             time.Time
+	RuleID           string
+	RuleName         string
+	FindingID        string
+	BlobID           string
+	Path             string
+	RepoPath         string
+	CommitID         string
+	Line             int
+	ValidationStatus string
+	Severity         int // CEF scale 0-10
+}
+
+// NewEvent builds an Event from a match and the provenance of its blob.
+// prov may be nil when provenance is unknown.
+func NewEvent(match *types.Match, prov types.Provenance) Event {
+	ev := Event{
+		Time:      time.Now(),
+		RuleID:    match.RuleID,
+		RuleName:  match.RuleName,
+		FindingID: match.FindingID,
+		BlobID:    match.BlobID.Hex(),
+		Line:      match.Location.Source.Start.Line,
+		Severity:  Severity(match),
+	}
+	if match.ValidationResult != nil {
+		ev.ValidationStatus = string(match.ValidationResult.Status)
+	}
+	if prov != nil {
+		ev.Path = prov.Path()
+		if gp, ok := prov.(types.GitProvenance); ok {
+			ev.RepoPath = gp.RepoPath
+			if gp.Commit != nil {
+				ev.CommitID = gp.Commit.CommitID
+			}
+		}
+	}
+	return ev
+}
+
+// Severity maps a match to the CEF 0-10 severity scale.
+// Rules carry no severity of their own, so the validation outcome drives it:
+// confirmed-live secrets are very high, confirmed-dead ones low, and anything
+// unvalidated sits in the middle.
+func Severity(match *types.Match) int {
+	if match.ValidationResult == nil {
+		return 5
+	}
+	switch match.ValidationResult.Status {
+	case types.StatusValid:
+		return 10
+	case types.StatusInvalid:
+		return 3
+	default:
+		return 5
+	}
+}
+
+// Encode renders an event in the given format without any syslog framing.
+func Encode(f Format, ev Event) string {
+	switch f {
+	case FormatLEEF:
+		return EncodeLEEF(ev)
+	case FormatOCSF:
+		return EncodeOCSF(ev)
+	case FormatECS:
+		return EncodeECS(ev)
+	}
+	return EncodeCEF(ev)
+}
+
+// EncodeCEF renders an event as an ArcSight Common Event Format line.
+// Custom string fields use the cs1-cs4 slots with matching labels.
+func EncodeCEF(ev Event) string {
+	var b strings.Builder
+	b.WriteString("CEF:0|")
+	b.WriteString(cefHeader(Vendor))
+	b.WriteString("|")
+	b.WriteString(cefHeader(Product))
+	b.WriteString("|")
+	b.WriteString(cefHeader(Version))
+	b.WriteString("|")
+	b.WriteString(cefHeader(ev.RuleID))
+	b.WriteString("|")
+	b.WriteString(cefHeader(ev.RuleName))
+	b.WriteString("|")
+	b.WriteString(strconv.Itoa(ev.Severity))
+	b.WriteString("|")
+
+	ext := []string{
+		"rt=" + strconv.FormatInt(ev.Time.UnixMilli(), 10),
+		"cat=secret",
+	}
+	if ev.Path != "" {
+		ext = append(ext, "fname="+cefExt(ev.Path))
+	}
+	if ev.BlobID != "" {
+		ext = append(ext, "fileHash="+cefExt(ev.BlobID))
+	}
+	if ev.Line > 0 {
+		ext = append(ext, "cn1Label=line", "cn1="+strconv.Itoa(ev.Line))
+	}
+	ext = append(ext, "cs1Label=validationStatus", "cs1="+cefExt(statusOrNone(ev.ValidationStatus)))
+	if ev.FindingID != "" {
+		ext = append(ext, "cs2Label=findingId", "cs2="+cefExt(ev.FindingID))
+	}
+	if ev.RepoPath != "" {
+		ext = append(ext, "cs3Label=repository", "cs3="+cefExt(ev.RepoPath))
+	}
+	if ev.CommitID != "" {
+		ext = append(ext, "cs4Label=commit", "cs4="+cefExt(ev.CommitID))
+	}
+	b.WriteString(strings.Join(ext, " "))
+	return b.String()
+}
+
+// EncodeLEEF renders an event as an IBM QRadar LEEF 1.0 line (tab-delimited attributes).
+func EncodeLEEF(ev Event) string {
+	var b strings.Builder
+	b.WriteString("LEEF:1.0|")
+	b.WriteString(leefHeader(Vendor))
+	b.WriteString("|")
+	b.WriteString(leefHeader(Product))
+	b.WriteString("|")
+	b.WriteString(leefHeader(Version))
+	b.WriteString("|")
+	b.WriteString(leefHeader(ev.RuleID))
+	b.WriteString("|")
+
+	attrs := []string{
+		"devTime=" + ev.Time.UTC().Format("Jan 02 2006 15:04:05"),
+		"devTimeFormat=MMM dd yyyy HH:mm:ss",
+		"sev=" + strconv.Itoa(ev.Severity),
+		"cat=secret",
+		"ruleName=" + leefValue(ev.RuleName),
+		"validationStatus=" + leefValue(statusOrNone(ev.ValidationStatus)),
+	}
+	if ev.Path != "" {
+		attrs = append(attrs, "resource="+leefValue(ev.Path))
+	}
+	if ev.Line > 0 {
+		attrs = append(attrs, "line="+strconv.Itoa(ev.Line))
+	}
+	if ev.BlobID != "" {
+		attrs = append(attrs, "blobId="+leefValue(ev.BlobID))
+	}
+	if ev.FindingID != "" {
+		attrs = append(attrs, "findingId="+leefValue(ev.FindingID))
+	}
+	if ev.RepoPath != "" {
+		attrs = append(attrs, "repository="+leefValue(ev.RepoPath))
+	}
+	if ev.CommitID != "" {
+		attrs = append(attrs, "commit="+leefValue(ev.CommitID))
+	}
+	b.WriteString(strings.Join(attrs, "\t"))
+	return b.String()
+}
+
+func statusOrNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// cefHeader escapes pipes and backslashes in CEF header fields.
+func cefHeader(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return stripNewlines(s)
+}
+
+// cefExt escapes backslashes, equals signs and newlines in CEF extension values.
+func cefExt(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "=", `\=`)
+	s = strings.ReplaceAll(s, "\r", `\r`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return s
+}
+
+// leefHeader escapes pipes in LEEF header fields.
+func leefHeader(s string) string {
+	return stripNewlines(strings.ReplaceAll(s, "|", `\|`))
+}
+
+// leefValue strips the tab delimiter and newlines from LEEF attribute values.
+func leefValue(s string) string {
+	return stripNewlines(strings.ReplaceAll(s, "\t", " "))
+}
+
+func stripNewlines(s string) string {
+	s = strings.ReplaceAll(s, "\r", " ")
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,150 @@
+package siem
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMatch() *types.Match {
+	return &types.Match{
+		RuleID:    "np.aws.1",
+		RuleName:  "AWS API Key",
+		FindingID: "abc123",
+		Location: types.Location{
+			Source: types.SourceSpan{Start: types.SourcePoint{Line: 12, Column: 3}},
+		},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("CEF")
+	require.NoError(t, err)
+	assert.Equal(t, FormatCEF, f)
+
+	f, err = ParseFormat("leef")
+	require.NoError(t, err)
+	assert.Equal(t, FormatLEEF, f)
+
+	f, err = ParseFormat("ocsf")
+	require.NoError(t, err)
+	assert.Equal(t, FormatOCSF, f)
+
+	f, err = ParseFormat("ECS")
+	require.NoError(t, err)
+	assert.Equal(t, FormatECS, f)
+
+	_, err = ParseFormat("json")
+	assert.Error(t, err)
+}
+
+func TestSeverity(t *testing.T) {
+	m := testMatch()
+	assert.Equal(t, 5, Severity(m))
+
+	m.ValidationResult = types.NewValidationResult(types.StatusValid, 1, "ok")
+	assert.Equal(t, 10, Severity(m))
+
+	m.ValidationResult = types.NewValidationResult(types.StatusInvalid, 1, "revoked")
+	assert.Equal(t, 3, Severity(m))
+}
+
+func TestNewEvent_GitProvenance(t *testing.T) {
+	m := testMatch()
+	m.ValidationResult = types.NewValidationResult(types.StatusValid, 1, "ok")
+	prov := types.GitProvenance{
+		RepoPath: "org/repo",
+		BlobPath: "config/prod.env",
+		Commit:   &types.CommitMetadata{CommitID: "deadbeef"},
+	}
+
+	ev := NewEvent(m, prov)
+	assert.Equal(t, "np.aws.1", ev.RuleID)
+	assert.Equal(t, "config/prod.env", ev.Path)
+	assert.Equal(t, "org/repo", ev.RepoPath)
+	assert.Equal(t, "deadbeef", ev.CommitID)
+	assert.Equal(t, 12, ev.Line)
+	assert.Equal(t, "valid", ev.ValidationStatus)
+	assert.Equal(t, 10, ev.Severity)
+}
+
+func TestEncodeCEF(t *testing.T) {
+	ev := NewEvent(testMatch(), types.FileProvenance{FilePath: "a=b/secret.txt"})
+	ev.Time = time.UnixMilli(1700000000000)
+
+	line := EncodeCEF(ev)
+	assert.True(t, strings.HasPrefix(line, "CEF:0|Praetorian|Titus|"+Version+"|np.aws.1|AWS API Key|5|"))
+	assert.Contains(t, line, "rt=1700000000000")
+	assert.Contains(t, line, `fname=a\=b/secret.txt`)
+	assert.Contains(t, line, "cn1Label=line cn1=12")
+	assert.Contains(t, line, "cs1Label=validationStatus cs1=none")
+	assert.Contains(t, line, "cs2Label=findingId cs2=abc123")
+}
+
+func TestEncodeCEF_EscapesHeader(t *testing.T) {
+	m := testMatch()
+	m.RuleName = `Pipe|Back\slash`
+	line := EncodeCEF(NewEvent(m, nil))
+	assert.Contains(t, line, `|Pipe\|Back\\slash|`)
+}
+
+func TestEncodeLEEF(t *testing.T) {
+	m := testMatch()
+	m.ValidationResult = types.NewValidationResult(types.StatusInvalid, 1, "revoked")
+	ev := NewEvent(m, types.FileProvenance{FilePath: "secret.txt"})
+
+	line := EncodeLEEF(ev)
+	assert.True(t, strings.HasPrefix(line, "LEEF:1.0|Praetorian|Titus|"+Version+"|np.aws.1|"))
+	attrs := strings.Split(line[strings.LastIndex(line, "|")+1:], "\t")
+	assert.Contains(t, attrs, "sev=3")
+	assert.Contains(t, attrs, "resource=secret.txt")
+	assert.Contains(t, attrs, "validationStatus=invalid")
+	assert.Contains(t, attrs, "line=12")
+}
+
+func TestEncodeOCSF(t *testing.T) {
+	m := testMatch()
+	m.ValidationResult = types.NewValidationResult(types.StatusValid, 1, "ok")
+	ev := NewEvent(m, types.GitProvenance{
+		RepoPath: "org/repo",
+		BlobPath: "config/prod.env",
+		Commit:   &types.CommitMetadata{CommitID: "deadbeef"},
+	})
+	ev.Time = time.UnixMilli(1700000000000)
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal([]byte(Encode(FormatOCSF, ev)), &doc))
+	assert.EqualValues(t, 2001, doc["class_uid"])
+	assert.EqualValues(t, 200101, doc["type_uid"])
+	assert.EqualValues(t, 1700000000000, doc["time"])
+	assert.EqualValues(t, 5, doc["severity_id"])
+	assert.Equal(t, "AWS API Key in config/prod.env", doc["message"])
+	assert.Equal(t, map[string]any{"uid": "abc123", "title": "AWS API Key", "types": []any{"secret"}}, doc["finding"])
+	assert.Equal(t, "np.aws.1", doc["analytic"].(map[string]any)["uid"])
+	resource := doc["resources"].([]any)[0].(map[string]any)
+	assert.Equal(t, "config/prod.env", resource["name"])
+	assert.Equal(t, map[string]any{"line": 12.0, "repository": "org/repo", "commit": "deadbeef"}, resource["data"])
+	assert.Equal(t, map[string]any{"validation_status": "valid"}, doc["unmapped"])
+}
+
+func TestEncodeECS(t *testing.T) {
+	ev := NewEvent(testMatch(), types.FileProvenance{FilePath: "secret.txt"})
+	ev.Time = time.UnixMilli(1700000000000)
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal([]byte(Encode(FormatECS, ev)), &doc))
+	assert.Equal(t, "2023-11-14T22:13:20Z", doc["@timestamp"])
+	assert.Equal(t, "alert", doc["event"].(map[string]any)["kind"])
+	assert.EqualValues(t, 5, doc["event"].(map[string]any)["severity"])
+	assert.Equal(t, map[string]any{"id": "np.aws.1", "name": "AWS API Key", "ruleset": "titus"}, doc["rule"])
+	assert.Equal(t, map[string]any{"path": "secret.txt"}, doc["file"])
+	titus := doc["titus"].(map[string]any)
+	assert.Equal(t, "abc123", titus["finding_id"])
+	assert.Equal(t, "none", titus["validation_status"])
+	assert.EqualValues(t, 12, titus["line"])
+}
@@ -0,0 +1,104 @@
+package siem
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// syslogFacility is the syslog facility used for network delivery (local0).
+const syslogFacility = 16
+
+// Sink delivers encoded events to a file or a syslog collector.
+// It is safe for concurrent use by multiple scan workers.
+type Sink struct {
+	mu       sync.Mutex
+	w        io.WriteCloser
+	format   Format
+	syslog   bool // wrap each event in an RFC 5424 header
+	hostname string
+}
+
+// Open creates a sink for the given target.
+// Supported targets:
+//   - udp://host:port  (syslog over UDP, one datagram per event)
+//   - tcp://host:port  (syslog over TCP, newline-delimited)
+//   - file:///path or a plain path (one event per line, appended)
+func Open(target string, format Format) (*Sink, error) {
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+	s := &Sink{format: format, hostname: hostname}
+
+	switch {
+	case strings.HasPrefix(target, "udp://"), strings.HasPrefix(target, "tcp://"):
+		network := target[:3]
+		addr := target[len("udp://"):]
+		conn, err := net.DialTimeout(network, addr, 10*time.Second)
+		if err != nil {
+			return nil, fmt.Errorf("connecting to %s: %w", target, err)
+		}
+		s.w = conn
+		s.syslog = true
+	default:
+		path := strings.TrimPrefix(target, "file://")
+		if path == "" {
+			return nil, fmt.Errorf("empty SIEM target")
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("opening %s: %w", path, err)
+		}
+		s.w = f
+	}
+	return s, nil
+}
+
+// NewWriterSink creates a sink that writes unframed events to w.
+func NewWriterSink(w io.WriteCloser, format Format) *Sink {
+	return &Sink{w: w, format: format}
+}
+
+// Emit encodes and writes a single event.
+func (s *Sink) Emit(ev Event) error {
+	line := Encode(s.format, ev)
+	if s.syslog {
+		line = s.syslogHeader(ev) + line
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := io.WriteString(s.w, line+"\n")
+	return err
+}
+
+// Close releases the underlying file or connection.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Close()
+}
+
+// syslogHeader builds an RFC 5424 header for the event.
+func (s *Sink) syslogHeader(ev Event) string {
+	pri := syslogFacility*8 + syslogSeverity(ev.Severity)
+	return fmt.Sprintf("<%d>1 %s %s titus %d - - ",
+		pri, ev.Time.UTC().Format(time.RFC3339), s.hostname, os.Getpid())
+}
+
+// syslogSeverity maps the CEF 0-10 scale onto syslog severities.
+func syslogSeverity(cef int) int {
+	switch {
+	case cef >= 8:
+		return 2 // critical
+	case cef >= 5:
+		return 4 // warning
+	default:
+		return 5 // notice
+	}
+}
@@ -0,0 +1,60 @@
+package siem
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSink_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+
+	s, err := Open("file://"+path, FormatCEF)
+	require.NoError(t, err)
+	require.NoError(t, s.Emit(NewEvent(testMatch(), nil)))
+	require.NoError(t, s.Emit(NewEvent(testMatch(), nil)))
+	require.NoError(t, s.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "CEF:0|"), "file output should not carry a syslog header")
+}
+
+func TestSink_UDPSyslog(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	s, err := Open("udp://"+conn.LocalAddr().String(), FormatLEEF)
+	require.NoError(t, err)
+	defer s.Close()
+
+	m := testMatch()
+	m.ValidationResult = types.NewValidationResult(types.StatusValid, 1, "ok")
+	require.NoError(t, s.Emit(NewEvent(m, nil)))
+
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+
+	msg := string(buf[:n])
+	// local0 (16) * 8 + critical (2)
+	assert.True(t, strings.HasPrefix(msg, "<130>1 "), "unexpected header: %q", msg)
+	assert.Contains(t, msg, " titus ")
+	assert.Contains(t, msg, "LEEF:1.0|Praetorian|Titus|")
+}
+
+func TestSink_EmptyTarget(t *testing.T) {
+	_, err := Open("file://", FormatCEF)
+	assert.Error(t, err)
+}
@@ -0,0 +1,335 @@
+// Package signing produces detached signatures over scan reports, so that
+// results kept as audit evidence can later be shown to be unchanged.
+//
+// What is signed is a Statement: the SHA-256 of the report output and of
+// the datastore it was produced from. It is signed either with a provided
+// Ed25519 or ECDSA key, or keylessly by cosign, which binds the signature to
+// an OIDC identity through a short-lived Sigstore certificate.
+package signing
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// cosignPath is the cosign binary used for keyless signing.
+var cosignPath = "cosign"
+
+// Statement is the signed description of a report.
+type Statement struct {
+	Format          string    `json:"format"`
+	OutputSHA256    string    `json:"output_sha256"`
+	Datastore       string    `json:"datastore,omitempty"`
+	DatastoreSHA256 string    `json:"datastore_sha256,omitempty"`
+	Created         time.Time `json:"created"`
+	Tool            string    `json:"tool"`
+}
+
+// NewStatement describes a report's output and, unless datastorePath is
+// empty, the datastore file it came from.
+func NewStatement(format string, output []byte, datastorePath, tool string) (Statement, error) {
+	sum := sha256.Sum256(output)
+	st := Statement{
+		Format:       format,
+		OutputSHA256: hex.EncodeToString(sum[:]),
+		Created:      time.Now().UTC(),
+		Tool:         tool,
+	}
+	if datastorePath != "" {
+		hash, err := HashFile(datastorePath)
+		if err != nil {
+			return Statement{}, fmt.Errorf("hashing datastore: %w", err)
+		}
+		st.Datastore = filepath.Base(datastorePath)
+		st.DatastoreSHA256 = hash
+	}
+	return st, nil
+}
+
+// Check reports whether output and the datastore file at datastorePath
+// (skipped if empty) are the ones the statement describes.
+func (st Statement) Check(output []byte, datastorePath string) error {
+	sum := sha256.Sum256(output)
+	if hex.EncodeToString(sum[:]) != st.OutputSHA256 {
+		return fmt.Errorf("report output does not match the signed SHA-256 %s", st.OutputSHA256)
+	}
+	if datastorePath == "" {
+		return nil
+	}
+	if st.DatastoreSHA256 == "" {
+		return fmt.Errorf("the signature does not cover a datastore")
+	}
+	hash, err := HashFile(datastorePath)
+	if err != nil {
+		return fmt.Errorf("hashing datastore: %w", err)
+	}
+	if hash != st.DatastoreSHA256 {
+		return fmt.Errorf("datastore does not match the signed SHA-256 %s", st.DatastoreSHA256)
+	}
+	return nil
+}
+
+// HashFile returns the hex SHA-256 of a file.
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Signature is a detached report signature, written as JSON next to the
+// report.
+type Signature struct {
+	Payload      []byte          `json:"payload"`                 // the JSON Statement that was signed
+	Signature    []byte          `json:"signature,omitempty"`     // key signatures
+	PublicKey    string          `json:"public_key,omitempty"`    // PEM public key, for key signatures
+	CosignBundle json.RawMessage `json:"cosign_bundle,omitempty"` // keyless signatures
+}
+
+// SignWithKey signs a statement with a PEM-encoded Ed25519 or ECDSA private
+// key, in PKCS #8 or (for ECDSA) SEC 1 form.
+func SignWithKey(st Statement, keyPEM []byte) (*Signature, error) {
+	signer, err := parsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(st)
+	if err != nil {
+		return nil, err
+	}
+
+	var sig []byte
+	switch key := signer.(type) {
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(key, payload)
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256(payload)
+		if sig, err = ecdsa.SignASN1(rand.Reader, key, digest[:]); err != nil {
+			return nil, fmt.Errorf("signing: %w", err)
+		}
+	}
+
+	pub, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, fmt.Errorf("encoding public key: %w", err)
+	}
+	return &Signature{
+		Payload:   payload,
+		Signature: sig,
+		PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub})),
+	}, nil
+}
+
+// SignKeyless signs a statement with cosign's keyless flow, which
+// authenticates through an OIDC provider (a browser login, or the ambient
+// token in CI) and records the signature in the Rekor transparency log.
+func SignKeyless(ctx context.Context, st Statement) (*Signature, error) {
+	payload, err := json.Marshal(st)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "titus-sign-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	payloadPath := filepath.Join(dir, "statement.json")
+	bundlePath := filepath.Join(dir, "bundle.json")
+	if err := os.WriteFile(payloadPath, payload, 0o600); err != nil {
+		return nil, err
+	}
+	if err := runCosign(ctx, "sign-blob", "--yes", "--bundle", bundlePath, payloadPath); err != nil {
+		return nil, err
+	}
+	bundle, err := os.ReadFile(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading cosign bundle: %w", err)
+	}
+	if !json.Valid(bundle) {
+		return nil, fmt.Errorf("cosign wrote an invalid bundle")
+	}
+	return &Signature{Payload: payload, CosignBundle: bundle}, nil
+}
+
+// VerifyOptions says which signer to trust.
+type VerifyOptions struct {
+	// PublicKeyPEM is the trusted public key, for key signatures.
+	PublicKeyPEM []byte
+
+	// CertificateIdentity and CertificateOIDCIssuer are the expected signer
+	// identity (such as an email address or CI workflow) and OIDC issuer,
+	// for keyless signatures.
+	CertificateIdentity   string
+	CertificateOIDCIssuer string
+}
+
+// Verify checks the signature against the trusted signer and returns the
+// signed statement.
+func (s *Signature) Verify(ctx context.Context, opts VerifyOptions) (Statement, error) {
+	switch {
+	case len(s.CosignBundle) > 0:
+		if err := s.verifyKeyless(ctx, opts); err != nil {
+			return Statement{}, err
+		}
+	case len(s.Signature) > 0:
+		if err := s.verifyKey(opts.PublicKeyPEM); err != nil {
+			return Statement{}, err
+		}
+	default:
+		return Statement{}, fmt.Errorf("signature file has no signature")
+	}
+
+	var st Statement
+	if err := json.Unmarshal(s.Payload, &st); err != nil {
+		return Statement{}, fmt.Errorf("parsing signed statement: %w", err)
+	}
+	return st, nil
+}
+
+func (s *Signature) verifyKey(trustedPEM []byte) error {
+	if len(trustedPEM) == 0 {
+		return fmt.Errorf("a trusted public key is required to verify a key signature")
+	}
+	trusted, err := parsePublicKey(trustedPEM)
+	if err != nil {
+		return err
+	}
+	embedded, err := parsePublicKey([]byte(s.PublicKey))
+	if err == nil {
+		if eq, ok := embedded.(interface{ Equal(crypto.PublicKey) bool }); ok && !eq.Equal(trusted) {
+			return fmt.Errorf("report was signed with a different key")
+		}
+	}
+
+	switch key := trusted.(type) {
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, s.Payload, s.Signature) {
+			return fmt.Errorf("signature verification failed")
+		}
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(s.Payload)
+		if !ecdsa.VerifyASN1(key, digest[:], s.Signature) {
+			return fmt.Errorf("signature verification failed")
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", trusted)
+	}
+	return nil
+}
+
+func (s *Signature) verifyKeyless(ctx context.Context, opts VerifyOptions) error {
+	if opts.CertificateIdentity == "" || opts.CertificateOIDCIssuer == "" {
+		return fmt.Errorf("the signer identity and OIDC issuer are required to verify a keyless signature")
+	}
+	dir, err := os.MkdirTemp("", "titus-verify-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	payloadPath := filepath.Join(dir, "statement.json")
+	bundlePath := filepath.Join(dir, "bundle.json")
+	if err := os.WriteFile(payloadPath, s.Payload, 0o600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(bundlePath, s.CosignBundle, 0o600); err != nil {
+		return err
+	}
+	return runCosign(ctx, "verify-blob", "--bundle", bundlePath,
+		"--certificate-identity", opts.CertificateIdentity,
+		"--certificate-oidc-issuer", opts.CertificateOIDCIssuer,
+		payloadPath)
+}
+
+// Write saves the signature as JSON.
+func (s *Signature) Write(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Read loads a signature written by Write.
+func Read(path string) (*Signature, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Signature
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing signature %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+func runCosign(ctx context.Context, args ...string) error {
+	cmd := exec.CommandContext(ctx, cosignPath, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("cosign %s: %w", args[0], err)
+	}
+	return nil
+}
+
+func parsePrivateKey(keyPEM []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("signing key is not PEM encoded")
+	}
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing signing key: %w", err)
+		}
+		switch key := key.(type) {
+		case ed25519.PrivateKey:
+			return key, nil
+		case *ecdsa.PrivateKey:
+			return key, nil
+		}
+		return nil, fmt.Errorf("unsupported signing key type %T (use Ed25519 or ECDSA)", key)
+	case "EC PRIVATE KEY":
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing signing key: %w", err)
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported signing key PEM type %q (encrypted keys must be decrypted first)", block.Type)
+}
+
+func parsePublicKey(keyPEM []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("public key is not a PEM-encoded PUBLIC KEY")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing public key: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,133 @@
+package signing
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pemKeys(t *testing.T, priv any, pub any) (privPEM, pubPEM []byte) {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+}
+
+func TestSignWithKey(t *testing.T) {
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ecPriv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	datastore := filepath.Join(t.TempDir(), "datastore.db")
+	require.NoError(t, os.WriteFile(datastore, []byte("sqlite"), 0o600))
+	output := []byte(`{"matches":[]}`)
+
+	for name, keys := range map[string][2]any{
+		"ed25519": {edPriv, edPub},
+		"ecdsa":   {ecPriv, &ecPriv.PublicKey},
+	} {
+		t.Run(name, func(t *testing.T) {
+			privPEM, pubPEM := pemKeys(t, keys[0], keys[1])
+			st, err := NewStatement("json", output, datastore, "titus test")
+			require.NoError(t, err)
+			sig, err := SignWithKey(st, privPEM)
+			require.NoError(t, err)
+
+			path := filepath.Join(t.TempDir(), "sig.json")
+			require.NoError(t, sig.Write(path))
+			sig, err = Read(path)
+			require.NoError(t, err)
+
+			got, err := sig.Verify(context.Background(), VerifyOptions{PublicKeyPEM: pubPEM})
+			require.NoError(t, err)
+			assert.Equal(t, "json", got.Format)
+			assert.Equal(t, "datastore.db", got.Datastore)
+			assert.NoError(t, got.Check(output, datastore))
+			assert.NoError(t, got.Check(output, ""))
+			assert.Error(t, got.Check([]byte(`{"matches":[1]}`), ""))
+
+			_, err = sig.Verify(context.Background(), VerifyOptions{})
+			assert.Error(t, err, "a trusted key is required")
+
+			sig.Payload[len(sig.Payload)-2] ^= 1
+			_, err = sig.Verify(context.Background(), VerifyOptions{PublicKeyPEM: pubPEM})
+			assert.Error(t, err, "tampered statement")
+		})
+	}
+
+	t.Run("wrong key", func(t *testing.T) {
+		privPEM, _ := pemKeys(t, edPriv, edPub)
+		_, otherPEM := pemKeys(t, ecPriv, &ecPriv.PublicKey)
+		st, err := NewStatement("json", output, "", "titus test")
+		require.NoError(t, err)
+		sig, err := SignWithKey(st, privPEM)
+		require.NoError(t, err)
+		_, err = sig.Verify(context.Background(), VerifyOptions{PublicKeyPEM: otherPEM})
+		assert.ErrorContains(t, err, "different key")
+		assert.ErrorContains(t, st.Check(output, datastore), "does not cover a datastore")
+	})
+}
+
+func TestStatement_DatastoreChanged(t *testing.T) {
+	datastore := filepath.Join(t.TempDir(), "datastore.db")
+	require.NoError(t, os.WriteFile(datastore, []byte("before"), 0o600))
+	st, err := NewStatement("sarif", nil, datastore, "titus test")
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(datastore, []byte("after"), 0o600))
+	assert.ErrorContains(t, st.Check(nil, datastore), "datastore does not match")
+}
+
+// TestSignKeyless runs the keyless flow against a stand-in for cosign that
+// records its arguments and writes a bundle.
+func TestSignKeyless(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("stand-in cosign is a shell script")
+	}
+	dir := t.TempDir()
+	log := filepath.Join(dir, "args")
+	script := "#!/bin/sh\necho \"$@\" >> " + log + "\n" +
+		"while [ $# -gt 0 ]; do if [ \"$1\" = --bundle ]; then shift; [ -f \"$1\" ] || echo '{\"cert\":\"x\"}' > \"$1\"; fi; shift; done\n"
+	fake := filepath.Join(dir, "cosign")
+	require.NoError(t, os.WriteFile(fake, []byte(script), 0o755))
+	defer func(old string) { cosignPath = old }(cosignPath)
+	cosignPath = fake
+
+	st, err := NewStatement("json", []byte("{}"), "", "titus test")
+	require.NoError(t, err)
+	sig, err := SignKeyless(context.Background(), st)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"cert":"x"}`, string(sig.CosignBundle))
+	assert.Empty(t, sig.Signature)
+
+	_, err = sig.Verify(context.Background(), VerifyOptions{})
+	assert.ErrorContains(t, err, "identity")
+
+	got, err := sig.Verify(context.Background(), VerifyOptions{
+		CertificateIdentity:   "dev@example.com",
+		CertificateOIDCIssuer: "https://accounts.example.com",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, st.OutputSHA256, got.OutputSHA256)
+
+	args, err := os.ReadFile(log)
+	require.NoError(t, err)
+	assert.Contains(t, string(args), "sign-blob --yes --bundle")
+	assert.Contains(t, string(args), "verify-blob --bundle")
+	assert.Contains(t, string(args), "--certificate-identity dev@example.com --certificate-oidc-issuer https://accounts.example.com")
+}
@@ -0,0 +1,242 @@
+//go:build !wasm
+
+package store
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// ExportOptions configures Export.
+type ExportOptions struct {
+	// Redacted replaces each secret capture group with its HMAC-SHA256 and
+	// masks secrets in match snippets, validation messages and annotation
+	// comments. The HMAC is keyed with the source datastore's secret key,
+	// which isn't exported, so guesses at a secret can't be checked against
+	// the copy.
+	Redacted bool
+}
+
+// Export copies the SQLite datastore at srcPath to dstPath, which must not
+// exist. All metadata, annotations, provenance and scan records are kept.
+func Export(srcPath, dstPath string, opts ExportOptions) error {
+	if _, err := os.Stat(dstPath); err == nil {
+		return fmt.Errorf("%s already exists", dstPath)
+	}
+
+	src, err := NewSQLite(srcPath)
+	if err != nil {
+		return err
+	}
+	var key []byte
+	if opts.Redacted {
+		if key, err = src.SecretKey(); err != nil {
+			src.Close()
+			return fmt.Errorf("reading secret key: %w", err)
+		}
+	}
+	_, err = src.db.Exec("VACUUM INTO ?", dstPath)
+	src.Close()
+	if err != nil {
+		return fmt.Errorf("copying datastore: %w", err)
+	}
+	if !opts.Redacted {
+		return nil
+	}
+
+	dst, err := NewSQLite(dstPath)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+	if err := dst.ExecBatch(func(s Store) error {
+		return redact(s.(*SQLiteStore).e, key)
+	}); err != nil {
+		return fmt.Errorf("redacting datastore: %w", err)
+	}
+	// Rewrite the file so the replaced values don't survive in free pages.
+	if _, err := dst.db.Exec("VACUUM"); err != nil {
+		return fmt.Errorf("compacting datastore: %w", err)
+	}
+	return nil
+}
+
+// redactedMatch is a match row whose secrets are being redacted.
+type redactedMatch struct {
+	id                      int64
+	blobID                  string
+	before, matching, after []byte
+	groups                  [][]byte
+	message                 sql.NullString
+}
+
+func redact(e dbLike, key []byte) error {
+	rows, err := e.Query("SELECT id, blob_id, snippet_before, snippet_matching, snippet_after, groups_json, validation_message FROM matches")
+	if err != nil {
+		return err
+	}
+	var matches []redactedMatch
+	for rows.Next() {
+		var m redactedMatch
+		var groupsJSON sql.NullString
+		if err := rows.Scan(&m.id, &m.blobID, &m.before, &m.matching, &m.after, &groupsJSON, &m.message); err != nil {
+			rows.Close()
+			return err
+		}
+		m.groups, _ = deserializeGroups(groupsJSON.String)
+		matches = append(matches, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// A snippet's context can hold other secrets found in the same blob, so
+	// every secret of the blob is masked in each of its snippets.
+	secrets := map[string][][]byte{}
+	var all [][]byte
+	for _, m := range matches {
+		secrets[m.blobID] = append(secrets[m.blobID], matchSecrets(m)...)
+		all = append(all, matchSecrets(m)...)
+	}
+	for _, s := range secrets {
+		sortLongestFirst(s)
+	}
+
+	for _, m := range matches {
+		s := secrets[m.blobID]
+		message := m.message
+		if message.Valid {
+			message.String = string(maskSecrets([]byte(message.String), s, key))
+		}
+		groupsJSON, _ := serializeGroups(hashGroups(m.groups, key))
+		if _, err := e.Exec("UPDATE matches SET snippet_before = ?, snippet_matching = ?, snippet_after = ?, groups_json = ?, validation_message = ? WHERE id = ?",
+			maskSecrets(m.before, s, key), maskSecrets(m.matching, s, key), maskSecrets(m.after, s, key), groupsJSON, message, m.id); err != nil {
+			return err
+		}
+	}
+
+	findings := map[int64]string{}
+	rows, err = e.Query("SELECT id, groups_json FROM findings")
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var id int64
+		var groupsJSON sql.NullString
+		if err := rows.Scan(&id, &groupsJSON); err != nil {
+			rows.Close()
+			return err
+		}
+		groups, _ := deserializeGroups(groupsJSON.String)
+		findings[id], _ = serializeGroups(hashGroups(groups, key))
+		for _, g := range groups {
+			if len(g) > 0 {
+				all = append(all, g)
+			}
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for id, groupsJSON := range findings {
+		if _, err := e.Exec("UPDATE findings SET groups_json = ? WHERE id = ?", groupsJSON, id); err != nil {
+			return err
+		}
+	}
+
+	// The key that names secrets in sprawl graphs would let guesses be
+	// checked against a shared graph
+	if _, err := e.Exec("DELETE FROM settings WHERE key = 'secret_key'"); err != nil {
+		return err
+	}
+
+	// An annotation comment can quote a secret, of any finding
+	sortLongestFirst(all)
+	comments := map[int64]string{}
+	rows, err = e.Query("SELECT id, comment FROM annotations WHERE comment IS NOT NULL")
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var id int64
+		var comment string
+		if err := rows.Scan(&id, &comment); err != nil {
+			rows.Close()
+			return err
+		}
+		comments[id] = string(maskSecrets([]byte(comment), all, key))
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for id, comment := range comments {
+		if _, err := e.Exec("UPDATE annotations SET comment = ? WHERE id = ?", comment, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortLongestFirst sorts secrets longest first, so that a secret containing
+// another is masked whole.
+func sortLongestFirst(secrets [][]byte) {
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+}
+
+// maskSecrets returns b with each of secrets masked.
+func maskSecrets(b []byte, secrets [][]byte, key []byte) []byte {
+	for _, secret := range secrets {
+		b = bytes.ReplaceAll(b, secret, mask(secret, key))
+	}
+	return b
+}
+
+// hashGroups replaces each capture group with "hmac:" and the hex
+// HMAC-SHA256 of its value under key, so a redacted secret can still be
+// recognized by whoever holds the source datastore.
+func hashGroups(groups [][]byte, key []byte) [][]byte {
+	if groups == nil {
+		return nil
+	}
+	hashed := make([][]byte, len(groups))
+	for i, g := range groups {
+		hashed[i] = []byte("hmac:" + hmacHex(key, g))
+	}
+	return hashed
+}
+
+// matchSecrets returns the secrets of a match: its capture groups, or its
+// whole matching text if it has none.
+func matchSecrets(m redactedMatch) [][]byte {
+	var secrets [][]byte
+	for _, g := range m.groups {
+		if len(g) > 0 {
+			secrets = append(secrets, g)
+		}
+	}
+	if len(secrets) == 0 && len(m.matching) > 0 {
+		secrets = append(secrets, m.matching)
+	}
+	return secrets
+}
+
+// mask returns the placeholder a secret is replaced with in snippets, which
+// names the start of its HMAC, as titus graph names secrets.
+func mask(secret, key []byte) []byte {
+	return []byte("[REDACTED hmac:" + hmacHex(key, secret)[:12] + "]")
+}
+
+func hmacHex(key, b []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(b)
+	return hex.EncodeToString(mac.Sum(nil))
+}
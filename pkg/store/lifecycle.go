@@ -0,0 +1,24 @@
+package store
+
+import (
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// findingLifecycle returns the state and timestamps to store for a new
+// finding: new and seen now, unless the finding already has them.
+func findingLifecycle(f *types.Finding) (types.FindingState, time.Time, time.Time) {
+	state := f.State
+	if state == "" {
+		state = types.FindingStateNew
+	}
+	firstSeen, lastSeen := f.FirstSeen, f.LastSeen
+	if firstSeen.IsZero() {
+		firstSeen = time.Now().UTC().Truncate(time.Second)
+	}
+	if lastSeen.IsZero() {
+		lastSeen = firstSeen
+	}
+	return state, firstSeen, lastSeen
+}
@@ -1,16 +1,20 @@
 package store
 
 import (
+	"crypto/rand"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/praetorian-inc/titus/pkg/types"
 )
 
 // blobRecord stores blob metadata.
 type blobRecord struct {
-	id   types.BlobID
-	size int64
+	id     types.BlobID
+	size   int64
+	scanID int64
 }
 
 // MemoryStore implements Store using in-memory data structures.
@@ -21,7 +25,12 @@ type MemoryStore struct {
 	blobs      map[string]blobRecord        // keyed by BlobID.Hex()
 	matches    []*types.Match               // all matches
 	findings   map[string]*types.Finding    // keyed by structural_id
+	order      []*types.Finding             // findings in the order added, for GetFindingsSince
 	provenance map[string][]types.Provenance // keyed by BlobID.Hex()
+	repoRefs   map[string]map[string]string  // repo -> ref -> commit
+	scans      []*types.Scan
+	fpVersion  types.FingerprintVersion
+	secretKey  []byte
 }
 
 // NewMemory creates a new in-memory store.
@@ -31,6 +40,7 @@ func NewMemory() *MemoryStore {
 		matches:    make([]*types.Match, 0),
 		findings:   make(map[string]*types.Finding),
 		provenance: make(map[string][]types.Provenance),
+		repoRefs:   make(map[string]map[string]string),
 	}
 }
 
@@ -46,8 +56,9 @@ func (m *MemoryStore) AddBlob(id types.BlobID, size int64) error {
 	}
 
 	m.blobs[key] = blobRecord{
-		id:   id,
-		size: size,
+		id:     id,
+		size:   size,
+		scanID: m.scanID(),
 	}
 	return nil
 }
@@ -63,6 +74,9 @@ func (m *MemoryStore) AddMatch(match *types.Match) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if match.ScanID == 0 {
+		match.ScanID = m.scanID()
+	}
 	m.matches = append(m.matches, match)
 	return nil
 }
@@ -77,7 +91,10 @@ func (m *MemoryStore) AddFinding(f *types.Finding) error {
 		return nil
 	}
 
-	m.findings[f.ID] = f
+	stored := *f
+	stored.State, stored.FirstSeen, stored.LastSeen = findingLifecycle(f)
+	m.findings[f.ID] = &stored
+	m.order = append(m.order, &stored)
 	return nil
 }
 
@@ -149,6 +166,42 @@ func (m *MemoryStore) GetAllMatches() ([]*types.Match, error) {
 	return result, nil
 }
 
+// GetMatchesSince retrieves the matches added after the first after; the
+// row ID of a match is its position in the order added, from 1.
+func (m *MemoryStore) GetMatchesSince(after int64) ([]*types.Match, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	last := int64(len(m.matches))
+	if after >= last {
+		return []*types.Match{}, after, nil
+	}
+	result := make([]*types.Match, last-max(after, 0))
+	copy(result, m.matches[max(after, 0):])
+	return result, last, nil
+}
+
+// GetFindingsSince retrieves the findings added after the first after,
+// leaving out those since remapped to another ID.
+func (m *MemoryStore) GetFindingsSince(after int64) ([]*types.Finding, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	last := int64(len(m.order))
+	if after >= last {
+		return []*types.Finding{}, after, nil
+	}
+	result := []*types.Finding{}
+	for _, finding := range m.order[max(after, 0):] {
+		if m.findings[finding.ID] != finding {
+			continue
+		}
+		f := *finding
+		result = append(result, &f)
+	}
+	return result, last, nil
+}
+
 // GetFindings retrieves all findings (for reporting).
 func (m *MemoryStore) GetFindings() ([]*types.Finding, error) {
 	m.mu.RLock()
@@ -156,7 +209,9 @@ func (m *MemoryStore) GetFindings() ([]*types.Finding, error) {
 
 	result := make([]*types.Finding, 0, len(m.findings))
 	for _, finding := range m.findings {
-		result = append(result, finding)
+		// Copy so lifecycle updates don't race with readers
+		f := *finding
+		result = append(result, &f)
 	}
 	return result, nil
 }
@@ -170,6 +225,49 @@ func (m *MemoryStore) FindingExists(structuralID string) (bool, error) {
 	return exists, nil
 }
 
+// MarkFindingsSeen records that a scan found the findings again.
+func (m *MemoryStore) MarkFindingsSeen(ids []string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, id := range ids {
+		f, ok := m.findings[id]
+		if !ok {
+			continue
+		}
+		if f.FirstSeen.IsZero() {
+			f.FirstSeen = at
+		}
+		f.LastSeen = at
+		f.State = f.State.Seen()
+	}
+	return nil
+}
+
+// SetFindingState sets the lifecycle state of a finding.
+func (m *MemoryStore) SetFindingState(id string, state types.FindingState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if f, ok := m.findings[id]; ok {
+		f.State = state
+	}
+	return nil
+}
+
+// SetMatchValidation replaces the validation result of a match.
+func (m *MemoryStore) SetMatchValidation(structuralID string, result *types.ValidationResult) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, match := range m.matches {
+		if match.StructuralID == structuralID {
+			match.ValidationResult = result
+		}
+	}
+	return nil
+}
+
 // BlobExists checks if a blob has already been scanned.
 func (m *MemoryStore) BlobExists(id types.BlobID) (bool, error) {
 	m.mu.RLock()
@@ -208,9 +306,148 @@ func (m *MemoryStore) SetAnnotation(targetType, targetID, status, comment string
 	return nil
 }
 
+// GetAnnotations returns no annotations; the in-memory store doesn't keep them.
+func (m *MemoryStore) GetAnnotations() ([]*types.Annotation, error) {
+	return nil, nil
+}
+
+// PutAnnotation is a no-op for in-memory store.
+func (m *MemoryStore) PutAnnotation(a *types.Annotation) error {
+	return nil
+}
+
+// GetRepoRefs retrieves the last-scanned commit for each ref of a repository.
+func (m *MemoryStore) GetRepoRefs(repo string) (map[string]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	refs := make(map[string]string, len(m.repoRefs[repo]))
+	for ref, commit := range m.repoRefs[repo] {
+		refs[ref] = commit
+	}
+	return refs, nil
+}
+
+// SetRepoRef records the last-scanned commit for a repository ref.
+func (m *MemoryStore) SetRepoRef(repo, ref, commit string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.repoRefs[repo] == nil {
+		m.repoRefs[repo] = make(map[string]string)
+	}
+	m.repoRefs[repo][ref] = commit
+	return nil
+}
+
+// AddScan records the start of a scan.
+func (m *MemoryStore) AddScan(scan *types.Scan) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	scan.ID = int64(len(m.scans) + 1)
+	stored := *scan
+	m.scans = append(m.scans, &stored)
+	return nil
+}
+
+// FinishScan records when a scan ended.
+func (m *MemoryStore) FinishScan(scan *types.Scan) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if scan.ID > 0 && int(scan.ID) <= len(m.scans) {
+		m.scans[scan.ID-1].FinishedAt = scan.FinishedAt
+	}
+	return nil
+}
+
+// GetScans retrieves all recorded scans, oldest first.
+func (m *MemoryStore) GetScans() ([]*types.Scan, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]*types.Scan, len(m.scans))
+	for i, scan := range m.scans {
+		s := *scan
+		result[i] = &s
+	}
+	return result, nil
+}
+
+// scanID returns the ID of the latest scan, which new blobs and matches are
+// attributed to. The caller must hold mu.
+func (m *MemoryStore) scanID() int64 {
+	return int64(len(m.scans))
+}
+
 // Close closes the database connection.
 // For in-memory store, this is a no-op.
 func (m *MemoryStore) Close() error {
 	// No resources to clean up for in-memory store
 	return nil
 }
+
+// SecretKey returns the store's key for keyed hashes of secrets, creating
+// it on first use.
+func (m *MemoryStore) SecretKey() ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.secretKey == nil {
+		key := make([]byte, secretKeySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		m.secretKey = key
+	}
+	return m.secretKey, nil
+}
+
+// GetFingerprintVersion returns the recorded fingerprint version.
+func (m *MemoryStore) GetFingerprintVersion() (types.FingerprintVersion, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.fpVersion, nil
+}
+
+// SetFingerprintVersion records the fingerprint version.
+func (m *MemoryStore) SetFingerprintVersion(v types.FingerprintVersion) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fpVersion = v
+	return nil
+}
+
+// RemapFindings changes finding IDs. Annotations aren't kept in memory.
+func (m *MemoryStore) RemapFindings(ids map[string]string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	moved := make(map[string]*types.Finding, len(ids))
+	for _, oldID := range sortedKeys(ids) {
+		if f, ok := m.findings[oldID]; ok && ids[oldID] != oldID {
+			delete(m.findings, oldID)
+			moved[oldID] = f
+		}
+	}
+	for _, oldID := range sortedKeys(ids) {
+		f, ok := moved[oldID]
+		if !ok {
+			continue
+		}
+		if _, taken := m.findings[ids[oldID]]; taken {
+			continue
+		}
+		f.ID = ids[oldID]
+		m.findings[f.ID] = f
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of ids in order, so remapping is deterministic.
+func sortedKeys(ids map[string]string) []string {
+	keys := make([]string, 0, len(ids))
+	for k := range ids {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -4,6 +4,7 @@ package store
 
 import (
 	"testing"
+	"time"
 
 	"github.com/praetorian-inc/titus/pkg/types"
 	"github.com/stretchr/testify/assert"
@@ -431,3 +432,61 @@ func TestMemory_Close(t *testing.T) {
 	// Assert - Close should be a no-op for in-memory store
 	assert.NoError(t, err)
 }
+
+func TestMemoryStore_RepoRefs(t *testing.T) {
+	s := NewMemory()
+
+	require.NoError(t, s.SetRepoRef("repo", "refs/heads/main", "aaaa"))
+	require.NoError(t, s.SetRepoRef("repo", "refs/heads/main", "bbbb"))
+
+	refs, err := s.GetRepoRefs("repo")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"refs/heads/main": "bbbb"}, refs)
+
+	// Returned map is a copy
+	refs["refs/heads/main"] = "mutated"
+	refs, _ = s.GetRepoRefs("repo")
+	assert.Equal(t, "bbbb", refs["refs/heads/main"])
+}
+
+func TestMemory_FindingLifecycle(t *testing.T) {
+	store := NewMemory()
+	require.NoError(t, store.AddFinding(&types.Finding{ID: "f1", RuleID: "np.test.1"}))
+
+	findings, err := store.GetFindings()
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, types.FindingStateNew, findings[0].State)
+	assert.False(t, findings[0].FirstSeen.IsZero())
+
+	require.NoError(t, store.SetFindingState("f1", types.FindingStateRemediated))
+	require.NoError(t, store.MarkFindingsSeen([]string{"f1"}, findings[0].FirstSeen))
+	findings, err = store.GetFindings()
+	require.NoError(t, err)
+	assert.Equal(t, types.FindingStateRegressed, findings[0].State)
+}
+
+func TestMemory_Scans(t *testing.T) {
+	store := NewMemory()
+	blobID := types.ComputeBlobID([]byte("test content"))
+	require.NoError(t, store.AddMatch(&types.Match{BlobID: blobID, StructuralID: "m0"}))
+
+	scan := &types.Scan{StartedAt: time.Now(), Target: "."}
+	require.NoError(t, store.AddScan(scan))
+	assert.Equal(t, int64(1), scan.ID)
+	require.NoError(t, store.AddMatch(&types.Match{BlobID: blobID, StructuralID: "m1"}))
+
+	scan.FinishedAt = scan.StartedAt.Add(time.Second)
+	require.NoError(t, store.FinishScan(scan))
+
+	scans, err := store.GetScans()
+	require.NoError(t, err)
+	require.Len(t, scans, 1)
+	assert.Equal(t, scan, scans[0])
+
+	matches, err := store.GetAllMatches()
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+	assert.Equal(t, int64(0), matches[0].ScanID)
+	assert.Equal(t, int64(1), matches[1].ScanID)
+}
@@ -41,6 +41,18 @@ func CreateSchema(db *sql.DB) error {
 		return fmt.Errorf("creating annotations table: %w", err)
 	}
 
+	if err := createRepoRefsTable(db); err != nil {
+		return fmt.Errorf("creating repo_refs table: %w", err)
+	}
+
+	if err := createScansTable(db); err != nil {
+		return fmt.Errorf("creating scans table: %w", err)
+	}
+
+	if err := createSettingsTable(db); err != nil {
+		return fmt.Errorf("creating settings table: %w", err)
+	}
+
 	return nil
 }
 
@@ -73,10 +85,17 @@ func createBlobsTable(db *sql.DB) error {
 	_, err := db.Exec(`
 		CREATE TABLE IF NOT EXISTS blobs (
 			id TEXT PRIMARY KEY NOT NULL,
-			size INTEGER NOT NULL
+			size INTEGER NOT NULL,
+			scan_id INTEGER
 		)
 	`)
-	return err
+	if err != nil {
+		return err
+	}
+
+	// Migrate old datastores: add the scan column if missing
+	db.Exec("ALTER TABLE blobs ADD COLUMN scan_id INTEGER")
+	return nil
 }
 
 func createRulesTable(db *sql.DB) error {
@@ -112,10 +131,19 @@ func createMatchesTable(db *sql.DB) error {
 			start_line INTEGER,
 			start_column INTEGER,
 			end_line INTEGER,
-			end_column INTEGER
+			end_column INTEGER,
+			scan_id INTEGER,
+			kind TEXT
 		)
 	`)
-	return err
+	if err != nil {
+		return err
+	}
+
+	// Migrate old datastores: add the scan and kind columns if missing
+	db.Exec("ALTER TABLE matches ADD COLUMN scan_id INTEGER")
+	db.Exec("ALTER TABLE matches ADD COLUMN kind TEXT")
+	return nil
 }
 
 func createFindingsTable(db *sql.DB) error {
@@ -124,10 +152,26 @@ func createFindingsTable(db *sql.DB) error {
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			structural_id TEXT NOT NULL UNIQUE,
 			rule_id TEXT NOT NULL,
-			groups_json TEXT
+			groups_json TEXT,
+			state TEXT,
+			first_seen TEXT,
+			last_seen TEXT
 		)
 	`)
-	return err
+	if err != nil {
+		return err
+	}
+
+	// Migrate old datastores: add lifecycle columns if missing (errors mean
+	// the column already exists).
+	for _, col := range []string{
+		"state TEXT",
+		"first_seen TEXT",
+		"last_seen TEXT",
+	} {
+		db.Exec("ALTER TABLE findings ADD COLUMN " + col)
+	}
+	return nil
 }
 
 func createProvenanceTable(db *sql.DB) error {
@@ -146,6 +190,7 @@ func createProvenanceTable(db *sql.DB) error {
 			committer_email TEXT,
 			committer_timestamp TEXT,
 			commit_message TEXT,
+			file_type TEXT,
 			UNIQUE(blob_id, type, path, repo_path, commit_hash)
 		)
 	`)
@@ -164,6 +209,7 @@ func createProvenanceTable(db *sql.DB) error {
 		"committer_email TEXT",
 		"committer_timestamp TEXT",
 		"commit_message TEXT",
+		"file_type TEXT",
 	} {
 		db.Exec("ALTER TABLE provenance ADD COLUMN " + col)
 	}
@@ -184,8 +230,51 @@ func createAnnotationsTable(db *sql.DB) error {
 			status TEXT,
 			comment TEXT,
 			updated_at TEXT NOT NULL,
+			author TEXT,
 			UNIQUE(target_type, target_id)
 		)
 	`)
+	if err != nil {
+		return err
+	}
+	db.Exec("ALTER TABLE annotations ADD COLUMN author TEXT")
+	return nil
+}
+
+func createRepoRefsTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS repo_refs (
+			repo TEXT NOT NULL,
+			ref TEXT NOT NULL,
+			commit_hash TEXT NOT NULL,
+			updated_at TEXT NOT NULL,
+			PRIMARY KEY (repo, ref)
+		)
+	`)
+	return err
+}
+
+func createScansTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS scans (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			started_at TEXT NOT NULL,
+			finished_at TEXT,
+			args_json TEXT,
+			version TEXT,
+			rules_hash TEXT,
+			target TEXT
+		)
+	`)
+	return err
+}
+
+func createSettingsTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS settings (
+			key TEXT PRIMARY KEY NOT NULL,
+			value TEXT NOT NULL
+		)
+	`)
 	return err
 }
@@ -4,8 +4,10 @@ package store
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -22,8 +24,9 @@ type dbLike interface {
 }
 
 type SQLiteStore struct {
-	db *sql.DB // root connection (nil for tx-scoped stores)
-	e  dbLike  // active execer: db or tx
+	db     *sql.DB // root connection (nil for tx-scoped stores)
+	e      dbLike  // active execer: db or tx
+	scanID int64   // scan new blobs and matches are attributed to (0 = none)
 }
 
 func init() {
@@ -59,7 +62,7 @@ func NewSQLite(path string) (*SQLiteStore, error) {
 }
 
 func (s *SQLiteStore) AddBlob(id types.BlobID, size int64) error {
-	_, err := s.e.Exec("INSERT OR IGNORE INTO blobs (id, size) VALUES (?, ?)", id.Hex(), size)
+	_, err := s.e.Exec("INSERT OR IGNORE INTO blobs (id, size, scan_id) VALUES (?, ?, ?)", id.Hex(), size, nullScanID(s.scanID))
 	return err
 }
 
@@ -107,16 +110,21 @@ func (s *SQLiteStore) AddMatch(m *types.Match) error {
 	// finding_id is null for now
 	var findingID sql.NullInt64
 
-	_, err = s.e.Exec(`INSERT OR IGNORE INTO matches (blob_id, rule_id, structural_id, offset_start, offset_end, snippet_before, snippet_matching, snippet_after, groups_json, validation_status, validation_confidence, validation_message, validation_timestamp, finding_id, start_line, start_column, end_line, end_column) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	scanID := m.ScanID
+	if scanID == 0 {
+		scanID = s.scanID
+	}
+
+	_, err = s.e.Exec(`INSERT OR IGNORE INTO matches (blob_id, rule_id, structural_id, offset_start, offset_end, snippet_before, snippet_matching, snippet_after, groups_json, validation_status, validation_confidence, validation_message, validation_timestamp, finding_id, start_line, start_column, end_line, end_column, scan_id, kind) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		m.BlobID.Hex(), m.RuleID, m.StructuralID, m.Location.Offset.Start, m.Location.Offset.End,
 		m.Snippet.Before, m.Snippet.Matching, m.Snippet.After, groupsJSON,
 		validationStatus, validationConfidence, validationMessage, validationTimestamp,
-		findingID, startLine, startColumn, endLine, endColumn)
+		findingID, startLine, startColumn, endLine, endColumn, nullScanID(scanID), sql.NullString{String: m.Kind, Valid: m.Kind != ""})
 	return err
 }
 
 func (s *SQLiteStore) GetMatches(blobID types.BlobID) ([]*types.Match, error) {
-	rows, err := s.e.Query(`SELECT m.blob_id, m.rule_id, r.name, m.structural_id, m.offset_start, m.offset_end, m.snippet_before, m.snippet_matching, m.snippet_after, m.groups_json, m.validation_status, m.validation_confidence, m.validation_message, m.validation_timestamp, m.finding_id, m.start_line, m.start_column, m.end_line, m.end_column FROM matches m JOIN rules r ON m.rule_id = r.id WHERE m.blob_id = ?`, blobID.Hex())
+	rows, err := s.e.Query(`SELECT m.blob_id, m.rule_id, r.name, m.structural_id, m.offset_start, m.offset_end, m.snippet_before, m.snippet_matching, m.snippet_after, m.groups_json, m.validation_status, m.validation_confidence, m.validation_message, m.validation_timestamp, m.finding_id, m.start_line, m.start_column, m.end_line, m.end_column, m.scan_id, m.kind FROM matches m JOIN rules r ON m.rule_id = r.id WHERE m.blob_id = ?`, blobID.Hex())
 	if err != nil {
 		return nil, err
 	}
@@ -125,7 +133,7 @@ func (s *SQLiteStore) GetMatches(blobID types.BlobID) ([]*types.Match, error) {
 }
 
 func (s *SQLiteStore) GetAllMatches() ([]*types.Match, error) {
-	rows, err := s.e.Query(`SELECT m.blob_id, m.rule_id, r.name, m.structural_id, m.offset_start, m.offset_end, m.snippet_before, m.snippet_matching, m.snippet_after, m.groups_json, m.validation_status, m.validation_confidence, m.validation_message, m.validation_timestamp, m.finding_id, m.start_line, m.start_column, m.end_line, m.end_column FROM matches m JOIN rules r ON m.rule_id = r.id`)
+	rows, err := s.e.Query(`SELECT m.blob_id, m.rule_id, r.name, m.structural_id, m.offset_start, m.offset_end, m.snippet_before, m.snippet_matching, m.snippet_after, m.groups_json, m.validation_status, m.validation_confidence, m.validation_message, m.validation_timestamp, m.finding_id, m.start_line, m.start_column, m.end_line, m.end_column, m.scan_id, m.kind FROM matches m JOIN rules r ON m.rule_id = r.id`)
 	if err != nil {
 		return nil, err
 	}
@@ -133,31 +141,88 @@ func (s *SQLiteStore) GetAllMatches() ([]*types.Match, error) {
 	return scanMatches(rows)
 }
 
+func (s *SQLiteStore) GetMatchesSince(after int64) ([]*types.Match, int64, error) {
+	var last int64
+	if err := s.e.QueryRow("SELECT COALESCE(MAX(id), 0) FROM matches").Scan(&last); err != nil {
+		return nil, after, err
+	}
+	if last <= after {
+		return []*types.Match{}, after, nil
+	}
+	rows, err := s.e.Query(`SELECT m.blob_id, m.rule_id, r.name, m.structural_id, m.offset_start, m.offset_end, m.snippet_before, m.snippet_matching, m.snippet_after, m.groups_json, m.validation_status, m.validation_confidence, m.validation_message, m.validation_timestamp, m.finding_id, m.start_line, m.start_column, m.end_line, m.end_column, m.scan_id, m.kind FROM matches m JOIN rules r ON m.rule_id = r.id WHERE m.id > ? AND m.id <= ? ORDER BY m.id`, after, last)
+	if err != nil {
+		return nil, after, err
+	}
+	defer rows.Close()
+	matches, err := scanMatches(rows)
+	if err != nil {
+		return nil, after, err
+	}
+	return matches, last, nil
+}
+
 func (s *SQLiteStore) AddFinding(f *types.Finding) error {
 	groupsJSON, err := serializeGroups(f.Groups)
 	if err != nil {
 		return fmt.Errorf("serializing groups: %w", err)
 	}
-	_, err = s.e.Exec("INSERT OR IGNORE INTO findings (structural_id, rule_id, groups_json) VALUES (?, ?, ?)", f.ID, f.RuleID, groupsJSON)
+	state, firstSeen, lastSeen := findingLifecycle(f)
+	_, err = s.e.Exec("INSERT OR IGNORE INTO findings (structural_id, rule_id, groups_json, state, first_seen, last_seen) VALUES (?, ?, ?, ?, ?, ?)",
+		f.ID, f.RuleID, groupsJSON, string(state), firstSeen.Format(time.RFC3339), lastSeen.Format(time.RFC3339))
 	return err
 }
 
 func (s *SQLiteStore) GetFindings() ([]*types.Finding, error) {
-	rows, err := s.e.Query("SELECT structural_id, rule_id, groups_json FROM findings")
+	rows, err := s.e.Query("SELECT structural_id, rule_id, groups_json, state, first_seen, last_seen FROM findings")
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
+	return scanFindings(rows)
+}
+
+func (s *SQLiteStore) GetFindingsSince(after int64) ([]*types.Finding, int64, error) {
+	var last int64
+	if err := s.e.QueryRow("SELECT COALESCE(MAX(id), 0) FROM findings").Scan(&last); err != nil {
+		return nil, after, err
+	}
+	if last <= after {
+		return []*types.Finding{}, after, nil
+	}
+	rows, err := s.e.Query("SELECT structural_id, rule_id, groups_json, state, first_seen, last_seen FROM findings WHERE id > ? AND id <= ? ORDER BY id", after, last)
+	if err != nil {
+		return nil, after, err
+	}
+	defer rows.Close()
+	findings, err := scanFindings(rows)
+	if err != nil {
+		return nil, after, err
+	}
+	return findings, last, nil
+}
+
+func scanFindings(rows *sql.Rows) ([]*types.Finding, error) {
 	var result []*types.Finding
 	for rows.Next() {
 		var f types.Finding
-		var groupsJSON sql.NullString
-		if err := rows.Scan(&f.ID, &f.RuleID, &groupsJSON); err != nil {
+		var groupsJSON, state, firstSeen, lastSeen sql.NullString
+		if err := rows.Scan(&f.ID, &f.RuleID, &groupsJSON, &state, &firstSeen, &lastSeen); err != nil {
 			return nil, err
 		}
 		if groupsJSON.Valid {
 			f.Groups, _ = deserializeGroups(groupsJSON.String)
 		}
+		// Findings from datastores that predate lifecycle tracking are new
+		f.State = types.FindingState(state.String)
+		if f.State == "" {
+			f.State = types.FindingStateNew
+		}
+		if firstSeen.Valid {
+			f.FirstSeen, _ = time.Parse(time.RFC3339, firstSeen.String)
+		}
+		if lastSeen.Valid {
+			f.LastSeen, _ = time.Parse(time.RFC3339, lastSeen.String)
+		}
 		result = append(result, &f)
 	}
 	if result == nil {
@@ -172,16 +237,51 @@ func (s *SQLiteStore) FindingExists(structuralID string) (bool, error) {
 	return count > 0, err
 }
 
+// MarkFindingsSeen updates last_seen and applies FindingState.Seen in SQL.
+func (s *SQLiteStore) MarkFindingsSeen(ids []string, at time.Time) error {
+	ts := at.UTC().Format(time.RFC3339)
+	for _, id := range ids {
+		_, err := s.e.Exec(`
+			UPDATE findings SET
+				first_seen = COALESCE(first_seen, ?),
+				last_seen = ?,
+				state = CASE
+					WHEN state = ? THEN ?
+					WHEN state IS NULL OR state = '' THEN ?
+					ELSE state
+				END
+			WHERE structural_id = ?`,
+			ts, ts,
+			string(types.FindingStateRemediated), string(types.FindingStateRegressed),
+			string(types.FindingStateNew), id)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) SetFindingState(id string, state types.FindingState) error {
+	_, err := s.e.Exec("UPDATE findings SET state = ? WHERE structural_id = ?", string(state), id)
+	return err
+}
+
+func (s *SQLiteStore) SetMatchValidation(structuralID string, result *types.ValidationResult) error {
+	_, err := s.e.Exec("UPDATE matches SET validation_status = ?, validation_confidence = ?, validation_message = ?, validation_timestamp = ? WHERE structural_id = ?",
+		string(result.Status), result.Confidence, result.Message, result.ValidatedAt.Format(time.RFC3339), structuralID)
+	return err
+}
+
 func (s *SQLiteStore) AddProvenance(blobID types.BlobID, prov types.Provenance) error {
 	var provType, path, repoPath, commitHash string
 	var authorName, authorEmail, authorTimestamp string
 	var committerName, committerEmail, committerTimestamp string
-	var commitMessage string
+	var commitMessage, fileType string
 	switch p := prov.(type) {
 	case types.FileProvenance:
-		provType, path = "file", p.FilePath
+		provType, path, fileType = "file", p.FilePath, p.FileType
 	case types.GitProvenance:
-		provType, path, repoPath = "git", p.BlobPath, p.RepoPath
+		provType, path, repoPath, fileType = "git", p.BlobPath, p.RepoPath, p.FileType
 		if p.Commit != nil {
 			commitHash = p.Commit.CommitID
 			authorName = p.Commit.AuthorName
@@ -196,6 +296,8 @@ func (s *SQLiteStore) AddProvenance(blobID types.BlobID, prov types.Provenance)
 			}
 			commitMessage = p.Commit.Message
 		}
+	case types.ArchiveProvenance:
+		provType, path, repoPath = "archive", p.MemberPath, p.ArchivePath
 	case types.ExtendedProvenance:
 		provType = "extended"
 		payloadJSON, _ := json.Marshal(p.Payload)
@@ -204,12 +306,12 @@ func (s *SQLiteStore) AddProvenance(blobID types.BlobID, prov types.Provenance)
 		return fmt.Errorf("unknown provenance type: %T", prov)
 	}
 	_, err := s.e.Exec(`INSERT OR IGNORE INTO provenance
-		(blob_id, type, path, repo_path, commit_hash, author_name, author_email, author_timestamp, committer_name, committer_email, committer_timestamp, commit_message)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		(blob_id, type, path, repo_path, commit_hash, author_name, author_email, author_timestamp, committer_name, committer_email, committer_timestamp, commit_message, file_type)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		blobID.Hex(), provType, path, repoPath, commitHash,
 		authorName, authorEmail, authorTimestamp,
 		committerName, committerEmail, committerTimestamp,
-		commitMessage)
+		commitMessage, fileType)
 	return err
 }
 
@@ -227,7 +329,7 @@ func (s *SQLiteStore) getAllProvenanceFull(blobID types.BlobID) ([]types.Provena
 	rows, err := s.e.Query(`SELECT type, path, repo_path, commit_hash,
 		author_name, author_email, author_timestamp,
 		committer_name, committer_email, committer_timestamp,
-		commit_message FROM provenance WHERE blob_id = ?`, blobID.Hex())
+		commit_message, file_type FROM provenance WHERE blob_id = ?`, blobID.Hex())
 	if err != nil {
 		return nil, err
 	}
@@ -238,18 +340,18 @@ func (s *SQLiteStore) getAllProvenanceFull(blobID types.BlobID) ([]types.Provena
 		var path, repoPath, commitHash sql.NullString
 		var authorName, authorEmail, authorTS sql.NullString
 		var committerName, committerEmail, committerTS sql.NullString
-		var commitMessage sql.NullString
+		var commitMessage, fileType sql.NullString
 		if err := rows.Scan(&provType, &path, &repoPath, &commitHash,
 			&authorName, &authorEmail, &authorTS,
 			&committerName, &committerEmail, &committerTS,
-			&commitMessage); err != nil {
+			&commitMessage, &fileType); err != nil {
 			return nil, err
 		}
 		switch provType {
 		case "file":
-			result = append(result, types.FileProvenance{FilePath: path.String})
+			result = append(result, types.FileProvenance{FilePath: path.String, FileType: fileType.String})
 		case "git":
-			prov := types.GitProvenance{RepoPath: repoPath.String, BlobPath: path.String}
+			prov := types.GitProvenance{RepoPath: repoPath.String, BlobPath: path.String, FileType: fileType.String}
 			if commitHash.Valid && commitHash.String != "" {
 				meta := &types.CommitMetadata{
 					CommitID:      commitHash.String,
@@ -268,6 +370,8 @@ func (s *SQLiteStore) getAllProvenanceFull(blobID types.BlobID) ([]types.Provena
 				prov.Commit = meta
 			}
 			result = append(result, prov)
+		case "archive":
+			result = append(result, types.ArchiveProvenance{ArchivePath: repoPath.String, MemberPath: path.String})
 		case "extended":
 			var payload map[string]interface{}
 			if path.Valid {
@@ -304,6 +408,8 @@ func (s *SQLiteStore) getAllProvenanceLegacy(blobID types.BlobID) ([]types.Prove
 				prov.Commit = &types.CommitMetadata{CommitID: commitHash.String}
 			}
 			result = append(result, prov)
+		case "archive":
+			result = append(result, types.ArchiveProvenance{ArchivePath: repoPath.String, MemberPath: path.String})
 		case "extended":
 			var payload map[string]interface{}
 			if path.Valid {
@@ -334,7 +440,7 @@ func (s *SQLiteStore) ExecBatch(fn func(Store) error) error {
 	if err != nil {
 		return fmt.Errorf("beginning transaction: %w", err)
 	}
-	txStore := &SQLiteStore{e: tx} // db is nil; ExecBatch on txStore would panic, which is correct
+	txStore := &SQLiteStore{e: tx, scanID: s.scanID} // db is nil; ExecBatch on txStore would panic, which is correct
 	if err := fn(txStore); err != nil {
 		tx.Rollback()
 		return err
@@ -382,6 +488,133 @@ func (s *SQLiteStore) SetAnnotation(targetType, targetID, status, comment string
 	return err
 }
 
+// annotationTimeLayout is the format of updated_at values written by
+// SQLite's datetime('now').
+const annotationTimeLayout = "2006-01-02 15:04:05"
+
+func (s *SQLiteStore) GetAnnotations() ([]*types.Annotation, error) {
+	rows, err := s.e.Query("SELECT target_type, target_id, status, comment, author, updated_at FROM annotations ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []*types.Annotation
+	for rows.Next() {
+		var a types.Annotation
+		var status, comment, author sql.NullString
+		var updatedAt string
+		if err := rows.Scan(&a.TargetType, &a.TargetID, &status, &comment, &author, &updatedAt); err != nil {
+			return nil, err
+		}
+		a.Status, a.Comment, a.Author = status.String, comment.String, author.String
+		if a.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAt); err != nil {
+			a.UpdatedAt, _ = time.Parse(annotationTimeLayout, updatedAt)
+		}
+		result = append(result, &a)
+	}
+	return result, rows.Err()
+}
+
+func (s *SQLiteStore) PutAnnotation(a *types.Annotation) error {
+	nullString := func(v string) sql.NullString {
+		return sql.NullString{String: v, Valid: v != ""}
+	}
+	_, err := s.e.Exec(`
+		INSERT INTO annotations (target_type, target_id, status, comment, author, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(target_type, target_id)
+		DO UPDATE SET status = excluded.status, comment = excluded.comment, author = excluded.author, updated_at = excluded.updated_at`,
+		a.TargetType, a.TargetID, nullString(a.Status), nullString(a.Comment), nullString(a.Author),
+		a.UpdatedAt.UTC().Format(time.RFC3339Nano),
+	)
+	return err
+}
+
+func (s *SQLiteStore) GetRepoRefs(repo string) (map[string]string, error) {
+	rows, err := s.e.Query("SELECT ref, commit_hash FROM repo_refs WHERE repo = ?", repo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	refs := make(map[string]string)
+	for rows.Next() {
+		var ref, commit string
+		if err := rows.Scan(&ref, &commit); err != nil {
+			return nil, err
+		}
+		refs[ref] = commit
+	}
+	return refs, rows.Err()
+}
+
+func (s *SQLiteStore) SetRepoRef(repo, ref, commit string) error {
+	_, err := s.e.Exec(`
+		INSERT INTO repo_refs (repo, ref, commit_hash, updated_at)
+		VALUES (?, ?, ?, datetime('now'))
+		ON CONFLICT(repo, ref)
+		DO UPDATE SET commit_hash = excluded.commit_hash, updated_at = excluded.updated_at`,
+		repo, ref, commit,
+	)
+	return err
+}
+
+// AddScan records the start of a scan. Later blobs and matches stored
+// through s, or through the transactions of its ExecBatch, are attributed
+// to it.
+func (s *SQLiteStore) AddScan(scan *types.Scan) error {
+	argsJSON, err := json.Marshal(scan.Args)
+	if err != nil {
+		return fmt.Errorf("serializing args: %w", err)
+	}
+	res, err := s.e.Exec("INSERT INTO scans (started_at, args_json, version, rules_hash, target) VALUES (?, ?, ?, ?, ?)",
+		scan.StartedAt.UTC().Format(time.RFC3339Nano), string(argsJSON), scan.Version, scan.RulesHash, scan.Target)
+	if err != nil {
+		return err
+	}
+	if scan.ID, err = res.LastInsertId(); err != nil {
+		return err
+	}
+	s.scanID = scan.ID
+	return nil
+}
+
+func (s *SQLiteStore) FinishScan(scan *types.Scan) error {
+	_, err := s.e.Exec("UPDATE scans SET finished_at = ? WHERE id = ?", scan.FinishedAt.UTC().Format(time.RFC3339Nano), scan.ID)
+	return err
+}
+
+func (s *SQLiteStore) GetScans() ([]*types.Scan, error) {
+	rows, err := s.e.Query("SELECT id, started_at, finished_at, args_json, version, rules_hash, target FROM scans ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := []*types.Scan{}
+	for rows.Next() {
+		var scan types.Scan
+		var startedAt string
+		var finishedAt, argsJSON, version, rulesHash, target sql.NullString
+		if err := rows.Scan(&scan.ID, &startedAt, &finishedAt, &argsJSON, &version, &rulesHash, &target); err != nil {
+			return nil, err
+		}
+		scan.StartedAt, _ = time.Parse(time.RFC3339Nano, startedAt)
+		if finishedAt.Valid {
+			scan.FinishedAt, _ = time.Parse(time.RFC3339Nano, finishedAt.String)
+		}
+		if argsJSON.Valid {
+			json.Unmarshal([]byte(argsJSON.String), &scan.Args)
+		}
+		scan.Version, scan.RulesHash, scan.Target = version.String, rulesHash.String, target.String
+		result = append(result, &scan)
+	}
+	return result, rows.Err()
+}
+
+// nullScanID stores 0, no scan, as NULL.
+func nullScanID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
+
 func scanMatches(rows *sql.Rows) ([]*types.Match, error) {
 	var result []*types.Match
 	for rows.Next() {
@@ -391,11 +624,12 @@ func scanMatches(rows *sql.Rows) ([]*types.Match, error) {
 		var snippetBefore, snippetMatching, snippetAfter []byte
 		var validationStatus, validationMessage, validationTimestamp sql.NullString
 		var validationConfidence sql.NullFloat64
-		var findingID, startLine, startColumn, endLine, endColumn sql.NullInt64
+		var findingID, startLine, startColumn, endLine, endColumn, scanID sql.NullInt64
+		var kind sql.NullString
 		err := rows.Scan(&blobIDHex, &m.RuleID, &m.RuleName, &m.StructuralID, &m.Location.Offset.Start, &m.Location.Offset.End,
 			&snippetBefore, &snippetMatching, &snippetAfter, &groupsJSON,
 			&validationStatus, &validationConfidence, &validationMessage, &validationTimestamp,
-			&findingID, &startLine, &startColumn, &endLine, &endColumn)
+			&findingID, &startLine, &startColumn, &endLine, &endColumn, &scanID, &kind)
 		if err != nil {
 			return nil, err
 		}
@@ -427,6 +661,8 @@ func scanMatches(rows *sql.Rows) ([]*types.Match, error) {
 		if endColumn.Valid {
 			m.Location.Source.End.Column = int(endColumn.Int64)
 		}
+		m.ScanID = scanID.Int64
+		m.Kind = kind.String
 		result = append(result, &m)
 	}
 	if result == nil {
@@ -461,3 +697,76 @@ func deserializeGroups(data string) ([][]byte, error) {
 	}
 	return result, nil
 }
+
+// GetFingerprintVersion reads the fingerprint_version setting.
+func (s *SQLiteStore) GetFingerprintVersion() (types.FingerprintVersion, error) {
+	var v int
+	err := s.e.QueryRow("SELECT value FROM settings WHERE key = 'fingerprint_version'").Scan(&v)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return types.FingerprintVersion(v), err
+}
+
+// SetFingerprintVersion writes the fingerprint_version setting.
+func (s *SQLiteStore) SetFingerprintVersion(v types.FingerprintVersion) error {
+	_, err := s.e.Exec(`
+		INSERT INTO settings (key, value) VALUES ('fingerprint_version', ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value`,
+		int(v),
+	)
+	return err
+}
+
+// SecretKey reads the secret_key setting, creating it if it isn't set.
+func (s *SQLiteStore) SecretKey() ([]byte, error) {
+	key := make([]byte, secretKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	// Another process may set it first; the key kept is the one read back
+	if _, err := s.e.Exec("INSERT OR IGNORE INTO settings (key, value) VALUES ('secret_key', ?)", hex.EncodeToString(key)); err != nil {
+		return nil, err
+	}
+	var value string
+	if err := s.e.QueryRow("SELECT value FROM settings WHERE key = 'secret_key'").Scan(&value); err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(value)
+}
+
+// RemapFindings moves every remapped finding and its annotation to a
+// temporary ID first, so that IDs which swap or chain don't collide, then
+// to its new ID. Rows whose new ID is taken are dropped in favour of the
+// row that has it. Call it inside ExecBatch to remap atomically.
+func (s *SQLiteStore) RemapFindings(ids map[string]string) error {
+	temp := func(id string) string { return "remap:" + id }
+	var remapped []string
+	for _, oldID := range sortedKeys(ids) {
+		if ids[oldID] != oldID {
+			remapped = append(remapped, oldID)
+		}
+	}
+	for _, oldID := range remapped {
+		if _, err := s.e.Exec("UPDATE findings SET structural_id = ? WHERE structural_id = ?", temp(oldID), oldID); err != nil {
+			return err
+		}
+		if _, err := s.e.Exec("UPDATE annotations SET target_id = ? WHERE target_type = 'finding' AND target_id = ?", temp(oldID), oldID); err != nil {
+			return err
+		}
+	}
+	for _, oldID := range remapped {
+		newID := ids[oldID]
+		for _, q := range []string{
+			"UPDATE OR IGNORE findings SET structural_id = ? WHERE structural_id = ?",
+			"DELETE FROM findings WHERE structural_id = ?2",
+			"UPDATE OR IGNORE annotations SET target_id = ? WHERE target_type = 'finding' AND target_id = ?",
+			"DELETE FROM annotations WHERE target_type = 'finding' AND target_id = ?2",
+		} {
+			if _, err := s.e.Exec(q, newID, temp(oldID)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -3,6 +3,7 @@
 package store
 
 import (
+	"database/sql"
 	"path/filepath"
 	"testing"
 	"time"
@@ -357,3 +358,373 @@ func TestSQLite_ProvenanceWithCommitMetadata(t *testing.T) {
 	assert.Equal(t, committerTS, got.Commit.CommitterTimestamp)
 	assert.Equal(t, "add config", got.Commit.Message)
 }
+
+func TestSQLite_RepoRefs(t *testing.T) {
+	store, err := NewSQLite(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	refs, err := store.GetRepoRefs("https://github.com/org/repo")
+	require.NoError(t, err)
+	assert.Empty(t, refs)
+
+	require.NoError(t, store.SetRepoRef("https://github.com/org/repo", "refs/heads/main", "aaaa"))
+	require.NoError(t, store.SetRepoRef("https://github.com/org/repo", "refs/heads/dev", "bbbb"))
+	require.NoError(t, store.SetRepoRef("https://github.com/org/repo", "refs/heads/main", "cccc"))
+	require.NoError(t, store.SetRepoRef("https://github.com/org/other", "refs/heads/main", "dddd"))
+
+	refs, err = store.GetRepoRefs("https://github.com/org/repo")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"refs/heads/main": "cccc",
+		"refs/heads/dev":  "bbbb",
+	}, refs)
+}
+
+func TestSQLite_FindingLifecycle(t *testing.T) {
+	store, err := NewSQLite(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	first := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, store.AddFinding(&types.Finding{ID: "f1", RuleID: "np.test.1", FirstSeen: first}))
+
+	findings, err := store.GetFindings()
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, types.FindingStateNew, findings[0].State)
+	assert.Equal(t, first, findings[0].FirstSeen)
+	assert.Equal(t, first, findings[0].LastSeen)
+
+	// Remediated, then found again by a later scan
+	require.NoError(t, store.SetFindingState("f1", types.FindingStateRemediated))
+	later := first.Add(30 * 24 * time.Hour)
+	require.NoError(t, store.MarkFindingsSeen([]string{"f1", "unknown"}, later))
+
+	findings, err = store.GetFindings()
+	require.NoError(t, err)
+	assert.Equal(t, types.FindingStateRegressed, findings[0].State)
+	assert.Equal(t, first, findings[0].FirstSeen)
+	assert.Equal(t, later, findings[0].LastSeen)
+
+	// Triaged findings stay triaged when seen again
+	require.NoError(t, store.SetFindingState("f1", types.FindingStateTriaged))
+	require.NoError(t, store.MarkFindingsSeen([]string{"f1"}, later))
+	findings, err = store.GetFindings()
+	require.NoError(t, err)
+	assert.Equal(t, types.FindingStateTriaged, findings[0].State)
+}
+
+func TestSQLite_FindingLifecycleMigration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE findings (id INTEGER PRIMARY KEY AUTOINCREMENT, structural_id TEXT NOT NULL UNIQUE, rule_id TEXT NOT NULL, groups_json TEXT)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO findings (structural_id, rule_id) VALUES ('old', 'np.test.1')`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	store, err := NewSQLite(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	findings, err := store.GetFindings()
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, types.FindingStateNew, findings[0].State)
+	assert.True(t, findings[0].FirstSeen.IsZero())
+
+	now := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, store.MarkFindingsSeen([]string{"old"}, now))
+	findings, err = store.GetFindings()
+	require.NoError(t, err)
+	assert.Equal(t, now, findings[0].FirstSeen)
+}
+
+func TestSQLite_ProvenanceFileType(t *testing.T) {
+	store, err := NewSQLite(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	blobID := types.ComputeBlobID([]byte("resource {}"))
+	require.NoError(t, store.AddBlob(blobID, 11))
+	require.NoError(t, store.AddProvenance(blobID, types.FileProvenance{FilePath: "main.tf", FileType: "Terraform"}))
+	require.NoError(t, store.AddProvenance(blobID, types.GitProvenance{RepoPath: "/repo", BlobPath: "infra/main.tf", FileType: "Terraform"}))
+
+	provs, err := store.GetAllProvenance(blobID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []types.Provenance{
+		types.FileProvenance{FilePath: "main.tf", FileType: "Terraform"},
+		types.GitProvenance{RepoPath: "/repo", BlobPath: "infra/main.tf", FileType: "Terraform"},
+	}, provs)
+}
+
+func TestSQLite_ArchiveProvenance(t *testing.T) {
+	store, err := NewSQLite(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	blobID := types.ComputeBlobID([]byte(`password = "hunter22"`))
+	require.NoError(t, store.AddBlob(blobID, 21))
+	prov := types.ArchiveProvenance{ArchivePath: "terraform.tfstate", MemberPath: "aws_db_instance.main"}
+	require.NoError(t, store.AddProvenance(blobID, prov))
+	require.NoError(t, store.AddProvenance(blobID, prov))
+
+	provs, err := store.GetAllProvenance(blobID)
+	require.NoError(t, err)
+	assert.Equal(t, []types.Provenance{prov}, provs)
+}
+
+func TestSQLite_Scans(t *testing.T) {
+	store, err := NewSQLite(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	require.NoError(t, store.AddRule(&types.Rule{ID: "np.test.1", Name: "Test"}))
+	blobID := types.ComputeBlobID([]byte("before"))
+	require.NoError(t, store.AddBlob(blobID, 6))
+	require.NoError(t, store.AddMatch(&types.Match{BlobID: blobID, RuleID: "np.test.1", StructuralID: "m0"}))
+
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	scan := &types.Scan{StartedAt: started, Args: []string{"scan", "."}, Version: "1.0.0", RulesHash: "abc", Target: "."}
+	require.NoError(t, store.AddScan(scan))
+	assert.NotZero(t, scan.ID)
+
+	blobID = types.ComputeBlobID([]byte("during"))
+	require.NoError(t, store.ExecBatch(func(tx Store) error {
+		if err := tx.AddBlob(blobID, 6); err != nil {
+			return err
+		}
+		return tx.AddMatch(&types.Match{BlobID: blobID, RuleID: "np.test.1", StructuralID: "m1"})
+	}))
+
+	scan.FinishedAt = started.Add(time.Minute)
+	require.NoError(t, store.FinishScan(scan))
+
+	scans, err := store.GetScans()
+	require.NoError(t, err)
+	require.Len(t, scans, 1)
+	assert.Equal(t, scan, scans[0])
+
+	matches, err := store.GetAllMatches()
+	require.NoError(t, err)
+	scanIDs := map[string]int64{}
+	for _, m := range matches {
+		scanIDs[m.StructuralID] = m.ScanID
+	}
+	assert.Equal(t, map[string]int64{"m0": 0, "m1": scan.ID}, scanIDs)
+}
+
+func TestSQLite_ScansMigration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE blobs (id TEXT PRIMARY KEY NOT NULL, size INTEGER NOT NULL)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE matches (id INTEGER PRIMARY KEY AUTOINCREMENT, blob_id TEXT NOT NULL, rule_id TEXT NOT NULL, structural_id TEXT NOT NULL UNIQUE, offset_start INTEGER NOT NULL, offset_end INTEGER NOT NULL, snippet_before BLOB, snippet_matching BLOB, snippet_after BLOB, groups_json TEXT, validation_status TEXT, validation_confidence REAL, validation_message TEXT, validation_timestamp TEXT, finding_id INTEGER, start_line INTEGER, start_column INTEGER, end_line INTEGER, end_column INTEGER)`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	store, err := NewSQLite(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	require.NoError(t, store.AddScan(&types.Scan{StartedAt: time.Now()}))
+	require.NoError(t, store.AddRule(&types.Rule{ID: "np.test.1", Name: "Test"}))
+	blobID := types.ComputeBlobID([]byte("content"))
+	require.NoError(t, store.AddBlob(blobID, 7))
+	require.NoError(t, store.AddMatch(&types.Match{BlobID: blobID, RuleID: "np.test.1", StructuralID: "m1"}))
+
+	matches, err := store.GetAllMatches()
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, int64(1), matches[0].ScanID)
+}
+
+func TestSQLite_MatchKind(t *testing.T) {
+	store, err := NewSQLite(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	require.NoError(t, store.AddRule(&types.Rule{ID: "np.test.1", Name: "Test"}))
+	blobID := types.ComputeBlobID([]byte("keys/id_rsa"))
+	require.NoError(t, store.AddBlob(blobID, 11))
+	require.NoError(t, store.AddMatch(&types.Match{BlobID: blobID, RuleID: "np.test.1", StructuralID: "m0"}))
+	require.NoError(t, store.AddMatch(&types.Match{BlobID: blobID, RuleID: "np.test.1", StructuralID: "m1", Kind: types.RuleKindFilename}))
+
+	matches, err := store.GetMatches(blobID)
+	require.NoError(t, err)
+	kinds := map[string]string{}
+	for _, m := range matches {
+		kinds[m.StructuralID] = m.Kind
+	}
+	assert.Equal(t, map[string]string{"m0": "", "m1": "filename"}, kinds)
+}
+
+func TestSQLite_SetMatchValidation(t *testing.T) {
+	store, err := NewSQLite(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	require.NoError(t, store.AddRule(&types.Rule{ID: "np.test.1", Name: "Test"}))
+	blobID := types.ComputeBlobID([]byte("token"))
+	require.NoError(t, store.AddBlob(blobID, 5))
+	require.NoError(t, store.AddMatch(&types.Match{BlobID: blobID, RuleID: "np.test.1", StructuralID: "m0"}))
+
+	result := types.NewValidationResult(types.StatusValid, 0.9, "active")
+	require.NoError(t, store.SetMatchValidation("m0", result))
+
+	matches, err := store.GetMatches(blobID)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	require.NotNil(t, matches[0].ValidationResult)
+	assert.Equal(t, types.StatusValid, matches[0].ValidationResult.Status)
+	assert.Equal(t, 0.9, matches[0].ValidationResult.Confidence)
+	assert.Equal(t, "active", matches[0].ValidationResult.Message)
+	assert.WithinDuration(t, result.ValidatedAt, matches[0].ValidationResult.ValidatedAt, time.Second)
+}
+
+func TestSQLite_FingerprintVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	store, err := NewSQLite(path)
+	require.NoError(t, err)
+
+	v, err := store.GetFingerprintVersion()
+	require.NoError(t, err)
+	assert.Zero(t, v)
+
+	require.NoError(t, store.SetFingerprintVersion(types.FingerprintV2))
+	require.NoError(t, store.Close())
+
+	store, err = NewSQLite(path)
+	require.NoError(t, err)
+	defer store.Close()
+	v, err = store.GetFingerprintVersion()
+	require.NoError(t, err)
+	assert.Equal(t, types.FingerprintV2, v)
+}
+
+func TestSQLite_RemapFindings(t *testing.T) {
+	store, err := NewSQLite(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	for _, id := range []string{"a", "b", "c", "d"} {
+		require.NoError(t, store.AddFinding(&types.Finding{ID: id, RuleID: "np.test.1"}))
+	}
+	require.NoError(t, store.SetAnnotation("finding", "a", "false_positive", "test key"))
+	require.NoError(t, store.SetAnnotation("finding", "c", "accepted", ""))
+
+	// a and b swap, c merges into d
+	err = store.ExecBatch(func(tx Store) error {
+		return tx.RemapFindings(map[string]string{"a": "b", "b": "a", "c": "d"})
+	})
+	require.NoError(t, err)
+
+	findings, err := store.GetFindings()
+	require.NoError(t, err)
+	var ids []string
+	for _, f := range findings {
+		ids = append(ids, f.ID)
+	}
+	assert.ElementsMatch(t, []string{"a", "b", "d"}, ids)
+
+	status, comment, err := store.GetAnnotation("finding", "b")
+	require.NoError(t, err)
+	assert.Equal(t, "false_positive", status)
+	assert.Equal(t, "test key", comment)
+	status, _, err = store.GetAnnotation("finding", "a")
+	require.NoError(t, err)
+	assert.Empty(t, status)
+	status, _, err = store.GetAnnotation("finding", "d")
+	require.NoError(t, err)
+	assert.Equal(t, "accepted", status)
+}
+
+func TestSQLite_Annotations(t *testing.T) {
+	store, err := NewSQLite(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	require.NoError(t, store.SetAnnotation("finding", "a", "false_positive", "test key"))
+	at := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+	require.NoError(t, store.PutAnnotation(&types.Annotation{
+		TargetType: "match", TargetID: "m0", Status: "accepted", Author: "alice", UpdatedAt: at,
+	}))
+
+	annotations, err := store.GetAnnotations()
+	require.NoError(t, err)
+	require.Len(t, annotations, 2)
+	assert.Equal(t, "a", annotations[0].TargetID)
+	assert.Equal(t, "test key", annotations[0].Comment)
+	assert.WithinDuration(t, time.Now(), annotations[0].UpdatedAt, time.Minute)
+	assert.Equal(t, &types.Annotation{TargetType: "match", TargetID: "m0", Status: "accepted", Author: "alice", UpdatedAt: at}, annotations[1])
+
+	status, _, err := store.GetAnnotation("match", "m0")
+	require.NoError(t, err)
+	assert.Equal(t, "accepted", status)
+}
+
+func TestGetSince(t *testing.T) {
+	sqlite, err := NewSQLite(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer sqlite.Close()
+
+	for name, s := range map[string]Store{"sqlite": sqlite, "memory": NewMemory()} {
+		t.Run(name, func(t *testing.T) {
+			blobID := types.ComputeBlobID([]byte("content"))
+			require.NoError(t, s.AddBlob(blobID, 7))
+			require.NoError(t, s.AddRule(&types.Rule{ID: "np.test.1", Name: "Test"}))
+			add := func(id string) {
+				require.NoError(t, s.AddMatch(&types.Match{BlobID: blobID, RuleID: "np.test.1", StructuralID: id}))
+				require.NoError(t, s.AddFinding(&types.Finding{ID: id, RuleID: "np.test.1"}))
+			}
+			add("a")
+			add("b")
+
+			matches, lastMatch, err := s.GetMatchesSince(0)
+			require.NoError(t, err)
+			assert.Len(t, matches, 2)
+			findings, lastFinding, err := s.GetFindingsSince(0)
+			require.NoError(t, err)
+			assert.Len(t, findings, 2)
+
+			matches, next, err := s.GetMatchesSince(lastMatch)
+			require.NoError(t, err)
+			assert.Empty(t, matches)
+			assert.Equal(t, lastMatch, next, "with nothing new, the last row ID is kept")
+
+			add("c")
+			matches, _, err = s.GetMatchesSince(lastMatch)
+			require.NoError(t, err)
+			require.Len(t, matches, 1)
+			assert.Equal(t, "c", matches[0].StructuralID)
+			findings, _, err = s.GetFindingsSince(lastFinding)
+			require.NoError(t, err)
+			require.Len(t, findings, 1)
+			assert.Equal(t, "c", findings[0].ID)
+		})
+	}
+}
+
+func TestSQLite_SecretKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	store, err := NewSQLite(path)
+	require.NoError(t, err)
+	key, err := store.SecretKey()
+	require.NoError(t, err)
+	assert.Len(t, key, secretKeySize)
+	require.NoError(t, store.Close())
+
+	store, err = NewSQLite(path)
+	require.NoError(t, err)
+	defer store.Close()
+	again, err := store.SecretKey()
+	require.NoError(t, err)
+	assert.Equal(t, key, again, "the key is kept with the datastore")
+
+	other, err := NewMemory().SecretKey()
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+}
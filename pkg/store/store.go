@@ -1,9 +1,14 @@
 package store
 
 import (
+	"time"
+
 	"github.com/praetorian-inc/titus/pkg/types"
 )
 
+// secretKeySize is the size of the key returned by SecretKey.
+const secretKeySize = 32
+
 // Store provides persistence for scan results.
 // This interface abstracts the underlying storage implementation,
 // allowing for different backends (SQLite, PostgreSQL, etc.).
@@ -35,9 +40,30 @@ type Store interface {
 	// GetFindings retrieves all findings (for reporting).
 	GetFindings() ([]*types.Finding, error)
 
+	// GetMatchesSince retrieves the matches stored after the one with row
+	// ID after (0 = all) in the order stored, and the row ID of the last
+	// match, or after if there are none. It lets a reader following a scan
+	// load only the matches the scan has added since.
+	GetMatchesSince(after int64) ([]*types.Match, int64, error)
+
+	// GetFindingsSince retrieves the findings stored after the one with
+	// row ID after, as GetMatchesSince does matches.
+	GetFindingsSince(after int64) ([]*types.Finding, int64, error)
+
 	// FindingExists checks if a finding with this structural ID exists.
 	FindingExists(structuralID string) (bool, error)
 
+	// MarkFindingsSeen records that a scan at time at found the findings again,
+	// moving remediated findings to regressed.
+	MarkFindingsSeen(ids []string, at time.Time) error
+
+	// SetFindingState sets the lifecycle state of a finding.
+	SetFindingState(id string, state types.FindingState) error
+
+	// SetMatchValidation replaces the validation result of the match with
+	// this structural ID, for matches validated after they were stored.
+	SetMatchValidation(structuralID string, result *types.ValidationResult) error
+
 // BlobExists checks if a blob has already been scanned.
 	BlobExists(id types.BlobID) (bool, error)
 
@@ -54,6 +80,48 @@ type Store interface {
 	// SetAnnotation creates or updates an annotation.
 	SetAnnotation(targetType, targetID, status, comment string) error
 
+	// GetAnnotations retrieves all annotations.
+	GetAnnotations() ([]*types.Annotation, error)
+
+	// PutAnnotation creates or updates an annotation, keeping its author
+	// and timestamp.
+	PutAnnotation(a *types.Annotation) error
+
+	// GetRepoRefs retrieves the last-scanned commit for each ref of a repository.
+	// Returns an empty map if the repository has never been scanned.
+	GetRepoRefs(repo string) (map[string]string, error)
+
+	// SetRepoRef records the last-scanned commit (high-water mark) for a repository ref.
+	SetRepoRef(repo, ref, commit string) error
+
+	// AddScan records the start of a scan and sets scan.ID. Blobs and matches
+	// stored afterwards that weren't already stored are attributed to it.
+	AddScan(scan *types.Scan) error
+
+	// FinishScan records scan.FinishedAt.
+	FinishScan(scan *types.Scan) error
+
+	// GetScans retrieves all recorded scans, oldest first.
+	GetScans() ([]*types.Scan, error)
+
+	// GetFingerprintVersion returns the fingerprint version the store's
+	// finding IDs were computed with, or 0 if none was recorded.
+	GetFingerprintVersion() (types.FingerprintVersion, error)
+
+	// SetFingerprintVersion records the fingerprint version of the store's
+	// finding IDs.
+	SetFingerprintVersion(v types.FingerprintVersion) error
+
+	// SecretKey returns the store's random key for keyed hashes of secret
+	// values, creating it on first use. Such hashes tell secrets apart
+	// without letting anyone who hasn't the datastore check a guess.
+	SecretKey() ([]byte, error)
+
+	// RemapFindings changes the ID of each finding keyed in ids to its value,
+	// carrying its annotation along. A finding whose new ID is already taken
+	// is merged into the finding that has it.
+	RemapFindings(ids map[string]string) error
+
 	// Close closes the database connection.
 	Close() error
 }
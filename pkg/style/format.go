@@ -0,0 +1,46 @@
+package style
+
+import (
+	"fmt"
+	"time"
+)
+
+// Bytes formats n bytes with a binary unit, e.g. 1.5 MiB.
+func Bytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// Age describes how long ago a secret was introduced, in the largest whole
+// unit: "3 years", "5 months", "12 days" or "today". With short, it's
+// abbreviated for table columns: "3y", "5mo", "12d" or "<1d".
+func Age(introduced, now time.Time, short bool) string {
+	days := int(now.Sub(introduced).Hours() / 24)
+	n, unit, abbrev := days, "day", "d"
+	switch {
+	case days >= 365:
+		n, unit, abbrev = days/365, "year", "y"
+	case days >= 30:
+		n, unit, abbrev = days/30, "month", "mo"
+	case days < 1 && short:
+		return "<1d"
+	case days < 1:
+		return "today"
+	}
+	switch {
+	case short:
+		return fmt.Sprintf("%d%s", n, abbrev)
+	case n == 1:
+		return fmt.Sprintf("1 %s", unit)
+	default:
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+}
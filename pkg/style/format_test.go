@@ -0,0 +1,32 @@
+package style
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBytes(t *testing.T) {
+	assert.Equal(t, "1023 B", Bytes(1023))
+	assert.Equal(t, "1.5 KiB", Bytes(1536))
+	assert.Equal(t, "3.0 MiB", Bytes(3<<20))
+	assert.Equal(t, "2.0 GiB", Bytes(2<<30))
+}
+
+func TestAge(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		introduced  time.Time
+		long, short string
+	}{
+		{now.Add(-time.Hour), "today", "<1d"},
+		{now.AddDate(0, 0, -1), "1 day", "1d"},
+		{now.AddDate(0, 0, -12), "12 days", "12d"},
+		{now.AddDate(0, 0, -65), "2 months", "2mo"},
+		{now.AddDate(-3, 0, -1), "3 years", "3y"},
+	} {
+		assert.Equal(t, tt.long, Age(tt.introduced, now, false))
+		assert.Equal(t, tt.short, Age(tt.introduced, now, true))
+	}
+}
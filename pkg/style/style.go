@@ -0,0 +1,90 @@
+// Package style decides whether output is colored and holds the color scheme,
+// table layout and size and age formats shared by the scan, report and
+// compare commands. The same decision applies to the explore TUI, so --color
+// and NO_COLOR behave alike everywhere.
+package style
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+)
+
+// Mode is a --color setting.
+type Mode string
+
+const (
+	Auto   Mode = "auto"   // color terminals, unless NO_COLOR is set
+	Always Mode = "always" // color even when piped
+	Never  Mode = "never"  // never color
+)
+
+// ParseMode parses a --color value.
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case Auto, Always, Never:
+		return Mode(s), nil
+	}
+	return "", fmt.Errorf("invalid color mode %q (expected auto, always or never)", s)
+}
+
+// Enabled reports whether output written to f should be colored. In Auto
+// mode that is when f is a terminal and the NO_COLOR environment variable
+// is unset or empty (see https://no-color.org).
+func Enabled(mode Mode, f *os.File) bool {
+	switch mode {
+	case Always:
+		return true
+	case Never:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// Apply sets the process-wide color state used by the command output and
+// the explore TUI.
+func Apply(enabled bool) {
+	color.NoColor = !enabled
+	if !enabled {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
+
+// Styles holds color formatters matching the NoseyParker color scheme.
+type Styles struct {
+	FindingHeading *color.Color
+	ID             *color.Color
+	RuleName       *color.Color
+	Heading        *color.Color
+	Match          *color.Color
+	Metadata       *color.Color
+	Active         *color.Color // secrets validation confirmed live
+}
+
+// New creates the formatters, which color their output only if enabled.
+func New(enabled bool) *Styles {
+	s := &Styles{
+		FindingHeading: color.New(color.Bold, color.FgHiWhite),
+		ID:             color.New(color.FgHiGreen),
+		RuleName:       color.New(color.Bold, color.FgHiBlue),
+		Heading:        color.New(color.Bold),
+		Match:          color.New(color.FgYellow),
+		Metadata:       color.New(color.FgHiBlue),
+		Active:         color.New(color.Bold, color.FgHiRed),
+	}
+	for _, c := range []*color.Color{s.FindingHeading, s.ID, s.RuleName, s.Heading, s.Match, s.Metadata, s.Active} {
+		if enabled {
+			c.EnableColor()
+		} else {
+			c.DisableColor()
+		}
+	}
+	return s
+}
@@ -0,0 +1,42 @@
+package style
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMode(t *testing.T) {
+	for _, s := range []string{"auto", "always", "never"} {
+		mode, err := ParseMode(s)
+		require.NoError(t, err)
+		assert.Equal(t, Mode(s), mode)
+	}
+	_, err := ParseMode("sometimes")
+	assert.ErrorContains(t, err, "invalid color mode")
+}
+
+func TestEnabled(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	require.NoError(t, err)
+	defer f.Close()
+
+	assert.True(t, Enabled(Always, f))
+	assert.False(t, Enabled(Never, f))
+	assert.False(t, Enabled(Auto, f), "a file is not a terminal")
+
+	t.Setenv("NO_COLOR", "1")
+	assert.True(t, Enabled(Always, f), "--color=always overrides NO_COLOR")
+	assert.False(t, Enabled(Auto, f))
+}
+
+func TestNew(t *testing.T) {
+	s := New(false)
+	assert.Equal(t, "test", s.FindingHeading.Sprint("test"))
+	assert.Equal(t, "rule", s.RuleName.Sprintf("%s", "rule"))
+
+	s = New(true)
+	assert.Equal(t, "\x1b[1;94mrule\x1b[22;0m", s.RuleName.Sprint("rule"), "color even when output is not a terminal")
+}
@@ -0,0 +1,112 @@
+package style
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"golang.org/x/term"
+)
+
+// minShrinkWidth is the narrowest a column is truncated to when a table
+// doesn't fit the terminal.
+const minShrinkWidth = 10
+
+// Column describes a table column.
+type Column struct {
+	Header string // may be colored
+	Right  bool   // right-align, for counts
+	Shrink bool   // truncate to fit the table's Width
+}
+
+// Table lays out rows in aligned columns. Cells are measured by display
+// width rather than bytes, so names with non-ASCII or wide (CJK, emoji)
+// characters and cells colored with escape codes line up.
+type Table struct {
+	Columns []Column
+	Rows    [][]string
+	// Separator, if set, is repeated under the header to the table's width.
+	Separator string
+	// Width, if positive, is the widest a line may be. Columns marked
+	// Shrink are truncated with an ellipsis until lines fit.
+	Width int
+}
+
+// Render writes the table to w: one line per row, each cell padded to its
+// column's width, with a space at either end and three between columns.
+func (t *Table) Render(w io.Writer) error {
+	widths := make([]int, len(t.Columns))
+	for i, c := range t.Columns {
+		widths[i] = ansi.StringWidth(c.Header)
+	}
+	for _, row := range t.Rows {
+		for i, cell := range row {
+			if i < len(widths) {
+				widths[i] = max(widths[i], ansi.StringWidth(cell))
+			}
+		}
+	}
+	lineWidth := 2 + 3*(len(widths)-1)
+	for _, n := range widths {
+		lineWidth += n
+	}
+	if t.Width > 0 {
+		for i, c := range t.Columns {
+			if over := lineWidth - t.Width; over > 0 && c.Shrink && widths[i] > minShrinkWidth {
+				cut := min(over, widths[i]-minShrinkWidth)
+				widths[i] -= cut
+				lineWidth -= cut
+			}
+		}
+	}
+
+	var b strings.Builder
+	writeRow := func(cells []string) {
+		b.WriteString(" ")
+		for i, n := range widths {
+			if i > 0 {
+				b.WriteString("   ")
+			}
+			var cell string
+			if i < len(cells) {
+				cell = ansi.Truncate(cells[i], n, "…")
+			}
+			pad := strings.Repeat(" ", n-ansi.StringWidth(cell))
+			if t.Columns[i].Right {
+				b.WriteString(pad + cell)
+			} else {
+				b.WriteString(cell + pad)
+			}
+		}
+		b.WriteString(" \n")
+	}
+
+	headers := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		headers[i] = c.Header
+	}
+	writeRow(headers)
+	if t.Separator != "" {
+		b.WriteString(strings.Repeat(t.Separator, lineWidth/ansi.StringWidth(t.Separator)) + "\n")
+	}
+	for _, row := range t.Rows {
+		writeRow(row)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// TerminalWidth returns the width of the terminal w writes to, or 0 if w
+// isn't a terminal.
+func TerminalWidth(w io.Writer) int {
+	f, ok := w.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return 0
+	}
+	width, _, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
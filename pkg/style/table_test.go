@@ -0,0 +1,60 @@
+package style
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTable_Render(t *testing.T) {
+	table := Table{
+		Columns:   []Column{{Header: "Rule", Shrink: true}, {Header: "Findings", Right: true}},
+		Rows:      [][]string{{"AWS API Key", "12"}, {"GitLab 個人アクセストークン", "3"}, {"Clé d'API Stripe", "1"}},
+		Separator: "─",
+	}
+	var buf bytes.Buffer
+	require.NoError(t, table.Render(&buf))
+
+	assert.Equal(t, ""+
+		" Rule                          Findings \n"+
+		"────────────────────────────────────────\n"+
+		" AWS API Key                         12 \n"+
+		" GitLab 個人アクセストークン          3 \n"+
+		" Clé d'API Stripe                     1 \n", buf.String())
+}
+
+func TestTable_RenderColored(t *testing.T) {
+	bold := color.New(color.Bold)
+	bold.EnableColor()
+	table := Table{
+		Columns: []Column{{Header: bold.Sprint("Rule")}, {Header: bold.Sprint("Findings"), Right: true}},
+		Rows:    [][]string{{bold.Sprint("Slack Webhook"), "2"}},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, table.Render(&buf))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, ansi.StringWidth(lines[0]), ansi.StringWidth(lines[1]), "escape codes don't count toward width")
+	assert.Equal(t, " Slack Webhook          2 ", ansi.Strip(lines[1]))
+}
+
+func TestTable_RenderWidth(t *testing.T) {
+	table := Table{
+		Columns: []Column{{Header: "Rule", Shrink: true}, {Header: "Findings", Right: true}},
+		Rows:    [][]string{{"Generic Credential Assignment in Configuration", "7"}, {"秘密鍵を含む設定ファイルの認証情報", "2"}},
+		Width:   32,
+	}
+	var buf bytes.Buffer
+	require.NoError(t, table.Render(&buf))
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		assert.Equal(t, 32, ansi.StringWidth(line), "line %q", line)
+	}
+	assert.Contains(t, buf.String(), " Generic Credential…          7 \n")
+}
@@ -0,0 +1,13 @@
+package types
+
+import "time"
+
+// Annotation is an analyst's triage decision on a finding or a match.
+type Annotation struct {
+	TargetType string    `json:"target_type"` // "finding" or "match"
+	TargetID   string    `json:"target_id"`   // finding ID or match structural ID
+	Status     string    `json:"status,omitempty"`
+	Comment    string    `json:"comment,omitempty"`
+	Author     string    `json:"author,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,39 @@
+package types
+
+// BlobLocation is one place where the blob of a match was found, flattened
+// from its provenance for output.
+type BlobLocation struct {
+	Kind       string `json:"kind"` // provenance kind: file, git, archive or extended
+	Path       string `json:"path,omitempty"`
+	Repository string `json:"repository,omitempty"`
+	Commit     string `json:"commit,omitempty"`
+}
+
+// NewBlobLocation flattens a provenance record.
+func NewBlobLocation(prov Provenance) BlobLocation {
+	loc := BlobLocation{Kind: prov.Kind(), Path: prov.Path()}
+	if gp, ok := prov.(GitProvenance); ok {
+		loc.Repository = gp.RepoPath
+		if gp.Commit != nil {
+			loc.Commit = gp.Commit.CommitID
+		}
+	}
+	return loc
+}
+
+// String returns the path, followed by the repository and commit of blobs
+// found in git history.
+func (l BlobLocation) String() string {
+	s := l.Path
+	if l.Repository != "" {
+		s += " in " + l.Repository
+	}
+	if l.Commit != "" {
+		commit := l.Commit
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		s += " @ " + commit
+	}
+	return s
+}
@@ -4,14 +4,75 @@ import (
 	"crypto/sha1"
 	"encoding/hex"
 	"encoding/json"
+	"time"
 )
 
+// FindingState is where a finding is in its lifecycle across scans.
+type FindingState string
+
+const (
+	// FindingStateNew is a finding that has not been reviewed yet.
+	FindingStateNew FindingState = "new"
+	// FindingStateTriaged is a finding that has been accepted or rejected in review.
+	FindingStateTriaged FindingState = "triaged"
+	// FindingStateRemediated is a finding that a later scan of its location no longer found.
+	FindingStateRemediated FindingState = "remediated"
+	// FindingStateRegressed is a remediated finding that a scan found again.
+	FindingStateRegressed FindingState = "regressed"
+)
+
+// Seen returns the state after a scan finds the finding again.
+func (s FindingState) Seen() FindingState {
+	switch s {
+	case "":
+		return FindingStateNew
+	case FindingStateRemediated:
+		return FindingStateRegressed
+	}
+	return s
+}
+
+// Triaged returns the state after the finding is reviewed. Remediated
+// findings stay remediated.
+func (s FindingState) Triaged() FindingState {
+	switch s {
+	case "", FindingStateNew, FindingStateRegressed:
+		return FindingStateTriaged
+	}
+	return s
+}
+
 // Finding groups matches with same (rule, groups) for deduplication.
 type Finding struct {
 	ID      string   // SHA-1(rule_structural_id + '\0' + json(groups))
 	RuleID  string
 	Groups  [][]byte
 	Matches []*Match // matches belonging to this finding
+
+	State     FindingState
+	FirstSeen time.Time // first scan that found it
+	LastSeen  time.Time // most recent scan that found it
+
+	// Introduced is the earliest commit date of its matches in git
+	// history, an estimate of when the secret was leaked; zero if unknown.
+	// Reports set it; it isn't stored.
+	Introduced time.Time
+}
+
+// SecretValue returns the value taken to be the secret of a finding with
+// capture groups groups, for grouping the findings of different rules that
+// caught the same secret: its longest group, since rules that capture an
+// identifier alongside a secret (an AWS key ID and secret key, a username
+// and password) capture the secret as the longer value. It returns nil if
+// there are no groups.
+func SecretValue(groups [][]byte) []byte {
+	var secret []byte
+	for _, g := range groups {
+		if len(g) > len(secret) {
+			secret = g
+		}
+	}
+	return secret
 }
 
 // ComputeFindingID computes content-based finding ID.
@@ -0,0 +1,81 @@
+package types
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FingerprintVersion selects the algorithm that computes finding IDs. A
+// version's algorithm never changes once released, so IDs computed with it
+// are stable across titus releases; a datastore records the version its
+// findings use.
+type FingerprintVersion int
+
+const (
+	// FingerprintV1 is SHA-1(rule_structural_id + '\0' + json(groups)), as
+	// computed by ComputeFindingID and Nosey Parker. The structural ID is the
+	// SHA-1 of the rule's pattern, so editing a rule's pattern changes the IDs
+	// of its findings.
+	FingerprintV1 FingerprintVersion = 1
+
+	// FingerprintV2 is SHA-1("titus-fp-v2" + '\0' + rule_id + '\0' +
+	// json(groups)). It keys on the rule's ID instead of its pattern, so IDs
+	// survive pattern edits; they change only if the rule is renamed or its
+	// capture groups change.
+	FingerprintV2 FingerprintVersion = 2
+
+	// DefaultFingerprintVersion is used for datastores that haven't recorded
+	// a version: those created before versions existed, and new ones.
+	DefaultFingerprintVersion = FingerprintV1
+)
+
+// ParseFingerprintVersion parses "v1", "v2" (or "1", "2").
+func ParseFingerprintVersion(s string) (FingerprintVersion, error) {
+	switch strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), "v") {
+	case "1":
+		return FingerprintV1, nil
+	case "2":
+		return FingerprintV2, nil
+	}
+	return 0, fmt.Errorf("unknown fingerprint version %q (want v1 or v2)", s)
+}
+
+// String returns the version as "v1", "v2" and so on.
+func (v FingerprintVersion) String() string {
+	return fmt.Sprintf("v%d", int(v))
+}
+
+// FindingID computes the ID of the finding that rule r's match with groups
+// belongs to. Versions other than FingerprintV2 compute FingerprintV1 IDs.
+func (v FingerprintVersion) FindingID(r *Rule, groups [][]byte) string {
+	if v != FingerprintV2 {
+		return ComputeFindingID(r.StructuralID, groups)
+	}
+	h := sha1.New()
+	h.Write([]byte("titus-fp-v2"))
+	h.Write([]byte{0})
+	h.Write([]byte(r.ID))
+	h.Write([]byte{0})
+	groupsJSON, _ := json.Marshal(groups)
+	h.Write(groupsJSON)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// FindingContentKey identifies the finding of rule ruleID with groups
+// whatever the fingerprint version, for joining matches to stored findings
+// without recomputing their IDs.
+func FindingContentKey(ruleID string, groups [][]byte) string {
+	var b strings.Builder
+	b.WriteString(ruleID)
+	for _, g := range groups {
+		b.WriteByte(0)
+		b.WriteString(strconv.Itoa(len(g)))
+		b.WriteByte(':')
+		b.Write(g)
+	}
+	return b.String()
+}
@@ -1,7 +1,11 @@
 package types
 
+import (
+	"bytes"
+	"sort"
+)
+
 // ComputeLineColumn computes line and column numbers from a byte offset in content.
-// Lines and columns are 1-indexed (first line is 1, first column is 1).
 func ComputeLineColumn(content []byte, byteOffset int) (line, column int) {
 	line = 1
 	column = 1
@@ -15,3 +19,39 @@ func ComputeLineColumn(content []byte, byteOffset int) (line, column int) {
 	}
 	return line, column
 }
+
+// LineIndex maps byte offsets in a blob to line and column numbers. It
+// records the blob's newline offsets in one pass, so each lookup is a binary
+// search rather than a rescan of the content from the start.
+type LineIndex struct {
+	newlines []int // offsets of '\n' bytes, ascending
+	size     int
+}
+
+// NewLineIndex indexes the newlines in content.
+func NewLineIndex(content []byte) *LineIndex {
+	idx := &LineIndex{size: len(content)}
+	for pos := 0; ; {
+		i := bytes.IndexByte(content[pos:], '\n')
+		if i < 0 {
+			break
+		}
+		idx.newlines = append(idx.newlines, pos+i)
+		pos += i + 1
+	}
+	return idx
+}
+
+// LineColumn returns the 1-based line and column of a byte offset, with the
+// same results as ComputeLineColumn. Offsets past the end of the content are
+// clamped to it.
+func (idx *LineIndex) LineColumn(byteOffset int) (line, column int) {
+	byteOffset = max(0, min(byteOffset, idx.size))
+	// Newlines before the offset; a newline at the offset ends its line.
+	n := sort.SearchInts(idx.newlines, byteOffset)
+	lineStart := 0
+	if n > 0 {
+		lineStart = idx.newlines[n-1] + 1
+	}
+	return n + 1, byteOffset - lineStart + 1
+}
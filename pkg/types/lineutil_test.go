@@ -73,3 +73,17 @@ func TestComputeLineColumn(t *testing.T) {
 		})
 	}
 }
+
+func TestLineIndex_MatchesComputeLineColumn(t *testing.T) {
+	contents := []string{"", "hello", "hello\nworld", "\n\nx\n", "line1\r\nline2\nline3\n"}
+	for _, content := range contents {
+		idx := NewLineIndex([]byte(content))
+		for offset := -1; offset <= len(content)+2; offset++ {
+			wantLine, wantColumn := ComputeLineColumn([]byte(content), offset)
+			gotLine, gotColumn := idx.LineColumn(offset)
+			if gotLine != wantLine || gotColumn != wantColumn {
+				t.Errorf("LineColumn(%q, %d) = %d:%d, want %d:%d", content, offset, gotLine, gotColumn, wantLine, wantColumn)
+			}
+		}
+	}
+}
@@ -13,11 +13,24 @@ type Match struct {
 	FindingID        string // SHA-1(rule_structural_id + '\0' + json(groups)) — content-based dedup ID
 	RuleID           string // e.g., "np.aws.1"
 	RuleName         string // e.g., "AWS API Key"
+	Kind             string `json:"kind,omitempty"` // RuleKindFilename for matches of a file's path, RuleKindPII for sensitive data; empty for credentials in content
 	Location         Location
 	Groups           [][]byte          // regex capture groups (positional, deprecated - use NamedGroups)
 	NamedGroups      map[string][]byte // named capture groups from regex (?P<name>...)
 	Snippet          Snippet
 	ValidationResult *ValidationResult `json:"validation_result,omitempty"`
+	LikelyFP         bool              `json:"likely_fp,omitempty"`      // context or path suggests test/example data (not persisted)
+	FPReasons        []string          `json:"fp_reasons,omitempty"`     // why LikelyFP is set
+	Owner            *Owner            `json:"owner,omitempty"`          // who to route remediation to (not persisted)
+	ScanID           int64             `json:"scan_id,omitempty"`        // the scan that first stored the match (0 if unrecorded)
+	Locations        []BlobLocation    `json:"locations,omitempty"`      // where the blob was found, up to a limit (not persisted)
+	LocationCount    int               `json:"location_count,omitempty"` // how many places the blob was found in all (not persisted)
+}
+
+// Active reports whether validation confirmed that the match's secret is
+// live. Outputs list active matches first.
+func (m *Match) Active() bool {
+	return m.ValidationResult != nil && m.ValidationResult.Status == StatusValid
 }
 
 // ComputeStructuralID computes content-based unique ID.
@@ -180,4 +180,5 @@ func TestMatch_JSON_WithoutValidation(t *testing.T) {
 
 	// validation_result should not be in JSON (omitempty)
 	assert.NotContains(t, string(data), "validation_result")
+	assert.NotContains(t, string(data), "likely_fp")
 }
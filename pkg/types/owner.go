@@ -0,0 +1,15 @@
+package types
+
+// Owner identifies who is responsible for a match: the author of the
+// matched line according to git blame, and the CODEOWNERS owners of its path.
+type Owner struct {
+	AuthorName  string   `json:"author_name,omitempty"`
+	AuthorEmail string   `json:"author_email,omitempty"`
+	Commit      string   `json:"commit,omitempty"` // commit that last changed the line
+	Codeowners  []string `json:"codeowners,omitempty"`
+}
+
+// IsZero reports whether no owner information was found.
+func (o *Owner) IsZero() bool {
+	return o == nil || (o.AuthorName == "" && o.AuthorEmail == "" && len(o.Codeowners) == 0)
+}
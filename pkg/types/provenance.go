@@ -12,6 +12,7 @@ type Provenance interface {
 // FileProvenance for filesystem files.
 type FileProvenance struct {
 	FilePath string
+	FileType string // language or file type, e.g. "Go" or "Terraform" (see pkg/filetype)
 }
 
 // Kind returns "file".
@@ -29,6 +30,7 @@ type GitProvenance struct {
 	RepoPath string
 	Commit   *CommitMetadata // nil if not tracking commit info
 	BlobPath string          // path within repo at commit
+	FileType string          // language or file type, e.g. "Go" or "Terraform" (see pkg/filetype)
 }
 
 // Kind returns "git".
@@ -53,6 +55,32 @@ type CommitMetadata struct {
 	Message            string
 }
 
+// Time returns when the commit was authored, or committed if the author
+// date is unknown.
+func (c *CommitMetadata) Time() time.Time {
+	if c.AuthorTimestamp.IsZero() {
+		return c.CommitterTimestamp
+	}
+	return c.AuthorTimestamp
+}
+
+// EarliestCommit returns the earliest commit time of the git provenance in
+// provs, or the zero time if none has commit metadata. For the locations
+// of a secret, it estimates when the secret was introduced.
+func EarliestCommit(provs []Provenance) time.Time {
+	var earliest time.Time
+	for _, p := range provs {
+		gp, ok := p.(GitProvenance)
+		if !ok || gp.Commit == nil {
+			continue
+		}
+		if t := gp.Commit.Time(); !t.IsZero() && (earliest.IsZero() || t.Before(earliest)) {
+			earliest = t
+		}
+	}
+	return earliest
+}
+
 // ExtendedProvenance for custom sources (S3, HTTP, etc.).
 type ExtendedProvenance struct {
 	Payload map[string]interface{}
@@ -146,3 +146,16 @@ func TestCommitMetadata(t *testing.T) {
 	assert.Equal(t, committerTime, commit.CommitterTimestamp)
 	assert.Equal(t, "Fix bug", commit.Message)
 }
+
+func TestEarliestCommit(t *testing.T) {
+	older := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.True(t, EarliestCommit(nil).IsZero())
+	assert.True(t, EarliestCommit([]Provenance{FileProvenance{FilePath: "a"}, GitProvenance{BlobPath: "b"}}).IsZero())
+	assert.Equal(t, older, EarliestCommit([]Provenance{
+		GitProvenance{Commit: &CommitMetadata{AuthorTimestamp: newer}},
+		FileProvenance{FilePath: "a"},
+		GitProvenance{Commit: &CommitMetadata{CommitterTimestamp: older}}, // no author date
+	}))
+}
@@ -17,10 +17,21 @@ type PatternRequirements struct {
 	IgnoreIfContains []string `json:"ignore_if_contains,omitempty"`
 }
 
+// Rule kinds. Content rules, the default, match the content of blobs.
+// Filename rules match the paths of files, whatever their content. PII rules
+// match content too, but find personal or otherwise sensitive data rather
+// than credentials; their matches carry the kind so they can be kept apart.
+const (
+	RuleKindContent  = ""
+	RuleKindFilename = "filename"
+	RuleKindPII      = "pii"
+)
+
 // Rule is a detection rule with pattern and metadata.
 type Rule struct {
 	ID               string   // e.g., "np.aws.1"
 	Name             string   // human-readable name
+	Kind             string   // RuleKindContent, RuleKindFilename or RuleKindPII
 	Pattern          string   // regex pattern
 	StructuralID     string   // SHA-1 of pattern (computed)
 	Description      string   // optional
@@ -28,6 +39,7 @@ type Rule struct {
 	NegativeExamples []string // negative test cases
 	References       []string // documentation URLs
 	Categories       []string // classification tags
+	Tags             []string // free-form labels, e.g. "pii" or "validated-capable"
 	Keywords         []string // keywords for Aho-Corasick prefiltering
 
 	// MinEntropy is the minimum Shannon entropy (bits/char) the secret capture
@@ -38,6 +50,92 @@ type Rule struct {
 	// PatternRequirements specifies character-class and content constraints
 	// for the captured value. nil means no requirements.
 	PatternRequirements *PatternRequirements
+
+	// Verify names a post-match check, registered in package verifier, that
+	// the captured value must pass. Empty means no check.
+	Verify string
+
+	// MinMatches, if above 1, drops the rule's matches in a blob unless the
+	// blob has at least this many, for data that only matters in bulk.
+	MinMatches int
+
+	// Correlation, if non-nil, lets this rule also be produced by pairing
+	// matches of other rules found near each other in the same blob.
+	Correlation *Correlation
+
+	// CorrelationOnly marks a rule that was loaded only to supply components
+	// to a correlated rule. Its matches are dropped unless they are paired.
+	CorrelationOnly bool
+
+	// Context, if non-nil, overrides the matcher's context window for this
+	// rule's matches.
+	Context *ContextOverride
+
+	// Validation, if non-nil, is the HTTP request the rule's validation
+	// block makes to check whether a match's secret is live.
+	Validation *HTTPValidation
+}
+
+// ContextOverride sets how much surrounding content is kept with a rule's
+// matches. Nil fields keep the matcher's setting; 0 disables that limit.
+type ContextOverride struct {
+	Lines *int `json:"lines,omitempty"` // lines before/after the match
+	Bytes *int `json:"bytes,omitempty"` // bytes before/after the match
+}
+
+// HTTPValidation is the request of a rule's validation block. The URL,
+// headers and body are templates over the match's capture groups, such as
+// {{ TOKEN }} or {{ TOKEN | append: ':' | b64enc }}.
+type HTTPValidation struct {
+	Method          string            `json:"method"`
+	URL             string            `json:"url"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	Body            string            `json:"body,omitempty"`
+	ResponseMatcher []ResponseMatcher `json:"response_matcher,omitempty"`
+
+	// SecretGroup names the capture group {{ TOKEN }} stands for. Empty
+	// means the "token" group, or else the first one.
+	SecretGroup string `json:"secret_group,omitempty"`
+
+	// TimeoutSeconds, if above 0, bounds how long the validation may take.
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+}
+
+// ResponseMatcher checks the response to a validation request. The
+// credentials are valid when every matcher matches, a negative one matching
+// when its condition doesn't hold. It is shared by rule validation blocks
+// and validator definitions, so it is decoded from YAML too.
+type ResponseMatcher struct {
+	Type           string   `json:"type,omitempty" yaml:"type,omitempty"` // StatusMatch, WordMatch, HeaderMatch or JsonValid; empty with report_response
+	Status         []int    `json:"status,omitempty" yaml:"status,omitempty"`
+	Words          []string `json:"words,omitempty" yaml:"words,omitempty"`
+	MatchAllWords  bool     `json:"match_all_words,omitempty" yaml:"match_all_words,omitempty"`
+	Header         string   `json:"header,omitempty" yaml:"header,omitempty"`     // for HeaderMatch
+	Expected       []string `json:"expected,omitempty" yaml:"expected,omitempty"` // for HeaderMatch, values the header may contain
+	Negative       bool     `json:"negative,omitempty" yaml:"negative,omitempty"`
+	ReportResponse bool     `json:"report_response,omitempty" yaml:"report_response,omitempty"` // keep the response body of a valid result
+}
+
+// Response matcher types.
+const (
+	StatusMatch = "StatusMatch"
+	WordMatch   = "WordMatch"
+	HeaderMatch = "HeaderMatch"
+	JSONValid   = "JsonValid"
+)
+
+// Correlation describes how separate component matches (e.g. an AWS access key
+// ID and a secret access key a few lines apart) combine into one match of the
+// rule that declares it.
+type Correlation struct {
+	Components  []CorrelationComponent `json:"components"`
+	MaxDistance int                    `json:"max_distance,omitempty"` // max bytes between components (0 = matcher default)
+}
+
+// CorrelationComponent is one part of a correlated match.
+type CorrelationComponent struct {
+	Group   string   `json:"group"` // named group the component's value is recorded under
+	RuleIDs []string `json:"rules"` // rules whose matches supply the value
 }
 
 // namedGroupRe matches named capture groups like (?P<name>...) and replaces
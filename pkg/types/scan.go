@@ -0,0 +1,15 @@
+package types
+
+import "time"
+
+// Scan records one scan run, so blobs and matches can be attributed to the
+// run that first stored them.
+type Scan struct {
+	ID         int64     `json:"id"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"` // zero if the scan didn't finish
+	Args       []string  `json:"args"`        // command line, with secret flag values redacted
+	Version    string    `json:"version"`     // titus version
+	RulesHash  string    `json:"rules_hash"`  // identifies the rule set used
+	Target     string    `json:"target"`
+}
@@ -0,0 +1,212 @@
+// pkg/validator/audit.go
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptrace"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// AuditEntry records one outbound request made to validate a match. It
+// names the rule, the validator and the host contacted, never the secret.
+type AuditEntry struct {
+	Time      time.Time              `json:"time"`
+	RuleID    string                 `json:"rule_id"`
+	MatchID   string                 `json:"match_id,omitempty"` // structural ID of the match
+	Validator string                 `json:"validator"`
+	Host      string                 `json:"host"` // host:port; empty for plugins, whose requests aren't seen
+	Result    types.ValidationStatus `json:"result"`
+}
+
+// AuditLog writes an AuditEntry per outbound validation request as a JSON
+// line. It is safe for concurrent use.
+type AuditLog struct {
+	mu     sync.Mutex
+	enc    *json.Encoder
+	closer io.Closer
+	err    error // first write error
+}
+
+// NewAuditLog returns an audit log writing to w.
+func NewAuditLog(w io.Writer) *AuditLog {
+	l := &AuditLog{enc: json.NewEncoder(w)}
+	if c, ok := w.(io.Closer); ok {
+		l.closer = c
+	}
+	return l
+}
+
+// OpenAuditLog opens an audit log file, appending to it if it exists so one
+// file can cover several runs.
+func OpenAuditLog(path string) (*AuditLog, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening validation audit log: %w", err)
+	}
+	return NewAuditLog(f), nil
+}
+
+// Record writes an entry. Write errors are kept for Close to report, so
+// auditing never interrupts validation.
+func (l *AuditLog) Record(e AuditEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.enc.Encode(e); err != nil && l.err == nil {
+		l.err = err
+	}
+}
+
+// Close closes the underlying writer and returns the first error writing
+// to it.
+func (l *AuditLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closer != nil {
+		if err := l.closer.Close(); err != nil && l.err == nil {
+			l.err = err
+		}
+	}
+	if l.err != nil {
+		return fmt.Errorf("writing validation audit log: %w", l.err)
+	}
+	return nil
+}
+
+// outboundRequest is a request a validator made.
+type outboundRequest struct {
+	host string
+	at   time.Time
+}
+
+// requestRecorder collects the requests a validation makes, and whether
+// they reached their hosts. If gate is set, it is asked once per host
+// before the first request to it; if it returns an error, the validation
+// is cancelled and the error kept in blocked.
+type requestRecorder struct {
+	mu        sync.Mutex
+	requests  []outboundRequest
+	connected bool  // an HTTP connection was made
+	netErr    error // a DNS lookup or dial failed
+
+	gate    func(host string) error
+	cancel  context.CancelFunc
+	allowed map[string]bool
+	blocked error
+}
+
+func (r *requestRecorder) add(host string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.blocked != nil {
+		return
+	}
+	if r.gate != nil && !r.allowed[host] {
+		if err := r.gate(host); err != nil {
+			r.blocked = err
+			r.cancel()
+			return
+		}
+		if r.allowed == nil {
+			r.allowed = make(map[string]bool)
+		}
+		r.allowed[host] = true
+	}
+	r.requests = append(r.requests, outboundRequest{host: host, at: time.Now().UTC()})
+}
+
+// hosts returns the hosts requests were made to, each once.
+func (r *requestRecorder) hosts() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var hosts []string
+	seen := make(map[string]bool)
+	for _, req := range r.requests {
+		if !seen[req.host] {
+			seen[req.host] = true
+			hosts = append(hosts, req.host)
+		}
+	}
+	return hosts
+}
+
+// blockedErr returns the error gate stopped the validation with, if any.
+func (r *requestRecorder) blockedErr() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.blocked
+}
+
+type recorderKey struct{}
+
+// withRequestRecorder returns a context whose HTTP requests, and the
+// connections validators announce with noteRequest, are recorded in r.
+func withRequestRecorder(ctx context.Context, r *requestRecorder) context.Context {
+	ctx = context.WithValue(ctx, recorderKey{}, r)
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GetConn: r.add,
+		GotConn: func(httptrace.GotConnInfo) {
+			r.mu.Lock()
+			r.connected = true
+			r.mu.Unlock()
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			r.failed(info.Err)
+		},
+		ConnectDone: func(_, _ string, err error) {
+			r.failed(err)
+		},
+	})
+}
+
+func (r *requestRecorder) failed(err error) {
+	if err == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.netErr == nil {
+		r.netErr = err
+	}
+}
+
+// unreachable reports whether the HTTP requests of a validation failed
+// without reaching any host.
+func (r *requestRecorder) unreachable() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.netErr != nil && !r.connected
+}
+
+// noteRequest records a connection to addr (host:port) that doesn't go
+// through net/http, such as a database login, for the audit log.
+func noteRequest(ctx context.Context, addr string) {
+	if r, ok := ctx.Value(recorderKey{}).(*requestRecorder); ok {
+		r.add(addr)
+	}
+}
+
+// audit writes the requests v made to validate match to the audit log.
+// Plugins run in their own process, so a plugin validation is logged once
+// without a host.
+func (l *AuditLog) audit(v Validator, match *types.Match, requests []outboundRequest, result types.ValidationStatus) {
+	if _, ok := v.(*PluginValidator); ok && len(requests) == 0 {
+		requests = []outboundRequest{{at: time.Now().UTC()}}
+	}
+	for _, req := range requests {
+		l.Record(AuditEntry{
+			Time:      req.at,
+			RuleID:    match.RuleID,
+			MatchID:   match.StructuralID,
+			Validator: v.Name(),
+			Host:      req.host,
+			Result:    result,
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// pkg/validator/audit_test.go
+package validator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fetchValidator validates by requesting url with the match's context.
+type fetchValidator struct {
+	url string
+}
+
+func (v *fetchValidator) Name() string                   { return "fetch" }
+func (v *fetchValidator) CanValidate(ruleID string) bool { return ruleID == "np.test.1" }
+
+func (v *fetchValidator) Validate(ctx context.Context, match *types.Match) (*types.ValidationResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+string(match.NamedGroups["secret"]))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return types.NewValidationResult(types.StatusInvalid, 1.0, "rejected"), nil
+}
+
+func TestEngine_AuditLog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	engine := NewEngine(2, &fetchValidator{url: server.URL})
+	engine.SetAuditLog(NewAuditLog(&buf))
+
+	match := &types.Match{
+		RuleID:       "np.test.1",
+		StructuralID: "abc123",
+		NamedGroups:  map[string][]byte{"secret": []byte("sk_live_supersecret")},
+	}
+	_, err := engine.ValidateMatch(context.Background(), match)
+	require.NoError(t, err)
+	// Cached results make no request, so aren't logged again
+	_, err = engine.ValidateMatch(context.Background(), match)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 1)
+	assert.NotContains(t, lines[0], "supersecret")
+
+	var entry AuditEntry
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "np.test.1", entry.RuleID)
+	assert.Equal(t, "abc123", entry.MatchID)
+	assert.Equal(t, "fetch", entry.Validator)
+	assert.Equal(t, strings.TrimPrefix(server.URL, "http://"), entry.Host)
+	assert.Equal(t, types.StatusInvalid, entry.Result)
+	assert.False(t, entry.Time.IsZero())
+}
+
+func TestEngine_AuditLog_NoRequests(t *testing.T) {
+	var buf bytes.Buffer
+	mock := &mockValidator{
+		name:    "test",
+		ruleIDs: []string{"np.test.1"},
+		result:  types.NewValidationResult(types.StatusUndetermined, 0, "skipping localhost address"),
+	}
+	engine := NewEngine(2, mock)
+	engine.SetAuditLog(NewAuditLog(&buf))
+
+	match := &types.Match{RuleID: "np.test.1", NamedGroups: map[string][]byte{"secret": []byte("x")}}
+	result := <-engine.ValidateAsync(context.Background(), match)
+	require.NotNil(t, result)
+	assert.Empty(t, buf.String(), "validations without outbound requests aren't logged")
+}
+
+func TestNoteRequest(t *testing.T) {
+	rec := &requestRecorder{}
+	ctx := withRequestRecorder(context.Background(), rec)
+	noteRequest(ctx, "db.example.com:5432")
+	noteRequest(context.Background(), "ignored:22")
+
+	require.Len(t, rec.requests, 1)
+	assert.Equal(t, "db.example.com:5432", rec.requests[0].host)
+}
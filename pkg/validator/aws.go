@@ -4,7 +4,9 @@ package validator
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"regexp"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -35,6 +37,11 @@ type STSClient interface {
 // AWSValidator validates AWS credentials using STS GetCallerIdentity.
 type AWSValidator struct {
 	stsClient STSClient // nil means create client per-validation with provided credentials
+
+	// Permission probing, for live keys of a validator from NewAWSProbingValidator
+	probe      bool
+	httpClient *http.Client
+	endpoints  map[string]string // by signing service name
 }
 
 // NewAWSValidator creates a new AWS credential validator.
@@ -47,6 +54,15 @@ func NewAWSValidatorWithClient(client STSClient) *AWSValidator {
 	return &AWSValidator{stsClient: client}
 }
 
+// NewAWSProbingValidator creates an AWS validator that also probes what a
+// live key may do, with read-only calls such as iam:ListAccountAliases and
+// sts:GetSessionToken, and summarizes which were allowed in the result. The
+// calls show up in the account's CloudTrail. Register it with
+// Engine.Prepend to use it in place of the default AWS validator.
+func NewAWSProbingValidator() *AWSValidator {
+	return &AWSValidator{probe: true, httpClient: http.DefaultClient, endpoints: defaultAWSEndpoints}
+}
+
 // Name returns the validator name.
 func (v *AWSValidator) Name() string {
 	return "aws"
@@ -117,6 +133,16 @@ func (v *AWSValidator) Validate(ctx context.Context, match *types.Match) (*types
 	result.Details["account"] = aws.ToString(identity.Account)
 	result.Details["arn"] = aws.ToString(identity.Arn)
 	result.Details["user_id"] = aws.ToString(identity.UserId)
+
+	if v.probe {
+		probes := v.probePermissions(ctx, aws.Credentials{AccessKeyID: keyID, SecretAccessKey: secret, SessionToken: sessionToken})
+		summary := make([]string, len(probes))
+		for i, p := range probes {
+			summary[i] = p.String()
+			result.Details[p.action] = p.outcome
+		}
+		result.Message += "; permissions: " + strings.Join(summary, ", ")
+	}
 	return result, nil
 }
 
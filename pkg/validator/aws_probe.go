@@ -0,0 +1,171 @@
+// pkg/validator/aws_probe.go
+package validator
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// defaultAWSEndpoints are the global endpoints permission probes call, by
+// signing service name.
+var defaultAWSEndpoints = map[string]string{
+	"iam": "https://iam.amazonaws.com/",
+	"s3":  "https://s3.amazonaws.com/",
+	"sts": "https://sts.amazonaws.com/",
+}
+
+// awsProbe is a read-only API call made with a live key to learn what it
+// can do.
+type awsProbe struct {
+	action  string // IAM action name, e.g. "iam:ListAccountAliases"
+	service string // signing service name, keying the endpoint
+	params  string // Query API form body; empty for a GET
+	// describe summarizes a successful response, e.g. "user deploy"
+	describe func(body []byte) string
+}
+
+// awsPermissionProbes are the calls made by NewAWSProbingValidator, in the
+// order they're reported. None of them change the account:
+// GetSessionToken only issues short-lived credentials, which are discarded.
+var awsPermissionProbes = []awsProbe{
+	{action: "iam:GetUser", service: "iam", params: "Action=GetUser&Version=2010-05-08", describe: describeIAMUser},
+	{action: "iam:ListAccountAliases", service: "iam", params: "Action=ListAccountAliases&Version=2010-05-08", describe: describeAccountAliases},
+	{action: "s3:ListBuckets", service: "s3", describe: describeBuckets},
+	{action: "sts:GetSessionToken", service: "sts", params: "Action=GetSessionToken&Version=2011-06-15&DurationSeconds=900"},
+}
+
+// awsProbeResult is the outcome of one probe.
+type awsProbeResult struct {
+	action  string
+	outcome string // "allowed", "denied" or "failed"
+	detail  string // what an allowed call returned, or why one failed
+}
+
+func (r awsProbeResult) String() string {
+	if r.detail == "" {
+		return r.action + " " + r.outcome
+	}
+	return fmt.Sprintf("%s %s (%s)", r.action, r.outcome, r.detail)
+}
+
+// probePermissions makes each permission probe with creds.
+func (v *AWSValidator) probePermissions(ctx context.Context, creds aws.Credentials) []awsProbeResult {
+	results := make([]awsProbeResult, 0, len(awsPermissionProbes))
+	for _, p := range awsPermissionProbes {
+		results = append(results, v.probeOne(ctx, creds, p))
+	}
+	return results
+}
+
+// probeOne signs and sends one probe's request.
+func (v *AWSValidator) probeOne(ctx context.Context, creds aws.Credentials, p awsProbe) awsProbeResult {
+	result := awsProbeResult{action: p.action, outcome: "failed"}
+
+	method := http.MethodGet
+	if p.params != "" {
+		method = http.MethodPost
+	}
+	payload := []byte(p.params)
+	req, err := http.NewRequestWithContext(ctx, method, v.endpoints[p.service], bytes.NewReader(payload))
+	if err != nil {
+		result.detail = err.Error()
+		return result
+	}
+	if p.params != "" {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	}
+	sum := sha256.Sum256(payload)
+	payloadHash := hex.EncodeToString(sum[:])
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash) // required by S3
+	if err := v4.NewSigner().SignHTTP(ctx, creds, req, payloadHash, p.service, "us-east-1", time.Now()); err != nil {
+		result.detail = err.Error()
+		return result
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		result.detail = "request failed"
+		return result
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		result.outcome = "allowed"
+		if p.describe != nil {
+			result.detail = p.describe(body)
+		}
+	case resp.StatusCode == http.StatusForbidden && strings.HasPrefix(awsErrorCode(body), "AccessDenied"):
+		result.outcome = "denied"
+	default:
+		result.detail = awsErrorCode(body)
+		if result.detail == "" {
+			result.detail = fmt.Sprintf("HTTP %d", resp.StatusCode)
+		}
+	}
+	return result
+}
+
+// awsErrorCode returns the code of an AWS XML error response, from either
+// the Query API's <ErrorResponse><Error> or S3's bare <Error>.
+func awsErrorCode(body []byte) string {
+	var e struct {
+		QueryCode string `xml:"Error>Code"`
+		S3Code    string `xml:"Code"`
+	}
+	if xml.Unmarshal(body, &e) != nil {
+		return ""
+	}
+	if e.QueryCode != "" {
+		return e.QueryCode
+	}
+	return e.S3Code
+}
+
+func describeIAMUser(body []byte) string {
+	var resp struct {
+		UserName string `xml:"GetUserResult>User>UserName"`
+	}
+	if xml.Unmarshal(body, &resp) != nil || resp.UserName == "" {
+		return ""
+	}
+	return "user " + resp.UserName
+}
+
+func describeAccountAliases(body []byte) string {
+	var resp struct {
+		Aliases []string `xml:"ListAccountAliasesResult>AccountAliases>member"`
+	}
+	if xml.Unmarshal(body, &resp) != nil {
+		return ""
+	}
+	if len(resp.Aliases) == 0 {
+		return "no aliases"
+	}
+	return "alias " + strings.Join(resp.Aliases, ", ")
+}
+
+func describeBuckets(body []byte) string {
+	var resp struct {
+		Buckets []string `xml:"Buckets>Bucket>Name"`
+	}
+	if xml.Unmarshal(body, &resp) != nil {
+		return ""
+	}
+	if len(resp.Buckets) == 1 {
+		return "1 bucket"
+	}
+	return fmt.Sprintf("%d buckets", len(resp.Buckets))
+}
@@ -1,3 +1,5 @@
+//go:build !wasm
+
 // pkg/validator/azure.go
 package validator
 
@@ -1,3 +1,5 @@
+//go:build !wasm
+
 // pkg/validator/azure_test.go
 package validator
 
@@ -0,0 +1,115 @@
+// pkg/validator/breaker.go
+package validator
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Circuit breaker defaults for NewEngine.
+const (
+	DefaultBreakerFailures = 5
+	DefaultBreakerCooldown = 5 * time.Minute
+)
+
+// circuitOpenError is returned for validations skipped because the
+// validator's endpoint kept failing.
+type circuitOpenError struct {
+	validator string
+	host      string // host:port; empty if the validator's hosts aren't seen
+	failures  int
+}
+
+func (e *circuitOpenError) Error() string {
+	endpoint := e.validator + " endpoint"
+	if e.host != "" {
+		endpoint += " " + e.host
+	}
+	return fmt.Sprintf("skipped: %s unreachable (%d consecutive network failures)", endpoint, e.failures)
+}
+
+// breaker stops calling a validator's endpoint after consecutive network
+// failures, so an unreachable provider fails fast instead of stalling every
+// match. Circuits are kept per validator and host, so that one unreachable
+// self-hosted instance doesn't stop validation against the others. After a
+// cooldown, one validation is let through to probe the endpoint: success
+// closes the circuit, failure keeps it open for another cooldown.
+type breaker struct {
+	mu       sync.Mutex
+	failures int           // consecutive failures that open the circuit; 0 = never
+	cooldown time.Duration // time before probing an open circuit
+	state    map[breakerKey]*breakerState
+	now      func() time.Time
+}
+
+// breakerKey names a circuit: a validator and the host:port it contacts,
+// or no host for validators whose requests aren't seen, such as plugins.
+type breakerKey struct {
+	validator string
+	host      string
+}
+
+// breakerState is the circuit of one endpoint.
+type breakerState struct {
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newBreaker(failures int, cooldown time.Duration) *breaker {
+	return &breaker{
+		failures: failures,
+		cooldown: cooldown,
+		state:    make(map[breakerKey]*breakerState),
+		now:      time.Now,
+	}
+}
+
+// allow returns a *circuitOpenError if the validator named name shouldn't
+// contact host.
+func (b *breaker) allow(name, host string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := b.state[breakerKey{name, host}]
+	if b.failures <= 0 || s == nil || s.failures < b.failures {
+		return nil
+	}
+	if !s.probing && b.now().Sub(s.openedAt) >= b.cooldown {
+		s.probing = true
+		return nil
+	}
+	return &circuitOpenError{validator: name, host: host, failures: s.failures}
+}
+
+// record counts the outcome of a validation by the validator named name
+// against host.
+func (b *breaker) record(name, host string, networkFailure bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	key := breakerKey{name, host}
+	s := b.state[key]
+	if s == nil {
+		s = &breakerState{}
+		b.state[key] = s
+	}
+	s.probing = false
+	if !networkFailure {
+		s.failures = 0
+		return
+	}
+	s.failures++
+	if b.failures > 0 && s.failures >= b.failures {
+		s.openedAt = b.now()
+	}
+}
+
+// release ends a probe of host whose outcome is unknown, leaving the
+// circuit as it was.
+func (b *breaker) release(name, host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if s := b.state[breakerKey{name, host}]; s != nil {
+		s.probing = false
+	}
+}
@@ -0,0 +1,144 @@
+// pkg/validator/breaker_test.go
+package validator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := newBreaker(3, time.Minute)
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		b.record("aws", "", true)
+	}
+	require.NoError(t, b.allow("aws", ""))
+
+	// A success resets the count
+	b.record("aws", "", false)
+	b.record("aws", "", true)
+	b.record("aws", "", true)
+	require.NoError(t, b.allow("aws", ""))
+
+	b.record("aws", "", true)
+	var open *circuitOpenError
+	require.ErrorAs(t, b.allow("aws", ""), &open)
+	assert.Contains(t, open.Error(), "aws endpoint unreachable (3 consecutive network failures)")
+	assert.NoError(t, b.allow("github", ""), "other validators aren't affected")
+
+	// After the cooldown one probe goes through
+	now = now.Add(time.Minute)
+	require.NoError(t, b.allow("aws", ""))
+	assert.Error(t, b.allow("aws", ""), "only one probe at a time")
+
+	// A failed probe keeps the circuit open for another cooldown
+	b.record("aws", "", true)
+	assert.Error(t, b.allow("aws", ""))
+	now = now.Add(time.Minute)
+	require.NoError(t, b.allow("aws", ""))
+	b.record("aws", "", false)
+	assert.NoError(t, b.allow("aws", ""))
+	assert.NoError(t, b.allow("aws", ""))
+}
+
+func TestBreaker_Disabled(t *testing.T) {
+	b := newBreaker(0, time.Minute)
+	for i := 0; i < 10; i++ {
+		b.record("aws", "", true)
+	}
+	assert.NoError(t, b.allow("aws", ""))
+}
+
+// slowValidator blocks until its context is done.
+type slowValidator struct {
+	calls int
+}
+
+func (v *slowValidator) Name() string                   { return "slow" }
+func (v *slowValidator) CanValidate(ruleID string) bool { return ruleID == "np.test.1" }
+
+func (v *slowValidator) Validate(ctx context.Context, match *types.Match) (*types.ValidationResult, error) {
+	v.calls++
+	<-ctx.Done()
+	return types.NewValidationResult(types.StatusUndetermined, 0, ctx.Err().Error()), nil
+}
+
+func TestEngine_Timeout(t *testing.T) {
+	slow := &slowValidator{}
+	engine := NewEngine(1, slow)
+	engine.SetTimeout(time.Hour)
+	engine.SetValidatorTimeout("slow", 20*time.Millisecond)
+	engine.SetCircuitBreaker(2, time.Hour)
+
+	for i, secret := range []string{"a", "b", "c", "d"} {
+		match := &types.Match{RuleID: "np.test.1", NamedGroups: map[string][]byte{"secret": []byte(secret)}}
+		result, err := engine.ValidateMatch(context.Background(), match)
+		require.NoError(t, err)
+		assert.Equal(t, types.StatusUndetermined, result.Status)
+		if i < 2 {
+			assert.Equal(t, "timed out after 20ms", result.Message)
+		} else {
+			assert.Contains(t, result.Message, "skipped: slow endpoint unreachable (2 consecutive")
+		}
+	}
+	assert.Equal(t, 2, slow.calls, "validations after the circuit opens are skipped")
+}
+
+func TestEngine_CircuitBreaker_Unreachable(t *testing.T) {
+	// A closed server's address refuses connections
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	fetch := &fetchValidator{url: url}
+	engine := NewEngine(1, fetch)
+	engine.SetCircuitBreaker(1, time.Hour)
+
+	match := &types.Match{RuleID: "np.test.1", NamedGroups: map[string][]byte{"secret": []byte("x")}}
+	result, err := engine.ValidateMatch(context.Background(), match)
+	require.NoError(t, err)
+	assert.Contains(t, result.Message, "validation error")
+
+	result, err = engine.ValidateMatch(context.Background(), match)
+	require.NoError(t, err)
+	assert.Contains(t, result.Message, "skipped: fetch endpoint "+strings.TrimPrefix(url, "http://")+" unreachable")
+	assert.Nil(t, engine.cache.Get(cacheKey(match, []byte("x"))), "skipped validations aren't cached")
+
+	// Other hosts of the same validator are still validated
+	live := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer live.Close()
+	fetch.url = live.URL
+	match = &types.Match{RuleID: "np.test.1", NamedGroups: map[string][]byte{"secret": []byte("y")}}
+	result, err = engine.ValidateMatch(context.Background(), match)
+	require.NoError(t, err)
+	assert.Equal(t, types.StatusInvalid, result.Status)
+}
+
+func TestEngine_CircuitBreaker_RejectionsDontCount(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	engine := NewEngine(1, &fetchValidator{url: server.URL})
+	engine.SetCircuitBreaker(1, time.Hour)
+
+	for _, secret := range []string{"a", "b", "c"} {
+		match := &types.Match{RuleID: "np.test.1", NamedGroups: map[string][]byte{"secret": []byte(secret)}}
+		result, err := engine.ValidateMatch(context.Background(), match)
+		require.NoError(t, err)
+		assert.Equal(t, types.StatusInvalid, result.Status)
+	}
+}
@@ -0,0 +1,21 @@
+// pkg/validator/browser.go
+package validator
+
+// NewBrowserEngine creates a validation engine for the WASM build, where
+// requests go through the browser's fetch API. Only embedded HTTP validators
+// marked `browser: true` are included: their endpoints permit cross-origin
+// requests and need nothing beyond a single HTTP call.
+func NewBrowserEngine(workers int) *Engine {
+	embedded, err := LoadEmbeddedValidators()
+	if err != nil {
+		return NewEngine(workers)
+	}
+
+	var validators []Validator
+	for _, v := range embedded {
+		if hv, ok := v.(*HTTPValidator); ok && hv.BrowserSafe() {
+			validators = append(validators, hv)
+		}
+	}
+	return NewEngine(workers, validators...)
+}
@@ -2,22 +2,45 @@
 package validator
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
 
 	"github.com/praetorian-inc/titus/pkg/types"
 )
 
+// DefaultValidationTimeout is how long NewEngine lets a validation take.
+const DefaultValidationTimeout = 30 * time.Second
+
 // NewDefaultEngine creates a validation engine pre-loaded with all built-in validators.
-// This is the single source of truth for validator registration.
 func NewDefaultEngine(workers int) *Engine {
+	return NewEngine(workers, defaultValidators()...)
+}
+
+// NewAggressiveEngine creates a validation engine with the built-in validators
+// plus the aggressive ones, which authenticate to hosts named in a match's
+// context rather than to the credential issuer's API (e.g. SSH logins with
+// discovered private keys). Only use it where such access attempts are authorized.
+func NewAggressiveEngine(workers int) *Engine {
+	validators := defaultValidators()
+	validators = append(validators, NewSSHKeyValidator())
+	return NewEngine(workers, validators...)
+}
+
+// defaultValidators returns the built-in validators.
+// This is the single source of truth for validator registration.
+func defaultValidators() []Validator {
 	var validators []Validator
 
 	// Go validators (complex multi-credential validation)
 	validators = append(validators, NewAWSValidator())
 	validators = append(validators, NewSauceLabsValidator())
 	validators = append(validators, NewTwilioValidator())
-	validators = append(validators, NewAzureStorageValidator())
+	validators = append(validators, nativeValidators()...)
 	validators = append(validators, NewPostgresValidator())
 	validators = append(validators, NewBrowserStackValidator())
 	validators = append(validators, NewAmplitudeValidator())
@@ -38,7 +61,11 @@ func NewDefaultEngine(workers int) *Engine {
 		validators = append(validators, embedded...)
 	}
 
-	return NewEngine(workers, validators...)
+	// Validation blocks of the built-in rules, for the rules none of the
+	// validators above handles
+	validators = append(validators, builtinRuleValidators()...)
+
+	return validators
 }
 
 // Engine coordinates validation across multiple validators with caching.
@@ -47,6 +74,11 @@ type Engine struct {
 	cache      *ValidationCache
 	workers    int
 	sem        chan struct{} // semaphore for bounded concurrency
+	pending    atomic.Int64  // async validations submitted and not finished
+	audit      *AuditLog     // nil = outbound requests aren't logged
+	timeout    time.Duration // per validation; 0 = none
+	timeouts   map[string]time.Duration
+	breaker    *breaker
 }
 
 // NewEngine creates a validation engine with registered validators.
@@ -59,9 +91,58 @@ func NewEngine(workers int, validators ...Validator) *Engine {
 		cache:      NewValidationCache(),
 		workers:    workers,
 		sem:        make(chan struct{}, workers),
+		timeout:    DefaultValidationTimeout,
+		timeouts:   make(map[string]time.Duration),
+		breaker:    newBreaker(DefaultBreakerFailures, DefaultBreakerCooldown),
 	}
 }
 
+// SetTimeout sets how long a validation may take, unless its validator has
+// a timeout of its own; 0 means no limit. It must be called before the
+// engine is used.
+func (e *Engine) SetTimeout(d time.Duration) {
+	e.timeout = d
+}
+
+// SetValidatorTimeout sets how long validations by the named validator may
+// take, overriding SetTimeout and the validator's own timeout. It must be
+// called before the engine is used.
+func (e *Engine) SetValidatorTimeout(name string, d time.Duration) {
+	e.timeouts[name] = d
+}
+
+// SetCircuitBreaker makes the engine skip a validator after failures
+// consecutive network failures or timeouts, marking the matches it would
+// have validated undetermined, until cooldown has passed. failures <= 0
+// turns the breaker off. It must be called before the engine is used.
+func (e *Engine) SetCircuitBreaker(failures int, cooldown time.Duration) {
+	e.breaker = newBreaker(failures, cooldown)
+}
+
+// timeoutFor returns the timeout of validations by v.
+func (e *Engine) timeoutFor(v Validator) time.Duration {
+	if d, ok := e.timeouts[v.Name()]; ok {
+		return d
+	}
+	if t, ok := v.(interface{ Timeout() time.Duration }); ok && t.Timeout() > 0 {
+		return t.Timeout()
+	}
+	return e.timeout
+}
+
+// Prepend registers validators ahead of the existing ones, so they take
+// precedence for any rule IDs both handle. It must be called before the
+// engine is used.
+func (e *Engine) Prepend(validators ...Validator) {
+	e.validators = append(append([]Validator(nil), validators...), e.validators...)
+}
+
+// SetAuditLog logs every outbound request validators make to l. It must be
+// called before the engine is used.
+func (e *Engine) SetAuditLog(l *AuditLog) {
+	e.audit = l
+}
+
 // ValidateMatch validates a match using the appropriate validator.
 // Checks cache first, then finds and invokes matching validator.
 func (e *Engine) ValidateMatch(ctx context.Context, match *types.Match) (*types.ValidationResult, error) {
@@ -72,19 +153,15 @@ func (e *Engine) ValidateMatch(ctx context.Context, match *types.Match) (*types.
 	}
 
 	// Check cache first
-	if cached := e.cache.Get(secret); cached != nil {
+	key := cacheKey(match, secret)
+	if cached := e.cache.Get(key); cached != nil {
 		return cached, nil
 	}
 
 	// Find appropriate validator
 	for _, v := range e.validators {
 		if v.CanValidate(match.RuleID) {
-			result, err := v.Validate(ctx, match)
-			if err != nil {
-				return types.NewValidationResult(types.StatusUndetermined, 0, fmt.Sprintf("validation error: %v", err)), nil
-			}
-			e.cache.Set(secret, result)
-			return result, nil
+			return e.validateWith(ctx, v, match, key), nil
 		}
 	}
 
@@ -121,6 +198,29 @@ func extractSecret(match *types.Match) []byte {
 	return match.Snippet.Matching
 }
 
+// cacheKey returns the bytes a match's validation result is cached under. A
+// match with several named groups is keyed on all of them, since the same
+// secret can validate differently when paired with another identifier (an
+// AWS secret key with a different key ID, for example).
+func cacheKey(match *types.Match, secret []byte) []byte {
+	if len(match.NamedGroups) < 2 {
+		return secret
+	}
+	names := make([]string, 0, len(match.NamedGroups))
+	for name := range match.NamedGroups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b bytes.Buffer
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte(0)
+		b.Write(match.NamedGroups[name])
+		b.WriteByte(0)
+	}
+	return b.Bytes()
+}
+
 // ValidateAsync submits a match for async validation.
 // Returns immediately with a channel that will receive the result.
 func (e *Engine) ValidateAsync(ctx context.Context, match *types.Match) <-chan *types.ValidationResult {
@@ -135,15 +235,18 @@ func (e *Engine) ValidateAsync(ctx context.Context, match *types.Match) <-chan *
 	}
 
 	// Check cache first (fast path - no goroutine needed)
-	if cached := e.cache.Get(secret); cached != nil {
+	key := cacheKey(match, secret)
+	if cached := e.cache.Get(key); cached != nil {
 		result <- cached
 		close(result)
 		return result
 	}
 
 	// Submit for async validation
+	e.pending.Add(1)
 	go func() {
 		defer close(result)
+		defer e.pending.Add(-1)
 
 		// Acquire semaphore (bounded concurrency)
 		select {
@@ -155,30 +258,102 @@ func (e *Engine) ValidateAsync(ctx context.Context, match *types.Match) <-chan *
 		}
 
 		// Re-check cache (another goroutine may have validated)
-		if cached := e.cache.Get(secret); cached != nil {
+		if cached := e.cache.Get(key); cached != nil {
 			result <- cached
 			return
 		}
 
 		// Perform validation
-		r, _ := e.validateSync(ctx, match, secret)
+		r, _ := e.validateSync(ctx, match, key)
 		result <- r
 	}()
 
 	return result
 }
 
+// Pending returns the number of matches submitted with ValidateAsync whose
+// validation hasn't finished, including those waiting for a worker.
+func (e *Engine) Pending() int {
+	return int(e.pending.Load())
+}
+
 // validateSync performs the actual validation.
-func (e *Engine) validateSync(ctx context.Context, match *types.Match, secret []byte) (*types.ValidationResult, error) {
+func (e *Engine) validateSync(ctx context.Context, match *types.Match, key []byte) (*types.ValidationResult, error) {
 	for _, v := range e.validators {
 		if v.CanValidate(match.RuleID) {
-			result, err := v.Validate(ctx, match)
-			if err != nil {
-				return types.NewValidationResult(types.StatusUndetermined, 0, fmt.Sprintf("validation error: %v", err)), nil
-			}
-			e.cache.Set(secret, result)
-			return result, nil
+			return e.validateWith(ctx, v, match, key), nil
 		}
 	}
 	return types.NewValidationResult(types.StatusUndetermined, 0, "no validator available"), nil
 }
+
+// validateWith validates match with v, caching the result under key.
+// Errors, and validations skipped by the circuit breaker, aren't cached.
+func (e *Engine) validateWith(ctx context.Context, v Validator, match *types.Match, key []byte) *types.ValidationResult {
+	result, err := e.run(ctx, v, match)
+	var open *circuitOpenError
+	if errors.As(err, &open) {
+		return types.NewValidationResult(types.StatusUndetermined, 0, open.Error())
+	}
+	if err != nil {
+		return types.NewValidationResult(types.StatusUndetermined, 0, fmt.Sprintf("validation error: %v", err))
+	}
+	e.cache.Set(key, result)
+	return result
+}
+
+// run validates match with v within its timeout, unless the circuit
+// breaker has v, or a host it contacts, skipped. The requests it makes are
+// recorded in the audit log if one is set, and network failures and
+// timeouts counted by the breaker against the hosts contacted.
+func (e *Engine) run(ctx context.Context, v Validator, match *types.Match) (*types.ValidationResult, error) {
+	if err := e.breaker.allow(v.Name(), ""); err != nil {
+		return nil, err
+	}
+
+	vctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	timeout := e.timeoutFor(v)
+	if timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		vctx, cancelTimeout = context.WithTimeout(vctx, timeout)
+		defer cancelTimeout()
+	}
+	rec := &requestRecorder{
+		gate:   func(host string) error { return e.breaker.allow(v.Name(), host) },
+		cancel: cancel,
+	}
+	result, err := v.Validate(withRequestRecorder(vctx, rec), match)
+
+	hosts := rec.hosts()
+	blocked := rec.blockedErr()
+	timedOut := vctx.Err() == context.DeadlineExceeded && ctx.Err() == nil
+	if timedOut && err == nil && result != nil && result.Status == types.StatusUndetermined {
+		result.Message = fmt.Sprintf("timed out after %s", timeout)
+	}
+	failed := timedOut || rec.unreachable()
+	if len(hosts) == 0 {
+		hosts = []string{""}
+	} else {
+		e.breaker.release(v.Name(), "")
+	}
+	for _, host := range hosts {
+		if blocked != nil {
+			e.breaker.release(v.Name(), host)
+		} else {
+			e.breaker.record(v.Name(), host, failed)
+		}
+	}
+
+	if e.audit != nil {
+		status := types.StatusUndetermined
+		if err == nil && result != nil && blocked == nil {
+			status = result.Status
+		}
+		e.audit.audit(v, match, rec.requests, status)
+	}
+	if blocked != nil {
+		return nil, blocked
+	}
+	return result, err
+}
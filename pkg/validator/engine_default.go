@@ -0,0 +1,10 @@
+//go:build !wasm
+
+// pkg/validator/engine_default.go
+package validator
+
+// nativeValidators returns the built-in validators that depend on SDKs or
+// network access unavailable in the WASM build.
+func nativeValidators() []Validator {
+	return []Validator{NewAzureStorageValidator()}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/praetorian-inc/titus/pkg/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestEngine_New(t *testing.T) {
@@ -139,3 +140,49 @@ func TestEngine_ValidationError(t *testing.T) {
 	assert.Equal(t, types.StatusUndetermined, result.Status)
 	assert.Contains(t, result.Message, "network timeout")
 }
+
+func TestEngine_CacheKeyIncludesAllNamedGroups(t *testing.T) {
+	mock := &mockValidator{
+		name:    "test",
+		ruleIDs: []string{"np.test.1"},
+		result:  types.NewValidationResult(types.StatusInvalid, 1.0, "rejected"),
+	}
+	engine := NewEngine(4, mock)
+
+	first := &types.Match{
+		RuleID:      "np.test.1",
+		NamedGroups: map[string][]byte{"key_id": []byte("id-1"), "secret_key": []byte("shared-secret")},
+	}
+	result, err := engine.ValidateMatch(context.Background(), first)
+	assert.NoError(t, err)
+	assert.Equal(t, types.StatusInvalid, result.Status)
+
+	// Same secret with a different key ID must not reuse the cached result
+	mock.result = types.NewValidationResult(types.StatusValid, 1.0, "accepted")
+	second := &types.Match{
+		RuleID:      "np.test.1",
+		NamedGroups: map[string][]byte{"key_id": []byte("id-2"), "secret_key": []byte("shared-secret")},
+	}
+	result, err = engine.ValidateMatch(context.Background(), second)
+	assert.NoError(t, err)
+	assert.Equal(t, types.StatusValid, result.Status)
+}
+
+func TestNewAggressiveEngine_AddsSSHValidator(t *testing.T) {
+	assert.False(t, NewDefaultEngine(1).CanValidate("np.pem.1"))
+	assert.True(t, NewAggressiveEngine(1).CanValidate("np.pem.1"))
+}
+
+func TestNewBrowserEngine_OnlyBrowserSafeValidators(t *testing.T) {
+	engine := NewBrowserEngine(1)
+	assert.True(t, engine.CanValidate("np.github.1"))
+	assert.True(t, engine.CanValidate("np.openai.1"))
+	assert.False(t, engine.CanValidate("np.aws.2"), "Go validators are not browser-safe")
+	assert.False(t, engine.CanValidate("np.slack.3"), "unmarked YAML validators are excluded")
+
+	for _, v := range engine.validators {
+		hv, ok := v.(*HTTPValidator)
+		require.True(t, ok)
+		assert.True(t, hv.BrowserSafe(), hv.Name())
+	}
+}
@@ -0,0 +1,10 @@
+//go:build wasm
+
+// pkg/validator/engine_wasm.go
+package validator
+
+// nativeValidators returns no validators in the WASM build; the Azure SDK
+// does not compile for js/wasm.
+func nativeValidators() []Validator {
+	return nil
+}
@@ -2,13 +2,18 @@
 package validator
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/praetorian-inc/titus/pkg/types"
 )
@@ -35,6 +40,18 @@ func (v *HTTPValidator) Name() string {
 	return v.def.Name
 }
 
+// BrowserSafe reports whether the validator's endpoint permits cross-origin
+// requests, so it can run in a browser through fetch.
+func (v *HTTPValidator) BrowserSafe() bool {
+	return v.def.Browser
+}
+
+// Timeout returns how long a validation may take, or 0 for the engine's
+// default.
+func (v *HTTPValidator) Timeout() time.Duration {
+	return v.def.Timeout
+}
+
 // CanValidate returns true if this validator handles the given rule ID.
 func (v *HTTPValidator) CanValidate(ruleID string) bool {
 	for _, rid := range v.def.RuleIDs {
@@ -45,7 +62,16 @@ func (v *HTTPValidator) CanValidate(ruleID string) bool {
 	return false
 }
 
-// Validate performs HTTP validation against the configured endpoint.
+// maxResponseBody caps how much of a response is read for matchers and
+// extraction.
+const maxResponseBody = 1 << 20
+
+// maxReportedResponse caps how much of a response body report_response
+// keeps in a result's details.
+const maxReportedResponse = 1024
+
+// Validate performs HTTP validation against the configured endpoint, after
+// making the requests of any steps.
 func (v *HTTPValidator) Validate(ctx context.Context, match *types.Match) (*types.ValidationResult, error) {
 	// Extract secret from match
 	secret, err := v.extractSecret(match)
@@ -53,27 +79,75 @@ func (v *HTTPValidator) Validate(ctx context.Context, match *types.Match) (*type
 		return types.NewValidationResult(types.StatusUndetermined, 0, err.Error()), nil
 	}
 
+	// Template variables: the named capture groups, also in upper case as
+	// rule validation blocks write them, the secret as TOKEN, plus the
+	// fields steps extract
+	vars := make(map[string][]byte, 2*len(match.NamedGroups)+1)
+	for name, value := range match.NamedGroups {
+		vars[strings.ToUpper(name)] = value
+	}
+	maps.Copy(vars, match.NamedGroups)
+	if _, ok := vars["TOKEN"]; !ok {
+		vars["TOKEN"] = []byte(secret)
+	}
+
+	for i := range v.def.HTTP.Steps {
+		step := &v.def.HTTP.Steps[i]
+		stepSecret := secret
+		if step.Auth.SecretGroup != "" {
+			if stepSecret, err = secretFromGroup(match, step.Auth.SecretGroup); err != nil {
+				return types.NewValidationResult(types.StatusUndetermined, 0, fmt.Sprintf("step %d: %v", i+1, err)), nil
+			}
+		}
+		result, body := v.send(ctx, step, stepSecret, vars)
+		if result.Status != types.StatusValid {
+			result.Message = fmt.Sprintf("step %d: %s", i+1, result.Message)
+			return result, nil
+		}
+		fields, err := extractFields(step.Extract, body)
+		if err != nil {
+			return types.NewValidationResult(types.StatusUndetermined, 0, fmt.Sprintf("step %d: %v", i+1, err)), nil
+		}
+		for _, f := range fields {
+			vars[f.name] = []byte(f.value)
+		}
+	}
+
+	result, body := v.send(ctx, &v.def.HTTP, secret, vars)
+	if result.Status == types.StatusValid && len(v.def.HTTP.Extract) > 0 {
+		// Fields missing from the response are left out of the message
+		fields, _ := extractFields(v.def.HTTP.Extract, body)
+		describeFields(result, fields)
+	}
+	return result, nil
+}
+
+// send makes the request h defines and evaluates the response, returning
+// the result and the response body.
+func (v *HTTPValidator) send(ctx context.Context, h *HTTPDef, secret string, vars map[string][]byte) (*types.ValidationResult, []byte) {
 	// Substitute URL templates with named capture group values
-	url := substituteTemplateVars(v.def.HTTP.URL, match.NamedGroups)
+	url := substituteTemplateVars(h.URL, vars)
 
 	// Build request with optional body (also substitute template vars)
 	var body io.Reader
-	if v.def.HTTP.Body != "" {
-		body = strings.NewReader(substituteTemplateVars(v.def.HTTP.Body, match.NamedGroups))
+	if h.Body != "" {
+		body = strings.NewReader(substituteTemplateVars(h.Body, vars))
 	}
-	req, err := http.NewRequestWithContext(ctx, v.def.HTTP.Method, url, body)
+	req, err := http.NewRequestWithContext(ctx, h.Method, url, body)
 	if err != nil {
 		return types.NewValidationResult(types.StatusUndetermined, 0, fmt.Sprintf("failed to create request: %v", err)), nil
 	}
 
-	// Apply auth
-	if err := v.applyAuth(req, secret); err != nil {
+	// Apply auth (a static username may name a capture group, e.g. a client ID)
+	auth := h.Auth
+	auth.Username = substituteTemplateVars(auth.Username, vars)
+	if err := applyAuth(req, &auth, secret); err != nil {
 		return types.NewValidationResult(types.StatusUndetermined, 0, err.Error()), nil
 	}
 
 	// Apply custom headers (with template substitution)
-	for _, h := range v.def.HTTP.Headers {
-		req.Header.Set(h.Name, substituteTemplateVars(h.Value, match.NamedGroups))
+	for _, hdr := range h.Headers {
+		req.Header.Set(hdr.Name, substituteTemplateVars(hdr.Value, vars))
 	}
 
 	// Execute request
@@ -83,19 +157,15 @@ func (v *HTTPValidator) Validate(ctx context.Context, match *types.Match) (*type
 	}
 	defer resp.Body.Close()
 
-	// Read response body if needed for body-based validation
-	var respBody []byte
-	if v.def.HTTP.SuccessBodyContains != "" || v.def.HTTP.FailureBodyContains != "" {
-		respBody, err = io.ReadAll(resp.Body)
-		if err != nil {
-			return types.NewValidationResult(types.StatusUndetermined, 0, fmt.Sprintf("failed to read response body: %v", err)), nil
-		}
-	} else {
-		io.Copy(io.Discard, resp.Body)
+	// Read response body for body-based validation and extraction
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if err != nil {
+		return types.NewValidationResult(types.StatusUndetermined, 0, fmt.Sprintf("failed to read response body: %v", err)), nil
 	}
+	io.Copy(io.Discard, resp.Body)
 
-	// Check response code and body
-	return v.evaluateResponse(resp.StatusCode, respBody), nil
+	// Check response code, headers and body
+	return v.evaluateResponse(h, resp, respBody), respBody
 }
 
 func (v *HTTPValidator) extractSecret(match *types.Match) (string, error) {
@@ -103,7 +173,12 @@ func (v *HTTPValidator) extractSecret(match *types.Match) (string, error) {
 	if groupName == "" {
 		return "", fmt.Errorf("secret_group not specified in validator config")
 	}
+	return secretFromGroup(match, groupName)
+}
 
+// secretFromGroup returns the value of a named capture group, or of a
+// positional one for a numeric name.
+func secretFromGroup(match *types.Match, groupName string) (string, error) {
 	// Try named capture groups first
 	if match.NamedGroups != nil {
 		if value, ok := match.NamedGroups[groupName]; ok {
@@ -129,8 +204,8 @@ func (v *HTTPValidator) extractSecret(match *types.Match) (string, error) {
 	return "", fmt.Errorf("named group %q not found (available: %v)", groupName, available)
 }
 
-func (v *HTTPValidator) applyAuth(req *http.Request, secret string) error {
-	switch v.def.HTTP.Auth.Type {
+func applyAuth(req *http.Request, auth *AuthDef, secret string) error {
+	switch auth.Type {
 	case "none", "":
 		// No authentication - URL itself may contain the secret (e.g., Slack webhooks)
 		return nil
@@ -139,7 +214,7 @@ func (v *HTTPValidator) applyAuth(req *http.Request, secret string) error {
 		req.Header.Set("Authorization", "Bearer "+secret)
 
 	case "basic":
-		username := v.def.HTTP.Auth.Username
+		username := auth.Username
 		if username == "" {
 			username = secret // Secret is the username if not specified
 		}
@@ -147,14 +222,14 @@ func (v *HTTPValidator) applyAuth(req *http.Request, secret string) error {
 		req.Header.Set("Authorization", "Basic "+auth)
 
 	case "header":
-		headerName := v.def.HTTP.Auth.HeaderName
+		headerName := auth.HeaderName
 		if headerName == "" {
 			return fmt.Errorf("header auth requires header_name")
 		}
 		req.Header.Set(headerName, secret)
 
 	case "query":
-		paramName := v.def.HTTP.Auth.QueryParam
+		paramName := auth.QueryParam
 		if paramName == "" {
 			return fmt.Errorf("query auth requires query_param")
 		}
@@ -165,43 +240,53 @@ func (v *HTTPValidator) applyAuth(req *http.Request, secret string) error {
 	case "api_key":
 		// Sets "Authorization: key=SECRET" or custom prefix
 		// Used by Firebase FCM, Google APIs, etc.
-		prefix := v.def.HTTP.Auth.KeyPrefix
+		prefix := auth.KeyPrefix
 		if prefix == "" {
 			prefix = "key="
 		}
 		req.Header.Set("Authorization", prefix+secret)
 
 	default:
-		return fmt.Errorf("unsupported auth type: %s", v.def.HTTP.Auth.Type)
+		return fmt.Errorf("unsupported auth type: %s", auth.Type)
 	}
 	return nil
 }
 
-func (v *HTTPValidator) evaluateResponse(statusCode int, body []byte) *types.ValidationResult {
+func (v *HTTPValidator) evaluateResponse(h *HTTPDef, resp *http.Response, body []byte) *types.ValidationResult {
+	statusCode := resp.StatusCode
 	bodyStr := string(body)
 
 	// Check failure body first (takes precedence over success codes)
-	if v.def.HTTP.FailureBodyContains != "" && strings.Contains(bodyStr, v.def.HTTP.FailureBodyContains) {
+	if (h.FailureBodyContains != "" && strings.Contains(bodyStr, h.FailureBodyContains)) || negativeMatch(h.ResponseMatcher, resp, body) {
 		return types.NewValidationResult(types.StatusInvalid, 1.0, fmt.Sprintf("HTTP %d - response body indicates invalid credentials", statusCode))
 	}
 
+	// Without success codes, as in rule validation blocks, the response
+	// matchers alone decide
+	if len(h.SuccessCodes) == 0 && len(h.ResponseMatcher) > 0 {
+		if positiveMatch(h.ResponseMatcher, resp, body) {
+			return validResult(h, statusCode, body)
+		}
+		if statusCode == http.StatusTooManyRequests || statusCode >= 500 {
+			return types.NewValidationResult(types.StatusUndetermined, 0.5, fmt.Sprintf("HTTP %d - unexpected status code", statusCode))
+		}
+		return types.NewValidationResult(types.StatusInvalid, 1.0, fmt.Sprintf("HTTP %d - credentials rejected", statusCode))
+	}
+
 	// Check success codes
-	for _, code := range v.def.HTTP.SuccessCodes {
+	for _, code := range h.SuccessCodes {
 		if statusCode == code {
-			// If success_body_contains is specified, also check the body
-			if v.def.HTTP.SuccessBodyContains != "" {
-				if strings.Contains(bodyStr, v.def.HTTP.SuccessBodyContains) {
-					return types.NewValidationResult(types.StatusValid, 1.0, fmt.Sprintf("HTTP %d - credentials accepted", statusCode))
-				}
+			// If success_body_contains or response matchers are specified, also check the response
+			if (h.SuccessBodyContains != "" && !strings.Contains(bodyStr, h.SuccessBodyContains)) || !positiveMatch(h.ResponseMatcher, resp, body) {
 				// Status code matched but body didn't - treat as invalid
 				return types.NewValidationResult(types.StatusInvalid, 1.0, fmt.Sprintf("HTTP %d - response body indicates invalid credentials", statusCode))
 			}
-			return types.NewValidationResult(types.StatusValid, 1.0, fmt.Sprintf("HTTP %d - credentials accepted", statusCode))
+			return validResult(h, statusCode, body)
 		}
 	}
 
 	// Check failure codes
-	for _, code := range v.def.HTTP.FailureCodes {
+	for _, code := range h.FailureCodes {
 		if statusCode == code {
 			return types.NewValidationResult(types.StatusInvalid, 1.0, fmt.Sprintf("HTTP %d - credentials rejected", statusCode))
 		}
@@ -211,15 +296,189 @@ func (v *HTTPValidator) evaluateResponse(statusCode int, body []byte) *types.Val
 	return types.NewValidationResult(types.StatusUndetermined, 0.5, fmt.Sprintf("HTTP %d - unexpected status code", statusCode))
 }
 
-// substituteTemplateVars replaces template variables in s using named capture groups.
-// Handles all common template syntaxes: {{name}}, {{ name }}, {{.name}}, {{ .name }}
-func substituteTemplateVars(s string, groups map[string][]byte) string {
-	for name, value := range groups {
-		val := string(value)
-		s = strings.ReplaceAll(s, "{{"+name+"}}", val)
-		s = strings.ReplaceAll(s, "{{ "+name+" }}", val)
-		s = strings.ReplaceAll(s, "{{."+name+"}}", val)
-		s = strings.ReplaceAll(s, "{{ ."+name+" }}", val)
+// validResult returns the result of a request whose response shows the
+// credentials are valid, with the start of the body as the "response"
+// detail if a matcher has report_response.
+func validResult(h *HTTPDef, statusCode int, body []byte) *types.ValidationResult {
+	result := types.NewValidationResult(types.StatusValid, 1.0, fmt.Sprintf("HTTP %d - credentials accepted", statusCode))
+	for _, m := range h.ResponseMatcher {
+		if m.ReportResponse && len(body) > 0 {
+			result.Details["response"] = string(body[:min(len(body), maxReportedResponse)])
+			break
+		}
+	}
+	return result
+}
+
+// negativeMatch reports whether any negative matcher matches the response.
+func negativeMatch(matchers []types.ResponseMatcher, resp *http.Response, body []byte) bool {
+	for i := range matchers {
+		if matchers[i].Negative && responseMatches(&matchers[i], resp, body) {
+			return true
+		}
+	}
+	return false
+}
+
+// positiveMatch reports whether every positive matcher matches the response.
+func positiveMatch(matchers []types.ResponseMatcher, resp *http.Response, body []byte) bool {
+	for i := range matchers {
+		if !matchers[i].Negative && !responseMatches(&matchers[i], resp, body) {
+			return false
+		}
+	}
+	return true
+}
+
+// responseMatches reports whether the response has one of m's status codes,
+// any of its words (all of them with match_all_words), a header containing
+// one of its expected values, or a JSON body. Matchers with only
+// report_response match anything.
+func responseMatches(m *types.ResponseMatcher, resp *http.Response, body []byte) bool {
+	switch m.Type {
+	case types.StatusMatch:
+		return slices.Contains(m.Status, resp.StatusCode)
+	case types.WordMatch:
+		found := 0
+		for _, w := range m.Words {
+			if bytes.Contains(body, []byte(w)) {
+				found++
+			}
+		}
+		if m.MatchAllWords {
+			return len(m.Words) > 0 && found == len(m.Words)
+		}
+		return found > 0
+	case types.HeaderMatch:
+		value := strings.ToLower(resp.Header.Get(m.Header))
+		for _, e := range m.Expected {
+			if strings.Contains(value, strings.ToLower(e)) {
+				return true
+			}
+		}
+		return false
+	case types.JSONValid:
+		return json.Valid(body)
+	}
+	return true
+}
+
+// extractedField is a response field pulled out by an ExtractDef.
+type extractedField struct {
+	name  string
+	value string
+}
+
+// extractFields pulls the fields defs name out of a JSON body. It returns
+// the fields found, and an error naming the first one that wasn't.
+func extractFields(defs []ExtractDef, body []byte) ([]extractedField, error) {
+	if len(defs) == 0 {
+		return nil, nil
+	}
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("response is not JSON: %w", err)
+	}
+	var fields []extractedField
+	var missing error
+	for _, d := range defs {
+		path, err := parseJSONPath(d.JSON)
+		if err != nil {
+			return fields, err
+		}
+		value, ok := lookupJSON(doc, path)
+		if !ok {
+			if missing == nil {
+				missing = fmt.Errorf("response has no %s", d.JSON)
+			}
+			continue
+		}
+		fields = append(fields, extractedField{d.Name, value})
+	}
+	return fields, missing
+}
+
+// describeFields adds extracted fields to a result's message and details.
+func describeFields(result *types.ValidationResult, fields []extractedField) {
+	if len(fields) == 0 {
+		return
+	}
+	if result.Details == nil {
+		result.Details = make(map[string]string, len(fields))
+	}
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		result.Details[f.name] = f.value
+		parts[i] = f.name + "=" + f.value
+	}
+	result.Message += " (" + strings.Join(parts, ", ") + ")"
+}
+
+// jsonPathStep is an object key or array index of a JSON path.
+type jsonPathStep struct {
+	key   string
+	index int // used when key is empty
+}
+
+// parseJSONPath parses a dotted path with array indexes, such as
+// data.accounts[0].name or [0].id.
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	var steps []jsonPathStep
+	for _, part := range strings.Split(path, ".") {
+		key, rest, hasIndex := strings.Cut(part, "[")
+		if key == "" && !hasIndex {
+			return nil, fmt.Errorf("invalid json path %q", path)
+		}
+		if key != "" {
+			steps = append(steps, jsonPathStep{key: key})
+		}
+		for hasIndex {
+			idx, after, ok := strings.Cut(rest, "]")
+			n, err := strconv.Atoi(idx)
+			if !ok || err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid json path %q", path)
+			}
+			steps = append(steps, jsonPathStep{index: n})
+			if after == "" {
+				break
+			}
+			if rest, hasIndex = strings.CutPrefix(after, "["); !hasIndex {
+				return nil, fmt.Errorf("invalid json path %q", path)
+			}
+		}
+	}
+	return steps, nil
+}
+
+// lookupJSON returns the value at path in a decoded JSON document, as text.
+func lookupJSON(doc any, path []jsonPathStep) (string, bool) {
+	for _, step := range path {
+		switch node := doc.(type) {
+		case map[string]any:
+			if step.key == "" {
+				return "", false
+			}
+			doc = node[step.key]
+		case []any:
+			if step.key != "" || step.index >= len(node) {
+				return "", false
+			}
+			doc = node[step.index]
+		default:
+			return "", false
+		}
+	}
+	switch value := doc.(type) {
+	case nil:
+		return "", false
+	case string:
+		return value, true
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(value), true
+	default:
+		out, err := json.Marshal(value)
+		return string(out), err == nil
 	}
-	return s
 }
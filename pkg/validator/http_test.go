@@ -648,3 +648,144 @@ func TestHTTPValidator_Validate_FailureBodyContains(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, types.StatusInvalid, result.Status)
 }
+
+func TestHTTPValidator_Validate_ResponseMatcher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		if r.Header.Get("Authorization") == "Bearer good-token" {
+			w.Write([]byte(`{"ok":true,"scopes":["read","write"]}`))
+			return
+		}
+		w.Write([]byte(`{"ok":false,"error":"Invalid API key provided"}`))
+	}))
+	defer server.Close()
+
+	def := ValidatorDef{
+		Name:    "api-token",
+		RuleIDs: []string{"np.test.1"},
+		HTTP: HTTPDef{
+			Method:       "GET",
+			URL:          server.URL,
+			Auth:         AuthDef{Type: "bearer", SecretGroup: "token"},
+			SuccessCodes: []int{200},
+			ResponseMatcher: []types.ResponseMatcher{
+				{Type: types.WordMatch, Words: []string{`"ok":true`, `"scopes"`}, MatchAllWords: true},
+				{Type: types.WordMatch, Words: []string{"Invalid API key"}, Negative: true},
+				{Type: types.HeaderMatch, Header: "Content-Type", Expected: []string{"application/json"}},
+			},
+		},
+	}
+	v := NewHTTPValidator(def, nil)
+
+	result, err := v.Validate(context.Background(), &types.Match{NamedGroups: map[string][]byte{"token": []byte("good-token")}})
+	assert.NoError(t, err)
+	assert.Equal(t, types.StatusValid, result.Status)
+
+	result, err = v.Validate(context.Background(), &types.Match{NamedGroups: map[string][]byte{"token": []byte("bad-token")}})
+	assert.NoError(t, err)
+	assert.Equal(t, types.StatusInvalid, result.Status)
+	assert.Contains(t, result.Message, "response body indicates invalid credentials")
+}
+
+func TestHTTPValidator_Validate_Extract(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"user":{"email":"alice@example.com"},"accounts":[{"id":42}]}`))
+	}))
+	defer server.Close()
+
+	def := ValidatorDef{
+		Name:    "api-token",
+		RuleIDs: []string{"np.test.1"},
+		HTTP: HTTPDef{
+			Method:       "GET",
+			URL:          server.URL,
+			Auth:         AuthDef{Type: "bearer", SecretGroup: "token"},
+			SuccessCodes: []int{200},
+			Extract: []ExtractDef{
+				{Name: "email", JSON: "user.email"},
+				{Name: "account", JSON: "accounts[0].id"},
+				{Name: "plan", JSON: "billing.plan"},
+			},
+		},
+	}
+	v := NewHTTPValidator(def, nil)
+
+	result, err := v.Validate(context.Background(), &types.Match{NamedGroups: map[string][]byte{"token": []byte("tok")}})
+	assert.NoError(t, err)
+	assert.Equal(t, types.StatusValid, result.Status)
+	assert.Equal(t, "HTTP 200 - credentials accepted (email=alice@example.com, account=42)", result.Message)
+	assert.Equal(t, map[string]string{"email": "alice@example.com", "account": "42"}, result.Details)
+}
+
+func TestHTTPValidator_Validate_Steps(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if id != "client-id" || secret != "client-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"access_token":"at-123"}`))
+	})
+	mux.HandleFunc("/me", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer at-123" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"name":"acme"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	def := ValidatorDef{
+		Name:    "oauth-client",
+		RuleIDs: []string{"np.test.1"},
+		HTTP: HTTPDef{
+			Method:       "GET",
+			URL:          server.URL + "/me",
+			Auth:         AuthDef{Type: "none", SecretGroup: "secret"},
+			Headers:      []Header{{Name: "Authorization", Value: "Bearer {{access_token}}"}},
+			SuccessCodes: []int{200},
+			FailureCodes: []int{401},
+			Extract:      []ExtractDef{{Name: "org", JSON: "name"}},
+			Steps: []HTTPDef{{
+				Method:       "POST",
+				URL:          server.URL + "/oauth/token",
+				Auth:         AuthDef{Type: "basic", Username: "{{id}}", SecretGroup: "secret"},
+				SuccessCodes: []int{200},
+				FailureCodes: []int{401},
+				Extract:      []ExtractDef{{Name: "access_token", JSON: "access_token"}},
+			}},
+		},
+	}
+	v := NewHTTPValidator(def, nil)
+
+	match := &types.Match{NamedGroups: map[string][]byte{"id": []byte("client-id"), "secret": []byte("client-secret")}}
+	result, err := v.Validate(context.Background(), match)
+	assert.NoError(t, err)
+	assert.Equal(t, types.StatusValid, result.Status)
+	assert.NotContains(t, result.Message, "at-123", "step fields aren't reported")
+	assert.Equal(t, "acme", result.Details["org"])
+
+	match.NamedGroups["secret"] = []byte("wrong")
+	result, err = v.Validate(context.Background(), match)
+	assert.NoError(t, err)
+	assert.Equal(t, types.StatusInvalid, result.Status)
+	assert.Equal(t, "step 1: HTTP 401 - credentials rejected", result.Message)
+}
+
+func TestParseJSONPath(t *testing.T) {
+	path, err := parseJSONPath("data.items[1][0].name")
+	assert.NoError(t, err)
+	assert.Equal(t, []jsonPathStep{{key: "data"}, {key: "items"}, {index: 1}, {index: 0}, {key: "name"}}, path)
+
+	path, err = parseJSONPath("[0].id")
+	assert.NoError(t, err)
+	assert.Equal(t, []jsonPathStep{{index: 0}, {key: "id"}}, path)
+
+	for _, bad := range []string{"", "a..b", "a[x]", "a[1", "a[1]b"} {
+		_, err := parseJSONPath(bad)
+		assert.Error(t, err, bad)
+	}
+}
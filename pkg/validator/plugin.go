@@ -0,0 +1,237 @@
+// pkg/validator/plugin.go
+package validator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// Default timeouts for plugin invocations.
+const (
+	DefaultPluginDescribeTimeout = 10 * time.Second
+	DefaultPluginValidateTimeout = 30 * time.Second
+)
+
+// PluginDescription is what a plugin prints in response to `describe`.
+type PluginDescription struct {
+	Name  string   `json:"name"`
+	Rules []string `json:"rules"`
+}
+
+// PluginRequest is written to a plugin's stdin for `validate`.
+type PluginRequest struct {
+	RuleID      string            `json:"rule_id"`
+	RuleName    string            `json:"rule_name,omitempty"`
+	Groups      []string          `json:"groups"`
+	NamedGroups map[string]string `json:"named_groups,omitempty"`
+	Snippet     PluginSnippet     `json:"snippet"`
+}
+
+// PluginSnippet is the match text and its surrounding context.
+type PluginSnippet struct {
+	Before   string `json:"before"`
+	Matching string `json:"matching"`
+	After    string `json:"after"`
+}
+
+// PluginResponse is what a plugin prints to stdout for `validate`.
+type PluginResponse struct {
+	Status     string  `json:"status"` // valid, invalid or undetermined
+	Confidence float64 `json:"confidence"`
+	Message    string  `json:"message"`
+}
+
+// PluginValidator validates matches by running an external executable, so
+// organizations can validate proprietary services without changing Titus.
+//
+// The protocol is JSON over stdin/stdout, one process per call:
+//
+//	<plugin> describe            prints a PluginDescription
+//	<plugin> validate < request  reads a PluginRequest, prints a PluginResponse
+//
+// A non-zero exit status is treated as a validation error, with stderr as the
+// reason.
+type PluginValidator struct {
+	path    string
+	name    string
+	rules   map[string]bool
+	timeout time.Duration
+}
+
+// NewPluginValidator runs the executable at path with `describe` and returns
+// a validator for the rules it reports.
+func NewPluginValidator(ctx context.Context, path string) (*PluginValidator, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultPluginDescribeTimeout)
+	defer cancel()
+
+	out, err := runPlugin(ctx, path, "describe", nil)
+	if err != nil {
+		return nil, err
+	}
+	var desc PluginDescription
+	if err := json.Unmarshal(out, &desc); err != nil {
+		return nil, fmt.Errorf("parsing describe output: %w", err)
+	}
+	if len(desc.Rules) == 0 {
+		return nil, fmt.Errorf("plugin reports no rules")
+	}
+	if desc.Name == "" {
+		desc.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	rules := make(map[string]bool, len(desc.Rules))
+	for _, id := range desc.Rules {
+		rules[id] = true
+	}
+	return &PluginValidator{
+		path:    path,
+		name:    desc.Name,
+		rules:   rules,
+		timeout: DefaultPluginValidateTimeout,
+	}, nil
+}
+
+// Name returns the plugin name.
+func (v *PluginValidator) Name() string {
+	return "plugin:" + v.name
+}
+
+// Timeout returns how long the plugin may take to validate a match.
+func (v *PluginValidator) Timeout() time.Duration {
+	return v.timeout
+}
+
+// CanValidate returns true for the rule IDs the plugin described.
+func (v *PluginValidator) CanValidate(ruleID string) bool {
+	return v.rules[ruleID]
+}
+
+// Validate sends the match to the plugin and converts its response.
+func (v *PluginValidator) Validate(ctx context.Context, match *types.Match) (*types.ValidationResult, error) {
+	req := PluginRequest{
+		RuleID:   match.RuleID,
+		RuleName: match.RuleName,
+		Groups:   make([]string, len(match.Groups)),
+		Snippet: PluginSnippet{
+			Before:   string(match.Snippet.Before),
+			Matching: string(match.Snippet.Matching),
+			After:    string(match.Snippet.After),
+		},
+	}
+	for i, g := range match.Groups {
+		req.Groups[i] = string(g)
+	}
+	if len(match.NamedGroups) > 0 {
+		req.NamedGroups = make(map[string]string, len(match.NamedGroups))
+		for name, value := range match.NamedGroups {
+			req.NamedGroups[name] = string(value)
+		}
+	}
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("encoding request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+	out, err := runPlugin(ctx, v.path, "validate", input)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp PluginResponse
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return nil, fmt.Errorf("parsing plugin response: %w", err)
+	}
+	status := types.ValidationStatus(resp.Status)
+	switch status {
+	case types.StatusValid, types.StatusInvalid, types.StatusUndetermined:
+	default:
+		return nil, fmt.Errorf("plugin returned unknown status %q", resp.Status)
+	}
+	return types.NewValidationResult(status, resp.Confidence, resp.Message), nil
+}
+
+// runPlugin runs the plugin with a single command argument, feeding it stdin
+// and returning its stdout.
+func runPlugin(ctx context.Context, path, command string, stdin []byte) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, path, command)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("running %s %s: %w: %s", filepath.Base(path), command, err, msg)
+		}
+		return nil, fmt.Errorf("running %s %s: %w", filepath.Base(path), command, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// DefaultPluginDir returns the directory validator plugins are loaded from
+// when none is given: titus/validators under the user's config directory.
+func DefaultPluginDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "titus", "validators")
+}
+
+// LoadPlugins creates a validator for every executable in dir, in name order.
+// Hidden files and non-executables are skipped. A missing dir yields no
+// plugins; a plugin that fails to describe itself is an error.
+func LoadPlugins(ctx context.Context, dir string) ([]Validator, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading plugin directory: %w", err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var plugins []Validator
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !isExecutable(entry.Name(), info.Mode()) {
+			continue
+		}
+		p, err := NewPluginValidator(ctx, filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("loading plugin %s: %w", entry.Name(), err)
+		}
+		plugins = append(plugins, p)
+	}
+	return plugins, nil
+}
+
+// isExecutable reports whether a file can be run as a plugin.
+func isExecutable(name string, mode os.FileMode) bool {
+	if !mode.IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".exe", ".bat", ".cmd":
+			return true
+		}
+		return false
+	}
+	return mode.Perm()&0o111 != 0
+}
@@ -0,0 +1,131 @@
+// pkg/validator/plugin_test.go
+package validator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// acmePlugin accepts the token "good-token" for rule acme.1.
+const acmePlugin = `#!/bin/sh
+case "$1" in
+describe)
+  echo '{"name": "acme", "rules": ["acme.1"]}'
+  ;;
+validate)
+  if grep -q '"token":"good-token"'; then
+    echo '{"status": "valid", "confidence": 1.0, "message": "acme token is live"}'
+  else
+    echo '{"status": "invalid", "confidence": 0.9, "message": "acme rejected token"}'
+  fi
+  ;;
+esac
+`
+
+func writePlugin(t *testing.T, dir, name, script string, mode os.FileMode) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(script), mode))
+}
+
+func skipWithoutShell(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin tests use shell scripts")
+	}
+}
+
+func TestLoadPlugins(t *testing.T) {
+	skipWithoutShell(t)
+	dir := t.TempDir()
+	writePlugin(t, dir, "acme", acmePlugin, 0o755)
+	writePlugin(t, dir, "README.md", "not a plugin", 0o644)
+	writePlugin(t, dir, ".hidden", acmePlugin, 0o755)
+
+	plugins, err := LoadPlugins(context.Background(), dir)
+	require.NoError(t, err)
+	require.Len(t, plugins, 1)
+	assert.Equal(t, "plugin:acme", plugins[0].Name())
+	assert.True(t, plugins[0].CanValidate("acme.1"))
+	assert.False(t, plugins[0].CanValidate("np.aws.1"))
+}
+
+func TestLoadPlugins_MissingDir(t *testing.T) {
+	plugins, err := LoadPlugins(context.Background(), filepath.Join(t.TempDir(), "nope"))
+	assert.NoError(t, err)
+	assert.Empty(t, plugins)
+}
+
+func TestLoadPlugins_BrokenPlugin(t *testing.T) {
+	skipWithoutShell(t)
+	dir := t.TempDir()
+	writePlugin(t, dir, "broken", "#!/bin/sh\necho 'no config' >&2\nexit 3\n", 0o755)
+
+	_, err := LoadPlugins(context.Background(), dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "broken")
+	assert.Contains(t, err.Error(), "no config")
+}
+
+func TestPluginValidator_Validate(t *testing.T) {
+	skipWithoutShell(t)
+	dir := t.TempDir()
+	writePlugin(t, dir, "acme", acmePlugin, 0o755)
+	v, err := NewPluginValidator(context.Background(), filepath.Join(dir, "acme"))
+	require.NoError(t, err)
+
+	match := func(token string) *types.Match {
+		return &types.Match{
+			RuleID:      "acme.1",
+			Groups:      [][]byte{[]byte(token)},
+			NamedGroups: map[string][]byte{"token": []byte(token)},
+		}
+	}
+
+	result, err := v.Validate(context.Background(), match("good-token"))
+	require.NoError(t, err)
+	assert.Equal(t, types.StatusValid, result.Status)
+	assert.Equal(t, "acme token is live", result.Message)
+
+	result, err = v.Validate(context.Background(), match("bad-token"))
+	require.NoError(t, err)
+	assert.Equal(t, types.StatusInvalid, result.Status)
+	assert.Equal(t, 0.9, result.Confidence)
+}
+
+func TestPluginValidator_BadResponse(t *testing.T) {
+	skipWithoutShell(t)
+	dir := t.TempDir()
+	script := "#!/bin/sh\nif [ \"$1\" = describe ]; then echo '{\"rules\": [\"x.1\"]}'; else echo '{\"status\": \"maybe\"}'; fi\n"
+	writePlugin(t, dir, "odd.sh", script, 0o755)
+	v, err := NewPluginValidator(context.Background(), filepath.Join(dir, "odd.sh"))
+	require.NoError(t, err)
+	assert.Equal(t, "plugin:odd", v.Name())
+
+	_, err = v.Validate(context.Background(), &types.Match{RuleID: "x.1"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown status")
+}
+
+func TestEngine_PrependTakesPrecedence(t *testing.T) {
+	builtin := &mockValidator{name: "builtin", ruleIDs: []string{"np.test.1"},
+		result: types.NewValidationResult(types.StatusInvalid, 1.0, "builtin")}
+	plugin := &mockValidator{name: "plugin", ruleIDs: []string{"np.test.1"},
+		result: types.NewValidationResult(types.StatusValid, 1.0, "plugin")}
+
+	engine := NewEngine(1, builtin)
+	engine.Prepend(plugin)
+
+	result, err := engine.ValidateMatch(context.Background(), &types.Match{
+		RuleID:      "np.test.1",
+		NamedGroups: map[string][]byte{"secret": []byte("s")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "plugin", result.Message)
+}
@@ -4,6 +4,7 @@ package validator
 import (
 	"context"
 	"fmt"
+	"net"
 
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/praetorian-inc/titus/pkg/types"
@@ -62,6 +63,7 @@ func (v *PostgresValidator) Validate(ctx context.Context, match *types.Match) (*
 	)
 
 	// Attempt connection
+	noteRequest(ctx, net.JoinHostPort(host, port))
 	conn, err := pgconn.Connect(ctx, connString)
 	if err != nil {
 		// Analyze error to determine if it's auth failure or network issue
@@ -0,0 +1,110 @@
+// pkg/validator/rules.go
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/rule"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// namedGroup matches the named capture groups of a rule pattern.
+var namedGroup = regexp.MustCompile(`\(\?P<(\w+)>`)
+
+// RuleValidators returns an HTTP validator for each rule with a validation
+// block it can make the request of. Blocks with Liquid tags ({% %}), or whose
+// templates read values the rule doesn't capture, such as those another
+// rule's match supplies, are left out.
+func RuleValidators(rules []*types.Rule) []Validator {
+	var validators []Validator
+	for _, r := range rules {
+		if r.Validation == nil {
+			continue
+		}
+		def, err := ruleValidatorDef(r)
+		if err != nil {
+			continue
+		}
+		validators = append(validators, NewHTTPValidator(def, nil))
+	}
+	return validators
+}
+
+// ruleValidatorDef converts a rule's validation block to a validator
+// definition named after the rule.
+func ruleValidatorDef(r *types.Rule) (ValidatorDef, error) {
+	v := r.Validation
+	if v.URL == "" || len(v.ResponseMatcher) == 0 {
+		return ValidatorDef{}, fmt.Errorf("rule %s: validation needs a url and response matchers", r.ID)
+	}
+	if err := checkResponseMatchers(v.ResponseMatcher); err != nil {
+		return ValidatorDef{}, fmt.Errorf("rule %s: %w", r.ID, err)
+	}
+
+	// {{ TOKEN }} is the secret, the other variables capture groups
+	groups := namedGroup.FindAllStringSubmatch(r.Pattern, -1)
+	known := map[string]bool{"TOKEN": true}
+	secretGroup := v.SecretGroup
+	for _, g := range groups {
+		known[strings.ToUpper(g[1])] = true
+		if secretGroup == "" && strings.EqualFold(g[1], "token") {
+			secretGroup = g[1]
+		}
+	}
+	if secretGroup == "" {
+		secretGroup = "1"
+	}
+
+	templates := []string{v.URL, v.Body}
+	headers := make([]Header, 0, len(v.Headers))
+	for name, value := range v.Headers {
+		headers = append(headers, Header{Name: name, Value: value})
+		templates = append(templates, value)
+	}
+	sort.Slice(headers, func(i, j int) bool { return headers[i].Name < headers[j].Name })
+	for _, t := range templates {
+		names, err := templateVariables(t)
+		if err != nil {
+			return ValidatorDef{}, fmt.Errorf("rule %s: %w", r.ID, err)
+		}
+		for _, name := range names {
+			if !known[name] {
+				return ValidatorDef{}, fmt.Errorf("rule %s: validation needs %s, which the rule doesn't capture", r.ID, name)
+			}
+		}
+	}
+
+	method := strings.ToUpper(v.Method)
+	if method == "" {
+		method = "GET"
+	}
+	return ValidatorDef{
+		Name:    r.ID,
+		RuleIDs: []string{r.ID},
+		Timeout: time.Duration(v.TimeoutSeconds) * time.Second,
+		HTTP: HTTPDef{
+			Method:          method,
+			URL:             v.URL,
+			Auth:            AuthDef{Type: "none", SecretGroup: secretGroup},
+			Headers:         headers,
+			Body:            v.Body,
+			ResponseMatcher: slices.Clone(v.ResponseMatcher),
+		},
+	}, nil
+}
+
+// builtinRuleValidators returns the validators of the built-in rules'
+// validation blocks, loaded once.
+var builtinRuleValidators = sync.OnceValue(func() []Validator {
+	rules, err := rule.NewLoader().LoadBuiltinRules()
+	if err != nil {
+		return nil
+	}
+	return RuleValidators(rules)
+})
@@ -0,0 +1,115 @@
+// pkg/validator/rules_test.go
+package validator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleValidators(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Query().Get("key") == "good-key" && r.Header.Get("Authorization") == "Basic YWNtZTpnb29kLWtleQ==":
+			w.Write([]byte(`{"projects":[{"id":1}]}`))
+		case r.URL.Query().Get("key") == "busy-key":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Query().Get("key") == "revoked-key":
+			w.Write([]byte(`{"error":"key revoked"}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	r := &types.Rule{
+		ID:      "kingfisher.example.1",
+		Pattern: `(?P<account>[a-z]+):(?P<token>[a-z-]+)`,
+		Validation: &types.HTTPValidation{
+			Method: "get",
+			URL:    server.URL + "/api/v4/projects?key={{ TOKEN }}",
+			Headers: map[string]string{
+				"Authorization": "Basic {{ ACCOUNT | append: ':' | append: TOKEN | b64enc }}",
+			},
+			ResponseMatcher: []types.ResponseMatcher{
+				{ReportResponse: true},
+				{Type: types.StatusMatch, Status: []int{200}},
+				{Type: types.WordMatch, Words: []string{`"id"`}},
+				{Type: types.WordMatch, Words: []string{`"error"`}, Negative: true},
+				{Type: types.JSONValid},
+			},
+			TimeoutSeconds: 12,
+		},
+	}
+	validators := RuleValidators([]*types.Rule{r})
+	require.Len(t, validators, 1)
+	v := validators[0].(*HTTPValidator)
+	assert.Equal(t, "kingfisher.example.1", v.Name())
+	assert.True(t, v.CanValidate("kingfisher.example.1"))
+	assert.Equal(t, 12*time.Second, v.Timeout())
+
+	validate := func(key string) *types.ValidationResult {
+		result, err := v.Validate(context.Background(), &types.Match{
+			RuleID:      r.ID,
+			NamedGroups: map[string][]byte{"account": []byte("acme"), "token": []byte(key)},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	result := validate("good-key")
+	assert.Equal(t, types.StatusValid, result.Status)
+	assert.Equal(t, `{"projects":[{"id":1}]}`, result.Details["response"], "report_response keeps the body")
+	assert.Equal(t, types.StatusInvalid, validate("bad-key").Status)
+	assert.Equal(t, types.StatusInvalid, validate("revoked-key").Status)
+	assert.Equal(t, types.StatusUndetermined, validate("busy-key").Status)
+}
+
+func TestRuleValidators_Unsupported(t *testing.T) {
+	matchers := []types.ResponseMatcher{{Type: types.StatusMatch, Status: []int{200}}}
+	rules := []*types.Rule{
+		{ID: "no.validation", Pattern: `(?P<token>x)`},
+		{ID: "other.rule.variable", Pattern: `(?P<token>x)`, Validation: &types.HTTPValidation{
+			URL: "https://{{ APPID }}.example.com/keys/{{ TOKEN }}", ResponseMatcher: matchers,
+		}},
+		{ID: "liquid.tags", Pattern: `(?P<token>x)`, Validation: &types.HTTPValidation{
+			URL: `{%- assign r = TOKEN -%}https://example.com/{{ r }}`, ResponseMatcher: matchers,
+		}},
+		{ID: "unknown.filter", Pattern: `(?P<token>x)`, Validation: &types.HTTPValidation{
+			URL: "https://example.com/", Headers: map[string]string{"X-Sig": "{{ TOKEN | hmac_sha1: 'k' }}"}, ResponseMatcher: matchers,
+		}},
+		{ID: "no.matchers", Pattern: `(?P<token>x)`, Validation: &types.HTTPValidation{URL: "https://example.com/"}},
+	}
+	assert.Empty(t, RuleValidators(rules))
+}
+
+func TestBuiltinRuleValidators(t *testing.T) {
+	validators := builtinRuleValidators()
+	assert.Greater(t, len(validators), 100)
+
+	names := make(map[string]bool)
+	for _, v := range validators {
+		names[v.Name()] = true
+	}
+	assert.True(t, names["kingfisher.airbrake.1"])
+	assert.False(t, names["kingfisher.algolia.1"], "the app ID comes from another rule's match")
+
+	// The validators written for a rule come first, and the blocks cover the rest
+	engine := NewDefaultEngine(1)
+	validatorFor := func(ruleID string) string {
+		for _, v := range engine.validators {
+			if v.CanValidate(ruleID) {
+				return v.Name()
+			}
+		}
+		return ""
+	}
+	assert.Equal(t, "airbrake-user-key", validatorFor("kingfisher.airbrake.1"))
+	assert.Equal(t, "kingfisher.apify.1", validatorFor("kingfisher.apify.1"))
+}
@@ -0,0 +1,218 @@
+// pkg/validator/ssh.go
+package validator
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"golang.org/x/crypto/ssh"
+)
+
+// Pre-compiled patterns for extracting an SSH host from snippet context, most
+// specific first: ssh_config HostName, Ansible inventory, ssh commands and URLs,
+// known_hosts entries, ssh_config Host, then generic host assignments.
+var sshHostPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?im)^\s*HostName\s+([a-z0-9][a-z0-9.-]*)`),
+	regexp.MustCompile(`(?i)\bansible_(?:ssh_)?host\s*[=:]\s*["']?([a-z0-9][a-z0-9.-]*)`),
+	regexp.MustCompile(`(?i)\bssh\s+(?:-\S+\s+(?:\S+\s+)?)*[a-z_][a-z0-9_.-]*@([a-z0-9][a-z0-9.-]*)`),
+	regexp.MustCompile(`(?i)\bssh://(?:[a-z_][a-z0-9_.-]*@)?([a-z0-9][a-z0-9.-]*)`),
+	regexp.MustCompile(`(?m)^([a-zA-Z0-9][a-zA-Z0-9.-]*)(?:,\S+)?\s+(?:ssh-(?:rsa|ed25519|dss)|ecdsa-sha2-nistp\d+)\s+AAAA`),
+	regexp.MustCompile(`(?im)^\s*Host\s+([a-z0-9][a-z0-9.-]*)\s*$`),
+	regexp.MustCompile(`(?i)\b(?:(?:ssh|deploy|remote)_?)?(?:host|hostname|server)\s*[=:]\s*["']?([a-z0-9][a-z0-9.-]*)`),
+}
+
+// Pre-compiled patterns for extracting an SSH user from snippet context.
+var sshUserPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?im)^\s*User\s+([a-z_][a-z0-9_.-]*)`),
+	regexp.MustCompile(`(?i)\bansible_(?:ssh_)?user\s*[=:]\s*["']?([a-z_][a-z0-9_.-]*)`),
+	regexp.MustCompile(`(?i)\bssh\s+(?:-\S+\s+(?:\S+\s+)?)*([a-z_][a-z0-9_.-]*)@[a-z0-9]`),
+	regexp.MustCompile(`(?i)\bssh://([a-z_][a-z0-9_.-]*)@`),
+	regexp.MustCompile(`(?i)\b(?:(?:ssh|deploy|remote)_?)?(?:user|username|login)\s*[=:]\s*["']?([a-z_][a-z0-9_.-]*)`),
+}
+
+// Pre-compiled patterns for extracting an SSH port from snippet context.
+var sshPortPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?im)^\s*Port\s+(\d{1,5})\b`),
+	regexp.MustCompile(`(?i)\bansible_(?:ssh_)?port\s*[=:]\s*["']?(\d{1,5})\b`),
+	regexp.MustCompile(`(?i)\bssh://[^\s/:]+:(\d{1,5})\b`),
+}
+
+// pemBlockPattern finds the armor of a PEM-encoded private key.
+var pemBlockPattern = regexp.MustCompile(`-----BEGIN ([A-Z0-9 ]*PRIVATE KEY)-----([\s\S]*?)-----END ([A-Z0-9 ]*PRIVATE KEY)-----`)
+
+// pemConcatPattern matches the quotes and operators joining lines of a key
+// written as string concatenation, e.g. "AbC..." + (newline) "DeF...".
+var pemConcatPattern = regexp.MustCompile(`["'][ \t]*[+,]?[ \t]*\r?\n[ \t]*\+?[ \t]*["']`)
+
+// SSHKeyValidator checks whether a private key grants SSH access to a host
+// named in the surrounding context (ssh_config, known_hosts, Ansible inventory,
+// ssh commands). It only performs public-key authentication: the connection is
+// closed as soon as the server accepts or rejects the key, and no session is
+// opened and no command is run.
+//
+// Logging in to third-party hosts is intrusive, so this validator is not part
+// of the default engine; it is only registered by NewAggressiveEngine.
+type SSHKeyValidator struct {
+	timeout time.Duration
+	dial    func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// NewSSHKeyValidator creates a new SSH private key validator.
+func NewSSHKeyValidator() *SSHKeyValidator {
+	d := &net.Dialer{Timeout: 5 * time.Second}
+	return &SSHKeyValidator{
+		timeout: 5 * time.Second,
+		dial:    d.DialContext,
+	}
+}
+
+// Name returns the validator name.
+func (v *SSHKeyValidator) Name() string {
+	return "ssh"
+}
+
+// CanValidate returns true for PEM-encoded private key rule IDs.
+func (v *SSHKeyValidator) CanValidate(ruleID string) bool {
+	return ruleID == "np.pem.1"
+}
+
+// Validate attempts public-key authentication with the key against the host
+// and user found in the match context.
+func (v *SSHKeyValidator) Validate(ctx context.Context, match *types.Match) (*types.ValidationResult, error) {
+	signer, err := parseSSHPrivateKey(match.Snippet.Matching)
+	if err != nil {
+		var missing *ssh.PassphraseMissingError
+		if errors.As(err, &missing) {
+			return types.NewValidationResult(types.StatusUndetermined, 0, "cannot validate: key is passphrase-protected"), nil
+		}
+		return types.NewValidationResult(types.StatusUndetermined, 0, fmt.Sprintf("cannot validate: %v", err)), nil
+	}
+
+	host, user, port, err := v.extractTarget(match)
+	if err != nil {
+		return types.NewValidationResult(types.StatusUndetermined, 0, fmt.Sprintf("cannot validate: %v", err)), nil
+	}
+
+	if isLocalhost(host) {
+		return types.NewValidationResult(types.StatusUndetermined, 0, "skipping localhost address - cannot validate"), nil
+	}
+
+	addr := net.JoinHostPort(host, port)
+	noteRequest(ctx, addr)
+	conn, err := v.dial(ctx, "tcp", addr)
+	if err != nil {
+		return types.NewValidationResult(types.StatusUndetermined, 0, fmt.Sprintf("connection failed: %v", err)), nil
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(v.timeout))
+
+	config := &ssh.ClientConfig{
+		User: user,
+		Auth: []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		// The host is untrusted either way; the key signature is bound to the
+		// session and cannot be replayed by whoever answers.
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         v.timeout,
+	}
+	client, _, _, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		if strings.Contains(err.Error(), "unable to authenticate") {
+			return types.NewValidationResult(
+				types.StatusInvalid,
+				1.0,
+				fmt.Sprintf("key rejected for %s@%s", user, addr),
+			), nil
+		}
+		return types.NewValidationResult(types.StatusUndetermined, 0, fmt.Sprintf("ssh handshake failed: %v", err)), nil
+	}
+	client.Close()
+
+	return types.NewValidationResult(
+		types.StatusValid,
+		1.0,
+		fmt.Sprintf("key grants SSH access to %s@%s", user, addr),
+	), nil
+}
+
+// extractTarget finds the host, user and port (default "22") in the match context.
+func (v *SSHKeyValidator) extractTarget(match *types.Match) (host, user, port string, err error) {
+	// The key body itself is excluded so base64 cannot be mistaken for context
+	parts := [][]byte{match.Snippet.Before, match.Snippet.After}
+
+	host = findFirst(sshHostPatterns, parts)
+	if host == "" {
+		return "", "", "", fmt.Errorf("no SSH host found in context")
+	}
+	user = findFirst(sshUserPatterns, parts)
+	if user == "" {
+		return "", "", "", fmt.Errorf("partial target: found host %s but no user in context", host)
+	}
+	port = findFirst(sshPortPatterns, parts)
+	if port == "" {
+		port = "22"
+	}
+	return host, user, port, nil
+}
+
+// findFirst returns the first capture of the first pattern that matches any part.
+func findFirst(patterns []*regexp.Regexp, parts [][]byte) string {
+	for _, pattern := range patterns {
+		for _, part := range parts {
+			if m := pattern.FindSubmatch(part); len(m) >= 2 {
+				return string(m[1])
+			}
+		}
+	}
+	return ""
+}
+
+// parseSSHPrivateKey parses a PEM-encoded private key as matched in source,
+// where it may be wrapped in quotes, joined with + or use escaped newlines.
+func parseSSHPrivateKey(matched []byte) (ssh.Signer, error) {
+	cleaned := strings.NewReplacer(`\r`, "", `\n`, "\n").Replace(string(matched))
+	cleaned = pemConcatPattern.ReplaceAllString(cleaned, "\n")
+	m := pemBlockPattern.FindStringSubmatch(cleaned)
+	if m == nil {
+		return nil, fmt.Errorf("no PEM private key block")
+	}
+
+	body := m[2]
+	var headers strings.Builder
+	var b64 strings.Builder
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.Contains(line, ":") {
+			// RFC 1421 headers of legacy encrypted keys (Proc-Type, DEK-Info)
+			headers.WriteString(line + "\n")
+			continue
+		}
+		b64.WriteString(line)
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "-----BEGIN %s-----\n", m[1])
+	if headers.Len() > 0 {
+		out.WriteString(headers.String() + "\n")
+	}
+	data := b64.String()
+	for len(data) > 64 {
+		out.WriteString(data[:64] + "\n")
+		data = data[64:]
+	}
+	if data != "" {
+		out.WriteString(data + "\n")
+	}
+	fmt.Fprintf(&out, "-----END %s-----\n", m[3])
+
+	if block, _ := pem.Decode([]byte(out.String())); block == nil {
+		return nil, fmt.Errorf("malformed PEM private key")
+	}
+	return ssh.ParsePrivateKey([]byte(out.String()))
+}
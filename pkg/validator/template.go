@@ -0,0 +1,193 @@
+// pkg/validator/template.go
+package validator
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// templateFilters are the filters a template expression may apply, as in
+// {{ TOKEN | append: ':' | b64enc }}. Each gets the value so far and the
+// filter's arguments.
+var templateFilters = map[string]func(value string, args []string) (string, error){
+	"append": func(value string, args []string) (string, error) {
+		return value + strings.Join(args, ""), nil
+	},
+	"prepend": func(value string, args []string) (string, error) {
+		return strings.Join(args, "") + value, nil
+	},
+	"replace": func(value string, args []string) (string, error) {
+		if len(args) != 2 {
+			return "", fmt.Errorf("replace takes 2 arguments")
+		}
+		return strings.ReplaceAll(value, args[0], args[1]), nil
+	},
+	"b64enc": func(value string, _ []string) (string, error) {
+		return base64.StdEncoding.EncodeToString([]byte(value)), nil
+	},
+	"json_escape": func(value string, _ []string) (string, error) {
+		quoted, err := json.Marshal(value)
+		return string(quoted[1 : len(quoted)-1]), err
+	},
+	"url_encode": func(value string, _ []string) (string, error) {
+		return url.QueryEscape(value), nil
+	},
+	"upcase": func(value string, _ []string) (string, error) {
+		return strings.ToUpper(value), nil
+	},
+	"downcase": func(value string, _ []string) (string, error) {
+		return strings.ToLower(value), nil
+	},
+}
+
+// templateExpr is the expression inside {{ }}: a variable and the filters
+// applied to it.
+type templateExpr struct {
+	name    string
+	filters []templateFilter
+}
+
+// templateFilter is a filter of a template expression. An argument is a
+// quoted string or the name of a variable.
+type templateFilter struct {
+	name string
+	args []templateArg
+}
+
+type templateArg struct {
+	value    string
+	variable bool
+}
+
+// parseTemplateExpr parses the text between {{ and }}. A variable may be
+// written as name or .name.
+func parseTemplateExpr(s string) (templateExpr, error) {
+	parts := strings.Split(s, "|")
+	expr := templateExpr{name: strings.TrimPrefix(strings.TrimSpace(parts[0]), ".")}
+	if expr.name == "" {
+		return expr, fmt.Errorf("template %q has no variable", s)
+	}
+	for _, part := range parts[1:] {
+		name, rawArgs, _ := strings.Cut(part, ":")
+		f := templateFilter{name: strings.TrimSpace(name)}
+		if _, ok := templateFilters[f.name]; !ok {
+			return expr, fmt.Errorf("unknown template filter %q", f.name)
+		}
+		if strings.TrimSpace(rawArgs) != "" {
+			for _, a := range strings.Split(rawArgs, ",") {
+				a = strings.TrimSpace(a)
+				if len(a) >= 2 && (a[0] == '"' || a[0] == '\'') && a[len(a)-1] == a[0] {
+					f.args = append(f.args, templateArg{value: a[1 : len(a)-1]})
+				} else {
+					f.args = append(f.args, templateArg{value: a, variable: true})
+				}
+			}
+		}
+		expr.filters = append(expr.filters, f)
+	}
+	return expr, nil
+}
+
+// variables returns the names of the variables expr reads.
+func (expr templateExpr) variables() []string {
+	names := []string{expr.name}
+	for _, f := range expr.filters {
+		for _, a := range f.args {
+			if a.variable {
+				names = append(names, a.value)
+			}
+		}
+	}
+	return names
+}
+
+// eval evaluates expr, reporting false if a variable it reads isn't set.
+func (expr templateExpr) eval(vars map[string][]byte) (string, bool, error) {
+	value, ok := vars[expr.name]
+	if !ok {
+		return "", false, nil
+	}
+	out := string(value)
+	for _, f := range expr.filters {
+		args := make([]string, len(f.args))
+		for i, a := range f.args {
+			args[i] = a.value
+			if a.variable {
+				v, ok := vars[a.value]
+				if !ok {
+					return "", false, nil
+				}
+				args[i] = string(v)
+			}
+		}
+		var err error
+		if out, err = templateFilters[f.name](out, args); err != nil {
+			return "", false, fmt.Errorf("template filter %s: %w", f.name, err)
+		}
+	}
+	return out, true, nil
+}
+
+// templateVariables returns the names of the variables the templates in s
+// read, or an error if one can't be parsed. Liquid tags, {% %}, aren't
+// supported.
+func templateVariables(s string) ([]string, error) {
+	if strings.Contains(s, "{%") {
+		return nil, fmt.Errorf("template tags ({%% %%}) are not supported")
+	}
+	var names []string
+	for rest := s; ; {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			return names, nil
+		}
+		end := strings.Index(rest[start:], "}}")
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated template in %q", s)
+		}
+		expr, err := parseTemplateExpr(rest[start+2 : start+end])
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, expr.variables()...)
+		rest = rest[start+end+2:]
+	}
+}
+
+// substituteTemplateVars replaces the templates in s with their values,
+// from named capture groups and the like. It accepts {{name}}, {{ name }},
+// {{.name}} and {{ .name }}, each optionally with filters. Templates that
+// read variables that aren't set, or can't be parsed, are left as they are.
+func substituteTemplateVars(s string, vars map[string][]byte) string {
+	var out strings.Builder
+	for {
+		start := strings.Index(s, "{{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(s[start:], "}}")
+		if end < 0 {
+			break
+		}
+		out.WriteString(s[:start])
+		raw := s[start : start+end+2]
+		s = s[start+end+2:]
+
+		expr, err := parseTemplateExpr(raw[2 : len(raw)-2])
+		if err != nil {
+			out.WriteString(raw)
+			continue
+		}
+		value, ok, err := expr.eval(vars)
+		if err != nil || !ok {
+			out.WriteString(raw)
+			continue
+		}
+		out.WriteString(value)
+	}
+	out.WriteString(s)
+	return out.String()
+}
@@ -0,0 +1,39 @@
+// pkg/validator/template_test.go
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubstituteTemplateVars(t *testing.T) {
+	vars := map[string][]byte{"TOKEN": []byte(`s3"cr/t`), "CLIENTID": []byte("app"), "host": []byte("example.com")}
+
+	for tmpl, want := range map[string]string{
+		"https://{{host}}/a":                              "https://example.com/a",
+		"https://{{ .host }}/a":                           "https://example.com/a",
+		"Basic {{ TOKEN | append: ':' | b64enc }}":        "Basic czMiY3IvdDo=",
+		`{{ CLIENTID | append: ":" | append: TOKEN }}`:    `app:s3"cr/t`,
+		`{"key": "{{ TOKEN | json_escape }}"}`:            `{"key": "s3\"cr/t"}`,
+		"?key={{ TOKEN | url_encode }}":                   "?key=s3%22cr%2Ft",
+		`{{ host | replace: ".com", ".org" | upcase }}`:   "EXAMPLE.ORG",
+		"{{ MISSING }} and {{ TOKEN | append: MISSING }}": "{{ MISSING }} and {{ TOKEN | append: MISSING }}",
+		"{{ TOKEN | no_such_filter }}":                    "{{ TOKEN | no_such_filter }}",
+		"no templates, {{ unterminated":                   "no templates, {{ unterminated",
+	} {
+		assert.Equal(t, want, substituteTemplateVars(tmpl, vars), tmpl)
+	}
+}
+
+func TestTemplateVariables(t *testing.T) {
+	names, err := templateVariables("https://{{ APPID }}.example.com/{{ TOKEN | append: ':' | append: SECRET }}")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"APPID", "TOKEN", "SECRET"}, names)
+
+	for _, bad := range []string{"{% if x %}", "{{ TOKEN | hmac_sha1: 'k' }}", "{{ }}", "{{ TOKEN"} {
+		_, err := templateVariables(bad)
+		assert.Error(t, err, bad)
+	}
+}
@@ -3,7 +3,9 @@ package validator
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/praetorian-inc/titus/pkg/types"
 	"gopkg.in/yaml.v3"
 )
 
@@ -14,22 +16,33 @@ type ValidatorsConfig struct {
 
 // ValidatorDef defines a single HTTP-based validator.
 type ValidatorDef struct {
-	Name    string   `yaml:"name"`
-	RuleIDs []string `yaml:"rule_ids"`
-	HTTP    HTTPDef  `yaml:"http"`
+	Name    string        `yaml:"name"`
+	RuleIDs []string      `yaml:"rule_ids"`
+	HTTP    HTTPDef       `yaml:"http"`
+	Browser bool          `yaml:"browser,omitempty"` // endpoint permits cross-origin requests, so it can run from the WASM build
+	Timeout time.Duration `yaml:"timeout,omitempty"` // how long a validation, steps included, may take (default: the engine's)
 }
 
 // HTTPDef defines HTTP request configuration.
 type HTTPDef struct {
-	Method              string   `yaml:"method"`
-	URL                 string   `yaml:"url"`
-	Auth                AuthDef  `yaml:"auth"`
-	Headers             []Header `yaml:"headers,omitempty"`
-	Body                string   `yaml:"body,omitempty"` // Static request body for POST/PUT
-	SuccessCodes        []int    `yaml:"success_codes"`
-	FailureCodes        []int    `yaml:"failure_codes"`
-	SuccessBodyContains string   `yaml:"success_body_contains,omitempty"` // Response body must contain this string for success
-	FailureBodyContains string   `yaml:"failure_body_contains,omitempty"` // Response body containing this string indicates failure
+	Method              string                  `yaml:"method"`
+	URL                 string                  `yaml:"url"`
+	Auth                AuthDef                 `yaml:"auth"`
+	Headers             []Header                `yaml:"headers,omitempty"`
+	Body                string                  `yaml:"body,omitempty"` // Static request body for POST/PUT
+	SuccessCodes        []int                   `yaml:"success_codes"`
+	FailureCodes        []int                   `yaml:"failure_codes"`
+	SuccessBodyContains string                  `yaml:"success_body_contains,omitempty"` // Response body must contain this string for success
+	FailureBodyContains string                  `yaml:"failure_body_contains,omitempty"` // Response body containing this string indicates failure
+	ResponseMatcher     []types.ResponseMatcher `yaml:"response_matcher,omitempty"`      // Response matchers, in the schema of rule validation blocks
+	Extract             []ExtractDef            `yaml:"extract,omitempty"`               // Response fields added to the message of a valid result, or passed to later requests by steps
+	Steps               []HTTPDef               `yaml:"steps,omitempty"`                 // Requests made first, in order, e.g. to exchange the secret for a token
+}
+
+// ExtractDef pulls a field out of a JSON response.
+type ExtractDef struct {
+	Name string `yaml:"name"` // label in the message, and template variable for later requests
+	JSON string `yaml:"json"` // path to the field, e.g. user.email or accounts[0].id
 }
 
 // AuthDef defines authentication configuration.
@@ -38,7 +51,7 @@ type AuthDef struct {
 	SecretGroup string `yaml:"secret_group"`           // named capture group containing the secret (e.g., "secret", "token")
 	HeaderName  string `yaml:"header_name,omitempty"`  // for type=header
 	QueryParam  string `yaml:"query_param,omitempty"`  // for type=query
-	Username    string `yaml:"username,omitempty"`     // for type=basic (if static)
+	Username    string `yaml:"username,omitempty"`     // for type=basic (static, or a {{group}} template)
 	KeyPrefix   string `yaml:"key_prefix,omitempty"`   // for type=api_key, default "key=" (e.g., "Authorization: key=SECRET")
 }
 
@@ -57,8 +70,64 @@ func LoadValidatorsFromYAML(data []byte) ([]Validator, error) {
 
 	validators := make([]Validator, 0, len(cfg.Validators))
 	for _, def := range cfg.Validators {
+		if err := checkHTTPDef(&def.HTTP, true); err != nil {
+			return nil, fmt.Errorf("validator %s: %w", def.Name, err)
+		}
 		validators = append(validators, NewHTTPValidator(def, nil))
 	}
 
 	return validators, nil
 }
+
+// checkResponseMatchers reports response matchers that can't match.
+func checkResponseMatchers(matchers []types.ResponseMatcher) error {
+	for _, m := range matchers {
+		switch m.Type {
+		case types.StatusMatch:
+			if len(m.Status) == 0 {
+				return fmt.Errorf("StatusMatch without status")
+			}
+		case types.WordMatch:
+			if len(m.Words) == 0 {
+				return fmt.Errorf("WordMatch without words")
+			}
+		case types.HeaderMatch:
+			if m.Header == "" || len(m.Expected) == 0 {
+				return fmt.Errorf("HeaderMatch needs a header and expected values")
+			}
+		case types.JSONValid:
+		case "":
+			if !m.ReportResponse {
+				return fmt.Errorf("response matcher without a type")
+			}
+		default:
+			return fmt.Errorf("unknown response matcher type %q: want StatusMatch, WordMatch, HeaderMatch or JsonValid", m.Type)
+		}
+	}
+	return nil
+}
+
+// checkHTTPDef reports matchers, extractions and steps that can't work.
+// Steps can't have steps of their own.
+func checkHTTPDef(h *HTTPDef, top bool) error {
+	if err := checkResponseMatchers(h.ResponseMatcher); err != nil {
+		return err
+	}
+	for _, e := range h.Extract {
+		if e.Name == "" || e.JSON == "" {
+			return fmt.Errorf("extract needs a name and a json path")
+		}
+		if _, err := parseJSONPath(e.JSON); err != nil {
+			return err
+		}
+	}
+	if len(h.Steps) > 0 && !top {
+		return fmt.Errorf("steps can't have steps")
+	}
+	for i := range h.Steps {
+		if err := checkHTTPDef(&h.Steps[i], false); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
@@ -3,8 +3,10 @@ package validator
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 )
 
@@ -77,3 +79,51 @@ validators:
 	assert.True(t, validators[0].CanValidate("np.github.1"))
 	assert.True(t, validators[1].CanValidate("np.slack.1"))
 }
+
+func TestLoadValidatorsFromYAML_InvalidMatchers(t *testing.T) {
+	for name, http := range map[string]string{
+		"unknown matcher type":   `response_matcher: [{type: RegexMatch}]`,
+		"word matcher no words":  `response_matcher: [{type: WordMatch}]`,
+		"header matcher no name": `response_matcher: [{type: HeaderMatch, expected: [json]}]`,
+		"invalid json path":      `extract: [{name: id, json: "a[x]"}]`,
+		"nested steps":           `steps: [{url: "https://example.com", steps: [{url: "https://example.com"}]}]`,
+	} {
+		yamlData := []byte(`
+validators:
+  - name: broken
+    rule_ids: [np.test.1]
+    http:
+      method: GET
+      url: https://example.com
+      ` + http + `
+`)
+		_, err := LoadValidatorsFromYAML(yamlData)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestLoadValidatorsFromYAML_Timeout(t *testing.T) {
+	yamlData := []byte(`
+validators:
+  - name: slow-api
+    rule_ids: [np.slow.1]
+    timeout: 45s
+    http:
+      method: GET
+      url: https://api.example.com/me
+      auth:
+        type: bearer
+        secret_group: token
+      success_codes: [200]
+`)
+
+	validators, err := LoadValidatorsFromYAML(yamlData)
+	require.NoError(t, err)
+	require.Len(t, validators, 1)
+	assert.Equal(t, 45*time.Second, validators[0].(*HTTPValidator).Timeout())
+
+	engine := NewEngine(1, validators...)
+	assert.Equal(t, 45*time.Second, engine.timeoutFor(validators[0]))
+	engine.SetValidatorTimeout("slow-api", 5*time.Second)
+	assert.Equal(t, 5*time.Second, engine.timeoutFor(validators[0]))
+}
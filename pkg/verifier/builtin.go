@@ -0,0 +1,140 @@
+package verifier
+
+import (
+	"encoding/base32"
+	"hash/crc32"
+	"strings"
+)
+
+// Built-in verifier names.
+const (
+	Luhn              = "luhn"                 // payment card number with a valid Luhn check digit
+	SSN               = "ssn"                  // US Social Security number in an issuable range
+	Base32            = "base32"               // decodes as unpadded RFC 4648 base32
+	AWSAccountFromKey = "aws_account_from_key" // AWS key ID that encodes an account ID
+	GitHubCRC32       = "github_crc32"         // GitHub token whose last 6 characters are its CRC32
+)
+
+// luhn reports whether the digits of value, ignoring spaces and dashes,
+// form a 12 to 19 digit number with a valid Luhn check digit.
+func luhn(value []byte) bool {
+	var sum, n int
+	for i := len(value) - 1; i >= 0; i-- {
+		c := value[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 12 && n <= 19 && sum%10 == 0
+}
+
+// ssn reports whether value is a ddd-dd-dddd number that could be a US
+// Social Security number: area not 000, 666 or 900-999, and neither the
+// group nor the serial all zeros.
+func ssn(value []byte) bool {
+	s := string(value)
+	if len(s) != 11 || s[3] != '-' || s[6] != '-' {
+		return false
+	}
+	area, group, serial := s[0:3], s[4:6], s[7:11]
+	for _, part := range []string{area, group, serial} {
+		for _, c := range part {
+			if c < '0' || c > '9' {
+				return false
+			}
+		}
+	}
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
+
+// base32Decodes reports whether value, stripped of any = padding, decodes
+// as RFC 4648 base32.
+func base32Decodes(value []byte) bool {
+	s := strings.TrimRight(string(value), "=")
+	if s == "" {
+		return false
+	}
+	_, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(s)
+	return err == nil
+}
+
+// awsAccountFromKey reports whether value is an AWS access key ID whose
+// characters after the four-letter prefix decode, as base32, to an account
+// ID. Key IDs are drawn from the base32 alphabet, so strings with 0, 1, 8
+// or 9 after the prefix are not keys.
+func awsAccountFromKey(value []byte) bool {
+	_, ok := AWSAccountID(string(value))
+	return ok
+}
+
+// AWSAccountID returns the 12-digit ID of the AWS account that the access
+// key ID keyID (AKIA..., ASIA... and so on) belongs to, as encoded in the
+// key ID itself.
+func AWSAccountID(keyID string) (string, bool) {
+	if len(keyID) != 20 {
+		return "", false
+	}
+	decoded, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(keyID[4:])
+	if err != nil || len(decoded) < 6 {
+		return "", false
+	}
+	var z uint64
+	for _, b := range decoded[:6] {
+		z = z<<8 | uint64(b)
+	}
+	account := (z & 0x7fffffffff80) >> 7
+	if account >= 1e12 {
+		return "", false
+	}
+	s := "000000000000" + uitoa(account)
+	return s[len(s)-12:], true
+}
+
+// githubCRC32 reports whether value is a GitHub token (ghp_, gho_, ghu_,
+// ghs_ or ghr_ and 36 characters) whose last 6 characters are the base62
+// CRC32 of the 30 before them.
+func githubCRC32(value []byte) bool {
+	s := string(value)
+	if len(s) != 40 || !strings.HasPrefix(s, "gh") || s[3] != '_' {
+		return false
+	}
+	random, checksum := s[4:34], s[34:]
+	return base62(crc32.ChecksumIEEE([]byte(random)), 6) == checksum
+}
+
+const base62Digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// base62 encodes n in base62, left-padded with zeros to width.
+func base62(n uint32, width int) string {
+	buf := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		buf[i] = base62Digits[n%62]
+		n /= 62
+	}
+	return string(buf)
+}
+
+func uitoa(n uint64) string {
+	if n == 0 {
+		return "0"
+	}
+	var buf [20]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = byte('0' + n%10)
+		n /= 10
+	}
+	return string(buf[i:])
+}
@@ -0,0 +1,65 @@
+// Package verifier holds the post-match checks that a rule can name with
+// verify:. A verifier is a cheap, offline check of the value a rule
+// captured, such as a check digit or an embedded checksum. Matches whose
+// value fails are dropped before they are recorded, which cuts false
+// positives for token formats with internal structure.
+//
+// Unlike the live checks in package validator, verifiers never contact the
+// service a secret belongs to.
+package verifier
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Func reports whether value, the secret a rule captured, passes the check.
+type Func func(value []byte) bool
+
+var (
+	mu        sync.RWMutex
+	verifiers = map[string]Func{
+		Luhn:              luhn,
+		SSN:               ssn,
+		Base32:            base32Decodes,
+		AWSAccountFromKey: awsAccountFromKey,
+		GitHubCRC32:       githubCRC32,
+	}
+)
+
+// Register adds a verifier that rules can name with verify: name. It must
+// be called before the matcher for those rules is created. Registering a
+// name twice is an error.
+func Register(name string, fn Func) error {
+	if name == "" || fn == nil {
+		return fmt.Errorf("verifier needs a name and a function")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := verifiers[name]; ok {
+		return fmt.Errorf("verifier %q is already registered", name)
+	}
+	verifiers[name] = fn
+	return nil
+}
+
+// Lookup returns the verifier registered as name.
+func Lookup(name string) (Func, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	fn, ok := verifiers[name]
+	return fn, ok
+}
+
+// Names returns the names of the registered verifiers, sorted.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(verifiers))
+	for name := range verifiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
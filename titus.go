@@ -95,6 +95,7 @@ type scannerConfig struct {
 	contextLines     int
 	enableValidation bool
 	validationWorkers int
+	aggressiveValidation bool
 }
 
 // Option configures a Scanner.
@@ -125,6 +126,17 @@ func WithValidation() Option {
 	}
 }
 
+// WithAggressiveValidation enables secret validation including aggressive
+// validators, which authenticate to hosts named in a match's context (for
+// example an SSH login attempt with a discovered private key). Only use it
+// where such access attempts are authorized.
+func WithAggressiveValidation() Option {
+	return func(c *scannerConfig) {
+		c.enableValidation = true
+		c.aggressiveValidation = true
+	}
+}
+
 // WithValidationWorkers sets the number of concurrent validation workers.
 // Default is 4. Only applies when validation is enabled.
 func WithValidationWorkers(workers int) Option {
@@ -182,7 +194,7 @@ func NewScanner(opts ...Option) (*Scanner, error) {
 	// Create validation engine if enabled
 	var validationEngine *validator.Engine
 	if config.enableValidation {
-		validationEngine = createValidationEngine(config.validationWorkers)
+		validationEngine = createValidationEngine(config.validationWorkers, config.aggressiveValidation)
 	}
 
 	return &Scanner{
@@ -310,7 +322,10 @@ func (s *Scanner) validateMatches(ctx context.Context, matches []*Match) {
 }
 
 // createValidationEngine creates a validation engine with all available validators.
-func createValidationEngine(workers int) *validator.Engine {
+func createValidationEngine(workers int, aggressive bool) *validator.Engine {
+	if aggressive {
+		return validator.NewAggressiveEngine(workers)
+	}
 	return validator.NewDefaultEngine(workers)
 }
 
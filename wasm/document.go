@@ -0,0 +1,54 @@
+//go:build wasm
+
+package main
+
+import (
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/praetorian-inc/titus/pkg/extract"
+)
+
+// canExtract reports whether a file can be scanned with TitusScanDocument.
+// JS: TitusCanExtract(filename) -> bool
+func canExtract(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return false
+	}
+	return extract.Supported(args[0].String())
+}
+
+// scanDocument extracts and scans a document or archive, such as a
+// downloaded file. Supported formats are zip archives (including jar, apk
+// and crx), xlsx, docx, pptx, ipynb and tar/tar.gz.
+// JS: TitusScanDocument(handle, bytes (Uint8Array), filename) -> JSON batch results or error
+func scanDocument(this js.Value, args []js.Value) interface{} {
+	if len(args) < 3 {
+		return map[string]interface{}{"error": "handle, bytes and filename arguments required"}
+	}
+
+	handle := args[0].Int()
+	content := make([]byte, args[1].Get("length").Int())
+	js.CopyBytesToGo(content, args[1])
+	filename := args[2].String()
+
+	scannersMu.RLock()
+	core, ok := scanners[handle]
+	scannersMu.RUnlock()
+
+	if !ok {
+		return map[string]interface{}{"error": "invalid scanner handle"}
+	}
+
+	batchResult, err := core.ScanDocument(filename, content)
+	if err != nil {
+		return map[string]interface{}{"error": "document scan failed: " + err.Error()}
+	}
+
+	jsonBytes, err := json.Marshal(batchResult)
+	if err != nil {
+		return map[string]interface{}{"error": "failed to marshal results: " + err.Error()}
+	}
+
+	return string(jsonBytes)
+}
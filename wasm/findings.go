@@ -0,0 +1,139 @@
+//go:build wasm
+
+package main
+
+import (
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/praetorian-inc/titus/pkg/scanner"
+	"github.com/praetorian-inc/titus/pkg/types"
+)
+
+// findings accumulates matches across the pages scanned in this session.
+var findings = scanner.NewFindings()
+
+// recordFindings adds scan results from a page to the session's findings.
+// resultsJSON is the output of TitusScan or TitusScanBatch, with any
+// validation results filled in.
+// JS: TitusRecordFindings(url, resultsJSON) -> null or error
+func recordFindings(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return map[string]interface{}{"error": "url and resultsJSON arguments required"}
+	}
+
+	url := args[0].String()
+
+	// Either a single ScanResult or a BatchScanResult
+	var parsed struct {
+		scanner.ScanResult
+		Results []scanner.ScanResult `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(args[1].String()), &parsed); err != nil {
+		return map[string]interface{}{"error": "failed to parse results JSON: " + err.Error()}
+	}
+	results := parsed.Results
+	if len(parsed.Matches) > 0 {
+		results = append(results, parsed.ScanResult)
+	}
+
+	findings.Add(url, results)
+	return nil
+}
+
+// getFindings returns the session's findings, each with the pages it was
+// found on.
+// JS: TitusGetFindings() -> JSON findings array
+func getFindings(this js.Value, args []js.Value) interface{} {
+	return marshalFindings(json.Marshal)
+}
+
+// exportJSON returns the session's findings as indented JSON, for saving as
+// a report.
+// JS: TitusExportJSON() -> JSON findings array
+func exportJSON(this js.Value, args []js.Value) interface{} {
+	return marshalFindings(func(v any) ([]byte, error) {
+		return json.MarshalIndent(v, "", "  ")
+	})
+}
+
+func marshalFindings(marshal func(any) ([]byte, error)) interface{} {
+	list := findings.List()
+	if list == nil {
+		list = []*scanner.Finding{}
+	}
+	jsonBytes, err := marshal(list)
+	if err != nil {
+		return map[string]interface{}{"error": "failed to marshal findings: " + err.Error()}
+	}
+	return string(jsonBytes)
+}
+
+// exportSARIF returns findings as a SARIF report, with each result located
+// at the pages it was found on. findingsJSON, if given, is the array of
+// findings the extension stored (see extension/storage/db.js), which
+// outlive the session; otherwise the session's findings are exported.
+// JS: TitusExportSARIF([findingsJSON]) -> SARIF JSON
+func exportSARIF(this js.Value, args []js.Value) interface{} {
+	source := findings
+	if len(args) > 0 && args[0].Type() == js.TypeString {
+		var stored []storedFinding
+		if err := json.Unmarshal([]byte(args[0].String()), &stored); err != nil {
+			return map[string]interface{}{"error": "failed to parse findings JSON: " + err.Error()}
+		}
+		source = scanner.NewFindings()
+		for _, f := range stored {
+			source.Add(f.URL, []scanner.ScanResult{{Source: f.Source, Matches: []*types.Match{f.match()}}})
+		}
+	}
+
+	rules := make(map[string]*types.Rule)
+	if builtin, err := scanner.GetBuiltinRules(); err == nil {
+		for _, r := range builtin {
+			rules[r.ID] = r
+		}
+	}
+	jsonBytes, err := source.SARIF(rules).ToJSON()
+	if err != nil {
+		return map[string]interface{}{"error": "failed to marshal SARIF: " + err.Error()}
+	}
+	return string(jsonBytes)
+}
+
+// storedFinding is a match as the extension stores it, one per page.
+type storedFinding struct {
+	URL          string                  `json:"url"`
+	Source       string                  `json:"source"`
+	RuleID       string                  `json:"ruleId"`
+	RuleName     string                  `json:"ruleName"`
+	StructuralID string                  `json:"structuralId"`
+	Secret       string                  `json:"secret"`
+	Snippet      types.Snippet           `json:"snippet"`
+	Location     types.Location          `json:"location"`
+	Validation   *types.ValidationResult `json:"validation"`
+}
+
+// match rebuilds the match f was stored from. Only the secret is kept of
+// its capture groups, so it stands for them in the finding ID.
+func (f storedFinding) match() *types.Match {
+	var groups [][]byte
+	if f.Secret != "" {
+		groups = [][]byte{[]byte(f.Secret)}
+	}
+	return &types.Match{
+		StructuralID:     f.StructuralID,
+		RuleID:           f.RuleID,
+		RuleName:         f.RuleName,
+		Location:         f.Location,
+		Groups:           groups,
+		Snippet:          f.Snippet,
+		ValidationResult: f.Validation,
+	}
+}
+
+// clearFindings discards the session's findings.
+// JS: TitusClearFindings()
+func clearFindings(this js.Value, args []js.Value) interface{} {
+	findings.Clear()
+	return nil
+}
@@ -11,8 +11,17 @@ func main() {
 	js.Global().Set("TitusNewScanner", js.FuncOf(newScanner))
 	js.Global().Set("TitusScan", js.FuncOf(scan))
 	js.Global().Set("TitusScanBatch", js.FuncOf(scanBatch))
+	js.Global().Set("TitusScanDocument", js.FuncOf(scanDocument))
+	js.Global().Set("TitusCanExtract", js.FuncOf(canExtract))
+	js.Global().Set("TitusRecordFindings", js.FuncOf(recordFindings))
+	js.Global().Set("TitusGetFindings", js.FuncOf(getFindings))
+	js.Global().Set("TitusExportJSON", js.FuncOf(exportJSON))
+	js.Global().Set("TitusExportSARIF", js.FuncOf(exportSARIF))
+	js.Global().Set("TitusClearFindings", js.FuncOf(clearFindings))
 	js.Global().Set("TitusCloseScanner", js.FuncOf(closeScanner))
 	js.Global().Set("TitusGetBuiltinRules", js.FuncOf(getBuiltinRules))
+	js.Global().Set("TitusCanValidate", js.FuncOf(canValidate))
+	js.Global().Set("TitusValidate", js.FuncOf(validate))
 
 	// Keep WASM running
 	<-make(chan struct{})
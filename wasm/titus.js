@@ -320,6 +320,8 @@ function clearNetworkResponses() {
  * @param {boolean} options.stylesheets - Scan stylesheets (default: true)
  * @param {boolean} options.storage - Scan localStorage/sessionStorage (default: true)
  * @param {boolean} options.network - Include captured network responses (default: true)
+ * @param {boolean} options.record - Add the results to the session's findings for
+ *     TitusGetFindings, TitusExportJSON and TitusExportSARIF (default: false)
  * @returns {Promise<object>} Scan results
  */
 async function TitusScanPage(scannerHandle, options = {}) {
@@ -329,6 +331,7 @@ async function TitusScanPage(scannerHandle, options = {}) {
         stylesheets: true,
         storage: true,
         network: true,
+        record: false,
         ...options
     };
 
@@ -381,18 +384,50 @@ async function TitusScanPage(scannerHandle, options = {}) {
         result = resultStr;
     }
 
+    if (opts.record) {
+        TitusRecordFindings(window.location.href, resultStr);
+    }
+
     return {
         ...result,
         itemsScanned: items.length
     };
 }
 
+/**
+ * Scan a downloaded document or archive for secrets.
+ * Supported formats are zip archives (including jar, apk and crx), xlsx,
+ * docx, pptx, ipynb and tar/tar.gz; check with TitusCanExtract(filename).
+ *
+ * @param {number} scannerHandle - Handle from TitusNewScanner()
+ * @param {Blob|ArrayBuffer|Uint8Array} data - File content
+ * @param {string} filename - File name, used to pick the format
+ * @returns {Promise<object>} Scan results, one per extracted member
+ */
+async function TitusScanFile(scannerHandle, data, filename) {
+    if (!titusReady) {
+        throw new Error('Titus WASM module not initialized. Call TitusInit() first.');
+    }
+
+    if (data instanceof Blob) {
+        data = await data.arrayBuffer();
+    }
+    const bytes = data instanceof Uint8Array ? data : new Uint8Array(data);
+
+    const resultStr = TitusScanDocument(scannerHandle, bytes, filename);
+    if (typeof resultStr !== 'string') {
+        throw new Error(resultStr && resultStr.error ? resultStr.error : 'document scan failed');
+    }
+    return JSON.parse(resultStr);
+}
+
 // Export for module systems
 if (typeof module !== 'undefined' && module.exports) {
     module.exports = {
         TitusInit,
         TitusIsReady,
         TitusScanPage,
+        TitusScanFile,
         collectInlineScripts,
         collectExternalScripts,
         collectStylesheets,
@@ -0,0 +1,78 @@
+//go:build wasm
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"syscall/js"
+
+	"github.com/praetorian-inc/titus/pkg/types"
+	"github.com/praetorian-inc/titus/pkg/validator"
+)
+
+var (
+	engine     *validator.Engine
+	engineOnce sync.Once
+)
+
+// browserEngine returns the shared validation engine, created on first use.
+func browserEngine() *validator.Engine {
+	engineOnce.Do(func() {
+		engine = validator.NewBrowserEngine(4)
+	})
+	return engine
+}
+
+// canValidate reports whether a rule has a browser-safe validator.
+// JS: TitusCanValidate(ruleID) -> bool
+func canValidate(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return false
+	}
+	return browserEngine().CanValidate(args[0].String())
+}
+
+// validate checks a match returned by TitusScan against its service.
+// Requests go through fetch, which must not block the JS event loop, so the
+// result is delivered through a Promise.
+// JS: TitusValidate(matchJSON) -> Promise<JSON ValidationResult or error>
+func validate(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return map[string]interface{}{"error": "matchJSON argument required"}
+	}
+	matchJSON := args[0].String()
+
+	handler := js.FuncOf(func(this js.Value, promiseArgs []js.Value) interface{} {
+		resolve := promiseArgs[0]
+		go func() {
+			resolve.Invoke(validateMatch(matchJSON))
+		}()
+		return nil
+	})
+	promise := js.Global().Get("Promise").New(handler)
+	handler.Release()
+	return promise
+}
+
+// validateMatch validates a JSON-encoded match and returns the JSON result
+// or an error object.
+func validateMatch(matchJSON string) interface{} {
+	var match types.Match
+	if err := json.Unmarshal([]byte(matchJSON), &match); err != nil {
+		return map[string]interface{}{"error": "failed to parse match JSON: " + err.Error()}
+	}
+
+	result, err := browserEngine().ValidateMatch(context.Background(), &match)
+	if err != nil {
+		return map[string]interface{}{"error": "validation failed: " + err.Error()}
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return map[string]interface{}{"error": "failed to marshal result: " + err.Error()}
+	}
+
+	return string(jsonBytes)
+}